@@ -1,56 +1,88 @@
 package app
 
 import (
+	"context"
 	"dromatech/pos-backend/global"
 	configdomain "dromatech/pos-backend/internal/domain/config"
+	apikeyhandler "dromatech/pos-backend/internal/handler/apikey"
+	attachmenthandler "dromatech/pos-backend/internal/handler/attachment"
+	branchhandler "dromatech/pos-backend/internal/handler/branch"
 	customerhandler "dromatech/pos-backend/internal/handler/customer"
+	docshandler "dromatech/pos-backend/internal/handler/docs"
+	expensehandler "dromatech/pos-backend/internal/handler/expense"
 	kontrabonhandler "dromatech/pos-backend/internal/handler/kontrabon"
+	languagehandler "dromatech/pos-backend/internal/handler/language"
+	loghandler "dromatech/pos-backend/internal/handler/log"
+	notificationhandler "dromatech/pos-backend/internal/handler/notification"
 	pinghandler "dromatech/pos-backend/internal/handler/ping"
 	pricehandler "dromatech/pos-backend/internal/handler/price"
 	producthandler "dromatech/pos-backend/internal/handler/product"
 	rolehandler "dromatech/pos-backend/internal/handler/role"
 	sessionhandler "dromatech/pos-backend/internal/handler/session"
 	supplierhandler "dromatech/pos-backend/internal/handler/supplier"
+	tenanthandler "dromatech/pos-backend/internal/handler/tenant"
 	transactionhandler "dromatech/pos-backend/internal/handler/transaction"
 	unithandler "dromatech/pos-backend/internal/handler/unit"
 	webuserhandler "dromatech/pos-backend/internal/handler/webuser"
+	apikeyrepo "dromatech/pos-backend/internal/repo/apikey"
+	attachmentrepo "dromatech/pos-backend/internal/repo/attachment"
+	branchrepo "dromatech/pos-backend/internal/repo/branch"
 	configrepo "dromatech/pos-backend/internal/repo/config"
 	customerrepo "dromatech/pos-backend/internal/repo/customer"
+	expenserepo "dromatech/pos-backend/internal/repo/expense"
 	kontrabonrepo "dromatech/pos-backend/internal/repo/kontrabon"
+	loginhistoryrepo "dromatech/pos-backend/internal/repo/loginhistory"
+	notificationrepo "dromatech/pos-backend/internal/repo/notification"
 	pricerepo "dromatech/pos-backend/internal/repo/price"
 	productrepo "dromatech/pos-backend/internal/repo/product"
 	rolerepo "dromatech/pos-backend/internal/repo/role"
 	sequencerepo "dromatech/pos-backend/internal/repo/sequence"
 	supplierrepo "dromatech/pos-backend/internal/repo/supplier"
+	tenantrepo "dromatech/pos-backend/internal/repo/tenant"
 	transactionrepo "dromatech/pos-backend/internal/repo/transaction"
 	unitrepo "dromatech/pos-backend/internal/repo/unit"
 	webuserrepo "dromatech/pos-backend/internal/repo/webuser"
+	apikeyusecase "dromatech/pos-backend/internal/usecase/apikey"
+	attachmentusecase "dromatech/pos-backend/internal/usecase/attachment"
+	branchusecase "dromatech/pos-backend/internal/usecase/branch"
 	customerusecase "dromatech/pos-backend/internal/usecase/customer"
+	expenseusecase "dromatech/pos-backend/internal/usecase/expense"
 	kontrabonusecase "dromatech/pos-backend/internal/usecase/kontrabon"
+	notificationusecase "dromatech/pos-backend/internal/usecase/notification"
 	priceusecase "dromatech/pos-backend/internal/usecase/price"
 	productusecase "dromatech/pos-backend/internal/usecase/product"
 	roleusecase "dromatech/pos-backend/internal/usecase/role"
 	sessionusecase "dromatech/pos-backend/internal/usecase/session"
 	supplierusecase "dromatech/pos-backend/internal/usecase/supplier"
+	tenantusecase "dromatech/pos-backend/internal/usecase/tenant"
 	transactionusecase "dromatech/pos-backend/internal/usecase/transaction"
 	unitusecase "dromatech/pos-backend/internal/usecase/unit"
 	webuserusecase "dromatech/pos-backend/internal/usecase/webuser"
+	storageutil "dromatech/pos-backend/internal/util/storage"
 	"fmt"
-	"strconv"
 )
 
 type AppHandler struct {
-	pingHandler        *pinghandler.Handler
-	sessionHandler     *sessionhandler.Handler
-	webUserHander      *webuserhandler.Handler
-	roleHandler        *rolehandler.Handler
-	productHandler     *producthandler.Handler
-	supplierHandler    *supplierhandler.Handler
-	customerHandler    *customerhandler.Handler
-	unitHandler        *unithandler.Handler
-	transactionHandler *transactionhandler.Handler
-	kontrabonHandler   *kontrabonhandler.Handler
-	priceHandler       *pricehandler.Handler
+	logHandler          *loghandler.Handler
+	languageHandler     *languagehandler.Handler
+	pingHandler         *pinghandler.Handler
+	docsHandler         *docshandler.Handler
+	sessionHandler      *sessionhandler.Handler
+	webUserHander       *webuserhandler.Handler
+	roleHandler         *rolehandler.Handler
+	productHandler      *producthandler.Handler
+	supplierHandler     *supplierhandler.Handler
+	customerHandler     *customerhandler.Handler
+	unitHandler         *unithandler.Handler
+	transactionHandler  *transactionhandler.Handler
+	kontrabonHandler    *kontrabonhandler.Handler
+	priceHandler        *pricehandler.Handler
+	apiKeyHandler       *apikeyhandler.Handler
+	attachmentHandler   *attachmenthandler.Handler
+	notificationHandler *notificationhandler.Handler
+	expenseHandler      *expensehandler.Handler
+	branchHandler       *branchhandler.Handler
+	tenantHandler       *tenanthandler.Handler
 }
 
 func StartApp() error {
@@ -61,12 +93,9 @@ func StartApp() error {
 	}
 
 	// set global config
-	global.LOGIN_URL = configRepo.GetValue(configdomain.LOGIN_URL)
-	global.UNAUTHORIZED_URL = configRepo.GetValue(configdomain.UNAUTHORIZED_URL)
-	global.FORBIDDEN_URL = configRepo.GetValue(configdomain.FORBIDDEN_URL)
-
-	sessionTimeout, _ := strconv.Atoi(configRepo.GetValue(configdomain.SESSION_TIMEOUT_MINUTE))
-	global.SESSION_TIMEOUT_MINUTE = sessionTimeout
+	global.LOGIN_URL = configRepo.GetValue(context.Background(), configdomain.LOGIN_URL)
+	global.UNAUTHORIZED_URL = configRepo.GetValue(context.Background(), configdomain.UNAUTHORIZED_URL)
+	global.FORBIDDEN_URL = configRepo.GetValue(context.Background(), configdomain.FORBIDDEN_URL)
 
 	// init repo
 	webuserRepo := webuserrepo.New()
@@ -79,21 +108,46 @@ func StartApp() error {
 	transactionRepo := transactionrepo.New()
 	kontrabonRepo := kontrabonrepo.New()
 	priceRepo := pricerepo.New()
+	loginHistoryRepo := loginhistoryrepo.New()
+	apiKeyRepo := apikeyrepo.New()
+	attachmentRepo := attachmentrepo.New()
+	notificationRepo := notificationrepo.New()
+	expenseRepo := expenserepo.New()
+	branchRepo := branchrepo.New()
+	tenantRepo := tenantrepo.New()
+
+	// init storage of the attachment files
+	storage, err := storageutil.New(global.CONFIG.Storage)
+	if err != nil {
+		return err
+	}
 
 	// init usecase
-	sessionUsecase := sessionusecase.New(configRepo, webuserRepo, roleRepo)
-	webUserUsecase := webuserusecase.New(webuserRepo)
-	roleUsecase := roleusecase.New(roleRepo)
+	sessionUsecase := sessionusecase.New(configRepo, webuserRepo, roleRepo, loginHistoryRepo, apiKeyRepo)
+	webUserUsecase := webuserusecase.New(webuserRepo, configRepo, sessionUsecase)
+	roleUsecase := roleusecase.New(roleRepo, sessionUsecase)
+	apiKeyUsecase := apikeyusecase.New(apiKeyRepo, webuserRepo, roleRepo, sessionUsecase)
 	productUsecase := productusecase.New(productRepo)
 	supplierUsecase := supplierusecase.New(supplierRepo)
 	custmerUsecase := customerusecase.New(customerRepo)
 	unitUsecase := unitusecase.New(unitRepo)
-	transactionusecase := transactionusecase.New(transactionRepo, sequenceRepo, supplierRepo, customerRepo, kontrabonRepo)
-	kontrabonUseccase := kontrabonusecase.New(kontrabonRepo, sequenceRepo, customerRepo)
+	notificationUsecase := notificationusecase.New(notificationRepo)
+	transactionusecase := transactionusecase.New(transactionRepo, sequenceRepo, supplierRepo, customerRepo, kontrabonRepo, attachmentRepo, expenseRepo, configRepo, branchRepo, notificationUsecase, storage)
+	kontrabonUseccase := kontrabonusecase.New(kontrabonRepo, sequenceRepo, customerRepo, branchRepo)
 	priceUsecase := priceusecase.New(priceRepo, productRepo, customerRepo, transactionRepo)
+	attachmentUsecase := attachmentusecase.New(attachmentRepo, transactionRepo, transactionusecase, configRepo, storage)
+	expenseUsecase := expenseusecase.New(expenseRepo, webuserRepo)
+	branchUsecase := branchusecase.New(branchRepo)
+	tenantUsecase := tenantusecase.New(tenantRepo)
 
 	// init Handler
+	logHandler := loghandler.New()
+	languageHandler := languagehandler.New()
 	pingHandler := pinghandler.New()
+	docsHandler, err := docshandler.New(apiDocument())
+	if err != nil {
+		return err
+	}
 	sessionHandler := sessionhandler.New(sessionUsecase)
 	webUserHander := webuserhandler.New(webUserUsecase)
 	rolehandler := rolehandler.New(roleUsecase)
@@ -104,21 +158,38 @@ func StartApp() error {
 	transactionHandler := transactionhandler.New(transactionusecase)
 	kontrabonHandler := kontrabonhandler.New(kontrabonUseccase)
 	priceHandler := pricehandler.New(priceUsecase)
+	apiKeyHandler := apikeyhandler.New(apiKeyUsecase)
+	attachmentHandler := attachmenthandler.New(attachmentUsecase)
+	notificationHandler := notificationhandler.New(notificationUsecase)
+	expenseHandler := expensehandler.New(expenseUsecase)
+	branchHandler := branchhandler.New(branchUsecase)
+	tenantHandler := tenanthandler.New(tenantUsecase, global.CONFIG.Tenant)
 
 	appHandler := AppHandler{
-		pingHandler:        pingHandler,
-		sessionHandler:     sessionHandler,
-		webUserHander:      webUserHander,
-		roleHandler:        rolehandler,
-		productHandler:     productHandler,
-		supplierHandler:    supplierHandler,
-		customerHandler:    customerHandler,
-		unitHandler:        unitHandler,
-		transactionHandler: transactionHandler,
-		kontrabonHandler:   kontrabonHandler,
-		priceHandler:       priceHandler,
+		logHandler:          logHandler,
+		languageHandler:     languageHandler,
+		pingHandler:         pingHandler,
+		docsHandler:         docsHandler,
+		sessionHandler:      sessionHandler,
+		webUserHander:       webUserHander,
+		roleHandler:         rolehandler,
+		productHandler:      productHandler,
+		supplierHandler:     supplierHandler,
+		customerHandler:     customerHandler,
+		unitHandler:         unitHandler,
+		transactionHandler:  transactionHandler,
+		kontrabonHandler:    kontrabonHandler,
+		priceHandler:        priceHandler,
+		apiKeyHandler:       apiKeyHandler,
+		attachmentHandler:   attachmentHandler,
+		notificationHandler: notificationHandler,
+		expenseHandler:      expenseHandler,
+		branchHandler:       branchHandler,
+		tenantHandler:       tenantHandler,
 	}
 
+	go transactionusecase.SweepExpiredTransfers(context.Background(), tenantUsecase)
+
 	router := newRoutes(appHandler)
 	router.Run(fmt.Sprintf(global.CONFIG.Server.HTTP.Address))
 
@@ -0,0 +1,339 @@
+package app
+
+import (
+	apikeydomain "dromatech/pos-backend/internal/domain/apikey"
+	attachmentdomain "dromatech/pos-backend/internal/domain/attachment"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	customerdomain "dromatech/pos-backend/internal/domain/customer"
+	expensedomain "dromatech/pos-backend/internal/domain/expense"
+	kontrabondomain "dromatech/pos-backend/internal/domain/kontrabon"
+	notificationdomain "dromatech/pos-backend/internal/domain/notification"
+	pricedomain "dromatech/pos-backend/internal/domain/price"
+	productdomain "dromatech/pos-backend/internal/domain/product"
+	roledomain "dromatech/pos-backend/internal/domain/role"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	supplierdomain "dromatech/pos-backend/internal/domain/supplier"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	unitdomain "dromatech/pos-backend/internal/domain/unit"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	openapiutil "dromatech/pos-backend/internal/util/openapi"
+	"net/http"
+)
+
+const API_TITLE = "TJ POS Backend"
+const API_VERSION = "1.0.0"
+const API_DESCRIPTION = "Every response is sent with HTTP 200 and the status field of the envelope, " +
+	"send header X-Error-Mode: http to receive HTTP error statuses instead."
+
+var idBody = openapiutil.Fields{"id": ""}
+
+var transactionQuery = []string{"startDate", "endDate", "code", "stakeholderId", "txType", "status", "productId", "txId"}
+
+// apiRoutes describes every route of newRoutes, the router test fails when one is missing
+var apiRoutes = []openapiutil.Route{
+	{Method: http.MethodGet, Path: "/api/ping", Tag: "system", Summary: "Health check", Public: true, Raw: true},
+	{Method: http.MethodGet, Path: "/api/docs/*any", Tag: "system", Summary: "OpenAPI specification (openapi.json) and swagger ui", Public: true, Raw: true},
+
+	{Method: http.MethodPost, Path: "/api/auth/login", Tag: "auth", Summary: "Login", Public: true,
+		Body: openapiutil.Fields{"username": "", "password": ""}, Response: sessiondomain.Session{}},
+	{Method: http.MethodPost, Path: "/api/auth/login/verify", Tag: "auth", Summary: "Second login step with a TOTP or recovery code when login answered twoFactorRequired", Public: true,
+		Body: openapiutil.Fields{"challengeToken": "", "code": ""}, Response: sessiondomain.Session{}},
+	{Method: http.MethodPost, Path: "/api/auth/logout", Tag: "auth", Summary: "Logout the session of the token header"},
+	{Method: http.MethodGet, Path: "/api/auth/getmenu", Tag: "auth", Summary: "Session with the menu of the role", Response: sessiondomain.Session{}},
+	{Method: http.MethodPost, Path: "/api/auth/branch", Tag: "auth", Summary: "Switch the active branch of the session to one assigned to the user",
+		Body: openapiutil.Fields{"branchId": ""}, Response: sessiondomain.Session{}},
+	{Method: http.MethodGet, Path: "/api/auth/check", Tag: "auth", Summary: "Check a permission of the session", Query: []string{"permission"}},
+
+	{Method: http.MethodGet, Path: "/api/session/find", Tag: "session", Summary: "List active sessions", Query: []string{"userId"},
+		Response: []*sessiondomain.SessionInfo{}},
+	{Method: http.MethodPost, Path: "/api/session/revoke", Tag: "session", Summary: "Revoke a session", Body: idBody},
+	{Method: http.MethodPost, Path: "/api/session/revoke-user", Tag: "session", Summary: "Revoke every session of a user",
+		Body: openapiutil.Fields{"userId": ""}},
+	{Method: http.MethodGet, Path: "/api/session/login-history", Tag: "session", Summary: "Login history", Query: []string{"userId", "startDate", "endDate"},
+		SortFields: sessiondomain.HistorySortFields, Response: []*sessiondomain.LoginHistory{}},
+
+	{Method: http.MethodPost, Path: "/api/user/edit", Tag: "user", Summary: "Edit name of the session user",
+		Body: openapiutil.Fields{"name": ""}},
+	{Method: http.MethodPost, Path: "/api/user/edit-user", Tag: "user", Summary: "Edit user",
+		Body: openapiutil.Fields{"userId": "", "name": "", "username": "", "role": "", "active": ""}},
+	{Method: http.MethodPost, Path: "/api/user/change-password", Tag: "user", Summary: "Change password of the session user",
+		Body: openapiutil.Fields{"password1": "", "password2": "", "password3": ""}},
+	{Method: http.MethodPost, Path: "/api/user/force-change-password", Tag: "user", Summary: "Reset password of a user",
+		Body: openapiutil.Fields{"userId": "", "password1": "", "password2": ""}},
+	{Method: http.MethodPost, Path: "/api/user/register-user", Tag: "user", Summary: "Register user",
+		Body: openapiutil.Fields{"name": "", "username": "", "password": "", "roleId": ""}},
+	{Method: http.MethodPost, Path: "/api/user/register-service-account", Tag: "user", Summary: "Register a service account that calls the api with api keys",
+		Body: openapiutil.Fields{"name": "", "username": "", "roleId": ""}},
+	{Method: http.MethodGet, Path: "/api/user/find-all", Tag: "user", Summary: "List users",
+		SortFields: webuserdomain.SortFields, Response: []*webuserdomain.WebUser{}},
+	{Method: http.MethodPost, Path: "/api/user/change-status", Tag: "user", Summary: "Activate or deactivate a user",
+		Body: openapiutil.Fields{"userId": "", "active": false}},
+	{Method: http.MethodPost, Path: "/api/user/change-language", Tag: "user", Summary: "Change language of the session user",
+		Body: openapiutil.Fields{"language": ""}},
+	{Method: http.MethodPost, Path: "/api/user/unlock", Tag: "user", Summary: "Unlock a user locked after failed logins",
+		Body: openapiutil.Fields{"userId": ""}},
+	{Method: http.MethodPost, Path: "/api/user/totp/setup", Tag: "user", Summary: "Start TOTP enrolment of the session user", Response: webuserdomain.TotpSetup{}},
+	{Method: http.MethodPost, Path: "/api/user/totp/enable", Tag: "user", Summary: "Confirm TOTP enrolment, returns the recovery codes once",
+		Body: openapiutil.Fields{"code": ""}, Response: webuserdomain.RecoveryCodes{}},
+	{Method: http.MethodPost, Path: "/api/user/totp/disable", Tag: "user", Summary: "Turn off TOTP of the session user",
+		Body: openapiutil.Fields{"code": ""}},
+	{Method: http.MethodPost, Path: "/api/user/totp/recovery-codes", Tag: "user", Summary: "Replace the recovery codes of the session user",
+		Body: openapiutil.Fields{"code": ""}, Response: webuserdomain.RecoveryCodes{}},
+	{Method: http.MethodPost, Path: "/api/user/totp/reset", Tag: "user", Summary: "Turn off TOTP of a user who lost the authenticator",
+		Body: openapiutil.Fields{"userId": ""}},
+	{Method: http.MethodGet, Path: "/api/user/stakeholders", Tag: "user", Summary: "Customers and suppliers assigned to a user", Query: []string{"userId"},
+		Response: webuserdomain.Stakeholders{}},
+	{Method: http.MethodPost, Path: "/api/user/stakeholders/assign", Tag: "user", Summary: "Replace the customers and suppliers a user of a restricted role may read",
+		Body: openapiutil.Fields{"userId": "", "customers": []string{}, "suppliers": []string{}}},
+	{Method: http.MethodGet, Path: "/api/user/managed-users", Tag: "user", Summary: "Users a supervisor manages", Query: []string{"userId"},
+		Response: []webuserdomain.ManagedUser{}},
+	{Method: http.MethodPost, Path: "/api/user/managed-users/assign", Tag: "user", Summary: "Replace the users a supervisor manages and may decide dana transfers for",
+		Body: openapiutil.Fields{"userId": "", "users": []string{}}},
+	{Method: http.MethodGet, Path: "/api/user/branches", Tag: "user", Summary: "Branches a user is assigned to", Query: []string{"userId"},
+		Response: []*branchdomain.Branch{}},
+	{Method: http.MethodPost, Path: "/api/user/branches/assign", Tag: "user", Summary: "Replace the branches a user is assigned to, a user without one reads every branch",
+		Body: openapiutil.Fields{"userId": "", "branches": []string{}}},
+
+	{Method: http.MethodGet, Path: "/api/apikey/find", Tag: "apikey", Summary: "List api keys of a service account", Query: []string{"userId"},
+		Response: []*apikeydomain.ApiKey{}},
+	{Method: http.MethodPost, Path: "/api/apikey/create", Tag: "apikey", Summary: "Issue an api key, the key is only returned here",
+		Body: openapiutil.Fields{"userId": "", "name": "", "permissions": []string{}, "expiredDate": ""}, Response: apikeydomain.IssuedKey{}},
+	{Method: http.MethodPost, Path: "/api/apikey/rotate", Tag: "apikey", Summary: "Replace the secret of an api key, the old key stops working",
+		Body: openapiutil.Fields{"id": ""}, Response: apikeydomain.IssuedKey{}},
+	{Method: http.MethodPost, Path: "/api/apikey/revoke", Tag: "apikey", Summary: "Revoke an api key",
+		Body: openapiutil.Fields{"id": ""}},
+
+	{Method: http.MethodGet, Path: "/api/role/active-list", Tag: "role", Summary: "List active roles", Response: []*roledomain.RoleResponseModel{}},
+	{Method: http.MethodGet, Path: "/api/role/find-all", Tag: "role", Summary: "List roles", Response: []*roledomain.Role{}},
+	{Method: http.MethodGet, Path: "/api/role/permissions", Tag: "role", Summary: "Permissions of a role", Query: []string{"roleId"}, Response: []*roledomain.Permission{}},
+	{Method: http.MethodPost, Path: "/api/role/create", Tag: "role", Summary: "Create role",
+		Body: openapiutil.Fields{"roleName": "", "requireTwoFactor": false, "allData": true, "permissions": []string{}}},
+	{Method: http.MethodPost, Path: "/api/role/edit", Tag: "role", Summary: "Edit role",
+		Body: openapiutil.Fields{"roleId": "", "roleName": "", "active": false, "requireTwoFactor": false, "allData": true, "permissions": []string{}}},
+
+	{Method: http.MethodGet, Path: "/api/product/find", Tag: "product", Summary: "List products", Query: []string{"id", "code", "name", "active"},
+		SortFields: productdomain.SortFields, Response: []*productdomain.Product{}},
+	{Method: http.MethodGet, Path: "/api/product/findActive", Tag: "product", Summary: "List active products", Query: []string{"id", "code", "name"},
+		SortFields: productdomain.SortFields, Response: []*productdomain.Product{}},
+	{Method: http.MethodPost, Path: "/api/product/edit", Tag: "product", Summary: "Edit product",
+		Body: openapiutil.Fields{"id": "", "unitId": "", "code": "", "name": "", "active": false, "description": "", "taxRate": float64(0)}},
+	{Method: http.MethodPost, Path: "/api/product/create", Tag: "product", Summary: "Create product",
+		Body: openapiutil.Fields{"code": "", "name": "", "unitId": "", "description": "", "taxRate": float64(0)}},
+
+	{Method: http.MethodGet, Path: "/api/supplier/find", Tag: "supplier", Summary: "List suppliers", Query: []string{"id", "code", "name", "active"},
+		SortFields: supplierdomain.SortFields, Response: []*supplierdomain.Supplier{}},
+	{Method: http.MethodPost, Path: "/api/supplier/edit", Tag: "supplier", Summary: "Edit supplier",
+		Body: openapiutil.Fields{"id": "", "code": "", "name": "", "active": false, "description": ""}},
+	{Method: http.MethodPost, Path: "/api/supplier/create", Tag: "supplier", Summary: "Create supplier",
+		Body: openapiutil.Fields{"code": "", "name": "", "description": ""}},
+	{Method: http.MethodGet, Path: "/api/supplier/buy-price", Tag: "supplier", Summary: "Buy prices of a supplier", Query: []string{"supplierId", "unitId", "date", "productId"},
+		Response: []*supplierdomain.BuyPriceResponse{}},
+	{Method: http.MethodPost, Path: "/api/supplier/update-buy-price", Tag: "supplier", Summary: "Update buy prices", Body: supplierdomain.BuyPriceRequest{}},
+	{Method: http.MethodPost, Path: "/api/supplier/add-price", Tag: "supplier", Summary: "Add buy price", Body: supplierdomain.AddPriceRequest{}},
+	{Method: http.MethodGet, Path: "/api/supplier/find-latest-price", Tag: "supplier", Summary: "Latest buy prices", Query: []string{"supplierId", "unitId"},
+		Response: []*supplierdomain.PriceResponse{}},
+	{Method: http.MethodGet, Path: "/api/supplier/find-price", Tag: "supplier", Summary: "Buy price history", Query: []string{"supplierId", "unitId", "productId", "latest"},
+		Response: []*supplierdomain.PriceResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/customer/find", Tag: "customer", Summary: "List customers", Query: []string{"id", "code", "name", "active"},
+		SortFields: customerdomain.SortFields, Response: []*customerdomain.Customer{}},
+	{Method: http.MethodGet, Path: "/api/customer/findActive", Tag: "customer", Summary: "List customers", Query: []string{"id", "code", "name", "active"},
+		SortFields: customerdomain.SortFields, Response: []*customerdomain.Customer{}},
+	{Method: http.MethodPost, Path: "/api/customer/edit", Tag: "customer", Summary: "Edit customer",
+		Body: openapiutil.Fields{"id": "", "code": "", "name": "", "active": false, "initialCredit": int64(0), "description": "", "npwp": "", "address": "",
+			"taxable": false, "taxInclusive": false, "taxRate": float64(0)}},
+	{Method: http.MethodPost, Path: "/api/customer/create", Tag: "customer", Summary: "Create customer",
+		Body: openapiutil.Fields{"code": "", "name": "", "initialCredit": int64(0), "description": "", "npwp": "", "address": "",
+			"taxable": false, "taxInclusive": false, "taxRate": float64(0)}},
+	{Method: http.MethodGet, Path: "/api/customer/sell-price", Tag: "customer", Summary: "Sell prices of a customer", Query: []string{"customerId", "unitId", "date", "productId"},
+		Response: []*customerdomain.SellPriceResponse{}},
+	{Method: http.MethodPost, Path: "/api/customer/update-sell-price", Tag: "customer", Summary: "Update sell prices", Body: customerdomain.SellPriceRequest{}},
+	{Method: http.MethodPost, Path: "/api/customer/add-price", Tag: "customer", Summary: "Add sell price", Body: customerdomain.AddPriceRequest{}},
+	{Method: http.MethodGet, Path: "/api/customer/find-latest-price", Tag: "customer", Summary: "Latest sell prices", Query: []string{"customerId", "unitId"},
+		Response: []*customerdomain.PriceResponse{}},
+	{Method: http.MethodGet, Path: "/api/customer/find-price", Tag: "customer", Summary: "Sell price history", Query: []string{"customerId", "unitId", "productId", "latest"},
+		Response: []*customerdomain.PriceResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/unit/find", Tag: "unit", Summary: "List units", Query: []string{"id", "code", "active"},
+		SortFields: unitdomain.SortFields, Response: []*unitdomain.Unit{}},
+	{Method: http.MethodPost, Path: "/api/unit/edit", Tag: "unit", Summary: "Edit unit",
+		Body: openapiutil.Fields{"id": "", "active": false, "code": "", "description": ""}},
+	{Method: http.MethodPost, Path: "/api/unit/create", Tag: "unit", Summary: "Create unit",
+		Body: openapiutil.Fields{"code": "", "description": ""}},
+	{Method: http.MethodGet, Path: "/api/unit/findActive", Tag: "unit", Summary: "List active units", Query: []string{"id", "code"},
+		SortFields: unitdomain.SortFields, Response: []*unitdomain.Unit{}},
+
+	{Method: http.MethodGet, Path: "/api/expense/category/find", Tag: "expense", Summary: "List operasional expense categories", Query: []string{"id", "code", "active"},
+		SortFields: expensedomain.CategorySortFields, Response: []*expensedomain.Category{}},
+	{Method: http.MethodPost, Path: "/api/expense/category/create", Tag: "expense", Summary: "Create expense category",
+		Body: openapiutil.Fields{"code": "", "name": ""}},
+	{Method: http.MethodPost, Path: "/api/expense/category/edit", Tag: "expense", Summary: "Edit expense category",
+		Body: openapiutil.Fields{"id": "", "code": "", "name": "", "active": false}},
+	{Method: http.MethodGet, Path: "/api/expense/budget/find", Tag: "expense", Summary: "List operasional budgets", Query: []string{"categoryId", "webUserId"},
+		SortFields: expensedomain.BudgetSortFields, Response: []*expensedomain.Budget{}},
+	{Method: http.MethodPost, Path: "/api/expense/budget/create", Tag: "expense", Summary: "Create a daily or monthly budget, an empty category or user covers all of them",
+		Body: expensedomain.BudgetRequest{}},
+	{Method: http.MethodPost, Path: "/api/expense/budget/edit", Tag: "expense", Summary: "Edit budget", Body: expensedomain.BudgetRequest{}},
+	{Method: http.MethodPost, Path: "/api/expense/budget/delete", Tag: "expense", Summary: "Delete budget", Body: idBody},
+
+	{Method: http.MethodGet, Path: "/api/branch/find", Tag: "branch", Summary: "List branches", Query: []string{"id", "code", "active"},
+		SortFields: branchdomain.SortFields, Response: []*branchdomain.Branch{}},
+	{Method: http.MethodPost, Path: "/api/branch/create", Tag: "branch", Summary: "Create branch, the code prefix is put in front of its transaction and kontrabon codes",
+		Body: branchdomain.BranchRequest{}},
+	{Method: http.MethodPost, Path: "/api/branch/edit", Tag: "branch", Summary: "Edit branch", Body: branchdomain.BranchRequest{}},
+
+	{Method: http.MethodGet, Path: "/api/transaction/find", Tag: "transaction", Summary: "List sell transactions", Query: transactionQuery,
+		SortFields: transactiondomain.SellSortFields, Response: []*transactiondomain.TransactionStatus{}},
+	{Method: http.MethodPost, Path: "/api/transaction/create", Tag: "transaction", Summary: "Create transaction",
+		Body: transactiondomain.Transaction{}, Response: map[string]string{}},
+	{Method: http.MethodPost, Path: "/api/transaction/updateStatus", Tag: "transaction", Summary: "Move transaction to the next status",
+		Body: openapiutil.Fields{"transactionId": ""}},
+	{Method: http.MethodPost, Path: "/api/transaction/updateBuyPrice", Tag: "transaction", Summary: "Update buy price of a transaction product",
+		Body: openapiutil.Fields{"transactionId": "", "productId": "", "buyPrice": float64(0), "sellPrice": float64(0), "quantity": int64(0), "buy_quantity": int64(0)}},
+	{Method: http.MethodPost, Path: "/api/transaction/cancelTrx", Tag: "transaction", Summary: "Cancel transaction",
+		Body: openapiutil.Fields{"transactionId": ""}},
+	{Method: http.MethodPost, Path: "/api/transaction/update", Tag: "transaction", Summary: "Update transaction", Body: transactiondomain.Transaction{}},
+	{Method: http.MethodGet, Path: "/api/transaction/report", Tag: "transaction", Summary: "Transaction report", Query: transactionQuery,
+		Response: []*transactiondomain.ReportDate{}},
+	{Method: http.MethodPost, Path: "/api/transaction/updateHargaBeli", Tag: "transaction", Summary: "Update buy prices of a date",
+		Body: transactiondomain.UpdateHargaBeliRequest{}},
+	{Method: http.MethodPost, Path: "/api/transaction/insertTransactionBuy", Tag: "transaction", Summary: "Insert buy transactions",
+		Body: transactiondomain.InsertTransactionBuyRequestBulk{}},
+	{Method: http.MethodGet, Path: "/api/transaction/findCustomerCredit", Tag: "transaction", Summary: "Customer credit of a month", Query: []string{"month", "sell"},
+		Response: transactiondomain.TransactionCredit{}},
+	{Method: http.MethodGet, Path: "/api/transaction/findCustomerReport", Tag: "transaction", Summary: "Customer report of a month", Query: []string{"month", "stakeholderId"},
+		Response: transactiondomain.LaporanCustomerSumary{}},
+	{Method: http.MethodGet, Path: "/api/transaction/reconciliation", Tag: "transaction", Summary: "Ordered quantity of the sell transactions against belanja and transaction_buy per date and product",
+		Query: []string{"startDate", "endDate"}, Response: transactiondomain.ReconciliationResponse{}},
+	{Method: http.MethodPost, Path: "/api/transaction/reconciliation/apply", Tag: "transaction", Summary: "Set the average buy price of a date on its sell transactions",
+		Body: transactiondomain.ReconciliationApplyRequest{}, Response: transactiondomain.ReconciliationApplyResponse{}},
+	{Method: http.MethodGet, Path: "/api/transaction/rekap/branch", Tag: "transaction", Summary: "Consolidated rekapitulasi of every branch over a date range for head office",
+		Query: []string{"startDate", "endDate"}, Response: transactiondomain.RekapBranchResponse{}},
+	{Method: http.MethodGet, Path: "/api/transaction/efaktur/export", Tag: "transaction", Summary: "Taxable sell transactions over a date range that were given a serial number as an e-Faktur import csv",
+		Query: []string{"startDate", "endDate"}, Raw: true},
+	{Method: http.MethodPost, Path: "/api/transaction/efaktur/allocate", Tag: "transaction", Summary: "Give tax invoice serial numbers to the final taxable sell transactions over a date range",
+		Body: transactiondomain.TaxInvoiceAllocateRequest{}, Response: transactiondomain.TaxInvoiceAllocateResponse{}},
+	{Method: http.MethodGet, Path: "/api/transaction/closing/find", Tag: "transaction", Summary: "Closed cash boxes of mobile users", Query: []string{"startDate", "endDate", "userId"},
+		Response: []transactiondomain.CashClosing{}},
+	{Method: http.MethodPost, Path: "/api/transaction/closing/reopen", Tag: "transaction", Summary: "Reopen a closed cash box", Body: transactiondomain.CashClosingReopenRequest{}},
+	{Method: http.MethodGet, Path: "/api/transaction/closing/reopen-history", Tag: "transaction", Summary: "Reopened cash boxes", Query: []string{"startDate", "endDate", "userId"},
+		Response: []transactiondomain.CashClosingReopen{}},
+	{Method: http.MethodGet, Path: "/api/transaction/attachment/find", Tag: "transaction", Summary: "Attachments of a mobile record of any user", Query: []string{"ownerType", "ownerId"},
+		Response: []attachmentdomain.Attachment{}},
+	{Method: http.MethodGet, Path: "/api/transaction/attachment/download", Tag: "transaction", Summary: "Download an attachment of any user", Query: []string{"id", "thumbnail"},
+		Raw: true},
+
+	{Method: http.MethodGet, Path: "/api/kontrabon/find", Tag: "kontrabon", Summary: "List kontrabon", Query: []string{"startDate", "endDate", "code", "customerId"},
+		SortFields: kontrabondomain.SortFields, Response: []*kontrabondomain.KontrabonResponse{}},
+	{Method: http.MethodGet, Path: "/api/kontrabon/findTransaction", Tag: "kontrabon", Summary: "Transactions of a kontrabon", Query: []string{"kontrabonId"},
+		Response: []*transactiondomain.TransactionStatus{}},
+	{Method: http.MethodPost, Path: "/api/kontrabon/create", Tag: "kontrabon", Summary: "Create kontrabon", Body: kontrabondomain.CreateRequest{}},
+	{Method: http.MethodPost, Path: "/api/kontrabon/add", Tag: "kontrabon", Summary: "Add transactions to a kontrabon", Body: kontrabondomain.UpdateRequest{}},
+	{Method: http.MethodPost, Path: "/api/kontrabon/remove", Tag: "kontrabon", Summary: "Remove transactions from a kontrabon", Body: kontrabondomain.UpdateRequest{}},
+	{Method: http.MethodPost, Path: "/api/kontrabon/update-lunas", Tag: "kontrabon", Summary: "Mark kontrabon as paid",
+		Body: openapiutil.Fields{"kontrabonId": "", "paymentDate": "", "totalPayment": float64(0), "description": ""}},
+
+	{Method: http.MethodGet, Path: "/api/price/template/find", Tag: "price", Summary: "List sell price templates", Query: []string{"name"},
+		Response: []*pricedomain.PriceTemplate{}},
+	{Method: http.MethodGet, Path: "/api/price/template/findDetail", Tag: "price", Summary: "Prices of a sell template", Query: []string{"templateId"},
+		Response: []*pricedomain.PriceTemplateDetail{}},
+	{Method: http.MethodPost, Path: "/api/price/template/create", Tag: "price", Summary: "Create sell price template", Body: openapiutil.Fields{"name": ""}},
+	{Method: http.MethodPost, Path: "/api/price/template/edit-price", Tag: "price", Summary: "Edit a price of a sell template",
+		Body: openapiutil.Fields{"templateId": "", "productId": "", "price": float64(0)}},
+	{Method: http.MethodPost, Path: "/api/price/template/apply", Tag: "price", Summary: "Apply sell template to customers", Body: pricedomain.ApplyToCustomerReq{}},
+	{Method: http.MethodPost, Path: "/api/price/template/delete", Tag: "price", Summary: "Delete sell template", Body: pricedomain.DeleteTemplateReq{}},
+	{Method: http.MethodPost, Path: "/api/price/template/copy", Tag: "price", Summary: "Copy sell template",
+		Body: openapiutil.Fields{"templateId": "", "name": ""}},
+	{Method: http.MethodPost, Path: "/api/price/template/download", Tag: "price", Summary: "Fill sell template from customer prices", Body: pricedomain.Download{}},
+
+	{Method: http.MethodGet, Path: "/api/price/buytemplate/find", Tag: "price", Summary: "List buy price templates", Query: []string{"name"},
+		Response: []*pricedomain.PriceTemplate{}},
+	{Method: http.MethodGet, Path: "/api/price/buytemplate/findDetail", Tag: "price", Summary: "Prices of a buy template", Query: []string{"templateId"},
+		Response: []*pricedomain.PriceTemplateDetail{}},
+	{Method: http.MethodPost, Path: "/api/price/buytemplate/create", Tag: "price", Summary: "Create buy price template", Body: openapiutil.Fields{"name": ""}},
+	{Method: http.MethodPost, Path: "/api/price/buytemplate/edit-price", Tag: "price", Summary: "Edit a price of a buy template",
+		Body: openapiutil.Fields{"templateId": "", "productId": "", "price": float64(0)}},
+	{Method: http.MethodPost, Path: "/api/price/buytemplate/apply", Tag: "price", Summary: "Apply buy template to transactions of a date", Body: pricedomain.ApplyToTrxReq{}},
+	{Method: http.MethodPost, Path: "/api/price/buytemplate/delete", Tag: "price", Summary: "Delete buy template", Body: pricedomain.DeleteTemplateReq{}},
+	{Method: http.MethodPost, Path: "/api/price/buytemplate/copy", Tag: "price", Summary: "Copy buy template",
+		Body: openapiutil.Fields{"templateId": "", "name": ""}},
+	{Method: http.MethodPost, Path: "/api/price/buytemplate/download", Tag: "price", Summary: "Fill buy template from transaction prices", Body: pricedomain.Download{}},
+
+	{Method: http.MethodGet, Path: "/api/mobile/dana/find", Tag: "mobile", Summary: "Dana of the session user", Query: []string{"date"},
+		Response: transactiondomain.DanaInquiryResponse{}},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/create", Tag: "mobile", Summary: "Create dana", Body: transactiondomain.DanaRequest{}},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/update", Tag: "mobile", Summary: "Update dana", Body: transactiondomain.DanaRequest{}},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/send", Tag: "mobile", Summary: "Send dana to another user", Body: transactiondomain.DanaTransactionRequest{}},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/approve", Tag: "mobile", Summary: "Approve received dana", Body: idBody},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/reject", Tag: "mobile", Summary: "Reject received dana", Body: idBody},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/approve-bulk", Tag: "mobile", Summary: "Approve several received or supervised dana transfers, with the result of each",
+		Body: transactiondomain.DanaBulkRequest{}, Response: transactiondomain.DanaBulkResponse{}},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/reject-bulk", Tag: "mobile", Summary: "Reject several received or supervised dana transfers, with the result of each",
+		Body: transactiondomain.DanaBulkRequest{}, Response: transactiondomain.DanaBulkResponse{}},
+	{Method: http.MethodGet, Path: "/api/mobile/dana/supervised", Tag: "mobile", Summary: "Dana transfers between the users the session user manages", Query: []string{"status"},
+		Response: []transactiondomain.SupervisedDanaTransfer{}},
+	{Method: http.MethodPost, Path: "/api/mobile/dana/cancel", Tag: "mobile", Summary: "Cancel sent dana", Body: idBody},
+	{Method: http.MethodGet, Path: "/api/mobile/dana/find-user", Tag: "mobile", Summary: "Users dana can be sent to", Response: []transactiondomain.WebUserMobile{}},
+
+	{Method: http.MethodPost, Path: "/api/mobile/penjualan/create", Tag: "mobile", Summary: "Create cash sale", Body: transactiondomain.TrxCreateRequest{}},
+	{Method: http.MethodPost, Path: "/api/mobile/penjualan/delete", Tag: "mobile", Summary: "Delete cash sale", Body: idBody},
+	{Method: http.MethodGet, Path: "/api/mobile/penjualan/find", Tag: "mobile", Summary: "Cash sales of a date", Query: []string{"date"},
+		Response: []transactiondomain.TrxInquiryResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/mobile/belanja/create", Tag: "mobile", Summary: "Create belanja", Body: transactiondomain.TrxCreateRequest{}},
+	{Method: http.MethodPost, Path: "/api/mobile/belanja/delete", Tag: "mobile", Summary: "Delete belanja", Body: idBody},
+	{Method: http.MethodGet, Path: "/api/mobile/belanja/find", Tag: "mobile", Summary: "Belanja of a date", Query: []string{"date"},
+		Response: []transactiondomain.TrxInquiryResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/mobile/operasional/create", Tag: "mobile", Summary: "Create operasional, refused or warned when it goes over a budget",
+		Body: transactiondomain.TrxCreateOperasionalRequest{}, Response: transactiondomain.OperasionalCreateResponse{}},
+	{Method: http.MethodPost, Path: "/api/mobile/operasional/delete", Tag: "mobile", Summary: "Delete operasional", Body: idBody},
+	{Method: http.MethodGet, Path: "/api/mobile/operasional/find", Tag: "mobile", Summary: "Operasional of a date", Query: []string{"date"},
+		Response: []transactiondomain.TrxInquiryOperasionalResponse{}},
+	{Method: http.MethodGet, Path: "/api/mobile/operasional/find-description", Tag: "mobile", Summary: "Suggested operasional descriptions", Response: []string{}},
+	{Method: http.MethodGet, Path: "/api/mobile/operasional/category", Tag: "mobile", Summary: "Active operasional expense categories",
+		SortFields: expensedomain.CategorySortFields, Response: []*expensedomain.Category{}},
+
+	{Method: http.MethodGet, Path: "/api/mobile/saldo", Tag: "mobile", Summary: "Saldo of the session user", Query: []string{"date"},
+		Response: transactiondomain.SaldoResponse{}},
+	{Method: http.MethodGet, Path: "/api/mobile/rekapitulasi", Tag: "mobile", Summary: "Rekapitulasi of a date", Query: []string{"date"},
+		Response: transactiondomain.RekapitulasiResponse{}},
+	{Method: http.MethodGet, Path: "/api/mobile/rekapitulasi/range", Tag: "mobile", Summary: "Rekapitulasi of the mobile users over a date range by day, week or month",
+		Query: []string{"startDate", "endDate", "bucket"}, Response: transactiondomain.RekapRangeResponse{}},
+	{Method: http.MethodGet, Path: "/api/mobile/rekapitulasi/export", Tag: "mobile", Summary: "Range rekapitulasi as a csv or xlsx file",
+		Query: []string{"startDate", "endDate", "bucket", "format"}, Raw: true},
+
+	{Method: http.MethodPost, Path: "/api/mobile/closing/close", Tag: "mobile", Summary: "Close the cash box of a date", Body: transactiondomain.CashClosingRequest{},
+		Response: transactiondomain.CashClosing{}},
+	{Method: http.MethodGet, Path: "/api/mobile/closing/find", Tag: "mobile", Summary: "Closing of a date of the session user, null when still open", Query: []string{"date"},
+		Response: transactiondomain.CashClosing{}},
+
+	{Method: http.MethodPost, Path: "/api/mobile/sync/push", Tag: "mobile", Summary: "Apply records recorded offline, safe to retry",
+		Body: transactiondomain.SyncPushRequest{}, Response: transactiondomain.SyncPushResponse{}},
+	{Method: http.MethodGet, Path: "/api/mobile/sync/changes", Tag: "mobile", Summary: "Changes of the records of the session user after a cursor", Query: []string{"cursor", "limit"},
+		Response: transactiondomain.SyncChangesResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/mobile/attachment/upload", Tag: "mobile", Summary: "Attach a receipt to a belanja, operasional or transfer of the session user",
+		Form: openapiutil.Fields{"ownerType": "", "ownerId": "", "file": openapiutil.Binary{}}, Response: attachmentdomain.Attachment{}},
+	{Method: http.MethodGet, Path: "/api/mobile/attachment/find", Tag: "mobile", Summary: "Attachments of a record of the session user", Query: []string{"ownerType", "ownerId"},
+		Response: []attachmentdomain.Attachment{}},
+	{Method: http.MethodGet, Path: "/api/mobile/attachment/download", Tag: "mobile", Summary: "Download an attachment, or its JPEG thumbnail", Query: []string{"id", "thumbnail"},
+		Raw: true},
+	{Method: http.MethodPost, Path: "/api/mobile/attachment/delete", Tag: "mobile", Summary: "Delete an attachment of a day that is still open", Body: idBody},
+
+	{Method: http.MethodGet, Path: "/api/mobile/notification/stream", Tag: "mobile",
+		Summary: "Server-Sent Events of the notifications of the session user, send Last-Event-ID or lastEventId to receive the ones missed",
+		Query:   []string{"lastEventId"}, Raw: true},
+	{Method: http.MethodGet, Path: "/api/mobile/notification/find", Tag: "mobile", Summary: "Latest notifications of the session user", Query: []string{"unread", "limit"},
+		Response: notificationdomain.NotificationListResponse{}},
+	{Method: http.MethodPost, Path: "/api/mobile/notification/read", Tag: "mobile", Summary: "Mark notifications as read", Body: notificationdomain.MarkReadRequest{}},
+}
+
+// apiDocument builds the OpenAPI document served at /api/docs
+func apiDocument() *openapiutil.Document {
+	builder := openapiutil.New(API_TITLE, API_VERSION, API_DESCRIPTION)
+	for _, route := range apiRoutes {
+		builder.Add(route)
+	}
+	return builder.Document()
+}
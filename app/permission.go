@@ -0,0 +1,48 @@
+package app
+
+import (
+	roledomain "dromatech/pos-backend/internal/domain/role"
+	sessionhandler "dromatech/pos-backend/internal/handler/session"
+	permissionutil "dromatech/pos-backend/internal/util/permission"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoutesWithoutPermission returns the registered routes which are not whitelisted and not granted by any permission api
+func RoutesWithoutPermission(apis []*roledomain.PermissionApi) []gin.RouteInfo {
+	var rules []permissionutil.Rule
+	for _, api := range apis {
+		rules = append(rules, permissionutil.Rule{Method: api.Method, Path: api.Path})
+	}
+	matcher := permissionutil.NewMatcher(rules)
+
+	var uncovered []gin.RouteInfo
+	for _, route := range newRoutes(AppHandler{}).Routes() {
+		if sessionhandler.WhitelistPath[route.Path] || matcher.Allowed(route.Method, route.Path) {
+			continue
+		}
+		uncovered = append(uncovered, route)
+	}
+	return uncovered
+}
+
+// PermissionApisWithoutRoute returns the permission apis which match no registered route, usually a typo or a removed endpoint
+func PermissionApisWithoutRoute(apis []*roledomain.PermissionApi) []*roledomain.PermissionApi {
+	routes := newRoutes(AppHandler{}).Routes()
+
+	var unused []*roledomain.PermissionApi
+	for _, api := range apis {
+		rule := permissionutil.Rule{Method: api.Method, Path: api.Path}
+		matched := false
+		for _, route := range routes {
+			if rule.Matches(route.Method, route.Path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unused = append(unused, api)
+		}
+	}
+	return unused
+}
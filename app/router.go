@@ -5,22 +5,54 @@ import (
 )
 
 func newRoutes(appHandler AppHandler) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(appHandler.logHandler.RequestLogger)
+	router.Use(appHandler.languageHandler.Resolve)
+	router.Use(appHandler.tenantHandler.Resolve)
 	router.Use(appHandler.sessionHandler.AuthCheck)
+	router.Use(appHandler.languageHandler.ResolveUser)
 
 	router.GET("/api/ping", appHandler.pingHandler.Ping)
+	router.GET("/api/docs/*any", appHandler.docsHandler.Serve)
 	router.POST("/api/auth/login", appHandler.sessionHandler.Login)
+	router.POST("/api/auth/login/verify", appHandler.sessionHandler.VerifyLogin)
 	router.POST("/api/auth/logout", appHandler.sessionHandler.Logout)
 	router.GET("/api/auth/getmenu", appHandler.sessionHandler.GetMenu)
+	router.POST("/api/auth/branch", appHandler.sessionHandler.SelectBranch)
 	router.GET("/api/auth/check", appHandler.sessionHandler.CheckPermission)
 
+	router.GET("/api/session/find", appHandler.sessionHandler.FindSessions)
+	router.POST("/api/session/revoke", appHandler.sessionHandler.Revoke)
+	router.POST("/api/session/revoke-user", appHandler.sessionHandler.RevokeUser)
+	router.GET("/api/session/login-history", appHandler.sessionHandler.FindLoginHistory)
+
 	router.POST("/api/user/edit", appHandler.webUserHander.EditName)
 	router.POST("/api/user/edit-user", appHandler.webUserHander.EditUser)
 	router.POST("/api/user/change-password", appHandler.webUserHander.ChangePassword)
 	router.POST("/api/user/force-change-password", appHandler.webUserHander.ForceChangePassword)
 	router.POST("/api/user/register-user", appHandler.webUserHander.RegisterUser)
+	router.POST("/api/user/register-service-account", appHandler.webUserHander.RegisterServiceAccount)
 	router.GET("/api/user/find-all", appHandler.webUserHander.FindAllUser)
 	router.POST("/api/user/change-status", appHandler.webUserHander.ChangeStatus)
+	router.POST("/api/user/change-language", appHandler.webUserHander.ChangeLanguage)
+	router.POST("/api/user/unlock", appHandler.webUserHander.Unlock)
+	router.POST("/api/user/totp/setup", appHandler.webUserHander.SetupTotp)
+	router.POST("/api/user/totp/enable", appHandler.webUserHander.EnableTotp)
+	router.POST("/api/user/totp/disable", appHandler.webUserHander.DisableTotp)
+	router.POST("/api/user/totp/recovery-codes", appHandler.webUserHander.RegenerateRecoveryCodes)
+	router.POST("/api/user/totp/reset", appHandler.webUserHander.ResetTotp)
+	router.GET("/api/user/stakeholders", appHandler.webUserHander.FindStakeholders)
+	router.POST("/api/user/stakeholders/assign", appHandler.webUserHander.AssignStakeholders)
+	router.GET("/api/user/managed-users", appHandler.webUserHander.FindManagedUsers)
+	router.POST("/api/user/managed-users/assign", appHandler.webUserHander.AssignManagedUsers)
+	router.GET("/api/user/branches", appHandler.webUserHander.FindBranches)
+	router.POST("/api/user/branches/assign", appHandler.webUserHander.AssignBranches)
+
+	router.GET("/api/apikey/find", appHandler.apiKeyHandler.Find)
+	router.POST("/api/apikey/create", appHandler.apiKeyHandler.Create)
+	router.POST("/api/apikey/rotate", appHandler.apiKeyHandler.Rotate)
+	router.POST("/api/apikey/revoke", appHandler.apiKeyHandler.Revoke)
 
 	router.GET("/api/role/active-list", appHandler.roleHandler.GetActive)
 	router.GET("/api/role/find-all", appHandler.roleHandler.GetAll)
@@ -57,6 +89,18 @@ func newRoutes(appHandler AppHandler) *gin.Engine {
 	router.POST("/api/unit/create", appHandler.unitHandler.Create)
 	router.GET("/api/unit/findActive", appHandler.unitHandler.FindActive)
 
+	router.GET("/api/expense/category/find", appHandler.expenseHandler.FindCategory)
+	router.POST("/api/expense/category/create", appHandler.expenseHandler.CreateCategory)
+	router.POST("/api/expense/category/edit", appHandler.expenseHandler.EditCategory)
+	router.GET("/api/expense/budget/find", appHandler.expenseHandler.FindBudget)
+	router.POST("/api/expense/budget/create", appHandler.expenseHandler.CreateBudget)
+	router.POST("/api/expense/budget/edit", appHandler.expenseHandler.EditBudget)
+	router.POST("/api/expense/budget/delete", appHandler.expenseHandler.DeleteBudget)
+
+	router.GET("/api/branch/find", appHandler.branchHandler.Find)
+	router.POST("/api/branch/create", appHandler.branchHandler.Create)
+	router.POST("/api/branch/edit", appHandler.branchHandler.Edit)
+
 	router.GET("/api/transaction/find", appHandler.transactionHandler.Find)
 	router.POST("/api/transaction/create", appHandler.transactionHandler.Create)
 	router.POST("/api/transaction/updateStatus", appHandler.transactionHandler.UpdateStatus)
@@ -68,6 +112,16 @@ func newRoutes(appHandler AppHandler) *gin.Engine {
 	router.POST("/api/transaction/insertTransactionBuy", appHandler.transactionHandler.InsertTransactionBuy)
 	router.GET("/api/transaction/findCustomerCredit", appHandler.transactionHandler.FindCustomerCredit)
 	router.GET("/api/transaction/findCustomerReport", appHandler.transactionHandler.FindCustomerReport)
+	router.GET("/api/transaction/reconciliation", appHandler.transactionHandler.FindReconciliation)
+	router.POST("/api/transaction/reconciliation/apply", appHandler.transactionHandler.ApplyReconciliation)
+	router.GET("/api/transaction/rekap/branch", appHandler.transactionHandler.FindRekapBranch)
+	router.GET("/api/transaction/efaktur/export", appHandler.transactionHandler.ExportEFaktur)
+	router.POST("/api/transaction/efaktur/allocate", appHandler.transactionHandler.AllocateTaxInvoiceNumbers)
+	router.GET("/api/transaction/closing/find", appHandler.transactionHandler.FindClosingList)
+	router.POST("/api/transaction/closing/reopen", appHandler.transactionHandler.ReopenCash)
+	router.GET("/api/transaction/closing/reopen-history", appHandler.transactionHandler.FindReopenHistory)
+	router.GET("/api/transaction/attachment/find", appHandler.attachmentHandler.FindAll)
+	router.GET("/api/transaction/attachment/download", appHandler.attachmentHandler.DownloadAny)
 
 	router.GET("/api/kontrabon/find", appHandler.kontrabonHandler.Find)
 	router.GET("/api/kontrabon/findTransaction", appHandler.kontrabonHandler.FindTransaction)
@@ -100,6 +154,9 @@ func newRoutes(appHandler AppHandler) *gin.Engine {
 	router.POST("/api/mobile/dana/send", appHandler.transactionHandler.SendDana)
 	router.POST("/api/mobile/dana/approve", appHandler.transactionHandler.ApproveDana)
 	router.POST("/api/mobile/dana/reject", appHandler.transactionHandler.RejectDana)
+	router.POST("/api/mobile/dana/approve-bulk", appHandler.transactionHandler.ApproveDanaBulk)
+	router.POST("/api/mobile/dana/reject-bulk", appHandler.transactionHandler.RejectDanaBulk)
+	router.GET("/api/mobile/dana/supervised", appHandler.transactionHandler.FindSupervisedTransfers)
 	router.POST("/api/mobile/dana/cancel", appHandler.transactionHandler.CancelSendDana)
 	router.GET("/api/mobile/dana/find-user", appHandler.transactionHandler.FindUserMobile)
 
@@ -115,9 +172,27 @@ func newRoutes(appHandler AppHandler) *gin.Engine {
 	router.POST("/api/mobile/operasional/delete", appHandler.transactionHandler.DeleteOperasional)
 	router.GET("/api/mobile/operasional/find", appHandler.transactionHandler.FindOperasional)
 	router.GET("/api/mobile/operasional/find-description", appHandler.transactionHandler.FindDescriptionOperasional)
+	router.GET("/api/mobile/operasional/category", appHandler.expenseHandler.FindActiveCategory)
 
 	router.GET("/api/mobile/saldo", appHandler.transactionHandler.FindSaldo)
 	router.GET("/api/mobile/rekapitulasi", appHandler.transactionHandler.FindRekapitulasi)
+	router.GET("/api/mobile/rekapitulasi/range", appHandler.transactionHandler.FindRekapRange)
+	router.GET("/api/mobile/rekapitulasi/export", appHandler.transactionHandler.ExportRekapRange)
+
+	router.POST("/api/mobile/closing/close", appHandler.transactionHandler.CloseCash)
+	router.GET("/api/mobile/closing/find", appHandler.transactionHandler.FindClosing)
+
+	router.POST("/api/mobile/sync/push", appHandler.transactionHandler.SyncPush)
+	router.GET("/api/mobile/sync/changes", appHandler.transactionHandler.SyncChanges)
+
+	router.POST("/api/mobile/attachment/upload", appHandler.attachmentHandler.Upload)
+	router.GET("/api/mobile/attachment/find", appHandler.attachmentHandler.Find)
+	router.GET("/api/mobile/attachment/download", appHandler.attachmentHandler.Download)
+	router.POST("/api/mobile/attachment/delete", appHandler.attachmentHandler.Delete)
+
+	router.GET("/api/mobile/notification/stream", appHandler.notificationHandler.Stream)
+	router.GET("/api/mobile/notification/find", appHandler.notificationHandler.FindList)
+	router.POST("/api/mobile/notification/read", appHandler.notificationHandler.MarkRead)
 
 	return router
 }
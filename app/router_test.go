@@ -0,0 +1,72 @@
+package app
+
+import (
+	docshandler "dromatech/pos-backend/internal/handler/docs"
+	openapiutil "dromatech/pos-backend/internal/util/openapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newRoutes(AppHandler{})
+	doc := apiDocument()
+
+	for _, route := range router.Routes() {
+		if !doc.Has(route.Method, route.Path) {
+			t.Errorf("route %s %s is missing from the OpenAPI spec, add it to apiRoutes", route.Method, route.Path)
+		}
+	}
+}
+
+func TestDocumentedRoutesExist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newRoutes(AppHandler{})
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, route := range apiRoutes {
+		if !registered[route.Method+" "+route.Path] {
+			t.Errorf("apiRoutes documents %s %s which is not registered in newRoutes", route.Method, route.Path)
+		}
+	}
+}
+
+func TestServeSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler, err := docshandler.New(apiDocument())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.GET("/api/docs/*any", handler.Serve)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/docs/openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	doc := &openapiutil.Document{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), doc); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Has(http.MethodPost, "/api/transaction/create") {
+		t.Error("served spec is missing POST /api/transaction/create")
+	}
+	if _, exists := doc.Components.Schemas["transactiondomain.Transaction"]; !exists {
+		t.Error("served spec is missing the transactiondomain.Transaction schema")
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/docs/", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected the swagger ui index with status 200, got %d", recorder.Code)
+	}
+}
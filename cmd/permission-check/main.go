@@ -0,0 +1,61 @@
+// Command permission-check lists the registered routes no permission grants and the permission apis matching no route.
+// It exits with status 1 when it finds either, so it can run in CI against a migrated database.
+package main
+
+import (
+	"context"
+	"dromatech/pos-backend/app"
+	"dromatech/pos-backend/config"
+	"dromatech/pos-backend/global"
+	rolerepo "dromatech/pos-backend/internal/repo/role"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	configFile := flag.String("config", "config.yaml", "configuration file with the database to check")
+	flag.Parse()
+
+	cfg, err := config.New(*configFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+
+	dsn := cfg.Database.DSN()
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+	global.DBCON = db
+
+	apis, err := rolerepo.New().FindPermissionApis(context.Background())
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	uncovered := app.RoutesWithoutPermission(apis)
+	unused := app.PermissionApisWithoutRoute(apis)
+
+	fmt.Printf("%d routes not covered by any permission\n", len(uncovered))
+	for _, route := range uncovered {
+		fmt.Printf("  %-6s %s\n", route.Method, route.Path)
+	}
+	fmt.Printf("%d permission apis matching no route\n", len(unused))
+	for _, api := range unused {
+		fmt.Printf("  %-6s %-40s %s\n", api.Method, api.Path, api.PermissionID)
+	}
+
+	if len(uncovered) > 0 || len(unused) > 0 {
+		os.Exit(1)
+	}
+}
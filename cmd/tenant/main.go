@@ -0,0 +1,86 @@
+// Command tenant provisions a tenant of a multi-tenant deployment. It creates the schema of the tenant, runs the table
+// and seed scripts on it and registers the tenant so requests for its code are routed to the schema.
+package main
+
+import (
+	"context"
+	"dromatech/pos-backend/config"
+	"dromatech/pos-backend/global"
+	tenantdomain "dromatech/pos-backend/internal/domain/tenant"
+	tenantrepo "dromatech/pos-backend/internal/repo/tenant"
+	tenantusecase "dromatech/pos-backend/internal/usecase/tenant"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	configFile := flag.String("config", "config.yaml", "configuration file with the database hosting the tenants")
+	code := flag.String("code", "", "code of the tenant, also its subdomain")
+	name := flag.String("name", "", "name of the business, the code when empty")
+	schema := flag.String("schema", "", "postgres schema of the tenant, tenant_<code> when empty")
+	schemaDir := flag.String("schema-dir", "schema", "directory holding tenant.sql, table.sql and seed.sql")
+	seed := flag.Bool("seed", true, "run seed.sql after table.sql")
+	flag.Parse()
+
+	if *code == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.New(*configFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+	global.CONFIG = cfg
+
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+	global.DBCON = db
+
+	files := []string{"table.sql"}
+	if *seed {
+		files = append(files, "seed.sql")
+	}
+	var scripts []string
+	for _, file := range files {
+		script, err := os.ReadFile(filepath.Join(*schemaDir, file))
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(2)
+		}
+		scripts = append(scripts, string(script))
+	}
+
+	ctx := context.Background()
+	repo := tenantrepo.New()
+	registry, err := os.ReadFile(filepath.Join(*schemaDir, "tenant.sql"))
+	if err == nil {
+		err = repo.RunScript(ctx, string(registry))
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+
+	tenant, err := tenantusecase.New(repo).Provision(ctx, tenantdomain.ProvisionRequest{
+		Code:    *code,
+		Name:    *name,
+		Schema:  *schema,
+		Scripts: scripts,
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("tenant %s provisioned in schema %s\n", tenant.Code, tenant.Schema)
+}
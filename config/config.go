@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
 	yaml "gopkg.in/yaml.v2"
@@ -26,6 +27,8 @@ type Config struct {
 	Server    Server    `yaml:"server"`
 	Database  DBConfig  `yaml:"database"`
 	LogConfig LogConfig `yaml:"log_config"`
+	Storage   Storage   `yaml:"storage"`
+	Tenant    Tenant    `yaml:"tenant"`
 }
 
 type DBConfig struct {
@@ -36,6 +39,11 @@ type DBConfig struct {
 	DBName   string `yaml:"dbname"`
 }
 
+// DSN returns the connection string of the database
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", c.Host, c.User, c.Password, c.DBName, c.Port)
+}
+
 type Server struct {
 	HTTP HTTP `yaml:"http"`
 }
@@ -50,4 +58,24 @@ type HTTP struct {
 
 type LogConfig struct {
 	LogFilename string `yaml:"log_filename"`
+	Output      string `yaml:"output"` // file, stdout or both
+	MaxSizeMB   int    `yaml:"max_size_mb"`
+	MaxBackups  int    `yaml:"max_backups"`
+	MaxAgeDays  int    `yaml:"max_age_days"`
+	Compress    bool   `yaml:"compress"`
+}
+
+// Storage defines where uploaded files are kept
+type Storage struct {
+	Type      string `yaml:"type"` // local, the default
+	LocalPath string `yaml:"local_path"`
+}
+
+// Tenant defines how a request is routed to the schema of its business when several share the deployment.
+// A request is served by the tenant named in Header, else by the subdomain of Domain it was sent to,
+// else by the default tenant in the public schema.
+type Tenant struct {
+	Enabled bool   `yaml:"enabled"`
+	Header  string `yaml:"header"` // X-Tenant when empty
+	Domain  string `yaml:"domain"` // e.g. pos.example.com serves tenant abc at abc.pos.example.com
 }
@@ -11,4 +11,3 @@ var DBCON *gorm.DB
 var LOGIN_URL string
 var FORBIDDEN_URL string
 var UNAUTHORIZED_URL string
-var SESSION_TIMEOUT_MINUTE int
@@ -0,0 +1,29 @@
+package apikeydomain
+
+import (
+	"time"
+)
+
+// ApiKey lets a service account call the api without logging in, the key itself is only shown when issued
+type ApiKey struct {
+	ID        string `json:"id"`
+	WebUserID string `json:"userId"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	KeyHash   string `json:"-"`
+	// Permissions narrows the role of the service account, empty grants everything the role grants
+	Permissions  []string   `json:"permissions"`
+	ExpiredTime  *time.Time `json:"expiredTime"`
+	LastUsedTime *time.Time `json:"lastUsedTime"`
+	LastUsedIP   string     `json:"lastUsedIp"`
+	CreatedBy    string     `json:"createdBy"`
+	CreatedTime  time.Time  `json:"createdTime"`
+	RotatedTime  *time.Time `json:"rotatedTime"`
+	RevokedTime  *time.Time `json:"revokedTime"`
+}
+
+// IssuedKey is returned once when a key is created or rotated
+type IssuedKey struct {
+	ApiKey *ApiKey `json:"apiKey"`
+	Key    string  `json:"key"`
+}
@@ -0,0 +1,25 @@
+package attachmentdomain
+
+import "time"
+
+// records an attachment can be linked to, the names follow the entities of the mobile sync
+const (
+	OWNER_TYPE_BELANJA       = "belanja"
+	OWNER_TYPE_OPERASIONAL   = "operasional"
+	OWNER_TYPE_DANA_TRANSFER = "danaTransfer"
+)
+
+// Attachment is a file proving a mobile record, e.g. the photo of a receipt
+type Attachment struct {
+	ID           string    `json:"id"`
+	OwnerType    string    `json:"ownerType"`
+	OwnerID      string    `json:"ownerId"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	HasThumbnail bool      `json:"hasThumbnail"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	UploadedBy   string    `json:"uploadedBy"`
+	UploadedTime time.Time `json:"uploadedTime"`
+}
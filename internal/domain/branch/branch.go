@@ -0,0 +1,23 @@
+package branchdomain
+
+// SortFields are the fields the branch list can be sorted by
+var SortFields = []string{"code", "name"}
+
+// Branch keeps its books apart from the other branches, CodePrefix is put in front of the transaction and kontrabon codes
+type Branch struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	CodePrefix string `json:"codePrefix"`
+	Address    string `json:"address"`
+	Active     bool   `json:"active"`
+}
+
+type BranchRequest struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	CodePrefix string `json:"codePrefix"`
+	Address    string `json:"address"`
+	Active     *bool  `json:"active"`
+}
@@ -7,12 +7,35 @@ type Config struct {
 	Value string `json:"value"`
 }
 
+// ConfigCache keeps the config of every tenant, DataMap is by tenant code then config id
 type ConfigCache struct {
 	sync.RWMutex
-	DataMap map[string]*Config
+	DataMap map[string]map[string]*Config
 }
 
 const LOGIN_URL = "LOGIN_URL"
 const FORBIDDEN_URL = "FORBIDDEN_URL"
 const UNAUTHORIZED_URL = "UNAUTHORIZED_URL"
 const SESSION_TIMEOUT_MINUTE = "SESSION_TIMEOUT_MINUTE"
+const LOGIN_MAX_ATTEMPT = "LOGIN_MAX_ATTEMPT"
+const LOGIN_LOCK_MINUTE = "LOGIN_LOCK_MINUTE"
+const LOGIN_IP_MAX_ATTEMPT = "LOGIN_IP_MAX_ATTEMPT"
+const LOGIN_BACKOFF_SECOND = "LOGIN_BACKOFF_SECOND"
+const LOGIN_BACKOFF_MAX_SECOND = "LOGIN_BACKOFF_MAX_SECOND"
+const TOTP_ISSUER = "TOTP_ISSUER"
+const TOTP_CHALLENGE_MINUTE = "TOTP_CHALLENGE_MINUTE"
+const PASSWORD_MIN_LENGTH = "PASSWORD_MIN_LENGTH"
+const PASSWORD_REQUIRE_MIXED_CASE = "PASSWORD_REQUIRE_MIXED_CASE"
+const PASSWORD_REQUIRE_DIGIT = "PASSWORD_REQUIRE_DIGIT"
+const PASSWORD_REQUIRE_SYMBOL = "PASSWORD_REQUIRE_SYMBOL"
+const PASSWORD_HISTORY_COUNT = "PASSWORD_HISTORY_COUNT"
+const PASSWORD_EXPIRY_DAY = "PASSWORD_EXPIRY_DAY"
+const ATTACHMENT_MAX_SIZE_KB = "ATTACHMENT_MAX_SIZE_KB"
+const DANA_TRANSFER_EXPIRE_HOUR = "DANA_TRANSFER_EXPIRE_HOUR"
+const DANA_TRANSFER_SWEEP_MINUTE = "DANA_TRANSFER_SWEEP_MINUTE"
+const PPN_RATE = "PPN_RATE"
+const TAX_ROUNDING = "TAX_ROUNDING"
+const TAX_ROUNDING_UNIT = "TAX_ROUNDING_UNIT"
+const EFAKTUR_TRANSACTION_CODE = "EFAKTUR_TRANSACTION_CODE"
+const EFAKTUR_SERIAL_START = "EFAKTUR_SERIAL_START"
+const EFAKTUR_SERIAL_END = "EFAKTUR_SERIAL_END"
@@ -6,6 +6,9 @@ import (
 	"time"
 )
 
+// SortFields are the fields the list endpoint can be sorted by
+var SortFields = []string{"code", "name"}
+
 type Customer struct {
 	ID            string `json:"id"`
 	Code          string `json:"code"`
@@ -13,6 +16,16 @@ type Customer struct {
 	Description   string `json:"description"`
 	Active        bool   `json:"active"`
 	InitialCredit int64  `json:"initialCredit"`
+	Npwp          string `json:"npwp"`
+	Address       string `json:"address"`
+	Tax
+}
+
+// Tax is how the PPN of a customer's transactions is worked out, a nil TaxRate takes the rate of the product or PPN_RATE
+type Tax struct {
+	Taxable      bool     `json:"taxable"`
+	TaxInclusive bool     `json:"taxInclusive"`
+	TaxRate      *float64 `json:"taxRate"`
 }
 
 type AddPriceRequest struct {
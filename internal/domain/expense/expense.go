@@ -0,0 +1,56 @@
+package expensedomain
+
+// CategorySortFields are the fields the category list can be sorted by
+var CategorySortFields = []string{"code", "name"}
+
+// BudgetSortFields are the fields the budget list can be sorted by
+var BudgetSortFields = []string{"category", "user", "period", "amount"}
+
+// periods of a budget, a daily budget counts the date of the operasional and a monthly one its month
+const (
+	PERIOD_DAILY   = "daily"
+	PERIOD_MONTHLY = "monthly"
+)
+
+// modes of a budget, a warning still saves the operasional
+const (
+	MODE_WARN   = "warn"
+	MODE_REJECT = "reject"
+)
+
+type Category struct {
+	ID     string `json:"id"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// Budget caps the operasional of a period, an empty category covers every category and an empty user every user together
+type Budget struct {
+	ID           string  `json:"id"`
+	CategoryID   string  `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	WebUserID    string  `json:"webUserId"`
+	UserName     string  `json:"userName"`
+	Period       string  `json:"period"`
+	Amount       float64 `json:"amount"`
+	Mode         string  `json:"mode"`
+	Active       bool    `json:"active"`
+}
+
+type BudgetRequest struct {
+	ID         string  `json:"id"`
+	CategoryID string  `json:"categoryId"`
+	WebUserID  string  `json:"webUserId"`
+	Period     string  `json:"period"`
+	Amount     float64 `json:"amount"`
+	Mode       string  `json:"mode"`
+	Active     *bool   `json:"active"`
+}
+
+// BudgetUsage is a budget an operasional goes over, Spent includes the operasional
+type BudgetUsage struct {
+	Budget
+	Spent   float64 `json:"spent"`
+	Message string  `json:"message"`
+}
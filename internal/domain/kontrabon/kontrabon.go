@@ -5,6 +5,9 @@ import "time"
 const STATUS_CREATED = "CREATED"
 const STATUS_LUNAS = "LUNAS"
 
+// SortFields are the fields the list endpoint can be sorted by
+var SortFields = []string{"code", "createdTime", "status"}
+
 type Kontrabon struct {
 	ID          string    `json:"id"`
 	Code        string    `json:"code"`
@@ -0,0 +1,46 @@
+package notificationdomain
+
+import "time"
+
+// types of a notification, the event name of the stream
+const (
+	TYPE_DANA_TRANSFER_PENDING  = "danaTransferPending"
+	TYPE_DANA_TRANSFER_APPROVED = "danaTransferApproved"
+	TYPE_DANA_TRANSFER_REJECTED = "danaTransferRejected"
+	TYPE_DANA_TRANSFER_CANCELED = "danaTransferCanceled"
+	TYPE_DANA_TRANSFER_EXPIRED  = "danaTransferExpired"
+)
+
+// Notification is sent to a user, the id increases so it is also the event id of the stream
+type Notification struct {
+	ID           int64                     `json:"id"`
+	WebUserID    string                    `json:"-"`
+	Type         string                    `json:"type"`
+	EntityID     string                    `json:"entityId"`
+	DanaTransfer *DanaTransferNotification `json:"danaTransfer,omitempty"`
+	Read         bool                      `json:"read"`
+	CreatedTime  time.Time                 `json:"createdTime"`
+}
+
+// DanaTransferNotification is the transfer as it was when the notification was made
+type DanaTransferNotification struct {
+	ID           string    `json:"id"`
+	Date         time.Time `json:"date"`
+	Sender       string    `json:"sender"`
+	SenderName   string    `json:"senderName"`
+	Receiver     string    `json:"receiver"`
+	ReceiverName string    `json:"receiverName"`
+	Amount       float64   `json:"amount"`
+	Status       string    `json:"status"`
+}
+
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int64          `json:"unread"`
+}
+
+// MarkReadRequest marks the notifications of the ids as read, or every notification of the user with All
+type MarkReadRequest struct {
+	IDs []int64 `json:"ids"`
+	All bool    `json:"all"`
+}
@@ -1,5 +1,8 @@
 package productdomain
 
+// SortFields are the fields the list endpoint can be sorted by
+var SortFields = []string{"code", "name", "unit"}
+
 type Product struct {
 	ID          string `json:"id"`
 	Code        string `json:"code"`
@@ -8,4 +11,6 @@ type Product struct {
 	Description string `json:"description"`
 	Active      bool   `json:"active"`
 	UnitID      string `json:"unitId"`
+	// TaxRate is the PPN percentage of the product, nil follows the rate of the customer or PPN_RATE
+	TaxRate *float64 `json:"taxRate"`
 }
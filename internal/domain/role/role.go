@@ -5,9 +5,12 @@ import (
 )
 
 type Role struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Active      bool     `json:"active"`
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Active           bool   `json:"active"`
+	RequireTwoFactor bool   `json:"requireTwoFactor"`
+	// AllData lets the role read every customer and supplier, otherwise users only read the ones assigned to them
+	AllData     bool     `json:"allData"`
 	Permissions []string `json:"permissions"`
 }
 
@@ -27,3 +30,10 @@ type Permission struct {
 	SubMenu string `json:"subMenu"`
 	Name    string `json:"name"`
 }
+
+// PermissionApi grants Method (* for any) on Path, a gin route path or a prefix ending in /*
+type PermissionApi struct {
+	PermissionID string `json:"permissionId"`
+	Method       string `json:"method"`
+	Path         string `json:"path"`
+}
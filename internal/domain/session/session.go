@@ -1,11 +1,14 @@
 package sessiondomain
 
 import (
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	permissionutil "dromatech/pos-backend/internal/util/permission"
 	"sync"
 	"time"
 )
 
 type Menu struct {
+	ID      string     `json:"-"`
 	Name    string     `json:"name"`
 	Icon    string     `json:"icon"`
 	Path    string     `json:"path"`
@@ -13,22 +16,133 @@ type Menu struct {
 }
 
 type SubMenu struct {
+	ID          string   `json:"-"`
 	Name        string   `json:"name"`
 	Outcome     string   `json:"outcome"`
 	Icon        string   `json:"icon"`
 	Permissions []string `json:"-"`
 }
 
+const LOGOUT_REASON_LOGOUT = "LOGOUT"
+const LOGOUT_REASON_EXPIRED = "EXPIRED"
+const LOGOUT_REASON_REVOKED = "REVOKED"
+const LOGOUT_REASON_DEACTIVATED = "DEACTIVATED"
+const LOGOUT_REASON_REFRESH_FAILED = "REFRESH_FAILED"
+
+const FAILURE_REASON_UNKNOWN_USER = "UNKNOWN_USER"
+const FAILURE_REASON_INVALID_PASSWORD = "INVALID_PASSWORD"
+const FAILURE_REASON_INACTIVE = "INACTIVE"
+const FAILURE_REASON_LOCKED = "LOCKED"
+const FAILURE_REASON_THROTTLED = "THROTTLED"
+const FAILURE_REASON_INVALID_TOTP = "INVALID_TOTP"
+const FAILURE_REASON_SERVICE_ACCOUNT = "SERVICE_ACCOUNT"
+
+// HistorySortFields are the fields the login history can be sorted by
+var HistorySortFields = []string{"loginTime", "username"}
+
 type Session struct {
-	Token       string    `json:"token"`
-	ExpiredTime time.Time `json:"-"`
-	UserID      string    `json:"-"`
-	RoleID      string    `json:"-"`
-	UserName    string    `json:"username"`
-	Name        string    `json:"name"`
-	RoleName    string    `json:"roleName"`
-	Menu        []*Menu   `json:"menu"`
-	Permissions []string  `json:"-"`
+	ID           string                  `json:"-"`
+	Token        string                  `json:"token"`
+	ExpiredTime  time.Time               `json:"-"`
+	LoginTime    time.Time               `json:"-"`
+	LastActivity time.Time               `json:"-"`
+	ClientIP     string                  `json:"-"`
+	UserAgent    string                  `json:"-"`
+	UserID       string                  `json:"-"`
+	RoleID       string                  `json:"-"`
+	UserName     string                  `json:"username"`
+	Name         string                  `json:"name"`
+	RoleName     string                  `json:"roleName"`
+	Language     string                  `json:"language"`
+	Menu         []*Menu                 `json:"menu"`
+	Access       *permissionutil.Matcher `json:"-"`
+	// TwoFactorSetupRequired limits the session to the whitelisted paths until the user enrols TOTP required by the role
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired"`
+	TwoFactorEnabled       bool `json:"-"`
+	// AllData is false when the role restricts the user to the assigned customers and suppliers
+	AllData bool `json:"-"`
+	// PasswordChangeRequired limits the session to changing the password
+	PasswordChangeRequired bool `json:"passwordChangeRequired"`
+	// BranchID is the active branch the branch scoped data is read and written for, empty for a user without branches who reads every branch
+	BranchID   string                 `json:"branchId"`
+	BranchName string                 `json:"branchName"`
+	Branches   []*branchdomain.Branch `json:"branches"`
+	// Tenant is the business the session was opened in, the session is valid in that tenant only
+	Tenant string `json:"-"`
+}
+
+// SessionInfo is an active session as listed to admins, without the token
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"userId"`
+	UserName     string    `json:"username"`
+	Name         string    `json:"name"`
+	RoleName     string    `json:"roleName"`
+	LoginTime    time.Time `json:"loginTime"`
+	LastActivity time.Time `json:"lastActivity"`
+	ClientIP     string    `json:"clientIp"`
+	UserAgent    string    `json:"userAgent"`
+}
+
+type LoginHistory struct {
+	ID            string     `json:"id"`
+	WebUserID     string     `json:"webUserId"`
+	Username      string     `json:"username"`
+	Success       bool       `json:"success"`
+	FailureReason string     `json:"failureReason"`
+	ClientIP      string     `json:"clientIp"`
+	UserAgent     string     `json:"userAgent"`
+	LoginTime     time.Time  `json:"loginTime"`
+	LogoutTime    *time.Time `json:"logoutTime"`
+	LogoutReason  string     `json:"logoutReason"`
+}
+
+// LoginAttempt tracks consecutive failed logins of a username or client IP
+type LoginAttempt struct {
+	Failures    int
+	LastFailure time.Time
+	// ExpiredTime is the end of the lock window of the tenant the attempt was made in
+	ExpiredTime time.Time
+}
+
+type LoginAttemptCache struct {
+	sync.Mutex
+	DataMap map[string]*LoginAttempt
+}
+
+// LoginChallenge is the second login step of a user with two-factor authentication
+type LoginChallenge struct {
+	Token             string    `json:"challengeToken"`
+	TwoFactorRequired bool      `json:"twoFactorRequired"`
+	ExpiredTime       time.Time `json:"expiredTime"`
+	UserID            string    `json:"-"`
+	Username          string    `json:"-"`
+	Attempts          int       `json:"-"`
+}
+
+type LoginChallengeCache struct {
+	sync.Mutex
+	DataMap map[string]*LoginChallenge
+}
+
+// RoleAccessCache holds the permission matcher of each role, built once and shared by its sessions
+type RoleAccessCache struct {
+	sync.RWMutex
+	DataMap map[string]*permissionutil.Matcher
+}
+
+// ApiKeyAccess is a verified api key with the session its requests run as
+type ApiKeyAccess struct {
+	KeyHash      string
+	ExpiredTime  *time.Time
+	LastUsedTime time.Time
+	Session      *Session
+}
+
+// ApiKeyCache holds the api keys in use by key id, entries are dropped when the key, its user or its role changes
+type ApiKeyCache struct {
+	sync.Mutex
+	DataMap map[string]*ApiKeyAccess
 }
 
 type SessionCache struct {
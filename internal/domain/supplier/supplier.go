@@ -6,6 +6,9 @@ import (
 	"time"
 )
 
+// SortFields are the fields the list endpoint can be sorted by
+var SortFields = []string{"code", "name"}
+
 type Supplier struct {
 	ID          string `json:"id"`
 	Code        string `json:"code"`
@@ -0,0 +1,32 @@
+package tenantdomain
+
+import (
+	"sync"
+	"time"
+)
+
+// Tenant is a business hosted on the deployment, its data is kept in its own postgres schema
+type Tenant struct {
+	ID          string    `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Schema      string    `json:"schema"`
+	Active      bool      `json:"active"`
+	CreatedTime time.Time `json:"createdTime"`
+}
+
+// ProvisionRequest creates a tenant, an empty Schema is derived from the code
+type ProvisionRequest struct {
+	Code   string
+	Name   string
+	Schema string
+	// Scripts are run in order on the new schema, the table script then the seed
+	Scripts []string
+}
+
+type TenantCache struct {
+	sync.RWMutex
+	DataMap map[string]*Tenant
+	// ReadTime is when each tenant was read from the registry
+	ReadTime map[string]time.Time
+}
@@ -0,0 +1,54 @@
+package transactiondomain
+
+import "time"
+
+// mobile records checked against the closing of their day before they are removed
+const (
+	MobileEntryPenjualan   = "penjualan_tunai"
+	MobileEntryBelanja     = "belanja"
+	MobileEntryOperasional = "operasional"
+)
+
+// CashClosing is the saldo of a mobile user snapshotted at the end of a day, the day cannot be edited while it is closed
+type CashClosing struct {
+	ID        string    `json:"id"`
+	Date      time.Time `json:"date"`
+	WebUserID string    `json:"webUserId"`
+	Name      string    `json:"name"`
+	BranchID  string    `json:"branchId"`
+	SaldoResponse
+	ClosedBy   string    `json:"closedBy"`
+	ClosedTime time.Time `json:"closedTime"`
+}
+
+// CashClosingReopen is the audit record left when a supervisor reopens a closed day
+type CashClosingReopen struct {
+	ID           string    `json:"id"`
+	Date         time.Time `json:"date"`
+	WebUserID    string    `json:"webUserId"`
+	Name         string    `json:"name"`
+	BranchID     string    `json:"branchId"`
+	SaldoAkhir   float64   `json:"saldoAkhir"`
+	ClosedBy     string    `json:"closedBy"`
+	ClosedTime   time.Time `json:"closedTime"`
+	Reason       string    `json:"reason"`
+	ReopenedBy   string    `json:"reopenedBy"`
+	ReopenedTime time.Time `json:"reopenedTime"`
+}
+
+type CashClosingRequest struct {
+	Date string `json:"date"`
+}
+
+type CashClosingReopenRequest struct {
+	UserID string `json:"userId"`
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+// MobileEntry is the owner and day of a mobile record, used to check the day is still open before the record is removed
+type MobileEntry struct {
+	ID        string
+	Date      time.Time
+	WebUserID string
+}
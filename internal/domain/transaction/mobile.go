@@ -1,6 +1,10 @@
 package transactiondomain
 
-import "time"
+import (
+	attachmentdomain "dromatech/pos-backend/internal/domain/attachment"
+	expensedomain "dromatech/pos-backend/internal/domain/expense"
+	"time"
+)
 
 type DanaStatus string
 
@@ -9,6 +13,8 @@ const (
 	DanaStatusApproved DanaStatus = "approved"
 	DanaStatusRejected DanaStatus = "rejected"
 	DanaStatusCanceled DanaStatus = "canceled"
+	// set by the sweeper on a transfer left pending past DANA_TRANSFER_EXPIRE_HOUR
+	DanaStatusExpired DanaStatus = "expired"
 )
 
 type Belanja struct {
@@ -40,6 +46,40 @@ type DanaTransaction struct {
 	Amount      float64    `json:"amount"`
 	Status      DanaStatus `json:"status"`
 	CreatedTime time.Time  `json:"createdTime"`
+	// InsertedTime is when the server saved the transfer, the expiry counts from it
+	InsertedTime time.Time `json:"-"`
+}
+
+// DanaBulkRequest lists the transfers a bulk approve or reject decides
+type DanaBulkRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// DanaBulkResult is the outcome of one transfer of a bulk decision, a failed one leaves the transfer as it was
+type DanaBulkResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+type DanaBulkResponse struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []DanaBulkResult `json:"results"`
+}
+
+// SupervisedDanaTransfer is a transfer between users the supervisor manages, DecidedBy is the receiver or the supervisor who overrode it
+type SupervisedDanaTransfer struct {
+	ID           string     `json:"id"`
+	Date         time.Time  `json:"date"`
+	Sender       string     `json:"sender"`
+	SenderName   string     `json:"senderName"`
+	Receiver     string     `json:"receiver"`
+	ReceiverName string     `json:"receiverName"`
+	Amount       float64    `json:"amount"`
+	Status       DanaStatus `json:"status"`
+	DecidedBy    string     `json:"decidedBy,omitempty"`
+	CreatedTime  time.Time  `json:"createdTime"`
 }
 
 type PenjualanTunai struct {
@@ -58,6 +98,7 @@ type Operasional struct {
 	ID          string    `json:"id"`
 	Date        time.Time `json:"date"`
 	WebUserID   string    `json:"webUserId"`
+	CategoryID  string    `json:"categoryId"`
 	Description string    `json:"description"`
 	Quantity    int16     `json:"quantity"`
 	Price       float64   `json:"price"`
@@ -69,12 +110,17 @@ type DanaRequest struct {
 	Date         string  `json:"date"`
 	SaldoAwal    float64 `json:"saldoAwal"`
 	DanaTambahan float64 `json:"danaTambahan"`
+	// set by the offline sync to keep the client time, a new record gets its id and time on the server otherwise
+	CreatedTime *time.Time `json:"-"`
 }
 
 type DanaTransactionRequest struct {
 	Date     string  `json:"date"`
 	Receiver string  `json:"receiver"`
 	Amount   float64 `json:"amount"`
+	// set by the offline sync to keep the client id and time, a new record gets its id and time on the server otherwise
+	ID          string     `json:"-"`
+	CreatedTime *time.Time `json:"-"`
 }
 
 type DanaInquiryResponse struct {
@@ -91,6 +137,8 @@ type DanaTransactionInquiryResponse struct {
 	Amount      float64    `json:"amount"`
 	Status      DanaStatus `json:"status"`
 	CreatedTime time.Time  `json:"createdTime"`
+
+	Attachments []attachmentdomain.Attachment `json:"attachments,omitempty"`
 }
 
 type WebUserMobile struct {
@@ -103,6 +151,9 @@ type TrxCreateRequest struct {
 	ProductID string  `json:"productId"`
 	Quantity  int16   `json:"quantity"`
 	Price     float64 `json:"price"`
+	// set by the offline sync to keep the client id and time, a new record gets its id and time on the server otherwise
+	ID          string     `json:"-"`
+	CreatedTime *time.Time `json:"-"`
 }
 
 type TrxInquiryResponse struct {
@@ -110,20 +161,36 @@ type TrxInquiryResponse struct {
 	ProductName string  `json:"productName"`
 	Quantity    int16   `json:"quantity"`
 	Price       float64 `json:"price"`
+
+	Attachments []attachmentdomain.Attachment `json:"attachments,omitempty"`
 }
 
 type TrxCreateOperasionalRequest struct {
 	Date        string  `json:"date"`
+	CategoryID  string  `json:"categoryId"`
 	Description string  `json:"description"`
 	Quantity    int16   `json:"quantity"`
 	Price       float64 `json:"price"`
+	// set by the offline sync to keep the client id and time, a new record gets its id and time on the server otherwise
+	ID          string     `json:"-"`
+	CreatedTime *time.Time `json:"-"`
 }
 
 type TrxInquiryOperasionalResponse struct {
-	ID          string  `json:"id"`
-	Description string  `json:"description"`
-	Quantity    int16   `json:"quantity"`
-	Price       float64 `json:"price"`
+	ID           string  `json:"id"`
+	CategoryID   string  `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	Description  string  `json:"description"`
+	Quantity     int16   `json:"quantity"`
+	Price        float64 `json:"price"`
+
+	Attachments []attachmentdomain.Attachment `json:"attachments,omitempty"`
+}
+
+// OperasionalCreateResponse carries the budgets in warn mode the new operasional goes over
+type OperasionalCreateResponse struct {
+	ID       string                      `json:"id"`
+	Warnings []expensedomain.BudgetUsage `json:"warnings"`
 }
 
 type SaldoResponse struct {
@@ -0,0 +1,37 @@
+package transactiondomain
+
+import "time"
+
+// ReconciliationItem compares what the customers ordered of a product on a date with what was bought for it,
+// purchases are the belanja of the mobile users and the transaction_buy of the web
+type ReconciliationItem struct {
+	Date                   time.Time `json:"date"`
+	ProductID              string    `json:"productId"`
+	ProductCode            string    `json:"productCode"`
+	ProductName            string    `json:"productName"`
+	OrderedQuantity        float64   `json:"orderedQuantity"`
+	BelanjaQuantity        float64   `json:"belanjaQuantity"`
+	BelanjaTotal           float64   `json:"belanjaTotal"`
+	TransactionBuyQuantity float64   `json:"transactionBuyQuantity"`
+	TransactionBuyTotal    float64   `json:"transactionBuyTotal"`
+	PurchasedQuantity      float64   `json:"purchasedQuantity"`
+	// Difference is purchased minus ordered, negative when less was bought than ordered
+	Difference      float64 `json:"difference"`
+	AverageBuyPrice float64 `json:"averageBuyPrice"`
+}
+
+type ReconciliationResponse struct {
+	StartDate time.Time            `json:"startDate"`
+	EndDate   time.Time            `json:"endDate"`
+	Items     []ReconciliationItem `json:"items"`
+}
+
+// ReconciliationApplyRequest sets the average buy price of the products of a date on the sell transactions, an empty list applies every product
+type ReconciliationApplyRequest struct {
+	Date       string   `json:"date"`
+	ProductIDs []string `json:"productIds"`
+}
+
+type ReconciliationApplyResponse struct {
+	Updated int `json:"updated"`
+}
@@ -0,0 +1,85 @@
+package transactiondomain
+
+import "time"
+
+// buckets of the range rekapitulasi, a week starts on Monday
+const (
+	RekapBucketDay   = "day"
+	RekapBucketWeek  = "week"
+	RekapBucketMonth = "month"
+)
+
+// RekapAmount is the money a mobile user moved, only approved transfers are counted
+type RekapAmount struct {
+	Penjualan    float64 `json:"penjualan"`
+	Belanja      float64 `json:"belanja"`
+	Operasional  float64 `json:"operasional"`
+	DanaTambahan float64 `json:"danaTambahan"`
+	DanaMasuk    float64 `json:"danaMasuk"`
+	DanaKeluar   float64 `json:"danaKeluar"`
+}
+
+// RekapPeriod is the rekap of a user in a bucket, Period is the first date of the bucket
+type RekapPeriod struct {
+	Period    time.Time `json:"period"`
+	WebUserID string    `json:"webUserId"`
+	Name      string    `json:"name"`
+	RekapAmount
+}
+
+// RekapUser is the rekap of a user over the whole range
+type RekapUser struct {
+	WebUserID string `json:"webUserId"`
+	Name      string `json:"name"`
+	RekapAmount
+}
+
+// RekapProduct is the belanja of a product by a user over the whole range
+type RekapProduct struct {
+	WebUserID    string  `json:"webUserId"`
+	Name         string  `json:"name"`
+	ProductID    string  `json:"productId"`
+	ProductCode  string  `json:"productCode"`
+	ProductName  string  `json:"productName"`
+	Quantity     int64   `json:"quantity"`
+	Total        float64 `json:"total"`
+	AveragePrice float64 `json:"averagePrice"`
+}
+
+// RekapCategory is the operasional of a user in an expense category over the whole range, an empty category is the operasional without one
+type RekapCategory struct {
+	WebUserID    string  `json:"webUserId"`
+	Name         string  `json:"name"`
+	CategoryID   string  `json:"categoryId"`
+	CategoryCode string  `json:"categoryCode"`
+	CategoryName string  `json:"categoryName"`
+	Count        int64   `json:"count"`
+	Total        float64 `json:"total"`
+}
+
+type RekapRangeResponse struct {
+	StartDate  time.Time       `json:"startDate"`
+	EndDate    time.Time       `json:"endDate"`
+	Bucket     string          `json:"bucket"`
+	Periods    []RekapPeriod   `json:"periods"`
+	Users      []RekapUser     `json:"users"`
+	Products   []RekapProduct  `json:"products"`
+	Categories []RekapCategory `json:"categories"`
+	Total      RekapAmount     `json:"total"`
+}
+
+// RekapBranch is the rekap of a branch over the whole range, an empty branch holds the records made without one
+type RekapBranch struct {
+	BranchID   string `json:"branchId"`
+	BranchCode string `json:"branchCode"`
+	BranchName string `json:"branchName"`
+	RekapAmount
+}
+
+// RekapBranchResponse is the consolidated rekap of every branch for head office
+type RekapBranchResponse struct {
+	StartDate time.Time     `json:"startDate"`
+	EndDate   time.Time     `json:"endDate"`
+	Branches  []RekapBranch `json:"branches"`
+	Total     RekapAmount   `json:"total"`
+}
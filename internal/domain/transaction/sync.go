@@ -0,0 +1,125 @@
+package transactiondomain
+
+import "time"
+
+// entities of the offline sync, the change feed uses the same names
+const (
+	SyncEntityDana         = "dana"
+	SyncEntityDanaTransfer = "danaTransfer"
+	SyncEntityPenjualan    = "penjualan"
+	SyncEntityBelanja      = "belanja"
+	SyncEntityOperasional  = "operasional"
+	SyncEntityClosing      = "closing"
+)
+
+const (
+	SyncActionUpsert = "upsert"
+	SyncActionDelete = "delete"
+)
+
+// status of a pushed record, a duplicate was already applied by an earlier push and is not applied again, a failed
+// record stopped the push and the records after it are pushed again
+const (
+	SyncStatusApplied   = "applied"
+	SyncStatusDuplicate = "duplicate"
+	SyncStatusConflict  = "conflict"
+	SyncStatusInvalid   = "invalid"
+	SyncStatusFailed    = "failed"
+)
+
+// reason of a conflict, the client keeps the record until the conflict is resolved on the server
+const (
+	SyncReasonClosed     = "closed"
+	SyncReasonRejected   = "rejected"
+	SyncReasonCanceled   = "canceled"
+	SyncReasonNotOwner   = "notOwner"
+	SyncReasonNotAllowed = "notAllowed"
+	SyncReasonRefused    = "refused"
+	SyncReasonExpired    = "expired"
+	SyncReasonModified   = "modified"
+)
+
+// SyncPushRequest carries the records recorded offline, each list is applied in order
+type SyncPushRequest struct {
+	Dana         []SyncDana         `json:"dana"`
+	DanaTransfer []SyncDanaTransfer `json:"danaTransfer"`
+	Penjualan    []SyncTrx          `json:"penjualan"`
+	Belanja      []SyncTrx          `json:"belanja"`
+	Operasional  []SyncOperasional  `json:"operasional"`
+}
+
+// SyncDana sets the dana of a date, the server keeps its own id when the date already has dana
+type SyncDana struct {
+	ID           string     `json:"id"`
+	Date         string     `json:"date"`
+	SaldoAwal    float64    `json:"saldoAwal"`
+	DanaTambahan float64    `json:"danaTambahan"`
+	ClientTime   *time.Time `json:"clientTime"`
+}
+
+type SyncDanaTransfer struct {
+	ID         string     `json:"id"`
+	Date       string     `json:"date"`
+	Receiver   string     `json:"receiver"`
+	Amount     float64    `json:"amount"`
+	ClientTime *time.Time `json:"clientTime"`
+}
+
+type SyncTrx struct {
+	ID         string     `json:"id"`
+	Date       string     `json:"date"`
+	ProductID  string     `json:"productId"`
+	Quantity   int16      `json:"quantity"`
+	Price      float64    `json:"price"`
+	ClientTime *time.Time `json:"clientTime"`
+	Deleted    bool       `json:"deleted"`
+}
+
+type SyncOperasional struct {
+	ID          string     `json:"id"`
+	Date        string     `json:"date"`
+	CategoryID  string     `json:"categoryId"`
+	Description string     `json:"description"`
+	Quantity    int16      `json:"quantity"`
+	Price       float64    `json:"price"`
+	ClientTime  *time.Time `json:"clientTime"`
+	Deleted     bool       `json:"deleted"`
+}
+
+type SyncResult struct {
+	Entity string `json:"entity"`
+	ID     string `json:"id"`
+	// ServerID is the id the server keeps when it differs from the client id
+	ServerID string `json:"serverId,omitempty"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type SyncPushResponse struct {
+	Results []SyncResult `json:"results"`
+}
+
+// SyncChange is the latest change of a record, Data is the record as it is now and empty when deleted
+type SyncChange struct {
+	Seq         int64       `json:"seq"`
+	TxID        int64       `json:"-"`
+	Entity      string      `json:"entity"`
+	ID          string      `json:"id"`
+	Action      string      `json:"action"`
+	ChangedTime time.Time   `json:"changedTime"`
+	Data        interface{} `json:"data"`
+}
+
+// SyncChangesResponse pages the change feed, Cursor is passed back to read the next page
+type SyncChangesResponse struct {
+	Changes []SyncChange `json:"changes"`
+	Cursor  string       `json:"cursor"`
+	HasMore bool         `json:"hasMore"`
+}
+
+// SyncCursor is the last change read, the feed is ordered by the transaction of a change then its seq
+type SyncCursor struct {
+	TxID int64
+	Seq  int64
+}
@@ -14,6 +14,10 @@ const TRANSACTION_KONTRABON = "KONTRABON"
 const TRANSACTION_DIBAYAR = "DIBAYAR"
 const TRANSACTION_BATAL = "BATAL"
 
+// DISCOUNT_PERCENT takes the discount value as a percentage of the price, DISCOUNT_AMOUNT as an amount of money
+const DISCOUNT_PERCENT = "PERCENT"
+const DISCOUNT_AMOUNT = "AMOUNT"
+
 type Transaction struct {
 	ID                string               `json:"id"`
 	Code              string               `json:"code"`
@@ -25,23 +29,40 @@ type Transaction struct {
 	UserId            string               `json:"userId"`
 	CreatedTime       time.Time            `json:"createdTime"`
 	Total             float64              `json:"total"`
+	DiscountType      string               `json:"discountType"`
+	DiscountValue     float64              `json:"discountValue"`
+	TaxInclusive      bool                 `json:"taxInclusive"`
+	Subtotal          float64              `json:"subtotal"`
+	Discount          float64              `json:"discount"`
+	Dpp               float64              `json:"dpp"`
+	Ppn               float64              `json:"ppn"`
+	GrandTotal        float64              `json:"grandTotal"`
 	TransactionDetail []*TransactionDetail `json:"transactionDetail"`
 }
 
 type TransactionDetail struct {
-	ID            string    `json:"id"`
-	TransactionID string    `json:"transactionId"`
-	UnitID        string    `json:"unitId"`
-	ProductID     string    `json:"productId"`
-	BuyPrice      float64   `json:"buyPrice"`
-	SellPrice     float64   `json:"sellPrice"`
-	Quantity      float64   `json:"quantity"`
-	BuyQuantity   float64   `json:"buyQuantity"`
-	CreatedTime   time.Time `json:"-"`
-	WebUserID     string    `json:"-"`
-	Latest        bool      `json:"-"`
-	SortingVal    int64     `json:"-"`
-}
+	ID             string    `json:"id"`
+	TransactionID  string    `json:"transactionId"`
+	UnitID         string    `json:"unitId"`
+	ProductID      string    `json:"productId"`
+	BuyPrice       float64   `json:"buyPrice"`
+	SellPrice      float64   `json:"sellPrice"`
+	Quantity       float64   `json:"quantity"`
+	BuyQuantity    float64   `json:"buyQuantity"`
+	DiscountType   string    `json:"discountType"`
+	DiscountValue  float64   `json:"discountValue"`
+	DiscountAmount float64   `json:"discountAmount"`
+	TaxRate        float64   `json:"taxRate"`
+	Dpp            float64   `json:"dpp"`
+	Ppn            float64   `json:"ppn"`
+	CreatedTime    time.Time `json:"-"`
+	WebUserID      string    `json:"-"`
+	Latest         bool      `json:"-"`
+	SortingVal     int64     `json:"-"`
+}
+
+// SellSortFields are the fields the sell transaction list can be sorted by
+var SellSortFields = []string{"code", "date", "status"}
 
 type TransactionStatus struct {
 	ID                string                     `json:"id"`
@@ -57,20 +78,34 @@ type TransactionStatus struct {
 	UserName          string                     `json:"userName"`
 	CreatedTime       string                     `json:"createdTime"`
 	Total             float64                    `json:"total"`
+	DiscountType      string                     `json:"discountType"`
+	DiscountValue     float64                    `json:"discountValue"`
+	TaxInclusive      bool                       `json:"taxInclusive"`
+	Subtotal          float64                    `json:"subtotal"`
+	Discount          float64                    `json:"discount"`
+	Dpp               float64                    `json:"dpp"`
+	Ppn               float64                    `json:"ppn"`
+	GrandTotal        float64                    `json:"grandTotal"`
 	TransactionDetail []*TransactionStatusDetail `json:"transactionDetail"`
 }
 
 type TransactionStatusDetail struct {
-	TransactionID string  `json:"transactionId"`
-	UnitID        string  `json:"unitId"`
-	UnitCode      string  `json:"unitCode"`
-	ProductID     string  `json:"productId"`
-	ProductCode   string  `json:"productCode"`
-	ProductName   string  `json:"productName"`
-	BuyPrice      float64 `json:"buyPrice"`
-	SellPrice     float64 `json:"sellPrice"`
-	Quantity      float64 `json:"quantity"`
-	BuyQuantity   float64 `json:"buyQuantity"`
+	TransactionID  string  `json:"transactionId"`
+	UnitID         string  `json:"unitId"`
+	UnitCode       string  `json:"unitCode"`
+	ProductID      string  `json:"productId"`
+	ProductCode    string  `json:"productCode"`
+	ProductName    string  `json:"productName"`
+	BuyPrice       float64 `json:"buyPrice"`
+	SellPrice      float64 `json:"sellPrice"`
+	Quantity       float64 `json:"quantity"`
+	BuyQuantity    float64 `json:"buyQuantity"`
+	DiscountType   string  `json:"discountType"`
+	DiscountValue  float64 `json:"discountValue"`
+	DiscountAmount float64 `json:"discountAmount"`
+	TaxRate        float64 `json:"taxRate"`
+	Dpp            float64 `json:"dpp"`
+	Ppn            float64 `json:"ppn"`
 }
 
 type ReportDate struct {
@@ -82,17 +117,72 @@ type Report struct {
 	Code          string          `json:"code"`
 	ReferenceCode string          `json:"referenceCode"`
 	Status        string          `json:"status"`
+	Subtotal      float64         `json:"subtotal"`
+	Discount      float64         `json:"discount"`
+	Dpp           float64         `json:"dpp"`
+	Ppn           float64         `json:"ppn"`
+	GrandTotal    float64         `json:"grandTotal"`
 	ReportDetails []*ReportDetail `json:"reportDetails"`
 }
 
 type ReportDetail struct {
-	ID          string  `json:"id"`
-	ProductCode string  `json:"productCode"`
-	ProductName string  `json:"productName"`
-	BuyPrice    float64 `json:"buyPrice"`
-	SellPrice   float64 `json:"sellPrice"`
-	Quantity    float64 `json:"quantity"`
-	BuyQuantity float64 `json:"buyQuantity"`
+	ID             string  `json:"id"`
+	ProductCode    string  `json:"productCode"`
+	ProductName    string  `json:"productName"`
+	BuyPrice       float64 `json:"buyPrice"`
+	SellPrice      float64 `json:"sellPrice"`
+	Quantity       float64 `json:"quantity"`
+	BuyQuantity    float64 `json:"buyQuantity"`
+	DiscountAmount float64 `json:"discountAmount"`
+	TaxRate        float64 `json:"taxRate"`
+	Dpp            float64 `json:"dpp"`
+	Ppn            float64 `json:"ppn"`
+}
+
+// TaxSetting is what the PPN of a transaction is worked out from, the customer's settings and the rates of its products
+type TaxSetting struct {
+	Taxable      bool
+	TaxInclusive bool
+	CustomerRate *float64
+	ProductRates map[string]*float64
+}
+
+// EFaktur is a taxable sell transaction as it is exported to e-Faktur, TaxInvoiceNumber is empty until a serial number is given
+type EFaktur struct {
+	ID               string
+	Code             string
+	Date             time.Time
+	TaxInvoiceNumber string
+	CustomerNpwp     string
+	CustomerName     string
+	CustomerAddress  string
+	TaxInclusive     bool
+	Dpp              float64
+	Ppn              float64
+	Details          []*EFakturDetail
+}
+
+type EFakturDetail struct {
+	ProductCode string
+	ProductName string
+	SellPrice   float64
+	Quantity    float64
+	TaxRate     float64
+	Dpp         float64
+	Ppn         float64
+}
+
+// TaxInvoiceStatuses are the statuses of a sell transaction final enough to be given a tax invoice serial number
+var TaxInvoiceStatuses = []string{TRANSACTION_DICETAK, TRANSACTION_KONTRABON, TRANSACTION_DIBAYAR}
+
+// TaxInvoiceAllocateRequest gives serial numbers to the final taxable sell transactions between the dates
+type TaxInvoiceAllocateRequest struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+type TaxInvoiceAllocateResponse struct {
+	Allocated int `json:"allocated"`
 }
 
 type UpdateHargaBeliRequest struct {
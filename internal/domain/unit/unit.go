@@ -1,8 +1,11 @@
 package unitdomain
 
+// SortFields are the fields the list endpoint can be sorted by
+var SortFields = []string{"code", "description"}
+
 type Unit struct {
 	ID          string `json:"id"`
 	Code        string `json:"code"`
 	Description string `json:"description"`
-	Active      bool   `-`
+	Active      bool   `json:"Active"`
 }
@@ -5,17 +5,32 @@ import (
 	"time"
 )
 
+// SortFields are the fields the user list can be sorted by
+var SortFields = []string{"name", "username"}
+
+const STAKEHOLDER_TYPE_CUSTOMER = "CUSTOMER"
+const STAKEHOLDER_TYPE_SUPPLIER = "SUPPLIER"
+
 type WebUser struct {
-	ID                    string    `json:"id"`
-	Name                  string    `json:"name"`
-	Username              string    `json:"username"`
-	PasswordHash          string    `json:"_"`
-	PasswordSalt          string    `json:"_"`
-	Email                 string    `json:"_"`
-	RoleId                string    `json:"roleId"`
-	Active                bool      `json:"active"`
-	RegistrationTimestamp time.Time `json:"_"`
-	CreatedBy             string    `json:"_"`
+	ID                    string     `json:"id"`
+	Name                  string     `json:"name"`
+	Username              string     `json:"username"`
+	PasswordHash          string     `json:"-"`
+	PasswordSalt          string     `json:"-"`
+	Email                 string     `json:"-"`
+	RoleId                string     `json:"roleId"`
+	Active                bool       `json:"active"`
+	RegistrationTimestamp time.Time  `json:"-"`
+	CreatedBy             string     `json:"-"`
+	Language              string     `json:"language"`
+	FailedLoginCount      int        `json:"-"`
+	LockedUntil           *time.Time `json:"lockedUntil"`
+	TotpSecret            string     `json:"-"`
+	TotpEnabled           bool       `json:"totpEnabled"`
+	// ServiceAccount users cannot log in, they authenticate with api keys
+	ServiceAccount      bool       `json:"serviceAccount"`
+	MustChangePassword  bool       `json:"mustChangePassword"`
+	PasswordChangedTime *time.Time `json:"-"`
 }
 
 type WebUserCache struct {
@@ -29,3 +44,42 @@ type RegisterWebUser struct {
 	Password string `json:"Password"`
 	RoleId   string `json:"roleId"`
 }
+
+// TotpSetup is the pending secret of a user enrolling two-factor authentication
+type TotpSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qrCode"`
+}
+
+// RecoveryCodes are shown once, only their hashes are stored
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// StakeholderAssignment is a customer or supplier whose data the user may read when the role does not allow all data
+type StakeholderAssignment struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type Stakeholders struct {
+	Customers []*StakeholderAssignment `json:"customers"`
+	Suppliers []*StakeholderAssignment `json:"suppliers"`
+}
+
+// ManagedUser is a mobile user a supervisor manages, the supervisor may decide the dana transfers between them
+type ManagedUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// PasswordHistory is a password the user had, kept to refuse reusing it
+type PasswordHistory struct {
+	PasswordHash string
+	PasswordSalt string
+	CreatedTime  time.Time
+}
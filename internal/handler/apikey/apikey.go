@@ -0,0 +1,123 @@
+package apikeyhandler
+
+import (
+	"context"
+	apikeydomain "dromatech/pos-backend/internal/domain/apikey"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+type apiKeyUsecase interface {
+	FindKeys(ctx context.Context, userId string) ([]*apikeydomain.ApiKey, error)
+	CreateKey(ctx context.Context, creatorId, userId, name string, permissions []string, expiredDate string) (*apikeydomain.IssuedKey, error)
+	RotateKey(ctx context.Context, id string) (*apikeydomain.IssuedKey, error)
+	RevokeKey(ctx context.Context, id string) error
+}
+
+// Handler defines the handler
+type Handler struct {
+	apiKeyUc apiKeyUsecase
+}
+
+func New(apiKeyUsecase apiKeyUsecase) *Handler {
+	return &Handler{
+		apiKeyUc: apiKeyUsecase,
+	}
+}
+
+func (h *Handler) Find(c *gin.Context) {
+	userId := c.Query("userId")
+	if userId == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_SERVICE_ACCOUNT_SELECT)))
+		return
+	}
+
+	apiKeys, err := h.apiKeyUc.FindKeys(c.Request.Context(), userId)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "", apiKeys)
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	jsonData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+		return
+	}
+
+	userId := gjson.Get(string(jsonData), "userId")
+	name := gjson.Get(string(jsonData), "name")
+	expiredDate := gjson.Get(string(jsonData), "expiredDate")
+	if !userId.Exists() || userId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_SERVICE_ACCOUNT_SELECT)))
+		return
+	}
+	if !name.Exists() || name.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("name", i18nutil.T(c.Request.Context(), i18nutil.ERR_API_KEY_NAME_REQUIRED)))
+		return
+	}
+
+	permissions := []string{}
+	for _, permission := range gjson.Get(string(jsonData), "permissions").Array() {
+		permissions = append(permissions, permission.String())
+	}
+
+	session := restutil.GetSession(c)
+	issued, err := h.apiKeyUc.CreateKey(c.Request.Context(), session.UserID, userId.String(), name.String(), permissions, expiredDate.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "API key berhasil dibuat, simpan key ini karena tidak akan ditampilkan lagi", issued)
+}
+
+func (h *Handler) Rotate(c *gin.Context) {
+	id, ok := keyRequest(c)
+	if !ok {
+		return
+	}
+
+	issued, err := h.apiKeyUc.RotateKey(c.Request.Context(), id)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "API key berhasil diganti, simpan key ini karena tidak akan ditampilkan lagi", issued)
+}
+
+func (h *Handler) Revoke(c *gin.Context) {
+	id, ok := keyRequest(c)
+	if !ok {
+		return
+	}
+
+	err := h.apiKeyUc.RevokeKey(c.Request.Context(), id)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "API key berhasil dicabut", nil)
+}
+
+// keyRequest reads the key id of the rotate and revoke endpoints, the error is sent when it returns false
+func keyRequest(c *gin.Context) (string, bool) {
+	jsonData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+		return "", false
+	}
+
+	id := gjson.Get(string(jsonData), "id")
+	if !id.Exists() || id.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("id", i18nutil.T(c.Request.Context(), i18nutil.ERR_API_KEY_SELECT)))
+		return "", false
+	}
+	return id.String(), true
+}
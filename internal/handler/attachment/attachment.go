@@ -0,0 +1,139 @@
+package attachmenthandler
+
+import (
+	attachmentdomain "dromatech/pos-backend/internal/domain/attachment"
+	attachmentusecase "dromatech/pos-backend/internal/usecase/attachment"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MAX_UPLOAD_SIZE caps the body of an upload request, the configured size limit is checked in the usecase before the file is read
+const MAX_UPLOAD_SIZE = 64 << 20
+
+// Handler defines the handler
+type Handler struct {
+	attachmentUsecase attachmentusecase.AttachmentUsecase
+}
+
+func New(attachmentUsecase attachmentusecase.AttachmentUsecase) *Handler {
+	return &Handler{
+		attachmentUsecase: attachmentUsecase,
+	}
+}
+
+func (h *Handler) Upload(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MAX_UPLOAD_SIZE)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ATTACHMENT_TOO_LARGE, MAX_UPLOAD_SIZE>>10))
+			return
+		}
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ATTACHMENT_FILE_REQUIRED))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ATTACHMENT_FILE_REQUIRED))
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentUsecase.Upload(c.Request.Context(), userID, c.PostForm("ownerType"), c.PostForm("ownerId"), header.Filename, header.Size, file)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Lampiran berhasil diunggah", attachment)
+}
+
+func (h *Handler) Find(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	attachments, err := h.attachmentUsecase.FindByOwner(c.Request.Context(), userID, c.Query("ownerType"), c.Query("ownerId"))
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", attachments)
+}
+
+func (h *Handler) FindAll(c *gin.Context) {
+	attachments, err := h.attachmentUsecase.FindAllByOwner(c.Request.Context(), c.Query("ownerType"), c.Query("ownerId"))
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", attachments)
+}
+
+func (h *Handler) Download(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+	thumbnail, _ := strconv.ParseBool(c.Query("thumbnail"))
+
+	attachment, reader, err := h.attachmentUsecase.Download(c.Request.Context(), userID, c.Query("id"), thumbnail)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	send(c, attachment, reader, thumbnail)
+}
+
+func (h *Handler) DownloadAny(c *gin.Context) {
+	thumbnail, _ := strconv.ParseBool(c.Query("thumbnail"))
+
+	attachment, reader, err := h.attachmentUsecase.DownloadAny(c.Request.Context(), c.Query("id"), thumbnail)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	send(c, attachment, reader, thumbnail)
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	var request struct {
+		ID string `json:"id"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	if err := h.attachmentUsecase.Delete(c.Request.Context(), userID, request.ID); err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Lampiran berhasil dihapus", nil)
+}
+
+// send streams the file, a thumbnail is always a JPEG
+func send(c *gin.Context, attachment *attachmentdomain.Attachment, reader io.ReadCloser, thumbnail bool) {
+	defer reader.Close()
+
+	contentType := attachment.ContentType
+	size := attachment.Size
+	if thumbnail {
+		contentType = "image/jpeg"
+		size = -1
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", attachment.FileName),
+	})
+}
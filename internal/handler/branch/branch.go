@@ -0,0 +1,91 @@
+package branchhandler
+
+import (
+	"context"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type branchUsecase interface {
+	Find(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*branchdomain.Branch, int64, error)
+	Create(ctx context.Context, request branchdomain.BranchRequest) error
+	Edit(ctx context.Context, request branchdomain.BranchRequest) error
+}
+
+// Handler defines the handler
+type Handler struct {
+	branchUsecase branchUsecase
+}
+
+func New(branchUsecase branchUsecase) *Handler {
+	return &Handler{
+		branchUsecase: branchUsecase,
+	}
+}
+
+func (h *Handler) Find(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, branchdomain.SortFields...)
+	if !ok {
+		return
+	}
+
+	var activeBool *bool
+	if active := c.Query("active"); active != "" {
+		parsedBool, err := strconv.ParseBool(active)
+		if err != nil {
+			logutil.WithContext(c.Request.Context()).Error(err.Error())
+		} else {
+			activeBool = &parsedBool
+		}
+	}
+
+	branches, total, err := h.branchUsecase.Find(c.Request.Context(), c.Query("id"), c.Query("code"), activeBool, list)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseList(c, "", branches, list.Meta(total, len(branches)))
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var request branchdomain.BranchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	err := h.branchUsecase.Create(c.Request.Context(), request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Cabang berhasil ditambahkan", nil)
+}
+
+func (h *Handler) Edit(c *gin.Context) {
+	var request branchdomain.BranchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+	if request.ID == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_BRANCH_SELECT))
+		return
+	}
+
+	err := h.branchUsecase.Edit(c.Request.Context(), request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Cabang berhasil diperbarui", nil)
+}
@@ -3,11 +3,12 @@ package customerhandler
 import (
 	customerdomain "dromatech/pos-backend/internal/domain/customer"
 	customerusecase "dromatech/pos-backend/internal/usecase/customer"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"io"
 	"strconv"
@@ -25,6 +26,11 @@ func New(customerUsecase customerusecase.CustmerUsecase) *Handler {
 }
 
 func (h *Handler) Find(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, customerdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 	name := c.Query("name")
@@ -38,27 +44,34 @@ func (h *Handler) Find(c *gin.Context) {
 		pointerBool = &latestBool
 	}
 
-	products, err := h.customerUsecase.Find(id, code, name, pointerBool)
+	products, total, err := h.customerUsecase.Find(c.Request.Context(), id, code, name, pointerBool, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
+		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) FindActive(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, customerdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 	name := c.Query("name")
 
 	active := true
 
-	products, err := h.customerUsecase.Find(id, code, name, &active)
+	products, total, err := h.customerUsecase.Find(c.Request.Context(), id, code, name, &active, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
+		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -69,13 +82,13 @@ func (h *Handler) Create(c *gin.Context) {
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode customer")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_CUSTOMER_CODE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama customer")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_CUSTOMER_NAME_REQUIRED))
 		return
 	}
 
@@ -87,9 +100,10 @@ func (h *Handler) Create(c *gin.Context) {
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.customerUsecase.Create(code.String(), name.String(), description.String(), initialCredit)
+	err = h.customerUsecase.Create(c.Request.Context(), code.String(), name.String(), description.String(),
+		gjson.GetBytes(jsonData, "npwp").String(), gjson.GetBytes(jsonData, "address").String(), initialCredit, taxFrom(jsonData))
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -104,25 +118,25 @@ func (h *Handler) Edit(c *gin.Context) {
 
 	id := gjson.Get(string(jsonData), "id")
 	if !id.Exists() || id.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih customer yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_CUSTOMER_SELECT))
 		return
 	}
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode customer")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_CUSTOMER_CODE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama customer")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_CUSTOMER_NAME_REQUIRED))
 		return
 	}
 
 	active := gjson.Get(string(jsonData), "active")
 	if !active.Exists() {
-		restutil.SendResponseFail(c, "Harap pilih status")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_STATUS_REQUIRED))
 		return
 	}
 
@@ -134,24 +148,40 @@ func (h *Handler) Edit(c *gin.Context) {
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.customerUsecase.Edit(id.String(), code.String(), name.String(), description.String(), active.Bool(), initialCredit)
+	err = h.customerUsecase.Edit(c.Request.Context(), id.String(), code.String(), name.String(), description.String(),
+		gjson.GetBytes(jsonData, "npwp").String(), gjson.GetBytes(jsonData, "address").String(), active.Bool(), initialCredit, taxFrom(jsonData))
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
 	restutil.SendResponseOk(c, "Customer berhasil diperbarui", nil)
 }
 
+// taxFrom reads the tax settings of a customer from its create or edit body, a missing or null taxRate follows the default
+func taxFrom(jsonData []byte) customerdomain.Tax {
+	tax := customerdomain.Tax{
+		Taxable:      gjson.GetBytes(jsonData, "taxable").Bool(),
+		TaxInclusive: gjson.GetBytes(jsonData, "taxInclusive").Bool(),
+	}
+
+	taxRate := gjson.GetBytes(jsonData, "taxRate")
+	if taxRate.Exists() && taxRate.Type != gjson.Null {
+		rate := taxRate.Float()
+		tax.TaxRate = &rate
+	}
+	return tax
+}
+
 func (h *Handler) GetSellPrice(c *gin.Context) {
 	supplierId := c.Query("customerId")
 	unitId := c.Query("unitId")
 	date := c.Query("date")
 	productId := c.Query("productId")
 
-	response, err := h.customerUsecase.GetSellPrice(supplierId, unitId, date, productId)
+	response, err := h.customerUsecase.GetSellPrice(c.Request.Context(), supplierId, unitId, date, productId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -161,23 +191,23 @@ func (h *Handler) GetSellPrice(c *gin.Context) {
 func (h *Handler) AddPrice(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat menambahkan data harga")
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_CREATE))
 		return
 	}
 
 	request := customerdomain.AddPriceRequest{}
 	err = json.Unmarshal(jsonData, &request)
 	if err != nil {
-		logrus.Errorf(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat menambahkan data harga")
+		logutil.WithContext(c.Request.Context()).Errorf(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_CREATE))
 		return
 	}
 
 	userId := restutil.GetSession(c).UserID
-	err = h.customerUsecase.AddSellPrice(request, userId)
+	err = h.customerUsecase.AddSellPrice(c.Request.Context(), request, userId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -187,22 +217,22 @@ func (h *Handler) AddPrice(c *gin.Context) {
 func (h *Handler) UpdateSellPrice(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat memperbarui harga")
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_UPDATE))
 		return
 	}
 
 	request := customerdomain.SellPriceRequest{}
 	err = json.Unmarshal(jsonData, &request)
 	if err != nil {
-		logrus.Errorf(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat memperbarui harga")
+		logutil.WithContext(c.Request.Context()).Errorf(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_UPDATE))
 		return
 	}
 
-	err = h.customerUsecase.UpdateSellPrice(request)
+	err = h.customerUsecase.UpdateSellPrice(c.Request.Context(), request)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -214,9 +244,9 @@ func (h *Handler) FindLatestPrice(c *gin.Context) {
 	unitId := c.Query("unitId")
 
 	latest := true
-	response, err := h.customerUsecase.FindSellPrice(customerId, unitId, "", &latest)
+	response, err := h.customerUsecase.FindSellPrice(c.Request.Context(), customerId, unitId, "", &latest)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -237,9 +267,9 @@ func (h *Handler) FindPrice(c *gin.Context) {
 		pointerBool = &latestBool
 	}
 
-	response, err := h.customerUsecase.FindSellPrice(customerId, unitId, productId, pointerBool)
+	response, err := h.customerUsecase.FindSellPrice(c.Request.Context(), customerId, unitId, productId, pointerBool)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
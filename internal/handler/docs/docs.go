@@ -0,0 +1,50 @@
+package docshandler
+
+import (
+	openapiutil "dromatech/pos-backend/internal/util/openapi"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+const SPEC_FILE = "/openapi.json"
+
+// initializer replaces the petstore initializer bundled with swagger ui so the ui loads our spec
+const initializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+type Handler struct {
+	spec []byte
+}
+
+// New creates docs handler serving the given specification
+func New(doc *openapiutil.Document) (*Handler, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{spec: spec}, nil
+}
+
+// Serve serves the specification and the bundled swagger ui under /api/docs/
+func (h *Handler) Serve(c *gin.Context) {
+	switch file := c.Param("any"); file {
+	case SPEC_FILE:
+		c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+	case "/swagger-initializer.js":
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(initializer))
+	default:
+		c.FileFromFS(file, swaggerFiles.HTTP)
+	}
+}
@@ -0,0 +1,204 @@
+package expensehandler
+
+import (
+	"context"
+	expensedomain "dromatech/pos-backend/internal/domain/expense"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+type expenseUsecase interface {
+	FindCategory(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*expensedomain.Category, int64, error)
+	CreateCategory(ctx context.Context, code, name string) error
+	EditCategory(ctx context.Context, id, code, name string, active *bool) error
+	FindBudget(ctx context.Context, categoryID, webUserID string, list queryutil.ListParam) ([]*expensedomain.Budget, int64, error)
+	CreateBudget(ctx context.Context, request expensedomain.BudgetRequest) error
+	EditBudget(ctx context.Context, request expensedomain.BudgetRequest) error
+	DeleteBudget(ctx context.Context, id string) error
+}
+
+// Handler defines the handler
+type Handler struct {
+	expenseUsecase expenseUsecase
+}
+
+func New(expenseUsecase expenseUsecase) *Handler {
+	return &Handler{
+		expenseUsecase: expenseUsecase,
+	}
+}
+
+func (h *Handler) FindCategory(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, expensedomain.CategorySortFields...)
+	if !ok {
+		return
+	}
+
+	var activeBool *bool
+	if active := c.Query("active"); active != "" {
+		parsedBool, err := strconv.ParseBool(active)
+		if err != nil {
+			logutil.WithContext(c.Request.Context()).Error(err.Error())
+		} else {
+			activeBool = &parsedBool
+		}
+	}
+
+	categories, total, err := h.expenseUsecase.FindCategory(c.Request.Context(), c.Query("id"), c.Query("code"), activeBool, list)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseList(c, "", categories, list.Meta(total, len(categories)))
+}
+
+// FindActiveCategory lists the categories the mobile app can pick for an operasional
+func (h *Handler) FindActiveCategory(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, expensedomain.CategorySortFields...)
+	if !ok {
+		return
+	}
+
+	var activeBool = true
+
+	categories, total, err := h.expenseUsecase.FindCategory(c.Request.Context(), "", "", &activeBool, list)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseList(c, "", categories, list.Meta(total, len(categories)))
+}
+
+func (h *Handler) CreateCategory(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	code := gjson.Get(string(jsonData), "code")
+	if !code.Exists() || code.String() == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EXPENSE_CATEGORY_CODE_REQUIRED))
+		return
+	}
+	name := gjson.Get(string(jsonData), "name")
+	if !name.Exists() || name.String() == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EXPENSE_CATEGORY_NAME_REQUIRED))
+		return
+	}
+
+	err = h.expenseUsecase.CreateCategory(c.Request.Context(), code.String(), name.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Kategori biaya berhasil ditambahkan", nil)
+}
+
+func (h *Handler) EditCategory(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	id := gjson.Get(string(jsonData), "id")
+	if !id.Exists() || id.String() == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EXPENSE_CATEGORY_SELECT))
+		return
+	}
+
+	var activeBool *bool
+	active := gjson.Get(string(jsonData), "active")
+	if active.Exists() {
+		activeAddress := active.Bool()
+		activeBool = &activeAddress
+	}
+
+	code := gjson.Get(string(jsonData), "code")
+	name := gjson.Get(string(jsonData), "name")
+
+	err = h.expenseUsecase.EditCategory(c.Request.Context(), id.String(), code.String(), name.String(), activeBool)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Kategori biaya berhasil diperbarui", nil)
+}
+
+func (h *Handler) FindBudget(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, expensedomain.BudgetSortFields...)
+	if !ok {
+		return
+	}
+
+	budgets, total, err := h.expenseUsecase.FindBudget(c.Request.Context(), c.Query("categoryId"), c.Query("webUserId"), list)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseList(c, "", budgets, list.Meta(total, len(budgets)))
+}
+
+func (h *Handler) CreateBudget(c *gin.Context) {
+	var request expensedomain.BudgetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	err := h.expenseUsecase.CreateBudget(c.Request.Context(), request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Anggaran berhasil ditambahkan", nil)
+}
+
+func (h *Handler) EditBudget(c *gin.Context) {
+	var request expensedomain.BudgetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+	if request.ID == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EXPENSE_BUDGET_SELECT))
+		return
+	}
+
+	err := h.expenseUsecase.EditBudget(c.Request.Context(), request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Anggaran berhasil diperbarui", nil)
+}
+
+func (h *Handler) DeleteBudget(c *gin.Context) {
+	var request expensedomain.BudgetRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.ID == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EXPENSE_BUDGET_SELECT))
+		return
+	}
+
+	err := h.expenseUsecase.DeleteBudget(c.Request.Context(), request.ID)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Anggaran berhasil dihapus", nil)
+}
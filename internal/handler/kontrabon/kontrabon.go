@@ -4,11 +4,12 @@ import (
 	kontrabondomain "dromatech/pos-backend/internal/domain/kontrabon"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
 	kontrabonusecase "dromatech/pos-backend/internal/usecase/kontrabon"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"io/ioutil"
 	"time"
@@ -26,26 +27,31 @@ func New(kontrabonUsecase kontrabonusecase.KontrabonUsecase) *Handler {
 }
 
 func (h *Handler) Find(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, kontrabondomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	startDate := c.Query("startDate")
 	endDate := c.Query("endDate")
 	code := c.Query("code")
 	customerId := c.Query("customerId")
 
-	kontrabons, err := h.kontrabonUsecase.Find(code, startDate, endDate, customerId)
+	kontrabons, total, err := h.kontrabonUsecase.Find(c.Request.Context(), code, startDate, endDate, customerId, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
-	restutil.SendResponseOk(c, "", kontrabons)
+	restutil.SendResponseList(c, "", kontrabons, list.Meta(total, len(kontrabons)))
 }
 
 func (h *Handler) FindTransaction(c *gin.Context) {
 	kontrabonId := c.Query("kontrabonId")
 
-	kontrabons, err := h.kontrabonUsecase.FindTransaction(kontrabonId)
+	kontrabons, err := h.kontrabonUsecase.FindTransaction(c.Request.Context(), kontrabonId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -55,7 +61,7 @@ func (h *Handler) FindTransaction(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
 	jsonData, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -64,19 +70,19 @@ func (h *Handler) Create(c *gin.Context) {
 
 	err = json.Unmarshal(jsonData, &kontrabon)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if len(kontrabon.TransactionIDs) <= 0 {
-		restutil.SendResponseFail(c, "Harap pilih transaksi yang akan ditambahkan ke kontrabon")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_KONTRABON_TRANSACTION_SELECT))
 		return
 	}
 
-	err = h.kontrabonUsecase.Create(kontrabon.CustomerID, kontrabon.TransactionIDs)
+	err = h.kontrabonUsecase.Create(c.Request.Context(), kontrabon.CustomerID, kontrabon.TransactionIDs)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -86,7 +92,7 @@ func (h *Handler) Create(c *gin.Context) {
 func (h *Handler) Add(c *gin.Context) {
 	jsonData, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -95,24 +101,24 @@ func (h *Handler) Add(c *gin.Context) {
 
 	err = json.Unmarshal(jsonData, &request)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if request.KontrabonID == "" {
-		restutil.SendResponseFail(c, "Harap pilih kontrabon yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_KONTRABON_SELECT))
 		return
 	}
 
 	if len(request.TransactionIDs) <= 0 {
-		restutil.SendResponseFail(c, "Harap pilih transaksi yang akan ditambahkan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_ADD_SELECT))
 		return
 	}
 
-	err = h.kontrabonUsecase.Update(request.KontrabonID, request.TransactionIDs, transactiondomain.TRANSACTION_KONTRABON)
+	err = h.kontrabonUsecase.Update(c.Request.Context(), request.KontrabonID, request.TransactionIDs, transactiondomain.TRANSACTION_KONTRABON)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -122,7 +128,7 @@ func (h *Handler) Add(c *gin.Context) {
 func (h *Handler) Remove(c *gin.Context) {
 	jsonData, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -131,24 +137,24 @@ func (h *Handler) Remove(c *gin.Context) {
 
 	err = json.Unmarshal(jsonData, &request)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if request.KontrabonID == "" {
-		restutil.SendResponseFail(c, "Harap pilih kontrabon yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_KONTRABON_SELECT))
 		return
 	}
 
 	if len(request.TransactionIDs) <= 0 {
-		restutil.SendResponseFail(c, "Harap pilih transaksi yang akan ditambahkan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_ADD_SELECT))
 		return
 	}
 
-	err = h.kontrabonUsecase.Update(request.KontrabonID, request.TransactionIDs, transactiondomain.TRANSACTION_PEMBUATAN)
+	err = h.kontrabonUsecase.Update(c.Request.Context(), request.KontrabonID, request.TransactionIDs, transactiondomain.TRANSACTION_PEMBUATAN)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -164,27 +170,27 @@ func (h *Handler) UpdateLunas(c *gin.Context) {
 
 	kontrabonID := gjson.Get(string(jsonData), "kontrabonId")
 	if !kontrabonID.Exists() || kontrabonID.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih kontrabon yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_KONTRABON_SELECT))
 		return
 	}
 
 	paymentDate := gjson.Get(string(jsonData), "paymentDate")
 	if !paymentDate.Exists() || paymentDate.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi tangal pembayaran")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PAYMENT_DATE_REQUIRED))
 		return
 	}
 
 	totalPayment := gjson.Get(string(jsonData), "totalPayment")
 	if !totalPayment.Exists() {
-		restutil.SendResponseFail(c, "Harap isi total pembayaran")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PAYMENT_TOTAL_REQUIRED))
 		return
 	}
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.kontrabonUsecase.UpdateLunas(kontrabonID.String(), now, totalPayment.Float(), description.String(), paymentDate.String())
+	err = h.kontrabonUsecase.UpdateLunas(c.Request.Context(), kontrabonID.String(), now, totalPayment.Float(), description.String(), paymentDate.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -0,0 +1,36 @@
+package languagehandler
+
+import (
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+}
+
+// New creates language handler
+func New() *Handler {
+	return &Handler{}
+}
+
+// Resolve puts the response language from Accept-Language into the request context, it runs before the tenant and the
+// session are checked so their errors are answered in that language too
+func (h *Handler) Resolve(c *gin.Context) {
+	lang := i18nutil.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if lang == "" {
+		lang = i18nutil.DEFAULT_LANG
+	}
+
+	c.Request = c.Request.WithContext(i18nutil.NewContext(c.Request.Context(), lang))
+	c.Next()
+}
+
+// ResolveUser replaces the language of Resolve with the preference of the logged in user
+func (h *Handler) ResolveUser(c *gin.Context) {
+	if session := restutil.GetSession(c); session != nil && i18nutil.IsSupported(session.Language) {
+		c.Request = c.Request.WithContext(i18nutil.NewContext(c.Request.Context(), session.Language))
+	}
+	c.Next()
+}
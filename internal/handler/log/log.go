@@ -0,0 +1,58 @@
+package loghandler
+
+import (
+	logutil "dromatech/pos-backend/internal/util/log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+}
+
+// New creates request log handler
+func New() *Handler {
+	return &Handler{}
+}
+
+// RequestLogger assigns a request ID to every request and writes one access log line after it is served
+func (h *Handler) RequestLogger(c *gin.Context) {
+	start := time.Now()
+
+	requestID := logutil.SanitizeRequestID(c.GetHeader(logutil.RequestIDHeader))
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	info := &logutil.RequestInfo{RequestID: requestID}
+	c.Request = c.Request.WithContext(logutil.NewContext(c.Request.Context(), info))
+	c.Header(logutil.RequestIDHeader, requestID)
+
+	c.Next()
+
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+
+	entry := logutil.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+		"method":     c.Request.Method,
+		"path":       path,
+		"status":     c.Writer.Status(),
+		"latency_ms": time.Since(start).Milliseconds(),
+		"client_ip":  c.ClientIP(),
+	})
+
+	status := c.Writer.Status()
+	switch {
+	case status >= http.StatusInternalServerError || len(c.Errors) > 0:
+		entry.WithField("errors", c.Errors.String()).Error("request")
+	case status >= http.StatusBadRequest:
+		entry.Warn("request")
+	default:
+		entry.Info("request")
+	}
+}
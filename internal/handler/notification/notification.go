@@ -0,0 +1,135 @@
+package notificationhandler
+
+import (
+	notificationdomain "dromatech/pos-backend/internal/domain/notification"
+	notificationusecase "dromatech/pos-backend/internal/usecase/notification"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HEARTBEAT_INTERVAL keeps proxies from closing an idle stream
+const HEARTBEAT_INTERVAL = 25 * time.Second
+
+// DEFAULT_STREAM_MINUTE ends a stream of a session without an expiry, the client reconnects so a revoked session stops receiving
+const DEFAULT_STREAM_MINUTE = 30
+
+// Handler defines the handler
+type Handler struct {
+	notificationUsecase notificationusecase.NotificationUsecase
+}
+
+func New(notificationUsecase notificationusecase.NotificationUsecase) *Handler {
+	return &Handler{
+		notificationUsecase: notificationUsecase,
+	}
+}
+
+// Stream sends the notifications of the session user as Server-Sent Events, the event name is the type and the event id the notification id
+func (h *Handler) Stream(c *gin.Context) {
+	session := restutil.GetSession(c)
+	userID := session.UserID
+
+	lastID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if lastID == 0 {
+		lastID, _ = strconv.ParseInt(c.Query("lastEventId"), 10, 64)
+	}
+
+	missed, notifications, unsubscribe, err := h.notificationUsecase.Subscribe(c.Request.Context(), userID, lastID)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, notification := range missed {
+		writeEvent(c.Writer, notification)
+		lastID = notification.ID
+	}
+	c.Writer.Flush()
+
+	// the stream ends when the session would expire with the timeout of its tenant, the client reconnects and is checked again
+	streamTime := time.Until(session.ExpiredTime)
+	if streamTime <= 0 {
+		streamTime = DEFAULT_STREAM_MINUTE * time.Minute
+	}
+	end := time.NewTimer(streamTime)
+	defer end.Stop()
+	heartbeat := time.NewTicker(HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case notification, ok := <-notifications:
+			if !ok {
+				return false
+			}
+			// already sent while catching up
+			if notification.ID <= lastID {
+				return true
+			}
+			writeEvent(w, notification)
+			lastID = notification.ID
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			return true
+		case <-end.C:
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func writeEvent(w io.Writer, notification notificationdomain.Notification) {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", notification.ID, notification.Type, data)
+}
+
+func (h *Handler) FindList(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	notifications, err := h.notificationUsecase.FindList(c.Request.Context(), userID, unreadOnly, limit)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", notifications)
+}
+
+func (h *Handler) MarkRead(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	var request notificationdomain.MarkReadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	if err := h.notificationUsecase.MarkRead(c.Request.Context(), userID, request); err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", nil)
+}
@@ -3,11 +3,12 @@ package pricehandler
 import (
 	pricedomain "dromatech/pos-backend/internal/domain/price"
 	priceusecase "dromatech/pos-backend/internal/usecase/price"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"io"
 	"io/ioutil"
@@ -27,9 +28,9 @@ func New(priceUsecase priceusecase.PriceUsecase) *Handler {
 func (h *Handler) Find(c *gin.Context) {
 	name := c.Query("name")
 
-	prices, err := h.priceUsecase.Find(name)
+	prices, err := h.priceUsecase.Find(c.Request.Context(), name)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -39,9 +40,9 @@ func (h *Handler) Find(c *gin.Context) {
 func (h *Handler) FindBuy(c *gin.Context) {
 	name := c.Query("name")
 
-	prices, err := h.priceUsecase.FindBuyTemplate(name)
+	prices, err := h.priceUsecase.FindBuyTemplate(c.Request.Context(), name)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -51,9 +52,9 @@ func (h *Handler) FindBuy(c *gin.Context) {
 func (h *Handler) FindDetail(c *gin.Context) {
 	templateId := c.Query("templateId")
 
-	prices, err := h.priceUsecase.FindDetail(templateId)
+	prices, err := h.priceUsecase.FindDetail(c.Request.Context(), templateId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -63,9 +64,9 @@ func (h *Handler) FindDetail(c *gin.Context) {
 func (h *Handler) FindBuyDetail(c *gin.Context) {
 	templateId := c.Query("templateId")
 
-	prices, err := h.priceUsecase.FindBuyDetail(templateId)
+	prices, err := h.priceUsecase.FindBuyDetail(c.Request.Context(), templateId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -80,13 +81,13 @@ func (h *Handler) Create(c *gin.Context) {
 
 	name := gjson.Get(string(jsonData), "name")
 	if !name.Exists() || name.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_NAME_REQUIRED))
 		return
 	}
 
-	err = h.priceUsecase.Create(name.String())
+	err = h.priceUsecase.Create(c.Request.Context(), name.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -101,13 +102,13 @@ func (h *Handler) CreateBuy(c *gin.Context) {
 
 	name := gjson.Get(string(jsonData), "name")
 	if !name.Exists() || name.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_NAME_REQUIRED))
 		return
 	}
 
-	err = h.priceUsecase.CreateBuyTemplate(name.String())
+	err = h.priceUsecase.CreateBuyTemplate(c.Request.Context(), name.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -122,25 +123,25 @@ func (h *Handler) EditPrice(c *gin.Context) {
 
 	price := gjson.Get(string(jsonData), "price")
 	if !price.Exists() {
-		restutil.SendResponseFail(c, "Harap isi harga produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_PRICE_REQUIRED))
 		return
 	}
 
 	templateId := gjson.Get(string(jsonData), "templateId")
 	if !templateId.Exists() || templateId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 		return
 	}
 
 	productId := gjson.Get(string(jsonData), "productId")
 	if !productId.Exists() || productId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_REQUIRED))
 		return
 	}
 
-	err = h.priceUsecase.EditPrice(templateId.String(), productId.String(), price.Float())
+	err = h.priceUsecase.EditPrice(c.Request.Context(), templateId.String(), productId.String(), price.Float())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -155,25 +156,25 @@ func (h *Handler) EditBuyPrice(c *gin.Context) {
 
 	price := gjson.Get(string(jsonData), "price")
 	if !price.Exists() {
-		restutil.SendResponseFail(c, "Harap isi harga produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_PRICE_REQUIRED))
 		return
 	}
 
 	templateId := gjson.Get(string(jsonData), "templateId")
 	if !templateId.Exists() || templateId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 		return
 	}
 
 	productId := gjson.Get(string(jsonData), "productId")
 	if !productId.Exists() || productId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_REQUIRED))
 		return
 	}
 
-	err = h.priceUsecase.EditBuyPrice(templateId.String(), productId.String(), price.Float())
+	err = h.priceUsecase.EditBuyPrice(c.Request.Context(), templateId.String(), productId.String(), price.Float())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -183,7 +184,7 @@ func (h *Handler) EditBuyPrice(c *gin.Context) {
 func (h *Handler) ApplyToCustomer(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -191,25 +192,25 @@ func (h *Handler) ApplyToCustomer(c *gin.Context) {
 	request := &pricedomain.ApplyToCustomerReq{}
 	err = json.Unmarshal(jsonData, request)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if request.TemplateID == "" {
-		restutil.SendResponseFail(c, "Harap pilih template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 		return
 	}
 
 	if len(request.CustomerIDs) == 0 {
-		restutil.SendResponseFail(c, "Harap pilih customer")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_CUSTOMER_REQUIRED))
 		return
 	}
 
 	userId := restutil.GetSession(c).UserID
-	err = h.priceUsecase.ApplyToCustomer(request.TemplateID, request.CustomerIDs, userId)
+	err = h.priceUsecase.ApplyToCustomer(c.Request.Context(), request.TemplateID, request.CustomerIDs, userId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -219,7 +220,7 @@ func (h *Handler) ApplyToCustomer(c *gin.Context) {
 func (h *Handler) ApplyToTrx(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -227,25 +228,25 @@ func (h *Handler) ApplyToTrx(c *gin.Context) {
 	request := &pricedomain.ApplyToTrxReq{}
 	err = json.Unmarshal(jsonData, request)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if request.TemplateID == "" {
-		restutil.SendResponseFail(c, "Harap pilih template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 		return
 	}
 
 	if request.Date == "" {
-		restutil.SendResponseFail(c, "Harap pilih tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_SELECT))
 		return
 	}
 
 	userId := restutil.GetSession(c).UserID
-	err = h.priceUsecase.ApplyToTrx(request.TemplateID, request.Date, userId)
+	err = h.priceUsecase.ApplyToTrx(c.Request.Context(), request.TemplateID, request.Date, userId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -255,7 +256,7 @@ func (h *Handler) ApplyToTrx(c *gin.Context) {
 func (h *Handler) DeleteTemplate(c *gin.Context) {
 	jsonData, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -263,17 +264,17 @@ func (h *Handler) DeleteTemplate(c *gin.Context) {
 	request := &pricedomain.DeleteTemplateReq{}
 	err = json.Unmarshal(jsonData, request)
 	if request.TemplateID != "" {
-		h.priceUsecase.DeleteTemplate(request.TemplateID)
+		h.priceUsecase.DeleteTemplate(c.Request.Context(), request.TemplateID)
 		restutil.SendResponseOk(c, "Template berhasil dihapus", nil)
 		return
 	}
-	restutil.SendResponseFail(c, "Harap pilih template")
+	restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 }
 
 func (h *Handler) DeleteBuyTemplate(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -281,15 +282,15 @@ func (h *Handler) DeleteBuyTemplate(c *gin.Context) {
 	request := &pricedomain.DeleteTemplateReq{}
 	err = json.Unmarshal(jsonData, request)
 	if request.TemplateID != "" {
-		err = h.priceUsecase.DeleteBuyTemplate(request.TemplateID)
+		err = h.priceUsecase.DeleteBuyTemplate(c.Request.Context(), request.TemplateID)
 		if err != nil {
-			restutil.SendResponseFail(c, err.Error())
+			restutil.SendError(c, err)
 			return
 		}
 		restutil.SendResponseOk(c, "Template berhasil dihapus", nil)
 		return
 	}
-	restutil.SendResponseFail(c, "Harap pilih template")
+	restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 }
 
 func (h *Handler) CopyTemplate(c *gin.Context) {
@@ -300,19 +301,19 @@ func (h *Handler) CopyTemplate(c *gin.Context) {
 
 	templateId := gjson.Get(string(jsonData), "templateId")
 	if !templateId.Exists() || templateId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !name.Exists() || name.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_NAME_REQUIRED))
 		return
 	}
 
-	err = h.priceUsecase.CopyTemplate(templateId.String(), name.String())
+	err = h.priceUsecase.CopyTemplate(c.Request.Context(), templateId.String(), name.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -327,19 +328,19 @@ func (h *Handler) CopyBuyTemplate(c *gin.Context) {
 
 	templateId := gjson.Get(string(jsonData), "templateId")
 	if !templateId.Exists() || templateId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !name.Exists() || name.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama template")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_NAME_REQUIRED))
 		return
 	}
 
-	err = h.priceUsecase.CopyBuyTemplate(templateId.String(), name.String())
+	err = h.priceUsecase.CopyBuyTemplate(c.Request.Context(), templateId.String(), name.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -355,17 +356,17 @@ func (h *Handler) Download(c *gin.Context) {
 	request := &pricedomain.Download{}
 	err = json.Unmarshal(jsonData, request)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if request != nil && request.TemplateDetailIDs != nil && len(request.TemplateDetailIDs) > 0 {
-		h.priceUsecase.Download(*request)
+		h.priceUsecase.Download(c.Request.Context(), *request)
 		restutil.SendResponseOk(c, "Template berhasil diunduh", nil)
 		return
 	}
-	restutil.SendResponseFail(c, "Harap pilih template")
+	restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 }
 
 func (h *Handler) DownloadBuy(c *gin.Context) {
@@ -377,15 +378,15 @@ func (h *Handler) DownloadBuy(c *gin.Context) {
 	request := &pricedomain.Download{}
 	err = json.Unmarshal(jsonData, request)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if request != nil && request.TemplateDetailIDs != nil && len(request.TemplateDetailIDs) > 0 {
-		h.priceUsecase.DownloadBuy(*request)
+		h.priceUsecase.DownloadBuy(c.Request.Context(), *request)
 		restutil.SendResponseOk(c, "Template berhasil diunduh", nil)
 		return
 	}
-	restutil.SendResponseFail(c, "Harap pilih template")
+	restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TEMPLATE_REQUIRED))
 }
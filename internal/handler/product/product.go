@@ -1,11 +1,13 @@
 package producthandler
 
 import (
+	productdomain "dromatech/pos-backend/internal/domain/product"
 	productusecase "dromatech/pos-backend/internal/usecase/product"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"io/ioutil"
 	"strconv"
@@ -23,6 +25,11 @@ func New(productUsecase productusecase.ProductUsecase) *Handler {
 }
 
 func (h *Handler) Find(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, productdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 	name := c.Query("name")
@@ -32,35 +39,41 @@ func (h *Handler) Find(c *gin.Context) {
 	if active != "" {
 		parsedBool, err := strconv.ParseBool(active)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(c.Request.Context()).Error(err.Error())
 			activeBool = nil
 		} else {
 			activeBool = &parsedBool
 		}
 	}
 
-	products, err := h.productUsecase.Find(id, code, name, activeBool)
+	products, total, err := h.productUsecase.Find(c.Request.Context(), id, code, name, activeBool, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) FindActive(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, productdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 	name := c.Query("name")
 
 	var activeBool = true
 
-	products, err := h.productUsecase.Find(id, code, name, &activeBool)
+	products, total, err := h.productUsecase.Find(c.Request.Context(), id, code, name, &activeBool, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
+		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -71,27 +84,27 @@ func (h *Handler) Create(c *gin.Context) {
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_CODE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_NAME_REQUIRED))
 		return
 	}
 
 	unitId := gjson.Get(string(jsonData), "unitId")
 	if !unitId.Exists() || unitId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih satuan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_UNIT_REQUIRED))
 		return
 	}
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.productUsecase.Create(code.String(), name.String(), description.String(), unitId.String())
+	err = h.productUsecase.Create(c.Request.Context(), code.String(), name.String(), description.String(), unitId.String(), taxRateFrom(jsonData))
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -106,41 +119,51 @@ func (h *Handler) Edit(c *gin.Context) {
 
 	id := gjson.Get(string(jsonData), "id")
 	if !id.Exists() || id.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih produk yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_SELECT))
 		return
 	}
 
 	unitId := gjson.Get(string(jsonData), "unitId")
 	if !unitId.Exists() || unitId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih unit")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_UNIT_REQUIRED))
 		return
 	}
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_CODE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_NAME_REQUIRED))
 		return
 	}
 
 	active := gjson.Get(string(jsonData), "active")
 	if !active.Exists() {
-		restutil.SendResponseFail(c, "Harap pilih status")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_STATUS_REQUIRED))
 		return
 	}
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.productUsecase.Edit(id.String(), unitId.String(), code.String(), name.String(), description.String(), active.Bool())
+	err = h.productUsecase.Edit(c.Request.Context(), id.String(), unitId.String(), code.String(), name.String(), description.String(), active.Bool(), taxRateFrom(jsonData))
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
 	restutil.SendResponseOk(c, "Produk berhasil diperbarui", nil)
 }
+
+// taxRateFrom reads the PPN rate of a product from its create or edit body, nil when it is missing or null
+func taxRateFrom(jsonData []byte) *float64 {
+	taxRate := gjson.GetBytes(jsonData, "taxRate")
+	if !taxRate.Exists() || taxRate.Type == gjson.Null {
+		return nil
+	}
+	rate := taxRate.Float()
+	return &rate
+}
@@ -1,7 +1,9 @@
 package rolehandler
 
 import (
+	"context"
 	roledomain "dromatech/pos-backend/internal/domain/role"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -10,11 +12,11 @@ import (
 )
 
 type roleUsecase interface {
-	GetActiveRole() ([]*roledomain.RoleResponseModel, error)
-	FindPermissions(roleId string) ([]*roledomain.Permission, error)
-	RegisterRole(roleName string, permissions []string) error
-	GetAllRole() ([]*roledomain.Role, error)
-	EditRole(roleId string, roleName string, active bool, permissions []string) error
+	GetActiveRole(ctx context.Context) ([]*roledomain.RoleResponseModel, error)
+	FindPermissions(ctx context.Context, roleId string) ([]*roledomain.Permission, error)
+	RegisterRole(ctx context.Context, role *roledomain.Role) error
+	GetAllRole(ctx context.Context) ([]*roledomain.Role, error)
+	EditRole(ctx context.Context, role *roledomain.Role) error
 }
 
 // Handler defines the handler
@@ -29,18 +31,18 @@ func New(roleusecase roleUsecase) *Handler {
 }
 
 func (h *Handler) GetActive(c *gin.Context) {
-	roles, err := h.roleusecase.GetActiveRole()
+	roles, err := h.roleusecase.GetActiveRole(c.Request.Context())
 	if err != nil {
-		restutil.SendResponseFail(c, "Terjadi kesalahan saat pengambilan data Role")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_FETCH))
 		return
 	}
 	restutil.SendResponseOk(c, "", roles)
 }
 
 func (h *Handler) GetAll(c *gin.Context) {
-	roles, err := h.roleusecase.GetAllRole()
+	roles, err := h.roleusecase.GetAllRole(c.Request.Context())
 	if err != nil {
-		restutil.SendResponseFail(c, "Terjadi kesalahan saat pengambilan data Role")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_FETCH))
 		return
 	}
 	restutil.SendResponseOk(c, "", roles)
@@ -48,9 +50,9 @@ func (h *Handler) GetAll(c *gin.Context) {
 
 func (h *Handler) FindPermissions(c *gin.Context) {
 	roleId := c.Query("roleId")
-	permissions, err := h.roleusecase.FindPermissions(roleId)
+	permissions, err := h.roleusecase.FindPermissions(c.Request.Context(), roleId)
 	if err != nil {
-		restutil.SendResponseFail(c, "Terjadi kesalahan saat pengambilan data Role")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_FETCH))
 		return
 	}
 	restutil.SendResponseOk(c, "", permissions)
@@ -63,14 +65,16 @@ func (h *Handler) RegisterRole(c *gin.Context) {
 	}
 
 	roleName := gjson.Get(string(jsonData), "roleName")
+	requireTwoFactor := gjson.Get(string(jsonData), "requireTwoFactor")
+	allData := gjson.Get(string(jsonData), "allData")
 	permissionArray := gjson.Get(string(jsonData), "permissions")
 
 	if !roleName.Exists() || roleName.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama role")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_NAME_REQUIRED))
 		return
 	}
 	if !permissionArray.Exists() || len(permissionArray.Array()) <= 0 {
-		restutil.SendResponseFail(c, "Harap pilih ability")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ABILITY_REQUIRED))
 		return
 	}
 
@@ -79,9 +83,17 @@ func (h *Handler) RegisterRole(c *gin.Context) {
 		permissions = append(permissions, p.String())
 	}
 
-	err = h.roleusecase.RegisterRole(roleName.String(), permissions)
+	role := &roledomain.Role{
+		Name:             roleName.String(),
+		Active:           true,
+		RequireTwoFactor: requireTwoFactor.Bool(),
+		// roles keep reading all data unless restricted explicitly
+		AllData:     !allData.Exists() || allData.Bool(),
+		Permissions: permissions,
+	}
+	err = h.roleusecase.RegisterRole(c.Request.Context(), role)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 	restutil.SendResponseOk(c, "Role baru berhasil ditambahkan", nil)
@@ -96,22 +108,24 @@ func (h *Handler) EditRole(c *gin.Context) {
 	roleId := gjson.Get(string(jsonData), "roleId")
 	roleName := gjson.Get(string(jsonData), "roleName")
 	active := gjson.Get(string(jsonData), "active")
+	requireTwoFactor := gjson.Get(string(jsonData), "requireTwoFactor")
+	allData := gjson.Get(string(jsonData), "allData")
 	permissionArray := gjson.Get(string(jsonData), "permissions")
 
 	if !roleId.Exists() || roleId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih role terlebih dahulu")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_REQUIRED))
 		return
 	}
 	if !roleName.Exists() || roleName.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama role")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_NAME_REQUIRED))
 		return
 	}
 	if !active.Exists() {
-		restutil.SendResponseFail(c, "Harap pilih status")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_STATUS_REQUIRED))
 		return
 	}
 	if !permissionArray.Exists() || len(permissionArray.Array()) <= 0 {
-		restutil.SendResponseFail(c, "Harap pilih ability")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ABILITY_REQUIRED))
 		return
 	}
 
@@ -120,9 +134,17 @@ func (h *Handler) EditRole(c *gin.Context) {
 		permissions = append(permissions, p.String())
 	}
 
-	err = h.roleusecase.EditRole(roleId.String(), roleName.String(), active.Bool(), permissions)
+	role := &roledomain.Role{
+		ID:               roleId.String(),
+		Name:             roleName.String(),
+		Active:           active.Bool(),
+		RequireTwoFactor: requireTwoFactor.Bool(),
+		AllData:          !allData.Exists() || allData.Bool(),
+		Permissions:      permissions,
+	}
+	err = h.roleusecase.EditRole(c.Request.Context(), role)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 	restutil.SendResponseOk(c, "Role berhasil diperbarui", nil)
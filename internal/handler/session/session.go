@@ -1,8 +1,12 @@
 package sessionhandler
 
 import (
+	"context"
 	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	queryutil "dromatech/pos-backend/internal/util/query"
 	restutil "dromatech/pos-backend/internal/util/rest"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,10 +16,17 @@ import (
 )
 
 type sessionUsecase interface {
-	Login(username string, password string) (*sessiondomain.Session, error)
-	Logout(token string)
-	AuthCheck(token string, requestorPath string) (string, int, *sessiondomain.Session)
-	GetSession(token string) *sessiondomain.Session
+	Login(ctx context.Context, username, password, clientIP, userAgent string) (*sessiondomain.Session, *sessiondomain.LoginChallenge, error)
+	VerifyLogin(ctx context.Context, challengeToken, code, clientIP, userAgent string) (*sessiondomain.Session, error)
+	Logout(ctx context.Context, token string)
+	AuthCheck(ctx context.Context, token string, method string, requestorPath string) (string, int, *sessiondomain.Session)
+	AuthCheckApiKey(ctx context.Context, key, clientIP, method, requestorPath string) (int, *sessiondomain.Session)
+	GetSession(ctx context.Context, token string) *sessiondomain.Session
+	FindSessions(ctx context.Context, userID string) []*sessiondomain.SessionInfo
+	Revoke(ctx context.Context, id string) error
+	RevokeUser(ctx context.Context, userID string) error
+	FindLoginHistory(ctx context.Context, userID, startDate, endDate string, list queryutil.ListParam) ([]*sessiondomain.LoginHistory, int64, error)
+	SelectBranch(ctx context.Context, token string, branchID string) (*sessiondomain.Session, error)
 }
 
 // Handler defines the handler
@@ -23,13 +34,30 @@ type Handler struct {
 	sessionUc sessionUsecase
 }
 
+// API_KEY_HEADER carries the api key of a service account in place of the token header
+const API_KEY_HEADER = "X-API-Key"
+
 var WhitelistPath = map[string]bool{
-	"/api/auth/login":           true,
-	"/api/auth/logout":          true,
-	"/api/auth/getmenu":         true,
-	"/api/user/edit":            true,
+	"/api/auth/login":               true,
+	"/api/auth/login/verify":        true,
+	"/api/auth/logout":              true,
+	"/api/auth/getmenu":             true,
+	"/api/auth/branch":              true,
+	"/api/user/edit":                true,
+	"/api/user/change-password":     true,
+	"/api/user/change-language":     true,
+	"/api/user/totp/setup":          true,
+	"/api/user/totp/enable":         true,
+	"/api/user/totp/disable":        true,
+	"/api/user/totp/recovery-codes": true,
+	"/api/ping":                     true,
+	"/api/docs/*any":                true,
+}
+
+// PasswordChangePath are the paths a session that has to change the password can still call
+var PasswordChangePath = map[string]bool{
 	"/api/user/change-password": true,
-	"/api/ping":                 true,
+	"/api/auth/logout":          true,
 }
 
 func New(sessionUsecase sessionUsecase) *Handler {
@@ -43,7 +71,11 @@ func (h *Handler) AuthCheck(c *gin.Context) {
 	token := c.GetHeader("token")
 	if isWhitelistedPath(path) {
 		if token != "" {
-			session := h.sessionUc.GetSession(token)
+			session := h.sessionUc.GetSession(c.Request.Context(), token)
+			if session != nil && session.PasswordChangeRequired && !PasswordChangePath[path] {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
 			if session != nil {
 				restutil.SetSession(c, session)
 			}
@@ -52,9 +84,21 @@ func (h *Handler) AuthCheck(c *gin.Context) {
 		return
 	}
 
-	_, status, session := h.sessionUc.AuthCheck(token, path)
+	var status int
+	var session *sessiondomain.Session
+	if apiKey := c.GetHeader(API_KEY_HEADER); token == "" && apiKey != "" {
+		status, session = h.sessionUc.AuthCheckApiKey(c.Request.Context(), apiKey, c.ClientIP(), c.Request.Method, path)
+	} else {
+		_, status, session = h.sessionUc.AuthCheck(c.Request.Context(), token, c.Request.Method, path)
+	}
 	if status == 200 {
 		restutil.SetSession(c, session)
+		if !session.AllData {
+			c.Request = c.Request.WithContext(scopeutil.NewContext(c.Request.Context(), session.UserID))
+		}
+		if session.BranchID != "" {
+			c.Request = c.Request.WithContext(scopeutil.NewBranchContext(c.Request.Context(), session.BranchID))
+		}
 		c.Next()
 		return
 	} else {
@@ -90,44 +134,197 @@ func (h *Handler) Login(c *gin.Context) {
 
 	username := gjson.Get(string(jsonData), "username")
 	if !username.Exists() || username.String() == "" {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap isi username", nil))
+		restutil.SendError(c, restutil.ErrRequired("username", i18nutil.T(c.Request.Context(), i18nutil.ERR_USERNAME_REQUIRED)))
 		return
 	}
 	password := gjson.Get(string(jsonData), "password")
 	if !password.Exists() || password.String() == "" {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap isi password", nil))
+		restutil.SendError(c, restutil.ErrRequired("password", i18nutil.T(c.Request.Context(), i18nutil.ERR_PASSWORD_REQUIRED)))
+		return
+	}
+
+	session, challenge, err := h.sessionUc.Login(c.Request.Context(), username.String(), password.String(), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	if challenge != nil {
+		restutil.SendResponseOk(c, "", challenge)
+		return
+	}
+
+	h.sendSession(c, session)
+}
+
+// VerifyLogin is the second login step of users with two-factor authentication
+func (h *Handler) VerifyLogin(c *gin.Context) {
+	jsonData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	challengeToken := gjson.Get(string(jsonData), "challengeToken")
+	if !challengeToken.Exists() || challengeToken.String() == "" {
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_TOTP_CHALLENGE_INVALID)))
+		return
+	}
+	code := gjson.Get(string(jsonData), "code")
+	if !code.Exists() || code.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("code", i18nutil.T(c.Request.Context(), i18nutil.ERR_TOTP_CODE_REQUIRED)))
 		return
 	}
 
-	session, err := h.sessionUc.Login(username.String(), password.String())
+	session, err := h.sessionUc.VerifyLogin(c.Request.Context(), challengeToken.String(), code.String(), c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, err.Error(), nil))
+		restutil.SendError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, restutil.CreateResponse(0, "", session))
+	h.sendSession(c, session)
+}
+
+func (h *Handler) sendSession(c *gin.Context, session *sessiondomain.Session) {
+	// the user preference wins over Accept-Language once the user is known
+	ctx := c.Request.Context()
+	if session.Language != "" {
+		ctx = i18nutil.NewContext(ctx, session.Language)
+	}
+
+	c.JSON(http.StatusOK, restutil.CreateResponse(0, "", translateSession(ctx, session)))
 }
 
 func (h *Handler) Logout(c *gin.Context) {
 	token := c.GetHeader("token")
-	h.sessionUc.Logout(token)
+	h.sessionUc.Logout(c.Request.Context(), token)
 	restutil.SendResponseOk(c, "Berhasil logout", nil)
 }
 
 func (h *Handler) GetMenu(c *gin.Context) {
 	token := c.GetHeader("token")
 	if token == "" {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap melakukan login terlebih dahulu", nil))
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_LOGIN_REQUIRED)))
 		return
 	}
 
-	session := h.sessionUc.GetSession(token)
+	session := h.sessionUc.GetSession(c.Request.Context(), token)
 	if session == nil {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap melakukan login terlebih dahulu", nil))
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_LOGIN_REQUIRED)))
+		return
+	}
+
+	restutil.SendResponseOk(c, "", translateSession(c.Request.Context(), session))
+}
+
+// SelectBranch switches the active branch of the session, the session is answered like getmenu
+func (h *Handler) SelectBranch(c *gin.Context) {
+	token := c.GetHeader("token")
+	if token == "" {
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_LOGIN_REQUIRED)))
+		return
+	}
+
+	jsonData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	branchID := gjson.Get(string(jsonData), "branchId")
+	if !branchID.Exists() || branchID.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("branchId", i18nutil.T(c.Request.Context(), i18nutil.ERR_BRANCH_SELECT)))
+		return
+	}
+
+	session, err := h.sessionUc.SelectBranch(c.Request.Context(), token, branchID.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", translateSession(c.Request.Context(), session))
+}
+
+func (h *Handler) FindSessions(c *gin.Context) {
+	userID := c.Query("userId")
+
+	sessions := h.sessionUc.FindSessions(c.Request.Context(), userID)
+	restutil.SendResponseOk(c, "", sessions)
+}
+
+func (h *Handler) Revoke(c *gin.Context) {
+	jsonData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+		return
+	}
+
+	id := gjson.Get(string(jsonData), "id")
+	if !id.Exists() || id.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("id", i18nutil.T(c.Request.Context(), i18nutil.ERR_SESSION_SELECT)))
+		return
+	}
+
+	err = h.sessionUc.Revoke(c.Request.Context(), id.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Sesi berhasil dihentikan", nil)
+}
+
+func (h *Handler) RevokeUser(c *gin.Context) {
+	jsonData, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+		return
+	}
+
+	userID := gjson.Get(string(jsonData), "userId")
+	if !userID.Exists() || userID.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	err = h.sessionUc.RevokeUser(c.Request.Context(), userID.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Semua sesi user berhasil dihentikan", nil)
+}
+
+func (h *Handler) FindLoginHistory(c *gin.Context) {
+	userID := c.Query("userId")
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+	list, ok := restutil.GetListParam(c, sessiondomain.HistorySortFields...)
+	if !ok {
+		return
+	}
+
+	histories, total, err := h.sessionUc.FindLoginHistory(c.Request.Context(), userID, startDate, endDate, list)
+	if err != nil {
+		restutil.SendError(c, err)
 		return
 	}
+	restutil.SendResponseList(c, "", histories, list.Meta(total, len(histories)))
+}
 
-	restutil.SendResponseOk(c, "", session)
+// translateSession returns a copy of the session with menu names in the request language, the cached session is left as is
+func translateSession(ctx context.Context, session *sessiondomain.Session) *sessiondomain.Session {
+	translated := *session
+	translated.Menu = make([]*sessiondomain.Menu, 0, len(session.Menu))
+	for _, menu := range session.Menu {
+		m := *menu
+		m.Name = i18nutil.MenuName(ctx, menu.ID, menu.Name)
+		m.SubMenu = make([]*sessiondomain.SubMenu, 0, len(menu.SubMenu))
+		for _, subMenu := range menu.SubMenu {
+			s := *subMenu
+			s.Name = i18nutil.MenuName(ctx, subMenu.ID, subMenu.Name)
+			m.SubMenu = append(m.SubMenu, &s)
+		}
+		translated.Menu = append(translated.Menu, &m)
+	}
+	return &translated
 }
 
 func isWhitelistedPath(path string) bool {
@@ -1,25 +1,28 @@
 package supplierhandler
 
 import (
+	"context"
 	supplierdomain "dromatech/pos-backend/internal/domain/supplier"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"io/ioutil"
 	"strconv"
 )
 
 type supplierUsecase interface {
-	Find(id, code, name string, active *bool) ([]*supplierdomain.Supplier, error)
-	Create(code, name, description string) error
-	Edit(id, code, name, description string, active bool) error
-	GetBuyPrice(supplierId, unitId, date, productId string) ([]*supplierdomain.BuyPriceResponse, error)
-	UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error
-	AddBuyPrice(entity supplierdomain.AddPriceRequest, userId string) error
-	FindBuyPrice(customerId, unitId, productId string, latest *bool) ([]*supplierdomain.PriceResponse, error)
+	Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*supplierdomain.Supplier, int64, error)
+	Create(ctx context.Context, code, name, description string) error
+	Edit(ctx context.Context, id, code, name, description string, active bool) error
+	GetBuyPrice(ctx context.Context, supplierId, unitId, date, productId string) ([]*supplierdomain.BuyPriceResponse, error)
+	UpdateBuyPrice(ctx context.Context, request supplierdomain.BuyPriceRequest) error
+	AddBuyPrice(ctx context.Context, entity supplierdomain.AddPriceRequest, userId string) error
+	FindBuyPrice(ctx context.Context, customerId, unitId, productId string, latest *bool) ([]*supplierdomain.PriceResponse, error)
 }
 
 // Handler defines the handler
@@ -34,6 +37,11 @@ func New(supplierUsecase supplierUsecase) *Handler {
 }
 
 func (h *Handler) Find(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, supplierdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 	name := c.Query("name")
@@ -43,19 +51,20 @@ func (h *Handler) Find(c *gin.Context) {
 	if active != "" {
 		parsedBool, err := strconv.ParseBool(active)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(c.Request.Context()).Error(err.Error())
 			activeBool = nil
 		} else {
 			activeBool = &parsedBool
 		}
 	}
 
-	products, err := h.supplierUsecase.Find(id, code, name, activeBool)
+	products, total, err := h.supplierUsecase.Find(c.Request.Context(), id, code, name, activeBool, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
+		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -66,21 +75,21 @@ func (h *Handler) Create(c *gin.Context) {
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode supplier")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SUPPLIER_CODE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama supplier")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SUPPLIER_NAME_REQUIRED))
 		return
 	}
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.supplierUsecase.Create(code.String(), name.String(), description.String())
+	err = h.supplierUsecase.Create(c.Request.Context(), code.String(), name.String(), description.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -95,33 +104,33 @@ func (h *Handler) Edit(c *gin.Context) {
 
 	id := gjson.Get(string(jsonData), "id")
 	if !id.Exists() || id.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih supplier yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SUPPLIER_SELECT))
 		return
 	}
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode supplier")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SUPPLIER_CODE_REQUIRED))
 		return
 	}
 
 	name := gjson.Get(string(jsonData), "name")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama supplier")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SUPPLIER_NAME_REQUIRED))
 		return
 	}
 
 	active := gjson.Get(string(jsonData), "active")
 	if !active.Exists() {
-		restutil.SendResponseFail(c, "Harap pilih status")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_STATUS_REQUIRED))
 		return
 	}
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.supplierUsecase.Edit(id.String(), code.String(), name.String(), description.String(), active.Bool())
+	err = h.supplierUsecase.Edit(c.Request.Context(), id.String(), code.String(), name.String(), description.String(), active.Bool())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -134,9 +143,9 @@ func (h *Handler) GetBuyPrice(c *gin.Context) {
 	date := c.Query("date")
 	productId := c.Query("productId")
 
-	response, err := h.supplierUsecase.GetBuyPrice(supplierId, unitId, date, productId)
+	response, err := h.supplierUsecase.GetBuyPrice(c.Request.Context(), supplierId, unitId, date, productId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -146,22 +155,22 @@ func (h *Handler) GetBuyPrice(c *gin.Context) {
 func (h *Handler) UpdateBuyPrice(c *gin.Context) {
 	jsonData, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat memperbarui harga")
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_UPDATE))
 		return
 	}
 
 	request := supplierdomain.BuyPriceRequest{}
 	err = json.Unmarshal(jsonData, &request)
 	if err != nil {
-		logrus.Errorf(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat memperbarui harga")
+		logutil.WithContext(c.Request.Context()).Errorf(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_UPDATE))
 		return
 	}
 
-	err = h.supplierUsecase.UpdateBuyPrice(request)
+	err = h.supplierUsecase.UpdateBuyPrice(c.Request.Context(), request)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -171,23 +180,23 @@ func (h *Handler) UpdateBuyPrice(c *gin.Context) {
 func (h *Handler) AddPrice(c *gin.Context) {
 	jsonData, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat menambahkan data harga")
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_CREATE))
 		return
 	}
 
 	request := supplierdomain.AddPriceRequest{}
 	err = json.Unmarshal(jsonData, &request)
 	if err != nil {
-		logrus.Errorf(err.Error())
-		restutil.SendResponseFail(c, "Ada kesalahan saat menambahkan data harga")
+		logutil.WithContext(c.Request.Context()).Errorf(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRICE_CREATE))
 		return
 	}
 
 	userId := restutil.GetSession(c).UserID
-	err = h.supplierUsecase.AddBuyPrice(request, userId)
+	err = h.supplierUsecase.AddBuyPrice(c.Request.Context(), request, userId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -199,9 +208,9 @@ func (h *Handler) FindLatestPrice(c *gin.Context) {
 	unitId := c.Query("unitId")
 
 	latest := true
-	response, err := h.supplierUsecase.FindBuyPrice(supplierId, unitId, "", &latest)
+	response, err := h.supplierUsecase.FindBuyPrice(c.Request.Context(), supplierId, unitId, "", &latest)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -222,9 +231,9 @@ func (h *Handler) FindPrice(c *gin.Context) {
 		pointerBool = &latestBool
 	}
 
-	response, err := h.supplierUsecase.FindBuyPrice(supplierId, unitId, productId, pointerBool)
+	response, err := h.supplierUsecase.FindBuyPrice(c.Request.Context(), supplierId, unitId, productId, pointerBool)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -0,0 +1,79 @@
+package tenanthandler
+
+import (
+	"context"
+	"dromatech/pos-backend/config"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DEFAULT_HEADER names the tenant of a request when the config names no header
+const DEFAULT_HEADER = "X-Tenant"
+
+type tenantUsecase interface {
+	Resolve(ctx context.Context, code string) (context.Context, error)
+}
+
+type Handler struct {
+	tenantUsecase tenantUsecase
+	config        config.Tenant
+}
+
+// New creates tenant handler
+func New(tenantUsecase tenantUsecase, config config.Tenant) *Handler {
+	if config.Header == "" {
+		config.Header = DEFAULT_HEADER
+	}
+	return &Handler{
+		tenantUsecase: tenantUsecase,
+		config:        config,
+	}
+}
+
+// Resolve routes the request to the schema of its tenant, it runs before the session is read since sessions belong to a tenant.
+// A request naming no tenant is served by the default tenant, one naming an unknown tenant is refused.
+func (h *Handler) Resolve(c *gin.Context) {
+	if !h.config.Enabled {
+		c.Next()
+		return
+	}
+
+	code := h.tenantCode(c)
+	if code == "" {
+		c.Next()
+		return
+	}
+
+	ctx, err := h.tenantUsecase.Resolve(c.Request.Context(), code)
+	if err != nil {
+		restutil.SendError(c, err)
+		c.Abort()
+		return
+	}
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// tenantCode reads the tenant from the header, else from the subdomain of the configured domain the request was sent to
+func (h *Handler) tenantCode(c *gin.Context) string {
+	if code := strings.TrimSpace(c.GetHeader(h.config.Header)); code != "" {
+		return code
+	}
+	if h.config.Domain == "" {
+		return ""
+	}
+
+	host := c.Request.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	suffix := "." + strings.ToLower(h.config.Domain)
+	host = strings.ToLower(host)
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	return strings.TrimSuffix(host, suffix)
+}
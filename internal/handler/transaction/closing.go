@@ -0,0 +1,122 @@
+package transactionhandler
+
+import (
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CloseCash(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	var request transactiondomain.CashClosingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
+		return
+	}
+
+	closing, err := h.transactionUsecase.CloseCash(c.Request.Context(), userID, date)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Kas berhasil ditutup", closing)
+}
+
+func (h *Handler) FindClosing(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+	date, ok := queryDate(c, "date")
+	if !ok {
+		return
+	}
+
+	closing, err := h.transactionUsecase.FindClosing(c.Request.Context(), userID, date)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", closing)
+}
+
+func (h *Handler) FindClosingList(c *gin.Context) {
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	closings, err := h.transactionUsecase.FindClosingList(c.Request.Context(), c.Query("userId"), startDate, endDate)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", closings)
+}
+
+func (h *Handler) ReopenCash(c *gin.Context) {
+	supervisorID := restutil.GetSession(c).UserID
+
+	var request transactiondomain.CashClosingReopenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	err := h.transactionUsecase.ReopenCash(c.Request.Context(), supervisorID, request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Kas berhasil dibuka kembali", nil)
+}
+
+func (h *Handler) FindReopenHistory(c *gin.Context) {
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	history, err := h.transactionUsecase.FindReopenHistory(c.Request.Context(), c.Query("userId"), startDate, endDate)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", history)
+}
+
+// queryDate reads a required yyyy-mm-dd query parameter, the failure is already sent when false
+func queryDate(c *gin.Context, name string) (time.Time, bool) {
+	dateString := c.Query(name)
+	if dateString == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse("2006-01-02", dateString)
+	if err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
+		return time.Time{}, false
+	}
+	return date, true
+}
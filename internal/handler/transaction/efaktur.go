@@ -0,0 +1,59 @@
+package transactionhandler
+
+import (
+	"bytes"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	exportutil "dromatech/pos-backend/internal/util/export"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) AllocateTaxInvoiceNumbers(c *gin.Context) {
+	var request transactiondomain.TaxInvoiceAllocateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
+		return
+	}
+
+	allocated, err := h.transactionUsecase.AllocateTaxInvoiceNumbers(c.Request.Context(), request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Nomor seri faktur pajak berhasil diberikan", allocated)
+}
+
+func (h *Handler) ExportEFaktur(c *gin.Context) {
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	rows, err := h.transactionUsecase.ExportEFaktur(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	// written to a buffer first so a failure can still be answered with an error
+	var file bytes.Buffer
+	if err := exportutil.WriteRows(&file, rows...); err != nil {
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EFAKTUR_EXPORT))
+		return
+	}
+
+	fileName := fmt.Sprintf("efaktur_%s_%s.csv", startDate.Format("20060102"), endDate.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, exportutil.CONTENT_TYPE_CSV, file.Bytes())
+}
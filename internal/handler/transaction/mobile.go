@@ -1,7 +1,9 @@
 package transactionhandler
 
 import (
+	"context"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"time"
 
@@ -13,19 +15,19 @@ func (h *Handler) FindDana(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 	dateString := c.Query("date")
 	if dateString == "" {
-		restutil.SendResponseFail(c, "Harap isi tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateString)
 	if err != nil {
-		restutil.SendResponseFail(c, "Tanggal tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
 		return
 	}
 
-	dana, err := h.transactionUsecase.FindDana(userID, date)
+	dana, err := h.transactionUsecase.FindDana(c.Request.Context(), userID, date)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -39,14 +41,14 @@ func (h *Handler) CreateDana(c *gin.Context) {
 	// get request body
 	var request transactiondomain.DanaRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
 	// create dana
-	err := h.transactionUsecase.CreateDana(userID, request)
+	err := h.transactionUsecase.CreateDana(c.Request.Context(), userID, request)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal membuat dana")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -59,17 +61,13 @@ func (h *Handler) UpdateDana(c *gin.Context) {
 	// get request body
 	var request transactiondomain.DanaRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.UpdateDana(userID, request)
+	err := h.transactionUsecase.UpdateDana(c.Request.Context(), userID, request)
 	if err != nil {
-		if err.Error() == "not allowed" {
-			restutil.SendResponseFail(c, "Anda tidak diperbolehkan mengubah data ini")
-			return
-		}
-		restutil.SendResponseFail(c, "Gagal mengubah data")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -82,13 +80,13 @@ func (h *Handler) SendDana(c *gin.Context) {
 	// get request body
 	var request transactiondomain.DanaTransactionRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.SendDana(userID, request)
+	err := h.transactionUsecase.SendDana(c.Request.Context(), userID, request)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengirim dana")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -100,21 +98,13 @@ func (h *Handler) ApproveDana(c *gin.Context) {
 	// get id from request body
 	var request map[string]string
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.ApproveDana(userID, request["id"])
+	err := h.transactionUsecase.ApproveDana(c.Request.Context(), userID, request["id"])
 	if err != nil {
-		if err.Error() == "not allowed" {
-			restutil.SendResponseFail(c, "Anda tidak diperbolehkan mengubah dana ini")
-			return
-		}
-		if err.Error() == "not pending" {
-			restutil.SendResponseFail(c, "Pengiriman dana sudah tidak dapat diapprove")
-			return
-		}
-		restutil.SendResponseFail(c, "Gagal mengubah dana")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -127,40 +117,72 @@ func (h *Handler) RejectDana(c *gin.Context) {
 	// get id from request body
 	var request map[string]string
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.RejectDana(userID, request["id"])
+	err := h.transactionUsecase.RejectDana(c.Request.Context(), userID, request["id"])
 	if err != nil {
-		if err.Error() == "not allowed" {
-			restutil.SendResponseFail(c, "Anda tidak diperbolehkan menolak pengiriman dana ini")
-			return
-		}
-		if err.Error() == "not pending" {
-			restutil.SendResponseFail(c, "Pengiriman dana sudah tidak dapat ditolak")
-			return
-		}
-		restutil.SendResponseFail(c, "Gagal menolak pengiriman dana")
+		restutil.SendError(c, err)
 		return
 	}
 
 	restutil.SendResponseOk(c, "Pengiriman dana berhasil ditolak", nil)
 }
 
+func (h *Handler) ApproveDanaBulk(c *gin.Context) {
+	h.decideDanaBulk(c, h.transactionUsecase.ApproveDanaBulk)
+}
+
+func (h *Handler) RejectDanaBulk(c *gin.Context) {
+	h.decideDanaBulk(c, h.transactionUsecase.RejectDanaBulk)
+}
+
+// decideDanaBulk answers with the result of every transfer, also when some of them failed
+func (h *Handler) decideDanaBulk(c *gin.Context, decide func(ctx context.Context, userID string, ids []string) (*transactiondomain.DanaBulkResponse, error)) {
+	userID := restutil.GetSession(c).UserID
+
+	var request transactiondomain.DanaBulkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	response, err := decide(c.Request.Context(), userID, request.IDs)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", response)
+}
+
+// FindSupervisedTransfers lists the transfers between the users the session user manages
+func (h *Handler) FindSupervisedTransfers(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	transfers, err := h.transactionUsecase.FindSupervisedTransfers(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", transfers)
+}
+
 func (h *Handler) CancelSendDana(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 
 	// get id from request body
 	var request map[string]string
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.CancelSendDana(userID, request["id"])
+	err := h.transactionUsecase.CancelSendDana(c.Request.Context(), userID, request["id"])
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal membatalkan pengiriman dana")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -170,9 +192,9 @@ func (h *Handler) CancelSendDana(c *gin.Context) {
 func (h *Handler) FindUserMobile(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 
-	userMobile, err := h.transactionUsecase.FindUserMobile(userID)
+	userMobile, err := h.transactionUsecase.FindUserMobile(c.Request.Context(), userID)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -185,13 +207,13 @@ func (h *Handler) CreatePenjualan(c *gin.Context) {
 	// get request body
 	var request transactiondomain.TrxCreateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.CreatePenjualan(userID, request)
+	err := h.transactionUsecase.CreatePenjualan(c.Request.Context(), userID, request)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal membuat penjualan")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -204,13 +226,13 @@ func (h *Handler) DeletePenjualan(c *gin.Context) {
 	// get id from request body
 	var request map[string]string
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.DeletePenjualan(userID, request["id"])
+	err := h.transactionUsecase.DeletePenjualan(c.Request.Context(), userID, request["id"])
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal menghapus penjualan")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -221,19 +243,19 @@ func (h *Handler) FindPenjualan(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 	dateString := c.Query("date")
 	if dateString == "" {
-		restutil.SendResponseFail(c, "Harap isi tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateString)
 	if err != nil {
-		restutil.SendResponseFail(c, "Tanggal tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
 		return
 	}
 
-	penjualan, err := h.transactionUsecase.FindPenjualan(userID, date)
+	penjualan, err := h.transactionUsecase.FindPenjualan(c.Request.Context(), userID, date)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -246,13 +268,13 @@ func (h *Handler) CreateBelanja(c *gin.Context) {
 	// get request body
 	var request transactiondomain.TrxCreateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.CreateBelanja(userID, request)
+	err := h.transactionUsecase.CreateBelanja(c.Request.Context(), userID, request)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal membuat belanja")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -265,13 +287,13 @@ func (h *Handler) DeleteBelanja(c *gin.Context) {
 	// get id from request body
 	var request map[string]string
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.DeleteBelanja(userID, request["id"])
+	err := h.transactionUsecase.DeleteBelanja(c.Request.Context(), userID, request["id"])
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal menghapus belanja")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -282,19 +304,19 @@ func (h *Handler) FindBelanja(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 	dateString := c.Query("date")
 	if dateString == "" {
-		restutil.SendResponseFail(c, "Harap isi tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateString)
 	if err != nil {
-		restutil.SendResponseFail(c, "Tanggal tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
 		return
 	}
 
-	belanja, err := h.transactionUsecase.FindBelanja(userID, date)
+	belanja, err := h.transactionUsecase.FindBelanja(c.Request.Context(), userID, date)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -307,17 +329,17 @@ func (h *Handler) CreateOperasional(c *gin.Context) {
 	// get request body
 	var request transactiondomain.TrxCreateOperasionalRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.CreateOperasional(userID, request)
+	operasional, err := h.transactionUsecase.CreateOperasional(c.Request.Context(), userID, request)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal membuat operasional")
+		restutil.SendError(c, err)
 		return
 	}
 
-	restutil.SendResponseOk(c, "Operasional berhasil dibuat", nil)
+	restutil.SendResponseOk(c, "Operasional berhasil dibuat", operasional)
 }
 
 func (h *Handler) DeleteOperasional(c *gin.Context) {
@@ -326,13 +348,13 @@ func (h *Handler) DeleteOperasional(c *gin.Context) {
 	// get id from request body
 	var request map[string]string
 	if err := c.ShouldBindJSON(&request); err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
 		return
 	}
 
-	err := h.transactionUsecase.DeleteOperasional(userID, request["id"])
+	err := h.transactionUsecase.DeleteOperasional(c.Request.Context(), userID, request["id"])
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal menghapus operasional")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -343,19 +365,19 @@ func (h *Handler) FindOperasional(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 	dateString := c.Query("date")
 	if dateString == "" {
-		restutil.SendResponseFail(c, "Harap isi tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateString)
 	if err != nil {
-		restutil.SendResponseFail(c, "Tanggal tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
 		return
 	}
 
-	operasional, err := h.transactionUsecase.FindOperasional(userID, date)
+	operasional, err := h.transactionUsecase.FindOperasional(c.Request.Context(), userID, date)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -395,19 +417,19 @@ func (h *Handler) FindSaldo(c *gin.Context) {
 	userID := restutil.GetSession(c).UserID
 	dateString := c.Query("date")
 	if dateString == "" {
-		restutil.SendResponseFail(c, "Harap isi tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateString)
 	if err != nil {
-		restutil.SendResponseFail(c, "Tanggal tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
 		return
 	}
 
-	saldo, err := h.transactionUsecase.FindSaldo(userID, date)
+	saldo, err := h.transactionUsecase.FindSaldo(c.Request.Context(), userID, date)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -417,19 +439,19 @@ func (h *Handler) FindSaldo(c *gin.Context) {
 func (h *Handler) FindRekapitulasi(c *gin.Context) {
 	dateString := c.Query("date")
 	if dateString == "" {
-		restutil.SendResponseFail(c, "Harap isi tanggal")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_REQUIRED))
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateString)
 	if err != nil {
-		restutil.SendResponseFail(c, "Tanggal tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATE_INVALID))
 		return
 	}
 
-	rekapitulasi, err := h.transactionUsecase.FindRekapitulasi(date)
+	rekapitulasi, err := h.transactionUsecase.FindRekapitulasi(c.Request.Context(), date)
 	if err != nil {
-		restutil.SendResponseFail(c, "Gagal mengambil data")
+		restutil.SendError(c, err)
 		return
 	}
 
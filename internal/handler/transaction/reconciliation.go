@@ -0,0 +1,46 @@
+package transactionhandler
+
+import (
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) FindReconciliation(c *gin.Context) {
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	reconciliation, err := h.transactionUsecase.FindReconciliation(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Data berhasil diambil", reconciliation)
+}
+
+func (h *Handler) ApplyReconciliation(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	var request transactiondomain.ReconciliationApplyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	applied, err := h.transactionUsecase.ApplyReconciliation(c.Request.Context(), userID, request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Harga beli rata-rata berhasil diterapkan", applied)
+}
@@ -0,0 +1,86 @@
+package transactionhandler
+
+import (
+	"bytes"
+	exportutil "dromatech/pos-backend/internal/util/export"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) FindRekapRange(c *gin.Context) {
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	rekap, err := h.transactionUsecase.FindRekapRange(c.Request.Context(), startDate, endDate, c.Query("bucket"))
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Data berhasil diambil", rekap)
+}
+
+func (h *Handler) FindRekapBranch(c *gin.Context) {
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	rekap, err := h.transactionUsecase.FindRekapBranch(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "Data berhasil diambil", rekap)
+}
+
+func (h *Handler) ExportRekapRange(c *gin.Context) {
+	format := c.DefaultQuery("format", exportutil.FORMAT_XLSX)
+	contentType := exportutil.ContentType(format)
+	if contentType == "" {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_EXPORT_FORMAT_INVALID))
+		return
+	}
+	startDate, ok := queryDate(c, "startDate")
+	if !ok {
+		return
+	}
+	endDate, ok := queryDate(c, "endDate")
+	if !ok {
+		return
+	}
+
+	tables, err := h.transactionUsecase.ExportRekapRange(c.Request.Context(), startDate, endDate, c.Query("bucket"))
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	// written to a buffer first so a failure can still be answered with an error
+	var file bytes.Buffer
+	if err := exportutil.Write(&file, format, tables...); err != nil {
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	fileName := fmt.Sprintf("rekapitulasi_%s_%s.%s", startDate.Format("20060102"), endDate.Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, contentType, file.Bytes())
+}
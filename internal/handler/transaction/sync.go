@@ -0,0 +1,42 @@
+package transactionhandler
+
+import (
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) SyncPush(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	var request transactiondomain.SyncPushRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_FETCH_DATA))
+		return
+	}
+
+	response, err := h.transactionUsecase.SyncPush(c.Request.Context(), userID, request)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", response)
+}
+
+func (h *Handler) SyncChanges(c *gin.Context) {
+	userID := restutil.GetSession(c).UserID
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	response, err := h.transactionUsecase.SyncChanges(c.Request.Context(), userID, c.Query("cursor"), limit)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", response)
+}
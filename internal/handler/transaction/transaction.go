@@ -3,6 +3,8 @@ package transactionhandler
 import (
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
 	transactionusecase "dromatech/pos-backend/internal/usecase/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"encoding/json"
 	"fmt"
@@ -12,7 +14,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
@@ -36,20 +37,24 @@ func (h *Handler) Find(c *gin.Context) {
 	status := c.Query("status")
 	productID := c.Query("productId")
 	txId := c.Query("txId")
+	list, ok := restutil.GetListParam(c, transactiondomain.SellSortFields...)
+	if !ok {
+		return
+	}
 
-	transactions, err := h.transactionUsecase.ViewSellTransaction(startDate, endDate, code, stakeholderID, txType, status, productID, txId)
+	transactions, total, err := h.transactionUsecase.ViewSellTransaction(c.Request.Context(), startDate, endDate, code, stakeholderID, txType, status, productID, txId, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
-	restutil.SendResponseOk(c, "", transactions)
+	restutil.SendResponseList(c, "", transactions, list.Meta(total, len(transactions)))
 }
 
 func (h *Handler) Create(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -57,25 +62,25 @@ func (h *Handler) Create(c *gin.Context) {
 	transaction := &transactiondomain.Transaction{}
 	err = json.Unmarshal(jsonData, transaction)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if transaction.StakeholderID == "" {
-		restutil.SendResponseFail(c, "Harap isi kode stakeholder")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_STAKEHOLDER_CODE_REQUIRED))
 		return
 	}
 
 	if transaction.TransactionType == "" {
-		restutil.SendResponseFail(c, "Harap isi kode tipe transaksi")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_TYPE_REQUIRED))
 		return
 	}
 
 	transaction.UserId = restutil.GetSession(c).UserID
-	txId, err := h.transactionUsecase.CreateTransaction(transaction)
+	txId, err := h.transactionUsecase.CreateTransaction(c.Request.Context(), transaction)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -93,13 +98,13 @@ func (h *Handler) UpdateStatus(c *gin.Context) {
 
 	id := gjson.Get(string(jsonData), "transactionId")
 	if !id.Exists() || id.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih transaksi yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_SELECT))
 		return
 	}
 
-	err = h.transactionUsecase.UpdateStatus(id.String())
+	err = h.transactionUsecase.UpdateStatus(c.Request.Context(), id.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -115,13 +120,13 @@ func (h *Handler) CancelTrx(c *gin.Context) {
 
 	id := gjson.Get(string(jsonData), "transactionId")
 	if !id.Exists() || id.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih transaksi yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_SELECT))
 		return
 	}
 
-	err = h.transactionUsecase.CancelTrx(id.String())
+	err = h.transactionUsecase.CancelTrx(c.Request.Context(), id.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -137,43 +142,43 @@ func (h *Handler) UpdateBuyPrice(c *gin.Context) {
 
 	buyPrice := gjson.Get(string(jsonData), "buyPrice")
 	if !buyPrice.Exists() {
-		restutil.SendResponseFail(c, "Harap isi harga beli")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_BUY_PRICE_REQUIRED))
 		return
 	}
 
 	sellPrice := gjson.Get(string(jsonData), "sellPrice")
 	if !sellPrice.Exists() {
-		restutil.SendResponseFail(c, "Harap isi harga jual")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SELL_PRICE_REQUIRED))
 		return
 	}
 
 	quantity := gjson.Get(string(jsonData), "quantity")
 	if !buyPrice.Exists() {
-		restutil.SendResponseFail(c, "Harap isi jumlah jual")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_SELL_QUANTITY_REQUIRED))
 		return
 	}
 
 	buyQuantity := gjson.Get(string(jsonData), "buy_quantity")
 	if !sellPrice.Exists() {
-		restutil.SendResponseFail(c, "Harap isi harga beli")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_BUY_PRICE_REQUIRED))
 		return
 	}
 
 	transactionId := gjson.Get(string(jsonData), "transactionId")
 	if !transactionId.Exists() || transactionId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih transaksi")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_REQUIRED))
 		return
 	}
 
 	productId := gjson.Get(string(jsonData), "productId")
 	if !productId.Exists() || productId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_REQUIRED))
 		return
 	}
 
-	err = h.transactionUsecase.UpdateBuyPrice(transactionId.String(), productId.String(), buyPrice.Float(), sellPrice.Float(), quantity.Int(), buyQuantity.Int())
+	err = h.transactionUsecase.UpdateBuyPrice(c.Request.Context(), transactionId.String(), productId.String(), buyPrice.Float(), sellPrice.Float(), quantity.Int(), buyQuantity.Int())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -183,7 +188,7 @@ func (h *Handler) UpdateBuyPrice(c *gin.Context) {
 func (h *Handler) Update(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -191,20 +196,20 @@ func (h *Handler) Update(c *gin.Context) {
 	transaction := &transactiondomain.Transaction{}
 	err = json.Unmarshal(jsonData, transaction)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if transaction.ID == "" {
-		restutil.SendResponseFail(c, "Harap pilih transaksi yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_SELECT))
 		return
 	}
 
 	transaction.UserId = restutil.GetSession(c).UserID
-	err = h.transactionUsecase.UpdateTransaction(transaction)
+	err = h.transactionUsecase.UpdateTransaction(c.Request.Context(), transaction)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -220,9 +225,9 @@ func (h *Handler) FindReport(c *gin.Context) {
 	productID := c.Query("productId")
 	txId := c.Query("txId")
 
-	reports, err := h.transactionUsecase.FindReport(startDate, endDate, code, stakeholderID, txType, status, productID, txId)
+	reports, err := h.transactionUsecase.FindReport(c.Request.Context(), startDate, endDate, code, stakeholderID, txType, status, productID, txId)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -232,7 +237,7 @@ func (h *Handler) FindReport(c *gin.Context) {
 func (h *Handler) UpdateHargaBeli(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -240,25 +245,25 @@ func (h *Handler) UpdateHargaBeli(c *gin.Context) {
 	requestBody := &transactiondomain.UpdateHargaBeliRequest{}
 	err = json.Unmarshal(jsonData, requestBody)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if requestBody.TransactionDetailID == "" {
-		restutil.SendResponseFail(c, "Harap pilih data yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_DATA_SELECT))
 		return
 	}
 
 	if requestBody.BuyPrice <= 0 {
-		restutil.SendResponseFail(c, "Harap isi harga beli")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_BUY_PRICE_REQUIRED))
 		return
 	}
 
 	requestBody.WebUserID = restutil.GetSession(c).UserID
-	err = h.transactionUsecase.UpdateHargaBeli(*requestBody)
+	err = h.transactionUsecase.UpdateHargaBeli(c.Request.Context(), *requestBody)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -268,7 +273,7 @@ func (h *Handler) UpdateHargaBeli(c *gin.Context) {
 func (h *Handler) InsertTransactionBuy(c *gin.Context) {
 	jsonData, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
@@ -276,42 +281,42 @@ func (h *Handler) InsertTransactionBuy(c *gin.Context) {
 	requestBody := &transactiondomain.InsertTransactionBuyRequestBulk{}
 	err = json.Unmarshal(jsonData, requestBody)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
 		c.AbortWithError(400, fmt.Errorf("bad request"))
 		return
 	}
 
 	if requestBody.TransactionID == "" {
-		restutil.SendResponseFail(c, "Harap pilih data transaksi yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_TRANSACTION_SELECT))
 		return
 	}
 
 	if len(requestBody.Details) == 0 {
-		restutil.SendResponseFail(c, "Harap pilih produk")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_REQUIRED))
 		return
 	}
 
 	for _, detail := range requestBody.Details {
 		if detail.ProductID == "" {
-			restutil.SendResponseFail(c, "Harap pilih produk")
+			restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PRODUCT_REQUIRED))
 			return
 		}
 
 		if detail.Quantity <= 0 {
-			restutil.SendResponseFail(c, "Harap isi jumlah beli")
+			restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_BUY_QUANTITY_REQUIRED))
 			return
 		}
 
 		if detail.Price <= 0 {
-			restutil.SendResponseFail(c, "Harap isi harga beli")
+			restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_BUY_PRICE_REQUIRED))
 			return
 		}
 	}
 
 	requestBody.WebUserID = restutil.GetSession(c).UserID
-	err = h.transactionUsecase.InsertTransactionBuy(*requestBody)
+	err = h.transactionUsecase.InsertTransactionBuy(c.Request.Context(), *requestBody)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -323,7 +328,7 @@ func (h *Handler) FindCustomerCredit(c *gin.Context) {
 	sell := c.Query("sell")
 
 	if month == "" {
-		restutil.SendResponseFail(c, "Harap pilih bulan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_MONTH_REQUIRED))
 		return
 	}
 
@@ -339,17 +344,17 @@ func (h *Handler) FindCustomerCredit(c *gin.Context) {
 
 	monnthTime, err := time.Parse("2006-01", month)
 	if err != nil {
-		restutil.SendResponseFail(c, "Harap pilih bulan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_MONTH_REQUIRED))
 		return
 	}
 	if monnthTime.Before(time.Date(2023, 9, 1, 0, 0, 0, 0, monnthTime.Location())) {
-		restutil.SendResponseFail(c, "Bulan tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_MONTH_INVALID))
 		return
 	}
 
-	response, err := h.transactionUsecase.FindCustomerCredit(monnthTime, sellBool)
+	response, err := h.transactionUsecase.FindCustomerCredit(c.Request.Context(), monnthTime, sellBool)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -361,28 +366,28 @@ func (h *Handler) FindCustomerReport(c *gin.Context) {
 	stakeholderId := c.Query("stakeholderId")
 
 	if stakeholderId == "" {
-		restutil.SendResponseFail(c, "Harap pilih stakeholder")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_STAKEHOLDER_REQUIRED))
 		return
 	}
 
 	if month == "" {
-		restutil.SendResponseFail(c, "Harap pilih bulan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_MONTH_REQUIRED))
 		return
 	}
 
 	monnthTime, err := time.Parse("2006-01", month)
 	if err != nil {
-		restutil.SendResponseFail(c, "Harap pilih bulan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_MONTH_REQUIRED))
 		return
 	}
 	if monnthTime.Before(time.Date(2023, 9, 1, 0, 0, 0, 0, monnthTime.Location())) {
-		restutil.SendResponseFail(c, "Bulan tidak valid")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_MONTH_INVALID))
 		return
 	}
 
-	response, err := h.transactionUsecase.FindCustomerReport(stakeholderId, monnthTime)
+	response, err := h.transactionUsecase.FindCustomerReport(c.Request.Context(), stakeholderId, monnthTime)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
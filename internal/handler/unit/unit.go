@@ -1,20 +1,23 @@
 package unithandler
 
 import (
+	"context"
 	unitdomain "dromatech/pos-backend/internal/domain/unit"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
 	restutil "dromatech/pos-backend/internal/util/rest"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"io/ioutil"
 	"strconv"
 )
 
 type unitUsecase interface {
-	Find(id, code string, active *bool) ([]*unitdomain.Unit, error)
-	Create(code, description string) error
-	Edit(id, code, description string, active *bool) error
+	Find(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*unitdomain.Unit, int64, error)
+	Create(ctx context.Context, code, description string) error
+	Edit(ctx context.Context, id, code, description string, active *bool) error
 }
 
 // Handler defines the handler
@@ -29,6 +32,11 @@ func New(unitUsecase unitUsecase) *Handler {
 }
 
 func (h *Handler) Find(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, unitdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 	active := c.Query("active")
@@ -37,33 +45,40 @@ func (h *Handler) Find(c *gin.Context) {
 	if active != "" {
 		parsedBool, err := strconv.ParseBool(active)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(c.Request.Context()).Error(err.Error())
 			activeBool = nil
 		} else {
 			activeBool = &parsedBool
 		}
 	}
 
-	products, err := h.unitUsecase.Find(id, code, activeBool)
+	products, total, err := h.unitUsecase.Find(c.Request.Context(), id, code, activeBool, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
+		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) FindActive(c *gin.Context) {
+	list, ok := restutil.GetListParam(c, unitdomain.SortFields...)
+	if !ok {
+		return
+	}
+
 	id := c.Query("id")
 	code := c.Query("code")
 
 	var activeBool = true
 
-	products, err := h.unitUsecase.Find(id, code, &activeBool)
+	products, total, err := h.unitUsecase.Find(c.Request.Context(), id, code, &activeBool, list)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
+		return
 	}
 
-	restutil.SendResponseOk(c, "", products)
+	restutil.SendResponseList(c, "", products, list.Meta(total, len(products)))
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -74,15 +89,15 @@ func (h *Handler) Create(c *gin.Context) {
 
 	code := gjson.Get(string(jsonData), "code")
 	if !code.Exists() || code.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kode satuan")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_UNIT_CODE_REQUIRED))
 		return
 	}
 
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.unitUsecase.Create(code.String(), description.String())
+	err = h.unitUsecase.Create(c.Request.Context(), code.String(), description.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -97,7 +112,7 @@ func (h *Handler) Edit(c *gin.Context) {
 
 	id := gjson.Get(string(jsonData), "id")
 	if !id.Exists() || id.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih unit yang akan diperbarui")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_UNIT_SELECT))
 		return
 	}
 
@@ -111,9 +126,9 @@ func (h *Handler) Edit(c *gin.Context) {
 	code := gjson.Get(string(jsonData), "code")
 	description := gjson.Get(string(jsonData), "description")
 
-	err = h.unitUsecase.Edit(id.String(), code.String(), description.String(), activeBool)
+	err = h.unitUsecase.Edit(c.Request.Context(), id.String(), code.String(), description.String(), activeBool)
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 
@@ -1,25 +1,42 @@
 package webuserhandler
 
 import (
+	"context"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	queryutil "dromatech/pos-backend/internal/util/query"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
 	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
 	restutil "dromatech/pos-backend/internal/util/rest"
 )
 
 type webuserUsecase interface {
-	EditUser(userId, name, username, role, status string) error
-	ChangePassword(userId, password1, password2 string) error
-	RegisterUser(creatorId, name, username, password, roleId string) error
-	FindAllUser() ([]*webuserdomain.WebUser, error)
-	ForceChangePassword(userId, password string) error
-	ChangeStatus(userId string, status bool)
+	EditUser(ctx context.Context, userId, name, username, role, status string) error
+	ChangePassword(ctx context.Context, userId, password1, password2 string) error
+	RegisterUser(ctx context.Context, creatorId, name, username, password, roleId string) error
+	RegisterServiceAccount(ctx context.Context, creatorId, name, username, roleId string) error
+	FindAllUser(ctx context.Context, list queryutil.ListParam) ([]*webuserdomain.WebUser, int64, error)
+	ForceChangePassword(ctx context.Context, userId, password string) error
+	ChangeStatus(ctx context.Context, userId string, status bool)
+	ChangeLanguage(ctx context.Context, userId string, language string) error
+	Unlock(ctx context.Context, userId string) error
+	SetupTotp(ctx context.Context, userId string) (*webuserdomain.TotpSetup, error)
+	EnableTotp(ctx context.Context, userId, code string) (*webuserdomain.RecoveryCodes, error)
+	DisableTotp(ctx context.Context, userId, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId, code string) (*webuserdomain.RecoveryCodes, error)
+	ResetTotp(ctx context.Context, userId string) error
+	FindStakeholders(ctx context.Context, userId string) (*webuserdomain.Stakeholders, error)
+	AssignStakeholders(ctx context.Context, userId string, customerIds, supplierIds []string) error
+	FindManagedUsers(ctx context.Context, supervisorId string) ([]*webuserdomain.ManagedUser, error)
+	AssignManagedUsers(ctx context.Context, supervisorId string, userIds []string) error
+	FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error)
+	AssignBranches(ctx context.Context, userId string, branchIds []string) error
 }
 
 // Handler defines the handler
@@ -41,12 +58,12 @@ func (h *Handler) EditName(c *gin.Context) {
 
 	name := gjson.Get(string(jsonData), "name")
 	if !name.Exists() || name.String() == "" {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap isi nama", nil))
+		restutil.SendError(c, restutil.ErrRequired("name", i18nutil.T(c.Request.Context(), i18nutil.ERR_NAME_REQUIRED)))
 		return
 	}
 
 	session := restutil.GetSession(c)
-	h.webuserUsecase.EditUser(session.UserID, name.String(), "", "", "")
+	h.webuserUsecase.EditUser(c.Request.Context(), session.UserID, name.String(), "", "", "")
 	restutil.SendResponseOk(c, "Nama berhasil diubah", nil)
 }
 
@@ -62,9 +79,9 @@ func (h *Handler) EditUser(c *gin.Context) {
 	role := gjson.Get(string(jsonData), "role")
 	active := gjson.Get(string(jsonData), "active")
 
-	err = h.webuserUsecase.EditUser(userId.String(), name.String(), username.String(), role.String(), active.String())
+	err = h.webuserUsecase.EditUser(c.Request.Context(), userId.String(), name.String(), username.String(), role.String(), active.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 	restutil.SendResponseOk(c, "Data berhasil diubah", nil)
@@ -80,26 +97,26 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 	password2 := gjson.Get(string(jsonData), "password2")
 	password3 := gjson.Get(string(jsonData), "password3")
 	if !password1.Exists() || password1.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kata sandi lama")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_OLD_PASSWORD_REQUIRED))
 		return
 	}
 	if !password2.Exists() || password2.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kata sandi baru")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_NEW_PASSWORD_REQUIRED))
 		return
 	}
 	if !password3.Exists() || password3.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi ulangi kata sandi baru")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_REPEAT_PASSWORD_REQUIRED))
 		return
 	}
 	if password2.String() != password3.String() {
-		restutil.SendResponseFail(c, "Ulangi kata sandi baru harus sesuai")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_REPEAT_PASSWORD_MISMATCH))
 		return
 	}
 
 	session := restutil.GetSession(c)
-	err = h.webuserUsecase.ChangePassword(session.UserID, password1.String(), password2.String())
+	err = h.webuserUsecase.ChangePassword(c.Request.Context(), session.UserID, password1.String(), password2.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 	restutil.SendResponseOk(c, "Kata sandi berhasil diubah", nil)
@@ -117,37 +134,75 @@ func (h *Handler) RegisterUser(c *gin.Context) {
 	roleId := gjson.Get(string(jsonData), "roleId")
 
 	if !name.Exists() || name.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi nama")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_NAME_REQUIRED))
 		return
 	}
 	if !username.Exists() || username.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi username")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_USERNAME_REQUIRED))
 		return
 	}
 	if !password.Exists() || password.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kata sandi")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_PASSWORD_REQUIRED))
 		return
 	}
 	if !roleId.Exists() || roleId.String() == "" {
-		restutil.SendResponseFail(c, "Harap pilih role")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_REQUIRED))
 		return
 	}
 
 	session := restutil.GetSession(c)
-	err = h.webuserUsecase.RegisterUser(session.UserID, name.String(), username.String(), password.String(), roleId.String())
+	err = h.webuserUsecase.RegisterUser(c.Request.Context(), session.UserID, name.String(), username.String(), password.String(), roleId.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 	restutil.SendResponseOk(c, "Pengguna baru berhasil ditambahkan", nil)
 }
 
+func (h *Handler) RegisterServiceAccount(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	name := gjson.Get(string(jsonData), "name")
+	username := gjson.Get(string(jsonData), "username")
+	roleId := gjson.Get(string(jsonData), "roleId")
+
+	if !name.Exists() || name.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("name", i18nutil.T(c.Request.Context(), i18nutil.ERR_NAME_REQUIRED)))
+		return
+	}
+	if !username.Exists() || username.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("username", i18nutil.T(c.Request.Context(), i18nutil.ERR_USERNAME_REQUIRED)))
+		return
+	}
+	if !roleId.Exists() || roleId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("roleId", i18nutil.T(c.Request.Context(), i18nutil.ERR_ROLE_REQUIRED)))
+		return
+	}
+
+	session := restutil.GetSession(c)
+	err = h.webuserUsecase.RegisterServiceAccount(c.Request.Context(), session.UserID, name.String(), username.String(), roleId.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Service account berhasil ditambahkan", nil)
+}
+
 func (h *Handler) FindAllUser(c *gin.Context) {
-	users, err := h.webuserUsecase.FindAllUser()
+	list, ok := restutil.GetListParam(c, webuserdomain.SortFields...)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.webuserUsecase.FindAllUser(c.Request.Context(), list)
 	if err != nil {
-		logrus.Error(err.Error())
+		restutil.SendError(c, err)
+		return
 	}
-	restutil.SendResponseOk(c, "", users)
+	restutil.SendResponseList(c, "", users, list.Meta(total, len(users)))
 }
 
 func (h *Handler) ForceChangePassword(c *gin.Context) {
@@ -158,29 +213,29 @@ func (h *Handler) ForceChangePassword(c *gin.Context) {
 
 	userId := gjson.Get(string(jsonData), "userId")
 	if !userId.Exists() || userId.String() == "" {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap pilih user yang akan diedit", nil))
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
 		return
 	}
 
 	password1 := gjson.Get(string(jsonData), "password1")
 	password2 := gjson.Get(string(jsonData), "password2")
 	if !password1.Exists() || password1.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi kata sandi baru")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_NEW_PASSWORD_REQUIRED))
 		return
 	}
 	if !password2.Exists() || password2.String() == "" {
-		restutil.SendResponseFail(c, "Harap isi ulangi kata sandi baru")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_REPEAT_PASSWORD_REQUIRED))
 		return
 	}
 	if password2.String() != password1.String() {
-		restutil.SendResponseFail(c, "Ulangi kata sandi baru harus sesuai")
+		restutil.SendResponseFail(c, i18nutil.T(c.Request.Context(), i18nutil.ERR_REPEAT_PASSWORD_MISMATCH))
 		return
 	}
 
 	//session := restutil.GetSession(c)
-	err = h.webuserUsecase.ForceChangePassword(userId.String(), password1.String())
+	err = h.webuserUsecase.ForceChangePassword(c.Request.Context(), userId.String(), password1.String())
 	if err != nil {
-		restutil.SendResponseFail(c, err.Error())
+		restutil.SendError(c, err)
 		return
 	}
 	restutil.SendResponseOk(c, "Kata sandi berhasil diubah", nil)
@@ -194,17 +249,287 @@ func (h *Handler) ChangeStatus(c *gin.Context) {
 
 	userId := gjson.Get(string(jsonData), "userId")
 	if !userId.Exists() || userId.String() == "" {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap pilih user yang akan diedit", nil))
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
 		return
 	}
 
 	active := gjson.Get(string(jsonData), "active")
 	if !active.Exists() {
-		c.JSON(http.StatusOK, restutil.CreateResponse(1, "Harap isi status", nil))
+		restutil.SendError(c, restutil.ErrRequired("active", i18nutil.T(c.Request.Context(), i18nutil.ERR_STATUS_REQUIRED)))
 		return
 	}
 
 	//session := restutil.GetSession(c)
-	h.webuserUsecase.ChangeStatus(userId.String(), active.Bool())
+	h.webuserUsecase.ChangeStatus(c.Request.Context(), userId.String(), active.Bool())
 	restutil.SendResponseOk(c, "Status berhasil diubah", nil)
 }
+
+func (h *Handler) ChangeLanguage(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	language := gjson.Get(string(jsonData), "language")
+	if !language.Exists() || language.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("language", i18nutil.T(c.Request.Context(), i18nutil.ERR_LANGUAGE_REQUIRED)))
+		return
+	}
+
+	session := restutil.GetSession(c)
+	if session == nil {
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_LOGIN_REQUIRED)))
+		return
+	}
+
+	err = h.webuserUsecase.ChangeLanguage(c.Request.Context(), session.UserID, language.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+
+	restutil.SendResponseOk(c, "", nil)
+}
+
+func (h *Handler) Unlock(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	userId := gjson.Get(string(jsonData), "userId")
+	if !userId.Exists() || userId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	err = h.webuserUsecase.Unlock(c.Request.Context(), userId.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "User berhasil dibuka", nil)
+}
+
+func (h *Handler) SetupTotp(c *gin.Context) {
+	session := restutil.GetSession(c)
+	if session == nil {
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_LOGIN_REQUIRED)))
+		return
+	}
+
+	setup, err := h.webuserUsecase.SetupTotp(c.Request.Context(), session.UserID)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "", setup)
+}
+
+func (h *Handler) EnableTotp(c *gin.Context) {
+	session, code, ok := totpRequest(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.webuserUsecase.EnableTotp(c.Request.Context(), session.UserID, code)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Autentikasi dua faktor berhasil diaktifkan", recoveryCodes)
+}
+
+func (h *Handler) DisableTotp(c *gin.Context) {
+	session, code, ok := totpRequest(c)
+	if !ok {
+		return
+	}
+
+	err := h.webuserUsecase.DisableTotp(c.Request.Context(), session.UserID, code)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Autentikasi dua faktor berhasil dinonaktifkan", nil)
+}
+
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	session, code, ok := totpRequest(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.webuserUsecase.RegenerateRecoveryCodes(c.Request.Context(), session.UserID, code)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "", recoveryCodes)
+}
+
+func (h *Handler) ResetTotp(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	userId := gjson.Get(string(jsonData), "userId")
+	if !userId.Exists() || userId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	err = h.webuserUsecase.ResetTotp(c.Request.Context(), userId.String())
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Autentikasi dua faktor user berhasil direset", nil)
+}
+
+func (h *Handler) FindStakeholders(c *gin.Context) {
+	userId := c.Query("userId")
+	if userId == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	stakeholders, err := h.webuserUsecase.FindStakeholders(c.Request.Context(), userId)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "", stakeholders)
+}
+
+func (h *Handler) AssignStakeholders(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	userId := gjson.Get(string(jsonData), "userId")
+	if !userId.Exists() || userId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	customerIds := []string{}
+	for _, id := range gjson.Get(string(jsonData), "customers").Array() {
+		customerIds = append(customerIds, id.String())
+	}
+	supplierIds := []string{}
+	for _, id := range gjson.Get(string(jsonData), "suppliers").Array() {
+		supplierIds = append(supplierIds, id.String())
+	}
+
+	err = h.webuserUsecase.AssignStakeholders(c.Request.Context(), userId.String(), customerIds, supplierIds)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Customer dan supplier user berhasil disimpan", nil)
+}
+
+func (h *Handler) FindManagedUsers(c *gin.Context) {
+	userId := c.Query("userId")
+	if userId == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	users, err := h.webuserUsecase.FindManagedUsers(c.Request.Context(), userId)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "", users)
+}
+
+func (h *Handler) AssignManagedUsers(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	userId := gjson.Get(string(jsonData), "userId")
+	if !userId.Exists() || userId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	userIds := []string{}
+	for _, id := range gjson.Get(string(jsonData), "users").Array() {
+		userIds = append(userIds, id.String())
+	}
+
+	err = h.webuserUsecase.AssignManagedUsers(c.Request.Context(), userId.String(), userIds)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "User yang dikelola berhasil disimpan", nil)
+}
+
+func (h *Handler) FindBranches(c *gin.Context) {
+	userId := c.Query("userId")
+	if userId == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	branches, err := h.webuserUsecase.FindBranches(c.Request.Context(), userId)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "", branches)
+}
+
+func (h *Handler) AssignBranches(c *gin.Context) {
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+	}
+
+	userId := gjson.Get(string(jsonData), "userId")
+	if !userId.Exists() || userId.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("userId", i18nutil.T(c.Request.Context(), i18nutil.ERR_USER_SELECT)))
+		return
+	}
+
+	branchIds := []string{}
+	for _, id := range gjson.Get(string(jsonData), "branches").Array() {
+		branchIds = append(branchIds, id.String())
+	}
+
+	err = h.webuserUsecase.AssignBranches(c.Request.Context(), userId.String(), branchIds)
+	if err != nil {
+		restutil.SendError(c, err)
+		return
+	}
+	restutil.SendResponseOk(c, "Cabang user berhasil disimpan", nil)
+}
+
+// totpRequest reads the code of the self-service TOTP endpoints, the error is sent when it returns false
+func totpRequest(c *gin.Context) (*sessiondomain.Session, string, bool) {
+	session := restutil.GetSession(c)
+	if session == nil {
+		restutil.SendError(c, restutil.ErrUnauthorized(i18nutil.T(c.Request.Context(), i18nutil.ERR_LOGIN_REQUIRED)))
+		return nil, "", false
+	}
+
+	jsonData, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithError(400, fmt.Errorf("bad request"))
+		return nil, "", false
+	}
+
+	code := gjson.Get(string(jsonData), "code")
+	if !code.Exists() || code.String() == "" {
+		restutil.SendError(c, restutil.ErrRequired("code", i18nutil.T(c.Request.Context(), i18nutil.ERR_TOTP_CODE_REQUIRED)))
+		return nil, "", false
+	}
+	return session, code.String(), true
+}
@@ -0,0 +1,175 @@
+package apikeyrepo
+
+import (
+	"context"
+	"database/sql"
+	apikeydomain "dromatech/pos-backend/internal/domain/apikey"
+	roledomain "dromatech/pos-backend/internal/domain/role"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"strings"
+	"time"
+)
+
+type ApiKeyRepo interface {
+	Find(ctx context.Context, id string) *apikeydomain.ApiKey
+	FindByUser(ctx context.Context, userId string) ([]*apikeydomain.ApiKey, error)
+	Create(ctx context.Context, apiKey *apikeydomain.ApiKey) error
+	Rotate(ctx context.Context, id string, keyHash string, rotatedTime time.Time) error
+	Revoke(ctx context.Context, id string, revokedTime time.Time) error
+	UpdateLastUsed(ctx context.Context, id string, lastUsedTime time.Time, clientIP string) error
+	FindPermissionApis(ctx context.Context, id string) ([]*roledomain.PermissionApi, error)
+}
+
+const selectQuery = "SELECT k.id, k.web_user_id, u.username, k.name, k.key_hash, " +
+	"(SELECT string_agg(permission_id, ';' ORDER BY permission_id) FROM api_key_permission WHERE api_key_id = k.id), " +
+	"k.expired_time, k.last_used_time, k.last_used_ip, k.created_by, k.created_time, k.rotated_time, k.revoked_time " +
+	"FROM api_key k JOIN web_user u ON u.id = k.web_user_id "
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+func (r *Repo) Find(ctx context.Context, id string) *apikeydomain.ApiKey {
+	rows, err := tenantutil.DB(ctx).Raw(selectQuery+"WHERE k.id = ?", id).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil
+	}
+	return scan(rows)
+}
+
+func (r *Repo) FindByUser(ctx context.Context, userId string) ([]*apikeydomain.ApiKey, error) {
+	rows, err := tenantutil.DB(ctx).Raw(selectQuery+"WHERE k.web_user_id = ? ORDER BY k.revoked_time DESC NULLS FIRST, k.created_time DESC", userId).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []*apikeydomain.ApiKey{}
+	for rows.Next() {
+		apiKeys = append(apiKeys, scan(rows))
+	}
+	return apiKeys, nil
+}
+
+func scan(rows *sql.Rows) *apikeydomain.ApiKey {
+	var ID sql.NullString
+	var WebUserID sql.NullString
+	var Username sql.NullString
+	var Name sql.NullString
+	var KeyHash sql.NullString
+	var Permissions sql.NullString
+	var ExpiredTime sql.NullTime
+	var LastUsedTime sql.NullTime
+	var LastUsedIP sql.NullString
+	var CreatedBy sql.NullString
+	var CreatedTime sql.NullTime
+	var RotatedTime sql.NullTime
+	var RevokedTime sql.NullTime
+
+	rows.Scan(&ID, &WebUserID, &Username, &Name, &KeyHash, &Permissions, &ExpiredTime, &LastUsedTime, &LastUsedIP, &CreatedBy, &CreatedTime, &RotatedTime, &RevokedTime)
+
+	apiKey := &apikeydomain.ApiKey{
+		ID:          ID.String,
+		WebUserID:   WebUserID.String,
+		Username:    Username.String,
+		Name:        Name.String,
+		KeyHash:     KeyHash.String,
+		Permissions: []string{},
+		LastUsedIP:  LastUsedIP.String,
+		CreatedBy:   CreatedBy.String,
+		CreatedTime: CreatedTime.Time,
+	}
+
+	if Permissions.Valid && Permissions.String != "" {
+		apiKey.Permissions = strings.Split(Permissions.String, ";")
+	}
+
+	if ExpiredTime.Valid {
+		apiKey.ExpiredTime = &ExpiredTime.Time
+	}
+
+	if LastUsedTime.Valid {
+		apiKey.LastUsedTime = &LastUsedTime.Time
+	}
+
+	if RotatedTime.Valid {
+		apiKey.RotatedTime = &RotatedTime.Time
+	}
+
+	if RevokedTime.Valid {
+		apiKey.RevokedTime = &RevokedTime.Time
+	}
+
+	return apiKey
+}
+
+func (r *Repo) Create(ctx context.Context, apiKey *apikeydomain.ApiKey) error {
+	tx := tenantutil.DB(ctx).Begin()
+	err := tx.Exec("INSERT INTO api_key(id, web_user_id, name, key_hash, expired_time, created_by, created_time) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?);",
+		apiKey.ID, apiKey.WebUserID, apiKey.Name, apiKey.KeyHash, apiKey.ExpiredTime, apiKey.CreatedBy, apiKey.CreatedTime).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, permission := range apiKey.Permissions {
+		err = tx.Exec("INSERT INTO api_key_permission(api_key_id, permission_id) VALUES (?, ?);",
+			apiKey.ID, permission).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+func (r *Repo) Rotate(ctx context.Context, id string, keyHash string, rotatedTime time.Time) error {
+	return tenantutil.DB(ctx).Exec("UPDATE api_key "+
+		"SET key_hash=?, rotated_time=? "+
+		"WHERE id=? AND revoked_time IS NULL;", keyHash, rotatedTime, id).Error
+}
+
+func (r *Repo) Revoke(ctx context.Context, id string, revokedTime time.Time) error {
+	return tenantutil.DB(ctx).Exec("UPDATE api_key "+
+		"SET revoked_time=? "+
+		"WHERE id=? AND revoked_time IS NULL;", revokedTime, id).Error
+}
+
+func (r *Repo) UpdateLastUsed(ctx context.Context, id string, lastUsedTime time.Time, clientIP string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE api_key "+
+		"SET last_used_time=?, last_used_ip=? "+
+		"WHERE id=?;", lastUsedTime, clientIP, id).Error
+}
+
+// FindPermissionApis returns the api rules of the permissions the key is narrowed to
+func (r *Repo) FindPermissionApis(ctx context.Context, id string) ([]*roledomain.PermissionApi, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT pa.permission_id, pa.method, pa.path FROM permission_api pa "+
+		"JOIN api_key_permission kp ON kp.permission_id = pa.permission_id "+
+		"WHERE kp.api_key_id = ?", id).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apis []*roledomain.PermissionApi
+	for rows.Next() {
+		api := &roledomain.PermissionApi{}
+		rows.Scan(&api.PermissionID, &api.Method, &api.Path)
+		apis = append(apis, api)
+	}
+	return apis, nil
+}
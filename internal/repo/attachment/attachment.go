@@ -0,0 +1,107 @@
+package attachmentrepo
+
+import (
+	"context"
+	"database/sql"
+	attachmentdomain "dromatech/pos-backend/internal/domain/attachment"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+)
+
+type AttachmentRepo interface {
+	Find(ctx context.Context, id string) (*attachmentdomain.Attachment, error)
+	FindByOwner(ctx context.Context, ownerType string, ownerIDs []string) (map[string][]attachmentdomain.Attachment, error)
+	Create(ctx context.Context, attachment *attachmentdomain.Attachment) error
+	Delete(ctx context.Context, id string) error
+}
+
+const selectQuery = "SELECT id, owner_type, owner_id, file_name, content_type, size, storage_key, thumbnail_key, uploaded_by, uploaded_time " +
+	"FROM attachment "
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+// Find returns the attachment, nil when it does not exist
+func (r *Repo) Find(ctx context.Context, id string) (*attachmentdomain.Attachment, error) {
+	rows, err := tenantutil.DB(ctx).Raw(selectQuery+"WHERE id = ?", id).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+	return scan(rows), nil
+}
+
+// FindByOwner returns the attachments of the records keyed by record id, oldest first
+func (r *Repo) FindByOwner(ctx context.Context, ownerType string, ownerIDs []string) (map[string][]attachmentdomain.Attachment, error) {
+	attachments := make(map[string][]attachmentdomain.Attachment)
+	if len(ownerIDs) == 0 {
+		return attachments, nil
+	}
+
+	rows, err := tenantutil.DB(ctx).Raw(selectQuery+"WHERE owner_type = ? AND owner_id IN ? ORDER BY uploaded_time", ownerType, ownerIDs).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attachment := scan(rows)
+		attachments[attachment.OwnerID] = append(attachments[attachment.OwnerID], *attachment)
+	}
+	return attachments, nil
+}
+
+func scan(rows *sql.Rows) *attachmentdomain.Attachment {
+	var ID sql.NullString
+	var OwnerType sql.NullString
+	var OwnerID sql.NullString
+	var FileName sql.NullString
+	var ContentType sql.NullString
+	var Size sql.NullInt64
+	var StorageKey sql.NullString
+	var ThumbnailKey sql.NullString
+	var UploadedBy sql.NullString
+	var UploadedTime sql.NullTime
+
+	rows.Scan(&ID, &OwnerType, &OwnerID, &FileName, &ContentType, &Size, &StorageKey, &ThumbnailKey, &UploadedBy, &UploadedTime)
+
+	return &attachmentdomain.Attachment{
+		ID:           ID.String,
+		OwnerType:    OwnerType.String,
+		OwnerID:      OwnerID.String,
+		FileName:     FileName.String,
+		ContentType:  ContentType.String,
+		Size:         Size.Int64,
+		HasThumbnail: ThumbnailKey.String != "",
+		StorageKey:   StorageKey.String,
+		ThumbnailKey: ThumbnailKey.String,
+		UploadedBy:   UploadedBy.String,
+		UploadedTime: UploadedTime.Time,
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, attachment *attachmentdomain.Attachment) error {
+	var thumbnailKey interface{}
+	if attachment.ThumbnailKey != "" {
+		thumbnailKey = attachment.ThumbnailKey
+	}
+	return tenantutil.DB(ctx).Exec("INSERT INTO attachment(id, owner_type, owner_id, file_name, content_type, size, storage_key, "+
+		"thumbnail_key, uploaded_by, uploaded_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		attachment.ID, attachment.OwnerType, attachment.OwnerID, attachment.FileName, attachment.ContentType, attachment.Size,
+		attachment.StorageKey, thumbnailKey, attachment.UploadedBy, attachment.UploadedTime).Error
+}
+
+func (r *Repo) Delete(ctx context.Context, id string) error {
+	return tenantutil.DB(ctx).Exec("DELETE FROM attachment WHERE id = ?;", id).Error
+}
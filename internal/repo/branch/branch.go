@@ -0,0 +1,124 @@
+package branchrepo
+
+import (
+	"context"
+	"database/sql"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+)
+
+type BranchRepo interface {
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*branchdomain.Branch, int64, error)
+	Find(ctx context.Context, id string) (*branchdomain.Branch, error)
+	FindByCode(ctx context.Context, code string) (*branchdomain.Branch, error)
+	Create(ctx context.Context, branch *branchdomain.Branch) error
+	Edit(ctx context.Context, branch *branchdomain.Branch) error
+	CodePrefix(ctx context.Context) (string, error)
+}
+
+// sortColumns maps the sort fields of the branch list to columns
+var sortColumns = map[string]string{
+	"code": "code",
+	"name": "name",
+}
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*branchdomain.Branch, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "code", "name")
+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, code, name, code_prefix, address, active FROM branch %s %s %s", where, list.OrderBy(sortColumns, "code"), list.LimitOffset()), values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entities := []*branchdomain.Branch{}
+	for rows.Next() {
+		entities = append(entities, scanBranch(rows))
+	}
+
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM branch %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
+}
+
+// Find returns the branch, nil when it does not exist
+func (r *Repo) Find(ctx context.Context, id string) (*branchdomain.Branch, error) {
+	return r.find(ctx, "id", id)
+}
+
+// FindByCode returns the branch of the code, nil when it does not exist
+func (r *Repo) FindByCode(ctx context.Context, code string) (*branchdomain.Branch, error) {
+	return r.find(ctx, "code", code)
+}
+
+func (r *Repo) find(ctx context.Context, field string, value string) (*branchdomain.Branch, error) {
+	entities, _, err := r.FindList(ctx, []queryutil.Param{{Field: field, Operator: "=", Value: value}}, queryutil.ListParam{})
+	if err != nil || len(entities) == 0 {
+		return nil, err
+	}
+	return entities[0], nil
+}
+
+func scanBranch(rows *sql.Rows) *branchdomain.Branch {
+	var ID sql.NullString
+	var Code sql.NullString
+	var Name sql.NullString
+	var CodePrefix sql.NullString
+	var Address sql.NullString
+	var Active sql.NullBool
+
+	rows.Scan(&ID, &Code, &Name, &CodePrefix, &Address, &Active)
+
+	return &branchdomain.Branch{
+		ID:         ID.String,
+		Code:       Code.String,
+		Name:       Name.String,
+		CodePrefix: CodePrefix.String,
+		Address:    Address.String,
+		Active:     Active.Bool,
+	}
+}
+
+func (r *Repo) Create(ctx context.Context, entity *branchdomain.Branch) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO branch(id, code, name, code_prefix, address, active) VALUES (?, ?, ?, ?, ?, ?)",
+		entity.ID, entity.Code, entity.Name, entity.CodePrefix, entity.Address, entity.Active).Error
+}
+
+func (r *Repo) Edit(ctx context.Context, entity *branchdomain.Branch) error {
+	return tenantutil.DB(ctx).Exec("UPDATE branch SET code=?, name=?, code_prefix=?, address=?, active=? WHERE id=?;",
+		entity.Code, entity.Name, entity.CodePrefix, entity.Address, entity.Active, entity.ID).Error
+}
+
+// CodePrefix returns the code prefix of the branch in ctx followed by a slash, empty without a branch or a prefix
+func (r *Repo) CodePrefix(ctx context.Context) (string, error) {
+	branchID, ok := scopeutil.BranchID(ctx)
+	if !ok {
+		return "", nil
+	}
+	branch, err := r.Find(ctx, branchID)
+	if err != nil || branch == nil || branch.CodePrefix == "" {
+		return "", err
+	}
+	return branch.CodePrefix + "/", nil
+}
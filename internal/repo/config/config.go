@@ -1,15 +1,17 @@
 package configrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	configdomain "dromatech/pos-backend/internal/domain/config"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+
 	"github.com/sirupsen/logrus"
 )
 
 type ConfigRepo interface {
-	ReInitCache() error
-	GetValue(key string) string
+	ReInitCache(ctx context.Context) error
+	GetValue(ctx context.Context, key string) string
 }
 
 type Repo struct {
@@ -17,22 +19,25 @@ type Repo struct {
 }
 
 func New() (*Repo, error) {
-	repo := &Repo{}
-	err := repo.ReInitCache()
+	repo := &Repo{
+		ConfigCache: configdomain.ConfigCache{
+			DataMap: make(map[string]map[string]*configdomain.Config),
+		},
+	}
+	err := repo.ReInitCache(context.Background())
 	return repo, err
 }
 
-func (r *Repo) ReInitCache() error {
-	r.ConfigCache.Lock()
-	r.ConfigCache.DataMap = make(map[string]*configdomain.Config)
-
-	rows, err := global.DBCON.Raw("SELECT id, value FROM config").Rows()
+// ReInitCache reads the config of the tenant of ctx again
+func (r *Repo) ReInitCache(ctx context.Context) error {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, value FROM config").Rows()
 	if err != nil {
 		logrus.Error(err.Error())
 		return err
 	}
 	defer rows.Close()
 
+	dataMap := make(map[string]*configdomain.Config)
 	for rows.Next() {
 		var ID sql.NullString
 		var Value sql.NullString
@@ -48,16 +53,32 @@ func (r *Repo) ReInitCache() error {
 			config.Value = Value.String
 		}
 
-		r.ConfigCache.DataMap[config.ID] = config
+		dataMap[config.ID] = config
 	}
 
+	r.ConfigCache.Lock()
+	r.ConfigCache.DataMap[tenantutil.Code(ctx)] = dataMap
 	r.ConfigCache.Unlock()
 
 	return nil
 }
 
-func (r *Repo) GetValue(key string) string {
-	if config, ok := r.ConfigCache.DataMap[key]; ok {
+// GetValue returns the config of the tenant of ctx, the config of a tenant is read on its first use
+func (r *Repo) GetValue(ctx context.Context, key string) string {
+	code := tenantutil.Code(ctx)
+	r.ConfigCache.RLock()
+	dataMap, ok := r.ConfigCache.DataMap[code]
+	r.ConfigCache.RUnlock()
+	if !ok {
+		if err := r.ReInitCache(ctx); err != nil {
+			return ""
+		}
+		r.ConfigCache.RLock()
+		dataMap = r.ConfigCache.DataMap[code]
+		r.ConfigCache.RUnlock()
+	}
+
+	if config, ok := dataMap[key]; ok {
 		return config.Value
 	}
 	return ""
@@ -1,27 +1,36 @@
 package customerrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	customerdomain "dromatech/pos-backend/internal/domain/customer"
 	dateutil "dromatech/pos-backend/internal/util/date"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"time"
 )
 
 type CustomerRepo interface {
-	Find(params map[string]interface{}) ([]*customerdomain.Customer, error)
-	Create(product *customerdomain.Customer) error
-	Edit(product *customerdomain.Customer) error
-	GetSellPrice(params []queryutil.Param) ([]*customerdomain.SellPriceResponse, error)
-	UpdateSellPrice(request customerdomain.SellPriceRequest) error
-	DeleteSellPrice(customerId, date string) error
-	AddSellPrice(entity customerdomain.AddPriceRequest) error
-	AddSellPriceTx(entity customerdomain.AddPriceRequest, tx *gorm.DB)
-	FindSellPrice(params []queryutil.Param) ([]*customerdomain.PriceResponse, error)
+	Find(ctx context.Context, params map[string]interface{}) ([]*customerdomain.Customer, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*customerdomain.Customer, int64, error)
+	Create(ctx context.Context, product *customerdomain.Customer) error
+	Edit(ctx context.Context, product *customerdomain.Customer) error
+	GetSellPrice(ctx context.Context, params []queryutil.Param) ([]*customerdomain.SellPriceResponse, error)
+	UpdateSellPrice(ctx context.Context, request customerdomain.SellPriceRequest) error
+	DeleteSellPrice(ctx context.Context, customerId, date string) error
+	AddSellPrice(ctx context.Context, entity customerdomain.AddPriceRequest) error
+	AddSellPriceTx(ctx context.Context, entity customerdomain.AddPriceRequest, tx *gorm.DB)
+	FindSellPrice(ctx context.Context, params []queryutil.Param) ([]*customerdomain.PriceResponse, error)
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"code": "code",
+	"name": "name",
 }
 
 type Repo struct {
@@ -32,25 +41,20 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params map[string]interface{}) ([]*customerdomain.Customer, error) {
-	where := ""
-	var values []interface{}
-	for key, value := range params {
-		if where != "" {
-			where += "AND "
-		}
-		where += key + " = ? "
-		values = append(values, value)
-	}
+func (r *Repo) Find(ctx context.Context, params map[string]interface{}) ([]*customerdomain.Customer, error) {
+	entities, _, err := r.FindList(ctx, queryutil.FromMap(params), queryutil.ListParam{})
+	return entities, err
+}
 
-	if where != "" {
-		where = "WHERE " + where
-	}
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*customerdomain.Customer, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "code", "name")
+	where, values = scopeutil.Where(ctx, where, values, "id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT id, code, name, description, active, initial_credit FROM customer %s ORDER BY code", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, code, name, description, active, initial_credit, npwp, address, taxable, tax_inclusive, tax_rate FROM customer %s %s %s", where, list.OrderBy(sortColumns, "code"), list.LimitOffset()), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -63,14 +67,19 @@ func (r *Repo) Find(params map[string]interface{}) ([]*customerdomain.Customer,
 		var Description sql.NullString
 		var Active sql.NullBool
 		var InitialCredit sql.NullInt64
+		var Npwp sql.NullString
+		var Address sql.NullString
+		var Taxable sql.NullBool
+		var TaxInclusive sql.NullBool
+		var TaxRate sql.NullFloat64
 
-		rows.Scan(&ID, &Code, &Name, &Description, &Active, &InitialCredit)
+		rows.Scan(&ID, &Code, &Name, &Description, &Active, &InitialCredit, &Npwp, &Address, &Taxable, &TaxInclusive, &TaxRate)
 
 		entity := &customerdomain.Customer{}
 		if ID.Valid && ID.String != "" {
 			entity.ID = ID.String
 		} else {
-			return nil, nil
+			return nil, 0, nil
 		}
 
 		if Code.Valid {
@@ -90,26 +99,42 @@ func (r *Repo) Find(params map[string]interface{}) ([]*customerdomain.Customer,
 		}
 
 		entity.InitialCredit = InitialCredit.Int64
+		entity.Npwp = Npwp.String
+		entity.Address = Address.String
+		entity.Taxable = Taxable.Bool
+		entity.TaxInclusive = TaxInclusive.Bool
+		if TaxRate.Valid {
+			entity.TaxRate = &TaxRate.Float64
+		}
 
 		entities = append(entities, entity)
 	}
 
-	return entities, nil
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM customer %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
 }
 
-func (r *Repo) Create(entity *customerdomain.Customer) error {
-	return global.DBCON.Exec("INSERT INTO public.customer(id, code, name, description, active, initial_credit) "+
-		"VALUES (?, ?, ?, ?, ?, ?)",
-		entity.ID, entity.Code, entity.Name, entity.Description, entity.Active, entity.InitialCredit).Error
+func (r *Repo) Create(ctx context.Context, entity *customerdomain.Customer) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO customer(id, code, name, description, active, initial_credit, npwp, address, taxable, tax_inclusive, tax_rate) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entity.ID, entity.Code, entity.Name, entity.Description, entity.Active, entity.InitialCredit, entity.Npwp, entity.Address, entity.Taxable, entity.TaxInclusive, entity.TaxRate).Error
 }
 
-func (r *Repo) Edit(entity *customerdomain.Customer) error {
-	return global.DBCON.Exec("UPDATE public.customer "+
-		"SET code=?, name=?, description=?, active=?, initial_credit=? "+
-		"WHERE id=?;", entity.Code, entity.Name, entity.Description, entity.Active, entity.InitialCredit, entity.ID).Error
+func (r *Repo) Edit(ctx context.Context, entity *customerdomain.Customer) error {
+	return tenantutil.DB(ctx).Exec("UPDATE customer "+
+		"SET code=?, name=?, description=?, active=?, initial_credit=?, npwp=?, address=?, taxable=?, tax_inclusive=?, tax_rate=? "+
+		"WHERE id=?;", entity.Code, entity.Name, entity.Description, entity.Active, entity.InitialCredit, entity.Npwp, entity.Address, entity.Taxable, entity.TaxInclusive, entity.TaxRate, entity.ID).Error
 }
 
-func (r *Repo) GetSellPrice(params []queryutil.Param) ([]*customerdomain.SellPriceResponse, error) {
+func (r *Repo) GetSellPrice(ctx context.Context, params []queryutil.Param) ([]*customerdomain.SellPriceResponse, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -128,12 +153,12 @@ func (r *Repo) GetSellPrice(params []queryutil.Param) ([]*customerdomain.SellPri
 		where = "WHERE " + where
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT p.id, p.code, p.name, p.description, bp.price FROM product p "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT p.id, p.code, p.name, p.description, bp.price FROM product p "+
 		"JOIN sell_price bp ON (p.id = bp.product_id) "+
 		"%s ORDER BY p.code", where), values...).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -167,11 +192,11 @@ func (r *Repo) GetSellPrice(params []queryutil.Param) ([]*customerdomain.SellPri
 	return entities, nil
 }
 
-func (r *Repo) UpdateSellPrice(request customerdomain.SellPriceRequest) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) UpdateSellPrice(ctx context.Context, request customerdomain.SellPriceRequest) error {
+	tx := tenantutil.DB(ctx).Begin()
 
 	for _, detail := range request.Prices {
-		tx.Exec("INSERT INTO public.sell_price(date, customer_id, product_id, price) "+
+		tx.Exec("INSERT INTO sell_price(date, customer_id, product_id, price) "+
 			"VALUES (?, ?, ?, ?, ?)", request.Date, request.CustomerId, detail.ProductID, detail.Price)
 
 		if tx.Error != nil {
@@ -183,25 +208,25 @@ func (r *Repo) UpdateSellPrice(request customerdomain.SellPriceRequest) error {
 	return tx.Error
 }
 
-func (r *Repo) DeleteSellPrice(customerId, date string) error {
-	tx := global.DBCON.Exec("DELETE from public.sell_price WHERE customer_id = ? AND date = ? ",
+func (r *Repo) DeleteSellPrice(ctx context.Context, customerId, date string) error {
+	tx := tenantutil.DB(ctx).Exec("DELETE from sell_price WHERE customer_id = ? AND date = ? ",
 		customerId, date)
 
 	return tx.Error
 }
 
-func (r *Repo) AddSellPrice(entity customerdomain.AddPriceRequest) error {
+func (r *Repo) AddSellPrice(ctx context.Context, entity customerdomain.AddPriceRequest) error {
 
-	tx := global.DBCON.Begin()
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.sell_price SET latest=FALSE "+
+	tx.Exec("UPDATE sell_price SET latest=FALSE "+
 		"WHERE customer_id = ? AND product_id = ? AND latest=TRUE;", entity.CustomerId, entity.ProductID)
 
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	tx.Exec("INSERT INTO public.sell_price(id, date, customer_id, product_id, price, web_user_id, latest, transaction_id) "+
+	tx.Exec("INSERT INTO sell_price(id, date, customer_id, product_id, price, web_user_id, latest, transaction_id) "+
 		"VALUES (?, ?, ?, ?, ?, ?, ?, ?)", entity.ID, entity.Date, entity.CustomerId, entity.ProductID, entity.Price, entity.WebUserId, entity.Latest, entity.TransactionId)
 
 	if tx.Error != nil {
@@ -214,19 +239,19 @@ func (r *Repo) AddSellPrice(entity customerdomain.AddPriceRequest) error {
 
 }
 
-func (r *Repo) AddSellPriceTx(entity customerdomain.AddPriceRequest, tx *gorm.DB) {
-	tx.Exec("UPDATE public.sell_price SET latest=FALSE "+
+func (r *Repo) AddSellPriceTx(ctx context.Context, entity customerdomain.AddPriceRequest, tx *gorm.DB) {
+	tx.Exec("UPDATE sell_price SET latest=FALSE "+
 		"WHERE customer_id = ? AND product_id = ? AND latest=TRUE;", entity.CustomerId, entity.ProductID)
 
 	if tx.Error != nil {
 		return
 	}
 
-	tx.Exec("INSERT INTO public.sell_price(id, date, customer_id, product_id, price, web_user_id, latest, transaction_id) "+
+	tx.Exec("INSERT INTO sell_price(id, date, customer_id, product_id, price, web_user_id, latest, transaction_id) "+
 		"VALUES (?, ?, ?, ?, ?, ?, ?, ?)", entity.ID, entity.Date, entity.CustomerId, entity.ProductID, entity.Price, entity.WebUserId, entity.Latest, entity.TransactionId)
 }
 
-func (r *Repo) FindSellPrice(params []queryutil.Param) ([]*customerdomain.PriceResponse, error) {
+func (r *Repo) FindSellPrice(ctx context.Context, params []queryutil.Param) ([]*customerdomain.PriceResponse, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -245,16 +270,16 @@ func (r *Repo) FindSellPrice(params []queryutil.Param) ([]*customerdomain.PriceR
 		where = "WHERE " + where
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT s.id, s.date, s.customer_id, p.unit_id, s.product_id, s.price, w.username, w.name, t.code "+
-		"FROM public.sell_price s "+
-		"JOIN public.web_user w ON (w.id = s.web_user_id) "+
-		"JOIN public.product p ON (p.id = s.product_id) "+
-		"JOIN public.unit u ON (u.id = p.unit_id) "+
-		"LEFT JOIN public.transaction t ON (t.id = s.transaction_id) "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT s.id, s.date, s.customer_id, p.unit_id, s.product_id, s.price, w.username, w.name, t.code "+
+		"FROM sell_price s "+
+		"JOIN web_user w ON (w.id = s.web_user_id) "+
+		"JOIN product p ON (p.id = s.product_id) "+
+		"JOIN unit u ON (u.id = p.unit_id) "+
+		"LEFT JOIN transaction t ON (t.id = s.transaction_id) "+
 		"%s ORDER BY date DESC ", where), values...).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -0,0 +1,229 @@
+package expenserepo
+
+import (
+	"context"
+	"database/sql"
+	expensedomain "dromatech/pos-backend/internal/domain/expense"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type ExpenseRepo interface {
+	FindCategoryList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*expensedomain.Category, int64, error)
+	FindCategory(ctx context.Context, id string) (*expensedomain.Category, error)
+	FindCategoryByCode(ctx context.Context, code string) (*expensedomain.Category, error)
+	CreateCategory(ctx context.Context, category *expensedomain.Category) error
+	EditCategory(ctx context.Context, category *expensedomain.Category) error
+
+	FindBudgetList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*expensedomain.Budget, int64, error)
+	FindBudget(ctx context.Context, id string) (*expensedomain.Budget, error)
+	FindApplicableBudgets(ctx context.Context, userID, categoryID string, tx *gorm.DB) ([]*expensedomain.Budget, error)
+	CreateBudget(ctx context.Context, budget *expensedomain.Budget) error
+	EditBudget(ctx context.Context, budget *expensedomain.Budget) error
+	DeleteBudget(ctx context.Context, id string) error
+}
+
+// categorySortColumns maps the sort fields of the category list to columns
+var categorySortColumns = map[string]string{
+	"code": "code",
+	"name": "name",
+}
+
+// budgetSortColumns maps the sort fields of the budget list to columns
+var budgetSortColumns = map[string]string{
+	"category": "ec.name",
+	"user":     "wu.name",
+	"period":   "b.period",
+	"amount":   "b.amount",
+}
+
+const selectBudgetQuery = "SELECT b.id, b.category_id, ec.name, b.web_user_id, wu.name, b.period, b.amount, b.mode, b.active " +
+	"FROM expense_budget b LEFT JOIN expense_category ec ON ec.id = b.category_id LEFT JOIN web_user wu ON wu.id = b.web_user_id "
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+func (r *Repo) FindCategoryList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*expensedomain.Category, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "code", "name")
+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, code, name, active FROM expense_category %s %s %s", where, list.OrderBy(categorySortColumns, "name"), list.LimitOffset()), values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entities := []*expensedomain.Category{}
+	for rows.Next() {
+		entities = append(entities, scanCategory(rows))
+	}
+
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM expense_category %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
+}
+
+// FindCategory returns the category, nil when it does not exist
+func (r *Repo) FindCategory(ctx context.Context, id string) (*expensedomain.Category, error) {
+	return r.findCategory(ctx, "id", id)
+}
+
+// FindCategoryByCode returns the category of the code, nil when it does not exist
+func (r *Repo) FindCategoryByCode(ctx context.Context, code string) (*expensedomain.Category, error) {
+	return r.findCategory(ctx, "code", code)
+}
+
+func (r *Repo) findCategory(ctx context.Context, field string, value string) (*expensedomain.Category, error) {
+	entities, _, err := r.FindCategoryList(ctx, []queryutil.Param{{Field: field, Operator: "=", Value: value}}, queryutil.ListParam{})
+	if err != nil || len(entities) == 0 {
+		return nil, err
+	}
+	return entities[0], nil
+}
+
+func scanCategory(rows *sql.Rows) *expensedomain.Category {
+	var ID sql.NullString
+	var Code sql.NullString
+	var Name sql.NullString
+	var Active sql.NullBool
+
+	rows.Scan(&ID, &Code, &Name, &Active)
+
+	return &expensedomain.Category{
+		ID:     ID.String,
+		Code:   Code.String,
+		Name:   Name.String,
+		Active: Active.Bool,
+	}
+}
+
+func (r *Repo) CreateCategory(ctx context.Context, entity *expensedomain.Category) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO expense_category(id, code, name, active) VALUES (?, ?, ?, ?)",
+		entity.ID, entity.Code, entity.Name, entity.Active).Error
+}
+
+func (r *Repo) EditCategory(ctx context.Context, entity *expensedomain.Category) error {
+	return tenantutil.DB(ctx).Exec("UPDATE expense_category SET code=?, name=?, active=? WHERE id=?;",
+		entity.Code, entity.Name, entity.Active, entity.ID).Error
+}
+
+func (r *Repo) FindBudgetList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*expensedomain.Budget, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "ec.name", "wu.name")
+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf(selectBudgetQuery+"%s %s %s", where, list.OrderBy(budgetSortColumns, "ec.name"), list.LimitOffset()), values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entities := []*expensedomain.Budget{}
+	for rows.Next() {
+		entities = append(entities, scanBudget(rows))
+	}
+
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM expense_budget b LEFT JOIN expense_category ec ON ec.id = b.category_id "+
+			"LEFT JOIN web_user wu ON wu.id = b.web_user_id %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
+}
+
+// FindBudget returns the budget, nil when it does not exist
+func (r *Repo) FindBudget(ctx context.Context, id string) (*expensedomain.Budget, error) {
+	entities, _, err := r.FindBudgetList(ctx, []queryutil.Param{{Field: "b.id", Operator: "=", Value: id}}, queryutil.ListParam{})
+	if err != nil || len(entities) == 0 {
+		return nil, err
+	}
+	return entities[0], nil
+}
+
+// FindApplicableBudgets returns the active budgets an operasional of the user in the category counts toward, they stay locked
+// until tx ends so the operasional counted toward a budget are checked and saved one at a time
+func (r *Repo) FindApplicableBudgets(ctx context.Context, userID, categoryID string, tx *gorm.DB) ([]*expensedomain.Budget, error) {
+	rows, err := tx.Raw(selectBudgetQuery+"WHERE b.active AND (b.web_user_id IS NULL OR b.web_user_id = ?) "+
+		"AND (b.category_id IS NULL OR b.category_id = ?) ORDER BY b.id FOR UPDATE OF b", userID, categoryID).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	entities := []*expensedomain.Budget{}
+	for rows.Next() {
+		entities = append(entities, scanBudget(rows))
+	}
+	return entities, nil
+}
+
+func scanBudget(rows *sql.Rows) *expensedomain.Budget {
+	var ID sql.NullString
+	var CategoryID sql.NullString
+	var CategoryName sql.NullString
+	var WebUserID sql.NullString
+	var UserName sql.NullString
+	var Period sql.NullString
+	var Amount sql.NullFloat64
+	var Mode sql.NullString
+	var Active sql.NullBool
+
+	rows.Scan(&ID, &CategoryID, &CategoryName, &WebUserID, &UserName, &Period, &Amount, &Mode, &Active)
+
+	return &expensedomain.Budget{
+		ID:           ID.String,
+		CategoryID:   CategoryID.String,
+		CategoryName: CategoryName.String,
+		WebUserID:    WebUserID.String,
+		UserName:     UserName.String,
+		Period:       Period.String,
+		Amount:       Amount.Float64,
+		Mode:         Mode.String,
+		Active:       Active.Bool,
+	}
+}
+
+func (r *Repo) CreateBudget(ctx context.Context, entity *expensedomain.Budget) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO expense_budget(id, category_id, web_user_id, period, amount, mode, active) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		entity.ID, nullable(entity.CategoryID), nullable(entity.WebUserID), entity.Period, entity.Amount, entity.Mode, entity.Active).Error
+}
+
+func (r *Repo) EditBudget(ctx context.Context, entity *expensedomain.Budget) error {
+	return tenantutil.DB(ctx).Exec("UPDATE expense_budget SET category_id=?, web_user_id=?, period=?, amount=?, mode=?, active=? WHERE id=?;",
+		nullable(entity.CategoryID), nullable(entity.WebUserID), entity.Period, entity.Amount, entity.Mode, entity.Active, entity.ID).Error
+}
+
+func (r *Repo) DeleteBudget(ctx context.Context, id string) error {
+	return tenantutil.DB(ctx).Exec("DELETE FROM expense_budget WHERE id = ?;", id).Error
+}
+
+// nullable stores an empty id as NULL, a budget without category or user covers all of them
+func nullable(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
@@ -1,26 +1,36 @@
 package kontrabonrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	kontrabondomain "dromatech/pos-backend/internal/domain/kontrabon"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
 	dateutil "dromatech/pos-backend/internal/util/date"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
 	"time"
 
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type KontrabonRepo interface {
-	Find(params []queryutil.Param) ([]*kontrabondomain.KontrabonResponse, error)
-	FindTransaction(params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error)
-	Create(entity kontrabondomain.Kontrabon, transactionIds []string) error
-	Update(kontrabonId string, transactionIds []string, status string) error
-	CreateTx(entity kontrabondomain.Kontrabon, transactionIds []string, tx *gorm.DB)
-	UpdateLunas(kontrabonId string, paymentTime time.Time, paymentValue float64, description, paymentDate string) error
+	Find(ctx context.Context, params []queryutil.Param) ([]*kontrabondomain.KontrabonResponse, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*kontrabondomain.KontrabonResponse, int64, error)
+	FindTransaction(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error)
+	Create(ctx context.Context, entity kontrabondomain.Kontrabon, transactionIds []string) error
+	Update(ctx context.Context, kontrabonId string, transactionIds []string, status string) error
+	CreateTx(ctx context.Context, entity kontrabondomain.Kontrabon, transactionIds []string, tx *gorm.DB)
+	UpdateLunas(ctx context.Context, kontrabonId string, paymentTime time.Time, paymentValue float64, description, paymentDate string) error
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"code":        "k.code",
+	"createdTime": "k.created_time",
+	"status":      "k.status",
 }
 
 type Repo struct {
@@ -31,7 +41,12 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params []queryutil.Param) ([]*kontrabondomain.KontrabonResponse, error) {
+func (r *Repo) Find(ctx context.Context, params []queryutil.Param) ([]*kontrabondomain.KontrabonResponse, error) {
+	entities, _, err := r.FindList(ctx, params, queryutil.ListParam{})
+	return entities, err
+}
+
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*kontrabondomain.KontrabonResponse, int64, error) {
 	params = append(params, queryutil.Param{
 		Logic:    "AND",
 		Field:    "td.latest",
@@ -39,33 +54,23 @@ func (r *Repo) Find(params []queryutil.Param) ([]*kontrabondomain.KontrabonRespo
 		Value:    "TRUE",
 	})
 
-	where := ""
-	var values []interface{}
-	for _, param := range params {
-		if where != "" {
-			logic := "AND "
-			if param.Logic != "" {
-				logic = param.Logic + " "
-			}
-			where += logic
-		}
-		where += param.Field + " " + param.Operator + " ? "
-		values = append(values, param.Value)
-	}
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "k.code")
+	where, values = scopeutil.Where(ctx, where, values, "k.customer_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "k.branch_id")
 
-	if where != "" {
-		where = "WHERE " + where
-	}
+	from := "FROM kontrabon k " +
+		"JOIN kontrabon_transaction kt ON (kt.kontrabon_id = k.id) " +
+		"JOIN transaction t ON (t.id = kt.transaction_id) " +
+		"JOIN transaction_detail td ON (td.transaction_id = t.id) "
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT k.id, k.code, k.created_time, k.status, SUM(td.sell_price * td.quantity), customer_id, payment_date, total_payment "+
-		"FROM public.kontrabon k "+
-		"JOIN public.kontrabon_transaction kt ON (kt.kontrabon_id = k.id) "+
-		"JOIN public.transaction t ON (t.id = kt.transaction_id) "+
-		"JOIN public.transaction_detail td ON (td.transaction_id = t.id)"+
-		"%s GROUP BY k.id, k.code, k.created_time, k.status ORDER BY k.created_time DESC, k.code ASC", where), values...).Rows()
+	// the total is the grand total of every transaction of the kontrabon, summed apart from the joined details
+	grandTotal := "(SELECT SUM(t2.grand_total) FROM kontrabon_transaction kt2 JOIN transaction t2 ON (t2.id = kt2.transaction_id) WHERE kt2.kontrabon_id = k.id)"
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT k.id, k.code, k.created_time, k.status, "+grandTotal+", customer_id, payment_date, total_payment "+
+		"%s %s GROUP BY k.id, k.code, k.created_time, k.status %s %s", from, where, list.OrderBy(sortColumns, "k.created_time DESC, k.code ASC"), list.LimitOffset()), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -86,7 +91,7 @@ func (r *Repo) Find(params []queryutil.Param) ([]*kontrabondomain.KontrabonRespo
 
 		var entity *kontrabondomain.KontrabonResponse
 		if !ID.Valid && ID.String == "" {
-			return nil, nil
+			return nil, 0, nil
 		}
 
 		if value, ok := entityMap[ID.String]; ok {
@@ -112,10 +117,19 @@ func (r *Repo) Find(params []queryutil.Param) ([]*kontrabondomain.KontrabonRespo
 
 	}
 
-	return entities, nil
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(DISTINCT k.id) %s %s", from, where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
 }
 
-func (r *Repo) FindTransaction(params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error) {
+func (r *Repo) FindTransaction(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error) {
 	params = append(params, queryutil.Param{
 		Logic:    "AND",
 		Field:    "td.latest",
@@ -140,8 +154,10 @@ func (r *Repo) FindTransaction(params []queryutil.Param) ([]*transactiondomain.T
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.stakeholder_id, c.code, c.name, t.transaction_type, t.status, "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.stakeholder_id, c.code, c.name, t.transaction_type, t.status, "+
 		"t.reference_code, t.web_user_id, w.name, t.created_time, "+
 		"u.id, u.code, td.product_id, p.code, p.name, td.buy_price, td.sell_price, td.quantity "+
 		"FROM transaction t "+
@@ -153,7 +169,7 @@ func (r *Repo) FindTransaction(params []queryutil.Param) ([]*transactiondomain.T
 		"JOIN unit u ON (u.id = p.unit_id) "+
 		"%s ORDER BY t.date ASC, t.code ASC, td.sorting_val ASC", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -231,9 +247,9 @@ func (r *Repo) FindTransaction(params []queryutil.Param) ([]*transactiondomain.T
 	return entities, nil
 }
 
-func (r *Repo) Create(entity kontrabondomain.Kontrabon, transactionIds []string) error {
-	tx := global.DBCON.Begin()
-	r.CreateTx(entity, transactionIds, tx)
+func (r *Repo) Create(ctx context.Context, entity kontrabondomain.Kontrabon, transactionIds []string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	r.CreateTx(ctx, entity, transactionIds, tx)
 
 	if tx.Error != nil {
 		return tx.Error
@@ -243,43 +259,44 @@ func (r *Repo) Create(entity kontrabondomain.Kontrabon, transactionIds []string)
 	return nil
 }
 
-func (r *Repo) CreateTx(entity kontrabondomain.Kontrabon, transactionIds []string, tx *gorm.DB) {
-	tx.Exec("INSERT INTO public.kontrabon(id, code, created_time, status, customer_id) VALUES (?, ?, ?, ?, ?)", entity.ID, entity.Code, entity.CreatedTime, entity.Status, entity.CustomerID)
+func (r *Repo) CreateTx(ctx context.Context, entity kontrabondomain.Kontrabon, transactionIds []string, tx *gorm.DB) {
+	tx.Exec("INSERT INTO kontrabon(id, code, created_time, status, customer_id, branch_id) VALUES (?, ?, ?, ?, ?, ?)",
+		entity.ID, entity.Code, entity.CreatedTime, entity.Status, entity.CustomerID, scopeutil.Branch(ctx))
 	if tx.Error != nil {
 		return
 	}
 
 	for _, transactionId := range transactionIds {
-		tx.Exec("INSERT INTO public.kontrabon_transaction(kontrabon_id, transaction_id) VALUES (?, ?);", entity.ID, transactionId)
+		tx.Exec("INSERT INTO kontrabon_transaction(kontrabon_id, transaction_id) VALUES (?, ?);", entity.ID, transactionId)
 		if tx.Error != nil {
 			return
 		}
-		tx.Exec("UPDATE public.transaction SET status=? WHERE id=?;", transactiondomain.TRANSACTION_KONTRABON, transactionId)
+		tx.Exec("UPDATE transaction SET status=? WHERE id=?;", transactiondomain.TRANSACTION_KONTRABON, transactionId)
 		if tx.Error != nil {
 			return
 		}
 	}
 }
 
-func (r *Repo) Update(kontrabonId string, transactionIds []string, status string) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) Update(ctx context.Context, kontrabonId string, transactionIds []string, status string) error {
+	tx := tenantutil.DB(ctx).Begin()
 
 	for _, transactionId := range transactionIds {
 		if status == transactiondomain.TRANSACTION_KONTRABON {
-			tx.Exec("INSERT INTO public.kontrabon_transaction(kontrabon_id, transaction_id) VALUES (?, ?);", kontrabonId, transactionId)
+			tx.Exec("INSERT INTO kontrabon_transaction(kontrabon_id, transaction_id) VALUES (?, ?);", kontrabonId, transactionId)
 			if tx.Error != nil {
 				tx.Rollback()
 				return tx.Error
 			}
 		} else if status == transactiondomain.TRANSACTION_PEMBUATAN {
-			tx.Exec("DELETE FROM public.kontrabon_transaction WHERE kontrabon_id=? AND transaction_id=?;", kontrabonId, transactionId)
+			tx.Exec("DELETE FROM kontrabon_transaction WHERE kontrabon_id=? AND transaction_id=?;", kontrabonId, transactionId)
 			if tx.Error != nil {
 				tx.Rollback()
 				return tx.Error
 			}
 		}
 
-		tx.Exec("UPDATE public.transaction SET status=? WHERE id=?;", status, transactionId)
+		tx.Exec("UPDATE transaction SET status=? WHERE id=?;", status, transactionId)
 		if tx.Error != nil {
 			tx.Rollback()
 			return tx.Error
@@ -290,15 +307,15 @@ func (r *Repo) Update(kontrabonId string, transactionIds []string, status string
 	return nil
 }
 
-func (r *Repo) UpdateLunas(kontrabonId string, paymentTime time.Time, paymentValue float64, description, paymentDate string) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) UpdateLunas(ctx context.Context, kontrabonId string, paymentTime time.Time, paymentValue float64, description, paymentDate string) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.kontrabon SET status=?, payment_update_time=?, total_payment=?, description=?, payment_date=? WHERE id=?", kontrabondomain.STATUS_LUNAS, paymentTime, paymentValue, description, paymentDate, kontrabonId)
+	tx.Exec("UPDATE kontrabon SET status=?, payment_update_time=?, total_payment=?, description=?, payment_date=? WHERE id=?", kontrabondomain.STATUS_LUNAS, paymentTime, paymentValue, description, paymentDate, kontrabonId)
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	tx.Exec("UPDATE public.transaction SET status=? WHERE id IN (SELECT kt.transaction_id FROM kontrabon_transaction kt WHERE kt.kontrabon_id = ?)", transactiondomain.TRANSACTION_DIBAYAR, kontrabonId)
+	tx.Exec("UPDATE transaction SET status=? WHERE id IN (SELECT kt.transaction_id FROM kontrabon_transaction kt WHERE kt.kontrabon_id = ?)", transactiondomain.TRANSACTION_DIBAYAR, kontrabonId)
 	if tx.Error != nil {
 		tx.Rollback()
 		return tx.Error
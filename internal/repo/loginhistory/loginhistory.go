@@ -0,0 +1,128 @@
+package loginhistoryrepo
+
+import (
+	"context"
+	"database/sql"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+	"time"
+)
+
+type LoginHistoryRepo interface {
+	Create(ctx context.Context, entity *sessiondomain.LoginHistory) error
+	Logout(ctx context.Context, id string, logoutTime time.Time, reason string) error
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*sessiondomain.LoginHistory, int64, error)
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"loginTime": "login_time",
+	"username":  "username",
+}
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+// Create records a login attempt, the username, ip and user agent come from the client and are cut to fit their columns
+func (r *Repo) Create(ctx context.Context, entity *sessiondomain.LoginHistory) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO login_history(id, web_user_id, username, success, failure_reason, client_ip, user_agent, login_time) "+
+		"VALUES (?, NULLIF(?, ''), LEFT(?, 64), ?, NULLIF(?, ''), LEFT(?, 64), LEFT(?, 512), ?)",
+		entity.ID, entity.WebUserID, entity.Username, entity.Success, entity.FailureReason, entity.ClientIP, entity.UserAgent, entity.LoginTime).Error
+}
+
+func (r *Repo) Logout(ctx context.Context, id string, logoutTime time.Time, reason string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE login_history "+
+		"SET logout_time=?, logout_reason=? "+
+		"WHERE id=? AND logout_time IS NULL;", logoutTime, reason, id).Error
+}
+
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*sessiondomain.LoginHistory, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "username", "client_ip")
+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, web_user_id, username, success, failure_reason, client_ip, user_agent, login_time, logout_time, logout_reason FROM login_history %s %s %s", where, list.OrderBy(sortColumns, "login_time DESC, id ASC"), list.LimitOffset()), values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entities []*sessiondomain.LoginHistory
+
+	for rows.Next() {
+		var ID sql.NullString
+		var WebUserID sql.NullString
+		var Username sql.NullString
+		var Success sql.NullBool
+		var FailureReason sql.NullString
+		var ClientIP sql.NullString
+		var UserAgent sql.NullString
+		var LoginTime sql.NullTime
+		var LogoutTime sql.NullTime
+		var LogoutReason sql.NullString
+
+		rows.Scan(&ID, &WebUserID, &Username, &Success, &FailureReason, &ClientIP, &UserAgent, &LoginTime, &LogoutTime, &LogoutReason)
+
+		entity := &sessiondomain.LoginHistory{}
+		if ID.Valid {
+			entity.ID = ID.String
+		}
+
+		if WebUserID.Valid {
+			entity.WebUserID = WebUserID.String
+		}
+
+		if Username.Valid {
+			entity.Username = Username.String
+		}
+
+		if Success.Valid {
+			entity.Success = Success.Bool
+		}
+
+		if FailureReason.Valid {
+			entity.FailureReason = FailureReason.String
+		}
+
+		if ClientIP.Valid {
+			entity.ClientIP = ClientIP.String
+		}
+
+		if UserAgent.Valid {
+			entity.UserAgent = UserAgent.String
+		}
+
+		if LoginTime.Valid {
+			entity.LoginTime = LoginTime.Time
+		}
+
+		if LogoutTime.Valid {
+			entity.LogoutTime = &LogoutTime.Time
+		}
+
+		if LogoutReason.Valid {
+			entity.LogoutReason = LogoutReason.String
+		}
+
+		entities = append(entities, entity)
+	}
+
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM login_history %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
+}
@@ -0,0 +1,140 @@
+package notificationrepo
+
+import (
+	"context"
+	"database/sql"
+	notificationdomain "dromatech/pos-backend/internal/domain/notification"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"encoding/json"
+)
+
+type NotificationRepo interface {
+	Create(ctx context.Context, notification *notificationdomain.Notification) error
+	FindList(ctx context.Context, userID string, unreadOnly bool, limit int) ([]notificationdomain.Notification, error)
+	FindAfter(ctx context.Context, userID string, afterID int64, limit int) ([]notificationdomain.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID string, ids []int64) error
+	MarkAllRead(ctx context.Context, userID string) error
+	FindUserName(ctx context.Context, id string) (string, error)
+}
+
+const selectQuery = "SELECT id, web_user_id, type, entity_id, data, read_time, created_time FROM notification "
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+// Create inserts the notification and sets its id
+func (r *Repo) Create(ctx context.Context, notification *notificationdomain.Notification) error {
+	var data interface{}
+	if notification.DanaTransfer != nil {
+		raw, err := json.Marshal(notification.DanaTransfer)
+		if err != nil {
+			return err
+		}
+		data = string(raw)
+	}
+
+	row := tenantutil.DB(ctx).Raw("INSERT INTO notification(web_user_id, type, entity_id, data, created_time) VALUES (?, ?, ?, ?, ?) RETURNING id;",
+		notification.WebUserID, notification.Type, notification.EntityID, data, notification.CreatedTime).Row()
+	if err := row.Scan(&notification.ID); err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindList returns the latest notifications of the user, newest first
+func (r *Repo) FindList(ctx context.Context, userID string, unreadOnly bool, limit int) ([]notificationdomain.Notification, error) {
+	query := selectQuery + "WHERE web_user_id = ? "
+	if unreadOnly {
+		query += "AND read_time IS NULL "
+	}
+	return find(ctx, query+"ORDER BY id DESC LIMIT ?", userID, limit)
+}
+
+// FindAfter returns the notifications of the user after the id in the order they were made, for a stream that reconnects
+func (r *Repo) FindAfter(ctx context.Context, userID string, afterID int64, limit int) ([]notificationdomain.Notification, error) {
+	return find(ctx, selectQuery+"WHERE web_user_id = ? AND id > ? ORDER BY id LIMIT ?", userID, afterID, limit)
+}
+
+func find(ctx context.Context, query string, args ...interface{}) ([]notificationdomain.Notification, error) {
+	rows, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []notificationdomain.Notification{}
+	for rows.Next() {
+		notifications = append(notifications, *scan(rows))
+	}
+	return notifications, nil
+}
+
+func scan(rows *sql.Rows) *notificationdomain.Notification {
+	var ID sql.NullInt64
+	var WebUserID sql.NullString
+	var Type sql.NullString
+	var EntityID sql.NullString
+	var Data sql.NullString
+	var ReadTime sql.NullTime
+	var CreatedTime sql.NullTime
+
+	rows.Scan(&ID, &WebUserID, &Type, &EntityID, &Data, &ReadTime, &CreatedTime)
+
+	notification := &notificationdomain.Notification{
+		ID:          ID.Int64,
+		WebUserID:   WebUserID.String,
+		Type:        Type.String,
+		EntityID:    EntityID.String,
+		Read:        ReadTime.Valid,
+		CreatedTime: CreatedTime.Time,
+	}
+	if Data.Valid {
+		transfer := &notificationdomain.DanaTransferNotification{}
+		if err := json.Unmarshal([]byte(Data.String), transfer); err == nil {
+			notification.DanaTransfer = transfer
+		}
+	}
+	return notification
+}
+
+func (r *Repo) CountUnread(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := tenantutil.DB(ctx).Raw("SELECT COUNT(id) FROM notification WHERE web_user_id = ? AND read_time IS NULL", userID).Row().Scan(&count)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead marks notifications of the user as read, ids of other users are ignored
+func (r *Repo) MarkRead(ctx context.Context, userID string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tenantutil.DB(ctx).Exec("UPDATE notification SET read_time = NOW() WHERE web_user_id = ? AND id IN ? AND read_time IS NULL;", userID, ids).Error
+}
+
+func (r *Repo) MarkAllRead(ctx context.Context, userID string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE notification SET read_time = NOW() WHERE web_user_id = ? AND read_time IS NULL;", userID).Error
+}
+
+// FindUserName returns the name of the user, empty when the user does not exist
+func (r *Repo) FindUserName(ctx context.Context, id string) (string, error) {
+	var name sql.NullString
+	err := tenantutil.DB(ctx).Raw("SELECT name FROM web_user WHERE id = ?", id).Row().Scan(&name)
+	if err != nil && err != sql.ErrNoRows {
+		logutil.WithContext(ctx).Error(err.Error())
+		return "", err
+	}
+	return name.String, nil
+}
@@ -1,34 +1,36 @@
 package pricerepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	customerdomain "dromatech/pos-backend/internal/domain/customer"
 	pricedomain "dromatech/pos-backend/internal/domain/price"
+	logutil "dromatech/pos-backend/internal/util/log"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
 	stringutil "dromatech/pos-backend/internal/util/string"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"time"
 )
 
 type PriceRepo interface {
-	Find(params map[string]interface{}) ([]*pricedomain.PriceTemplate, error)
-	FindDetail(params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error)
-	Create(name string) (string, error)
-	AddPrice(priceTemplateId string, productId string, price float64) error
-	EditPrice(priceTemplateId string, productId string, price float64) error
-	DeleteTemplate(templateId string) error
-	UpdateTemplate(priceTemplateId, customerId string, tx *gorm.DB)
-	UpdateChecked(request pricedomain.Download) error
-	FindBuyTemplate(params map[string]interface{}) ([]*pricedomain.PriceTemplate, error)
-	FindBuyDetail(params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error)
-	CreateBuyTemplate(name string) (string, error)
-	AddBuyPrice(priceTemplateId string, productId string, price float64) error
-	EditBuyPrice(priceTemplateId string, productId string, price float64) error
-	DeleteBuyTemplate(templateId string) error
-	UpdateBuyTemplate(priceTemplateId, webUserId string, trxId string, createdTime time.Time, tx *gorm.DB)
-	UpdateBuyChecked(request pricedomain.Download) error
+	Find(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplate, error)
+	FindDetail(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error)
+	Create(ctx context.Context, name string) (string, error)
+	AddPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error
+	EditPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error
+	DeleteTemplate(ctx context.Context, templateId string) error
+	UpdateTemplate(ctx context.Context, priceTemplateId, customerId string, tx *gorm.DB)
+	UpdateChecked(ctx context.Context, request pricedomain.Download) error
+	FindBuyTemplate(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplate, error)
+	FindBuyDetail(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error)
+	CreateBuyTemplate(ctx context.Context, name string) (string, error)
+	AddBuyPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error
+	EditBuyPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error
+	DeleteBuyTemplate(ctx context.Context, templateId string) error
+	UpdateBuyTemplate(ctx context.Context, priceTemplateId, webUserId string, trxId string, createdTime time.Time, tx *gorm.DB)
+	UpdateBuyChecked(ctx context.Context, request pricedomain.Download) error
 }
 
 type Repo struct {
@@ -39,7 +41,7 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params map[string]interface{}) ([]*pricedomain.PriceTemplate, error) {
+func (r *Repo) Find(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplate, error) {
 	where := ""
 	var values []interface{}
 	for key, value := range params {
@@ -53,10 +55,11 @@ func (r *Repo) Find(params map[string]interface{}) ([]*pricedomain.PriceTemplate
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.BranchWhere(ctx, where, values, "pt.branch_id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT pt.id, pt.name, pt.applied_to FROM price_template pt %s", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT pt.id, pt.name, pt.applied_to FROM price_template pt %s", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -69,7 +72,7 @@ func (r *Repo) Find(params map[string]interface{}) ([]*pricedomain.PriceTemplate
 
 		err = rows.Scan(&ID, &Name, &AppliedTo)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(ctx).Error(err.Error())
 			return nil, err
 		}
 
@@ -91,7 +94,7 @@ func (r *Repo) Find(params map[string]interface{}) ([]*pricedomain.PriceTemplate
 	return entities, nil
 }
 
-func (r *Repo) FindBuyTemplate(params map[string]interface{}) ([]*pricedomain.PriceTemplate, error) {
+func (r *Repo) FindBuyTemplate(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplate, error) {
 	where := ""
 	var values []interface{}
 	for key, value := range params {
@@ -105,10 +108,11 @@ func (r *Repo) FindBuyTemplate(params map[string]interface{}) ([]*pricedomain.Pr
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.BranchWhere(ctx, where, values, "pt.branch_id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT pt.id, pt.name FROM buy_price_template pt %s", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT pt.id, pt.name FROM buy_price_template pt %s", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -120,7 +124,7 @@ func (r *Repo) FindBuyTemplate(params map[string]interface{}) ([]*pricedomain.Pr
 
 		err = rows.Scan(&ID, &Name)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(ctx).Error(err.Error())
 			return nil, err
 		}
 
@@ -141,7 +145,7 @@ func (r *Repo) FindBuyTemplate(params map[string]interface{}) ([]*pricedomain.Pr
 	return entities, nil
 }
 
-func (r *Repo) FindDetail(params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error) {
+func (r *Repo) FindDetail(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error) {
 	where := ""
 	var values []interface{}
 	for key, value := range params {
@@ -156,9 +160,9 @@ func (r *Repo) FindDetail(params map[string]interface{}) ([]*pricedomain.PriceTe
 		where = "WHERE " + where
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT ptd.id, ptd.product_id, ptd.price, ptd.checked FROM public.price_template_detail ptd %s", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT ptd.id, ptd.product_id, ptd.price, ptd.checked FROM price_template_detail ptd %s", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -191,7 +195,7 @@ func (r *Repo) FindDetail(params map[string]interface{}) ([]*pricedomain.PriceTe
 	return entities, nil
 }
 
-func (r *Repo) FindBuyDetail(params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error) {
+func (r *Repo) FindBuyDetail(ctx context.Context, params map[string]interface{}) ([]*pricedomain.PriceTemplateDetail, error) {
 	where := ""
 	var values []interface{}
 	for key, value := range params {
@@ -206,9 +210,9 @@ func (r *Repo) FindBuyDetail(params map[string]interface{}) ([]*pricedomain.Pric
 		where = "WHERE " + where
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT ptd.id, ptd.product_id, ptd.price, ptd.checked FROM public.buy_price_template_detail ptd %s", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT ptd.id, ptd.product_id, ptd.price, ptd.checked FROM buy_price_template_detail ptd %s", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -241,48 +245,48 @@ func (r *Repo) FindBuyDetail(params map[string]interface{}) ([]*pricedomain.Pric
 	return entities, nil
 }
 
-func (r *Repo) Create(name string) (string, error) {
+func (r *Repo) Create(ctx context.Context, name string) (string, error) {
 	ID := stringutil.GenerateUUID()
 
-	return ID, global.DBCON.Exec("INSERT INTO public.price_template(id, name) VALUES (?, ?);", ID, name).Error
+	return ID, tenantutil.DB(ctx).Exec("INSERT INTO price_template(id, name, branch_id) VALUES (?, ?, ?);", ID, name, scopeutil.Branch(ctx)).Error
 }
 
-func (r *Repo) CreateBuyTemplate(name string) (string, error) {
+func (r *Repo) CreateBuyTemplate(ctx context.Context, name string) (string, error) {
 	ID := stringutil.GenerateUUID()
 
-	return ID, global.DBCON.Exec("INSERT INTO public.buy_price_template(id, name) VALUES (?, ?);", ID, name).Error
+	return ID, tenantutil.DB(ctx).Exec("INSERT INTO buy_price_template(id, name, branch_id) VALUES (?, ?, ?);", ID, name, scopeutil.Branch(ctx)).Error
 }
 
-func (r *Repo) AddPrice(priceTemplateId string, productId string, price float64) error {
+func (r *Repo) AddPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error {
 	ID := stringutil.GenerateUUID()
 
-	return global.DBCON.Exec("INSERT INTO public.price_template_detail(id, price_template_id, product_id, price) VALUES (?, ?, ?, ?);", ID, priceTemplateId, productId, price).Error
+	return tenantutil.DB(ctx).Exec("INSERT INTO price_template_detail(id, price_template_id, product_id, price) VALUES (?, ?, ?, ?);", ID, priceTemplateId, productId, price).Error
 }
 
-func (r *Repo) AddBuyPrice(priceTemplateId string, productId string, price float64) error {
+func (r *Repo) AddBuyPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error {
 	ID := stringutil.GenerateUUID()
 
-	return global.DBCON.Exec("INSERT INTO public.buy_price_template_detail(id, buy_price_template_id, product_id, price) VALUES (?, ?, ?, ?);", ID, priceTemplateId, productId, price).Error
+	return tenantutil.DB(ctx).Exec("INSERT INTO buy_price_template_detail(id, buy_price_template_id, product_id, price) VALUES (?, ?, ?, ?);", ID, priceTemplateId, productId, price).Error
 }
 
-func (r *Repo) EditPrice(priceTemplateId string, productId string, price float64) error {
-	return global.DBCON.Exec("UPDATE public.price_template_detail SET price=? WHERE price_template_id=? AND product_id=?;", price, priceTemplateId, productId).Error
+func (r *Repo) EditPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error {
+	return tenantutil.DB(ctx).Exec("UPDATE price_template_detail SET price=? WHERE price_template_id=? AND product_id=?;", price, priceTemplateId, productId).Error
 }
 
-func (r *Repo) EditBuyPrice(priceTemplateId string, productId string, price float64) error {
-	return global.DBCON.Exec("UPDATE public.buy_price_template_detail SET price=? WHERE buy_price_template_id=? AND product_id=?;", price, priceTemplateId, productId).Error
+func (r *Repo) EditBuyPrice(ctx context.Context, priceTemplateId string, productId string, price float64) error {
+	return tenantutil.DB(ctx).Exec("UPDATE buy_price_template_detail SET price=? WHERE buy_price_template_id=? AND product_id=?;", price, priceTemplateId, productId).Error
 }
 
-func (r *Repo) DeleteTemplate(templateId string) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) DeleteTemplate(ctx context.Context, templateId string) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("DELETE FROM public.price_template_detail WHERE price_template_id = ?;", templateId)
+	tx.Exec("DELETE FROM price_template_detail WHERE price_template_id = ?;", templateId)
 
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	tx.Exec("DELETE FROM public.price_template WHERE id = ?;", templateId)
+	tx.Exec("DELETE FROM price_template WHERE id = ?;", templateId)
 
 	if tx.Error != nil {
 		tx.Rollback()
@@ -292,16 +296,16 @@ func (r *Repo) DeleteTemplate(templateId string) error {
 	return tx.Commit().Error
 }
 
-func (r *Repo) DeleteBuyTemplate(templateId string) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) DeleteBuyTemplate(ctx context.Context, templateId string) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("DELETE FROM public.buy_price_template_detail WHERE buy_price_template_id = ?;", templateId)
+	tx.Exec("DELETE FROM buy_price_template_detail WHERE buy_price_template_id = ?;", templateId)
 
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	tx.Exec("DELETE FROM public.buy_price_template WHERE id = ?;", templateId)
+	tx.Exec("DELETE FROM buy_price_template WHERE id = ?;", templateId)
 
 	if tx.Error != nil {
 		tx.Rollback()
@@ -311,52 +315,52 @@ func (r *Repo) DeleteBuyTemplate(templateId string) error {
 	return tx.Commit().Error
 }
 
-func (r *Repo) UpdateTemplate(priceTemplateId, customerId string, tx *gorm.DB) {
-	tx.Exec("UPDATE public.price_template SET applied_to=? WHERE id=?;", customerId, priceTemplateId)
+func (r *Repo) UpdateTemplate(ctx context.Context, priceTemplateId, customerId string, tx *gorm.DB) {
+	tx.Exec("UPDATE price_template SET applied_to=? WHERE id=?;", customerId, priceTemplateId)
 }
 
-func (r *Repo) UpdateBuyTemplate(priceTemplateId, webUserId string, txId string, createdTime time.Time, tx *gorm.DB) {
+func (r *Repo) UpdateBuyTemplate(ctx context.Context, priceTemplateId, webUserId string, txId string, createdTime time.Time, tx *gorm.DB) {
 	id := stringutil.GenerateUUID()
-	tx.Exec("INSERT INTO public.buy_price_template_transaction(id, buy_price_template_id, transaction_id, created_time, web_user_id) VALUES (?,?,?,?,?);", id, priceTemplateId, txId, createdTime, webUserId)
+	tx.Exec("INSERT INTO buy_price_template_transaction(id, buy_price_template_id, transaction_id, created_time, web_user_id) VALUES (?,?,?,?,?);", id, priceTemplateId, txId, createdTime, webUserId)
 	if tx.Error != nil {
 		return
 	}
 }
 
-func (r *Repo) UpdateChecked(request pricedomain.Download) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) UpdateChecked(ctx context.Context, request pricedomain.Download) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.price_template_detail SET checked=FALSE WHERE price_template_id = ?;", request.TemplateID)
+	tx.Exec("UPDATE price_template_detail SET checked=FALSE WHERE price_template_id = ?;", request.TemplateID)
 	if tx.Error != nil {
 		tx.Rollback()
 		return tx.Error
 	}
 
-	tx.Exec("UPDATE public.price_template_detail SET checked=TRUE WHERE id IN ?;", request.TemplateDetailIDs)
+	tx.Exec("UPDATE price_template_detail SET checked=TRUE WHERE id IN ?;", request.TemplateDetailIDs)
 	return tx.Commit().Error
 }
 
-func (r *Repo) UpdateBuyChecked(request pricedomain.Download) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) UpdateBuyChecked(ctx context.Context, request pricedomain.Download) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.buy_price_template_detail SET checked=FALSE WHERE buy_price_template_id = ?;", request.TemplateID)
+	tx.Exec("UPDATE buy_price_template_detail SET checked=FALSE WHERE buy_price_template_id = ?;", request.TemplateID)
 	if tx.Error != nil {
 		tx.Rollback()
 		return tx.Error
 	}
 
-	tx.Exec("UPDATE public.buy_price_template_detail SET checked=TRUE WHERE id IN ?;", request.TemplateDetailIDs)
+	tx.Exec("UPDATE buy_price_template_detail SET checked=TRUE WHERE id IN ?;", request.TemplateDetailIDs)
 	return tx.Commit().Error
 }
 
-func (r *Repo) AddBuyPriceTx(entity customerdomain.AddPriceRequest, tx *gorm.DB) {
-	tx.Exec("UPDATE public.sell_price SET latest=FALSE "+
+func (r *Repo) AddBuyPriceTx(ctx context.Context, entity customerdomain.AddPriceRequest, tx *gorm.DB) {
+	tx.Exec("UPDATE sell_price SET latest=FALSE "+
 		"WHERE customer_id = ? AND product_id = ? AND latest=TRUE;", entity.CustomerId, entity.ProductID)
 
 	if tx.Error != nil {
 		return
 	}
 
-	tx.Exec("INSERT INTO public.sell_price(id, date, customer_id, product_id, price, web_user_id, latest, transaction_id) "+
+	tx.Exec("INSERT INTO sell_price(id, date, customer_id, product_id, price, web_user_id, latest, transaction_id) "+
 		"VALUES (?, ?, ?, ?, ?, ?, ?, ?)", entity.ID, entity.Date, entity.CustomerId, entity.ProductID, entity.Price, entity.WebUserId, entity.Latest, entity.TransactionId)
 }
@@ -1,17 +1,27 @@
 package productrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	productdomain "dromatech/pos-backend/internal/domain/product"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
-	"github.com/sirupsen/logrus"
 )
 
 type ProductRepo interface {
-	Find(params map[string]interface{}) ([]*productdomain.Product, error)
-	Create(product *productdomain.Product) error
-	Edit(product *productdomain.Product) error
+	Find(ctx context.Context, params map[string]interface{}) ([]*productdomain.Product, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*productdomain.Product, int64, error)
+	Create(ctx context.Context, product *productdomain.Product) error
+	Edit(ctx context.Context, product *productdomain.Product) error
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"code": "p.code",
+	"name": "p.name",
+	"unit": "u.code",
 }
 
 type Repo struct {
@@ -22,25 +32,19 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params map[string]interface{}) ([]*productdomain.Product, error) {
-	where := ""
-	var values []interface{}
-	for key, value := range params {
-		if where != "" {
-			where += "AND "
-		}
-		where += key + " = ? "
-		values = append(values, value)
-	}
+func (r *Repo) Find(ctx context.Context, params map[string]interface{}) ([]*productdomain.Product, error) {
+	entities, _, err := r.FindList(ctx, queryutil.FromMap(params), queryutil.ListParam{})
+	return entities, err
+}
 
-	if where != "" {
-		where = "WHERE " + where
-	}
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*productdomain.Product, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "p.code", "p.name")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT p.id, p.code, p.name, p.description, p.active, u.id, u.code FROM product p JOIN unit u ON (u.id = p.unit_id) %s ORDER BY p.name", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT p.id, p.code, p.name, p.description, p.active, u.id, u.code, p.tax_rate FROM product p JOIN unit u ON (u.id = p.unit_id) %s %s %s", where, list.OrderBy(sortColumns, "p.name"), list.LimitOffset()), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -54,14 +58,15 @@ func (r *Repo) Find(params map[string]interface{}) ([]*productdomain.Product, er
 		var Active sql.NullBool
 		var UnitID sql.NullString
 		var UnitCode sql.NullString
+		var TaxRate sql.NullFloat64
 
-		rows.Scan(&ID, &Code, &Name, &Description, &Active, &UnitID,  &UnitCode)
+		rows.Scan(&ID, &Code, &Name, &Description, &Active, &UnitID, &UnitCode, &TaxRate)
 
 		product := &productdomain.Product{}
 		if ID.Valid && ID.String != "" {
 			product.ID = ID.String
 		} else {
-			return nil, nil
+			return nil, 0, nil
 		}
 
 		if Code.Valid {
@@ -82,21 +87,33 @@ func (r *Repo) Find(params map[string]interface{}) ([]*productdomain.Product, er
 
 		product.UnitID = UnitID.String
 		product.UnitCode = UnitCode.String
+		if TaxRate.Valid {
+			product.TaxRate = &TaxRate.Float64
+		}
 
 		products = append(products, product)
 	}
 
-	return products, nil
+	total := int64(len(products))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM product p JOIN unit u ON (u.id = p.unit_id) %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return products, total, nil
 }
 
-func (r *Repo) Create(product *productdomain.Product) error {
-	return global.DBCON.Exec("INSERT INTO public.product(id, code, name, description, active, unit_id) "+
-		"VALUES (?, ?, ?, ?, ?, ?)",
-		product.ID, product.Code, product.Name, product.Description, product.Active, product.UnitID).Error
+func (r *Repo) Create(ctx context.Context, product *productdomain.Product) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO product(id, code, name, description, active, unit_id, tax_rate) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?)",
+		product.ID, product.Code, product.Name, product.Description, product.Active, product.UnitID, product.TaxRate).Error
 }
 
-func (r *Repo) Edit(product *productdomain.Product) error {
-	return global.DBCON.Exec("UPDATE public.product "+
-		"SET code=?, name=?, description=?, active=?, unit_id = ? "+
-		"WHERE id=?;", product.Code, product.Name, product.Description, product.Active, product.UnitID, product.ID).Error
+func (r *Repo) Edit(ctx context.Context, product *productdomain.Product) error {
+	return tenantutil.DB(ctx).Exec("UPDATE product "+
+		"SET code=?, name=?, description=?, active=?, unit_id = ?, tax_rate = ? "+
+		"WHERE id=?;", product.Code, product.Name, product.Description, product.Active, product.UnitID, product.TaxRate, product.ID).Error
 }
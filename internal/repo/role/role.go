@@ -1,26 +1,28 @@
 package rolerepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	roledomain "dromatech/pos-backend/internal/domain/role"
 	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"strings"
 )
 
 type RoleRepo interface {
-	Find(id string) *roledomain.Role
-	FindMenu(roleId string) ([]*sessiondomain.Menu, error)
-	FindAll() ([]*roledomain.Role, error)
-	FindActive() ([]*roledomain.RoleResponseModel, error)
-	FindActivePermissionPaths(roleId string) ([]string, error)
-	FindPermissions() ([]*roledomain.Permission, error)
-	FindPermissionsByRoleId(roleId string) ([]*roledomain.Permission, error)
-	RegisterRole(name string, permissions []string)
-	FindByName(name string) *roledomain.Role
-	EditRole(roleId string, name string, active bool, permissions []string)
+	Find(ctx context.Context, id string) *roledomain.Role
+	FindMenu(ctx context.Context, roleId string) ([]*sessiondomain.Menu, error)
+	FindAll(ctx context.Context) ([]*roledomain.Role, error)
+	FindActive(ctx context.Context) ([]*roledomain.RoleResponseModel, error)
+	FindActivePermissionApis(ctx context.Context, roleId string) ([]*roledomain.PermissionApi, error)
+	FindPermissionApis(ctx context.Context) ([]*roledomain.PermissionApi, error)
+	FindPermissions(ctx context.Context) ([]*roledomain.Permission, error)
+	FindPermissionsByRoleId(ctx context.Context, roleId string) ([]*roledomain.Permission, error)
+	RegisterRole(ctx context.Context, role *roledomain.Role)
+	FindByName(ctx context.Context, name string) *roledomain.Role
+	EditRole(ctx context.Context, role *roledomain.Role)
 }
 
 type Repo struct {
@@ -31,13 +33,15 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(id string) *roledomain.Role {
-	row := global.DBCON.Raw("SELECT id, name, active FROM role WHERE id = ? AND active = true", id).Row()
+func (r *Repo) Find(ctx context.Context, id string) *roledomain.Role {
+	row := tenantutil.DB(ctx).Raw("SELECT id, name, active, require_two_factor, all_data FROM role WHERE id = ? AND active = true", id).Row()
 	var ID sql.NullString
 	var Name sql.NullString
 	var Active sql.NullBool
+	var RequireTwoFactor sql.NullBool
+	var AllData sql.NullBool
 
-	row.Scan(&ID, &Name, &Active)
+	row.Scan(&ID, &Name, &Active, &RequireTwoFactor, &AllData)
 
 	role := &roledomain.Role{}
 	if ID.Valid {
@@ -52,16 +56,26 @@ func (r *Repo) Find(id string) *roledomain.Role {
 		role.Active = Active.Bool
 	}
 
+	if RequireTwoFactor.Valid {
+		role.RequireTwoFactor = RequireTwoFactor.Bool
+	}
+
+	if AllData.Valid {
+		role.AllData = AllData.Bool
+	}
+
 	return role
 }
 
-func (r *Repo) FindByName(name string) *roledomain.Role {
-	row := global.DBCON.Raw("SELECT id, name, active FROM role WHERE LOWER(name) = ? AND active = true", strings.ToLower(name)).Row()
+func (r *Repo) FindByName(ctx context.Context, name string) *roledomain.Role {
+	row := tenantutil.DB(ctx).Raw("SELECT id, name, active, require_two_factor, all_data FROM role WHERE LOWER(name) = ? AND active = true", strings.ToLower(name)).Row()
 	var ID sql.NullString
 	var Name sql.NullString
 	var Active sql.NullBool
+	var RequireTwoFactor sql.NullBool
+	var AllData sql.NullBool
 
-	row.Scan(&ID, &Name, &Active)
+	row.Scan(&ID, &Name, &Active, &RequireTwoFactor, &AllData)
 
 	role := &roledomain.Role{}
 	if ID.Valid && ID.String != "" {
@@ -78,13 +92,21 @@ func (r *Repo) FindByName(name string) *roledomain.Role {
 		role.Active = Active.Bool
 	}
 
+	if RequireTwoFactor.Valid {
+		role.RequireTwoFactor = RequireTwoFactor.Bool
+	}
+
+	if AllData.Valid {
+		role.AllData = AllData.Bool
+	}
+
 	return role
 }
 
-func (r *Repo) FindAll() ([]*roledomain.Role, error) {
-	rows, err := global.DBCON.Raw("SELECT id, name, active FROM role").Rows()
+func (r *Repo) FindAll(ctx context.Context) ([]*roledomain.Role, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, name, active, require_two_factor, all_data FROM role").Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -94,8 +116,10 @@ func (r *Repo) FindAll() ([]*roledomain.Role, error) {
 		var ID sql.NullString
 		var Name sql.NullString
 		var Active sql.NullBool
+		var RequireTwoFactor sql.NullBool
+		var AllData sql.NullBool
 
-		rows.Scan(&ID, &Name, &Active)
+		rows.Scan(&ID, &Name, &Active, &RequireTwoFactor, &AllData)
 
 		role := &roledomain.Role{}
 		if ID.Valid {
@@ -110,16 +134,24 @@ func (r *Repo) FindAll() ([]*roledomain.Role, error) {
 			role.Active = Active.Bool
 		}
 
+		if RequireTwoFactor.Valid {
+			role.RequireTwoFactor = RequireTwoFactor.Bool
+		}
+
+		if AllData.Valid {
+			role.AllData = AllData.Bool
+		}
+
 		roles = append(roles, role)
 	}
 
 	return roles, nil
 }
 
-func (r *Repo) FindActive() ([]*roledomain.RoleResponseModel, error) {
-	rows, err := global.DBCON.Raw("SELECT id, name FROM role WHERE active = true").Rows()
+func (r *Repo) FindActive(ctx context.Context) ([]*roledomain.RoleResponseModel, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, name FROM role WHERE active = true").Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -146,8 +178,8 @@ func (r *Repo) FindActive() ([]*roledomain.RoleResponseModel, error) {
 	return roles, nil
 }
 
-func (r *Repo) FindMenu(roleId string) ([]*sessiondomain.Menu, error) {
-	rows, err := global.DBCON.Raw("SELECT m.name, m.icon, m.path, s.name, s.outcome, s.icon, p.id FROM menu m "+
+func (r *Repo) FindMenu(ctx context.Context, roleId string) ([]*sessiondomain.Menu, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT m.id, m.name, m.icon, m.path, s.id, s.name, s.outcome, s.icon, p.id FROM menu m "+
 		"JOIN sub_menu s ON (m.id = s.menu_id) "+
 		"JOIN permission p ON (s.id = p.sub_menu_id) "+
 		"JOIN role_permission rp ON (p.id = rp.permission_id) "+
@@ -156,7 +188,7 @@ func (r *Repo) FindMenu(roleId string) ([]*sessiondomain.Menu, error) {
 		"ORDER BY m.seq_order ASC, s.seq_order, p.seq_order ASC", roleId).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -165,18 +197,21 @@ func (r *Repo) FindMenu(roleId string) ([]*sessiondomain.Menu, error) {
 	subMenu := &sessiondomain.SubMenu{}
 	var menus []*sessiondomain.Menu
 	for rows.Next() {
+		var menuID sql.NullString
 		var menuName sql.NullString
 		var menuIcon sql.NullString
 		var menuPath sql.NullString
+		var subID sql.NullString
 		var subName sql.NullString
 		var subOutcome sql.NullString
 		var subIcon sql.NullString
 		var perName sql.NullString
 
-		rows.Scan(&menuName, &menuIcon, &menuPath, &subName, &subOutcome, &subIcon, &perName)
+		rows.Scan(&menuID, &menuName, &menuIcon, &menuPath, &subID, &subName, &subOutcome, &subIcon, &perName)
 
 		if menu.Name != menuName.String {
 			menu = &sessiondomain.Menu{
+				ID:      menuID.String,
 				Name:    menuName.String,
 				Icon:    menuIcon.String,
 				Path:    menuPath.String,
@@ -187,6 +222,7 @@ func (r *Repo) FindMenu(roleId string) ([]*sessiondomain.Menu, error) {
 
 		if subMenu.Name != subName.String {
 			subMenu = &sessiondomain.SubMenu{
+				ID:      subID.String,
 				Name:    subName.String,
 				Outcome: subOutcome.String,
 				Icon:    subIcon.String,
@@ -201,38 +237,60 @@ func (r *Repo) FindMenu(roleId string) ([]*sessiondomain.Menu, error) {
 	return menus, nil
 }
 
-func (r *Repo) FindActivePermissionPaths(roleId string) ([]string, error) {
-	rows, err := global.DBCON.Raw("SELECT p.apis FROM permission p "+
-		"JOIN role_permission rp ON (p.id = rp.permission_id) "+
+// FindActivePermissionApis returns the api rules granted to the role, none when the role is inactive
+func (r *Repo) FindActivePermissionApis(ctx context.Context, roleId string) ([]*roledomain.PermissionApi, error) {
+	return r.findPermissionApis(ctx, "SELECT pa.permission_id, pa.method, pa.path FROM permission_api pa "+
+		"JOIN role_permission rp ON (pa.permission_id = rp.permission_id) "+
 		"JOIN role r ON (rp.role_id = r.id) "+
-		"WHERE r.id = ? AND r.active = true", roleId).Rows()
+		"WHERE r.id = ? AND r.active = true", roleId)
+}
+
+func (r *Repo) FindPermissionApis(ctx context.Context) ([]*roledomain.PermissionApi, error) {
+	return r.findPermissionApis(ctx, "SELECT permission_id, method, path FROM permission_api ORDER BY permission_id, path")
+}
+
+func (r *Repo) findPermissionApis(ctx context.Context, query string, values ...interface{}) ([]*roledomain.PermissionApi, error) {
+	rows, err := tenantutil.DB(ctx).Raw(query, values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
 
-	var paths []string
+	var apis []*roledomain.PermissionApi
 	for rows.Next() {
-		var path sql.NullString
+		var PermissionID sql.NullString
+		var Method sql.NullString
+		var Path sql.NullString
 
-		rows.Scan(&path)
+		rows.Scan(&PermissionID, &Method, &Path)
 
-		if path.Valid {
-			paths = append(paths, path.String)
+		api := &roledomain.PermissionApi{}
+		if PermissionID.Valid {
+			api.PermissionID = PermissionID.String
 		}
+
+		if Method.Valid {
+			api.Method = Method.String
+		}
+
+		if Path.Valid {
+			api.Path = Path.String
+		}
+
+		apis = append(apis, api)
 	}
 
-	return paths, nil
+	return apis, nil
 }
 
-func (r *Repo) FindPermissions() ([]*roledomain.Permission, error) {
-	rows, err := global.DBCON.Raw("SELECT p.id, m.name, s.name, p.name FROM permission p " +
+func (r *Repo) FindPermissions(ctx context.Context) ([]*roledomain.Permission, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT p.id, m.name, s.name, p.name FROM permission p " +
 		"JOIN sub_menu s ON (s.id = p.sub_menu_id) " +
 		"JOIN menu m ON (s.menu_id = m.id) " +
 		"ORDER BY m.seq_order, s.seq_order, p.seq_order").Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -248,15 +306,15 @@ func (r *Repo) FindPermissions() ([]*roledomain.Permission, error) {
 	return permissions, nil
 }
 
-func (r *Repo) FindPermissionsByRoleId(roleId string) ([]*roledomain.Permission, error) {
-	rows, err := global.DBCON.Raw("SELECT p.id, m.name, s.name, p.name FROM permission p "+
+func (r *Repo) FindPermissionsByRoleId(ctx context.Context, roleId string) ([]*roledomain.Permission, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT p.id, m.name, s.name, p.name FROM permission p "+
 		"JOIN sub_menu s ON (p.sub_menu_id = s.id) "+
 		"JOIN menu m ON (s.menu_id = m.id) "+
 		"JOIN role_permission rp ON (rp.permission_id = p.id) "+
 		"WHERE rp.role_id = ? "+
 		"ORDER BY m.seq_order, s.seq_order, p.seq_order", roleId).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -272,19 +330,19 @@ func (r *Repo) FindPermissionsByRoleId(roleId string) ([]*roledomain.Permission,
 	return permissions, nil
 }
 
-func (r *Repo) RegisterRole(name string, permissions []string) {
-	tx := global.DBCON.Begin()
+func (r *Repo) RegisterRole(ctx context.Context, role *roledomain.Role) {
+	tx := tenantutil.DB(ctx).Begin()
 	roleId := strings.ReplaceAll(uuid.NewString(), "-", "")
-	tx.Exec("INSERT INTO public.role(id, active, name) VALUES (?, ?, ?);",
-		roleId, true, name)
+	tx.Exec("INSERT INTO role(id, active, name, require_two_factor, all_data) VALUES (?, ?, ?, ?, ?);",
+		roleId, true, role.Name, role.RequireTwoFactor, role.AllData)
 
 	if tx.Error != nil {
 		tx.Rollback()
 		return
 	}
 
-	for _, id := range permissions {
-		tx.Exec("INSERT INTO public.role_permission(role_id, permission_id) VALUES (?, ?);",
+	for _, id := range role.Permissions {
+		tx.Exec("INSERT INTO role_permission(role_id, permission_id) VALUES (?, ?);",
 			roleId, id)
 
 		if tx.Error != nil {
@@ -299,33 +357,33 @@ func (r *Repo) RegisterRole(name string, permissions []string) {
 	tx.Commit()
 }
 
-func (r *Repo) EditRole(roleId string, name string, active bool, permissions []string) {
-	tx := global.DBCON.Begin()
-	tx.Exec("DELETE FROM public.role_permission WHERE role_id = ?;", roleId)
+func (r *Repo) EditRole(ctx context.Context, role *roledomain.Role) {
+	tx := tenantutil.DB(ctx).Begin()
+	tx.Exec("DELETE FROM role_permission WHERE role_id = ?;", role.ID)
 
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
+		logutil.WithContext(ctx).Error(tx.Error.Error())
 		return
 	}
 
-	for _, id := range permissions {
-		tx.Exec("INSERT INTO public.role_permission(role_id, permission_id) VALUES (?, ?);",
-			roleId, id)
+	for _, id := range role.Permissions {
+		tx.Exec("INSERT INTO role_permission(role_id, permission_id) VALUES (?, ?);",
+			role.ID, id)
 
 		if tx.Error != nil {
 			tx.Rollback()
-			logrus.Error(tx.Error.Error())
+			logutil.WithContext(ctx).Error(tx.Error.Error())
 			return
 		}
 	}
 
-	tx.Exec("UPDATE public.role SET active=?, name=? WHERE id=?;",
-		active, name, roleId)
+	tx.Exec("UPDATE role SET active=?, name=?, require_two_factor=?, all_data=? WHERE id=?;",
+		role.Active, role.Name, role.RequireTwoFactor, role.AllData, role.ID)
 
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
+		logutil.WithContext(ctx).Error(tx.Error.Error())
 		return
 	}
 
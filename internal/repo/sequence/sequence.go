@@ -1,14 +1,16 @@
 package sequencerepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"gorm.io/gorm"
 )
 
 type SequenceRepo interface {
-	NextVal(id string) int64
-	NextValTx(id string, tx *gorm.DB) int64
+	NextVal(ctx context.Context, id string) (int64, error)
+	NextValTx(ctx context.Context, id string, tx *gorm.DB) (int64, error)
 }
 
 type Repo struct {
@@ -19,37 +21,30 @@ func New() *Repo {
 	return repo
 }
 
-func nextVal(id string, tx *gorm.DB) int64 {
-	row := tx.Raw("SELECT id, next_value FROM public.sequence WHERE id = ?", id).Row()
-
-	var ID sql.NullString
+// nextVal takes the next value of the sequence in tx, a new sequence starts at 1. The row stays locked until tx ends so
+// concurrent transactions take their values one after the other.
+func nextVal(ctx context.Context, id string, tx *gorm.DB) (int64, error) {
 	var NextValue sql.NullInt64
-
-	row.Scan(&ID, &NextValue)
-
-	if !ID.Valid || ID.String == "" {
-		return int64(1)
-	} else {
-		return NextValue.Int64
+	err := tx.Raw("INSERT INTO sequence(id, next_value) VALUES (?, 2) "+
+		"ON CONFLICT (id) DO UPDATE SET next_value = sequence.next_value + 1 RETURNING next_value - 1", id).Row().Scan(&NextValue)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return 0, err
 	}
+	return NextValue.Int64, nil
 }
 
-func (r *Repo) NextVal(id string) int64 {
-	tx := global.DBCON.Begin()
-	nextVal := r.NextValTx(id, tx)
-	tx.Commit()
+func (r *Repo) NextVal(ctx context.Context, id string) (int64, error) {
+	tx := tenantutil.DB(ctx).Begin()
+	nextVal, err := r.NextValTx(ctx, id, tx)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
 
-	return nextVal
+	return nextVal, tx.Commit().Error
 }
 
-func (r *Repo) NextValTx(id string, tx *gorm.DB) int64 {
-	nextVal := nextVal(id, tx)
-
-	if nextVal == 1 {
-		tx.Exec("INSERT INTO public.sequence(id, next_value) VALUES (?, ?);", id, 2)
-		return nextVal
-	} else {
-		tx.Exec("UPDATE public.sequence SET next_value=? WHERE id=?;", nextVal+1, id)
-		return nextVal
-	}
+func (r *Repo) NextValTx(ctx context.Context, id string, tx *gorm.DB) (int64, error) {
+	return nextVal(ctx, id, tx)
 }
@@ -1,27 +1,36 @@
 package supplierrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	supplierdomain "dromatech/pos-backend/internal/domain/supplier"
 	dateutil "dromatech/pos-backend/internal/util/date"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	"time"
 )
 
 type SupplierRepo interface {
-	Find(params map[string]interface{}) ([]*supplierdomain.Supplier, error)
-	Create(product *supplierdomain.Supplier) error
-	Edit(product *supplierdomain.Supplier) error
-	GetBuyPrice(params []queryutil.Param) ([]*supplierdomain.BuyPriceResponse, error)
-	UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error
-	DeleteBuyPrice(supplierId, unitId, date string) error
-	AddBuyPrice(entity supplierdomain.AddPriceRequest) error
-	FindBuyPrice(params []queryutil.Param) ([]*supplierdomain.PriceResponse, error)
-	AddBuyPriceTx(entity supplierdomain.AddPriceRequest, tx *gorm.DB)
+	Find(ctx context.Context, params map[string]interface{}) ([]*supplierdomain.Supplier, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*supplierdomain.Supplier, int64, error)
+	Create(ctx context.Context, product *supplierdomain.Supplier) error
+	Edit(ctx context.Context, product *supplierdomain.Supplier) error
+	GetBuyPrice(ctx context.Context, params []queryutil.Param) ([]*supplierdomain.BuyPriceResponse, error)
+	UpdateBuyPrice(ctx context.Context, request supplierdomain.BuyPriceRequest) error
+	DeleteBuyPrice(ctx context.Context, supplierId, unitId, date string) error
+	AddBuyPrice(ctx context.Context, entity supplierdomain.AddPriceRequest) error
+	FindBuyPrice(ctx context.Context, params []queryutil.Param) ([]*supplierdomain.PriceResponse, error)
+	AddBuyPriceTx(ctx context.Context, entity supplierdomain.AddPriceRequest, tx *gorm.DB)
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"code": "code",
+	"name": "name",
 }
 
 type Repo struct {
@@ -32,25 +41,20 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params map[string]interface{}) ([]*supplierdomain.Supplier, error) {
-	where := ""
-	var values []interface{}
-	for key, value := range params {
-		if where != "" {
-			where += "AND "
-		}
-		where += key + " = ? "
-		values = append(values, value)
-	}
+func (r *Repo) Find(ctx context.Context, params map[string]interface{}) ([]*supplierdomain.Supplier, error) {
+	entities, _, err := r.FindList(ctx, queryutil.FromMap(params), queryutil.ListParam{})
+	return entities, err
+}
 
-	if where != "" {
-		where = "WHERE " + where
-	}
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*supplierdomain.Supplier, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "code", "name")
+	where, values = scopeutil.Where(ctx, where, values, "id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT id, code, name, description, active FROM supplier %s ORDER BY code", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, code, name, description, active FROM supplier %s %s %s", where, list.OrderBy(sortColumns, "code"), list.LimitOffset()), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -69,7 +73,7 @@ func (r *Repo) Find(params map[string]interface{}) ([]*supplierdomain.Supplier,
 		if ID.Valid && ID.String != "" {
 			entity.ID = ID.String
 		} else {
-			return nil, nil
+			return nil, 0, nil
 		}
 
 		if Code.Valid {
@@ -91,22 +95,31 @@ func (r *Repo) Find(params map[string]interface{}) ([]*supplierdomain.Supplier,
 		entities = append(entities, entity)
 	}
 
-	return entities, nil
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM supplier %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
 }
 
-func (r *Repo) Create(entity *supplierdomain.Supplier) error {
-	return global.DBCON.Exec("INSERT INTO public.supplier(id, code, name, description, active) "+
+func (r *Repo) Create(ctx context.Context, entity *supplierdomain.Supplier) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO supplier(id, code, name, description, active) "+
 		"VALUES (?, ?, ?, ?, ?)",
 		entity.ID, entity.Code, entity.Name, entity.Description, entity.Active).Error
 }
 
-func (r *Repo) Edit(entity *supplierdomain.Supplier) error {
-	return global.DBCON.Exec("UPDATE public.supplier "+
+func (r *Repo) Edit(ctx context.Context, entity *supplierdomain.Supplier) error {
+	return tenantutil.DB(ctx).Exec("UPDATE supplier "+
 		"SET code=?, name=?, description=?, active=? "+
 		"WHERE id=?;", entity.Code, entity.Name, entity.Description, entity.Active, entity.ID).Error
 }
 
-func (r *Repo) GetBuyPrice(params []queryutil.Param) ([]*supplierdomain.BuyPriceResponse, error) {
+func (r *Repo) GetBuyPrice(ctx context.Context, params []queryutil.Param) ([]*supplierdomain.BuyPriceResponse, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -125,12 +138,12 @@ func (r *Repo) GetBuyPrice(params []queryutil.Param) ([]*supplierdomain.BuyPrice
 		where = "WHERE " + where
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT p.id, p.code, p.name, p.description, bp.price FROM product p "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT p.id, p.code, p.name, p.description, bp.price FROM product p "+
 		"JOIN buy_price bp ON (p.id = bp.product_id) "+
 		"%s ORDER BY p.code", where), values...).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -164,11 +177,11 @@ func (r *Repo) GetBuyPrice(params []queryutil.Param) ([]*supplierdomain.BuyPrice
 	return entities, nil
 }
 
-func (r *Repo) UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) UpdateBuyPrice(ctx context.Context, request supplierdomain.BuyPriceRequest) error {
+	tx := tenantutil.DB(ctx).Begin()
 
 	for _, detail := range request.Prices {
-		tx.Exec("INSERT INTO public.buy_price(date, supplier_id, product_id, price) "+
+		tx.Exec("INSERT INTO buy_price(date, supplier_id, product_id, price) "+
 			"VALUES (?, ?, ?, ?, ?)", request.Date, request.SupplierId, detail.ProductID, detail.Price)
 
 		if tx.Error != nil {
@@ -180,25 +193,25 @@ func (r *Repo) UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error {
 	return tx.Error
 }
 
-func (r *Repo) DeleteBuyPrice(supplierId, unitId, date string) error {
-	tx := global.DBCON.Exec("DELETE from public.buy_price WHERE supplier_id = ? AND date = ? ",
+func (r *Repo) DeleteBuyPrice(ctx context.Context, supplierId, unitId, date string) error {
+	tx := tenantutil.DB(ctx).Exec("DELETE from buy_price WHERE supplier_id = ? AND date = ? ",
 		supplierId, unitId, date)
 
 	return tx.Error
 }
 
-func (r *Repo) AddBuyPrice(entity supplierdomain.AddPriceRequest) error {
+func (r *Repo) AddBuyPrice(ctx context.Context, entity supplierdomain.AddPriceRequest) error {
 
-	tx := global.DBCON.Begin()
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.buy_price SET latest=FALSE "+
+	tx.Exec("UPDATE buy_price SET latest=FALSE "+
 		"WHERE product_id = ? AND latest=TRUE;", entity.ProductID)
 
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	tx.Exec("INSERT INTO public.buy_price(id, date, product_id, price, web_user_id, latest, transaction_id) "+
+	tx.Exec("INSERT INTO buy_price(id, date, product_id, price, web_user_id, latest, transaction_id) "+
 		"VALUES (?, ?, ?, ?, ?, ?, ?)", entity.ID, entity.Date, entity.ProductID, entity.Price, entity.WebUserId, entity.Latest, entity.TransactionId)
 
 	if tx.Error != nil {
@@ -210,19 +223,19 @@ func (r *Repo) AddBuyPrice(entity supplierdomain.AddPriceRequest) error {
 	return nil
 }
 
-func (r *Repo) AddBuyPriceTx(entity supplierdomain.AddPriceRequest, tx *gorm.DB) {
-	tx.Exec("UPDATE public.buy_price SET latest=FALSE "+
+func (r *Repo) AddBuyPriceTx(ctx context.Context, entity supplierdomain.AddPriceRequest, tx *gorm.DB) {
+	tx.Exec("UPDATE buy_price SET latest=FALSE "+
 		"WHERE product_id = ? AND latest=TRUE;", entity.UnitId, entity.ProductID)
 
 	if tx.Error != nil {
 		return
 	}
 
-	tx.Exec("INSERT INTO public.buy_price(id, date, product_id, price, web_user_id, latest, transaction_id) "+
+	tx.Exec("INSERT INTO buy_price(id, date, product_id, price, web_user_id, latest, transaction_id) "+
 		"VALUES (?, ?, ?, ?, ?, ?, ?)", entity.ID, entity.Date, entity.ProductID, entity.Price, entity.WebUserId, entity.Latest, entity.TransactionId)
 }
 
-func (r *Repo) FindBuyPrice(params []queryutil.Param) ([]*supplierdomain.PriceResponse, error) {
+func (r *Repo) FindBuyPrice(ctx context.Context, params []queryutil.Param) ([]*supplierdomain.PriceResponse, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -241,16 +254,16 @@ func (r *Repo) FindBuyPrice(params []queryutil.Param) ([]*supplierdomain.PriceRe
 		where = "WHERE " + where
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT s.id, s.date, s.supplier_id, p.unit_id, s.product_id, s.price, w.username, w.name, t.code "+
-		"FROM public.buy_price s "+
-		"JOIN public.web_user w ON (w.id = s.web_user_id) " +
-		"JOIN public.product p ON (p.id = s.product_id) " +
-		"JOIN public.unit u ON (u.id = p.unit_id) " +
-		"LEFT JOIN public.transaction t ON (t.id = s.transaction_id) "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT s.id, s.date, s.supplier_id, p.unit_id, s.product_id, s.price, w.username, w.name, t.code "+
+		"FROM buy_price s "+
+		"JOIN web_user w ON (w.id = s.web_user_id) "+
+		"JOIN product p ON (p.id = s.product_id) "+
+		"JOIN unit u ON (u.id = p.unit_id) "+
+		"LEFT JOIN transaction t ON (t.id = s.transaction_id) "+
 		"%s ORDER BY date DESC ", where), values...).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -0,0 +1,108 @@
+package tenantrepo
+
+import (
+	"context"
+	"database/sql"
+	"dromatech/pos-backend/global"
+	tenantdomain "dromatech/pos-backend/internal/domain/tenant"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+)
+
+// TenantRepo reads the tenant registry, the registry is kept in the public schema whatever the tenant of ctx
+type TenantRepo interface {
+	FindByCode(ctx context.Context, code string) (*tenantdomain.Tenant, error)
+	FindActive(ctx context.Context) ([]*tenantdomain.Tenant, error)
+	SchemaExists(ctx context.Context, schema string) (bool, error)
+	CreateSchema(ctx context.Context, schema string) error
+	DropSchema(ctx context.Context, schema string) error
+	RunScript(ctx context.Context, script string) error
+	Create(ctx context.Context, tenant *tenantdomain.Tenant) error
+}
+
+type Repo struct {
+}
+
+func New() *Repo {
+	repo := &Repo{}
+	return repo
+}
+
+const selectTenant = "SELECT id, code, name, schema_name, active, created_time FROM public.tenant "
+
+// FindByCode returns the tenant of the code, nil when it does not exist
+func (r *Repo) FindByCode(ctx context.Context, code string) (*tenantdomain.Tenant, error) {
+	tenants, err := r.find(ctx, selectTenant+"WHERE code = ?", code)
+	if err != nil || len(tenants) == 0 {
+		return nil, err
+	}
+	return tenants[0], nil
+}
+
+func (r *Repo) FindActive(ctx context.Context) ([]*tenantdomain.Tenant, error) {
+	return r.find(ctx, selectTenant+"WHERE active = TRUE ORDER BY code")
+}
+
+func (r *Repo) find(ctx context.Context, query string, values ...interface{}) ([]*tenantdomain.Tenant, error) {
+	rows, err := global.DBCON.Raw(query, values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := []*tenantdomain.Tenant{}
+	for rows.Next() {
+		var ID, Code, Name, Schema sql.NullString
+		var Active sql.NullBool
+		var CreatedTime sql.NullTime
+		rows.Scan(&ID, &Code, &Name, &Schema, &Active, &CreatedTime)
+
+		tenants = append(tenants, &tenantdomain.Tenant{
+			ID:          ID.String,
+			Code:        Code.String,
+			Name:        Name.String,
+			Schema:      Schema.String,
+			Active:      Active.Bool,
+			CreatedTime: CreatedTime.Time,
+		})
+	}
+	return tenants, nil
+}
+
+func (r *Repo) SchemaExists(ctx context.Context, schema string) (bool, error) {
+	var count int64
+	err := global.DBCON.Raw("SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?", schema).Row().Scan(&count)
+	return count > 0, err
+}
+
+// CreateSchema and DropSchema take a schema checked by tenantutil.ValidSchema, a schema name cannot be a bind parameter
+func (r *Repo) CreateSchema(ctx context.Context, schema string) error {
+	if !tenantutil.ValidSchema(schema) {
+		return fmt.Errorf("invalid tenant schema %q", schema)
+	}
+	return global.DBCON.Exec("CREATE SCHEMA " + schema).Error
+}
+
+func (r *Repo) DropSchema(ctx context.Context, schema string) error {
+	if !tenantutil.ValidSchema(schema) {
+		return fmt.Errorf("invalid tenant schema %q", schema)
+	}
+	return global.DBCON.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE").Error
+}
+
+// RunScript runs the statements of an sql file on the schema of the tenant of ctx in one round trip
+func (r *Repo) RunScript(ctx context.Context, script string) error {
+	db, err := tenantutil.DB(ctx).DB()
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, script)
+	return err
+}
+
+func (r *Repo) Create(ctx context.Context, tenant *tenantdomain.Tenant) error {
+	return global.DBCON.Exec("INSERT INTO public.tenant(id, code, name, schema_name, active, created_time) VALUES (?, ?, ?, ?, ?, ?)",
+		tenant.ID, tenant.Code, tenant.Name, tenant.Schema, tenant.Active, tenant.CreatedTime).Error
+}
@@ -0,0 +1,245 @@
+package transactionrepo
+
+import (
+	"context"
+	"database/sql"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	logutil "dromatech/pos-backend/internal/util/log"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	stringutil "dromatech/pos-backend/internal/util/string"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const selectClosingQuery = "SELECT c.id, c.date, c.web_user_id, wu.name, c.branch_id, c.saldo_awal, c.dana_tambahan, c.dana_masuk, c.belanja, " +
+	"c.operasional, c.dana_keluar, c.saldo_akhir, c.closed_by, c.closed_time " +
+	"FROM cash_closing c JOIN web_user wu ON wu.id = c.web_user_id "
+
+// FindClosing returns the closing of the day in the branch of ctx, a day is closed per user and branch
+func (r *Repo) FindClosing(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error) {
+	branchID, _ := scopeutil.BranchID(ctx)
+	rows, err := tenantutil.DB(ctx).Raw(selectClosingQuery+"WHERE c.web_user_id = ? AND c.date = ? AND COALESCE(c.branch_id, '') = ?",
+		userID, date, branchID).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+	return scanClosing(rows), nil
+}
+
+// FindClosingList returns the closed days between startDate and endDate, of every mobile user when userID is empty
+func (r *Repo) FindClosingList(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosing, error) {
+	branch, branchValues := branchAnd(ctx, "c.branch_id")
+	query := selectClosingQuery + "WHERE c.date BETWEEN ? AND ? " + branch
+	values := append([]interface{}{startDate, endDate}, branchValues...)
+	if userID != "" {
+		query += "AND c.web_user_id = ? "
+		values = append(values, userID)
+	}
+
+	rows, err := tenantutil.DB(ctx).Raw(query+"ORDER BY c.date DESC, wu.name", values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	closings := []transactiondomain.CashClosing{}
+	for rows.Next() {
+		closings = append(closings, *scanClosing(rows))
+	}
+	return closings, nil
+}
+
+func scanClosing(rows *sql.Rows) *transactiondomain.CashClosing {
+	var ID sql.NullString
+	var Date sql.NullTime
+	var WebUserID sql.NullString
+	var Name sql.NullString
+	var BranchID sql.NullString
+	var SaldoAwal sql.NullFloat64
+	var DanaTambahan sql.NullFloat64
+	var DanaMasuk sql.NullFloat64
+	var Belanja sql.NullFloat64
+	var Operasional sql.NullFloat64
+	var DanaKeluar sql.NullFloat64
+	var SaldoAkhir sql.NullFloat64
+	var ClosedBy sql.NullString
+	var ClosedTime sql.NullTime
+
+	rows.Scan(&ID, &Date, &WebUserID, &Name, &BranchID, &SaldoAwal, &DanaTambahan, &DanaMasuk, &Belanja,
+		&Operasional, &DanaKeluar, &SaldoAkhir, &ClosedBy, &ClosedTime)
+
+	return &transactiondomain.CashClosing{
+		ID:        ID.String,
+		Date:      Date.Time,
+		WebUserID: WebUserID.String,
+		Name:      Name.String,
+		BranchID:  BranchID.String,
+		SaldoResponse: transactiondomain.SaldoResponse{
+			SaldoAwal:    SaldoAwal.Float64,
+			DanaTambahan: DanaTambahan.Float64,
+			DanaMasuk:    DanaMasuk.Float64,
+			Belanja:      Belanja.Float64,
+			Operasional:  Operasional.Float64,
+			DanaKeluar:   DanaKeluar.Float64,
+			SaldoAkhir:   SaldoAkhir.Float64,
+		},
+		ClosedBy:   ClosedBy.String,
+		ClosedTime: ClosedTime.Time,
+	}
+}
+
+// CreateClosing stores the snapshot of the day
+func (r *Repo) CreateClosing(ctx context.Context, closing *transactiondomain.CashClosing, tx *gorm.DB) error {
+	err := tx.Exec("INSERT INTO cash_closing(id, web_user_id, date, saldo_awal, dana_tambahan, dana_masuk, belanja, "+
+		"operasional, dana_keluar, saldo_akhir, closed_by, closed_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''));",
+		closing.ID, closing.WebUserID, closing.Date, closing.SaldoAwal, closing.DanaTambahan, closing.DanaMasuk, closing.Belanja,
+		closing.Operasional, closing.DanaKeluar, closing.SaldoAkhir, closing.ClosedBy, closing.ClosedTime, closing.BranchID).Error
+	if err != nil {
+		return err
+	}
+
+	return logChange(tx, closing.WebUserID, transactiondomain.SyncEntityClosing, closing.ID, transactiondomain.SyncActionUpsert)
+}
+
+// UpdateSaldoAwal sets the saldo awal of the dana of the day in the branch, the dana is created when the day has none yet
+// and an existing one keeps its dana tambahan
+func (r *Repo) UpdateSaldoAwal(ctx context.Context, userID, branchID string, date time.Time, saldoAwal float64, tx *gorm.DB) error {
+	result := tx.Exec("UPDATE dana SET saldo_awal = ? WHERE web_user_id = ? AND date = ? AND COALESCE(branch_id, '') = ?;",
+		saldoAwal, userID, date, branchID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		danaID := stringutil.GenerateUUID()
+		err := tx.Exec("INSERT INTO dana(id, date, web_user_id, saldo_awal, dana_tambahan, created_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''));",
+			danaID, date, userID, saldoAwal, 0, time.Now(), branchID).Error
+		if err != nil {
+			return err
+		}
+		return logChange(tx, userID, transactiondomain.SyncEntityDana, danaID, transactiondomain.SyncActionUpsert)
+	}
+
+	return tx.Exec("INSERT INTO mobile_change(web_user_id, entity, entity_id, action, changed_time) "+
+		"SELECT web_user_id, ?, id, ?, ? FROM dana WHERE web_user_id = ? AND date = ? AND COALESCE(branch_id, '') = ?;",
+		transactiondomain.SyncEntityDana, transactiondomain.SyncActionUpsert, time.Now(), userID, date, branchID).Error
+}
+
+// ReopenClosing removes the closing of the day and leaves the audit record in the same transaction
+func (r *Repo) ReopenClosing(ctx context.Context, reopen *transactiondomain.CashClosingReopen) error {
+	tx := tenantutil.DB(ctx).Begin()
+	err := tx.Exec("INSERT INTO mobile_change(web_user_id, entity, entity_id, action, changed_time) "+
+		"SELECT web_user_id, ?, id, ?, ? FROM cash_closing WHERE web_user_id = ? AND date = ? AND COALESCE(branch_id, '') = ?;",
+		transactiondomain.SyncEntityClosing, transactiondomain.SyncActionDelete, time.Now(), reopen.WebUserID, reopen.Date, reopen.BranchID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Exec("DELETE FROM cash_closing WHERE web_user_id = ? AND date = ? AND COALESCE(branch_id, '') = ?;",
+		reopen.WebUserID, reopen.Date, reopen.BranchID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Exec("INSERT INTO cash_closing_reopen(id, web_user_id, date, saldo_akhir, closed_by, closed_time, reason, "+
+		"reopened_by, reopened_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''));",
+		reopen.ID, reopen.WebUserID, reopen.Date, reopen.SaldoAkhir, reopen.ClosedBy, reopen.ClosedTime, reopen.Reason,
+		reopen.ReopenedBy, reopen.ReopenedTime, reopen.BranchID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (r *Repo) FindReopenHistory(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosingReopen, error) {
+	branch, branchValues := branchAnd(ctx, "o.branch_id")
+	query := "SELECT o.id, o.date, o.web_user_id, wu.name, o.branch_id, o.saldo_akhir, o.closed_by, o.closed_time, o.reason, o.reopened_by, o.reopened_time " +
+		"FROM cash_closing_reopen o JOIN web_user wu ON wu.id = o.web_user_id " +
+		"WHERE o.date BETWEEN ? AND ? " + branch
+	values := append([]interface{}{startDate, endDate}, branchValues...)
+	if userID != "" {
+		query += "AND o.web_user_id = ? "
+		values = append(values, userID)
+	}
+
+	rows, err := tenantutil.DB(ctx).Raw(query+"ORDER BY o.reopened_time DESC", values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []transactiondomain.CashClosingReopen{}
+	for rows.Next() {
+		var ID sql.NullString
+		var Date sql.NullTime
+		var WebUserID sql.NullString
+		var Name sql.NullString
+		var BranchID sql.NullString
+		var SaldoAkhir sql.NullFloat64
+		var ClosedBy sql.NullString
+		var ClosedTime sql.NullTime
+		var Reason sql.NullString
+		var ReopenedBy sql.NullString
+		var ReopenedTime sql.NullTime
+		rows.Scan(&ID, &Date, &WebUserID, &Name, &BranchID, &SaldoAkhir, &ClosedBy, &ClosedTime, &Reason, &ReopenedBy, &ReopenedTime)
+
+		history = append(history, transactiondomain.CashClosingReopen{
+			ID:           ID.String,
+			Date:         Date.Time,
+			WebUserID:    WebUserID.String,
+			Name:         Name.String,
+			BranchID:     BranchID.String,
+			SaldoAkhir:   SaldoAkhir.Float64,
+			ClosedBy:     ClosedBy.String,
+			ClosedTime:   ClosedTime.Time,
+			Reason:       Reason.String,
+			ReopenedBy:   ReopenedBy.String,
+			ReopenedTime: ReopenedTime.Time,
+		})
+	}
+	return history, nil
+}
+
+// mobileEntryTables are the mobile records that can be removed, the table name never comes from the request
+var mobileEntryTables = map[string]bool{
+	transactiondomain.MobileEntryPenjualan:   true,
+	transactiondomain.MobileEntryBelanja:     true,
+	transactiondomain.MobileEntryOperasional: true,
+}
+
+// FindMobileEntry returns the owner and day of a penjualan_tunai, belanja or operasional record, nil when it does not exist
+func (r *Repo) FindMobileEntry(ctx context.Context, table string, id string) (*transactiondomain.MobileEntry, error) {
+	if !mobileEntryTables[table] {
+		return nil, nil
+	}
+
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, date, web_user_id FROM "+table+" WHERE id = ?", id).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var ID sql.NullString
+	var Date sql.NullTime
+	var WebUserID sql.NullString
+	rows.Scan(&ID, &Date, &WebUserID)
+	return &transactiondomain.MobileEntry{ID: ID.String, Date: Date.Time, WebUserID: WebUserID.String}, nil
+}
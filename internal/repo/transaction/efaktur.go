@@ -0,0 +1,155 @@
+package transactionrepo
+
+import (
+	"context"
+	"database/sql"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FindEFaktur reads the sell transactions of taxable customers between the dates that were given a serial number with
+// their latest details, canceled transactions are left out and the rest are in the order their serial numbers were given
+func (r *Repo) FindEFaktur(ctx context.Context, startDate, endDate time.Time) ([]*transactiondomain.EFaktur, error) {
+	where, values := eFakturWhere(ctx, startDate, endDate,
+		queryutil.Param{Logic: "AND", Field: "t.status", Operator: "<>", Value: transactiondomain.TRANSACTION_BATAL},
+		queryutil.Param{Logic: "AND", Field: "t.tax_invoice_number", Operator: "<>", Value: ""},
+		queryutil.Param{Logic: "AND", Field: "td.latest", Operator: "=", Value: true},
+	)
+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.tax_invoice_number, c.npwp, c.name, c.address, t.tax_inclusive, t.dpp, t.ppn, "+
+		"p.code, p.name, td.sell_price, td.quantity, td.tax_rate, td.dpp, td.ppn "+
+		"FROM transaction t "+
+		"JOIN transaction_detail td ON (td.transaction_id = t.id) "+
+		"JOIN customer c ON (c.id = t.stakeholder_id) "+
+		"JOIN product p ON (p.id = td.product_id) "+
+		"%s ORDER BY t.date ASC, t.code ASC, td.sorting_val ASC", where), values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entities []*transactiondomain.EFaktur
+	entityMap := make(map[string]*transactiondomain.EFaktur)
+	for rows.Next() {
+		var ID sql.NullString
+		var Code sql.NullString
+		var Date time.Time
+		var TaxInvoiceNumber sql.NullString
+		var CustomerNpwp sql.NullString
+		var CustomerName sql.NullString
+		var CustomerAddress sql.NullString
+		var TaxInclusive sql.NullBool
+		var Dpp sql.NullFloat64
+		var Ppn sql.NullFloat64
+		var ProductCode sql.NullString
+		var ProductName sql.NullString
+		var SellPrice sql.NullFloat64
+		var Quantity sql.NullFloat64
+		var TaxRate sql.NullFloat64
+		var DetailDpp sql.NullFloat64
+		var DetailPpn sql.NullFloat64
+
+		err = rows.Scan(&ID, &Code, &Date, &TaxInvoiceNumber, &CustomerNpwp, &CustomerName, &CustomerAddress, &TaxInclusive, &Dpp, &Ppn,
+			&ProductCode, &ProductName, &SellPrice, &Quantity, &TaxRate, &DetailDpp, &DetailPpn)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, err
+		}
+
+		entity, ok := entityMap[ID.String]
+		if !ok {
+			entity = &transactiondomain.EFaktur{
+				ID:               ID.String,
+				Code:             Code.String,
+				Date:             Date,
+				TaxInvoiceNumber: TaxInvoiceNumber.String,
+				CustomerNpwp:     CustomerNpwp.String,
+				CustomerName:     CustomerName.String,
+				CustomerAddress:  CustomerAddress.String,
+				TaxInclusive:     TaxInclusive.Bool,
+				Dpp:              Dpp.Float64,
+				Ppn:              Ppn.Float64,
+			}
+			entities = append(entities, entity)
+			entityMap[ID.String] = entity
+		}
+
+		entity.Details = append(entity.Details, &transactiondomain.EFakturDetail{
+			ProductCode: ProductCode.String,
+			ProductName: ProductName.String,
+			SellPrice:   SellPrice.Float64,
+			Quantity:    Quantity.Float64,
+			TaxRate:     TaxRate.Float64,
+			Dpp:         DetailDpp.Float64,
+			Ppn:         DetailPpn.Float64,
+		})
+	}
+
+	return entities, nil
+}
+
+// FindTaxInvoicePending returns the ids of the final sell transactions of taxable customers between the dates without a
+// serial number, in the order the numbers are given. The rows stay locked in tx so a concurrent allocation waits for it.
+func (r *Repo) FindTaxInvoicePending(ctx context.Context, startDate, endDate time.Time, tx *gorm.DB) ([]string, error) {
+	where, values := eFakturWhere(ctx, startDate, endDate,
+		queryutil.Param{Logic: "AND", Field: "t.status", Operator: "IN", Value: transactiondomain.TaxInvoiceStatuses},
+	)
+
+	rows, err := tx.Raw(fmt.Sprintf("SELECT t.id FROM transaction t JOIN customer c ON (c.id = t.stakeholder_id) "+
+		"%s AND t.tax_invoice_number IS NULL ORDER BY t.date ASC, t.code ASC FOR UPDATE OF t", where), values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var ID sql.NullString
+		if err := rows.Scan(&ID); err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, err
+		}
+		ids = append(ids, ID.String)
+	}
+	return ids, nil
+}
+
+// eFakturWhere restricts params to the sell transactions of taxable customers between the dates the user of ctx may read
+func eFakturWhere(ctx context.Context, startDate, endDate time.Time, params ...queryutil.Param) (string, []interface{}) {
+	where, values := queryutil.Where(append([]queryutil.Param{
+		{Logic: "AND", Field: "t.transaction_type", Operator: "=", Value: transactiondomain.TRANSACTION_TYPE_SELL},
+		{Logic: "AND", Field: "c.taxable", Operator: "=", Value: true},
+	}, params...))
+	// the date of a transaction may carry a time of day, the whole end date is included
+	where += "AND t.date::date BETWEEN ? AND ? "
+	values = append(values, startDate, endDate)
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	return scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
+}
+
+// UpdateTaxInvoiceNumber gives a transaction its serial number and returns the number it has, a transaction that
+// already has one keeps it
+func (r *Repo) UpdateTaxInvoiceNumber(ctx context.Context, transactionID, number string, tx *gorm.DB) (string, error) {
+	result := tx.Exec("UPDATE transaction SET tax_invoice_number=? WHERE id=? AND tax_invoice_number IS NULL;", number, transactionID)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 1 {
+		return number, nil
+	}
+
+	var stored sql.NullString
+	if err := tx.Raw("SELECT tax_invoice_number FROM transaction WHERE id=?", transactionID).Row().Scan(&stored); err != nil {
+		return "", err
+	}
+	return stored.String, nil
+}
@@ -1,24 +1,31 @@
 package transactionrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	logutil "dromatech/pos-backend/internal/util/log"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
 	stringutil "dromatech/pos-backend/internal/util/string"
-	"log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
-func (r *Repo) FindDana(userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error) {
+func (r *Repo) FindDana(ctx context.Context, userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error) {
 	var dana transactiondomain.DanaInquiryResponse
 
 	// find to database
 	var ID sql.NullString
 	var SaldoAwal sql.NullFloat64
 	var DanaTambahan sql.NullFloat64
-	query := "SELECT id, saldo_awal, dana_tambahan FROM dana WHERE web_user_id = ? AND date = ?"
-	rows, err := global.DBCON.Raw(query, userID, date).Rows()
+	branch, branchValues := branchAnd(ctx, "branch_id")
+	query := "SELECT id, saldo_awal, dana_tambahan FROM dana WHERE web_user_id = ? AND date = ? " + branch
+	rows, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{userID, date}, branchValues...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -36,15 +43,18 @@ func (r *Repo) FindDana(userID string, date time.Time) (*transactiondomain.DanaI
 	dana.DanaTambahan = DanaTambahan.Float64
 
 	// find dana masuk
+	transferBranch, transferBranchValues := branchAnd(ctx, "dt.branch_id")
 	var IDDanaMasuk sql.NullString
 	var Sender sql.NullString
 	var Amount sql.NullFloat64
 	var Status sql.NullString
 	query = "SELECT dt.id, wu.name, dt.amount, dt.status FROM dana_transaction dt " +
 		"JOIN web_user wu ON dt.sender = wu.id " +
-		"WHERE dt.receiver = ? AND dt.date = ? AND dt.status IN (?, ?) ORDER BY dt.created_time DESC"
-	rows2, err := global.DBCON.Raw(query, userID, date, transactiondomain.DanaStatusApproved, transactiondomain.DanaStatusPending).Rows()
+		"WHERE dt.receiver = ? AND dt.date = ? AND dt.status IN (?, ?) " + transferBranch + "ORDER BY dt.created_time DESC"
+	rows2, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{userID, date, transactiondomain.DanaStatusApproved, transactiondomain.DanaStatusPending},
+		transferBranchValues...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows2.Close()
@@ -70,9 +80,11 @@ func (r *Repo) FindDana(userID string, date time.Time) (*transactiondomain.DanaI
 	var StatusKeluar sql.NullString
 	query = "SELECT dt.id, wu.name, dt.amount, dt.status FROM dana_transaction dt " +
 		"JOIN web_user wu ON dt.receiver = wu.id " +
-		"WHERE dt.sender = ? AND dt.date = ? AND dt.status IN (?, ?) ORDER BY dt.created_time DESC"
-	rows3, err := global.DBCON.Raw(query, userID, date, transactiondomain.DanaStatusApproved, transactiondomain.DanaStatusPending).Rows()
+		"WHERE dt.sender = ? AND dt.date = ? AND dt.status IN (?, ?) " + transferBranch + "ORDER BY dt.created_time DESC"
+	rows3, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{userID, date, transactiondomain.DanaStatusApproved, transactiondomain.DanaStatusPending},
+		transferBranchValues...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows3.Close()
@@ -94,7 +106,7 @@ func (r *Repo) FindDana(userID string, date time.Time) (*transactiondomain.DanaI
 	return &dana, nil
 }
 
-func (r *Repo) FindDanaByID(id string) (*transactiondomain.Dana, error) {
+func (r *Repo) FindDanaByID(ctx context.Context, id string) (*transactiondomain.Dana, error) {
 	var dana transactiondomain.Dana
 
 	var ID sql.NullString
@@ -104,8 +116,9 @@ func (r *Repo) FindDanaByID(id string) (*transactiondomain.Dana, error) {
 	var DanaTambahan sql.NullFloat64
 	var CreatedTime sql.NullTime
 	query := "SELECT id, date, web_user_id, saldo_awal, dana_tambahan, created_time FROM dana WHERE id = ?"
-	rows, err := global.DBCON.Raw(query, id).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(query, id).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -124,7 +137,7 @@ func (r *Repo) FindDanaByID(id string) (*transactiondomain.Dana, error) {
 	return &dana, nil
 }
 
-func (r *Repo) FindDanaTransaction(id string) (*transactiondomain.DanaTransaction, error) {
+func (r *Repo) FindDanaTransaction(ctx context.Context, id string) (*transactiondomain.DanaTransaction, error) {
 	var danaTransaction transactiondomain.DanaTransaction
 
 	// find dana transaction
@@ -136,8 +149,9 @@ func (r *Repo) FindDanaTransaction(id string) (*transactiondomain.DanaTransactio
 	var Status sql.NullString
 	var CreatedTime sql.NullTime
 	query := "SELECT id, date, sender, receiver, amount, status, created_time FROM dana_transaction WHERE id = ? AND (status = ? OR status = ?)"
-	rows, err := global.DBCON.Raw(query, id, transactiondomain.DanaStatusPending, transactiondomain.DanaStatusApproved).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(query, id, transactiondomain.DanaStatusPending, transactiondomain.DanaStatusApproved).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -156,110 +170,172 @@ func (r *Repo) FindDanaTransaction(id string) (*transactiondomain.DanaTransactio
 	return &danaTransaction, nil
 }
 
-func (r *Repo) CreateDana(userID string, request transactiondomain.DanaRequest) error {
-	tx := global.DBCON.Begin()
-
-	ID := stringutil.GenerateUUID()
+func (r *Repo) CreateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error {
+	ID := request.ID
+	if ID == "" {
+		ID = stringutil.GenerateUUID()
+	}
 	date, err := time.Parse("2006-01-02", request.Date)
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return err
 	}
-	tx.Exec("INSERT INTO public.dana(id, date, web_user_id, saldo_awal, dana_tambahan, created_time) VALUES (?, ?, ?, ?, ?, ?);",
-		ID, date, userID, request.SaldoAwal, request.DanaTambahan, time.Now())
 
-	if tx.Error != nil {
+	tx := tenantutil.DB(ctx).Begin()
+	err = tx.Exec("INSERT INTO dana(id, date, web_user_id, saldo_awal, dana_tambahan, created_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?);",
+		ID, date, userID, request.SaldoAwal, request.DanaTambahan, createdTime(request.CreatedTime), scopeutil.Branch(ctx)).Error
+	if err != nil {
 		tx.Rollback()
-		return tx.Error
+		return err
 	}
 
-	tx.Commit()
-
-	return nil
+	if err := logChange(tx, userID, transactiondomain.SyncEntityDana, ID, transactiondomain.SyncActionUpsert); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
-func (r *Repo) UpdateDana(userID string, request transactiondomain.DanaRequest) error {
-	tx := global.DBCON.Begin()
-
-	tx.Exec("UPDATE public.dana SET saldo_awal = ?, dana_tambahan = ? WHERE id = ? AND web_user_id = ?;",
-		request.SaldoAwal, request.DanaTambahan, request.ID, userID)
-
-	if tx.Error != nil {
+func (r *Repo) UpdateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error {
+	tx := tenantutil.DB(ctx).Begin()
+	err := tx.Exec("UPDATE dana SET saldo_awal = ?, dana_tambahan = ? WHERE id = ? AND web_user_id = ?;",
+		request.SaldoAwal, request.DanaTambahan, request.ID, userID).Error
+	if err != nil {
 		tx.Rollback()
-		return tx.Error
+		return err
 	}
 
-	tx.Commit()
-
-	return nil
+	if err := logChange(tx, userID, transactiondomain.SyncEntityDana, request.ID, transactiondomain.SyncActionUpsert); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
-func (r *Repo) SendDana(userID string, request transactiondomain.DanaTransactionRequest) error {
-	tx := global.DBCON.Begin()
-
-	ID := stringutil.GenerateUUID()
+func (r *Repo) SendDana(ctx context.Context, userID string, request transactiondomain.DanaTransactionRequest) error {
+	ID := request.ID
+	if ID == "" {
+		ID = stringutil.GenerateUUID()
+	}
 	date, err := time.Parse("2006-01-02", request.Date)
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return err
 	}
-	tx.Exec("INSERT INTO public.dana_transaction(id, date, sender, receiver, amount, status, created_time) VALUES (?, ?, ?, ?, ?, ?, ?);",
-		ID, date, userID, request.Receiver, request.Amount, transactiondomain.DanaStatusPending, time.Now())
 
-	if tx.Error != nil {
+	tx := tenantutil.DB(ctx).Begin()
+	err = tx.Exec("INSERT INTO dana_transaction(id, date, sender, receiver, amount, status, created_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?);",
+		ID, date, userID, request.Receiver, request.Amount, transactiondomain.DanaStatusPending, createdTime(request.CreatedTime), scopeutil.Branch(ctx)).Error
+	if err != nil {
 		tx.Rollback()
-		return tx.Error
+		return err
 	}
 
-	tx.Commit()
-
-	return nil
+	if err := logTransferChange(tx, ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
-func (r *Repo) ApproveDana(id string) error {
-	tx := global.DBCON.Begin()
+// ApproveDana approves the transfer when it is still pending, false when it was decided or expired meanwhile
+func (r *Repo) ApproveDana(ctx context.Context, id, decidedBy string) (bool, error) {
+	return decideDanaStatus(ctx, id, transactiondomain.DanaStatusApproved, decidedBy)
+}
 
-	tx.Exec("UPDATE public.dana_transaction SET status = ? WHERE id = ?;", transactiondomain.DanaStatusApproved, id)
+// RejectDana rejects the transfer when it is still pending, false when it was decided or expired meanwhile
+func (r *Repo) RejectDana(ctx context.Context, id, decidedBy string) (bool, error) {
+	return decideDanaStatus(ctx, id, transactiondomain.DanaStatusRejected, decidedBy)
+}
 
-	if tx.Error != nil {
+// ExpireDanaTransfers expires the transfers still pending that the server saved before the time and returns their ids
+func (r *Repo) ExpireDanaTransfers(ctx context.Context, before time.Time) ([]string, error) {
+	tx := tenantutil.DB(ctx).Begin()
+	rows, err := tx.Raw("UPDATE dana_transaction SET status = ? WHERE status = ? AND inserted_time < ? RETURNING id;",
+		transactiondomain.DanaStatusExpired, transactiondomain.DanaStatusPending, before).Rows()
+	if err != nil {
 		tx.Rollback()
-		return tx.Error
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
 	}
 
-	tx.Commit()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		rows.Scan(&id)
+		ids = append(ids, id)
+	}
+	rows.Close()
 
-	return nil
+	for _, id := range ids {
+		if err := logTransferChange(tx, id); err != nil {
+			tx.Rollback()
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, err
+		}
+	}
+	return ids, tx.Commit().Error
 }
 
-func (r *Repo) RejectDana(id string) error {
-	tx := global.DBCON.Begin()
-
-	tx.Exec("UPDATE public.dana_transaction SET status = ? WHERE id = ?;", transactiondomain.DanaStatusRejected, id)
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
+// IsSupervisorOf checks the supervisor manages every one of the users
+func (r *Repo) IsSupervisorOf(ctx context.Context, supervisorID string, userIDs ...string) (bool, error) {
+	var count int64
+	err := tenantutil.DB(ctx).Raw("SELECT COUNT(DISTINCT web_user_id) FROM web_user_supervisor WHERE supervisor_id = ? AND web_user_id IN ?",
+		supervisorID, userIDs).Row().Scan(&count)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return false, err
 	}
 
-	tx.Commit()
-
-	return nil
+	distinct := map[string]bool{}
+	for _, id := range userIDs {
+		distinct[id] = true
+	}
+	return count == int64(len(distinct)), nil
 }
 
-func (r *Repo) CancelSendDana(id string) error {
-	tx := global.DBCON.Begin()
-
-	tx.Exec("UPDATE public.dana_transaction SET status = ? WHERE id = ?;", transactiondomain.DanaStatusCanceled, id)
+// FindSupervisedTransfers returns the transfers whose sender and receiver are both managed by the supervisor, every status when empty
+func (r *Repo) FindSupervisedTransfers(ctx context.Context, supervisorID string, status transactiondomain.DanaStatus) ([]transactiondomain.SupervisedDanaTransfer, error) {
+	query := "SELECT dt.id, dt.date, dt.sender, s.name, dt.receiver, rc.name, dt.amount, dt.status, dt.decided_by, dt.created_time " +
+		"FROM dana_transaction dt JOIN web_user s ON s.id = dt.sender JOIN web_user rc ON rc.id = dt.receiver " +
+		"WHERE dt.sender IN (SELECT web_user_id FROM web_user_supervisor WHERE supervisor_id = ?) " +
+		"AND dt.receiver IN (SELECT web_user_id FROM web_user_supervisor WHERE supervisor_id = ?) "
+	values := []interface{}{supervisorID, supervisorID}
+	if status != "" {
+		query += "AND dt.status = ? "
+		values = append(values, status)
+	}
 
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
+	rows, err := tenantutil.DB(ctx).Raw(query+"ORDER BY dt.created_time DESC", values...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
 	}
+	defer rows.Close()
 
-	tx.Commit()
+	transfers := []transactiondomain.SupervisedDanaTransfer{}
+	for rows.Next() {
+		var SenderName sql.NullString
+		var ReceiverName sql.NullString
+		var Status sql.NullString
+		var DecidedBy sql.NullString
+		transfer := transactiondomain.SupervisedDanaTransfer{}
+		rows.Scan(&transfer.ID, &transfer.Date, &transfer.Sender, &SenderName, &transfer.Receiver, &ReceiverName,
+			&transfer.Amount, &Status, &DecidedBy, &transfer.CreatedTime)
+		transfer.SenderName = SenderName.String
+		transfer.ReceiverName = ReceiverName.String
+		transfer.Status = transactiondomain.DanaStatus(Status.String)
+		transfer.DecidedBy = DecidedBy.String
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
 
-	return nil
+func (r *Repo) CancelSendDana(ctx context.Context, id string) error {
+	return updateDanaStatus(ctx, id, transactiondomain.DanaStatusCanceled)
 }
 
-func (r *Repo) CheckUserMobilePermission(id string) (bool, error) {
+func (r *Repo) CheckUserMobilePermission(ctx context.Context, id string) (bool, error) {
 	var count sql.NullInt32
 
 	query := "SELECT COUNT(wu.id) FROM web_user wu " +
@@ -267,8 +343,9 @@ func (r *Repo) CheckUserMobilePermission(id string) (bool, error) {
 		"JOIN role_permission rp ON r.id = rp.role_id " +
 		"JOIN permission p ON rp.permission_id = p.id " +
 		"WHERE wu.id = ? AND p.id = 'mobile'"
-	rows, err := global.DBCON.Raw(query, id).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(query, id).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return false, err
 	}
 	defer rows.Close()
@@ -280,7 +357,7 @@ func (r *Repo) CheckUserMobilePermission(id string) (bool, error) {
 	return count.Int32 > 0, nil
 }
 
-func (r *Repo) FindUserMobile(id string) ([]transactiondomain.WebUserMobile, error) {
+func (r *Repo) FindUserMobile(ctx context.Context, id string) ([]transactiondomain.WebUserMobile, error) {
 	var ID sql.NullString
 	var Name sql.NullString
 	query := "SELECT wu.id, wu.name FROM web_user wu " +
@@ -288,8 +365,9 @@ func (r *Repo) FindUserMobile(id string) ([]transactiondomain.WebUserMobile, err
 		"JOIN role_permission rp ON r.id = rp.role_id " +
 		"JOIN permission p ON rp.permission_id = p.id " +
 		"WHERE wu.id <> ? AND p.id = 'mobile'"
-	rows, err := global.DBCON.Raw(query, id).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(query, id).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -303,56 +381,28 @@ func (r *Repo) FindUserMobile(id string) ([]transactiondomain.WebUserMobile, err
 	return userMobile, nil
 }
 
-func (r *Repo) CreatePenjualan(userID string, request transactiondomain.TrxCreateRequest) error {
-	tx := global.DBCON.Begin()
-
-	ID := stringutil.GenerateUUID()
-	date, err := time.Parse("2006-01-02", request.Date)
-	if err != nil {
-		return err
-	}
-
-	tx.Exec("INSERT INTO public.penjualan_tunai(id, date, web_user_id, product_id, quantity, price, created_time) VALUES (?, ?, ?, ?, ?, ?, ?);",
-		ID, date, userID, request.ProductID, request.Quantity, request.Price, time.Now())
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
-	}
-
-	tx.Commit()
-
-	return nil
+func (r *Repo) CreatePenjualan(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error {
+	return createMobileEntry(ctx, userID, transactiondomain.SyncEntityPenjualan, "INSERT INTO penjualan_tunai(id, date, web_user_id, product_id, quantity, price, created_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?);",
+		request.ID, request.Date, request.CreatedTime, request.ProductID, request.Quantity, request.Price)
 }
 
-func (r *Repo) DeletePenjualan(id string) error {
-	tx := global.DBCON.Begin()
-
-	tx.Exec("DELETE FROM public.penjualan_tunai WHERE id = ?;", id)
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
-	}
-
-	tx.Commit()
-
-	return nil
+func (r *Repo) DeletePenjualan(ctx context.Context, id string) ([]string, error) {
+	return deleteMobileEntry(ctx, transactiondomain.MobileEntryPenjualan, transactiondomain.SyncEntityPenjualan, id)
 }
 
-func (r *Repo) FindPenjualan(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
+func (r *Repo) FindPenjualan(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
 	var ID sql.NullString
 	var ProductName sql.NullString
 	var Quantity sql.NullInt16
 	var Price sql.NullFloat64
 
-	log.Println(userID, date)
-
+	branch, branchValues := branchAnd(ctx, "p.branch_id")
 	query := "SELECT p.id, pr.name, p.quantity, p.price FROM penjualan_tunai p " +
 		"JOIN product pr ON p.product_id = pr.id " +
-		"WHERE p.web_user_id = ? AND p.date = ? ORDER BY p.created_time DESC"
-	rows, err := global.DBCON.Raw(query, userID, date).Rows()
+		"WHERE p.web_user_id = ? AND p.date = ? " + branch + "ORDER BY p.created_time DESC"
+	rows, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{userID, date}, branchValues...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -360,7 +410,6 @@ func (r *Repo) FindPenjualan(userID string, date time.Time) ([]transactiondomain
 	var penjualan []transactiondomain.TrxInquiryResponse
 	for rows.Next() {
 		rows.Scan(&ID, &ProductName, &Quantity, &Price)
-		log.Println(ID, ProductName, Quantity, Price)
 		penjualan = append(penjualan, transactiondomain.TrxInquiryResponse{
 			ID:          ID.String,
 			ProductName: ProductName.String,
@@ -372,54 +421,28 @@ func (r *Repo) FindPenjualan(userID string, date time.Time) ([]transactiondomain
 	return penjualan, nil
 }
 
-func (r *Repo) CreateBelanja(userID string, request transactiondomain.TrxCreateRequest) error {
-	tx := global.DBCON.Begin()
-
-	ID := stringutil.GenerateUUID()
-	date, err := time.Parse("2006-01-02", request.Date)
-	if err != nil {
-		return err
-	}
-
-	tx.Exec("INSERT INTO public.belanja(id, date, web_user_id, product_id, quantity, price, created_time) VALUES (?, ?, ?, ?, ?, ?, ?);",
-		ID, date, userID, request.ProductID, request.Quantity, request.Price, time.Now())
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
-	}
-
-	tx.Commit()
-
-	return nil
+func (r *Repo) CreateBelanja(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error {
+	return createMobileEntry(ctx, userID, transactiondomain.SyncEntityBelanja, "INSERT INTO belanja(id, date, web_user_id, product_id, quantity, price, created_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?);",
+		request.ID, request.Date, request.CreatedTime, request.ProductID, request.Quantity, request.Price)
 }
 
-func (r *Repo) DeleteBelanja(id string) error {
-	tx := global.DBCON.Begin()
-
-	tx.Exec("DELETE FROM public.belanja WHERE id = ?;", id)
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
-	}
-
-	tx.Commit()
-
-	return nil
+func (r *Repo) DeleteBelanja(ctx context.Context, id string) ([]string, error) {
+	return deleteMobileEntry(ctx, transactiondomain.MobileEntryBelanja, transactiondomain.SyncEntityBelanja, id)
 }
 
-func (r *Repo) FindBelanja(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
+func (r *Repo) FindBelanja(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
 	var ID sql.NullString
 	var ProductName sql.NullString
 	var Quantity sql.NullInt16
 	var Price sql.NullFloat64
 
+	branch, branchValues := branchAnd(ctx, "b.branch_id")
 	query := "SELECT b.id, pr.name, b.quantity, b.price FROM belanja b " +
 		"JOIN product pr ON b.product_id = pr.id " +
-		"WHERE b.web_user_id = ? AND b.date = ? ORDER BY b.created_time DESC"
-	rows, err := global.DBCON.Raw(query, userID, date).Rows()
+		"WHERE b.web_user_id = ? AND b.date = ? " + branch + "ORDER BY b.created_time DESC"
+	rows, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{userID, date}, branchValues...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -438,78 +461,88 @@ func (r *Repo) FindBelanja(userID string, date time.Time) ([]transactiondomain.T
 	return belanja, nil
 }
 
-func (r *Repo) CreateOperasional(userID string, request transactiondomain.TrxCreateOperasionalRequest) error {
-	tx := global.DBCON.Begin()
-
-	ID := stringutil.GenerateUUID()
-	date, err := time.Parse("2006-01-02", request.Date)
-	if err != nil {
-		return err
+// CreateOperasional inserts the operasional in tx, the budgets it counts toward are checked in the same transaction
+func (r *Repo) CreateOperasional(ctx context.Context, userID string, request transactiondomain.TrxCreateOperasionalRequest, tx *gorm.DB) error {
+	var categoryID interface{}
+	if request.CategoryID != "" {
+		categoryID = request.CategoryID
 	}
+	return insertMobileEntry(ctx, tx, userID, transactiondomain.SyncEntityOperasional, "INSERT INTO operasional(id, date, web_user_id, category_id, description, quantity, price, created_time, branch_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		request.ID, request.Date, request.CreatedTime, categoryID, request.Description, request.Quantity, request.Price)
+}
 
-	tx.Exec("INSERT INTO public.operasional(id, date, web_user_id, description, quantity, price, created_time) VALUES (?, ?, ?, ?, ?, ?, ?);",
-		ID, date, userID, request.Description, request.Quantity, request.Price, time.Now())
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
+// SumOperasional sums the operasional in the range, an empty user or category sums all of them
+func (r *Repo) SumOperasional(ctx context.Context, userID, categoryID string, startDate, endDate time.Time, tx *gorm.DB) (float64, error) {
+	query := "SELECT SUM(quantity * price) FROM operasional WHERE date BETWEEN ? AND ?"
+	args := []interface{}{startDate, endDate}
+	if userID != "" {
+		query += " AND web_user_id = ?"
+		args = append(args, userID)
+	}
+	if categoryID != "" {
+		query += " AND category_id = ?"
+		args = append(args, categoryID)
 	}
 
-	tx.Commit()
-
-	return nil
+	var total sql.NullFloat64
+	err := tx.Raw(query, args...).Row().Scan(&total)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return 0, err
+	}
+	return total.Float64, nil
 }
 
-func (r *Repo) FindOperasional(userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error) {
+func (r *Repo) FindOperasional(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error) {
 	var ID sql.NullString
+	var CategoryID sql.NullString
+	var CategoryName sql.NullString
 	var Description sql.NullString
 	var Quantity sql.NullInt16
 	var Price sql.NullFloat64
 
-	query := "SELECT o.id, o.description, o.quantity, o.price FROM operasional o WHERE o.web_user_id = ? AND o.date = ? ORDER BY o.created_time DESC"
-	rows, err := global.DBCON.Raw(query, userID, date).Rows()
+	branch, branchValues := branchAnd(ctx, "o.branch_id")
+	query := "SELECT o.id, o.category_id, ec.name, o.description, o.quantity, o.price FROM operasional o " +
+		"LEFT JOIN expense_category ec ON ec.id = o.category_id WHERE o.web_user_id = ? AND o.date = ? " + branch + "ORDER BY o.created_time DESC"
+	rows, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{userID, date}, branchValues...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
 
 	var operasional []transactiondomain.TrxInquiryOperasionalResponse
 	for rows.Next() {
-		rows.Scan(&ID, &Description, &Quantity, &Price)
+		rows.Scan(&ID, &CategoryID, &CategoryName, &Description, &Quantity, &Price)
 		operasional = append(operasional, transactiondomain.TrxInquiryOperasionalResponse{
-			ID:          ID.String,
-			Description: Description.String,
-			Quantity:    Quantity.Int16,
-			Price:       Price.Float64,
+			ID:           ID.String,
+			CategoryID:   CategoryID.String,
+			CategoryName: CategoryName.String,
+			Description:  Description.String,
+			Quantity:     Quantity.Int16,
+			Price:        Price.Float64,
 		})
 	}
 
 	return operasional, nil
 }
 
-func (r *Repo) DeleteOperasional(id string) error {
-	tx := global.DBCON.Begin()
-
-	tx.Exec("DELETE FROM public.operasional WHERE id = ?;", id)
-
-	if tx.Error != nil {
-		tx.Rollback()
-		return tx.Error
-	}
-
-	tx.Commit()
-
-	return nil
+func (r *Repo) DeleteOperasional(ctx context.Context, id string) ([]string, error) {
+	return deleteMobileEntry(ctx, transactiondomain.MobileEntryOperasional, transactiondomain.SyncEntityOperasional, id)
 }
 
-func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.SaldoResponse, error) {
+func (r *Repo) FindSaldo(ctx context.Context, userID string, date time.Time) (*transactiondomain.SaldoResponse, error) {
 	// find saldo awal and dana tambahan
 	var saldoAwalVal sql.NullFloat64
 	var danaTambahanVal sql.NullFloat64
 
-	query := "SELECT saldo_awal, dana_tambahan FROM dana WHERE web_user_id = ? AND date = ?"
-	rows, err := global.DBCON.Raw(query, userID, date).Rows()
+	// every sum reads the records of the branch only, a user working in two branches has a saldo in each
+	branch, branchValues := branchAnd(ctx, "branch_id")
+	args := append([]interface{}{userID, date}, branchValues...)
+	query := "SELECT saldo_awal, dana_tambahan FROM dana WHERE web_user_id = ? AND date = ? " + branch
+	rows, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -530,9 +563,10 @@ func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.Sald
 
 	// find dana masuk
 	query = "SELECT SUM(dt.amount) FROM dana_transaction dt " +
-		"WHERE dt.receiver = ? AND dt.date = ? AND dt.status = ?"
-	rows2, err := global.DBCON.Raw(query, userID, date, transactiondomain.DanaStatusApproved).Rows()
+		"WHERE dt.status = ? AND dt.receiver = ? AND dt.date = ? " + branch
+	rows2, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{transactiondomain.DanaStatusApproved}, args...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows2.Close()
@@ -549,9 +583,10 @@ func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.Sald
 
 	// find dana keluar
 	query = "SELECT SUM(dt.amount) FROM dana_transaction dt " +
-		"WHERE dt.sender = ? AND dt.date = ? AND dt.status = ?"
-	rows3, err := global.DBCON.Raw(query, userID, date, transactiondomain.DanaStatusApproved).Rows()
+		"WHERE dt.status = ? AND dt.sender = ? AND dt.date = ? " + branch
+	rows3, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{transactiondomain.DanaStatusApproved}, args...)...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows3.Close()
@@ -568,9 +603,10 @@ func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.Sald
 
 	// find penjualan
 	query = "SELECT SUM(p.quantity * p.price) FROM penjualan_tunai p " +
-		"WHERE p.web_user_id = ? AND p.date = ?"
-	rows4, err := global.DBCON.Raw(query, userID, date).Rows()
+		"WHERE p.web_user_id = ? AND p.date = ? " + branch
+	rows4, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows4.Close()
@@ -589,9 +625,10 @@ func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.Sald
 
 	// find belanja
 	query = "SELECT SUM(b.quantity * b.price) FROM belanja b " +
-		"WHERE b.web_user_id = ? AND b.date = ?"
-	rows5, err := global.DBCON.Raw(query, userID, date).Rows()
+		"WHERE b.web_user_id = ? AND b.date = ? " + branch
+	rows5, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows5.Close()
@@ -608,9 +645,10 @@ func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.Sald
 
 	// find operasional
 	query = "SELECT SUM(o.quantity * o.price) FROM operasional o " +
-		"WHERE o.web_user_id = ? AND o.date = ?"
-	rows6, err := global.DBCON.Raw(query, userID, date).Rows()
+		"WHERE o.web_user_id = ? AND o.date = ? " + branch
+	rows6, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows6.Close()
@@ -638,17 +676,27 @@ func (r *Repo) FindSaldo(userID string, date time.Time) (*transactiondomain.Sald
 	}, nil
 }
 
-func (r *Repo) FindRekapitulasi(date time.Time) (*transactiondomain.RekapitulasiResponse, error) {
-	// find all user mobile
-	var ID sql.NullString
-	var Name sql.NullString
-	query := "SELECT wu.id, wu.name FROM web_user wu " +
-		"JOIN role r ON wu.role_id = r.id " +
+func (r *Repo) FindRekapitulasi(ctx context.Context, date time.Time) (*transactiondomain.RekapitulasiResponse, error) {
+	// every mobile user with the sums of the date, users without belanja or operasional show zero
+	query := "SELECT wu.id, wu.name, COALESCE(b.total, 0), COALESCE(o.total, 0) FROM web_user wu " +
+		"LEFT JOIN (SELECT web_user_id, SUM(quantity * price) AS total FROM belanja WHERE date = ? %[1]s GROUP BY web_user_id) b ON b.web_user_id = wu.id " +
+		"LEFT JOIN (SELECT web_user_id, SUM(quantity * price) AS total FROM operasional WHERE date = ? %[1]s GROUP BY web_user_id) o ON o.web_user_id = wu.id " +
+		"WHERE wu.id IN (SELECT wu2.id FROM web_user wu2 " +
+		"JOIN role r ON wu2.role_id = r.id " +
 		"JOIN role_permission rp ON r.id = rp.role_id " +
 		"JOIN permission p ON rp.permission_id = p.id " +
-		"WHERE p.id = 'mobile'"
-	rows, err := global.DBCON.Raw(query).Rows()
+		"WHERE p.id = 'mobile')"
+	// with a branch only the users of the branch are listed with the records of the branch
+	branch, branchValues := branchAnd(ctx, "branch_id")
+	args := append(append([]interface{}{date}, branchValues...), date)
+	args = append(args, branchValues...)
+	if userBranch, userBranchValues := branchAnd(ctx, "ub.branch_id"); userBranch != "" {
+		query += " AND wu.id IN (SELECT ub.web_user_id FROM web_user_branch ub WHERE TRUE " + userBranch + ")"
+		args = append(args, userBranchValues...)
+	}
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf(query, branch), args...).Rows()
 	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -657,45 +705,11 @@ func (r *Repo) FindRekapitulasi(date time.Time) (*transactiondomain.Rekapitulasi
 	var totalBelanja float64
 	var totalOperasional float64
 	for rows.Next() {
-		rows.Scan(&ID, &Name)
-
-		// find belanja
-		query = "SELECT SUM(b.quantity * b.price) FROM belanja b " +
-			"WHERE b.web_user_id = ? AND b.date = ?"
-		rows2, err := global.DBCON.Raw(query, ID.String, date).Rows()
-		if err != nil {
-			return nil, err
-		}
-		defer rows2.Close()
-
-		var belanjaVal sql.NullFloat64
-		for rows2.Next() {
-			rows2.Scan(&belanjaVal)
-		}
-
-		belanja := 0.0
-		if belanjaVal.Valid {
-			belanja = belanjaVal.Float64
-		}
-
-		// find operasional
-		query = "SELECT SUM(o.quantity * o.price) FROM operasional o " +
-			"WHERE o.web_user_id = ? AND o.date = ?"
-		rows3, err := global.DBCON.Raw(query, ID.String, date).Rows()
-		if err != nil {
-			return nil, err
-		}
-		defer rows3.Close()
-
-		var operasionalVal sql.NullFloat64
-		for rows3.Next() {
-			rows3.Scan(&operasionalVal)
-		}
-
-		operasional := 0.0
-		if operasionalVal.Valid {
-			operasional = operasionalVal.Float64
-		}
+		var ID sql.NullString
+		var Name sql.NullString
+		var belanja float64
+		var operasional float64
+		rows.Scan(&ID, &Name, &belanja, &operasional)
 
 		rekapitulasiList = append(rekapitulasiList, transactiondomain.RekapitulasiDetail{
 			Name:        Name.String,
@@ -0,0 +1,218 @@
+package transactionrepo
+
+import (
+	"context"
+	"database/sql"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	logutil "dromatech/pos-backend/internal/util/log"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+	"time"
+)
+
+// rekapMovementQuery lists every money movement of the mobile users in the range as one column per kind with its branch,
+// it takes the range once per source
+const rekapMovementQuery = "SELECT date, web_user_id, branch_id, quantity * price AS penjualan, 0 AS belanja, 0 AS operasional, 0 AS dana_tambahan, 0 AS dana_masuk, 0 AS dana_keluar " +
+	"FROM penjualan_tunai WHERE date BETWEEN ? AND ? " +
+	"UNION ALL SELECT date, web_user_id, branch_id, 0, quantity * price, 0, 0, 0, 0 FROM belanja WHERE date BETWEEN ? AND ? " +
+	"UNION ALL SELECT date, web_user_id, branch_id, 0, 0, quantity * price, 0, 0, 0 FROM operasional WHERE date BETWEEN ? AND ? " +
+	"UNION ALL SELECT date, web_user_id, branch_id, 0, 0, 0, dana_tambahan, 0, 0 FROM dana WHERE date BETWEEN ? AND ? " +
+	"UNION ALL SELECT date, receiver, branch_id, 0, 0, 0, 0, amount, 0 FROM dana_transaction WHERE date BETWEEN ? AND ? AND status = ? " +
+	"UNION ALL SELECT date, sender, branch_id, 0, 0, 0, 0, 0, amount FROM dana_transaction WHERE date BETWEEN ? AND ? AND status = ? "
+
+func rekapMovementArgs(startDate, endDate time.Time) []interface{} {
+	return []interface{}{
+		startDate, endDate,
+		startDate, endDate,
+		startDate, endDate,
+		startDate, endDate,
+		startDate, endDate, transactiondomain.DanaStatusApproved,
+		startDate, endDate, transactiondomain.DanaStatusApproved,
+	}
+}
+
+// branchAnd returns the restriction of column to the branch in ctx preceded by AND, empty when ctx reads every branch
+func branchAnd(ctx context.Context, column string) (string, []interface{}) {
+	condition, values := scopeutil.BranchCondition(ctx, column)
+	if condition == "" {
+		return "", nil
+	}
+	return "AND " + condition, values
+}
+
+// FindRekapPeriods sums the movements per bucket and user, bucket is a unit of date_trunc
+func (r *Repo) FindRekapPeriods(ctx context.Context, startDate, endDate time.Time, bucket string) ([]transactiondomain.RekapPeriod, error) {
+	query := "SELECT date_trunc(?, m.date)::date AS period, m.web_user_id, wu.name, SUM(m.penjualan), SUM(m.belanja), SUM(m.operasional), " +
+		"SUM(m.dana_tambahan), SUM(m.dana_masuk), SUM(m.dana_keluar) " +
+		"FROM (" + rekapMovementQuery + ") m JOIN web_user wu ON wu.id = m.web_user_id WHERE TRUE %s " +
+		"GROUP BY 1, m.web_user_id, wu.name ORDER BY 1, wu.name"
+	branch, branchValues := branchAnd(ctx, "m.branch_id")
+	query = fmt.Sprintf(query, branch)
+	args := append([]interface{}{bucket}, rekapMovementArgs(startDate, endDate)...)
+	args = append(args, branchValues...)
+	rows, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []transactiondomain.RekapPeriod{}
+	for rows.Next() {
+		period := transactiondomain.RekapPeriod{}
+		var Name sql.NullString
+		rows.Scan(&period.Period, &period.WebUserID, &Name, &period.Penjualan, &period.Belanja, &period.Operasional,
+			&period.DanaTambahan, &period.DanaMasuk, &period.DanaKeluar)
+		period.Name = Name.String
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// FindRekapBranches sums the movements per branch ignoring the branch in ctx, it is the consolidated rekap of head office
+func (r *Repo) FindRekapBranches(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.RekapBranch, error) {
+	query := "SELECT m.branch_id, br.code, br.name, SUM(m.penjualan), SUM(m.belanja), SUM(m.operasional), " +
+		"SUM(m.dana_tambahan), SUM(m.dana_masuk), SUM(m.dana_keluar) " +
+		"FROM (" + rekapMovementQuery + ") m LEFT JOIN branch br ON br.id = m.branch_id " +
+		"GROUP BY m.branch_id, br.code, br.name ORDER BY br.code NULLS FIRST"
+	rows, err := tenantutil.DB(ctx).Raw(query, rekapMovementArgs(startDate, endDate)...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := []transactiondomain.RekapBranch{}
+	for rows.Next() {
+		branch := transactiondomain.RekapBranch{}
+		var ID, Code, Name sql.NullString
+		rows.Scan(&ID, &Code, &Name, &branch.Penjualan, &branch.Belanja, &branch.Operasional,
+			&branch.DanaTambahan, &branch.DanaMasuk, &branch.DanaKeluar)
+		branch.BranchID = ID.String
+		branch.BranchCode = Code.String
+		branch.BranchName = Name.String
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// FindRekapProducts sums the belanja per user and product, the average price is weighted by quantity
+func (r *Repo) FindRekapProducts(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.RekapProduct, error) {
+	query := "SELECT b.web_user_id, wu.name, b.product_id, pr.code, pr.name, SUM(b.quantity), SUM(b.quantity * b.price) " +
+		"FROM belanja b JOIN web_user wu ON wu.id = b.web_user_id JOIN product pr ON pr.id = b.product_id " +
+		"WHERE b.date BETWEEN ? AND ? %s " +
+		"GROUP BY b.web_user_id, wu.name, b.product_id, pr.code, pr.name ORDER BY wu.name, pr.name"
+	branch, branchValues := branchAnd(ctx, "b.branch_id")
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf(query, branch), append([]interface{}{startDate, endDate}, branchValues...)...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []transactiondomain.RekapProduct{}
+	for rows.Next() {
+		product := transactiondomain.RekapProduct{}
+		var Name sql.NullString
+		var ProductCode sql.NullString
+		var ProductName sql.NullString
+		rows.Scan(&product.WebUserID, &Name, &product.ProductID, &ProductCode, &ProductName, &product.Quantity, &product.Total)
+		product.Name = Name.String
+		product.ProductCode = ProductCode.String
+		product.ProductName = ProductName.String
+		if product.Quantity != 0 {
+			product.AveragePrice = product.Total / float64(product.Quantity)
+		}
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+// FindRekapCategories sums the operasional per user and expense category
+func (r *Repo) FindRekapCategories(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.RekapCategory, error) {
+	query := "SELECT o.web_user_id, wu.name, o.category_id, ec.code, ec.name, COUNT(*), SUM(o.quantity * o.price) " +
+		"FROM operasional o JOIN web_user wu ON wu.id = o.web_user_id LEFT JOIN expense_category ec ON ec.id = o.category_id " +
+		"WHERE o.date BETWEEN ? AND ? %s " +
+		"GROUP BY o.web_user_id, wu.name, o.category_id, ec.code, ec.name ORDER BY wu.name, ec.name NULLS LAST"
+	branch, branchValues := branchAnd(ctx, "o.branch_id")
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf(query, branch), append([]interface{}{startDate, endDate}, branchValues...)...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []transactiondomain.RekapCategory{}
+	for rows.Next() {
+		category := transactiondomain.RekapCategory{}
+		var Name sql.NullString
+		var CategoryID sql.NullString
+		var CategoryCode sql.NullString
+		var CategoryName sql.NullString
+		rows.Scan(&category.WebUserID, &Name, &CategoryID, &CategoryCode, &CategoryName, &category.Count, &category.Total)
+		category.Name = Name.String
+		category.CategoryID = CategoryID.String
+		category.CategoryCode = CategoryCode.String
+		category.CategoryName = CategoryName.String
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+// FindReconciliation sums per date and product the ordered quantity of the sell transactions and the quantity and total
+// bought through belanja and transaction_buy, canceled transactions are left out
+func (r *Repo) FindReconciliation(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.ReconciliationItem, error) {
+	sellScope, sellScopeValues := scopeutil.Condition(ctx, "t.stakeholder_id")
+	if sellScope != "" {
+		sellScope = "AND " + sellScope
+	}
+	branch, branchValues := branchAnd(ctx, "t.branch_id")
+	sellScope += branch
+	sellScopeValues = append(sellScopeValues, branchValues...)
+	belanjaBranch, belanjaBranchValues := branchAnd(ctx, "branch_id")
+
+	query := "SELECT m.date, m.product_id, p.code, p.name, SUM(m.ordered), SUM(m.belanja_quantity), SUM(m.belanja_total), " +
+		"SUM(m.buy_quantity), SUM(m.buy_total) FROM (" +
+		"SELECT t.date::date AS date, td.product_id, td.quantity AS ordered, 0 AS belanja_quantity, 0 AS belanja_total, 0 AS buy_quantity, 0 AS buy_total " +
+		"FROM transaction t JOIN transaction_detail td ON td.transaction_id = t.id " +
+		"WHERE td.latest AND t.transaction_type = ? AND t.status <> ? AND t.date::date BETWEEN ? AND ? " + sellScope +
+		"UNION ALL SELECT date::date, product_id, 0, quantity, quantity * price, 0, 0 FROM belanja WHERE date::date BETWEEN ? AND ? " + belanjaBranch +
+		"UNION ALL SELECT t.date::date, tb.product_id, 0, 0, 0, tb.quantity, tb.quantity * tb.price " +
+		"FROM transaction_buy tb JOIN transaction t ON t.id = tb.transaction_id " +
+		"WHERE tb.latest AND t.status <> ? AND t.date::date BETWEEN ? AND ? " + sellScope +
+		") m JOIN product p ON p.id = m.product_id " +
+		"GROUP BY m.date, m.product_id, p.code, p.name ORDER BY m.date, p.name"
+
+	args := []interface{}{transactiondomain.TRANSACTION_TYPE_SELL, transactiondomain.TRANSACTION_BATAL, startDate, endDate}
+	args = append(args, sellScopeValues...)
+	args = append(args, startDate, endDate)
+	args = append(args, belanjaBranchValues...)
+	args = append(args, transactiondomain.TRANSACTION_BATAL, startDate, endDate)
+	args = append(args, sellScopeValues...)
+
+	rows, err := tenantutil.DB(ctx).Raw(query, args...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []transactiondomain.ReconciliationItem{}
+	for rows.Next() {
+		item := transactiondomain.ReconciliationItem{}
+		var ProductCode sql.NullString
+		var ProductName sql.NullString
+		rows.Scan(&item.Date, &item.ProductID, &ProductCode, &ProductName, &item.OrderedQuantity, &item.BelanjaQuantity, &item.BelanjaTotal,
+			&item.TransactionBuyQuantity, &item.TransactionBuyTotal)
+		item.ProductCode = ProductCode.String
+		item.ProductName = ProductName.String
+		item.PurchasedQuantity = item.BelanjaQuantity + item.TransactionBuyQuantity
+		item.Difference = item.PurchasedQuantity - item.OrderedQuantity
+		if item.PurchasedQuantity != 0 {
+			item.AverageBuyPrice = (item.BelanjaTotal + item.TransactionBuyTotal) / item.PurchasedQuantity
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
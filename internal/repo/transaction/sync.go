@@ -0,0 +1,340 @@
+package transactionrepo
+
+import (
+	"context"
+	"database/sql"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	logutil "dromatech/pos-backend/internal/util/log"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	stringutil "dromatech/pos-backend/internal/util/string"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// logChange adds the change of a record to the change feed of the user, in the transaction of the change
+func logChange(tx *gorm.DB, userID, entity, id, action string) error {
+	return tx.Exec("INSERT INTO mobile_change(web_user_id, entity, entity_id, action, changed_time) VALUES (?, ?, ?, ?, ?);",
+		userID, entity, id, action, time.Now()).Error
+}
+
+// logTransferChange adds the change of a transfer to the feed of both the sender and the receiver
+func logTransferChange(tx *gorm.DB, id string) error {
+	return tx.Exec("INSERT INTO mobile_change(web_user_id, entity, entity_id, action, changed_time) "+
+		"SELECT u.id, ?, dt.id, ?, ? FROM dana_transaction dt CROSS JOIN LATERAL (VALUES (dt.sender), (dt.receiver)) AS u(id) "+
+		"WHERE dt.id = ?;", transactiondomain.SyncEntityDanaTransfer, transactiondomain.SyncActionUpsert, time.Now(), id).Error
+}
+
+// createdTime is the client time of a synced record, the server time otherwise
+func createdTime(clientTime *time.Time) time.Time {
+	if clientTime != nil && !clientTime.IsZero() {
+		return *clientTime
+	}
+	return time.Now()
+}
+
+func updateDanaStatus(ctx context.Context, id string, status transactiondomain.DanaStatus) error {
+	tx := tenantutil.DB(ctx).Begin()
+	err := tx.Exec("UPDATE dana_transaction SET status = ? WHERE id = ?;", status, id).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := logTransferChange(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// decideDanaStatus approves or rejects a pending transfer, the status check in the update keeps a transfer the sweeper
+// expired or another user decided meanwhile as it is
+func decideDanaStatus(ctx context.Context, id string, status transactiondomain.DanaStatus, decidedBy string) (bool, error) {
+	tx := tenantutil.DB(ctx).Begin()
+	result := tx.Exec("UPDATE dana_transaction SET status = ?, decided_by = ? WHERE id = ? AND status = ?;",
+		status, decidedBy, id, transactiondomain.DanaStatusPending)
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := logTransferChange(tx, id); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	return true, tx.Commit().Error
+}
+
+// createMobileEntry inserts a penjualan, belanja or operasional, the query takes id, date, web_user_id, the fields, created_time and branch_id
+func createMobileEntry(ctx context.Context, userID, entity, query, id, date string, clientTime *time.Time, fields ...interface{}) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := insertMobileEntry(ctx, tx, userID, entity, query, id, date, clientTime, fields...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// insertMobileEntry inserts a penjualan, belanja or operasional and logs it to the feed of the user in tx
+func insertMobileEntry(ctx context.Context, tx *gorm.DB, userID, entity, query, id, date string, clientTime *time.Time, fields ...interface{}) error {
+	if id == "" {
+		id = stringutil.GenerateUUID()
+	}
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return err
+	}
+
+	values := append([]interface{}{id, parsed, userID}, fields...)
+	values = append(values, createdTime(clientTime), scopeutil.Branch(ctx))
+
+	if err := tx.Exec(query, values...).Error; err != nil {
+		return err
+	}
+	return logChange(tx, userID, entity, id, transactiondomain.SyncActionUpsert)
+}
+
+// deleteMobileEntry removes a penjualan, belanja or operasional with its attachments and logs the delete to the feed of its owner,
+// it returns the storage keys of the attachment files for the caller to remove once the delete is committed
+func deleteMobileEntry(ctx context.Context, table, entity, id string) ([]string, error) {
+	if !mobileEntryTables[table] {
+		return nil, nil
+	}
+
+	tx := tenantutil.DB(ctx).Begin()
+	err := tx.Exec("INSERT INTO mobile_change(web_user_id, entity, entity_id, action, changed_time) "+
+		"SELECT web_user_id, ?, id, ?, ? FROM "+table+" WHERE id = ?;",
+		entity, transactiondomain.SyncActionDelete, time.Now(), id).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// the owner types of the attachments are the entity names
+	rows, err := tx.Raw("DELETE FROM attachment WHERE owner_type = ? AND owner_id = ? RETURNING storage_key, thumbnail_key;", entity, id).Rows()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var keys []string
+	for rows.Next() {
+		var StorageKey sql.NullString
+		var ThumbnailKey sql.NullString
+		rows.Scan(&StorageKey, &ThumbnailKey)
+		for _, key := range []sql.NullString{StorageKey, ThumbnailKey} {
+			if key.String != "" {
+				keys = append(keys, key.String)
+			}
+		}
+	}
+	rows.Close()
+
+	if err := tx.Exec("DELETE FROM "+table+" WHERE id = ?;", id).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// FindDanaByDate returns the dana of the user on the date, nil when the date has no dana yet
+func (r *Repo) FindDanaByDate(ctx context.Context, userID string, date time.Time) (*transactiondomain.Dana, error) {
+	branch, branchValues := branchAnd(ctx, "branch_id")
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, date, web_user_id, saldo_awal, dana_tambahan, created_time FROM dana "+
+		"WHERE web_user_id = ? AND date = ? "+branch+"ORDER BY created_time LIMIT 1", append([]interface{}{userID, date}, branchValues...)...).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+	return scanDana(rows), nil
+}
+
+// FindDanaTransfer returns a transfer in any status, nil when it does not exist
+func (r *Repo) FindDanaTransfer(ctx context.Context, id string) (*transactiondomain.DanaTransaction, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, date, sender, receiver, amount, status, created_time, inserted_time FROM dana_transaction WHERE id = ?", id).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+	return scanDanaTransfer(rows), nil
+}
+
+// FindChanges returns the changes of the user after the cursor in the order their transactions committed, the changes of a
+// transaction that may still be running are left for a later read
+func (r *Repo) FindChanges(ctx context.Context, userID string, cursor transactiondomain.SyncCursor, limit int) ([]transactiondomain.SyncChange, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT seq, tx_id, entity, entity_id, action, changed_time FROM mobile_change "+
+		"WHERE web_user_id = ? AND (tx_id, seq) > (?, ?) AND tx_id < pg_snapshot_xmin(pg_current_snapshot())::TEXT::BIGINT "+
+		"ORDER BY tx_id, seq LIMIT ?", userID, cursor.TxID, cursor.Seq, limit).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []transactiondomain.SyncChange{}
+	for rows.Next() {
+		change := transactiondomain.SyncChange{}
+		rows.Scan(&change.Seq, &change.TxID, &change.Entity, &change.ID, &change.Action, &change.ChangedTime)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// FindSyncRecords returns the current records of an entity of the change feed by id, a deleted record is missing from the map
+func (r *Repo) FindSyncRecords(ctx context.Context, entity string, ids []string) (map[string]interface{}, error) {
+	records := make(map[string]interface{})
+	if len(ids) == 0 {
+		return records, nil
+	}
+
+	var query string
+	var scan func(rows *sql.Rows) (string, interface{})
+	switch entity {
+	case transactiondomain.SyncEntityDana:
+		query = "SELECT id, date, web_user_id, saldo_awal, dana_tambahan, created_time FROM dana WHERE id IN ?"
+		scan = func(rows *sql.Rows) (string, interface{}) {
+			dana := scanDana(rows)
+			return dana.ID, dana
+		}
+	case transactiondomain.SyncEntityDanaTransfer:
+		query = "SELECT id, date, sender, receiver, amount, status, created_time, inserted_time FROM dana_transaction WHERE id IN ?"
+		scan = func(rows *sql.Rows) (string, interface{}) {
+			transfer := scanDanaTransfer(rows)
+			return transfer.ID, transfer
+		}
+	case transactiondomain.SyncEntityPenjualan, transactiondomain.SyncEntityBelanja:
+		table := transactiondomain.MobileEntryBelanja
+		if entity == transactiondomain.SyncEntityPenjualan {
+			table = transactiondomain.MobileEntryPenjualan
+		}
+		query = "SELECT t.id, t.date, t.web_user_id, t.product_id, pr.code, pr.name, t.quantity, t.price, t.created_time " +
+			"FROM " + table + " t JOIN product pr ON pr.id = t.product_id WHERE t.id IN ?"
+		scan = func(rows *sql.Rows) (string, interface{}) {
+			belanja := scanBelanja(rows)
+			if entity == transactiondomain.SyncEntityPenjualan {
+				return belanja.ID, &transactiondomain.PenjualanTunai{ID: belanja.ID, Date: belanja.Date, WebUserID: belanja.WebUserID,
+					ProductID: belanja.ProductID, ProductCode: belanja.ProductCode, ProductName: belanja.ProductName,
+					Quantity: belanja.Quantity, Price: belanja.Price, CreatedTime: belanja.CreatedTime}
+			}
+			return belanja.ID, belanja
+		}
+	case transactiondomain.SyncEntityOperasional:
+		query = "SELECT id, date, web_user_id, category_id, description, quantity, price, created_time FROM operasional WHERE id IN ?"
+		scan = func(rows *sql.Rows) (string, interface{}) {
+			operasional := &transactiondomain.Operasional{}
+			var CategoryID sql.NullString
+			var Description sql.NullString
+			rows.Scan(&operasional.ID, &operasional.Date, &operasional.WebUserID, &CategoryID, &Description, &operasional.Quantity, &operasional.Price, &operasional.CreatedTime)
+			operasional.CategoryID = CategoryID.String
+			operasional.Description = Description.String
+			return operasional.ID, operasional
+		}
+	case transactiondomain.SyncEntityClosing:
+		query = selectClosingQuery + "WHERE c.id IN ?"
+		scan = func(rows *sql.Rows) (string, interface{}) {
+			closing := scanClosing(rows)
+			return closing.ID, closing
+		}
+	default:
+		return records, nil
+	}
+
+	rows, err := tenantutil.DB(ctx).Raw(query, ids).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		id, record := scan(rows)
+		records[id] = record
+	}
+	return records, nil
+}
+
+func scanDana(rows *sql.Rows) *transactiondomain.Dana {
+	var ID sql.NullString
+	var Date sql.NullTime
+	var WebUserID sql.NullString
+	var SaldoAwal sql.NullFloat64
+	var DanaTambahan sql.NullFloat64
+	var CreatedTime sql.NullTime
+	rows.Scan(&ID, &Date, &WebUserID, &SaldoAwal, &DanaTambahan, &CreatedTime)
+
+	return &transactiondomain.Dana{
+		ID:           ID.String,
+		Date:         Date.Time,
+		WebUserID:    WebUserID.String,
+		SaldoAwal:    SaldoAwal.Float64,
+		DanaTambahan: DanaTambahan.Float64,
+		CreatedTime:  CreatedTime.Time,
+	}
+}
+
+func scanDanaTransfer(rows *sql.Rows) *transactiondomain.DanaTransaction {
+	var ID sql.NullString
+	var Date sql.NullTime
+	var Sender sql.NullString
+	var Receiver sql.NullString
+	var Amount sql.NullFloat64
+	var Status sql.NullString
+	var CreatedTime sql.NullTime
+	var InsertedTime sql.NullTime
+	rows.Scan(&ID, &Date, &Sender, &Receiver, &Amount, &Status, &CreatedTime, &InsertedTime)
+
+	return &transactiondomain.DanaTransaction{
+		ID:           ID.String,
+		Date:         Date.Time,
+		Sender:       Sender.String,
+		Receiver:     Receiver.String,
+		Amount:       Amount.Float64,
+		Status:       transactiondomain.DanaStatus(Status.String),
+		CreatedTime:  CreatedTime.Time,
+		InsertedTime: InsertedTime.Time,
+	}
+}
+
+func scanBelanja(rows *sql.Rows) *transactiondomain.Belanja {
+	var ID sql.NullString
+	var Date sql.NullTime
+	var WebUserID sql.NullString
+	var ProductID sql.NullString
+	var ProductCode sql.NullString
+	var ProductName sql.NullString
+	var Quantity sql.NullInt16
+	var Price sql.NullFloat64
+	var CreatedTime sql.NullTime
+	rows.Scan(&ID, &Date, &WebUserID, &ProductID, &ProductCode, &ProductName, &Quantity, &Price, &CreatedTime)
+
+	return &transactiondomain.Belanja{
+		ID:          ID.String,
+		Date:        Date.Time,
+		WebUserID:   WebUserID.String,
+		ProductID:   ProductID.String,
+		ProductCode: ProductCode.String,
+		ProductName: ProductName.String,
+		Quantity:    Quantity.Int16,
+		Price:       Price.Float64,
+		CreatedTime: CreatedTime.Time,
+	}
+}
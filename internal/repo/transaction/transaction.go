@@ -1,69 +1,106 @@
 package transactionrepo
 
 import (
+	"context"
 	"database/sql"
+	logutil "dromatech/pos-backend/internal/util/log"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
 	"time"
 
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
-	"dromatech/pos-backend/global"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
 	dateutil "dromatech/pos-backend/internal/util/date"
 	queryutil "dromatech/pos-backend/internal/util/query"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
 	stringutil "dromatech/pos-backend/internal/util/string"
 )
 
 type TransactionRepo interface {
-	Find(params []queryutil.Param) ([]*transactiondomain.Transaction, error)
-	Create(entity *transactiondomain.Transaction, tx *gorm.DB)
-	Edit(product *transactiondomain.Transaction) error
-	UpdateStatus(transactionID, status string) error
-	UpdatePrice(transactionID, productID string, buyPrice, sellPrice float64, quantity, buyQuantity int64) error
-	FindSells(params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error)
-	UpdateTransaction(entity *transactiondomain.Transaction, tx *gorm.DB)
-	FindReport(params []queryutil.Param) ([]*transactiondomain.ReportDate, error)
-	UpdateHargaBeli(transactionDetailID string, buyPrice int64, webUserID string) error
-	InsertTransactionBuy(transactionId string, transactionBuy []transactiondomain.TransactionBuy) error
-	FindTransactionBuyStatus() ([]transactiondomain.TransactionBuyStatus, error)
-	FindDetails(params []queryutil.Param) ([]*transactiondomain.TransactionDetail, error)
-	UpdateHargaBeliTx(transactionDetailID string, buyPrice float64, webUserID string, tx *gorm.DB) error
-	FindLastCreditPerMonth(params []queryutil.Param) (map[string]map[int]float64, error)
-	FindLastCredit(params []queryutil.Param) (map[string]float64, error)
-	FindCustomerReport(stakeHolderID string, month time.Time) ([]*transactiondomain.LaporanCustomer, int, error)
+	Find(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.Transaction, error)
+	Create(ctx context.Context, entity *transactiondomain.Transaction, tx *gorm.DB)
+	Edit(ctx context.Context, product *transactiondomain.Transaction) error
+	UpdateStatus(ctx context.Context, transactionID, status string) error
+	FindSells(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error)
+	FindSellsList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*transactiondomain.TransactionStatus, int64, error)
+	UpdateTransaction(ctx context.Context, entity *transactiondomain.Transaction, tx *gorm.DB)
+	FindReport(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.ReportDate, error)
+	FindTaxSetting(ctx context.Context, customerID string, productIDs []string) (*transactiondomain.TaxSetting, error)
+	FindEFaktur(ctx context.Context, startDate, endDate time.Time) ([]*transactiondomain.EFaktur, error)
+	FindTaxInvoicePending(ctx context.Context, startDate, endDate time.Time, tx *gorm.DB) ([]string, error)
+	UpdateTaxInvoiceNumber(ctx context.Context, transactionID, number string, tx *gorm.DB) (string, error)
+	UpdateHargaBeli(ctx context.Context, transactionDetailID string, buyPrice int64, webUserID string) error
+	InsertTransactionBuy(ctx context.Context, transactionId string, transactionBuy []transactiondomain.TransactionBuy) error
+	FindTransactionBuyStatus(ctx context.Context) ([]transactiondomain.TransactionBuyStatus, error)
+	FindDetails(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.TransactionDetail, error)
+	UpdateHargaBeliTx(ctx context.Context, transactionDetailID string, buyPrice float64, webUserID string, tx *gorm.DB) error
+	FindLastCreditPerMonth(ctx context.Context, params []queryutil.Param) (map[string]map[int]float64, error)
+	FindLastCredit(ctx context.Context, params []queryutil.Param) (map[string]float64, error)
+	FindCustomerReport(ctx context.Context, stakeHolderID string, month time.Time) ([]*transactiondomain.LaporanCustomer, int, error)
 
 	// mobile
-	FindDana(userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error)
-	FindDanaByID(id string) (*transactiondomain.Dana, error)
-	CreateDana(userID string, request transactiondomain.DanaRequest) error
-	UpdateDana(userID string, request transactiondomain.DanaRequest) error
-	SendDana(userID string, request transactiondomain.DanaTransactionRequest) error
-	FindDanaTransaction(id string) (*transactiondomain.DanaTransaction, error)
-	ApproveDana(id string) error
-	RejectDana(id string) error
-	CancelSendDana(id string) error
-	CheckUserMobilePermission(id string) (bool, error)
-	FindUserMobile(id string) ([]transactiondomain.WebUserMobile, error)
+	FindDana(ctx context.Context, userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error)
+	FindDanaByID(ctx context.Context, id string) (*transactiondomain.Dana, error)
+	CreateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error
+	UpdateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error
+	SendDana(ctx context.Context, userID string, request transactiondomain.DanaTransactionRequest) error
+	FindDanaTransaction(ctx context.Context, id string) (*transactiondomain.DanaTransaction, error)
+	ApproveDana(ctx context.Context, id, decidedBy string) (bool, error)
+	RejectDana(ctx context.Context, id, decidedBy string) (bool, error)
+	ExpireDanaTransfers(ctx context.Context, before time.Time) ([]string, error)
+	IsSupervisorOf(ctx context.Context, supervisorID string, userIDs ...string) (bool, error)
+	FindSupervisedTransfers(ctx context.Context, supervisorID string, status transactiondomain.DanaStatus) ([]transactiondomain.SupervisedDanaTransfer, error)
+	CancelSendDana(ctx context.Context, id string) error
+	CheckUserMobilePermission(ctx context.Context, id string) (bool, error)
+	FindUserMobile(ctx context.Context, id string) ([]transactiondomain.WebUserMobile, error)
 
 	// penjualan tunai
-	FindPenjualan(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
-	CreatePenjualan(userID string, request transactiondomain.TrxCreateRequest) error
-	DeletePenjualan(id string) error
+	FindPenjualan(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
+	CreatePenjualan(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error
+	DeletePenjualan(ctx context.Context, id string) ([]string, error)
 
 	// belanja
-	FindBelanja(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
-	CreateBelanja(userID string, request transactiondomain.TrxCreateRequest) error
-	DeleteBelanja(id string) error
+	FindBelanja(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
+	CreateBelanja(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error
+	DeleteBelanja(ctx context.Context, id string) ([]string, error)
 
 	// operasional
-	FindOperasional(userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error)
-	CreateOperasional(userID string, request transactiondomain.TrxCreateOperasionalRequest) error
-	DeleteOperasional(id string) error
+	FindOperasional(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error)
+	CreateOperasional(ctx context.Context, userID string, request transactiondomain.TrxCreateOperasionalRequest, tx *gorm.DB) error
+	SumOperasional(ctx context.Context, userID, categoryID string, startDate, endDate time.Time, tx *gorm.DB) (float64, error)
+	DeleteOperasional(ctx context.Context, id string) ([]string, error)
 
 	// saldo
-	FindSaldo(userID string, date time.Time) (*transactiondomain.SaldoResponse, error)
-	FindRekapitulasi(date time.Time) (*transactiondomain.RekapitulasiResponse, error)
+	FindSaldo(ctx context.Context, userID string, date time.Time) (*transactiondomain.SaldoResponse, error)
+	FindRekapitulasi(ctx context.Context, date time.Time) (*transactiondomain.RekapitulasiResponse, error)
+	FindRekapPeriods(ctx context.Context, startDate, endDate time.Time, bucket string) ([]transactiondomain.RekapPeriod, error)
+	FindRekapProducts(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.RekapProduct, error)
+	FindRekapCategories(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.RekapCategory, error)
+	FindRekapBranches(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.RekapBranch, error)
+	FindReconciliation(ctx context.Context, startDate, endDate time.Time) ([]transactiondomain.ReconciliationItem, error)
+
+	// closing
+	FindClosing(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error)
+	FindClosingList(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosing, error)
+	CreateClosing(ctx context.Context, closing *transactiondomain.CashClosing, tx *gorm.DB) error
+	UpdateSaldoAwal(ctx context.Context, userID, branchID string, date time.Time, saldoAwal float64, tx *gorm.DB) error
+	ReopenClosing(ctx context.Context, reopen *transactiondomain.CashClosingReopen) error
+	FindReopenHistory(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosingReopen, error)
+	FindMobileEntry(ctx context.Context, table string, id string) (*transactiondomain.MobileEntry, error)
+
+	// sync
+	FindDanaByDate(ctx context.Context, userID string, date time.Time) (*transactiondomain.Dana, error)
+	FindDanaTransfer(ctx context.Context, id string) (*transactiondomain.DanaTransaction, error)
+	FindChanges(ctx context.Context, userID string, cursor transactiondomain.SyncCursor, limit int) ([]transactiondomain.SyncChange, error)
+	FindSyncRecords(ctx context.Context, entity string, ids []string) (map[string]interface{}, error)
+}
+
+// sellSortColumns maps the sort fields of the transaction list to columns, only transaction columns since the list is grouped by transaction
+var sellSortColumns = map[string]string{
+	"code":   "t.code",
+	"date":   "t.date",
+	"status": "t.status",
 }
 
 type Repo struct {
@@ -74,7 +111,7 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params []queryutil.Param) ([]*transactiondomain.Transaction, error) {
+func (r *Repo) Find(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.Transaction, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -92,8 +129,10 @@ func (r *Repo) Find(params []queryutil.Param) ([]*transactiondomain.Transaction,
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.stakeholder_id, t.transaction_type, t.status, "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.stakeholder_id, t.transaction_type, t.status, "+
 		"u.unit_id, td.product_id, td.buy_price, td.sell_price, td.quantity, td.buy_quantity "+
 		"FROM transaction t "+
 		"JOIN transaction_detail td ON (td.transaction_id = t.id) "+
@@ -101,7 +140,7 @@ func (r *Repo) Find(params []queryutil.Param) ([]*transactiondomain.Transaction,
 		"JOIN unit u ON (u.id = p.unit_id) "+
 		"%s ORDER BY t.date DESC", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -160,38 +199,62 @@ func (r *Repo) Find(params []queryutil.Param) ([]*transactiondomain.Transaction,
 	return entities, nil
 }
 
-func (r *Repo) FindSells(params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error) {
-	where := ""
-	var values []interface{}
-	for _, param := range params {
-		if where != "" {
-			logic := "AND "
-			if param.Logic != "" {
-				logic = param.Logic + " "
-			}
-			where += logic
+func (r *Repo) FindSells(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.TransactionStatus, error) {
+	entities, _, err := r.FindSellsList(ctx, params, queryutil.ListParam{})
+	return entities, err
+}
+
+// FindSellsList pages on transactions rather than on detail rows, so the ids of the page are selected first
+func (r *Repo) FindSellsList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*transactiondomain.TransactionStatus, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "t.code", "c.code", "c.name")
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
+
+	from := "FROM transaction t " +
+		"JOIN transaction_detail td ON (td.transaction_id = t.id) " +
+		"JOIN customer c ON (c.id = t.stakeholder_id) " +
+		"JOIN web_user w ON (w.id = t.web_user_id) " +
+		"JOIN product p ON (p.id = td.product_id) " +
+		"JOIN unit u ON (u.id = p.unit_id) "
+	orderBy := list.OrderBy(sellSortColumns, "t.date DESC")
+
+	var total int64
+	if list.Paged() {
+		err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(DISTINCT t.id) %s %s", from, where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
 		}
-		where += param.Field + " " + param.Operator + " ? "
-		values = append(values, param.Value)
-	}
 
-	if where != "" {
-		where = "WHERE " + where
+		idRows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT t.id %s %s GROUP BY t.id %s %s", from, where, orderBy, list.LimitOffset()), values...).Rows()
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+		var ids []string
+		for idRows.Next() {
+			var id string
+			idRows.Scan(&id)
+			ids = append(ids, id)
+		}
+		idRows.Close()
+
+		if len(ids) == 0 {
+			return []*transactiondomain.TransactionStatus{}, total, nil
+		}
+		where, values = queryutil.Where(append(params, queryutil.Param{Logic: "AND", Field: "t.id", Operator: "IN", Value: ids}))
 	}
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.stakeholder_id, c.code, c.name, t.transaction_type, t.status, "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT t.id, t.code, t.date, t.stakeholder_id, c.code, c.name, t.transaction_type, t.status, "+
 		"t.reference_code, t.web_user_id, w.name, t.created_time, "+
-		"u.id, u.code, td.product_id, p.code, p.name, td.buy_price, td.sell_price, td.quantity, td.buy_quantity "+
-		"FROM transaction t "+
-		"JOIN transaction_detail td ON (td.transaction_id = t.id) "+
-		"JOIN customer c ON (c.id = t.stakeholder_id) "+
-		"JOIN web_user w ON (w.id = t.web_user_id) "+
-		"JOIN product p ON (p.id = td.product_id) "+
-		"JOIN unit u ON (u.id = p.unit_id) "+
-		"%s ORDER BY t.date DESC, td.sorting_val ASC", where), values...).Rows()
+		"t.discount_type, t.discount_value, t.tax_inclusive, t.subtotal, t.discount, t.dpp, t.ppn, t.grand_total, "+
+		"u.id, u.code, td.product_id, p.code, p.name, td.buy_price, td.sell_price, td.quantity, td.buy_quantity, "+
+		"td.discount_type, td.discount_value, td.discount_amount, td.tax_rate, td.dpp, td.ppn "+
+		"%s %s %s, td.sorting_val ASC", from, where, orderBy), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -210,6 +273,14 @@ func (r *Repo) FindSells(params []queryutil.Param) ([]*transactiondomain.Transac
 		var UserId sql.NullString
 		var UserName sql.NullString
 		var CreatedTime time.Time
+		var DiscountType sql.NullString
+		var DiscountValue sql.NullFloat64
+		var TaxInclusive sql.NullBool
+		var Subtotal sql.NullFloat64
+		var Discount sql.NullFloat64
+		var Dpp sql.NullFloat64
+		var Ppn sql.NullFloat64
+		var GrandTotal sql.NullFloat64
 		var UnitID sql.NullString
 		var UnitCode sql.NullString
 		var ProductID sql.NullString
@@ -219,18 +290,25 @@ func (r *Repo) FindSells(params []queryutil.Param) ([]*transactiondomain.Transac
 		var SellPrice sql.NullFloat64
 		var Quantity sql.NullFloat64
 		var BuyQuantity sql.NullFloat64
+		var DetailDiscountType sql.NullString
+		var DetailDiscountValue sql.NullFloat64
+		var DiscountAmount sql.NullFloat64
+		var TaxRate sql.NullFloat64
+		var DetailDpp sql.NullFloat64
+		var DetailPpn sql.NullFloat64
 
 		rows.Scan(&ID, &Code, &Date, &StakeholderID, &CustomerCode, &CustomerName, &TransactionType, &Status, &ReferenceCode,
-			&UserId, &UserName, &CreatedTime, &UnitID, &UnitCode, &ProductID, &ProductCode, &ProductName, &BuyPrice, &SellPrice, &Quantity, &BuyQuantity)
+			&UserId, &UserName, &CreatedTime, &DiscountType, &DiscountValue, &TaxInclusive, &Subtotal, &Discount, &Dpp, &Ppn, &GrandTotal,
+			&UnitID, &UnitCode, &ProductID, &ProductCode, &ProductName, &BuyPrice, &SellPrice, &Quantity, &BuyQuantity,
+			&DetailDiscountType, &DetailDiscountValue, &DiscountAmount, &TaxRate, &DetailDpp, &DetailPpn)
 
 		var entity *transactiondomain.TransactionStatus
 		if !ID.Valid && ID.String == "" {
-			return nil, nil
+			return nil, 0, nil
 		}
 
 		if value, ok := entityMap[ID.String]; ok {
 			entity = value
-			entity.Total = entity.Total + ((SellPrice.Float64) * Quantity.Float64)
 		} else {
 			entity = &transactiondomain.TransactionStatus{}
 			entity.ID = ID.String
@@ -245,7 +323,15 @@ func (r *Repo) FindSells(params []queryutil.Param) ([]*transactiondomain.Transac
 			entity.UserId = UserId.String
 			entity.UserName = UserName.String
 			entity.CreatedTime = CreatedTime.Format(dateutil.TimeFormatResponse())
-			entity.Total = (SellPrice.Float64) * Quantity.Float64
+			entity.DiscountType = DiscountType.String
+			entity.DiscountValue = DiscountValue.Float64
+			entity.TaxInclusive = TaxInclusive.Bool
+			entity.Subtotal = Subtotal.Float64
+			entity.Discount = Discount.Float64
+			entity.Dpp = Dpp.Float64
+			entity.Ppn = Ppn.Float64
+			entity.GrandTotal = GrandTotal.Float64
+			entity.Total = GrandTotal.Float64
 
 			entities = append(entities, entity)
 			entityMap[ID.String] = entity
@@ -262,18 +348,30 @@ func (r *Repo) FindSells(params []queryutil.Param) ([]*transactiondomain.Transac
 		detail.SellPrice = SellPrice.Float64
 		detail.Quantity = Quantity.Float64
 		detail.BuyQuantity = BuyQuantity.Float64
+		detail.DiscountType = DetailDiscountType.String
+		detail.DiscountValue = DetailDiscountValue.Float64
+		detail.DiscountAmount = DiscountAmount.Float64
+		detail.TaxRate = TaxRate.Float64
+		detail.Dpp = DetailDpp.Float64
+		detail.Ppn = DetailPpn.Float64
 
 		entity.TransactionDetail = append(entity.TransactionDetail, detail)
 
 	}
 
-	return entities, nil
+	if !list.Paged() {
+		total = int64(len(entities))
+	}
+
+	return entities, total, nil
 }
 
-func (r *Repo) Create(entity *transactiondomain.Transaction, tx *gorm.DB) {
-	tx.Exec("INSERT INTO public.transaction(id, code, date, stakeholder_id, transaction_type, status, reference_code, web_user_id, created_time) "+
-		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);",
-		entity.ID, entity.Code, entity.Date, entity.StakeholderID, entity.TransactionType, entity.Status, entity.ReferenceCode, entity.UserId, entity.CreatedTime)
+func (r *Repo) Create(ctx context.Context, entity *transactiondomain.Transaction, tx *gorm.DB) {
+	tx.Exec("INSERT INTO transaction(id, code, date, stakeholder_id, transaction_type, status, reference_code, web_user_id, created_time, branch_id, "+
+		"discount_type, discount_value, tax_inclusive, subtotal, discount, dpp, ppn, grand_total) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		entity.ID, entity.Code, entity.Date, entity.StakeholderID, entity.TransactionType, entity.Status, entity.ReferenceCode, entity.UserId, entity.CreatedTime,
+		scopeutil.Branch(ctx), entity.DiscountType, entity.DiscountValue, entity.TaxInclusive, entity.Subtotal, entity.Discount, entity.Dpp, entity.Ppn, entity.GrandTotal)
 
 	if tx.Error != nil {
 		return
@@ -284,9 +382,11 @@ func (r *Repo) Create(entity *transactiondomain.Transaction, tx *gorm.DB) {
 		detail.BuyPrice = 0
 		txDetailId := stringutil.GenerateUUID()
 		detail.ID = txDetailId
-		tx.Exec("INSERT INTO public.transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val) "+
-			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
-			txDetailId, entity.ID, detail.ProductID, detail.BuyPrice, detail.SellPrice, detail.Quantity, entity.CreatedTime, entity.UserId, true, detail.Quantity, i)
+		tx.Exec("INSERT INTO transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val, "+
+			"discount_type, discount_value, discount_amount, tax_rate, dpp, ppn) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+			txDetailId, entity.ID, detail.ProductID, detail.BuyPrice, detail.SellPrice, detail.Quantity, entity.CreatedTime, entity.UserId, true, detail.Quantity, i,
+			detail.DiscountType, detail.DiscountValue, detail.DiscountAmount, detail.TaxRate, detail.Dpp, detail.Ppn)
 
 		if tx.Error != nil {
 			return
@@ -295,10 +395,10 @@ func (r *Repo) Create(entity *transactiondomain.Transaction, tx *gorm.DB) {
 
 }
 
-func (r *Repo) Edit(entity *transactiondomain.Transaction) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) Edit(ctx context.Context, entity *transactiondomain.Transaction) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.transaction "+
+	tx.Exec("UPDATE transaction "+
 		"SET status=? WHERE id=?;", entity.Status, entity.ID)
 
 	if tx.Error != nil {
@@ -306,7 +406,7 @@ func (r *Repo) Edit(entity *transactiondomain.Transaction) error {
 	}
 
 	for _, detail := range entity.TransactionDetail {
-		tx.Exec("UPDATE public.transaction_detail "+
+		tx.Exec("UPDATE transaction_detail "+
 			"SET buy_price=?, sell_price=?, quantity=?, buy_quantity=? WHERE transaction_id=?, product_id=?;",
 			detail.BuyPrice, detail.SellPrice, detail.Quantity, detail.BuyQuantity, detail.TransactionID, detail.ProductID)
 
@@ -320,19 +420,20 @@ func (r *Repo) Edit(entity *transactiondomain.Transaction) error {
 	return tx.Error
 }
 
-func (r *Repo) UpdateStatus(transactionID, status string) error {
-	return global.DBCON.Exec("UPDATE public.transaction "+
+func (r *Repo) UpdateStatus(ctx context.Context, transactionID, status string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE transaction "+
 		"SET status=? WHERE id=?;", status, transactionID).Error
 }
 
-func (r *Repo) UpdatePrice(transactionID, productID string, buyPrice, sellPrice float64, quantity, buyQuantity int64) error {
-	return global.DBCON.Exec("UPDATE public.transaction_detail "+
-		"SET buy_price=?, sell_price=?, quantity=?, buy_quantity=? WHERE transaction_id=?, product_id=?;",
-		buyPrice, sellPrice, quantity, buyQuantity, transactionID, productID).Error
-}
+func (r *Repo) UpdateTransaction(ctx context.Context, entity *transactiondomain.Transaction, tx *gorm.DB) {
+	tx.Exec("UPDATE transaction SET discount_type=?, discount_value=?, tax_inclusive=?, subtotal=?, discount=?, dpp=?, ppn=?, grand_total=? WHERE id=?;",
+		entity.DiscountType, entity.DiscountValue, entity.TaxInclusive, entity.Subtotal, entity.Discount, entity.Dpp, entity.Ppn, entity.GrandTotal, entity.ID)
+
+	if tx.Error != nil {
+		return
+	}
 
-func (r *Repo) UpdateTransaction(entity *transactiondomain.Transaction, tx *gorm.DB) {
-	tx.Exec("UPDATE public.transaction_detail SET latest=? WHERE transaction_id=?;", false, entity.ID)
+	tx.Exec("UPDATE transaction_detail SET latest=? WHERE transaction_id=?;", false, entity.ID)
 
 	if tx.Error != nil {
 		return
@@ -341,9 +442,11 @@ func (r *Repo) UpdateTransaction(entity *transactiondomain.Transaction, tx *gorm
 	for i, detail := range entity.TransactionDetail {
 		txDetailId := stringutil.GenerateUUID()
 		detail.ID = txDetailId
-		tx.Exec("INSERT INTO public.transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val) "+
-			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
-			txDetailId, entity.ID, detail.ProductID, detail.BuyPrice, detail.SellPrice, detail.Quantity, entity.CreatedTime, entity.UserId, true, detail.BuyQuantity, i)
+		tx.Exec("INSERT INTO transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val, "+
+			"discount_type, discount_value, discount_amount, tax_rate, dpp, ppn) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+			txDetailId, entity.ID, detail.ProductID, detail.BuyPrice, detail.SellPrice, detail.Quantity, entity.CreatedTime, entity.UserId, true, detail.BuyQuantity, i,
+			detail.DiscountType, detail.DiscountValue, detail.DiscountAmount, detail.TaxRate, detail.Dpp, detail.Ppn)
 
 		if tx.Error != nil {
 			return
@@ -352,7 +455,7 @@ func (r *Repo) UpdateTransaction(entity *transactiondomain.Transaction, tx *gorm
 
 }
 
-func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.ReportDate, error) {
+func (r *Repo) FindReport(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.ReportDate, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -370,15 +473,18 @@ func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.Report
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT t.date, t.code, t.reference_code, t.status, p.code, p.name, td.buy_quantity, td.buy_price, td.quantity, td.sell_price, td.id "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT t.date, t.code, t.reference_code, t.status, t.subtotal, t.discount, t.dpp, t.ppn, t.grand_total, "+
+		"p.code, p.name, td.buy_quantity, td.buy_price, td.quantity, td.sell_price, td.id, td.discount_amount, td.tax_rate, td.dpp, td.ppn "+
 		"FROM transaction t "+
 		"JOIN transaction_detail td ON (t.id = td.transaction_id) "+
 		"JOIN product p ON (td.product_id = p.id) "+
 		"JOIN customer c ON (t.stakeholder_id = c.id) "+
 		"%s ORDER BY t.date DESC, t.code ASC, p.id ASC", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -391,6 +497,11 @@ func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.Report
 		var Code sql.NullString
 		var ReferenceCode sql.NullString
 		var Status sql.NullString
+		var Subtotal sql.NullFloat64
+		var Discount sql.NullFloat64
+		var Dpp sql.NullFloat64
+		var Ppn sql.NullFloat64
+		var GrandTotal sql.NullFloat64
 		var ProductCode sql.NullString
 		var ProductName sql.NullString
 		var BuyQuantity sql.NullFloat64
@@ -398,8 +509,13 @@ func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.Report
 		var Quantity sql.NullFloat64
 		var SellPrice sql.NullFloat64
 		var ID sql.NullString
+		var DiscountAmount sql.NullFloat64
+		var TaxRate sql.NullFloat64
+		var DetailDpp sql.NullFloat64
+		var DetailPpn sql.NullFloat64
 
-		rows.Scan(&Date, &Code, &ReferenceCode, &Status, &ProductCode, &ProductName, &BuyQuantity, &BuyPrice, &Quantity, &SellPrice, &ID)
+		rows.Scan(&Date, &Code, &ReferenceCode, &Status, &Subtotal, &Discount, &Dpp, &Ppn, &GrandTotal,
+			&ProductCode, &ProductName, &BuyQuantity, &BuyPrice, &Quantity, &SellPrice, &ID, &DiscountAmount, &TaxRate, &DetailDpp, &DetailPpn)
 
 		var dateEntity *transactiondomain.ReportDate
 		var entity *transactiondomain.Report
@@ -425,6 +541,11 @@ func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.Report
 			entity.Code = Code.String
 			entity.ReferenceCode = ReferenceCode.String
 			entity.Status = Status.String
+			entity.Subtotal = Subtotal.Float64
+			entity.Discount = Discount.Float64
+			entity.Dpp = Dpp.Float64
+			entity.Ppn = Ppn.Float64
+			entity.GrandTotal = GrandTotal.Float64
 
 			dateEntity.Reports = append(dateEntity.Reports, entity)
 			entityMap[entity.Code] = entity
@@ -438,6 +559,10 @@ func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.Report
 		detail.Quantity = Quantity.Float64
 		detail.SellPrice = SellPrice.Float64
 		detail.ID = ID.String
+		detail.DiscountAmount = DiscountAmount.Float64
+		detail.TaxRate = TaxRate.Float64
+		detail.Dpp = DetailDpp.Float64
+		detail.Ppn = DetailPpn.Float64
 
 		entity.ReportDetails = append(entity.ReportDetails, detail)
 
@@ -446,17 +571,62 @@ func (r *Repo) FindReport(params []queryutil.Param) ([]*transactiondomain.Report
 	return dateEntities, nil
 
 }
-func (r *Repo) UpdateHargaBeli(transactionDetailID string, buyPrice int64, webUserID string) error {
-	tx := global.DBCON.Begin()
-	tx.Exec("UPDATE public.transaction_detail SET latest=? WHERE id=?;", false, transactionDetailID)
+
+// FindTaxSetting reads the tax settings of a customer and the rates of the products, a customer that is not found is not taxable
+func (r *Repo) FindTaxSetting(ctx context.Context, customerID string, productIDs []string) (*transactiondomain.TaxSetting, error) {
+	setting := &transactiondomain.TaxSetting{ProductRates: make(map[string]*float64)}
+
+	var Taxable sql.NullBool
+	var TaxInclusive sql.NullBool
+	var TaxRate sql.NullFloat64
+	err := tenantutil.DB(ctx).Raw("SELECT taxable, tax_inclusive, tax_rate FROM customer WHERE id = ?", customerID).Row().Scan(&Taxable, &TaxInclusive, &TaxRate)
+	if err != nil && err != sql.ErrNoRows {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	setting.Taxable = Taxable.Bool
+	setting.TaxInclusive = TaxInclusive.Bool
+	if TaxRate.Valid {
+		setting.CustomerRate = &TaxRate.Float64
+	}
+
+	if len(productIDs) == 0 {
+		return setting, nil
+	}
+
+	rows, err := tenantutil.DB(ctx).Raw("SELECT id, tax_rate FROM product WHERE id IN ? AND tax_rate IS NOT NULL", productIDs).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ID string
+		var Rate float64
+		if err := rows.Scan(&ID, &Rate); err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, err
+		}
+		setting.ProductRates[ID] = &Rate
+	}
+
+	return setting, nil
+}
+
+func (r *Repo) UpdateHargaBeli(ctx context.Context, transactionDetailID string, buyPrice int64, webUserID string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	tx.Exec("UPDATE transaction_detail SET latest=? WHERE id=?;", false, transactionDetailID)
 
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	tx.Exec("INSERT INTO public.transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val) "+
-		"SELECT ?, transaction_id, product_id, ?, sell_price, quantity, ?, ?, ?, buy_quantity, sorting_val "+
-		"FROM public.transaction_detail "+
+	tx.Exec("INSERT INTO transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val, "+
+		"discount_type, discount_value, discount_amount, tax_rate, dpp, ppn) "+
+		"SELECT ?, transaction_id, product_id, ?, sell_price, quantity, ?, ?, ?, buy_quantity, sorting_val, "+
+		"discount_type, discount_value, discount_amount, tax_rate, dpp, ppn "+
+		"FROM transaction_detail "+
 		"WHERE id=?;", stringutil.GenerateUUID(), buyPrice, time.Now(), webUserID, true, transactionDetailID)
 
 	if tx.Error != nil {
@@ -468,32 +638,31 @@ func (r *Repo) UpdateHargaBeli(transactionDetailID string, buyPrice int64, webUs
 	return nil
 }
 
-func (r *Repo) UpdateHargaBeliTx(transactionDetailID string, buyPrice float64, webUserID string, tx *gorm.DB) error {
-	tx.Exec("UPDATE public.transaction_detail SET latest=? WHERE id=?;", false, transactionDetailID)
-
-	if tx.Error != nil {
-		return tx.Error
+func (r *Repo) UpdateHargaBeliTx(ctx context.Context, transactionDetailID string, buyPrice float64, webUserID string, tx *gorm.DB) error {
+	// the error is on the result of Exec, tx itself keeps no error of a statement
+	if err := tx.Exec("UPDATE transaction_detail SET latest=? WHERE id=?;", false, transactionDetailID).Error; err != nil {
+		return err
 	}
 
-	tx.Exec("INSERT INTO public.transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val) "+
-		"SELECT ?, transaction_id, product_id, ?, sell_price, quantity, ?, ?, ?, buy_quantity, sorting_val "+
-		"FROM public.transaction_detail "+
-		"WHERE id=?;", stringutil.GenerateUUID(), buyPrice, time.Now(), webUserID, true, transactionDetailID)
-
-	return tx.Error
+	return tx.Exec("INSERT INTO transaction_detail(id, transaction_id, product_id, buy_price, sell_price, quantity, created_time, web_user_id, latest, buy_quantity, sorting_val, "+
+		"discount_type, discount_value, discount_amount, tax_rate, dpp, ppn) "+
+		"SELECT ?, transaction_id, product_id, ?, sell_price, quantity, ?, ?, ?, buy_quantity, sorting_val, "+
+		"discount_type, discount_value, discount_amount, tax_rate, dpp, ppn "+
+		"FROM transaction_detail "+
+		"WHERE id=?;", stringutil.GenerateUUID(), buyPrice, time.Now(), webUserID, true, transactionDetailID).Error
 }
 
-func (r *Repo) InsertTransactionBuy(transactionId string, transactionBuys []transactiondomain.TransactionBuy) error {
-	tx := global.DBCON.Begin()
+func (r *Repo) InsertTransactionBuy(ctx context.Context, transactionId string, transactionBuys []transactiondomain.TransactionBuy) error {
+	tx := tenantutil.DB(ctx).Begin()
 
-	tx.Exec("UPDATE public.transaction_buy SET latest=? WHERE transaction_id=?;", false, transactionId)
+	tx.Exec("UPDATE transaction_buy SET latest=? WHERE transaction_id=?;", false, transactionId)
 
 	if tx.Error != nil {
 		return tx.Error
 	}
 
 	for _, transactionBuy := range transactionBuys {
-		tx.Exec("INSERT INTO public.transaction_buy (id, transaction_id, product_id, price, quantity, payment_method, created_time, web_user_id, latest) "+
+		tx.Exec("INSERT INTO transaction_buy (id, transaction_id, product_id, price, quantity, payment_method, created_time, web_user_id, latest) "+
 			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);", transactionBuy.ID, transactionBuy.TransactionID, transactionBuy.ProductID, transactionBuy.Price, transactionBuy.Quantity, transactionBuy.PaymentMethod, transactionBuy.CreatedTime, transactionBuy.WebUserID, true)
 
 		if tx.Error != nil {
@@ -505,17 +674,19 @@ func (r *Repo) InsertTransactionBuy(transactionId string, transactionBuys []tran
 	return nil
 }
 
-func (r *Repo) FindTransactionBuyStatus() ([]transactiondomain.TransactionBuyStatus, error) {
-	rows, err := global.DBCON.Raw("SELECT t.id, t.code, c.code, c.name, count(tb.id), count(td.id) " +
-		"FROM transaction t " +
-		"JOIN customer c ON (c.id = t.stakeholder_id) " +
-		"LEFT JOIN transaction_buy tb ON (tb.transaction_id = t.id) " +
-		"JOIN transaction_detail td ON (td.transaction_id = t.id) " +
-		"WHERE (tb.id IS NULL OR tb.latest = true) AND td.latest = true " +
-		"GROUP BY t.id, t.code, c.code, c.name").Rows()
+func (r *Repo) FindTransactionBuyStatus(ctx context.Context) ([]transactiondomain.TransactionBuyStatus, error) {
+	where, values := scopeutil.Where(ctx, "WHERE (tb.id IS NULL OR tb.latest = true) AND td.latest = true ", nil, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
+	rows, err := tenantutil.DB(ctx).Raw("SELECT t.id, t.code, c.code, c.name, count(tb.id), count(td.id) "+
+		"FROM transaction t "+
+		"JOIN customer c ON (c.id = t.stakeholder_id) "+
+		"LEFT JOIN transaction_buy tb ON (tb.transaction_id = t.id) "+
+		"JOIN transaction_detail td ON (td.transaction_id = t.id) "+
+		where+
+		"GROUP BY t.id, t.code, c.code, c.name", values...).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -550,7 +721,7 @@ func (r *Repo) FindTransactionBuyStatus() ([]transactiondomain.TransactionBuySta
 	return entities, nil
 }
 
-func (r *Repo) FindDetails(params []queryutil.Param) ([]*transactiondomain.TransactionDetail, error) {
+func (r *Repo) FindDetails(ctx context.Context, params []queryutil.Param) ([]*transactiondomain.TransactionDetail, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -568,14 +739,16 @@ func (r *Repo) FindDetails(params []queryutil.Param) ([]*transactiondomain.Trans
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT td.id, td.transaction_id, td.product_id, td.buy_price, td.sell_price, td.quantity, "+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT td.id, td.transaction_id, td.product_id, td.buy_price, td.sell_price, td.quantity, "+
 		"td.buy_quantity, td.created_time, td.web_user_id, td.latest, td.sorting_val "+
 		"FROM transaction_detail td "+
 		"JOIN transaction t ON (td.transaction_id = t.id) "+
 		"%s ", where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -596,7 +769,7 @@ func (r *Repo) FindDetails(params []queryutil.Param) ([]*transactiondomain.Trans
 
 		err = rows.Scan(&ID, &TransactionID, &ProductID, &BuyPrice, &SellPrice, &Quantity, &BuyQuantity, &CreatedTime, &WebUserID, &Latest, &SortingVal)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(ctx).Error(err.Error())
 			return nil, err
 		}
 
@@ -624,7 +797,7 @@ func (r *Repo) FindDetails(params []queryutil.Param) ([]*transactiondomain.Trans
 	return entities, nil
 }
 
-func (r *Repo) FindLastCredit(params []queryutil.Param) (map[string]float64, error) {
+func (r *Repo) FindLastCredit(ctx context.Context, params []queryutil.Param) (map[string]float64, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -642,15 +815,18 @@ func (r *Repo) FindLastCredit(params []queryutil.Param) (map[string]float64, err
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
 
-	query := "SELECT c.code, SUM(td.quantity * td.sell_price) AS \"balance\" FROM transaction t " +
+	// the filter may join the details, the grand total of a transaction is summed once however many details it has
+	query := "SELECT s.code, SUM(s.grand_total) AS \"balance\" FROM (SELECT DISTINCT t.id, c.code, t.grand_total FROM transaction t " +
 		"JOIN transaction_detail td ON (t.id = td.transaction_id) " +
 		"JOIN customer c ON (t.stakeholder_id = c.id) " +
-		"%s GROUP BY c.code ORDER BY c.code;"
+		"%s) s GROUP BY s.code ORDER BY s.code;"
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf(query, where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf(query, where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -662,7 +838,7 @@ func (r *Repo) FindLastCredit(params []queryutil.Param) (map[string]float64, err
 
 		err = rows.Scan(&CustomerCode, &LastCredit)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(ctx).Error(err.Error())
 			return nil, err
 		}
 
@@ -676,7 +852,7 @@ func (r *Repo) FindLastCredit(params []queryutil.Param) (map[string]float64, err
 	return entities, nil
 }
 
-func (r *Repo) FindLastCreditPerMonth(params []queryutil.Param) (map[string]map[int]float64, error) {
+func (r *Repo) FindLastCreditPerMonth(ctx context.Context, params []queryutil.Param) (map[string]map[int]float64, error) {
 	where := ""
 	var values []interface{}
 	for _, param := range params {
@@ -694,15 +870,17 @@ func (r *Repo) FindLastCreditPerMonth(params []queryutil.Param) (map[string]map[
 	if where != "" {
 		where = "WHERE " + where
 	}
+	where, values = scopeutil.Where(ctx, where, values, "t.stakeholder_id")
+	where, values = scopeutil.BranchWhere(ctx, where, values, "t.branch_id")
 
-	query := "SELECT c.code, t.date, SUM(td.quantity * td.sell_price) FROM transaction t " +
+	query := "SELECT s.code, s.date, SUM(s.grand_total) FROM (SELECT DISTINCT t.id, c.code, t.date, t.grand_total FROM transaction t " +
 		"JOIN transaction_detail td ON (t.id = td.transaction_id) " +
 		"JOIN customer c ON (t.stakeholder_id = c.id) " +
-		"%s GROUP BY c.code, t.date ORDER BY c.code, t.date;"
+		"%s) s GROUP BY s.code, s.date ORDER BY s.code, s.date;"
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf(query, where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf(query, where), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -715,7 +893,7 @@ func (r *Repo) FindLastCreditPerMonth(params []queryutil.Param) (map[string]map[
 
 		err = rows.Scan(&CustomerCode, &Date, &Credit)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(ctx).Error(err.Error())
 			return nil, err
 		}
 
@@ -735,26 +913,38 @@ func (r *Repo) FindLastCreditPerMonth(params []queryutil.Param) (map[string]map[
 	return entities, nil
 }
 
-func (r *Repo) FindCustomerReport(stakeHolderID string, month time.Time) ([]*transactiondomain.LaporanCustomer, int, error) {
+func (r *Repo) FindCustomerReport(ctx context.Context, stakeHolderID string, month time.Time) ([]*transactiondomain.LaporanCustomer, int, error) {
 	query := `select p.code, p.name, count(p.*), t.date, t.id 
-	from public.transaction_detail td 
-	join public.transaction t on t.id = td.transaction_id 
-	join public.product p on p.id = td.product_id 
+	from transaction_detail td 
+	join transaction t on t.id = td.transaction_id 
+	join product p on p.id = td.product_id 
 	where t.transaction_type = 'SELL' 
 	and (t.status = 'KONTRABON' or t.status = 'DIBAYAR') 
 	and t.date >= ? 
 	and t.date <= ?
 	and t.stakeholder_id = ?
+	%s
 	group by p.code, p.name, t.date, t.stakeholder_id, t.id 
 	order by p.name, t.date`
 
+	// an unassigned customer reads as a customer without sales
+	scope, scopeValues := scopeutil.Condition(ctx, "t.stakeholder_id")
+	if scope != "" {
+		scope = "and " + scope
+	}
+	if branch, branchValues := scopeutil.BranchCondition(ctx, "t.branch_id"); branch != "" {
+		scope += "and " + branch
+		scopeValues = append(scopeValues, branchValues...)
+	}
+	query = fmt.Sprintf(query, scope)
+
 	startDate := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
 	endDate := time.Date(month.Year(), month.Month(), dateutil.DaysIn(month.Month(), month.Year()), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
 
-	rows, err := global.DBCON.Raw(query, startDate, endDate, stakeHolderID).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(query, append([]interface{}{startDate, endDate, stakeHolderID}, scopeValues...)...).Rows()
 
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
 		return nil, 0, err
 	}
 	defer rows.Close()
@@ -771,7 +961,7 @@ func (r *Repo) FindCustomerReport(stakeHolderID string, month time.Time) ([]*tra
 
 		err = rows.Scan(&ProductCode, &ProductName, &Count, &Date, &TxID)
 		if err != nil {
-			logrus.Error(err.Error())
+			logutil.WithContext(ctx).Error(err.Error())
 			return nil, 0, err
 		}
 
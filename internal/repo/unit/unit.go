@@ -1,17 +1,26 @@
 package unitrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
 	unitdomain "dromatech/pos-backend/internal/domain/unit"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"fmt"
-	"github.com/sirupsen/logrus"
 )
 
 type UnitRepo interface {
-	Find(params map[string]interface{}) ([]*unitdomain.Unit, error)
-	Create(product *unitdomain.Unit) error
-	Edit(product *unitdomain.Unit) error
+	Find(ctx context.Context, params map[string]interface{}) ([]*unitdomain.Unit, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*unitdomain.Unit, int64, error)
+	Create(ctx context.Context, product *unitdomain.Unit) error
+	Edit(ctx context.Context, product *unitdomain.Unit) error
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"code":        "code",
+	"description": "description",
 }
 
 type Repo struct {
@@ -22,25 +31,19 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) Find(params map[string]interface{}) ([]*unitdomain.Unit, error) {
-	where := ""
-	var values []interface{}
-	for key, value := range params {
-		if where != "" {
-			where += "AND "
-		}
-		where += key + " = ? "
-		values = append(values, value)
-	}
+func (r *Repo) Find(ctx context.Context, params map[string]interface{}) ([]*unitdomain.Unit, error) {
+	entities, _, err := r.FindList(ctx, queryutil.FromMap(params), queryutil.ListParam{})
+	return entities, err
+}
 
-	if where != "" {
-		where = "WHERE " + where
-	}
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*unitdomain.Unit, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "code", "description")
 
-	rows, err := global.DBCON.Raw(fmt.Sprintf("SELECT id, code, description FROM unit %s ORDER BY code", where), values...).Rows()
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, code, description FROM unit %s %s %s", where, list.OrderBy(sortColumns, "code"), list.LimitOffset()), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -57,7 +60,7 @@ func (r *Repo) Find(params map[string]interface{}) ([]*unitdomain.Unit, error) {
 		if ID.Valid && ID.String != "" {
 			entity.ID = ID.String
 		} else {
-			return nil, nil
+			return nil, 0, nil
 		}
 
 		if Code.Valid {
@@ -71,17 +74,26 @@ func (r *Repo) Find(params map[string]interface{}) ([]*unitdomain.Unit, error) {
 		entities = append(entities, entity)
 	}
 
-	return entities, nil
+	total := int64(len(entities))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM unit %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return entities, total, nil
 }
 
-func (r *Repo) Create(entity *unitdomain.Unit) error {
-	return global.DBCON.Exec("INSERT INTO public.unit(id, code, description, active) "+
+func (r *Repo) Create(ctx context.Context, entity *unitdomain.Unit) error {
+	return tenantutil.DB(ctx).Exec("INSERT INTO unit(id, code, description, active) "+
 		"VALUES (?, ?, ?, ?)",
 		entity.ID, entity.Code, entity.Description, entity.Active).Error
 }
 
-func (r *Repo) Edit(entity *unitdomain.Unit) error {
-	return global.DBCON.Exec("UPDATE public.unit "+
+func (r *Repo) Edit(ctx context.Context, entity *unitdomain.Unit) error {
+	return tenantutil.DB(ctx).Exec("UPDATE unit "+
 		"SET code=?, description=?, active=? "+
 		"WHERE id=?;", entity.Code, entity.Description, entity.Active, entity.ID).Error
 }
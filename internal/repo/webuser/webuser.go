@@ -1,21 +1,50 @@
 package webuserrepo
 
 import (
+	"context"
 	"database/sql"
-	"dromatech/pos-backend/global"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
 	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
-
-	"github.com/sirupsen/logrus"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type WebUserRepo interface {
-	FindAll() ([]*webuserdomain.WebUser, error)
-	Find(id string) *webuserdomain.WebUser
-	FindByUsername(username string) *webuserdomain.WebUser
-	EditUser(webUser *webuserdomain.WebUser) error
-	ChangePassword(userId string, newPassword string)
-	RegisterUser(webUser *webuserdomain.WebUser)
-	ChangeStatus(userId string, active bool)
+	FindAll(ctx context.Context) ([]*webuserdomain.WebUser, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*webuserdomain.WebUser, int64, error)
+	Find(ctx context.Context, id string) *webuserdomain.WebUser
+	FindByUsername(ctx context.Context, username string) *webuserdomain.WebUser
+	EditUser(ctx context.Context, webUser *webuserdomain.WebUser) error
+	ChangePassword(ctx context.Context, userId string, passwordHash string, mustChange bool, keepHistory int) error
+	FindPasswordHistory(ctx context.Context, userId string, limit int) ([]*webuserdomain.PasswordHistory, error)
+	RegisterUser(ctx context.Context, webUser *webuserdomain.WebUser)
+	ChangeStatus(ctx context.Context, userId string, active bool)
+	ChangeLanguage(ctx context.Context, userId string, language string) error
+	RecordLoginFailure(ctx context.Context, userId string, failedCount int, lockedUntil *time.Time) error
+	ResetLoginFailure(ctx context.Context, userId string) error
+	SaveTotpSecret(ctx context.Context, userId string, secret string) error
+	EnableTotp(ctx context.Context, userId string, codeHashes []string) error
+	DisableTotp(ctx context.Context, userId string) error
+	ReplaceRecoveryCodes(ctx context.Context, userId string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userId string, codeHash string) (bool, error)
+	UseTotpStep(ctx context.Context, userId string, step int64) (bool, error)
+	FindStakeholders(ctx context.Context, userId string) (*webuserdomain.Stakeholders, error)
+	ReplaceStakeholders(ctx context.Context, userId string, customerIds, supplierIds []string) error
+	FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error)
+	ReplaceBranches(ctx context.Context, userId string, branchIds []string) error
+}
+
+// sortColumns maps the sort fields of the list endpoint to columns
+var sortColumns = map[string]string{
+	"name":     "name",
+	"username": "username",
 }
 
 type Repo struct {
@@ -26,11 +55,19 @@ func New() *Repo {
 	return repo
 }
 
-func (r *Repo) FindAll() ([]*webuserdomain.WebUser, error) {
-	rows, err := global.DBCON.Raw("SELECT id, name, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by FROM web_user ORDER BY name").Rows()
+func (r *Repo) FindAll(ctx context.Context) ([]*webuserdomain.WebUser, error) {
+	users, _, err := r.FindList(ctx, nil, queryutil.ListParam{})
+	return users, err
+}
+
+func (r *Repo) FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*webuserdomain.WebUser, int64, error) {
+	where, values := queryutil.Where(params)
+	where, values = queryutil.Search(where, values, list.Search, "username", "name")
+
+	rows, err := tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT id, name, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by, failed_login_count, locked_until, totp_enabled, service_account, must_change_password FROM web_user %s %s %s", where, list.OrderBy(sortColumns, "name"), list.LimitOffset()), values...).Rows()
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, err
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -47,8 +84,13 @@ func (r *Repo) FindAll() ([]*webuserdomain.WebUser, error) {
 		var Active sql.NullBool
 		var RegistrationTimestamp sql.NullTime
 		var CreatedBy sql.NullString
+		var FailedLoginCount sql.NullInt64
+		var LockedUntil sql.NullTime
+		var TotpEnabled sql.NullBool
+		var ServiceAccount sql.NullBool
+		var MustChangePassword sql.NullBool
 
-		rows.Scan(&ID, &Name, &Username, &PasswordHash, &PasswordSalt, &Email, &RoleId, &Active, &RegistrationTimestamp, &CreatedBy)
+		rows.Scan(&ID, &Name, &Username, &PasswordHash, &PasswordSalt, &Email, &RoleId, &Active, &RegistrationTimestamp, &CreatedBy, &FailedLoginCount, &LockedUntil, &TotpEnabled, &ServiceAccount, &MustChangePassword)
 
 		user := &webuserdomain.WebUser{}
 		if ID.Valid {
@@ -91,14 +133,41 @@ func (r *Repo) FindAll() ([]*webuserdomain.WebUser, error) {
 			user.CreatedBy = CreatedBy.String
 		}
 
+		user.FailedLoginCount = int(FailedLoginCount.Int64)
+
+		if LockedUntil.Valid {
+			user.LockedUntil = &LockedUntil.Time
+		}
+
+		if TotpEnabled.Valid {
+			user.TotpEnabled = TotpEnabled.Bool
+		}
+
+		if ServiceAccount.Valid {
+			user.ServiceAccount = ServiceAccount.Bool
+		}
+
+		if MustChangePassword.Valid {
+			user.MustChangePassword = MustChangePassword.Bool
+		}
+
 		users = append(users, user)
 	}
 
-	return users, nil
+	total := int64(len(users))
+	if list.Paged() {
+		err = tenantutil.DB(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM web_user %s", where), values...).Row().Scan(&total)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, 0, err
+		}
+	}
+
+	return users, total, nil
 }
 
-func (r *Repo) Find(id string) *webuserdomain.WebUser {
-	row := global.DBCON.Raw("SELECT id, name, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by FROM web_user WHERE id = ?", id).Row()
+func (r *Repo) Find(ctx context.Context, id string) *webuserdomain.WebUser {
+	row := tenantutil.DB(ctx).Raw("SELECT id, name, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by, language, failed_login_count, locked_until, totp_secret, totp_enabled, service_account, must_change_password, password_changed_time FROM web_user WHERE id = ?", id).Row()
 
 	var ID sql.NullString
 	var Name sql.NullString
@@ -110,8 +179,16 @@ func (r *Repo) Find(id string) *webuserdomain.WebUser {
 	var Active sql.NullBool
 	var RegistrationTimestamp sql.NullTime
 	var CreatedBy sql.NullString
+	var FailedLoginCount sql.NullInt64
+	var LockedUntil sql.NullTime
+	var Language sql.NullString
+	var TotpSecret sql.NullString
+	var TotpEnabled sql.NullBool
+	var ServiceAccount sql.NullBool
+	var MustChangePassword sql.NullBool
+	var PasswordChangedTime sql.NullTime
 
-	row.Scan(&ID, &Name, &Username, &PasswordHash, &PasswordSalt, &Email, &RoleId, &Active, &RegistrationTimestamp, &CreatedBy)
+	row.Scan(&ID, &Name, &Username, &PasswordHash, &PasswordSalt, &Email, &RoleId, &Active, &RegistrationTimestamp, &CreatedBy, &Language, &FailedLoginCount, &LockedUntil, &TotpSecret, &TotpEnabled, &ServiceAccount, &MustChangePassword, &PasswordChangedTime)
 
 	user := &webuserdomain.WebUser{}
 	if ID.Valid {
@@ -154,11 +231,41 @@ func (r *Repo) Find(id string) *webuserdomain.WebUser {
 		user.CreatedBy = CreatedBy.String
 	}
 
+	user.FailedLoginCount = int(FailedLoginCount.Int64)
+
+	if LockedUntil.Valid {
+		user.LockedUntil = &LockedUntil.Time
+	}
+
+	if Language.Valid {
+		user.Language = Language.String
+	}
+
+	if TotpSecret.Valid {
+		user.TotpSecret = TotpSecret.String
+	}
+
+	if TotpEnabled.Valid {
+		user.TotpEnabled = TotpEnabled.Bool
+	}
+
+	if ServiceAccount.Valid {
+		user.ServiceAccount = ServiceAccount.Bool
+	}
+
+	if MustChangePassword.Valid {
+		user.MustChangePassword = MustChangePassword.Bool
+	}
+
+	if PasswordChangedTime.Valid {
+		user.PasswordChangedTime = &PasswordChangedTime.Time
+	}
+
 	return user
 }
 
-func (r *Repo) FindByUsername(username string) *webuserdomain.WebUser {
-	row := global.DBCON.Raw("SELECT id, name, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by FROM web_user WHERE username = ?", username).Row()
+func (r *Repo) FindByUsername(ctx context.Context, username string) *webuserdomain.WebUser {
+	row := tenantutil.DB(ctx).Raw("SELECT id, name, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by, language, failed_login_count, locked_until, totp_secret, totp_enabled, service_account, must_change_password, password_changed_time FROM web_user WHERE username = ?", username).Row()
 
 	var ID sql.NullString
 	var Name sql.NullString
@@ -170,8 +277,16 @@ func (r *Repo) FindByUsername(username string) *webuserdomain.WebUser {
 	var Active sql.NullBool
 	var RegistrationTimestamp sql.NullTime
 	var CreatedBy sql.NullString
+	var FailedLoginCount sql.NullInt64
+	var LockedUntil sql.NullTime
+	var Language sql.NullString
+	var TotpSecret sql.NullString
+	var TotpEnabled sql.NullBool
+	var ServiceAccount sql.NullBool
+	var MustChangePassword sql.NullBool
+	var PasswordChangedTime sql.NullTime
 
-	row.Scan(&ID, &Name, &Username, &PasswordHash, &PasswordSalt, &Email, &RoleId, &Active, &RegistrationTimestamp, &CreatedBy)
+	row.Scan(&ID, &Name, &Username, &PasswordHash, &PasswordSalt, &Email, &RoleId, &Active, &RegistrationTimestamp, &CreatedBy, &Language, &FailedLoginCount, &LockedUntil, &TotpSecret, &TotpEnabled, &ServiceAccount, &MustChangePassword, &PasswordChangedTime)
 
 	user := &webuserdomain.WebUser{}
 	if ID.Valid && ID.String != "" {
@@ -216,29 +331,351 @@ func (r *Repo) FindByUsername(username string) *webuserdomain.WebUser {
 		user.CreatedBy = CreatedBy.String
 	}
 
+	user.FailedLoginCount = int(FailedLoginCount.Int64)
+
+	if LockedUntil.Valid {
+		user.LockedUntil = &LockedUntil.Time
+	}
+
+	if Language.Valid {
+		user.Language = Language.String
+	}
+
+	if TotpSecret.Valid {
+		user.TotpSecret = TotpSecret.String
+	}
+
+	if TotpEnabled.Valid {
+		user.TotpEnabled = TotpEnabled.Bool
+	}
+
+	if ServiceAccount.Valid {
+		user.ServiceAccount = ServiceAccount.Bool
+	}
+
+	if MustChangePassword.Valid {
+		user.MustChangePassword = MustChangePassword.Bool
+	}
+
+	if PasswordChangedTime.Valid {
+		user.PasswordChangedTime = &PasswordChangedTime.Time
+	}
+
 	return user
 }
 
-func (r *Repo) EditUser(webUser *webuserdomain.WebUser) error {
-	return global.DBCON.Exec("UPDATE public.web_user "+
+func (r *Repo) EditUser(ctx context.Context, webUser *webuserdomain.WebUser) error {
+	return tenantutil.DB(ctx).Exec("UPDATE web_user "+
 		"SET name=?, username=?, role_id=?, active=? "+
 		"WHERE id=?;", webUser.Name, webUser.Username, webUser.RoleId, webUser.Active, webUser.ID).Error
 }
 
-func (r *Repo) ChangePassword(userId string, newPassword string) {
-	global.DBCON.Exec("UPDATE public.web_user "+
-		"SET password_hash=? "+
-		"WHERE id=?;", newPassword, userId)
+// ChangePassword sets the password hash and records it in the history, only the newest keepHistory passwords are kept
+func (r *Repo) ChangePassword(ctx context.Context, userId string, passwordHash string, mustChange bool, keepHistory int) error {
+	now := time.Now()
+	tx := tenantutil.DB(ctx).Begin()
+	err := tx.Exec("UPDATE web_user "+
+		"SET password_hash=?, must_change_password=?, password_changed_time=? "+
+		"WHERE id=?;", passwordHash, mustChange, now, userId).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Exec("INSERT INTO web_user_password_history(id, web_user_id, password_hash, password_salt, created_time) "+
+		"SELECT ?, id, password_hash, password_salt, ? FROM web_user WHERE id=?;",
+		strings.ReplaceAll(uuid.NewString(), "-", ""), now, userId).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Exec("DELETE FROM web_user_password_history "+
+		"WHERE web_user_id=? AND id NOT IN ("+
+		"SELECT id FROM web_user_password_history WHERE web_user_id=? ORDER BY created_time DESC LIMIT ?);",
+		userId, userId, keepHistory).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
-func (r *Repo) RegisterUser(webUser *webuserdomain.WebUser) {
-	global.DBCON.Exec("INSERT INTO public.web_user(id, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by, name) "+
-		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		webUser.ID, webUser.Username, webUser.PasswordHash, webUser.PasswordSalt, webUser.Email, webUser.RoleId, webUser.Active, webUser.RegistrationTimestamp, webUser.CreatedBy, webUser.Name)
+// FindPasswordHistory returns the newest passwords of the user, newest first
+func (r *Repo) FindPasswordHistory(ctx context.Context, userId string, limit int) ([]*webuserdomain.PasswordHistory, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT password_hash, password_salt, created_time FROM web_user_password_history "+
+		"WHERE web_user_id=? ORDER BY created_time DESC LIMIT ?", userId, limit).Rows()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []*webuserdomain.PasswordHistory
+	for rows.Next() {
+		history := &webuserdomain.PasswordHistory{}
+		rows.Scan(&history.PasswordHash, &history.PasswordSalt, &history.CreatedTime)
+		histories = append(histories, history)
+	}
+	return histories, nil
+}
+
+func (r *Repo) RegisterUser(ctx context.Context, webUser *webuserdomain.WebUser) {
+	tenantutil.DB(ctx).Exec("INSERT INTO web_user(id, username, password_hash, password_salt, email, role_id, active, registration_timestamp, created_by, name, service_account, must_change_password, password_changed_time) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		webUser.ID, webUser.Username, webUser.PasswordHash, webUser.PasswordSalt, webUser.Email, webUser.RoleId, webUser.Active, webUser.RegistrationTimestamp, webUser.CreatedBy, webUser.Name, webUser.ServiceAccount, webUser.MustChangePassword, webUser.PasswordChangedTime)
 }
 
-func (r *Repo) ChangeStatus(userId string, active bool) {
-	global.DBCON.Exec("UPDATE public.web_user "+
+func (r *Repo) ChangeStatus(ctx context.Context, userId string, active bool) {
+	tenantutil.DB(ctx).Exec("UPDATE web_user "+
 		"SET active=? "+
 		"WHERE id=?;", active, userId)
 }
+
+func (r *Repo) ChangeLanguage(ctx context.Context, userId string, language string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE web_user "+
+		"SET language=? "+
+		"WHERE id=?;", language, userId).Error
+}
+
+// RecordLoginFailure stores the failed login count and the time the user stays locked until, if any
+func (r *Repo) RecordLoginFailure(ctx context.Context, userId string, failedCount int, lockedUntil *time.Time) error {
+	return tenantutil.DB(ctx).Exec("UPDATE web_user "+
+		"SET failed_login_count=?, locked_until=? "+
+		"WHERE id=?;", failedCount, lockedUntil, userId).Error
+}
+
+// ResetLoginFailure clears the failed login count and unlocks the user
+func (r *Repo) ResetLoginFailure(ctx context.Context, userId string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE web_user "+
+		"SET failed_login_count = 0, locked_until = NULL "+
+		"WHERE id=?;", userId).Error
+}
+
+// SaveTotpSecret stores the secret of a pending enrolment, it is not used for login until enabled
+func (r *Repo) SaveTotpSecret(ctx context.Context, userId string, secret string) error {
+	return tenantutil.DB(ctx).Exec("UPDATE web_user "+
+		"SET totp_secret=? "+
+		"WHERE id=? AND totp_enabled = false;", secret, userId).Error
+}
+
+// EnableTotp turns on two-factor authentication together with a fresh set of recovery codes
+func (r *Repo) EnableTotp(ctx context.Context, userId string, codeHashes []string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := tx.Exec("UPDATE web_user SET totp_enabled = true WHERE id=?;", userId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertRecoveryCodes(tx, userId, codeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DisableTotp turns off two-factor authentication and drops the secret and recovery codes
+func (r *Repo) DisableTotp(ctx context.Context, userId string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := tx.Exec("UPDATE web_user SET totp_enabled = false, totp_secret = NULL WHERE id=?;", userId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM web_user_recovery_code WHERE web_user_id=?;", userId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *Repo) ReplaceRecoveryCodes(ctx context.Context, userId string, codeHashes []string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := insertRecoveryCodes(tx, userId, codeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// UseRecoveryCode marks the code as used, it returns false when the code does not exist or was used before
+func (r *Repo) UseRecoveryCode(ctx context.Context, userId string, codeHash string) (bool, error) {
+	result := tenantutil.DB(ctx).Exec("UPDATE web_user_recovery_code "+
+		"SET used_time=? "+
+		"WHERE web_user_id=? AND code_hash=? AND used_time IS NULL;", time.Now(), userId, codeHash)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UseTotpStep keeps the time step of an accepted TOTP code, it returns false when a code of the step or a later one was used before
+func (r *Repo) UseTotpStep(ctx context.Context, userId string, step int64) (bool, error) {
+	result := tenantutil.DB(ctx).Exec("UPDATE web_user "+
+		"SET totp_last_step=? "+
+		"WHERE id=? AND (totp_last_step IS NULL OR totp_last_step < ?);", step, userId, step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *Repo) FindStakeholders(ctx context.Context, userId string) (*webuserdomain.Stakeholders, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT s.stakeholder_id, s.stakeholder_type, COALESCE(c.code, sp.code), COALESCE(c.name, sp.name) "+
+		"FROM web_user_stakeholder s "+
+		"LEFT JOIN customer c ON c.id = s.stakeholder_id AND s.stakeholder_type = ? "+
+		"LEFT JOIN supplier sp ON sp.id = s.stakeholder_id AND s.stakeholder_type = ? "+
+		"WHERE s.web_user_id = ? "+
+		"ORDER BY 4", webuserdomain.STAKEHOLDER_TYPE_CUSTOMER, webuserdomain.STAKEHOLDER_TYPE_SUPPLIER, userId).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stakeholders := &webuserdomain.Stakeholders{
+		Customers: []*webuserdomain.StakeholderAssignment{},
+		Suppliers: []*webuserdomain.StakeholderAssignment{},
+	}
+	for rows.Next() {
+		var Code sql.NullString
+		var Name sql.NullString
+		assignment := &webuserdomain.StakeholderAssignment{}
+		rows.Scan(&assignment.ID, &assignment.Type, &Code, &Name)
+		assignment.Code = Code.String
+		assignment.Name = Name.String
+
+		if assignment.Type == webuserdomain.STAKEHOLDER_TYPE_SUPPLIER {
+			stakeholders.Suppliers = append(stakeholders.Suppliers, assignment)
+		} else {
+			stakeholders.Customers = append(stakeholders.Customers, assignment)
+		}
+	}
+	return stakeholders, nil
+}
+
+// ReplaceStakeholders assigns exactly the given customers and suppliers to the user, unknown ids are skipped
+func (r *Repo) ReplaceStakeholders(ctx context.Context, userId string, customerIds, supplierIds []string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := tx.Exec("DELETE FROM web_user_stakeholder WHERE web_user_id=?;", userId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, id := range customerIds {
+		err := tx.Exec("INSERT INTO web_user_stakeholder(web_user_id, stakeholder_id, stakeholder_type) "+
+			"SELECT ?, id, ? FROM customer WHERE id=? ON CONFLICT DO NOTHING;",
+			userId, webuserdomain.STAKEHOLDER_TYPE_CUSTOMER, id).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, id := range supplierIds {
+		err := tx.Exec("INSERT INTO web_user_stakeholder(web_user_id, stakeholder_id, stakeholder_type) "+
+			"SELECT ?, id, ? FROM supplier WHERE id=? ON CONFLICT DO NOTHING;",
+			userId, webuserdomain.STAKEHOLDER_TYPE_SUPPLIER, id).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+func (r *Repo) FindManagedUsers(ctx context.Context, supervisorId string) ([]*webuserdomain.ManagedUser, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT wu.id, wu.username, wu.name FROM web_user_supervisor s "+
+		"JOIN web_user wu ON wu.id = s.web_user_id WHERE s.supervisor_id = ? ORDER BY wu.name", supervisorId).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*webuserdomain.ManagedUser{}
+	for rows.Next() {
+		var Name sql.NullString
+		user := &webuserdomain.ManagedUser{}
+		rows.Scan(&user.ID, &user.Username, &Name)
+		user.Name = Name.String
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// ReplaceManagedUsers sets exactly the given users as managed by the supervisor, unknown ids and the supervisor itself are skipped
+func (r *Repo) ReplaceManagedUsers(ctx context.Context, supervisorId string, userIds []string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := tx.Exec("DELETE FROM web_user_supervisor WHERE supervisor_id=?;", supervisorId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, id := range userIds {
+		err := tx.Exec("INSERT INTO web_user_supervisor(supervisor_id, web_user_id) "+
+			"SELECT ?, id FROM web_user WHERE id=? AND id<>? ON CONFLICT DO NOTHING;", supervisorId, id, supervisorId).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+// FindBranches returns the branches assigned to the user ordered by code
+func (r *Repo) FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error) {
+	rows, err := tenantutil.DB(ctx).Raw("SELECT b.id, b.code, b.name, b.code_prefix, b.address, b.active FROM web_user_branch ub "+
+		"JOIN branch b ON b.id = ub.branch_id WHERE ub.web_user_id = ? ORDER BY b.code", userId).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := []*branchdomain.Branch{}
+	for rows.Next() {
+		var CodePrefix sql.NullString
+		var Address sql.NullString
+		branch := &branchdomain.Branch{}
+		rows.Scan(&branch.ID, &branch.Code, &branch.Name, &CodePrefix, &Address, &branch.Active)
+		branch.CodePrefix = CodePrefix.String
+		branch.Address = Address.String
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// ReplaceBranches sets exactly the given branches as assigned to the user, unknown ids are skipped
+func (r *Repo) ReplaceBranches(ctx context.Context, userId string, branchIds []string) error {
+	tx := tenantutil.DB(ctx).Begin()
+	if err := tx.Exec("DELETE FROM web_user_branch WHERE web_user_id=?;", userId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, id := range branchIds {
+		err := tx.Exec("INSERT INTO web_user_branch(web_user_id, branch_id) "+
+			"SELECT ?, id FROM branch WHERE id=? ON CONFLICT DO NOTHING;", userId, id).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+// insertRecoveryCodes replaces the recovery codes of the user inside tx
+func insertRecoveryCodes(tx *gorm.DB, userId string, codeHashes []string) error {
+	if err := tx.Exec("DELETE FROM web_user_recovery_code WHERE web_user_id=?;", userId).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		err := tx.Exec("INSERT INTO web_user_recovery_code(id, web_user_id, code_hash, created_time) VALUES (?, ?, ?, ?);",
+			strings.ReplaceAll(uuid.NewString(), "-", ""), userId, codeHash, now).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
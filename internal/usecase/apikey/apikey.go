@@ -0,0 +1,196 @@
+package apikeyusecase
+
+import (
+	"context"
+	apikeydomain "dromatech/pos-backend/internal/domain/apikey"
+	roledomain "dromatech/pos-backend/internal/domain/role"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	apikeyutil "dromatech/pos-backend/internal/util/apikey"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApiKeyUsecase interface {
+	FindKeys(ctx context.Context, userId string) ([]*apikeydomain.ApiKey, error)
+	CreateKey(ctx context.Context, creatorId, userId, name string, permissions []string, expiredDate string) (*apikeydomain.IssuedKey, error)
+	RotateKey(ctx context.Context, id string) (*apikeydomain.IssuedKey, error)
+	RevokeKey(ctx context.Context, id string) error
+}
+
+type Usecase struct {
+	apiKeyRepo       apiKeyRepo
+	webuserRepo      webuserRepo
+	roleRepo         roleRepo
+	sessionRefresher sessionRefresher
+}
+
+// sessionRefresher drops the cached key so a rotated or revoked key stops working at once
+type sessionRefresher interface {
+	RefreshApiKey(ctx context.Context, id string)
+}
+
+type apiKeyRepo interface {
+	Find(ctx context.Context, id string) *apikeydomain.ApiKey
+	FindByUser(ctx context.Context, userId string) ([]*apikeydomain.ApiKey, error)
+	Create(ctx context.Context, apiKey *apikeydomain.ApiKey) error
+	Rotate(ctx context.Context, id string, keyHash string, rotatedTime time.Time) error
+	Revoke(ctx context.Context, id string, revokedTime time.Time) error
+}
+
+type webuserRepo interface {
+	Find(ctx context.Context, id string) *webuserdomain.WebUser
+}
+
+type roleRepo interface {
+	FindPermissionsByRoleId(ctx context.Context, roleId string) ([]*roledomain.Permission, error)
+}
+
+func New(apiKeyRepo apiKeyRepo, webuserRepo webuserRepo, roleRepo roleRepo, sessionRefresher sessionRefresher) *Usecase {
+	uc := &Usecase{
+		apiKeyRepo:       apiKeyRepo,
+		webuserRepo:      webuserRepo,
+		roleRepo:         roleRepo,
+		sessionRefresher: sessionRefresher,
+	}
+
+	return uc
+}
+
+func (uc *Usecase) FindKeys(ctx context.Context, userId string) ([]*apikeydomain.ApiKey, error) {
+	if _, err := uc.findServiceAccount(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := uc.apiKeyRepo.FindByUser(ctx, userId)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return apiKeys, nil
+}
+
+// CreateKey issues a key for the service account, it is valid through expiredDate or until revoked when expiredDate is empty
+func (uc *Usecase) CreateKey(ctx context.Context, creatorId, userId, name string, permissions []string, expiredDate string) (*apikeydomain.IssuedKey, error) {
+	webuser, err := uc.findServiceAccount(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiredTime *time.Time
+	if expiredDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", expiredDate, time.Local)
+		if err != nil {
+			return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DATE_INVALID))
+		}
+		// the key works through the whole expiry date
+		endOfDate := date.AddDate(0, 0, 1)
+		if !endOfDate.After(time.Now()) {
+			return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_API_KEY_EXPIRY_INVALID))
+		}
+		expiredTime = &endOfDate
+	}
+
+	granted, err := uc.roleRepo.FindPermissionsByRoleId(ctx, webuser.RoleId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_ROLE_FETCH), err)
+	}
+	grantedMap := make(map[string]bool)
+	for _, permission := range granted {
+		grantedMap[permission.ID] = true
+	}
+	for _, permission := range permissions {
+		if !grantedMap[permission] {
+			return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_API_KEY_PERMISSION_INVALID, permission))
+		}
+	}
+
+	apiKey := &apikeydomain.ApiKey{
+		ID:          strings.ReplaceAll(uuid.NewString(), "-", ""),
+		WebUserID:   webuser.ID,
+		Username:    webuser.Username,
+		Name:        name,
+		Permissions: permissions,
+		ExpiredTime: expiredTime,
+		CreatedBy:   creatorId,
+		CreatedTime: time.Now(),
+	}
+	key, hash, err := apikeyutil.Generate(apiKey.ID)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_API_KEY_SAVE), err)
+	}
+	apiKey.KeyHash = hash
+
+	err = uc.apiKeyRepo.Create(ctx, apiKey)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_API_KEY_SAVE), err)
+	}
+
+	return &apikeydomain.IssuedKey{ApiKey: apiKey, Key: key}, nil
+}
+
+// RotateKey replaces the secret of the key keeping its permissions and expiry, the old secret stops working at once
+func (uc *Usecase) RotateKey(ctx context.Context, id string) (*apikeydomain.IssuedKey, error) {
+	apiKey, err := uc.findActiveKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, hash, err := apikeyutil.Generate(apiKey.ID)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_API_KEY_SAVE), err)
+	}
+
+	now := time.Now()
+	err = uc.apiKeyRepo.Rotate(ctx, apiKey.ID, hash, now)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_API_KEY_SAVE), err)
+	}
+	uc.sessionRefresher.RefreshApiKey(ctx, apiKey.ID)
+
+	apiKey.KeyHash = hash
+	apiKey.RotatedTime = &now
+	return &apikeydomain.IssuedKey{ApiKey: apiKey, Key: key}, nil
+}
+
+func (uc *Usecase) RevokeKey(ctx context.Context, id string) error {
+	apiKey, err := uc.findActiveKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = uc.apiKeyRepo.Revoke(ctx, apiKey.ID, time.Now())
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_API_KEY_SAVE), err)
+	}
+	uc.sessionRefresher.RefreshApiKey(ctx, apiKey.ID)
+	return nil
+}
+
+func (uc *Usecase) findServiceAccount(ctx context.Context, userId string) (*webuserdomain.WebUser, error) {
+	webuser := uc.webuserRepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" || !webuser.ServiceAccount {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_SERVICE_ACCOUNT_SELECT))
+	}
+	return webuser, nil
+}
+
+func (uc *Usecase) findActiveKey(ctx context.Context, id string) (*apikeydomain.ApiKey, error) {
+	apiKey := uc.apiKeyRepo.Find(ctx, id)
+	if apiKey == nil {
+		return nil, restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_API_KEY_NOT_FOUND))
+	}
+	if apiKey.RevokedTime != nil {
+		return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_API_KEY_REVOKED))
+	}
+	return apiKey, nil
+}
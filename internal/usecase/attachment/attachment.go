@@ -0,0 +1,353 @@
+package attachmentusecase
+
+import (
+	"bytes"
+	"context"
+	attachmentdomain "dromatech/pos-backend/internal/domain/attachment"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	imageutil "dromatech/pos-backend/internal/util/image"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	storageutil "dromatech/pos-backend/internal/util/storage"
+	stringutil "dromatech/pos-backend/internal/util/string"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_MAX_SIZE_KB = 5120
+	THUMBNAIL_SIZE      = 256
+	MAX_FILE_NAME       = 255
+)
+
+// allowedTypes are the detected content types accepted for upload with the extension they are stored with
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+// ownerEntries maps the owner types kept in the penjualan_tunai, belanja and operasional tables to their table
+var ownerEntries = map[string]string{
+	attachmentdomain.OWNER_TYPE_BELANJA:     transactiondomain.MobileEntryBelanja,
+	attachmentdomain.OWNER_TYPE_OPERASIONAL: transactiondomain.MobileEntryOperasional,
+}
+
+type AttachmentUsecase interface {
+	Upload(ctx context.Context, userID, ownerType, ownerID, fileName string, size int64, file io.Reader) (*attachmentdomain.Attachment, error)
+	FindByOwner(ctx context.Context, userID, ownerType, ownerID string) ([]attachmentdomain.Attachment, error)
+	FindAllByOwner(ctx context.Context, ownerType, ownerID string) ([]attachmentdomain.Attachment, error)
+	Download(ctx context.Context, userID, id string, thumbnail bool) (*attachmentdomain.Attachment, io.ReadCloser, error)
+	DownloadAny(ctx context.Context, id string, thumbnail bool) (*attachmentdomain.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type Usecase struct {
+	attachmentRepo  attachmentRepo
+	transactionRepo transactionRepo
+	cashLock        cashLock
+	configRepo      configRepo
+	storage         storageutil.Storage
+}
+
+type attachmentRepo interface {
+	Find(ctx context.Context, id string) (*attachmentdomain.Attachment, error)
+	FindByOwner(ctx context.Context, ownerType string, ownerIDs []string) (map[string][]attachmentdomain.Attachment, error)
+	Create(ctx context.Context, attachment *attachmentdomain.Attachment) error
+	Delete(ctx context.Context, id string) error
+}
+
+type transactionRepo interface {
+	FindMobileEntry(ctx context.Context, table string, id string) (*transactiondomain.MobileEntry, error)
+	FindDanaTransfer(ctx context.Context, id string) (*transactiondomain.DanaTransaction, error)
+}
+
+// cashLock tells whether a day of a mobile user is still open, the rule is the one of the cash closing
+type cashLock interface {
+	CheckOpen(ctx context.Context, userID string, date time.Time) error
+}
+
+type configRepo interface {
+	GetValue(ctx context.Context, key string) string
+}
+
+func New(attachmentRepo attachmentRepo, transactionRepo transactionRepo, cashLock cashLock, configRepo configRepo, storage storageutil.Storage) *Usecase {
+	uc := &Usecase{
+		attachmentRepo:  attachmentRepo,
+		transactionRepo: transactionRepo,
+		cashLock:        cashLock,
+		configRepo:      configRepo,
+		storage:         storage,
+	}
+
+	return uc
+}
+
+// owner is the record an attachment is linked to with the users allowed to see its attachments
+type owner struct {
+	date  time.Time
+	users []string
+}
+
+func (o *owner) allows(userID string) bool {
+	for _, user := range o.users {
+		if user == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Upload stores the file and links it to a record of the user, a thumbnail is made for images. The size the client sent is
+// checked before the file is read and no more than the limit is read, the proof of a closed day cannot be added to
+func (uc *Usecase) Upload(ctx context.Context, userID, ownerType, ownerID, fileName string, size int64, file io.Reader) (*attachmentdomain.Attachment, error) {
+	maxSizeKB := uc.configInt(ctx, configdomain.ATTACHMENT_MAX_SIZE_KB, DEFAULT_MAX_SIZE_KB)
+	maxSize := int64(maxSizeKB) * 1024
+	if size > maxSize {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_TOO_LARGE, maxSizeKB))
+	}
+
+	owner, err := uc.findOwner(ctx, userID, ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkOpen(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_FILE_REQUIRED))
+	}
+	if len(data) == 0 {
+		return nil, restutil.ErrRequired("file", i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_FILE_REQUIRED))
+	}
+	if int64(len(data)) > maxSize {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_TOO_LARGE, maxSizeKB))
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_TYPE_INVALID))
+	}
+
+	id := stringutil.GenerateUUID()
+	attachment := &attachmentdomain.Attachment{
+		ID:           id,
+		OwnerType:    ownerType,
+		OwnerID:      ownerID,
+		FileName:     cleanFileName(fileName, id+extension),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		StorageKey:   ownerType + "/" + id[:2] + "/" + id + extension,
+		UploadedBy:   userID,
+		UploadedTime: time.Now(),
+	}
+
+	if err := uc.storage.Save(ctx, attachment.StorageKey, bytes.NewReader(data)); err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_SAVE), err)
+	}
+
+	// a failed thumbnail leaves the attachment without one, the file itself is kept
+	if strings.HasPrefix(contentType, "image/") {
+		thumbnail, err := imageutil.Thumbnail(data, THUMBNAIL_SIZE)
+		if err == nil {
+			thumbnailKey := ownerType + "/" + id[:2] + "/" + id + "_thumb.jpg"
+			err = uc.storage.Save(ctx, thumbnailKey, bytes.NewReader(thumbnail))
+			if err == nil {
+				attachment.ThumbnailKey = thumbnailKey
+				attachment.HasThumbnail = true
+			}
+		}
+		if err != nil {
+			logutil.WithContext(ctx).Warn(err.Error())
+		}
+	}
+
+	if err := uc.attachmentRepo.Create(ctx, attachment); err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		uc.removeFiles(ctx, attachment)
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_SAVE), err)
+	}
+
+	return attachment, nil
+}
+
+func (uc *Usecase) FindByOwner(ctx context.Context, userID, ownerType, ownerID string) ([]attachmentdomain.Attachment, error) {
+	if _, err := uc.findOwner(ctx, userID, ownerType, ownerID); err != nil {
+		return nil, err
+	}
+	return uc.FindAllByOwner(ctx, ownerType, ownerID)
+}
+
+// FindAllByOwner lists the attachments of any user, the route permission is the check
+func (uc *Usecase) FindAllByOwner(ctx context.Context, ownerType, ownerID string) ([]attachmentdomain.Attachment, error) {
+	attachments, err := uc.attachmentRepo.FindByOwner(ctx, ownerType, []string{ownerID})
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if attachments[ownerID] == nil {
+		return []attachmentdomain.Attachment{}, nil
+	}
+	return attachments[ownerID], nil
+}
+
+// Download opens an attachment of a record of the user
+func (uc *Usecase) Download(ctx context.Context, userID, id string, thumbnail bool) (*attachmentdomain.Attachment, io.ReadCloser, error) {
+	attachment, err := uc.findAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := uc.findOwner(ctx, userID, attachment.OwnerType, attachment.OwnerID); err != nil {
+		return nil, nil, err
+	}
+	return uc.open(ctx, attachment, thumbnail)
+}
+
+// DownloadAny opens an attachment of any user, the route permission is the check
+func (uc *Usecase) DownloadAny(ctx context.Context, id string, thumbnail bool) (*attachmentdomain.Attachment, io.ReadCloser, error) {
+	attachment, err := uc.findAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return uc.open(ctx, attachment, thumbnail)
+}
+
+// Delete removes an attachment of a record of the user, the proof of a closed day is kept
+func (uc *Usecase) Delete(ctx context.Context, userID, id string) error {
+	attachment, err := uc.findAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	owner, err := uc.findOwner(ctx, userID, attachment.OwnerType, attachment.OwnerID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkOpen(ctx, owner); err != nil {
+		return err
+	}
+
+	if err := uc.attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_SAVE), err)
+	}
+	uc.removeFiles(ctx, attachment)
+	return nil
+}
+
+// checkOpen refuses a change to the attachments of a record on a day closed by any of its users
+func (uc *Usecase) checkOpen(ctx context.Context, owner *owner) error {
+	for _, user := range owner.users {
+		if err := uc.cashLock.CheckOpen(ctx, user, owner.date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *Usecase) findAttachment(ctx context.Context, id string) (*attachmentdomain.Attachment, error) {
+	attachment, err := uc.attachmentRepo.Find(ctx, id)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if attachment == nil {
+		return nil, restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_NOT_FOUND))
+	}
+	return attachment, nil
+}
+
+// findOwner resolves the record an attachment is linked to, the user must be its owner or, for a transfer, its sender or receiver
+func (uc *Usecase) findOwner(ctx context.Context, userID, ownerType, ownerID string) (*owner, error) {
+	var found *owner
+	if table, ok := ownerEntries[ownerType]; ok {
+		entry, err := uc.transactionRepo.FindMobileEntry(ctx, table, ownerID)
+		if err != nil {
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+		if entry != nil {
+			found = &owner{date: entry.Date, users: []string{entry.WebUserID}}
+		}
+	} else if ownerType == attachmentdomain.OWNER_TYPE_DANA_TRANSFER {
+		transfer, err := uc.transactionRepo.FindDanaTransfer(ctx, ownerID)
+		if err != nil {
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+		if transfer != nil {
+			found = &owner{date: transfer.Date, users: []string{transfer.Sender, transfer.Receiver}}
+		}
+	} else {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_OWNER_TYPE_INVALID))
+	}
+
+	if found == nil {
+		return nil, restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_OWNER_NOT_FOUND))
+	}
+	if !found.allows(userID) {
+		return nil, restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_NOT_ALLOWED))
+	}
+	return found, nil
+}
+
+func (uc *Usecase) open(ctx context.Context, attachment *attachmentdomain.Attachment, thumbnail bool) (*attachmentdomain.Attachment, io.ReadCloser, error) {
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, nil, restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_NOT_FOUND))
+		}
+		key = attachment.ThumbnailKey
+	}
+
+	reader, err := uc.storage.Open(ctx, key)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_ATTACHMENT_NOT_FOUND), err)
+	}
+	return attachment, reader, nil
+}
+
+func (uc *Usecase) removeFiles(ctx context.Context, attachment *attachmentdomain.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := uc.storage.Delete(ctx, key); err != nil {
+			logutil.WithContext(ctx).Warn(err.Error())
+		}
+	}
+}
+
+func (uc *Usecase) configInt(ctx context.Context, key string, defaultValue int) int {
+	value, err := strconv.Atoi(uc.configRepo.GetValue(ctx, key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// cleanFileName keeps the base name the client sent, for display and the download name only
+func cleanFileName(fileName, fallback string) string {
+	name := filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return fallback
+	}
+	if len(name) > MAX_FILE_NAME {
+		name = name[len(name)-MAX_FILE_NAME:]
+	}
+	return name
+}
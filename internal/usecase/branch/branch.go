@@ -0,0 +1,125 @@
+package branchusecase
+
+import (
+	"context"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	branchrepo "dromatech/pos-backend/internal/repo/branch"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// codePrefixPattern keeps the prefix readable in a code and free of the slash that separates its parts
+var codePrefixPattern = regexp.MustCompile(`^[A-Za-z0-9-]{0,16}$`)
+
+type BranchUsecase interface {
+	Find(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*branchdomain.Branch, int64, error)
+	Create(ctx context.Context, request branchdomain.BranchRequest) error
+	Edit(ctx context.Context, request branchdomain.BranchRequest) error
+}
+
+type Usecase struct {
+	branchRepo branchrepo.BranchRepo
+}
+
+func New(branchRepo branchrepo.BranchRepo) *Usecase {
+	uc := &Usecase{
+		branchRepo: branchRepo,
+	}
+
+	return uc
+}
+
+func (uc *Usecase) Find(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*branchdomain.Branch, int64, error) {
+	var param []queryutil.Param
+	if id != "" {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "id", Operator: "=", Value: id})
+	}
+	if code != "" {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "code", Operator: "ILIKE", Value: queryutil.Contains(code)})
+	}
+	if active != nil {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "active", Operator: "=", Value: *active})
+	}
+
+	branches, total, err := uc.branchRepo.FindList(ctx, param, list)
+	if err != nil {
+		return nil, 0, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return branches, total, nil
+}
+
+func (uc *Usecase) Create(ctx context.Context, request branchdomain.BranchRequest) error {
+	if request.Code == "" {
+		return restutil.ErrRequired("code", i18nutil.T(ctx, i18nutil.ERR_BRANCH_CODE_REQUIRED))
+	}
+	if request.Name == "" {
+		return restutil.ErrRequired("name", i18nutil.T(ctx, i18nutil.ERR_BRANCH_NAME_REQUIRED))
+	}
+	if !codePrefixPattern.MatchString(request.CodePrefix) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_BRANCH_PREFIX_INVALID))
+	}
+
+	existing, err := uc.branchRepo.FindByCode(ctx, request.Code)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BRANCH_SAVE), err)
+	}
+	if existing != nil {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_BRANCH_CODE_EXISTS, request.Code))
+	}
+
+	branch := &branchdomain.Branch{
+		ID:         strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Code:       request.Code,
+		Name:       request.Name,
+		CodePrefix: strings.ToUpper(request.CodePrefix),
+		Address:    request.Address,
+		Active:     true,
+	}
+	if err := uc.branchRepo.Create(ctx, branch); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BRANCH_SAVE), err)
+	}
+	return nil
+}
+
+// Edit changes the branch, an empty code or name keeps the current one while the prefix and address are replaced as given
+func (uc *Usecase) Edit(ctx context.Context, request branchdomain.BranchRequest) error {
+	branch, err := uc.branchRepo.Find(ctx, request.ID)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BRANCH_SAVE), err)
+	}
+	if branch == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_BRANCH_SELECT))
+	}
+	if !codePrefixPattern.MatchString(request.CodePrefix) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_BRANCH_PREFIX_INVALID))
+	}
+
+	if request.Code != "" && request.Code != branch.Code {
+		existing, err := uc.branchRepo.FindByCode(ctx, request.Code)
+		if err != nil {
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BRANCH_SAVE), err)
+		}
+		if existing != nil {
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_BRANCH_CODE_EXISTS, request.Code))
+		}
+		branch.Code = request.Code
+	}
+	if request.Name != "" {
+		branch.Name = request.Name
+	}
+	branch.CodePrefix = strings.ToUpper(request.CodePrefix)
+	branch.Address = request.Address
+	if request.Active != nil {
+		branch.Active = *request.Active
+	}
+
+	if err := uc.branchRepo.Edit(ctx, branch); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BRANCH_SAVE), err)
+	}
+	return nil
+}
@@ -1,24 +1,27 @@
 package customerusecase
 
 import (
+	"context"
 	customerdomain "dromatech/pos-backend/internal/domain/customer"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
-	"fmt"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	taxutil "dromatech/pos-backend/internal/util/tax"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type CustmerUsecase interface {
-	Find(id, code, name string, active *bool) ([]*customerdomain.Customer, error)
-	Create(code, name, description string, initialBalance int64) error
-	Edit(id, code, name, description string, active bool, initialBalance int64) error
-	GetSellPrice(customerId, unitId, date, productId string) ([]*customerdomain.SellPriceResponse, error)
-	UpdateSellPrice(request customerdomain.SellPriceRequest) error
-	AddSellPrice(entity customerdomain.AddPriceRequest, userId string) error
-	FindSellPrice(customerId, unitId, productId string, latest *bool) ([]*customerdomain.PriceResponse, error)
+	Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*customerdomain.Customer, int64, error)
+	Create(ctx context.Context, code, name, description, npwp, address string, initialBalance int64, tax customerdomain.Tax) error
+	Edit(ctx context.Context, id, code, name, description, npwp, address string, active bool, initialBalance int64, tax customerdomain.Tax) error
+	GetSellPrice(ctx context.Context, customerId, unitId, date, productId string) ([]*customerdomain.SellPriceResponse, error)
+	UpdateSellPrice(ctx context.Context, request customerdomain.SellPriceRequest) error
+	AddSellPrice(ctx context.Context, entity customerdomain.AddPriceRequest, userId string) error
+	FindSellPrice(ctx context.Context, customerId, unitId, productId string, latest *bool) ([]*customerdomain.PriceResponse, error)
 }
 
 type Usecase struct {
@@ -26,14 +29,15 @@ type Usecase struct {
 }
 
 type customerRepo interface {
-	Find(params map[string]interface{}) ([]*customerdomain.Customer, error)
-	Create(product *customerdomain.Customer) error
-	Edit(product *customerdomain.Customer) error
-	GetSellPrice(params []queryutil.Param) ([]*customerdomain.SellPriceResponse, error)
-	UpdateSellPrice(request customerdomain.SellPriceRequest) error
-	DeleteSellPrice(supplierId, date string) error
-	AddSellPrice(entity customerdomain.AddPriceRequest) error
-	FindSellPrice(params []queryutil.Param) ([]*customerdomain.PriceResponse, error)
+	Find(ctx context.Context, params map[string]interface{}) ([]*customerdomain.Customer, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*customerdomain.Customer, int64, error)
+	Create(ctx context.Context, product *customerdomain.Customer) error
+	Edit(ctx context.Context, product *customerdomain.Customer) error
+	GetSellPrice(ctx context.Context, params []queryutil.Param) ([]*customerdomain.SellPriceResponse, error)
+	UpdateSellPrice(ctx context.Context, request customerdomain.SellPriceRequest) error
+	DeleteSellPrice(ctx context.Context, supplierId, date string) error
+	AddSellPrice(ctx context.Context, entity customerdomain.AddPriceRequest) error
+	FindSellPrice(ctx context.Context, params []queryutil.Param) ([]*customerdomain.PriceResponse, error)
 }
 
 func New(customerRepo customerRepo) *Usecase {
@@ -44,32 +48,60 @@ func New(customerRepo customerRepo) *Usecase {
 	return uc
 }
 
-func (uc *Usecase) Find(id, code, name string, active *bool) ([]*customerdomain.Customer, error) {
-	param := make(map[string]interface{})
+func (uc *Usecase) Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*customerdomain.Customer, int64, error) {
+	var param []queryutil.Param
 	if id != "" {
-		param["id"] = id
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "id",
+			Operator: "=",
+			Value:    id,
+		})
 	}
 	if code != "" {
-		param["code"] = code
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "code",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(code),
+		})
 	}
 	if name != "" {
-		param["name"] = name
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "name",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(name),
+		})
 	}
 	if active != nil {
-		param["active"] = *active
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "active",
+			Operator: "=",
+			Value:    *active,
+		})
 	}
-	return uc.customerRepo.Find(param)
+	return uc.customerRepo.FindList(ctx, param, list)
 }
 
-func (uc *Usecase) Create(code, name, description string, initialCredit int64) error {
-	entities, err := uc.customerRepo.Find(map[string]interface{}{"code": code})
+func (uc *Usecase) Create(ctx context.Context, code, name, description, npwp, address string, initialCredit int64, tax customerdomain.Tax) error {
+	if !taxutil.ValidRate(tax.TaxRate) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TAX_RATE_INVALID))
+	}
+	npwp, ok := taxutil.Npwp(npwp)
+	if !ok {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_NPWP_INVALID))
+	}
+
+	entities, err := uc.customerRepo.Find(ctx, map[string]interface{}{"code": code})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data customer")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_CREATE), err)
 	}
 
 	if entities != nil || len(entities) > 0 {
-		return fmt.Errorf("Customer dengan kode %s sudah terdaftar", code)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_CODE_EXISTS, code))
 	}
 
 	id := strings.ReplaceAll(uuid.NewString(), "-", "")
@@ -81,40 +113,51 @@ func (uc *Usecase) Create(code, name, description string, initialCredit int64) e
 		Description:   description,
 		Active:        true,
 		InitialCredit: initialCredit,
+		Npwp:          npwp,
+		Address:       address,
+		Tax:           tax,
 	}
 
-	err = uc.customerRepo.Create(entity)
+	err = uc.customerRepo.Create(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data customer")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) Edit(id, code, name, description string, active bool, initialCredit int64) error {
-	entities, err := uc.customerRepo.Find(map[string]interface{}{"id": id})
+func (uc *Usecase) Edit(ctx context.Context, id, code, name, description, npwp, address string, active bool, initialCredit int64, tax customerdomain.Tax) error {
+	if !taxutil.ValidRate(tax.TaxRate) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TAX_RATE_INVALID))
+	}
+	npwp, ok := taxutil.Npwp(npwp)
+	if !ok {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_NPWP_INVALID))
+	}
+
+	entities, err := uc.customerRepo.Find(ctx, map[string]interface{}{"id": id})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data customer")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_UPDATE), err)
 	}
 
 	if len(entities) != 1 {
-		logrus.Errorf("Product with id %s more than 1", id)
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data customer")
+		logutil.WithContext(ctx).Errorf("Product with id %s more than 1", id)
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_UPDATE), nil)
 	}
 
 	entity := entities[0]
 
 	if code != entity.Code {
-		products, err := uc.customerRepo.Find(map[string]interface{}{"code": code})
+		products, err := uc.customerRepo.Find(ctx, map[string]interface{}{"code": code})
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data customer")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_UPDATE), err)
 		}
 
 		if products != nil || len(products) > 0 {
-			return fmt.Errorf("Produk dengan kode %s sudah terdaftar", code)
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CODE_EXISTS, code))
 		}
 	}
 
@@ -123,17 +166,20 @@ func (uc *Usecase) Edit(id, code, name, description string, active bool, initial
 	entity.Description = description
 	entity.Active = active
 	entity.InitialCredit = initialCredit
+	entity.Npwp = npwp
+	entity.Address = address
+	entity.Tax = tax
 
-	err = uc.customerRepo.Edit(entity)
+	err = uc.customerRepo.Edit(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data customer")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_UPDATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) GetSellPrice(customerId, unitId, date, productId string) ([]*customerdomain.SellPriceResponse, error) {
+func (uc *Usecase) GetSellPrice(ctx context.Context, customerId, unitId, date, productId string) ([]*customerdomain.SellPriceResponse, error) {
 	var param []queryutil.Param
 	if customerId != "" {
 		param = append(param, queryutil.Param{
@@ -168,53 +214,53 @@ func (uc *Usecase) GetSellPrice(customerId, unitId, date, productId string) ([]*
 		})
 	}
 
-	entities, err := uc.customerRepo.GetSellPrice(param)
+	entities, err := uc.customerRepo.GetSellPrice(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_FIND), err)
 	}
 
 	return entities, nil
 }
 
-func (uc *Usecase) UpdateSellPrice(request customerdomain.SellPriceRequest) error {
+func (uc *Usecase) UpdateSellPrice(ctx context.Context, request customerdomain.SellPriceRequest) error {
 	if request.CustomerId == "" {
-		return fmt.Errorf("Harap pilih customer")
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_REQUIRED))
 	}
 	if request.UnitId == "" {
-		return fmt.Errorf("Harap pilih satuan")
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_UNIT_REQUIRED))
 	}
 
-	err := uc.customerRepo.DeleteSellPrice(request.CustomerId, request.Date)
+	err := uc.customerRepo.DeleteSellPrice(ctx, request.CustomerId, request.Date)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_UPDATE), err)
 	}
 
-	err = uc.customerRepo.UpdateSellPrice(request)
+	err = uc.customerRepo.UpdateSellPrice(ctx, request)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_UPDATE), err)
 	}
 	return nil
 }
 
-func (uc *Usecase) AddSellPrice(entity customerdomain.AddPriceRequest, userId string) error {
+func (uc *Usecase) AddSellPrice(ctx context.Context, entity customerdomain.AddPriceRequest, userId string) error {
 	entity.ID = strings.ReplaceAll(uuid.NewString(), "-", "")
 	entity.Date = time.Now()
 	entity.WebUserId = userId
 	entity.Latest = true
 
-	err := uc.customerRepo.AddSellPrice(entity)
+	err := uc.customerRepo.AddSellPrice(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat menambahkan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) FindSellPrice(customerId, unitId, productId string, latest *bool) ([]*customerdomain.PriceResponse, error) {
+func (uc *Usecase) FindSellPrice(ctx context.Context, customerId, unitId, productId string, latest *bool) ([]*customerdomain.PriceResponse, error) {
 	var param []queryutil.Param
 	if customerId != "" {
 		param = append(param, queryutil.Param{
@@ -248,10 +294,10 @@ func (uc *Usecase) FindSellPrice(customerId, unitId, productId string, latest *b
 			Value:    strconv.FormatBool(*latest),
 		})
 	}
-	entities, err := uc.customerRepo.FindSellPrice(param)
+	entities, err := uc.customerRepo.FindSellPrice(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarion data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_FIND), err)
 	}
 	return entities, nil
 }
@@ -0,0 +1,220 @@
+package expenseusecase
+
+import (
+	"context"
+	expensedomain "dromatech/pos-backend/internal/domain/expense"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	expenserepo "dromatech/pos-backend/internal/repo/expense"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type ExpenseUsecase interface {
+	FindCategory(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*expensedomain.Category, int64, error)
+	CreateCategory(ctx context.Context, code, name string) error
+	EditCategory(ctx context.Context, id, code, name string, active *bool) error
+	FindBudget(ctx context.Context, categoryID, webUserID string, list queryutil.ListParam) ([]*expensedomain.Budget, int64, error)
+	CreateBudget(ctx context.Context, request expensedomain.BudgetRequest) error
+	EditBudget(ctx context.Context, request expensedomain.BudgetRequest) error
+	DeleteBudget(ctx context.Context, id string) error
+}
+
+type Usecase struct {
+	expenseRepo expenserepo.ExpenseRepo
+	webuserRepo webuserRepo
+}
+
+type webuserRepo interface {
+	Find(ctx context.Context, id string) *webuserdomain.WebUser
+}
+
+func New(expenseRepo expenserepo.ExpenseRepo, webuserRepo webuserRepo) *Usecase {
+	uc := &Usecase{
+		expenseRepo: expenseRepo,
+		webuserRepo: webuserRepo,
+	}
+
+	return uc
+}
+
+func (uc *Usecase) FindCategory(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*expensedomain.Category, int64, error) {
+	var param []queryutil.Param
+	if id != "" {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "id", Operator: "=", Value: id})
+	}
+	if code != "" {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "code", Operator: "ILIKE", Value: queryutil.Contains(code)})
+	}
+	if active != nil {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "active", Operator: "=", Value: *active})
+	}
+
+	categories, total, err := uc.expenseRepo.FindCategoryList(ctx, param, list)
+	if err != nil {
+		return nil, 0, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return categories, total, nil
+}
+
+func (uc *Usecase) CreateCategory(ctx context.Context, code, name string) error {
+	existing, err := uc.expenseRepo.FindCategoryByCode(ctx, code)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_CREATE), err)
+	}
+	if existing != nil {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_CODE_EXISTS, code))
+	}
+
+	category := &expensedomain.Category{
+		ID:     strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Code:   code,
+		Name:   name,
+		Active: true,
+	}
+	if err := uc.expenseRepo.CreateCategory(ctx, category); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_CREATE), err)
+	}
+	return nil
+}
+
+func (uc *Usecase) EditCategory(ctx context.Context, id, code, name string, active *bool) error {
+	category, err := uc.expenseRepo.FindCategory(ctx, id)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_UPDATE), err)
+	}
+	if category == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_SELECT))
+	}
+
+	if code != "" && code != category.Code {
+		existing, err := uc.expenseRepo.FindCategoryByCode(ctx, code)
+		if err != nil {
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_UPDATE), err)
+		}
+		if existing != nil {
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_CODE_EXISTS, code))
+		}
+		category.Code = code
+	}
+	if name != "" {
+		category.Name = name
+	}
+	if active != nil {
+		category.Active = *active
+	}
+
+	if err := uc.expenseRepo.EditCategory(ctx, category); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_UPDATE), err)
+	}
+	return nil
+}
+
+func (uc *Usecase) FindBudget(ctx context.Context, categoryID, webUserID string, list queryutil.ListParam) ([]*expensedomain.Budget, int64, error) {
+	var param []queryutil.Param
+	if categoryID != "" {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "b.category_id", Operator: "=", Value: categoryID})
+	}
+	if webUserID != "" {
+		param = append(param, queryutil.Param{Logic: "AND", Field: "b.web_user_id", Operator: "=", Value: webUserID})
+	}
+
+	budgets, total, err := uc.expenseRepo.FindBudgetList(ctx, param, list)
+	if err != nil {
+		return nil, 0, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return budgets, total, nil
+}
+
+func (uc *Usecase) CreateBudget(ctx context.Context, request expensedomain.BudgetRequest) error {
+	budget := &expensedomain.Budget{
+		ID:     strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Active: true,
+	}
+	if err := uc.applyBudget(ctx, budget, request); err != nil {
+		return err
+	}
+
+	if err := uc.expenseRepo.CreateBudget(ctx, budget); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_SAVE), err)
+	}
+	return nil
+}
+
+func (uc *Usecase) EditBudget(ctx context.Context, request expensedomain.BudgetRequest) error {
+	budget, err := uc.expenseRepo.FindBudget(ctx, request.ID)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_SAVE), err)
+	}
+	if budget == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_SELECT))
+	}
+	if err := uc.applyBudget(ctx, budget, request); err != nil {
+		return err
+	}
+
+	if err := uc.expenseRepo.EditBudget(ctx, budget); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_SAVE), err)
+	}
+	return nil
+}
+
+func (uc *Usecase) DeleteBudget(ctx context.Context, id string) error {
+	budget, err := uc.expenseRepo.FindBudget(ctx, id)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_DELETE), err)
+	}
+	if budget == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_SELECT))
+	}
+
+	if err := uc.expenseRepo.DeleteBudget(ctx, id); err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_DELETE), err)
+	}
+	return nil
+}
+
+// applyBudget validates the request and copies it to the budget, the category and user are replaced as given since empty means all
+func (uc *Usecase) applyBudget(ctx context.Context, budget *expensedomain.Budget, request expensedomain.BudgetRequest) error {
+	if request.Period != expensedomain.PERIOD_DAILY && request.Period != expensedomain.PERIOD_MONTHLY {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_PERIOD_INVALID))
+	}
+	if request.Mode == "" {
+		request.Mode = expensedomain.MODE_WARN
+	}
+	if request.Mode != expensedomain.MODE_WARN && request.Mode != expensedomain.MODE_REJECT {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_MODE_INVALID))
+	}
+	if request.Amount <= 0 {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_AMOUNT_INVALID))
+	}
+
+	if request.CategoryID != "" {
+		category, err := uc.expenseRepo.FindCategory(ctx, request.CategoryID)
+		if err != nil {
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_SAVE), err)
+		}
+		if category == nil {
+			return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_INVALID))
+		}
+	}
+	if request.WebUserID != "" {
+		webuser := uc.webuserRepo.Find(ctx, request.WebUserID)
+		if webuser == nil || webuser.ID == "" {
+			return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_USER_INVALID))
+		}
+	}
+
+	budget.CategoryID = request.CategoryID
+	budget.WebUserID = request.WebUserID
+	budget.Period = request.Period
+	budget.Amount = request.Amount
+	budget.Mode = request.Mode
+	if request.Active != nil {
+		budget.Active = *request.Active
+	}
+	return nil
+}
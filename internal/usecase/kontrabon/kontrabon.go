@@ -1,52 +1,61 @@
 package kontrabonusecase
 
 import (
-	"dromatech/pos-backend/global"
+	"context"
 	kontrabondomain "dromatech/pos-backend/internal/domain/kontrabon"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
 	customerrepo "dromatech/pos-backend/internal/repo/customer"
 	kontrabonrepo "dromatech/pos-backend/internal/repo/kontrabon"
 	sequencerepo "dromatech/pos-backend/internal/repo/sequence"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
 	stringutil "dromatech/pos-backend/internal/util/string"
-	"fmt"
-	"github.com/sirupsen/logrus"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"strconv"
 	"time"
 )
 
 type KontrabonUsecase interface {
-	Find(code, startDate, endDate, customerId string) ([]*kontrabondomain.KontrabonResponse, error)
-	FindTransaction(kontrabonId string) ([]*transactiondomain.TransactionStatus, error)
-	Create(customerId string, transactionIds []string) error
-	Update(kontrabonId string, transactionIds []string, status string) error
-	UpdateLunas(kontrabonId string, paymentTime time.Time, totalPayment float64, description, paymentDate string) error
+	Find(ctx context.Context, code, startDate, endDate, customerId string, list queryutil.ListParam) ([]*kontrabondomain.KontrabonResponse, int64, error)
+	FindTransaction(ctx context.Context, kontrabonId string) ([]*transactiondomain.TransactionStatus, error)
+	Create(ctx context.Context, customerId string, transactionIds []string) error
+	Update(ctx context.Context, kontrabonId string, transactionIds []string, status string) error
+	UpdateLunas(ctx context.Context, kontrabonId string, paymentTime time.Time, totalPayment float64, description, paymentDate string) error
 }
 
 type Usecase struct {
 	kontrabonRepo kontrabonrepo.KontrabonRepo
 	sequenceRepo  sequencerepo.SequenceRepo
 	customerRepo  customerrepo.CustomerRepo
+	branchRepo    branchRepo
 }
 
-func New(kontrabonRepo kontrabonrepo.KontrabonRepo, sequenceRepo sequencerepo.SequenceRepo, customerRepo customerrepo.CustomerRepo) *Usecase {
+// branchRepo gives the code prefix of the branch in ctx
+type branchRepo interface {
+	CodePrefix(ctx context.Context) (string, error)
+}
+
+func New(kontrabonRepo kontrabonrepo.KontrabonRepo, sequenceRepo sequencerepo.SequenceRepo, customerRepo customerrepo.CustomerRepo, branchRepo branchRepo) *Usecase {
 	uc := &Usecase{
 		kontrabonRepo: kontrabonRepo,
 		sequenceRepo:  sequenceRepo,
 		customerRepo:  customerRepo,
+		branchRepo:    branchRepo,
 	}
 
 	return uc
 }
 
-func (uc *Usecase) Find(code, startDate, endDate, customerId string) ([]*kontrabondomain.KontrabonResponse, error) {
+func (uc *Usecase) Find(ctx context.Context, code, startDate, endDate, customerId string, list queryutil.ListParam) ([]*kontrabondomain.KontrabonResponse, int64, error) {
 	var param []queryutil.Param
 	if code != "" {
 		param = append(param, queryutil.Param{
 			Logic:    "AND",
 			Field:    "k.code",
-			Operator: "=",
-			Value:    code,
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(code),
 		})
 	}
 	if startDate != "" {
@@ -74,10 +83,10 @@ func (uc *Usecase) Find(code, startDate, endDate, customerId string) ([]*kontrab
 		})
 	}
 
-	return uc.kontrabonRepo.Find(param)
+	return uc.kontrabonRepo.FindList(ctx, param, list)
 }
 
-func (uc *Usecase) FindTransaction(kontrabonId string) ([]*transactiondomain.TransactionStatus, error) {
+func (uc *Usecase) FindTransaction(ctx context.Context, kontrabonId string) ([]*transactiondomain.TransactionStatus, error) {
 	var param []queryutil.Param
 	if kontrabonId != "" {
 		param = append(param, queryutil.Param{
@@ -88,63 +97,68 @@ func (uc *Usecase) FindTransaction(kontrabonId string) ([]*transactiondomain.Tra
 		})
 	}
 
-	trx, err := uc.kontrabonRepo.FindTransaction(param)
+	trx, err := uc.kontrabonRepo.FindTransaction(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_FIND), err)
 	}
 
 	return trx, nil
 }
 
-func (uc *Usecase) Create(customerId string, transactionIds []string) error {
-	customer, err := uc.customerRepo.Find(map[string]interface{}{"id": customerId})
+func (uc *Usecase) Create(ctx context.Context, customerId string, transactionIds []string) error {
+	customer, err := uc.customerRepo.Find(ctx, map[string]interface{}{"id": customerId})
 	if err != nil || len(customer) == 0 {
-		return fmt.Errorf("Customer dengan ID %s tidak ditemukan", customerId)
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_ID_NOT_FOUND, customerId))
+	}
+
+	prefix, err := uc.branchRepo.CodePrefix(ctx)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_KONTRABON_CREATE), err)
 	}
 
 	createdTime := time.Now().UTC()
-	tx := global.DBCON.Begin()
-	code := uc.sequenceRepo.NextValTx(customer[0].Code, tx)
-	if tx.Error != nil {
-		logrus.Error(tx.Error.Error())
-		return fmt.Errorf("Terjadi kesalahan saat pembuatan kontrabon")
+	tx := tenantutil.DB(ctx).Begin()
+	code, err := uc.sequenceRepo.NextValTx(ctx, prefix+customer[0].Code, tx)
+	if err != nil {
+		tx.Rollback()
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_KONTRABON_CREATE), err)
 	}
 
 	kontrabon := kontrabondomain.Kontrabon{
 		ID:          stringutil.GenerateUUID(),
-		Code:        "KTBN/" + strconv.Itoa(int(code)) + "/" + customer[0].Code + "/" + stringutil.ToRoman(int(createdTime.Month())) + "/" + createdTime.Format("2006"),
+		Code:        prefix + "KTBN/" + strconv.Itoa(int(code)) + "/" + customer[0].Code + "/" + stringutil.ToRoman(int(createdTime.Month())) + "/" + createdTime.Format("2006"),
 		CreatedTime: createdTime,
 		Status:      kontrabondomain.STATUS_CREATED,
 		CustomerID:  customerId,
 	}
 
-	uc.kontrabonRepo.CreateTx(kontrabon, transactionIds, tx)
+	uc.kontrabonRepo.CreateTx(ctx, kontrabon, transactionIds, tx)
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
-		return fmt.Errorf("Terjadi kesalahan saat pembuatan kontrabon")
+		logutil.WithContext(ctx).Error(tx.Error.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_KONTRABON_CREATE), tx.Error)
 	}
 
 	tx.Commit()
 	return nil
 }
 
-func (uc *Usecase) Update(kontrabonId string, transactionIds []string, status string) error {
-	err := uc.kontrabonRepo.Update(kontrabonId, transactionIds, status)
+func (uc *Usecase) Update(ctx context.Context, kontrabonId string, transactionIds []string, status string) error {
+	err := uc.kontrabonRepo.Update(ctx, kontrabonId, transactionIds, status)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data kontrabon")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_KONTRABON_UPDATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) UpdateLunas(kontrabonId string, paymentTime time.Time, totalPayment float64, description, paymentDate string) error {
-	err := uc.kontrabonRepo.UpdateLunas(kontrabonId, paymentTime, totalPayment, description, paymentDate)
+func (uc *Usecase) UpdateLunas(ctx context.Context, kontrabonId string, paymentTime time.Time, totalPayment float64, description, paymentDate string) error {
+	err := uc.kontrabonRepo.UpdateLunas(ctx, kontrabonId, paymentTime, totalPayment, description, paymentDate)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan status")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_STATUS_UPDATE), err)
 	}
 	return nil
 }
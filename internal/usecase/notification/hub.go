@@ -0,0 +1,54 @@
+package notificationusecase
+
+import (
+	notificationdomain "dromatech/pos-backend/internal/domain/notification"
+	"sync"
+)
+
+// SUBSCRIBER_BUFFER is how many notifications wait for a slow stream
+const SUBSCRIBER_BUFFER = 16
+
+// hub passes notifications to the open streams of this instance, keyed by tenant and user
+type hub struct {
+	mutex       sync.Mutex
+	subscribers map[string]map[chan notificationdomain.Notification]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subscribers: make(map[string]map[chan notificationdomain.Notification]struct{})}
+}
+
+func (h *hub) subscribe(key string) (chan notificationdomain.Notification, func()) {
+	ch := make(chan notificationdomain.Notification, SUBSCRIBER_BUFFER)
+
+	h.mutex.Lock()
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = make(map[chan notificationdomain.Notification]struct{})
+	}
+	h.subscribers[key][ch] = struct{}{}
+	h.mutex.Unlock()
+
+	unsubscribe := func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		delete(h.subscribers[key], ch)
+		if len(h.subscribers[key]) == 0 {
+			delete(h.subscribers, key)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// publish never blocks, a stream with a full buffer is closed so the client reconnects and reads what it missed from the table
+func (h *hub) publish(key string, notification notificationdomain.Notification) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for ch := range h.subscribers[key] {
+		select {
+		case ch <- notification:
+		default:
+			close(ch)
+			delete(h.subscribers[key], ch)
+		}
+	}
+}
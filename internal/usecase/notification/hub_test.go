@@ -0,0 +1,155 @@
+package notificationusecase
+
+import (
+	"context"
+	notificationdomain "dromatech/pos-backend/internal/domain/notification"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	notificationrepo "dromatech/pos-backend/internal/repo/notification"
+	"testing"
+)
+
+// received drains what is waiting on the channel and tells whether it was closed
+func received(ch chan notificationdomain.Notification) ([]string, bool) {
+	var ids []string
+	for {
+		select {
+		case notification, ok := <-ch:
+			if !ok {
+				return ids, true
+			}
+			ids = append(ids, notification.EntityID)
+		default:
+			return ids, false
+		}
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	tests := []struct {
+		name        string
+		publish     int
+		unsubscribe bool
+		received    int
+		closed      bool
+	}{
+		{"one", 1, false, 1, false},
+		{"fills the buffer", SUBSCRIBER_BUFFER, false, SUBSCRIBER_BUFFER, false},
+		{"falls behind", SUBSCRIBER_BUFFER + 1, false, SUBSCRIBER_BUFFER, true},
+		{"unsubscribed", 1, true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHub()
+			ch, unsubscribe := h.subscribe("t1/U1")
+			other, _ := h.subscribe("t1/U2")
+			if tt.unsubscribe {
+				unsubscribe()
+			}
+
+			for i := 0; i < tt.publish; i++ {
+				h.publish("t1/U1", notificationdomain.Notification{EntityID: "T1"})
+			}
+
+			ids, closed := received(ch)
+			if len(ids) != tt.received || closed != tt.closed {
+				t.Errorf("received %d, closed %v, want %d, %v", len(ids), closed, tt.received, tt.closed)
+			}
+			if ids, _ := received(other); len(ids) != 0 {
+				t.Errorf("another user received %d", len(ids))
+			}
+
+			h.mutex.Lock()
+			_, subscribed := h.subscribers["t1/U1"][ch]
+			h.mutex.Unlock()
+			if want := !tt.unsubscribe && !tt.closed; subscribed != want {
+				t.Errorf("subscribed = %v, want %v", subscribed, want)
+			}
+		})
+	}
+}
+
+func TestHubPublishEverySubscriber(t *testing.T) {
+	h := newHub()
+	first, _ := h.subscribe("t1/U1")
+	second, _ := h.subscribe("t1/U1")
+	otherTenant, _ := h.subscribe("t2/U1")
+
+	h.publish("t1/U1", notificationdomain.Notification{EntityID: "T1"})
+
+	for name, ch := range map[string]chan notificationdomain.Notification{"first": first, "second": second} {
+		if ids, _ := received(ch); len(ids) != 1 || ids[0] != "T1" {
+			t.Errorf("%s stream received %v, want [T1]", name, ids)
+		}
+	}
+	if ids, _ := received(otherTenant); len(ids) != 0 {
+		t.Errorf("the same user of another tenant received %v", ids)
+	}
+}
+
+// notificationRepo keeps the created notifications, the methods NotifyTransfer does not use are left to the embedded nil repo
+type notificationRepo struct {
+	notificationrepo.NotificationRepo
+	created []*notificationdomain.Notification
+}
+
+func (r *notificationRepo) Create(ctx context.Context, notification *notificationdomain.Notification) error {
+	notification.ID = int64(len(r.created) + 1)
+	r.created = append(r.created, notification)
+	return nil
+}
+
+func (r *notificationRepo) FindUserName(ctx context.Context, id string) (string, error) {
+	return "name of " + id, nil
+}
+
+func TestNotifyTransfer(t *testing.T) {
+	tests := []struct {
+		status transactiondomain.DanaStatus
+		user   string
+		kind   string
+	}{
+		{transactiondomain.DanaStatusPending, "RECEIVER", notificationdomain.TYPE_DANA_TRANSFER_PENDING},
+		{transactiondomain.DanaStatusCanceled, "RECEIVER", notificationdomain.TYPE_DANA_TRANSFER_CANCELED},
+		{transactiondomain.DanaStatusApproved, "SENDER", notificationdomain.TYPE_DANA_TRANSFER_APPROVED},
+		{transactiondomain.DanaStatusRejected, "SENDER", notificationdomain.TYPE_DANA_TRANSFER_REJECTED},
+		{transactiondomain.DanaStatusExpired, "SENDER", notificationdomain.TYPE_DANA_TRANSFER_EXPIRED},
+		{transactiondomain.DanaStatus("UNKNOWN"), "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			repo := &notificationRepo{}
+			uc := New(repo)
+			sender, _ := uc.hub.subscribe("SENDER")
+			receiver, _ := uc.hub.subscribe("RECEIVER")
+
+			uc.NotifyTransfer(context.Background(), &transactiondomain.DanaTransaction{ID: "T1", Sender: "SENDER", Receiver: "RECEIVER", Status: tt.status})
+
+			if tt.user == "" {
+				if len(repo.created) != 0 {
+					t.Fatalf("created %d notifications, want none", len(repo.created))
+				}
+				return
+			}
+			if len(repo.created) != 1 {
+				t.Fatalf("created %d notifications, want 1", len(repo.created))
+			}
+			created := repo.created[0]
+			if created.WebUserID != tt.user || created.Type != tt.kind || created.DanaTransfer == nil {
+				t.Errorf("created for %s of type %s, want %s of type %s", created.WebUserID, created.Type, tt.user, tt.kind)
+			}
+
+			streams := map[string]chan notificationdomain.Notification{"SENDER": sender, "RECEIVER": receiver}
+			for user, ch := range streams {
+				want := 0
+				if user == tt.user {
+					want = 1
+				}
+				if ids, _ := received(ch); len(ids) != want {
+					t.Errorf("stream of %s received %d, want %d", user, len(ids), want)
+				}
+			}
+		})
+	}
+}
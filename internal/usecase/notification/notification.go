@@ -0,0 +1,149 @@
+package notificationusecase
+
+import (
+	"context"
+	notificationdomain "dromatech/pos-backend/internal/domain/notification"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	notificationrepo "dromatech/pos-backend/internal/repo/notification"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"time"
+)
+
+const (
+	DEFAULT_LIMIT = 50
+	MAX_LIMIT     = 200
+	// REPLAY_LIMIT caps what a reconnecting stream reads back, older notifications stay in the list
+	REPLAY_LIMIT = 100
+)
+
+type NotificationUsecase interface {
+	NotifyTransfer(ctx context.Context, transfer *transactiondomain.DanaTransaction)
+	Subscribe(ctx context.Context, userID string, lastID int64) ([]notificationdomain.Notification, <-chan notificationdomain.Notification, func(), error)
+	FindList(ctx context.Context, userID string, unreadOnly bool, limit int) (*notificationdomain.NotificationListResponse, error)
+	MarkRead(ctx context.Context, userID string, request notificationdomain.MarkReadRequest) error
+}
+
+type Usecase struct {
+	notificationRepo notificationrepo.NotificationRepo
+	hub              *hub
+}
+
+func New(notificationRepo notificationrepo.NotificationRepo) *Usecase {
+	uc := &Usecase{
+		notificationRepo: notificationRepo,
+		hub:              newHub(),
+	}
+
+	return uc
+}
+
+// NotifyTransfer tells the other side of a transfer about its status, the receiver of a new or canceled transfer and the sender of an approved, rejected or expired one.
+// The transfer is already saved, so a failure is logged and the client still sees the change through FindDana and the sync feed.
+func (uc *Usecase) NotifyTransfer(ctx context.Context, transfer *transactiondomain.DanaTransaction) {
+	notification := &notificationdomain.Notification{
+		EntityID:    transfer.ID,
+		CreatedTime: time.Now(),
+	}
+	switch transfer.Status {
+	case transactiondomain.DanaStatusPending:
+		notification.Type = notificationdomain.TYPE_DANA_TRANSFER_PENDING
+		notification.WebUserID = transfer.Receiver
+	case transactiondomain.DanaStatusCanceled:
+		notification.Type = notificationdomain.TYPE_DANA_TRANSFER_CANCELED
+		notification.WebUserID = transfer.Receiver
+	case transactiondomain.DanaStatusApproved:
+		notification.Type = notificationdomain.TYPE_DANA_TRANSFER_APPROVED
+		notification.WebUserID = transfer.Sender
+	case transactiondomain.DanaStatusRejected:
+		notification.Type = notificationdomain.TYPE_DANA_TRANSFER_REJECTED
+		notification.WebUserID = transfer.Sender
+	case transactiondomain.DanaStatusExpired:
+		notification.Type = notificationdomain.TYPE_DANA_TRANSFER_EXPIRED
+		notification.WebUserID = transfer.Sender
+	default:
+		return
+	}
+
+	senderName, err := uc.notificationRepo.FindUserName(ctx, transfer.Sender)
+	if err != nil {
+		return
+	}
+	receiverName, err := uc.notificationRepo.FindUserName(ctx, transfer.Receiver)
+	if err != nil {
+		return
+	}
+	notification.DanaTransfer = &notificationdomain.DanaTransferNotification{
+		ID:           transfer.ID,
+		Date:         transfer.Date,
+		Sender:       transfer.Sender,
+		SenderName:   senderName,
+		Receiver:     transfer.Receiver,
+		ReceiverName: receiverName,
+		Amount:       transfer.Amount,
+		Status:       string(transfer.Status),
+	}
+
+	if err := uc.notificationRepo.Create(ctx, notification); err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return
+	}
+	uc.hub.publish(tenantutil.Key(ctx, notification.WebUserID), *notification)
+}
+
+// Subscribe opens a stream of the notifications of the user, lastID is the last event id a reconnecting client has seen.
+// The notifications after lastID are returned to be sent first, the channel is closed when the stream falls behind.
+func (uc *Usecase) Subscribe(ctx context.Context, userID string, lastID int64) ([]notificationdomain.Notification, <-chan notificationdomain.Notification, func(), error) {
+	// subscribe before reading the missed ones so nothing is lost in between, the stream skips what it already sent
+	ch, unsubscribe := uc.hub.subscribe(tenantutil.Key(ctx, userID))
+
+	missed := []notificationdomain.Notification{}
+	if lastID > 0 {
+		var err error
+		missed, err = uc.notificationRepo.FindAfter(ctx, userID, lastID, REPLAY_LIMIT)
+		if err != nil {
+			unsubscribe()
+			return nil, nil, nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+	}
+	return missed, ch, unsubscribe, nil
+}
+
+func (uc *Usecase) FindList(ctx context.Context, userID string, unreadOnly bool, limit int) (*notificationdomain.NotificationListResponse, error) {
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+	if limit > MAX_LIMIT {
+		limit = MAX_LIMIT
+	}
+
+	notifications, err := uc.notificationRepo.FindList(ctx, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	unread, err := uc.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	return &notificationdomain.NotificationListResponse{
+		Notifications: notifications,
+		Unread:        unread,
+	}, nil
+}
+
+func (uc *Usecase) MarkRead(ctx context.Context, userID string, request notificationdomain.MarkReadRequest) error {
+	var err error
+	if request.All {
+		err = uc.notificationRepo.MarkAllRead(ctx, userID)
+	} else {
+		err = uc.notificationRepo.MarkRead(ctx, userID, request.IDs)
+	}
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_NOTIFICATION_READ), err)
+	}
+	return nil
+}
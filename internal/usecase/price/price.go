@@ -1,37 +1,39 @@
 package priceusecase
 
 import (
-	"dromatech/pos-backend/global"
+	"context"
 	customerdomain "dromatech/pos-backend/internal/domain/customer"
 	pricedomain "dromatech/pos-backend/internal/domain/price"
 	customerrepo "dromatech/pos-backend/internal/repo/customer"
 	pricerepo "dromatech/pos-backend/internal/repo/price"
 	productrepo "dromatech/pos-backend/internal/repo/product"
 	transactionrepo "dromatech/pos-backend/internal/repo/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
 	stringutil "dromatech/pos-backend/internal/util/string"
-	"fmt"
-	"github.com/sirupsen/logrus"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"time"
 )
 
 type PriceUsecase interface {
-	Find(name string) ([]*pricedomain.PriceTemplate, error)
-	FindDetail(templateId string) ([]*pricedomain.PriceTemplateDetail, error)
-	Create(templateName string) error
-	EditPrice(templateId, productId string, price float64) error
-	ApplyToCustomer(templateId string, customerId []string, userId string) error
-	DeleteTemplate(templateId string) error
-	CopyTemplate(templateId, templateName string) error
-	Download(request pricedomain.Download) error
-	FindBuyTemplate(name string) ([]*pricedomain.PriceTemplate, error)
-	FindBuyDetail(templateId string) ([]*pricedomain.PriceTemplateDetail, error)
-	CreateBuyTemplate(templateName string) error
-	EditBuyPrice(templateId, productId string, price float64) error
-	ApplyToTrx(templateId string, date string, userId string) error
-	DeleteBuyTemplate(templateId string) error
-	DownloadBuy(request pricedomain.Download) error
-	CopyBuyTemplate(templateId, templateName string) error
+	Find(ctx context.Context, name string) ([]*pricedomain.PriceTemplate, error)
+	FindDetail(ctx context.Context, templateId string) ([]*pricedomain.PriceTemplateDetail, error)
+	Create(ctx context.Context, templateName string) error
+	EditPrice(ctx context.Context, templateId, productId string, price float64) error
+	ApplyToCustomer(ctx context.Context, templateId string, customerId []string, userId string) error
+	DeleteTemplate(ctx context.Context, templateId string) error
+	CopyTemplate(ctx context.Context, templateId, templateName string) error
+	Download(ctx context.Context, request pricedomain.Download) error
+	FindBuyTemplate(ctx context.Context, name string) ([]*pricedomain.PriceTemplate, error)
+	FindBuyDetail(ctx context.Context, templateId string) ([]*pricedomain.PriceTemplateDetail, error)
+	CreateBuyTemplate(ctx context.Context, templateName string) error
+	EditBuyPrice(ctx context.Context, templateId, productId string, price float64) error
+	ApplyToTrx(ctx context.Context, templateId string, date string, userId string) error
+	DeleteBuyTemplate(ctx context.Context, templateId string) error
+	DownloadBuy(ctx context.Context, request pricedomain.Download) error
+	CopyBuyTemplate(ctx context.Context, templateId, templateName string) error
 }
 
 type Usecase struct {
@@ -52,170 +54,170 @@ func New(priceRepo pricerepo.PriceRepo, productRepo productrepo.ProductRepo, cus
 	return uc
 }
 
-func (uc *Usecase) Find(name string) ([]*pricedomain.PriceTemplate, error) {
+func (uc *Usecase) Find(ctx context.Context, name string) ([]*pricedomain.PriceTemplate, error) {
 	param := make(map[string]interface{})
 
 	if name != "" {
 		param["pt.name"] = name
 	}
 
-	entities, err := uc.priceRepo.Find(param)
+	entities, err := uc.priceRepo.Find(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FIND), err)
 	}
 
 	return entities, nil
 }
 
-func (uc *Usecase) FindBuyTemplate(name string) ([]*pricedomain.PriceTemplate, error) {
+func (uc *Usecase) FindBuyTemplate(ctx context.Context, name string) ([]*pricedomain.PriceTemplate, error) {
 	param := make(map[string]interface{})
 
 	if name != "" {
 		param["pt.name"] = name
 	}
 
-	entities, err := uc.priceRepo.FindBuyTemplate(param)
+	entities, err := uc.priceRepo.FindBuyTemplate(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FIND), err)
 	}
 
 	return entities, nil
 }
 
-func (uc *Usecase) FindDetail(templateId string) ([]*pricedomain.PriceTemplateDetail, error) {
+func (uc *Usecase) FindDetail(ctx context.Context, templateId string) ([]*pricedomain.PriceTemplateDetail, error) {
 	param := make(map[string]interface{})
 
 	if templateId != "" {
 		param["ptd.price_template_id"] = templateId
 	}
 
-	entities, err := uc.priceRepo.FindDetail(param)
+	entities, err := uc.priceRepo.FindDetail(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FIND), err)
 	}
 
 	return entities, nil
 }
 
-func (uc *Usecase) FindBuyDetail(templateId string) ([]*pricedomain.PriceTemplateDetail, error) {
+func (uc *Usecase) FindBuyDetail(ctx context.Context, templateId string) ([]*pricedomain.PriceTemplateDetail, error) {
 	param := make(map[string]interface{})
 
 	if templateId != "" {
 		param["ptd.buy_price_template_id"] = templateId
 	}
 
-	entities, err := uc.priceRepo.FindBuyDetail(param)
+	entities, err := uc.priceRepo.FindBuyDetail(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FIND), err)
 	}
 
 	return entities, nil
 }
 
-func (uc *Usecase) Create(templateName string) error {
-	products, err := uc.priceRepo.Find(map[string]interface{}{"pt.name": templateName})
+func (uc *Usecase) Create(ctx context.Context, templateName string) error {
+	products, err := uc.priceRepo.Find(ctx, map[string]interface{}{"pt.name": templateName})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_CREATE), err)
 	}
 
 	if products != nil || len(products) > 0 {
-		return fmt.Errorf("Template dengan nama %s sudah terdaftar", templateName)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_NAME_EXISTS, templateName))
 	}
 
-	_, err = uc.priceRepo.Create(templateName)
+	_, err = uc.priceRepo.Create(ctx, templateName)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) CreateBuyTemplate(templateName string) error {
-	products, err := uc.priceRepo.FindBuyTemplate(map[string]interface{}{"pt.name": templateName})
+func (uc *Usecase) CreateBuyTemplate(ctx context.Context, templateName string) error {
+	products, err := uc.priceRepo.FindBuyTemplate(ctx, map[string]interface{}{"pt.name": templateName})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_CREATE), err)
 	}
 
 	if products != nil || len(products) > 0 {
-		return fmt.Errorf("Template dengan nama %s sudah terdaftar", templateName)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_NAME_EXISTS, templateName))
 	}
 
-	_, err = uc.priceRepo.CreateBuyTemplate(templateName)
+	_, err = uc.priceRepo.CreateBuyTemplate(ctx, templateName)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) EditPrice(templateId, productId string, price float64) error {
-	priceDetail, err := uc.priceRepo.FindDetail(map[string]interface{}{"ptd.price_template_id": templateId, "ptd.product_id": productId})
+func (uc *Usecase) EditPrice(ctx context.Context, templateId, productId string, price float64) error {
+	priceDetail, err := uc.priceRepo.FindDetail(ctx, map[string]interface{}{"ptd.price_template_id": templateId, "ptd.product_id": productId})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 	}
 
 	if priceDetail != nil {
-		err = uc.priceRepo.EditPrice(templateId, productId, price)
+		err = uc.priceRepo.EditPrice(ctx, templateId, productId, price)
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 		}
 	} else {
-		err = uc.priceRepo.AddPrice(templateId, productId, price)
+		err = uc.priceRepo.AddPrice(ctx, templateId, productId, price)
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 		}
 	}
 
 	return nil
 }
 
-func (uc *Usecase) EditBuyPrice(templateId, productId string, price float64) error {
-	priceDetail, err := uc.priceRepo.FindBuyDetail(map[string]interface{}{"ptd.buy_price_template_id": templateId, "ptd.product_id": productId})
+func (uc *Usecase) EditBuyPrice(ctx context.Context, templateId, productId string, price float64) error {
+	priceDetail, err := uc.priceRepo.FindBuyDetail(ctx, map[string]interface{}{"ptd.buy_price_template_id": templateId, "ptd.product_id": productId})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 	}
 
 	if priceDetail != nil {
-		err = uc.priceRepo.EditBuyPrice(templateId, productId, price)
+		err = uc.priceRepo.EditBuyPrice(ctx, templateId, productId, price)
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 		}
 	} else {
-		err = uc.priceRepo.AddBuyPrice(templateId, productId, price)
+		err = uc.priceRepo.AddBuyPrice(ctx, templateId, productId, price)
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 		}
 	}
 
 	return nil
 }
 
-func (uc *Usecase) ApplyToCustomer(templateId string, customerId []string, userId string) error {
+func (uc *Usecase) ApplyToCustomer(ctx context.Context, templateId string, customerId []string, userId string) error {
 	date := time.Now().UTC()
-	priceDetail, err := uc.priceRepo.FindDetail(map[string]interface{}{"ptd.price_template_id": templateId})
+	priceDetail, err := uc.priceRepo.FindDetail(ctx, map[string]interface{}{"ptd.price_template_id": templateId})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 	}
 
-	products, err := uc.productRepo.Find(map[string]interface{}{"p.active": true})
+	products, err := uc.productRepo.Find(ctx, map[string]interface{}{"p.active": true})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 	}
 
 	priceDetailMap := make(map[string]float64)
@@ -223,7 +225,7 @@ func (uc *Usecase) ApplyToCustomer(templateId string, customerId []string, userI
 		priceDetailMap[price.ProductID] = price.Price
 	}
 
-	tx := global.DBCON.Begin()
+	tx := tenantutil.DB(ctx).Begin()
 	appliedCustomer := ""
 	for _, cID := range customerId {
 		appliedCustomer = appliedCustomer + cID + ";"
@@ -245,31 +247,31 @@ func (uc *Usecase) ApplyToCustomer(templateId string, customerId []string, userI
 				TransactionId: nil,
 			}
 
-			uc.customerRepo.AddSellPriceTx(priceRequest, tx)
+			uc.customerRepo.AddSellPriceTx(ctx, priceRequest, tx)
 			if tx.Error != nil {
 				tx.Rollback()
-				logrus.Error(err.Error())
-				return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+				logutil.WithContext(ctx).Error(err.Error())
+				return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), tx.Error)
 			}
 		}
 	}
-	uc.priceRepo.UpdateTemplate(templateId, appliedCustomer, tx)
+	uc.priceRepo.UpdateTemplate(ctx, templateId, appliedCustomer, tx)
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), tx.Error)
 	}
 	tx.Commit()
 
 	return nil
 }
 
-func (uc *Usecase) ApplyToTrx(templateId string, date string, userId string) error {
+func (uc *Usecase) ApplyToTrx(ctx context.Context, templateId string, date string, userId string) error {
 	now := time.Now().UTC()
-	priceDetail, err := uc.priceRepo.FindBuyDetail(map[string]interface{}{"ptd.buy_price_template_id": templateId})
+	priceDetail, err := uc.priceRepo.FindBuyDetail(ctx, map[string]interface{}{"ptd.buy_price_template_id": templateId})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 	}
 
 	productIds := make([]string, 0)
@@ -301,12 +303,12 @@ func (uc *Usecase) ApplyToTrx(templateId string, date string, userId string) err
 		Value:    productIds,
 	})
 
-	transactions, err := uc.transactionRepo.FindDetails(param)
+	transactions, err := uc.transactionRepo.FindDetails(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 	}
-	tx := global.DBCON.Begin()
+	tx := tenantutil.DB(ctx).Begin()
 	for _, transaction := range transactions {
 		price := float64(0)
 		if detail, ok := priceDetailMap[transaction.ProductID]; ok {
@@ -317,18 +319,18 @@ func (uc *Usecase) ApplyToTrx(templateId string, date string, userId string) err
 			continue
 		}
 
-		err = uc.transactionRepo.UpdateHargaBeliTx(transaction.ID, price, userId, tx)
+		err = uc.transactionRepo.UpdateHargaBeliTx(ctx, transaction.ID, price, userId, tx)
 		if err != nil {
 			tx.Rollback()
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), err)
 		}
 
-		uc.priceRepo.UpdateBuyTemplate(templateId, userId, transaction.TransactionID, now, tx)
+		uc.priceRepo.UpdateBuyTemplate(ctx, templateId, userId, transaction.TransactionID, now, tx)
 		if tx.Error != nil {
 			tx.Rollback()
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CHANGE), tx.Error)
 		}
 
 	}
@@ -338,84 +340,84 @@ func (uc *Usecase) ApplyToTrx(templateId string, date string, userId string) err
 	return nil
 }
 
-func (uc *Usecase) DeleteTemplate(templateId string) error {
-	return uc.priceRepo.DeleteTemplate(templateId)
+func (uc *Usecase) DeleteTemplate(ctx context.Context, templateId string) error {
+	return uc.priceRepo.DeleteTemplate(ctx, templateId)
 }
 
-func (uc *Usecase) DeleteBuyTemplate(templateId string) error {
-	return uc.priceRepo.DeleteBuyTemplate(templateId)
+func (uc *Usecase) DeleteBuyTemplate(ctx context.Context, templateId string) error {
+	return uc.priceRepo.DeleteBuyTemplate(ctx, templateId)
 }
 
-func (uc *Usecase) Download(request pricedomain.Download) error {
-	return uc.priceRepo.UpdateChecked(request)
+func (uc *Usecase) Download(ctx context.Context, request pricedomain.Download) error {
+	return uc.priceRepo.UpdateChecked(ctx, request)
 }
 
-func (uc *Usecase) DownloadBuy(request pricedomain.Download) error {
-	return uc.priceRepo.UpdateBuyChecked(request)
+func (uc *Usecase) DownloadBuy(ctx context.Context, request pricedomain.Download) error {
+	return uc.priceRepo.UpdateBuyChecked(ctx, request)
 }
 
-func (uc *Usecase) CopyTemplate(templateId, templateName string) error {
-	products, err := uc.priceRepo.Find(map[string]interface{}{"pt.name": templateName})
+func (uc *Usecase) CopyTemplate(ctx context.Context, templateId, templateName string) error {
+	products, err := uc.priceRepo.Find(ctx, map[string]interface{}{"pt.name": templateName})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 	}
 
 	if products != nil || len(products) > 0 {
-		return fmt.Errorf("Template dengan nama %s sudah terdaftar", templateName)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_NAME_EXISTS, templateName))
 	}
 
-	ID, err := uc.priceRepo.Create(templateName)
+	ID, err := uc.priceRepo.Create(ctx, templateName)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 	}
 
-	priceDetail, err := uc.priceRepo.FindDetail(map[string]interface{}{"ptd.price_template_id": templateId})
+	priceDetail, err := uc.priceRepo.FindDetail(ctx, map[string]interface{}{"ptd.price_template_id": templateId})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 	}
 
 	for _, price := range priceDetail {
-		err = uc.priceRepo.AddPrice(ID, price.ProductID, price.Price)
+		err = uc.priceRepo.AddPrice(ctx, ID, price.ProductID, price.Price)
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 		}
 	}
 
 	return nil
 }
 
-func (uc *Usecase) CopyBuyTemplate(templateId, templateName string) error {
-	products, err := uc.priceRepo.FindBuyTemplate(map[string]interface{}{"pt.name": templateName})
+func (uc *Usecase) CopyBuyTemplate(ctx context.Context, templateId, templateName string) error {
+	products, err := uc.priceRepo.FindBuyTemplate(ctx, map[string]interface{}{"pt.name": templateName})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 	}
 
 	if products != nil || len(products) > 0 {
-		return fmt.Errorf("Template dengan nama %s sudah terdaftar", templateName)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_NAME_EXISTS, templateName))
 	}
 
-	ID, err := uc.priceRepo.CreateBuyTemplate(templateName)
+	ID, err := uc.priceRepo.CreateBuyTemplate(ctx, templateName)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 	}
 
-	priceDetail, err := uc.priceRepo.FindBuyDetail(map[string]interface{}{"ptd.buy_price_template_id": templateId})
+	priceDetail, err := uc.priceRepo.FindBuyDetail(ctx, map[string]interface{}{"ptd.buy_price_template_id": templateId})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 	}
 
 	for _, price := range priceDetail {
-		err = uc.priceRepo.AddBuyPrice(ID, price.ProductID, price.Price)
+		err = uc.priceRepo.AddBuyPrice(ctx, ID, price.ProductID, price.Price)
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan duplikasi data template harga")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TEMPLATE_COPY), err)
 		}
 	}
 
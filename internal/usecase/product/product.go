@@ -1,18 +1,22 @@
 package productusecase
 
 import (
+	"context"
 	productdomain "dromatech/pos-backend/internal/domain/product"
 	productrepo "dromatech/pos-backend/internal/repo/product"
-	"fmt"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	taxutil "dromatech/pos-backend/internal/util/tax"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"strings"
 )
 
 type ProductUsecase interface {
-	Find(id, code, name string, active *bool) ([]*productdomain.Product, error)
-	Create(code, name, description, unitId string) error
-	Edit(id, unitId, code, name, description string, active bool) error
+	Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*productdomain.Product, int64, error)
+	Create(ctx context.Context, code, name, description, unitId string, taxRate *float64) error
+	Edit(ctx context.Context, id, unitId, code, name, description string, active bool, taxRate *float64) error
 }
 
 type Usecase struct {
@@ -27,32 +31,56 @@ func New(productRepo productrepo.ProductRepo) *Usecase {
 	return uc
 }
 
-func (uc *Usecase) Find(id, code, name string, active *bool) ([]*productdomain.Product, error) {
-	param := make(map[string]interface{})
+func (uc *Usecase) Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*productdomain.Product, int64, error) {
+	var param []queryutil.Param
 	if id != "" {
-		param["p.id"] = id
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "p.id",
+			Operator: "=",
+			Value:    id,
+		})
 	}
 	if code != "" {
-		param["p.code"] = code
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "p.code",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(code),
+		})
 	}
 	if name != "" {
-		param["p.name"] = name
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "p.name",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(name),
+		})
 	}
 	if active != nil {
-		param["p.active"] = *active
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "p.active",
+			Operator: "=",
+			Value:    *active,
+		})
 	}
-	return uc.productRepo.Find(param)
+	return uc.productRepo.FindList(ctx, param, list)
 }
 
-func (uc *Usecase) Create(code, name, description, unitId string) error {
-	products, err := uc.productRepo.Find(map[string]interface{}{"p.code": code})
+func (uc *Usecase) Create(ctx context.Context, code, name, description, unitId string, taxRate *float64) error {
+	if !taxutil.ValidRate(taxRate) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TAX_RATE_INVALID))
+	}
+
+	products, err := uc.productRepo.Find(ctx, map[string]interface{}{"p.code": code})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data produk")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CREATE), err)
 	}
 
 	if products != nil || len(products) > 0 {
-		return fmt.Errorf("Produk dengan kode %s sudah terdaftar", code)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CODE_EXISTS, code))
 	}
 
 	id := strings.ReplaceAll(uuid.NewString(), "-", "")
@@ -64,40 +92,45 @@ func (uc *Usecase) Create(code, name, description, unitId string) error {
 		Description: description,
 		Active:      true,
 		UnitID:      unitId,
+		TaxRate:     taxRate,
 	}
 
-	err = uc.productRepo.Create(product)
+	err = uc.productRepo.Create(ctx, product)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data produk")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) Edit(id, unitId, code, name, description string, active bool) error {
-	products, err := uc.productRepo.Find(map[string]interface{}{"p.id": id})
+func (uc *Usecase) Edit(ctx context.Context, id, unitId, code, name, description string, active bool, taxRate *float64) error {
+	if !taxutil.ValidRate(taxRate) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TAX_RATE_INVALID))
+	}
+
+	products, err := uc.productRepo.Find(ctx, map[string]interface{}{"p.id": id})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data produk")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_UPDATE), err)
 	}
 
 	if len(products) != 1 {
-		logrus.Errorf("Product with id %s more than 1", id)
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data produk")
+		logutil.WithContext(ctx).Errorf("Product with id %s more than 1", id)
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_UPDATE), nil)
 	}
 
 	product := products[0]
 
 	if code != product.Code {
-		products, err := uc.productRepo.Find(map[string]interface{}{"p.code": code})
+		products, err := uc.productRepo.Find(ctx, map[string]interface{}{"p.code": code})
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data produk")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_UPDATE), err)
 		}
 
 		if products != nil || len(products) > 0 {
-			return fmt.Errorf("Produk dengan kode %s sudah terdaftar", code)
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CODE_EXISTS, code))
 		}
 	}
 
@@ -106,11 +139,12 @@ func (uc *Usecase) Edit(id, unitId, code, name, description string, active bool)
 	product.UnitID = unitId
 	product.Description = description
 	product.Active = active
+	product.TaxRate = taxRate
 
-	err = uc.productRepo.Edit(product)
+	err = uc.productRepo.Edit(ctx, product)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data produk")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_UPDATE), err)
 	}
 
 	return nil
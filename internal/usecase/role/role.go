@@ -1,75 +1,84 @@
 package roleusecase
 
 import (
+	"context"
 	roledomain "dromatech/pos-backend/internal/domain/role"
 	sessiondomain "dromatech/pos-backend/internal/domain/session"
-	"fmt"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
 )
 
 type RoleUsecase interface {
-	GetActiveRole() ([]*roledomain.RoleResponseModel, error)
-	FindPermissions(roleId string) ([]*roledomain.Permission, error)
-	RegisterRole(roleName string, permissions []string) error
-	GetAllRole() ([]*roledomain.Role, error)
-	EditRole(roleId string, roleName string, active bool, permissions []string) error
+	GetActiveRole(ctx context.Context) ([]*roledomain.RoleResponseModel, error)
+	FindPermissions(ctx context.Context, roleId string) ([]*roledomain.Permission, error)
+	RegisterRole(ctx context.Context, role *roledomain.Role) error
+	GetAllRole(ctx context.Context) ([]*roledomain.Role, error)
+	EditRole(ctx context.Context, role *roledomain.Role) error
 }
 
 type Usecase struct {
-	rolerepo roleRepo
+	rolerepo         roleRepo
+	sessionRefresher sessionRefresher
+}
+
+// sessionRefresher applies role changes to the sessions logged in with the role
+type sessionRefresher interface {
+	RefreshRole(ctx context.Context, roleID string)
 }
 
 type roleRepo interface {
-	Find(id string) *roledomain.Role
-	FindMenu(roleId string) ([]*sessiondomain.Menu, error)
-	FindAll() ([]*roledomain.Role, error)
-	FindActive() ([]*roledomain.RoleResponseModel, error)
-	FindActivePermissionPaths(roleId string) ([]string, error)
-	FindPermissions() ([]*roledomain.Permission, error)
-	FindPermissionsByRoleId(roleId string) ([]*roledomain.Permission, error)
-	RegisterRole(name string, permissions []string)
-	FindByName(name string) *roledomain.Role
-	EditRole(roleId string, name string, active bool, permissions []string)
+	Find(ctx context.Context, id string) *roledomain.Role
+	FindMenu(ctx context.Context, roleId string) ([]*sessiondomain.Menu, error)
+	FindAll(ctx context.Context) ([]*roledomain.Role, error)
+	FindActive(ctx context.Context) ([]*roledomain.RoleResponseModel, error)
+	FindPermissions(ctx context.Context) ([]*roledomain.Permission, error)
+	FindPermissionsByRoleId(ctx context.Context, roleId string) ([]*roledomain.Permission, error)
+	RegisterRole(ctx context.Context, role *roledomain.Role)
+	FindByName(ctx context.Context, name string) *roledomain.Role
+	EditRole(ctx context.Context, role *roledomain.Role)
 }
 
-func New(rolerepo roleRepo) *Usecase {
+func New(rolerepo roleRepo, sessionRefresher sessionRefresher) *Usecase {
 	uc := &Usecase{
-		rolerepo: rolerepo,
+		rolerepo:         rolerepo,
+		sessionRefresher: sessionRefresher,
 	}
 
 	return uc
 }
 
-func (uc *Usecase) GetActiveRole() ([]*roledomain.RoleResponseModel, error) {
-	return uc.rolerepo.FindActive()
+func (uc *Usecase) GetActiveRole(ctx context.Context) ([]*roledomain.RoleResponseModel, error) {
+	return uc.rolerepo.FindActive(ctx)
 }
 
-func (uc *Usecase) GetAllRole() ([]*roledomain.Role, error) {
-	return uc.rolerepo.FindAll()
+func (uc *Usecase) GetAllRole(ctx context.Context) ([]*roledomain.Role, error) {
+	return uc.rolerepo.FindAll(ctx)
 }
 
-func (uc *Usecase) FindPermissions(roleId string) ([]*roledomain.Permission, error) {
+func (uc *Usecase) FindPermissions(ctx context.Context, roleId string) ([]*roledomain.Permission, error) {
 	if roleId != "" {
-		return uc.rolerepo.FindPermissionsByRoleId(roleId)
+		return uc.rolerepo.FindPermissionsByRoleId(ctx, roleId)
 	}
-	return uc.rolerepo.FindPermissions()
+	return uc.rolerepo.FindPermissions(ctx)
 }
 
-func (uc *Usecase) RegisterRole(roleName string, permissions []string) error {
-	role := uc.rolerepo.FindByName(roleName)
-	if role != nil {
-		return fmt.Errorf("Role dengan nama %s sudah terdaftar", roleName)
+func (uc *Usecase) RegisterRole(ctx context.Context, role *roledomain.Role) error {
+	existing := uc.rolerepo.FindByName(ctx, role.Name)
+	if existing != nil {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_ROLE_NAME_EXISTS, role.Name))
 	}
 
-	uc.rolerepo.RegisterRole(roleName, permissions)
+	uc.rolerepo.RegisterRole(ctx, role)
 	return nil
 }
 
-func (uc *Usecase) EditRole(roleId string, roleName string, active bool, permissions []string) error {
-	role := uc.rolerepo.FindByName(roleName)
-	if role != nil && role.ID != roleId {
-		return fmt.Errorf("Role dengan nama %s sudah terdaftar", roleName)
+func (uc *Usecase) EditRole(ctx context.Context, role *roledomain.Role) error {
+	existing := uc.rolerepo.FindByName(ctx, role.Name)
+	if existing != nil && existing.ID != role.ID {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_ROLE_NAME_EXISTS, role.Name))
 	}
 
-	uc.rolerepo.EditRole(roleId, roleName, active, permissions)
+	uc.rolerepo.EditRole(ctx, role)
+	uc.sessionRefresher.RefreshRole(ctx, role.ID)
 	return nil
 }
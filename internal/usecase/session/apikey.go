@@ -0,0 +1,153 @@
+package sessionusecase
+
+import (
+	"context"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	apikeyutil "dromatech/pos-backend/internal/util/apikey"
+	logutil "dromatech/pos-backend/internal/util/log"
+	permissionutil "dromatech/pos-backend/internal/util/permission"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"time"
+)
+
+// LAST_USED_INTERVAL is how often the last use of a key is written, a busy integration would otherwise write on every request
+const LAST_USED_INTERVAL = time.Minute
+
+// AuthCheckApiKey authenticates a request of a service account, the session is shared by the requests of the key and never listed as logged in
+func (uc *Usecase) AuthCheckApiKey(ctx context.Context, key, clientIP, method, requestorPath string) (int, *sessiondomain.Session) {
+	id, secret, ok := apikeyutil.Parse(key)
+	if !ok {
+		return 401, nil
+	}
+
+	uc.apiKeyCache.Lock()
+	access, ok := uc.apiKeyCache.DataMap[tenantutil.Key(ctx, id)]
+	uc.apiKeyCache.Unlock()
+	if !ok {
+		access = uc.loadApiKey(ctx, id)
+		if access == nil {
+			return 401, nil
+		}
+	}
+
+	if !apikeyutil.Verify(secret, access.KeyHash) {
+		return 401, nil
+	}
+
+	now := time.Now()
+	if access.ExpiredTime != nil && now.After(*access.ExpiredTime) {
+		return 401, nil
+	}
+
+	if !access.Session.Access.Allowed(method, requestorPath) {
+		return 403, nil
+	}
+
+	uc.apiKeyCache.Lock()
+	stale := now.Sub(access.LastUsedTime) >= LAST_USED_INTERVAL
+	if stale {
+		access.LastUsedTime = now
+	}
+	uc.apiKeyCache.Unlock()
+	if stale {
+		if err := uc.apiKeyRepo.UpdateLastUsed(ctx, id, now, clientIP); err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+		}
+	}
+
+	return 200, access.Session
+}
+
+// loadApiKey resolves an active key of an active service account and caches it, nil when the key cannot be used
+func (uc *Usecase) loadApiKey(ctx context.Context, id string) *sessiondomain.ApiKeyAccess {
+	apiKey := uc.apiKeyRepo.Find(ctx, id)
+	if apiKey == nil || apiKey.RevokedTime != nil {
+		return nil
+	}
+
+	webuser := uc.webuserrepo.Find(ctx, apiKey.WebUserID)
+	if webuser == nil || webuser.ID == "" || !webuser.Active || !webuser.ServiceAccount {
+		return nil
+	}
+
+	role, menus, access, err := uc.findRoleAccess(ctx, webuser.RoleId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil
+	}
+
+	if len(apiKey.Permissions) > 0 {
+		apis, err := uc.apiKeyRepo.FindPermissionApis(ctx, id)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil
+		}
+		var rules []permissionutil.Rule
+		for _, api := range apis {
+			rules = append(rules, permissionutil.Rule{Method: api.Method, Path: api.Path})
+		}
+		// the role still bounds the key, a permission later removed from the role is not granted by the key
+		access = permissionutil.NewMatcher(rules).Within(access)
+	}
+
+	keyAccess := &sessiondomain.ApiKeyAccess{
+		KeyHash:     apiKey.KeyHash,
+		ExpiredTime: apiKey.ExpiredTime,
+		Session: &sessiondomain.Session{
+			ID:        apiKey.ID,
+			LoginTime: apiKey.CreatedTime,
+			UserID:    webuser.ID,
+			RoleID:    webuser.RoleId,
+			UserName:  webuser.Username,
+			Name:      webuser.Name,
+			RoleName:  role.Name,
+			Language:  webuser.Language,
+			Menu:      menus,
+			Access:    access,
+			AllData:   role.AllData,
+			Tenant:    tenantutil.Code(ctx),
+		},
+	}
+	// a service account works in the first branch assigned to it
+	branches, err := uc.findBranches(ctx, webuser.ID)
+	if err != nil {
+		return nil
+	}
+	applyBranches(keyAccess.Session, branches, "")
+	if apiKey.LastUsedTime != nil {
+		keyAccess.LastUsedTime = *apiKey.LastUsedTime
+	}
+
+	uc.apiKeyCache.Lock()
+	uc.apiKeyCache.DataMap[tenantutil.Key(ctx, id)] = keyAccess
+	uc.apiKeyCache.Unlock()
+
+	return keyAccess
+}
+
+// RefreshApiKey drops the cached key, the next request reads it again so rotation and revocation apply at once
+func (uc *Usecase) RefreshApiKey(ctx context.Context, id string) {
+	uc.apiKeyCache.Lock()
+	delete(uc.apiKeyCache.DataMap, tenantutil.Key(ctx, id))
+	uc.apiKeyCache.Unlock()
+}
+
+func (uc *Usecase) removeApiKeys(ctx context.Context, match func(session *sessiondomain.Session) bool) {
+	uc.apiKeyCache.Lock()
+	for id, access := range uc.apiKeyCache.DataMap {
+		if access.Session.Tenant == tenantutil.Code(ctx) && match(access.Session) {
+			delete(uc.apiKeyCache.DataMap, id)
+		}
+	}
+	uc.apiKeyCache.Unlock()
+}
+
+func (uc *Usecase) removeExpiredApiKeys(now time.Time) {
+	uc.apiKeyCache.Lock()
+	for id, access := range uc.apiKeyCache.DataMap {
+		if access.ExpiredTime != nil && now.After(*access.ExpiredTime) {
+			delete(uc.apiKeyCache.DataMap, id)
+		}
+	}
+	uc.apiKeyCache.Unlock()
+}
@@ -1,45 +1,87 @@
 package sessionusecase
 
 import (
-	"crypto/sha256"
-	"dromatech/pos-backend/global"
+	"context"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
 	configdomain "dromatech/pos-backend/internal/domain/config"
+	roledomain "dromatech/pos-backend/internal/domain/role"
 	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	apikeyrepo "dromatech/pos-backend/internal/repo/apikey"
 	configrepo "dromatech/pos-backend/internal/repo/config"
+	loginhistoryrepo "dromatech/pos-backend/internal/repo/loginhistory"
 	rolerepo "dromatech/pos-backend/internal/repo/role"
 	webuserrepo "dromatech/pos-backend/internal/repo/webuser"
-	"encoding/base64"
-	"fmt"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	passwordutil "dromatech/pos-backend/internal/util/password"
+	permissionutil "dromatech/pos-backend/internal/util/permission"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
-	"strconv"
+	"math"
+	"sort"
 	"strings"
 	"time"
 )
 
+// DEFAULT_SESSION_TIMEOUT_MINUTE is used when the tenant has no SESSION_TIMEOUT_MINUTE
+const DEFAULT_SESSION_TIMEOUT_MINUTE = 30
+
 type SessionUsecase interface {
-	Login(username string, password string) (*sessiondomain.Session, error)
-	Logout(token string)
-	AuthCheck(token string, requestorPath string) (string, int)
-	GetSession(token string) *sessiondomain.Session
+	Login(ctx context.Context, username, password, clientIP, userAgent string) (*sessiondomain.Session, *sessiondomain.LoginChallenge, error)
+	VerifyLogin(ctx context.Context, challengeToken, code, clientIP, userAgent string) (*sessiondomain.Session, error)
+	Logout(ctx context.Context, token string)
+	AuthCheck(ctx context.Context, token string, method string, requestorPath string) (string, int, *sessiondomain.Session)
+	AuthCheckApiKey(ctx context.Context, key, clientIP, method, requestorPath string) (int, *sessiondomain.Session)
+	RefreshApiKey(ctx context.Context, id string)
+	GetSession(ctx context.Context, token string) *sessiondomain.Session
+	RefreshRole(ctx context.Context, roleID string)
+	RefreshUser(ctx context.Context, userID string)
+	FindSessions(ctx context.Context, userID string) []*sessiondomain.SessionInfo
+	Revoke(ctx context.Context, id string) error
+	RevokeUser(ctx context.Context, userID string) error
+	FindLoginHistory(ctx context.Context, userID, startDate, endDate string, list queryutil.ListParam) ([]*sessiondomain.LoginHistory, int64, error)
+	ClearLoginAttempts(ctx context.Context, username string)
 }
 
 type Usecase struct {
-	sessionCache sessiondomain.SessionCache
-	configRepo   configrepo.ConfigRepo
-	webuserrepo  webuserrepo.WebUserRepo
-	roleRepo     rolerepo.RoleRepo
+	sessionCache     sessiondomain.SessionCache
+	attemptCache     sessiondomain.LoginAttemptCache
+	challengeCache   sessiondomain.LoginChallengeCache
+	accessCache      sessiondomain.RoleAccessCache
+	apiKeyCache      sessiondomain.ApiKeyCache
+	configRepo       configrepo.ConfigRepo
+	webuserrepo      webuserrepo.WebUserRepo
+	roleRepo         rolerepo.RoleRepo
+	loginHistoryRepo loginhistoryrepo.LoginHistoryRepo
+	apiKeyRepo       apikeyrepo.ApiKeyRepo
 }
 
-func New(configRepo configrepo.ConfigRepo, webuserrepo webuserrepo.WebUserRepo, roleRepo rolerepo.RoleRepo) *Usecase {
-	sessionCache := sessiondomain.SessionCache{
-		DataMap: make(map[string]*sessiondomain.Session),
-	}
+func New(configRepo configrepo.ConfigRepo, webuserrepo webuserrepo.WebUserRepo, roleRepo rolerepo.RoleRepo, loginHistoryRepo loginhistoryrepo.LoginHistoryRepo, apiKeyRepo apikeyrepo.ApiKeyRepo) *Usecase {
 	uc := &Usecase{
-		sessionCache: sessionCache,
-		configRepo:   configRepo,
-		webuserrepo:  webuserrepo,
-		roleRepo:     roleRepo,
+		accessCache: sessiondomain.RoleAccessCache{
+			DataMap: make(map[string]*permissionutil.Matcher),
+		},
+		apiKeyCache: sessiondomain.ApiKeyCache{
+			DataMap: make(map[string]*sessiondomain.ApiKeyAccess),
+		},
+		challengeCache: sessiondomain.LoginChallengeCache{
+			DataMap: make(map[string]*sessiondomain.LoginChallenge),
+		},
+		sessionCache: sessiondomain.SessionCache{
+			DataMap: make(map[string]*sessiondomain.Session),
+		},
+		attemptCache: sessiondomain.LoginAttemptCache{
+			DataMap: make(map[string]*sessiondomain.LoginAttempt),
+		},
+		configRepo:       configRepo,
+		webuserrepo:      webuserrepo,
+		roleRepo:         roleRepo,
+		loginHistoryRepo: loginHistoryRepo,
+		apiKeyRepo:       apiKeyRepo,
 	}
 
 	go uc.removeExpiredSession()
@@ -50,111 +92,528 @@ func New(configRepo configrepo.ConfigRepo, webuserrepo webuserrepo.WebUserRepo,
 func (uc *Usecase) removeExpiredSession() {
 	for {
 		now := time.Now()
-		count := 0
+		var expired []*sessiondomain.Session
+		uc.sessionCache.Lock()
 		for key, value := range uc.sessionCache.DataMap {
 			if now.After(value.ExpiredTime) {
-				count++
-				uc.sessionCache.Lock()
+				expired = append(expired, value)
 				delete(uc.sessionCache.DataMap, key)
-				uc.sessionCache.Unlock()
 			}
 		}
+		uc.sessionCache.Unlock()
+		uc.removeStaleAttempts(now)
+		uc.removeExpiredChallenges(now)
+		uc.removeExpiredApiKeys(now)
+
+		count := len(expired)
+		for _, session := range expired {
+			uc.recordLogout(tenantutil.NewContext(context.Background(), session.Tenant), session, sessiondomain.LOGOUT_REASON_EXPIRED)
+		}
 
 		logrus.Printf("session sweep finished, %d sessions deleted", count)
 		time.Sleep(time.Second * 60)
 	}
 }
 
-func (uc *Usecase) Login(username string, password string) (*sessiondomain.Session, error) {
-	webuser := uc.webuserrepo.FindByUsername(username)
+func (uc *Usecase) Login(ctx context.Context, username, password, clientIP, userAgent string) (*sessiondomain.Session, *sessiondomain.LoginChallenge, error) {
+	history := &sessiondomain.LoginHistory{
+		ID:        strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Username:  username,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		LoginTime: time.Now(),
+	}
+
+	now := history.LoginTime
+	if wait := uc.loginWait(ctx, username, clientIP, now); wait > 0 {
+		history.FailureReason = sessiondomain.FAILURE_REASON_THROTTLED
+		uc.recordLogin(ctx, history)
+		return nil, nil, restutil.ErrTooManyRequests(i18nutil.T(ctx, i18nutil.ERR_LOGIN_THROTTLED, int(math.Ceil(wait.Seconds()))), wait)
+	}
+
+	webuser := uc.webuserrepo.FindByUsername(ctx, username)
 	if webuser == nil {
-		return nil, fmt.Errorf("User atau Password yang dimasukan salah")
+		uc.registerFailure(ctx, username, clientIP, now)
+		history.FailureReason = sessiondomain.FAILURE_REASON_UNKNOWN_USER
+		uc.recordLogin(ctx, history)
+		return nil, nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_LOGIN_INVALID))
+	}
+	history.WebUserID = webuser.ID
+
+	if webuser.LockedUntil != nil && now.Before(*webuser.LockedUntil) {
+		history.FailureReason = sessiondomain.FAILURE_REASON_LOCKED
+		uc.recordLogin(ctx, history)
+		return nil, nil, restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_USER_LOCKED, int(math.Ceil(webuser.LockedUntil.Sub(now).Minutes()))))
 	}
 
 	if !webuser.Active {
-		return nil, fmt.Errorf("User sudah tidak aktif")
+		history.FailureReason = sessiondomain.FAILURE_REASON_INACTIVE
+		uc.recordLogin(ctx, history)
+		return nil, nil, restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_USER_INACTIVE))
+	}
+
+	if webuser.ServiceAccount {
+		uc.registerFailure(ctx, username, clientIP, now)
+		history.FailureReason = sessiondomain.FAILURE_REASON_SERVICE_ACCOUNT
+		uc.recordLogin(ctx, history)
+		return nil, nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_LOGIN_INVALID))
+	}
+
+	if passwordutil.Hash(password, webuser.PasswordSalt) != webuser.PasswordHash {
+		uc.registerFailure(ctx, username, clientIP, now)
+		history.FailureReason = sessiondomain.FAILURE_REASON_INVALID_PASSWORD
+		uc.recordLogin(ctx, history)
+
+		// an expired lock starts the count over
+		failures := webuser.FailedLoginCount + 1
+		if webuser.LockedUntil != nil {
+			failures = 1
+		}
+		lockedUntil := uc.lockUntil(ctx, failures, now)
+		if err := uc.webuserrepo.RecordLoginFailure(ctx, webuser.ID, failures, lockedUntil); err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+		}
+		if lockedUntil != nil {
+			return nil, nil, restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_USER_LOCKED, int(uc.lockDuration(ctx).Minutes())))
+		}
+		return nil, nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_LOGIN_INVALID))
 	}
 
-	hasher := sha256.New()
-	hasher.Write([]byte(password + webuser.PasswordSalt))
-	passwordHash := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+	uc.ClearLoginAttempts(ctx, username)
+	if webuser.FailedLoginCount > 0 || webuser.LockedUntil != nil {
+		if err := uc.webuserrepo.ResetLoginFailure(ctx, webuser.ID); err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+		}
+	}
 
-	if passwordHash != webuser.PasswordHash {
-		return nil, fmt.Errorf("User atau Password yang dimasukan salah")
+	if webuser.TotpEnabled {
+		return nil, uc.newChallenge(ctx, webuser, now), nil
 	}
+	session, err := uc.createSession(ctx, webuser, history)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, nil, nil
+}
 
-	role := uc.roleRepo.Find(webuser.RoleId)
-	menus, err := uc.roleRepo.FindMenu(webuser.RoleId)
-	permissionPaths, err := uc.roleRepo.FindActivePermissionPaths(webuser.RoleId)
+// createSession logs the user in, the login history is recorded as a success. The branches scope what the user sees, the
+// login fails when they cannot be read
+func (uc *Usecase) createSession(ctx context.Context, webuser *webuserdomain.WebUser, history *sessiondomain.LoginHistory) (*sessiondomain.Session, error) {
+	role, menus, access, err := uc.findRoleAccess(ctx, webuser.RoleId)
 	if err != nil {
-		logrus.Error(err.Error())
+		logutil.WithContext(ctx).Error(err.Error())
+		role = &roledomain.Role{}
+	}
+	branches, err := uc.findBranches(ctx, webuser.ID)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_INTERNAL_SERVER), err)
 	}
 
 	token := strings.ReplaceAll(uuid.NewString(), "-", "")
-	minute, _ := strconv.Atoi(uc.configRepo.GetValue(configdomain.SESSION_TIMEOUT_MINUTE))
-	expiredTime := time.Now().Add(time.Minute * time.Duration(minute))
+	expiredTime := time.Now().Add(uc.sessionTimeout(ctx))
+
+	history.Success = true
+	history.FailureReason = ""
+	uc.recordLogin(ctx, history)
 
 	session := &sessiondomain.Session{
-		Token:       token,
-		ExpiredTime: expiredTime,
-		UserID:      webuser.ID,
-		RoleID:      webuser.RoleId,
-		UserName:    webuser.Username,
-		Name:        webuser.Name,
-		RoleName:    role.Name,
-		Menu:        menus,
-		Permissions: permissionPaths,
+		ID:                     history.ID,
+		Token:                  token,
+		ExpiredTime:            expiredTime,
+		LoginTime:              history.LoginTime,
+		LastActivity:           history.LoginTime,
+		ClientIP:               history.ClientIP,
+		UserAgent:              history.UserAgent,
+		UserID:                 webuser.ID,
+		RoleID:                 webuser.RoleId,
+		UserName:               webuser.Username,
+		Name:                   webuser.Name,
+		RoleName:               role.Name,
+		Language:               webuser.Language,
+		Menu:                   menus,
+		Access:                 access,
+		TwoFactorEnabled:       webuser.TotpEnabled,
+		TwoFactorSetupRequired: role.RequireTwoFactor && !webuser.TotpEnabled,
+		AllData:                role.AllData,
+		PasswordChangeRequired: uc.passwordChangeRequired(ctx, webuser, history.LoginTime),
+		Tenant:                 tenantutil.Code(ctx),
 	}
+	applyBranches(session, branches, "")
 
 	uc.sessionCache.Lock()
-	uc.sessionCache.DataMap[token] = session
+	uc.sessionCache.DataMap[tenantutil.Key(ctx, token)] = session
 	uc.sessionCache.Unlock()
 
 	return session, nil
 }
 
-func (uc *Usecase) AuthCheck(token string, requestorPath string) (string, int, *sessiondomain.Session) {
+// sessionTimeout is how long a session of the tenant of ctx lives without activity
+func (uc *Usecase) sessionTimeout(ctx context.Context) time.Duration {
+	return time.Duration(uc.configInt(ctx, configdomain.SESSION_TIMEOUT_MINUTE, DEFAULT_SESSION_TIMEOUT_MINUTE)) * time.Minute
+}
+
+func (uc *Usecase) AuthCheck(ctx context.Context, token string, method string, requestorPath string) (string, int, *sessiondomain.Session) {
 	if token == "" {
-		return uc.configRepo.GetValue(configdomain.LOGIN_URL), 301, nil
+		return uc.configRepo.GetValue(ctx, configdomain.LOGIN_URL), 301, nil
 	}
 
-	session, ok := uc.sessionCache.DataMap[token]
+	uc.sessionCache.RLock()
+	session, ok := uc.sessionCache.DataMap[tenantutil.Key(ctx, token)]
+	uc.sessionCache.RUnlock()
 	if !ok {
-		return uc.configRepo.GetValue(configdomain.UNAUTHORIZED_URL), 401, nil
+		return uc.configRepo.GetValue(ctx, configdomain.UNAUTHORIZED_URL), 401, nil
 	}
 
-	authorized := false
-	for _, permission := range session.Permissions {
-		paths := strings.Split(permission, ";")
-		for _, path := range paths {
-			if requestorPath == path {
-				authorized = true
-				break
-			}
-		}
-		if authorized {
-			break
-		}
+	if session.PasswordChangeRequired || session.TwoFactorSetupRequired {
+		return uc.configRepo.GetValue(ctx, configdomain.FORBIDDEN_URL), 403, nil
 	}
 
-	if !authorized {
-		return uc.configRepo.GetValue(configdomain.FORBIDDEN_URL), 403, nil
+	if !session.Access.Allowed(method, requestorPath) {
+		return uc.configRepo.GetValue(ctx, configdomain.FORBIDDEN_URL), 403, nil
 	}
 
-	session.ExpiredTime = time.Now().Add(time.Minute * time.Duration(global.SESSION_TIMEOUT_MINUTE))
+	session.LastActivity = time.Now()
+	session.ExpiredTime = session.LastActivity.Add(uc.sessionTimeout(ctx))
 	return "", 200, session
 }
 
-func (uc *Usecase) Logout(token string) {
+func (uc *Usecase) Logout(ctx context.Context, token string) {
 	uc.sessionCache.Lock()
-	delete(uc.sessionCache.DataMap, token)
+	session, ok := uc.sessionCache.DataMap[tenantutil.Key(ctx, token)]
+	delete(uc.sessionCache.DataMap, tenantutil.Key(ctx, token))
 	uc.sessionCache.Unlock()
+
+	if ok {
+		uc.recordLogout(ctx, session, sessiondomain.LOGOUT_REASON_LOGOUT)
+	}
 }
 
-func (uc *Usecase) GetSession(token string) *sessiondomain.Session {
-	if session, ok := uc.sessionCache.DataMap[token]; ok {
-		session.ExpiredTime = time.Now().Add(time.Minute * time.Duration(global.SESSION_TIMEOUT_MINUTE))
+func (uc *Usecase) GetSession(ctx context.Context, token string) *sessiondomain.Session {
+	uc.sessionCache.RLock()
+	session, ok := uc.sessionCache.DataMap[tenantutil.Key(ctx, token)]
+	uc.sessionCache.RUnlock()
+	if ok {
+		session.LastActivity = time.Now()
+		session.ExpiredTime = session.LastActivity.Add(uc.sessionTimeout(ctx))
 		return session
 	}
 	return nil
 }
+
+// RefreshRole re-resolves the role name, menu and permissions of every cached session of the role
+func (uc *Usecase) RefreshRole(ctx context.Context, roleID string) {
+	uc.accessCache.Lock()
+	delete(uc.accessCache.DataMap, tenantutil.Key(ctx, roleID))
+	uc.accessCache.Unlock()
+	uc.removeApiKeys(ctx, func(session *sessiondomain.Session) bool { return session.RoleID == roleID })
+
+	role, menus, access, err := uc.findRoleAccess(ctx, roleID)
+	if err != nil {
+		// a session keeping the access the role had before would outlive the change, its users log in again instead
+		logutil.WithContext(ctx).Errorf("refresh of role %s failed: %s", roleID, err.Error())
+		uc.removeSessions(ctx, func(session *sessiondomain.Session) bool { return session.RoleID == roleID }, sessiondomain.LOGOUT_REASON_REFRESH_FAILED)
+		return
+	}
+
+	count := 0
+	uc.sessionCache.Lock()
+	for token, session := range uc.sessionCache.DataMap {
+		if session.Tenant != tenantutil.Code(ctx) || session.RoleID != roleID {
+			continue
+		}
+		// sessions are replaced instead of modified since requests in flight still read the old one
+		refreshed := *session
+		refreshed.RoleName = role.Name
+		refreshed.Menu = menus
+		refreshed.Access = access
+		refreshed.TwoFactorSetupRequired = role.RequireTwoFactor && !session.TwoFactorEnabled
+		refreshed.AllData = role.AllData
+		uc.sessionCache.DataMap[token] = &refreshed
+		count++
+	}
+	uc.sessionCache.Unlock()
+
+	logutil.WithContext(ctx).Infof("%d sessions of role %s refreshed", count, roleID)
+}
+
+// RefreshUser updates every cached session of the user, deactivated users are logged out
+func (uc *Usecase) RefreshUser(ctx context.Context, userID string) {
+	uc.removeApiKeys(ctx, func(session *sessiondomain.Session) bool { return session.UserID == userID })
+
+	webuser := uc.webuserrepo.Find(ctx, userID)
+	if webuser == nil || webuser.ID == "" || !webuser.Active {
+		uc.removeUserSessions(ctx, userID, sessiondomain.LOGOUT_REASON_DEACTIVATED)
+		return
+	}
+
+	// a session keeping the access or the branches the user had before would outlive the change, the user logs in again instead
+	role, menus, access, err := uc.findRoleAccess(ctx, webuser.RoleId)
+	if err != nil {
+		logutil.WithContext(ctx).Errorf("refresh of user %s failed: %s", userID, err.Error())
+		uc.removeUserSessions(ctx, userID, sessiondomain.LOGOUT_REASON_REFRESH_FAILED)
+		return
+	}
+	branches, err := uc.findBranches(ctx, userID)
+	if err != nil {
+		logutil.WithContext(ctx).Errorf("refresh of user %s failed: %s", userID, err.Error())
+		uc.removeUserSessions(ctx, userID, sessiondomain.LOGOUT_REASON_REFRESH_FAILED)
+		return
+	}
+
+	count := 0
+	uc.sessionCache.Lock()
+	for token, session := range uc.sessionCache.DataMap {
+		if session.Tenant != tenantutil.Code(ctx) || session.UserID != userID {
+			continue
+		}
+		refreshed := *session
+		refreshed.RoleID = webuser.RoleId
+		refreshed.UserName = webuser.Username
+		refreshed.Name = webuser.Name
+		refreshed.Language = webuser.Language
+		refreshed.RoleName = role.Name
+		refreshed.Menu = menus
+		refreshed.Access = access
+		refreshed.TwoFactorEnabled = webuser.TotpEnabled
+		refreshed.TwoFactorSetupRequired = role.RequireTwoFactor && !webuser.TotpEnabled
+		refreshed.AllData = role.AllData
+		refreshed.PasswordChangeRequired = uc.passwordChangeRequired(ctx, webuser, time.Now())
+		applyBranches(&refreshed, branches, session.BranchID)
+		uc.sessionCache.DataMap[token] = &refreshed
+		count++
+	}
+	uc.sessionCache.Unlock()
+
+	logutil.WithContext(ctx).Infof("%d sessions of user %s refreshed", count, userID)
+}
+
+// SelectBranch switches the active branch of the session to one of the branches assigned to the user
+func (uc *Usecase) SelectBranch(ctx context.Context, token string, branchID string) (*sessiondomain.Session, error) {
+	uc.sessionCache.Lock()
+	defer uc.sessionCache.Unlock()
+
+	session, ok := uc.sessionCache.DataMap[tenantutil.Key(ctx, token)]
+	if !ok {
+		return nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_LOGIN_REQUIRED))
+	}
+
+	for _, branch := range session.Branches {
+		if branch.ID == branchID {
+			refreshed := *session
+			refreshed.BranchID = branch.ID
+			refreshed.BranchName = branch.Name
+			uc.sessionCache.DataMap[tenantutil.Key(ctx, token)] = &refreshed
+			return &refreshed, nil
+		}
+	}
+	return nil, restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_BRANCH_NOT_ASSIGNED))
+}
+
+// findBranches returns the active branches assigned to the user
+func (uc *Usecase) findBranches(ctx context.Context, userID string) ([]*branchdomain.Branch, error) {
+	assigned, err := uc.webuserrepo.FindBranches(ctx, userID)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, err
+	}
+
+	branches := []*branchdomain.Branch{}
+	for _, branch := range assigned {
+		if branch.Active {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// applyBranches sets the branches of the session, the current branch stays active while it is still assigned and the first one otherwise
+func applyBranches(session *sessiondomain.Session, branches []*branchdomain.Branch, current string) {
+	session.Branches = branches
+	session.BranchID = ""
+	session.BranchName = ""
+	for _, branch := range branches {
+		if session.BranchID == "" || branch.ID == current {
+			session.BranchID = branch.ID
+			session.BranchName = branch.Name
+		}
+	}
+}
+
+func (uc *Usecase) removeUserSessions(ctx context.Context, userID string, reason string) int {
+	removed := uc.removeSessions(ctx, func(session *sessiondomain.Session) bool { return session.UserID == userID }, reason)
+	logutil.WithContext(ctx).Infof("%d sessions of user %s logged out", removed, userID)
+	return removed
+}
+
+// removeSessions logs out the sessions of the tenant of ctx that match
+func (uc *Usecase) removeSessions(ctx context.Context, match func(session *sessiondomain.Session) bool, reason string) int {
+	var removed []*sessiondomain.Session
+	uc.sessionCache.Lock()
+	for token, session := range uc.sessionCache.DataMap {
+		if session.Tenant == tenantutil.Code(ctx) && match(session) {
+			delete(uc.sessionCache.DataMap, token)
+			removed = append(removed, session)
+		}
+	}
+	uc.sessionCache.Unlock()
+
+	for _, session := range removed {
+		uc.recordLogout(ctx, session, reason)
+	}
+	return len(removed)
+}
+
+// passwordChangeRequired reports whether an admin set the password or the password expired
+func (uc *Usecase) passwordChangeRequired(ctx context.Context, webuser *webuserdomain.WebUser, now time.Time) bool {
+	if webuser.MustChangePassword {
+		return true
+	}
+
+	expiryDay := uc.configInt(ctx, configdomain.PASSWORD_EXPIRY_DAY, 0)
+	if expiryDay == 0 {
+		return false
+	}
+	changedTime := webuser.RegistrationTimestamp
+	if webuser.PasswordChangedTime != nil {
+		changedTime = *webuser.PasswordChangedTime
+	}
+	return now.After(changedTime.AddDate(0, 0, expiryDay))
+}
+
+// findRoleAccess returns the role with its menu and permission matcher, the matcher is built once per role
+func (uc *Usecase) findRoleAccess(ctx context.Context, roleID string) (*roledomain.Role, []*sessiondomain.Menu, *permissionutil.Matcher, error) {
+	role := uc.roleRepo.Find(ctx, roleID)
+	menus, err := uc.roleRepo.FindMenu(ctx, roleID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	uc.accessCache.RLock()
+	access, ok := uc.accessCache.DataMap[tenantutil.Key(ctx, roleID)]
+	uc.accessCache.RUnlock()
+	if ok {
+		return role, menus, access, nil
+	}
+
+	apis, err := uc.roleRepo.FindActivePermissionApis(ctx, roleID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var rules []permissionutil.Rule
+	for _, api := range apis {
+		rules = append(rules, permissionutil.Rule{Method: api.Method, Path: api.Path})
+	}
+	access = permissionutil.NewMatcher(rules)
+
+	uc.accessCache.Lock()
+	uc.accessCache.DataMap[tenantutil.Key(ctx, roleID)] = access
+	uc.accessCache.Unlock()
+
+	return role, menus, access, nil
+}
+
+// FindSessions lists the active sessions, of one user when userID is given, most recent login first
+func (uc *Usecase) FindSessions(ctx context.Context, userID string) []*sessiondomain.SessionInfo {
+	sessions := []*sessiondomain.SessionInfo{}
+	uc.sessionCache.RLock()
+	for _, session := range uc.sessionCache.DataMap {
+		if session.Tenant != tenantutil.Code(ctx) || (userID != "" && session.UserID != userID) {
+			continue
+		}
+		sessions = append(sessions, &sessiondomain.SessionInfo{
+			ID:           session.ID,
+			UserID:       session.UserID,
+			UserName:     session.UserName,
+			Name:         session.Name,
+			RoleName:     session.RoleName,
+			LoginTime:    session.LoginTime,
+			LastActivity: session.LastActivity,
+			ClientIP:     session.ClientIP,
+			UserAgent:    session.UserAgent,
+		})
+	}
+	uc.sessionCache.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LoginTime.After(sessions[j].LoginTime)
+	})
+	return sessions
+}
+
+// Revoke logs out the session with the given id
+func (uc *Usecase) Revoke(ctx context.Context, id string) error {
+	var revoked *sessiondomain.Session
+	uc.sessionCache.Lock()
+	for token, session := range uc.sessionCache.DataMap {
+		if session.Tenant == tenantutil.Code(ctx) && session.ID == id {
+			revoked = session
+			delete(uc.sessionCache.DataMap, token)
+			break
+		}
+	}
+	uc.sessionCache.Unlock()
+
+	if revoked == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_SESSION_NOT_FOUND))
+	}
+
+	uc.recordLogout(ctx, revoked, sessiondomain.LOGOUT_REASON_REVOKED)
+	return nil
+}
+
+// RevokeUser logs out every session of the user
+func (uc *Usecase) RevokeUser(ctx context.Context, userID string) error {
+	if uc.removeUserSessions(ctx, userID, sessiondomain.LOGOUT_REASON_REVOKED) == 0 {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_SESSION_NOT_FOUND))
+	}
+	return nil
+}
+
+func (uc *Usecase) FindLoginHistory(ctx context.Context, userID, startDate, endDate string, list queryutil.ListParam) ([]*sessiondomain.LoginHistory, int64, error) {
+	var params []queryutil.Param
+	if userID != "" {
+		params = append(params, queryutil.Param{
+			Logic:    "AND",
+			Field:    "web_user_id",
+			Operator: "=",
+			Value:    userID,
+		})
+	}
+	if startDate != "" {
+		params = append(params, queryutil.Param{
+			Logic:    "AND",
+			Field:    "login_time::date",
+			Operator: ">=",
+			Value:    startDate,
+		})
+	}
+	if endDate != "" {
+		params = append(params, queryutil.Param{
+			Logic:    "AND",
+			Field:    "login_time::date",
+			Operator: "<=",
+			Value:    endDate,
+		})
+	}
+
+	histories, total, err := uc.loginHistoryRepo.FindList(ctx, params, list)
+	if err != nil {
+		return nil, 0, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return histories, total, nil
+}
+
+// recordLogin writes the login attempt, a failure to write it must not block the login
+func (uc *Usecase) recordLogin(ctx context.Context, history *sessiondomain.LoginHistory) {
+	err := uc.loginHistoryRepo.Create(ctx, history)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+	}
+}
+
+func (uc *Usecase) recordLogout(ctx context.Context, session *sessiondomain.Session, reason string) {
+	err := uc.loginHistoryRepo.Logout(ctx, session.ID, time.Now(), reason)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+	}
+}
@@ -0,0 +1,104 @@
+package sessionusecase
+
+import (
+	"context"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	roledomain "dromatech/pos-backend/internal/domain/role"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	loginhistoryrepo "dromatech/pos-backend/internal/repo/loginhistory"
+	rolerepo "dromatech/pos-backend/internal/repo/role"
+	webuserrepo "dromatech/pos-backend/internal/repo/webuser"
+	permissionutil "dromatech/pos-backend/internal/util/permission"
+	"errors"
+	"testing"
+	"time"
+)
+
+type refreshUserRepo struct {
+	webuserrepo.WebUserRepo
+	branchErr error
+}
+
+func (r *refreshUserRepo) Find(ctx context.Context, id string) *webuserdomain.WebUser {
+	return &webuserdomain.WebUser{ID: id, RoleId: "R1", Active: true}
+}
+
+func (r *refreshUserRepo) FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error) {
+	if r.branchErr != nil {
+		return nil, r.branchErr
+	}
+	return []*branchdomain.Branch{{ID: "B2", Name: "Cabang 2", Active: true}}, nil
+}
+
+type refreshRoleRepo struct {
+	rolerepo.RoleRepo
+	menuErr error
+}
+
+func (r *refreshRoleRepo) Find(ctx context.Context, id string) *roledomain.Role {
+	return &roledomain.Role{ID: id, Name: "Kasir"}
+}
+
+func (r *refreshRoleRepo) FindMenu(ctx context.Context, roleId string) ([]*sessiondomain.Menu, error) {
+	return nil, r.menuErr
+}
+
+// logoutRepo keeps the reason of every logout
+type logoutRepo struct {
+	loginhistoryrepo.LoginHistoryRepo
+	reasons map[string]string
+}
+
+func (r *logoutRepo) Logout(ctx context.Context, id string, logoutTime time.Time, reason string) error {
+	r.reasons[id] = reason
+	return nil
+}
+
+func TestRefreshUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		menuErr   error
+		branchErr error
+		kept      bool
+	}{
+		{"refreshed", nil, nil, true},
+		// a session that cannot be refreshed would keep the revoked access or branches, it is logged out instead
+		{"role access fails", errors.New("db down"), nil, false},
+		{"branches fail", nil, errors.New("db down"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logouts := &logoutRepo{reasons: map[string]string{}}
+			uc := &Usecase{
+				sessionCache: sessiondomain.SessionCache{DataMap: map[string]*sessiondomain.Session{
+					"T1": {ID: "S1", UserID: "U1", RoleID: "R1", BranchID: "B1"},
+					"T2": {ID: "S2", UserID: "U2", RoleID: "R1", BranchID: "B1"},
+				}},
+				accessCache:      sessiondomain.RoleAccessCache{DataMap: map[string]*permissionutil.Matcher{"R1": permissionutil.NewMatcher(nil)}},
+				apiKeyCache:      sessiondomain.ApiKeyCache{DataMap: map[string]*sessiondomain.ApiKeyAccess{}},
+				configRepo:       &tenantConfig{},
+				webuserrepo:      &refreshUserRepo{branchErr: tt.branchErr},
+				roleRepo:         &refreshRoleRepo{menuErr: tt.menuErr},
+				loginHistoryRepo: logouts,
+			}
+
+			uc.RefreshUser(context.Background(), "U1")
+
+			session, kept := uc.sessionCache.DataMap["T1"]
+			if kept != tt.kept {
+				t.Fatalf("session kept %v, want %v", kept, tt.kept)
+			}
+			if kept && (session.RoleName != "Kasir" || session.BranchID != "B2") {
+				t.Errorf("session role %q, branch %q, want Kasir, B2", session.RoleName, session.BranchID)
+			}
+			if !kept && logouts.reasons["S1"] != sessiondomain.LOGOUT_REASON_REFRESH_FAILED {
+				t.Errorf("logout reason %q, want %s", logouts.reasons["S1"], sessiondomain.LOGOUT_REASON_REFRESH_FAILED)
+			}
+			if _, ok := uc.sessionCache.DataMap["T2"]; !ok {
+				t.Error("the session of another user was removed")
+			}
+		})
+	}
+}
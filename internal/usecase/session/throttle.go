@@ -0,0 +1,128 @@
+package sessionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ATTEMPT_KEY_USER = "user:"
+const ATTEMPT_KEY_IP = "ip:"
+
+const DEFAULT_LOGIN_MAX_ATTEMPT = 5
+const DEFAULT_LOGIN_LOCK_MINUTE = 15
+const DEFAULT_LOGIN_IP_MAX_ATTEMPT = 20
+const DEFAULT_LOGIN_BACKOFF_SECOND = 1
+const DEFAULT_LOGIN_BACKOFF_MAX_SECOND = 60
+
+// loginWait returns how long the username and the client IP have to wait before the next login attempt
+func (uc *Usecase) loginWait(ctx context.Context, username, clientIP string, now time.Time) time.Duration {
+	uc.attemptCache.Lock()
+	defer uc.attemptCache.Unlock()
+
+	wait := uc.attemptWait(ctx, userAttemptKey(ctx, username), now, 0)
+	ipWait := uc.attemptWait(ctx, ipAttemptKey(ctx, clientIP), now, uc.configInt(ctx, configdomain.LOGIN_IP_MAX_ATTEMPT, DEFAULT_LOGIN_IP_MAX_ATTEMPT))
+	if ipWait > wait {
+		wait = ipWait
+	}
+	return wait
+}
+
+// attemptWait applies exponential backoff to consecutive failures, once maxFailures is reached the key waits for the whole lock window
+func (uc *Usecase) attemptWait(ctx context.Context, key string, now time.Time, maxFailures int) time.Duration {
+	attempt, ok := uc.attemptCache.DataMap[key]
+	if !ok {
+		return 0
+	}
+
+	until := attempt.LastFailure.Add(uc.backoff(ctx, attempt.Failures))
+	if maxFailures > 0 && attempt.Failures >= maxFailures {
+		until = attempt.LastFailure.Add(uc.lockDuration(ctx))
+	}
+	return until.Sub(now)
+}
+
+func (uc *Usecase) backoff(ctx context.Context, failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	max := time.Duration(uc.configInt(ctx, configdomain.LOGIN_BACKOFF_MAX_SECOND, DEFAULT_LOGIN_BACKOFF_MAX_SECOND)) * time.Second
+	backoff := time.Duration(uc.configInt(ctx, configdomain.LOGIN_BACKOFF_SECOND, DEFAULT_LOGIN_BACKOFF_SECOND)) * time.Second
+	for i := 1; i < failures && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// registerFailure counts a failed attempt for both the username and the client IP
+func (uc *Usecase) registerFailure(ctx context.Context, username, clientIP string, now time.Time) {
+	uc.attemptCache.Lock()
+	defer uc.attemptCache.Unlock()
+
+	for _, key := range []string{userAttemptKey(ctx, username), ipAttemptKey(ctx, clientIP)} {
+		attempt, ok := uc.attemptCache.DataMap[key]
+		if !ok || now.Sub(attempt.LastFailure) > uc.lockDuration(ctx) {
+			attempt = &sessiondomain.LoginAttempt{}
+			uc.attemptCache.DataMap[key] = attempt
+		}
+		attempt.Failures++
+		attempt.LastFailure = now
+		attempt.ExpiredTime = now.Add(uc.lockDuration(ctx))
+	}
+}
+
+// ClearLoginAttempts forgets the failed attempts of the username, the client IPs keep theirs
+func (uc *Usecase) ClearLoginAttempts(ctx context.Context, username string) {
+	uc.attemptCache.Lock()
+	delete(uc.attemptCache.DataMap, userAttemptKey(ctx, username))
+	uc.attemptCache.Unlock()
+}
+
+// removeStaleAttempts forgets the attempts whose lock window is over, each attempt keeps the window of its own tenant
+func (uc *Usecase) removeStaleAttempts(now time.Time) {
+	uc.attemptCache.Lock()
+	for key, attempt := range uc.attemptCache.DataMap {
+		if now.After(attempt.ExpiredTime) {
+			delete(uc.attemptCache.DataMap, key)
+		}
+	}
+	uc.attemptCache.Unlock()
+}
+
+// lockUntil returns until when the user is locked after the given number of consecutive failures, nil while under the limit
+func (uc *Usecase) lockUntil(ctx context.Context, failures int, now time.Time) *time.Time {
+	if failures < uc.configInt(ctx, configdomain.LOGIN_MAX_ATTEMPT, DEFAULT_LOGIN_MAX_ATTEMPT) {
+		return nil
+	}
+	lockedUntil := now.Add(uc.lockDuration(ctx))
+	return &lockedUntil
+}
+
+func (uc *Usecase) lockDuration(ctx context.Context) time.Duration {
+	return time.Duration(uc.configInt(ctx, configdomain.LOGIN_LOCK_MINUTE, DEFAULT_LOGIN_LOCK_MINUTE)) * time.Minute
+}
+
+func (uc *Usecase) configInt(ctx context.Context, key string, defaultValue int) int {
+	value, err := strconv.Atoi(uc.configRepo.GetValue(ctx, key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// userAttemptKey and ipAttemptKey count the attempts of a tenant apart from the other tenants
+func userAttemptKey(ctx context.Context, username string) string {
+	return tenantutil.Key(ctx, ATTEMPT_KEY_USER+strings.ToLower(username))
+}
+
+func ipAttemptKey(ctx context.Context, clientIP string) string {
+	return tenantutil.Key(ctx, ATTEMPT_KEY_IP+clientIP)
+}
@@ -0,0 +1,58 @@
+package sessionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	configrepo "dromatech/pos-backend/internal/repo/config"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"testing"
+	"time"
+)
+
+// tenantConfig returns the lock window of each tenant, the other config is left to the embedded nil repo
+type tenantConfig struct {
+	configrepo.ConfigRepo
+	lockMinute map[string]string
+}
+
+func (c *tenantConfig) GetValue(ctx context.Context, key string) string {
+	if key != configdomain.LOGIN_LOCK_MINUTE {
+		return ""
+	}
+	return c.lockMinute[tenantutil.Code(ctx)]
+}
+
+func TestRemoveStaleAttempts(t *testing.T) {
+	uc := &Usecase{
+		attemptCache: sessiondomain.LoginAttemptCache{DataMap: make(map[string]*sessiondomain.LoginAttempt)},
+		configRepo:   &tenantConfig{lockMinute: map[string]string{"": "10", "acme": "60"}},
+	}
+	failed := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	uc.registerFailure(context.Background(), "budi", "10.0.0.1", failed)
+	uc.registerFailure(tenantutil.NewContext(context.Background(), "acme"), "budi", "10.0.0.1", failed)
+
+	tests := []struct {
+		name        string
+		after       time.Duration
+		keepDefault bool
+		keepAcme    bool
+	}{
+		{"both windows open", 5 * time.Minute, true, true},
+		// the attempts of acme keep its own window, not the one of the default tenant
+		{"default window over", 30 * time.Minute, false, true},
+		{"both windows over", 61 * time.Minute, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.removeStaleAttempts(failed.Add(tt.after))
+
+			_, defaultKept := uc.attemptCache.DataMap[userAttemptKey(context.Background(), "budi")]
+			_, acmeKept := uc.attemptCache.DataMap[userAttemptKey(tenantutil.NewContext(context.Background(), "acme"), "budi")]
+			if defaultKept != tt.keepDefault || acmeKept != tt.keepAcme {
+				t.Errorf("default kept %v, acme kept %v, want %v, %v", defaultKept, acmeKept, tt.keepDefault, tt.keepAcme)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package sessionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	totputil "dromatech/pos-backend/internal/util/totp"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DEFAULT_TOTP_CHALLENGE_MINUTE = 5
+
+// CHALLENGE_MAX_ATTEMPT is how many wrong codes a challenge accepts before the password has to be entered again
+const CHALLENGE_MAX_ATTEMPT = 5
+
+// newChallenge starts the second login step of a user whose password was accepted
+func (uc *Usecase) newChallenge(ctx context.Context, webuser *webuserdomain.WebUser, now time.Time) *sessiondomain.LoginChallenge {
+	challenge := &sessiondomain.LoginChallenge{
+		Token:             strings.ReplaceAll(uuid.NewString(), "-", ""),
+		TwoFactorRequired: true,
+		ExpiredTime:       now.Add(time.Duration(uc.configInt(ctx, configdomain.TOTP_CHALLENGE_MINUTE, DEFAULT_TOTP_CHALLENGE_MINUTE)) * time.Minute),
+		UserID:            webuser.ID,
+		Username:          webuser.Username,
+	}
+
+	uc.challengeCache.Lock()
+	uc.challengeCache.DataMap[tenantutil.Key(ctx, challenge.Token)] = challenge
+	uc.challengeCache.Unlock()
+
+	return challenge
+}
+
+// VerifyLogin finishes the login of a challenge with a TOTP code or an unused recovery code
+func (uc *Usecase) VerifyLogin(ctx context.Context, challengeToken, code, clientIP, userAgent string) (*sessiondomain.Session, error) {
+	now := time.Now()
+
+	uc.challengeCache.Lock()
+	challenge, ok := uc.challengeCache.DataMap[tenantutil.Key(ctx, challengeToken)]
+	uc.challengeCache.Unlock()
+	if !ok || now.After(challenge.ExpiredTime) {
+		return nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_TOTP_CHALLENGE_INVALID))
+	}
+
+	history := &sessiondomain.LoginHistory{
+		ID:        strings.ReplaceAll(uuid.NewString(), "-", ""),
+		WebUserID: challenge.UserID,
+		Username:  challenge.Username,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		LoginTime: now,
+	}
+
+	if wait := uc.loginWait(ctx, challenge.Username, clientIP, now); wait > 0 {
+		history.FailureReason = sessiondomain.FAILURE_REASON_THROTTLED
+		uc.recordLogin(ctx, history)
+		return nil, restutil.ErrTooManyRequests(i18nutil.T(ctx, i18nutil.ERR_LOGIN_THROTTLED, int(math.Ceil(wait.Seconds()))), wait)
+	}
+
+	webuser := uc.webuserrepo.Find(ctx, challenge.UserID)
+	if webuser == nil || webuser.ID == "" || !webuser.Active || !webuser.TotpEnabled {
+		uc.removeChallenge(ctx, challengeToken)
+		return nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_TOTP_CHALLENGE_INVALID))
+	}
+
+	if !uc.verifyCode(ctx, webuser, code) {
+		uc.registerFailure(ctx, challenge.Username, clientIP, now)
+		history.FailureReason = sessiondomain.FAILURE_REASON_INVALID_TOTP
+		uc.recordLogin(ctx, history)
+
+		uc.challengeCache.Lock()
+		challenge.Attempts++
+		if challenge.Attempts >= CHALLENGE_MAX_ATTEMPT {
+			delete(uc.challengeCache.DataMap, tenantutil.Key(ctx, challengeToken))
+		}
+		uc.challengeCache.Unlock()
+		return nil, restutil.ErrUnauthorized(i18nutil.T(ctx, i18nutil.ERR_TOTP_INVALID))
+	}
+
+	uc.removeChallenge(ctx, challengeToken)
+	uc.ClearLoginAttempts(ctx, challenge.Username)
+	return uc.createSession(ctx, webuser, history)
+}
+
+// verifyCode accepts the current TOTP code once, otherwise the code is tried as a recovery code which is then used up
+func (uc *Usecase) verifyCode(ctx context.Context, webuser *webuserdomain.WebUser, code string) bool {
+	if step, ok := totputil.Step(code, webuser.TotpSecret, time.Now()); ok {
+		used, err := uc.webuserrepo.UseTotpStep(ctx, webuser.ID, step)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return false
+		}
+		return used
+	}
+
+	used, err := uc.webuserrepo.UseRecoveryCode(ctx, webuser.ID, totputil.HashRecoveryCode(code))
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return false
+	}
+	return used
+}
+
+func (uc *Usecase) removeChallenge(ctx context.Context, challengeToken string) {
+	uc.challengeCache.Lock()
+	delete(uc.challengeCache.DataMap, tenantutil.Key(ctx, challengeToken))
+	uc.challengeCache.Unlock()
+}
+
+func (uc *Usecase) removeExpiredChallenges(now time.Time) {
+	uc.challengeCache.Lock()
+	for token, challenge := range uc.challengeCache.DataMap {
+		if now.After(challenge.ExpiredTime) {
+			delete(uc.challengeCache.DataMap, token)
+		}
+	}
+	uc.challengeCache.Unlock()
+}
@@ -1,22 +1,24 @@
 package supplierusecase
 
 import (
+	"context"
 	supplierdomain "dromatech/pos-backend/internal/domain/supplier"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
 	queryutil "dromatech/pos-backend/internal/util/query"
-	"fmt"
+	restutil "dromatech/pos-backend/internal/util/rest"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type SupplierUsecase interface {
-	Find(id, code, name string, active *bool) ([]*supplierdomain.Supplier, error)
-	Create(code, name, description string) error
-	Edit(id, code, name, description string, active bool) error
-	GetBuyPrice(supplierId, unitId, date, productId string) ([]*supplierdomain.BuyPriceResponse, error)
-	UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error
+	Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*supplierdomain.Supplier, int64, error)
+	Create(ctx context.Context, code, name, description string) error
+	Edit(ctx context.Context, id, code, name, description string, active bool) error
+	GetBuyPrice(ctx context.Context, supplierId, unitId, date, productId string) ([]*supplierdomain.BuyPriceResponse, error)
+	UpdateBuyPrice(ctx context.Context, request supplierdomain.BuyPriceRequest) error
 }
 
 type Usecase struct {
@@ -24,14 +26,15 @@ type Usecase struct {
 }
 
 type supplierRepo interface {
-	Find(params map[string]interface{}) ([]*supplierdomain.Supplier, error)
-	Create(product *supplierdomain.Supplier) error
-	Edit(product *supplierdomain.Supplier) error
-	GetBuyPrice(params []queryutil.Param) ([]*supplierdomain.BuyPriceResponse, error)
-	UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error
-	DeleteBuyPrice(supplierId, unitId, date string) error
-	AddBuyPrice(entity supplierdomain.AddPriceRequest) error
-	FindBuyPrice(params []queryutil.Param) ([]*supplierdomain.PriceResponse, error)
+	Find(ctx context.Context, params map[string]interface{}) ([]*supplierdomain.Supplier, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*supplierdomain.Supplier, int64, error)
+	Create(ctx context.Context, product *supplierdomain.Supplier) error
+	Edit(ctx context.Context, product *supplierdomain.Supplier) error
+	GetBuyPrice(ctx context.Context, params []queryutil.Param) ([]*supplierdomain.BuyPriceResponse, error)
+	UpdateBuyPrice(ctx context.Context, request supplierdomain.BuyPriceRequest) error
+	DeleteBuyPrice(ctx context.Context, supplierId, unitId, date string) error
+	AddBuyPrice(ctx context.Context, entity supplierdomain.AddPriceRequest) error
+	FindBuyPrice(ctx context.Context, params []queryutil.Param) ([]*supplierdomain.PriceResponse, error)
 }
 
 func New(supplierRepo supplierRepo) *Usecase {
@@ -42,32 +45,52 @@ func New(supplierRepo supplierRepo) *Usecase {
 	return uc
 }
 
-func (uc *Usecase) Find(id, code, name string, active *bool) ([]*supplierdomain.Supplier, error) {
-	param := make(map[string]interface{})
+func (uc *Usecase) Find(ctx context.Context, id, code, name string, active *bool, list queryutil.ListParam) ([]*supplierdomain.Supplier, int64, error) {
+	var param []queryutil.Param
 	if id != "" {
-		param["id"] = id
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "id",
+			Operator: "=",
+			Value:    id,
+		})
 	}
 	if code != "" {
-		param["code"] = code
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "code",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(code),
+		})
 	}
 	if name != "" {
-		param["name"] = name
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "name",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(name),
+		})
 	}
 	if active != nil {
-		param["active"] = active
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "active",
+			Operator: "=",
+			Value:    *active,
+		})
 	}
-	return uc.supplierRepo.Find(param)
+	return uc.supplierRepo.FindList(ctx, param, list)
 }
 
-func (uc *Usecase) Create(code, name, description string) error {
-	entities, err := uc.supplierRepo.Find(map[string]interface{}{"code": code})
+func (uc *Usecase) Create(ctx context.Context, code, name, description string) error {
+	entities, err := uc.supplierRepo.Find(ctx, map[string]interface{}{"code": code})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data supplier")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_CREATE), err)
 	}
 
 	if entities != nil || len(entities) > 0 {
-		return fmt.Errorf("Supplier dengan kode %s sudah terdaftar", code)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_CODE_EXISTS, code))
 	}
 
 	id := strings.ReplaceAll(uuid.NewString(), "-", "")
@@ -80,38 +103,38 @@ func (uc *Usecase) Create(code, name, description string) error {
 		Active:      true,
 	}
 
-	err = uc.supplierRepo.Create(entity)
+	err = uc.supplierRepo.Create(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data supplier")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) Edit(id, code, name, description string, active bool) error {
-	entities, err := uc.supplierRepo.Find(map[string]interface{}{"id": id})
+func (uc *Usecase) Edit(ctx context.Context, id, code, name, description string, active bool) error {
+	entities, err := uc.supplierRepo.Find(ctx, map[string]interface{}{"id": id})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data supplier")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_UPDATE), err)
 	}
 
 	if len(entities) != 1 {
-		logrus.Errorf("Product with id %s more than 1", id)
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data supplier")
+		logutil.WithContext(ctx).Errorf("Product with id %s more than 1", id)
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_UPDATE), nil)
 	}
 
 	entity := entities[0]
 
 	if code != entity.Code {
-		products, err := uc.supplierRepo.Find(map[string]interface{}{"code": code})
+		products, err := uc.supplierRepo.Find(ctx, map[string]interface{}{"code": code})
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data supplier")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_UPDATE), err)
 		}
 
 		if products != nil || len(products) > 0 {
-			return fmt.Errorf("Produk dengan kode %s sudah terdaftar", code)
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CODE_EXISTS, code))
 		}
 	}
 
@@ -120,16 +143,16 @@ func (uc *Usecase) Edit(id, code, name, description string, active bool) error {
 	entity.Description = description
 	entity.Active = active
 
-	err = uc.supplierRepo.Edit(entity)
+	err = uc.supplierRepo.Edit(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data supplier")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_UPDATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) GetBuyPrice(supplierId, unitId, date, productId string) ([]*supplierdomain.BuyPriceResponse, error) {
+func (uc *Usecase) GetBuyPrice(ctx context.Context, supplierId, unitId, date, productId string) ([]*supplierdomain.BuyPriceResponse, error) {
 	var param []queryutil.Param
 	if supplierId != "" {
 		param = append(param, queryutil.Param{
@@ -164,53 +187,53 @@ func (uc *Usecase) GetBuyPrice(supplierId, unitId, date, productId string) ([]*s
 		})
 	}
 
-	entities, err := uc.supplierRepo.GetBuyPrice(param)
+	entities, err := uc.supplierRepo.GetBuyPrice(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_FIND), err)
 	}
 
 	return entities, nil
 }
 
-func (uc *Usecase) UpdateBuyPrice(request supplierdomain.BuyPriceRequest) error {
+func (uc *Usecase) UpdateBuyPrice(ctx context.Context, request supplierdomain.BuyPriceRequest) error {
 	if request.SupplierId == "" {
-		return fmt.Errorf("Harap pilih supplier")
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_REQUIRED))
 	}
 	if request.UnitId == "" {
-		return fmt.Errorf("Harap pilih satuan")
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_UNIT_REQUIRED))
 	}
 
-	err := uc.supplierRepo.DeleteBuyPrice(request.SupplierId, request.UnitId, request.Date)
+	err := uc.supplierRepo.DeleteBuyPrice(ctx, request.SupplierId, request.UnitId, request.Date)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_UPDATE), err)
 	}
 
-	err = uc.supplierRepo.UpdateBuyPrice(request)
+	err = uc.supplierRepo.UpdateBuyPrice(ctx, request)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_UPDATE), err)
 	}
 	return nil
 }
 
-func (uc *Usecase) AddBuyPrice(entity supplierdomain.AddPriceRequest, userId string) error {
+func (uc *Usecase) AddBuyPrice(ctx context.Context, entity supplierdomain.AddPriceRequest, userId string) error {
 	entity.ID = strings.ReplaceAll(uuid.NewString(), "-", "")
 	entity.Date = time.Now().UTC()
 	entity.WebUserId = userId
 	entity.Latest = true
 
-	err := uc.supplierRepo.AddBuyPrice(entity)
+	err := uc.supplierRepo.AddBuyPrice(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat menambahkan data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_CREATE), err)
 	}
 
 	return nil
 }
 
-func (uc *Usecase) FindBuyPrice(supplierId, unitId, productId string, latest *bool) ([]*supplierdomain.PriceResponse, error) {
+func (uc *Usecase) FindBuyPrice(ctx context.Context, supplierId, unitId, productId string, latest *bool) ([]*supplierdomain.PriceResponse, error) {
 	var param []queryutil.Param
 	if supplierId != "" {
 		param = append(param, queryutil.Param{
@@ -244,10 +267,10 @@ func (uc *Usecase) FindBuyPrice(supplierId, unitId, productId string, latest *bo
 			Value:    strconv.FormatBool(*latest),
 		})
 	}
-	entities, err := uc.supplierRepo.FindBuyPrice(param)
+	entities, err := uc.supplierRepo.FindBuyPrice(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarion data harga")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PRICE_FIND), err)
 	}
 	return entities, nil
 }
@@ -0,0 +1,172 @@
+package tenantusecase
+
+import (
+	"context"
+	tenantdomain "dromatech/pos-backend/internal/domain/tenant"
+	tenantrepo "dromatech/pos-backend/internal/repo/tenant"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	stringutil "dromatech/pos-backend/internal/util/string"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// codePattern keeps a tenant code usable as a subdomain
+var codePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,30}$`)
+
+// SCHEMA_PREFIX starts the schema derived from a tenant code
+const SCHEMA_PREFIX = "tenant_"
+
+// CACHE_TTL is how long a resolved tenant is kept before the registry is read again, a deactivated tenant stops resolving after it
+const CACHE_TTL = 5 * time.Minute
+
+type TenantUsecase interface {
+	Resolve(ctx context.Context, code string) (context.Context, error)
+	Contexts(ctx context.Context) []context.Context
+	Provision(ctx context.Context, request tenantdomain.ProvisionRequest) (*tenantdomain.Tenant, error)
+}
+
+type Usecase struct {
+	tenantRepo  tenantrepo.TenantRepo
+	tenantCache tenantdomain.TenantCache
+}
+
+func New(tenantRepo tenantrepo.TenantRepo) *Usecase {
+	uc := &Usecase{
+		tenantRepo: tenantRepo,
+		tenantCache: tenantdomain.TenantCache{
+			DataMap:  make(map[string]*tenantdomain.Tenant),
+			ReadTime: make(map[string]time.Time),
+		},
+	}
+
+	return uc
+}
+
+// Resolve routes ctx to the active tenant with the code, its connection is opened on first use
+func (uc *Usecase) Resolve(ctx context.Context, code string) (context.Context, error) {
+	code = strings.ToLower(code)
+
+	uc.tenantCache.RLock()
+	tenant, ok := uc.tenantCache.DataMap[code]
+	readTime := uc.tenantCache.ReadTime[code]
+	uc.tenantCache.RUnlock()
+	if !ok || time.Since(readTime) > CACHE_TTL {
+		var err error
+		tenant, err = uc.tenantRepo.FindByCode(ctx, code)
+		if err != nil {
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+		if tenant == nil || !tenant.Active {
+			uc.tenantCache.Lock()
+			delete(uc.tenantCache.DataMap, code)
+			delete(uc.tenantCache.ReadTime, code)
+			uc.tenantCache.Unlock()
+			return nil, restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_TENANT_NOT_FOUND, code))
+		}
+
+		uc.tenantCache.Lock()
+		uc.tenantCache.DataMap[code] = tenant
+		uc.tenantCache.ReadTime[code] = time.Now()
+		uc.tenantCache.Unlock()
+	}
+
+	if _, err := tenantutil.Connect(tenant.Code, tenant.Schema); err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TENANT_CONNECT, code), err)
+	}
+	return tenantutil.NewContext(ctx, tenant.Code), nil
+}
+
+// Contexts returns ctx for the default tenant followed by one for every active tenant, background jobs run once per context
+func (uc *Usecase) Contexts(ctx context.Context) []context.Context {
+	contexts := []context.Context{ctx}
+
+	tenants, err := uc.tenantRepo.FindActive(ctx)
+	if err != nil {
+		// without the registry, e.g. a single tenant deployment, only the default tenant runs
+		return contexts
+	}
+	for _, tenant := range tenants {
+		tenantCtx, err := uc.Resolve(ctx, tenant.Code)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			continue
+		}
+		contexts = append(contexts, tenantCtx)
+	}
+	return contexts
+}
+
+// Provision creates the schema of a new tenant, runs the scripts on it and registers the tenant.
+// The schema is dropped again when a script fails so the code can be provisioned once more.
+func (uc *Usecase) Provision(ctx context.Context, request tenantdomain.ProvisionRequest) (*tenantdomain.Tenant, error) {
+	code := strings.ToLower(request.Code)
+	if !codePattern.MatchString(code) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TENANT_CODE_INVALID))
+	}
+	schema := request.Schema
+	if schema == "" {
+		schema = SCHEMA_PREFIX + strings.ReplaceAll(code, "-", "_")
+	}
+	if !tenantutil.ValidSchema(schema) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TENANT_SCHEMA_INVALID))
+	}
+	name := request.Name
+	if name == "" {
+		name = code
+	}
+
+	existing, err := uc.tenantRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if existing != nil {
+		return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TENANT_EXISTS, code))
+	}
+	exists, err := uc.tenantRepo.SchemaExists(ctx, schema)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if exists {
+		return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TENANT_SCHEMA_EXISTS, schema))
+	}
+
+	if err := uc.tenantRepo.CreateSchema(ctx, schema); err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TENANT_PROVISION, code), err)
+	}
+	if _, err := tenantutil.Connect(code, schema); err != nil {
+		uc.dropSchema(ctx, schema)
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TENANT_CONNECT, code), err)
+	}
+
+	tenantCtx := tenantutil.NewContext(ctx, code)
+	for _, script := range request.Scripts {
+		if err := uc.tenantRepo.RunScript(tenantCtx, script); err != nil {
+			uc.dropSchema(ctx, schema)
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TENANT_PROVISION, code), err)
+		}
+	}
+
+	tenant := &tenantdomain.Tenant{
+		ID:          stringutil.GenerateUUID(),
+		Code:        code,
+		Name:        name,
+		Schema:      schema,
+		Active:      true,
+		CreatedTime: time.Now(),
+	}
+	if err := uc.tenantRepo.Create(ctx, tenant); err != nil {
+		uc.dropSchema(ctx, schema)
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TENANT_PROVISION, code), err)
+	}
+	return tenant, nil
+}
+
+func (uc *Usecase) dropSchema(ctx context.Context, schema string) {
+	if err := uc.tenantRepo.DropSchema(ctx, schema); err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+	}
+}
@@ -0,0 +1,86 @@
+package transactionusecase
+
+import (
+	"context"
+	expensedomain "dromatech/pos-backend/internal/domain/expense"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// createOperasional checks and saves an operasional in one transaction, the budgets it counts toward stay locked until it
+// is saved so two operasional saved at once cannot both fit in what is left of a budget
+func (u *Usecase) createOperasional(ctx context.Context, userID string, request transactiondomain.TrxCreateOperasionalRequest, date time.Time) ([]expensedomain.BudgetUsage, error) {
+	tx := tenantutil.DB(ctx).Begin()
+	warnings, err := u.checkOperasional(ctx, userID, request, date, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := u.transactionRepo.CreateOperasional(ctx, userID, request, tx); err != nil {
+		tx.Rollback()
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_OPERASIONAL_CREATE), err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_OPERASIONAL_CREATE), err)
+	}
+	return warnings, nil
+}
+
+// checkOperasional checks the category of a new operasional and the budgets it counts toward,
+// a budget in reject mode refuses the operasional while the ones in warn mode are returned
+func (u *Usecase) checkOperasional(ctx context.Context, userID string, request transactiondomain.TrxCreateOperasionalRequest, date time.Time, tx *gorm.DB) ([]expensedomain.BudgetUsage, error) {
+	if request.CategoryID != "" {
+		category, err := u.expenseRepo.FindCategory(ctx, request.CategoryID)
+		if err != nil {
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+		if category == nil || !category.Active {
+			return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_EXPENSE_CATEGORY_INVALID))
+		}
+	}
+
+	budgets, err := u.expenseRepo.FindApplicableBudgets(ctx, userID, request.CategoryID, tx)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	warnings := []expensedomain.BudgetUsage{}
+	amount := float64(request.Quantity) * request.Price
+	for _, budget := range budgets {
+		startDate, endDate := budgetPeriod(budget.Period, date)
+		spent, err := u.transactionRepo.SumOperasional(ctx, budget.WebUserID, budget.CategoryID, startDate, endDate, tx)
+		if err != nil {
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+		spent += amount
+		if spent <= budget.Amount {
+			continue
+		}
+
+		name := budget.CategoryName
+		if name == "" {
+			name = "operasional"
+		}
+		message := i18nutil.T(ctx, i18nutil.ERR_EXPENSE_BUDGET_EXCEEDED, name+" ("+budget.Period+")", spent, budget.Amount)
+		if budget.Mode == expensedomain.MODE_REJECT {
+			return nil, restutil.ErrConflict(message)
+		}
+		warnings = append(warnings, expensedomain.BudgetUsage{Budget: *budget, Spent: spent, Message: message})
+	}
+	return warnings, nil
+}
+
+// budgetPeriod returns the first and last date of the period of a budget the date falls in
+func budgetPeriod(period string, date time.Time) (time.Time, time.Time) {
+	if period == expensedomain.PERIOD_MONTHLY {
+		startDate := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		return startDate, startDate.AddDate(0, 1, -1)
+	}
+	return date, date
+}
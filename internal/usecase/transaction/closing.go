@@ -0,0 +1,186 @@
+package transactionusecase
+
+import (
+	"context"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	dateutil "dromatech/pos-backend/internal/util/date"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	scopeutil "dromatech/pos-backend/internal/util/scope"
+	stringutil "dromatech/pos-backend/internal/util/string"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"strings"
+	"time"
+)
+
+// CloseCash snapshots the saldo of the day and locks the day, the saldo akhir becomes the saldo awal of the next day
+func (u *Usecase) CloseCash(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error) {
+	if err := u.CheckOpen(ctx, userID, date); err != nil {
+		return nil, err
+	}
+
+	saldo, err := u.transactionRepo.FindSaldo(ctx, userID, date)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	// the saldo akhir becomes the saldo awal of the next day, a closed next day keeps its snapshot and has to be reopened first
+	nextDate := date.AddDate(0, 0, 1)
+	next, err := u.transactionRepo.FindClosing(ctx, userID, nextDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if next != nil {
+		return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_NEXT_CLOSED, nextDate.Format(dateutil.DateFormatResponse())))
+	}
+
+	branchID, _ := scopeutil.BranchID(ctx)
+	closing := &transactiondomain.CashClosing{
+		ID:            stringutil.GenerateUUID(),
+		Date:          date,
+		WebUserID:     userID,
+		BranchID:      branchID,
+		SaldoResponse: *saldo,
+		ClosedBy:      userID,
+		ClosedTime:    time.Now(),
+	}
+
+	tx := tenantutil.DB(ctx).Begin()
+	if err := u.transactionRepo.CreateClosing(ctx, closing, tx); err != nil {
+		tx.Rollback()
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_SAVE), err)
+	}
+	if err := u.transactionRepo.UpdateSaldoAwal(ctx, userID, branchID, nextDate, closing.SaldoAkhir, tx); err != nil {
+		tx.Rollback()
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_SAVE), err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_SAVE), err)
+	}
+
+	return closing, nil
+}
+
+func (u *Usecase) FindClosing(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error) {
+	closing, err := u.transactionRepo.FindClosing(ctx, userID, date)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	return closing, nil
+}
+
+func (u *Usecase) FindClosingList(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosing, error) {
+	closings, err := u.transactionRepo.FindClosingList(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	return closings, nil
+}
+
+// ReopenCash unlocks a closed day of a mobile user, the closing is kept in the reopen history
+func (u *Usecase) ReopenCash(ctx context.Context, supervisorID string, request transactiondomain.CashClosingReopenRequest) error {
+	if request.UserID == "" {
+		return restutil.ErrRequired("userId", i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_USER_REQUIRED))
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		return restutil.ErrRequired("reason", i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_REASON_REQUIRED))
+	}
+	date, err := time.Parse(dateutil.DateFormat(), request.Date)
+	if err != nil {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DATE_INVALID))
+	}
+
+	closing, err := u.transactionRepo.FindClosing(ctx, request.UserID, date)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if closing == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_NOT_FOUND, date.Format(dateutil.DateFormatResponse())))
+	}
+
+	err = u.transactionRepo.ReopenClosing(ctx, &transactiondomain.CashClosingReopen{
+		ID:           stringutil.GenerateUUID(),
+		Date:         closing.Date,
+		WebUserID:    closing.WebUserID,
+		BranchID:     closing.BranchID,
+		SaldoAkhir:   closing.SaldoAkhir,
+		ClosedBy:     closing.ClosedBy,
+		ClosedTime:   closing.ClosedTime,
+		Reason:       strings.TrimSpace(request.Reason),
+		ReopenedBy:   supervisorID,
+		ReopenedTime: time.Now(),
+	})
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSING_SAVE), err)
+	}
+
+	return nil
+}
+
+func (u *Usecase) FindReopenHistory(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosingReopen, error) {
+	history, err := u.transactionRepo.FindReopenHistory(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	return history, nil
+}
+
+// CheckOpen rejects a change to a day the mobile user already closed
+func (u *Usecase) CheckOpen(ctx context.Context, userID string, date time.Time) error {
+	closing, err := u.transactionRepo.FindClosing(ctx, userID, date)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if closing != nil {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSED, date.Format(dateutil.DateFormatResponse())))
+	}
+	return nil
+}
+
+// checkOpenDate is CheckOpen for the date of a request
+func (u *Usecase) checkOpenDate(ctx context.Context, userID string, date string) (time.Time, error) {
+	parsed, err := time.Parse(dateutil.DateFormat(), date)
+	if err != nil {
+		return parsed, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DATE_INVALID))
+	}
+	return parsed, u.CheckOpen(ctx, userID, parsed)
+}
+
+// checkEntryOpen is CheckOpen for the day of a record about to be removed
+func (u *Usecase) checkEntryOpen(ctx context.Context, table string, id string) error {
+	entry, err := u.transactionRepo.FindMobileEntry(ctx, table, id)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if entry == nil {
+		return nil
+	}
+	return u.CheckOpen(ctx, entry.WebUserID, entry.Date)
+}
+
+// checkTransferOpen is CheckOpen for the day of both the sender and the receiver of a transfer
+func (u *Usecase) checkTransferOpen(ctx context.Context, danaTransaction *transactiondomain.DanaTransaction) error {
+	if err := u.CheckOpen(ctx, danaTransaction.Sender, danaTransaction.Date); err != nil {
+		return err
+	}
+	return u.CheckOpen(ctx, danaTransaction.Receiver, danaTransaction.Date)
+}
+
+// carrySaldoAwal keeps the saldo awal equal to the saldo akhir of the previous day once that day is closed
+func (u *Usecase) carrySaldoAwal(ctx context.Context, userID string, date time.Time, request *transactiondomain.DanaRequest) error {
+	previous, err := u.transactionRepo.FindClosing(ctx, userID, date.AddDate(0, 0, -1))
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if previous != nil {
+		request.SaldoAwal = previous.SaldoAkhir
+	}
+	return nil
+}
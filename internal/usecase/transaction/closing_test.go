@@ -0,0 +1,187 @@
+package transactionusecase
+
+import (
+	"context"
+	"database/sql"
+	"dromatech/pos-backend/global"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	transactionrepo "dromatech/pos-backend/internal/repo/transaction"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// txPool is a connection whose transactions only count the commits and rollbacks, the repo fakes do the writing
+type txPool struct {
+	commits, rollbacks *int
+}
+
+func (p txPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p txPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p txPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p txPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p txPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &txConn{txPool: p}, nil
+}
+
+type txConn struct {
+	txPool
+}
+
+func (c *txConn) Commit() error {
+	*c.commits++
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	*c.rollbacks++
+	return nil
+}
+
+// txDialector opens a gorm.DB on a txPool
+type txDialector struct {
+	pool txPool
+}
+
+func (d txDialector) Name() string                                          { return "test" }
+func (d txDialector) Initialize(db *gorm.DB) error                          { db.ConnPool = d.pool; return nil }
+func (d txDialector) Migrator(db *gorm.DB) gorm.Migrator                    { return nil }
+func (d txDialector) DataTypeOf(*schema.Field) string                       { return "" }
+func (d txDialector) DefaultValueOf(*schema.Field) clause.Expression        { return nil }
+func (d txDialector) BindVarTo(clause.Writer, *gorm.Statement, interface{}) {}
+func (d txDialector) QuoteTo(clause.Writer, string)                         {}
+func (d txDialector) Explain(sql string, vars ...interface{}) string        { return sql }
+
+// useTxDB makes the default tenant open its transactions on a txPool for the test
+func useTxDB(t *testing.T) (commits, rollbacks *int) {
+	commits, rollbacks = new(int), new(int)
+	db, err := gorm.Open(txDialector{txPool{commits, rollbacks}}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	saved := global.DBCON
+	global.DBCON = db
+	t.Cleanup(func() { global.DBCON = saved })
+	return commits, rollbacks
+}
+
+// closingRepo keeps the dana and the closings of one user in memory, keyed by date
+type closingRepo struct {
+	transactionrepo.TransactionRepo
+	saldoAwal    map[string]float64
+	danaTambahan map[string]float64
+	closed       map[string]*transactiondomain.CashClosing
+	movement     float64
+	saveErr      error
+}
+
+func closingKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}
+
+func (r *closingRepo) FindSaldo(ctx context.Context, userID string, date time.Time) (*transactiondomain.SaldoResponse, error) {
+	saldoAwal := r.saldoAwal[closingKey(date)]
+	danaTambahan := r.danaTambahan[closingKey(date)]
+	return &transactiondomain.SaldoResponse{SaldoAwal: saldoAwal, DanaTambahan: danaTambahan, DanaMasuk: r.movement,
+		SaldoAkhir: saldoAwal + danaTambahan + r.movement}, nil
+}
+
+func (r *closingRepo) FindClosing(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error) {
+	return r.closed[closingKey(date)], nil
+}
+
+func (r *closingRepo) CreateClosing(ctx context.Context, closing *transactiondomain.CashClosing, tx *gorm.DB) error {
+	r.closed[closingKey(closing.Date)] = closing
+	return nil
+}
+
+func (r *closingRepo) UpdateSaldoAwal(ctx context.Context, userID, branchID string, date time.Time, saldoAwal float64, tx *gorm.DB) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.saldoAwal[closingKey(date)] = saldoAwal
+	return nil
+}
+
+func TestCloseCash(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	next := date.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name       string
+		nextDana   bool
+		nextClosed bool
+		saveErr    error
+		code       string
+		saldoAwal  float64
+		commits    int
+		rollbacks  int
+	}{
+		{"next day without dana", false, false, nil, "", 150000, 1, 0},
+		// the saldo awal the next day had before the closing is replaced, its dana tambahan is kept
+		{"next day with an older dana", true, false, nil, "", 150000, 1, 0},
+		{"next day closed", true, true, nil, restutil.ERR_CONFLICT, 90000, 0, 0},
+		{"save fails", true, false, errors.New("db down"), restutil.ERR_INTERNAL, 90000, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, rollbacks := useTxDB(t)
+			repo := &closingRepo{
+				saldoAwal:    map[string]float64{closingKey(date): 100000},
+				danaTambahan: map[string]float64{closingKey(date): 20000},
+				closed:       map[string]*transactiondomain.CashClosing{},
+				movement:     30000,
+				saveErr:      tt.saveErr,
+			}
+			if tt.nextDana {
+				repo.saldoAwal[closingKey(next)] = 90000
+				repo.danaTambahan[closingKey(next)] = 5000
+			}
+			if tt.nextClosed {
+				repo.closed[closingKey(next)] = &transactiondomain.CashClosing{Date: next}
+			}
+			u := &Usecase{transactionRepo: repo}
+
+			closing, err := u.CloseCash(context.Background(), "U1", date)
+			if tt.code != "" {
+				var restErr *restutil.Error
+				if !errors.As(err, &restErr) || restErr.Code != tt.code {
+					t.Fatalf("CloseCash() error = %v, want %s", err, tt.code)
+				}
+			} else if err != nil {
+				t.Fatalf("CloseCash() error = %v", err)
+			} else if closing.SaldoAkhir != 150000 {
+				t.Errorf("saldo akhir = %v, want 150000", closing.SaldoAkhir)
+			}
+
+			saldo, _ := repo.FindSaldo(context.Background(), "U1", next)
+			if saldo.SaldoAwal != tt.saldoAwal {
+				t.Errorf("saldo awal of the next day = %v, want %v", saldo.SaldoAwal, tt.saldoAwal)
+			}
+			if tt.nextDana && saldo.DanaTambahan != 5000 {
+				t.Errorf("dana tambahan of the next day = %v, want 5000", saldo.DanaTambahan)
+			}
+			if *commits != tt.commits || *rollbacks != tt.rollbacks {
+				t.Errorf("commits %d, rollbacks %d, want %d, %d", *commits, *rollbacks, tt.commits, tt.rollbacks)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package transactionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	dateutil "dromatech/pos-backend/internal/util/date"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// DEFAULT_EFAKTUR_TRANSACTION_CODE is the kode jenis transaksi when EFAKTUR_TRANSACTION_CODE is not set, 01 is a sale to a non-collector
+const DEFAULT_EFAKTUR_TRANSACTION_CODE = "01"
+
+// EFAKTUR_EMPTY_NPWP is written for a customer without an NPWP
+const EFAKTUR_EMPTY_NPWP = "000000000000000"
+
+// the header rows of the e-Faktur import, every invoice is an FK row followed by the LT row of its customer and an OF row per product
+var (
+	eFakturFKHeader = []interface{}{"FK", "KD_JENIS_TRANSAKSI", "FG_PENGGANTI", "NOMOR_FAKTUR", "MASA_PAJAK", "TAHUN_PAJAK", "TANGGAL_FAKTUR",
+		"NPWP", "NAMA", "ALAMAT_LENGKAP", "JUMLAH_DPP", "JUMLAH_PPN", "JUMLAH_PPNBM", "ID_KETERANGAN_TAMBAHAN", "FG_UANG_MUKA",
+		"UANG_MUKA_DPP", "UANG_MUKA_PPN", "UANG_MUKA_PPNBM", "REFERENSI"}
+	eFakturLTHeader = []interface{}{"LT", "NPWP", "NAMA", "JALAN", "BLOK", "NOMOR", "RT", "RW", "KECAMATAN", "KELURAHAN", "KABUPATEN",
+		"PROPINSI", "KODE_POS", "NOMOR_TELEPON"}
+	eFakturOFHeader = []interface{}{"OF", "KODE_OBJEK", "NAMA", "HARGA_SATUAN", "JUMLAH_BARANG", "HARGA_TOTAL", "DISKON", "DPP", "PPN",
+		"TARIF_PPNBM", "PPNBM"}
+)
+
+// ExportEFaktur lays out the taxable sell transactions between the dates as the rows of an e-Faktur import csv, only
+// the transactions given a serial number by AllocateTaxInvoiceNumbers are exported so reading never takes a number
+func (uc *Usecase) ExportEFaktur(ctx context.Context, startDate, endDate time.Time) ([][]interface{}, error) {
+	fakturs, err := uc.transactionRepo.FindEFaktur(ctx, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_EFAKTUR_EXPORT), err)
+	}
+
+	transactionCode := uc.configRepo.GetValue(ctx, configdomain.EFAKTUR_TRANSACTION_CODE)
+	if transactionCode == "" {
+		transactionCode = DEFAULT_EFAKTUR_TRANSACTION_CODE
+	}
+
+	rows := [][]interface{}{eFakturFKHeader, eFakturLTHeader, eFakturOFHeader}
+	for _, faktur := range fakturs {
+		npwp := faktur.CustomerNpwp
+		if npwp == "" {
+			npwp = EFAKTUR_EMPTY_NPWP
+		}
+
+		rows = append(rows,
+			[]interface{}{"FK", transactionCode, "0", faktur.TaxInvoiceNumber, int(faktur.Date.Month()), faktur.Date.Year(), faktur.Date.Format("02/01/2006"),
+				npwp, faktur.CustomerName, faktur.CustomerAddress, rupiah(faktur.Dpp), rupiah(faktur.Ppn), 0, "", 0, 0, 0, 0, faktur.Code},
+			[]interface{}{"LT", npwp, faktur.CustomerName, faktur.CustomerAddress, "", "", "", "", "", "", "", "", "", ""})
+
+		for _, detail := range faktur.Details {
+			// the unit price is written without PPN, an inclusive price gives its PPN back first
+			price := detail.SellPrice
+			if faktur.TaxInclusive {
+				price = price * 100 / (100 + detail.TaxRate)
+			}
+			total := cents(price * detail.Quantity)
+			rows = append(rows, []interface{}{"OF", detail.ProductCode, detail.ProductName, cents(price), detail.Quantity, total,
+				cents(math.Max(total-detail.Dpp, 0)), cents(detail.Dpp), cents(detail.Ppn), 0, 0})
+		}
+	}
+	return rows, nil
+}
+
+// AllocateTaxInvoiceNumbers gives the final taxable sell transactions between the dates that have no serial number the
+// next ones of the EFAKTUR_SERIAL_START to EFAKTUR_SERIAL_END range, all of them or none
+func (uc *Usecase) AllocateTaxInvoiceNumbers(ctx context.Context, request transactiondomain.TaxInvoiceAllocateRequest) (*transactiondomain.TaxInvoiceAllocateResponse, error) {
+	startDate, startErr := time.Parse(dateutil.DateFormat(), request.StartDate)
+	endDate, endErr := time.Parse(dateutil.DateFormat(), request.EndDate)
+	if startErr != nil || endErr != nil {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DATE_INVALID))
+	}
+
+	start, startErr := strconv.ParseInt(uc.configRepo.GetValue(ctx, configdomain.EFAKTUR_SERIAL_START), 10, 64)
+	end, endErr := strconv.ParseInt(uc.configRepo.GetValue(ctx, configdomain.EFAKTUR_SERIAL_END), 10, 64)
+	if startErr != nil || endErr != nil || start <= 0 || end < start {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_NOT_SET))
+	}
+
+	// the sequence is kept per range so a new range starts from its own first number
+	sequenceID := "EFAKTUR/" + strconv.FormatInt(start, 10)
+
+	tx := tenantutil.DB(ctx).Begin()
+	pending, err := uc.transactionRepo.FindTaxInvoicePending(ctx, startDate, endDate, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_ALLOCATE), err)
+	}
+
+	response := &transactiondomain.TaxInvoiceAllocateResponse{}
+	for _, transactionID := range pending {
+		sequence, err := uc.sequenceRepo.NextValTx(ctx, sequenceID, tx)
+		if err != nil {
+			tx.Rollback()
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_ALLOCATE), err)
+		}
+		number := start + sequence - 1
+		if number > end {
+			tx.Rollback()
+			return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_EXHAUSTED))
+		}
+
+		taxInvoiceNumber := fmt.Sprintf("%013d", number)
+		stored, err := uc.transactionRepo.UpdateTaxInvoiceNumber(ctx, transactionID, taxInvoiceNumber, tx)
+		if err != nil {
+			tx.Rollback()
+			logutil.WithContext(ctx).Error(err.Error())
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_ALLOCATE), err)
+		}
+		// numbered by another allocation in the meantime, the number is given back by rolling the sequence back
+		if stored != taxInvoiceNumber {
+			tx.Rollback()
+			return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_CONFLICT))
+		}
+		response.Allocated++
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TAX_SERIAL_ALLOCATE), err)
+	}
+	return response, nil
+}
+
+// cents rounds an amount to two decimals, the most e-Faktur takes
+func cents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// rupiah rounds an amount to a whole rupiah, the totals of the FK row take no decimals
+func rupiah(value float64) int64 {
+	return int64(math.Round(value))
+}
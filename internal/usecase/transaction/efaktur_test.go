@@ -0,0 +1,214 @@
+package transactionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	sequencerepo "dromatech/pos-backend/internal/repo/sequence"
+	transactionrepo "dromatech/pos-backend/internal/repo/transaction"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// eFakturRepo returns the same numbered transactions for any range
+type eFakturRepo struct {
+	transactionrepo.TransactionRepo
+	fakturs []*transactiondomain.EFaktur
+}
+
+func (r *eFakturRepo) FindEFaktur(ctx context.Context, startDate, endDate time.Time) ([]*transactiondomain.EFaktur, error) {
+	return r.fakturs, nil
+}
+
+type mapConfig map[string]string
+
+func (c mapConfig) GetValue(ctx context.Context, key string) string {
+	return c[key]
+}
+
+func TestExportEFaktur(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	fakturs := []*transactiondomain.EFaktur{
+		{ID: "T1", Code: "INV-1", Date: date, TaxInvoiceNumber: "0000000000001", CustomerName: "Toko A", CustomerAddress: "Jl. Satu",
+			Dpp: 29000, Ppn: 3190, Details: []*transactiondomain.EFakturDetail{
+				{ProductCode: "P1", ProductName: "Gula", SellPrice: 10000, Quantity: 1, TaxRate: 11, Dpp: 9667, Ppn: 1063},
+				{ProductCode: "P2", ProductName: "Kopi", SellPrice: 10000, Quantity: 2, TaxRate: 11, Dpp: 19333, Ppn: 2127},
+			}},
+		{ID: "T2", Code: "INV-2", Date: date, TaxInvoiceNumber: "0000000000002", CustomerNpwp: "012345678901000", CustomerName: "PT B",
+			TaxInclusive: true, Dpp: 9999.6, Ppn: 1100.4, Details: []*transactiondomain.EFakturDetail{
+				{ProductCode: "P1", ProductName: "Gula", SellPrice: 11100, Quantity: 1, TaxRate: 11, Dpp: 9999.6, Ppn: 1100.4},
+			}},
+	}
+
+	tests := []struct {
+		name   string
+		config mapConfig
+		code   string
+	}{
+		{"default transaction code", mapConfig{}, DEFAULT_EFAKTUR_TRANSACTION_CODE},
+		{"configured transaction code", mapConfig{configdomain.EFAKTUR_TRANSACTION_CODE: "07"}, "07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &Usecase{transactionRepo: &eFakturRepo{fakturs: fakturs}, configRepo: tt.config}
+
+			rows, err := u.ExportEFaktur(context.Background(), date, date)
+			if err != nil {
+				t.Fatalf("ExportEFaktur() error = %v", err)
+			}
+
+			want := [][]interface{}{
+				eFakturFKHeader, eFakturLTHeader, eFakturOFHeader,
+				{"FK", tt.code, "0", "0000000000001", 3, 2024, "05/03/2024", EFAKTUR_EMPTY_NPWP, "Toko A", "Jl. Satu", int64(29000), int64(3190),
+					0, "", 0, 0, 0, 0, "INV-1"},
+				{"LT", EFAKTUR_EMPTY_NPWP, "Toko A", "Jl. Satu", "", "", "", "", "", "", "", "", "", ""},
+				{"OF", "P1", "Gula", 10000.0, 1.0, 10000.0, 333.0, 9667.0, 1063.0, 0, 0},
+				{"OF", "P2", "Kopi", 10000.0, 2.0, 20000.0, 667.0, 19333.0, 2127.0, 0, 0},
+				// the totals are rounded to the rupiah, not cut
+				{"FK", tt.code, "0", "0000000000002", 3, 2024, "05/03/2024", "012345678901000", "PT B", "", int64(10000), int64(1100),
+					0, "", 0, 0, 0, 0, "INV-2"},
+				{"LT", "012345678901000", "PT B", "", "", "", "", "", "", "", "", "", "", ""},
+				// the inclusive price is written without its PPN
+				{"OF", "P1", "Gula", 10000.0, 1.0, 10000.0, 0.4, 9999.6, 1100.4, 0, 0},
+			}
+			if len(rows) != len(want) {
+				t.Fatalf("rows = %d, want %d", len(rows), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(rows[i], want[i]) {
+					t.Errorf("row %d = %v, want %v", i+1, rows[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExportEFakturTotals(t *testing.T) {
+	// the FK totals of a transaction worked out by calculateTax are the sums of its OF rows
+	transaction := &transactiondomain.Transaction{DiscountType: transactiondomain.DISCOUNT_AMOUNT, DiscountValue: 1000}
+	for _, price := range []float64{10000, 10000, 10000} {
+		transaction.TransactionDetail = append(transaction.TransactionDetail, &transactiondomain.TransactionDetail{ProductID: "P", SellPrice: price, Quantity: 1})
+	}
+	if !calculateTax(transaction, &transactiondomain.TaxSetting{Taxable: true}, 11, rounding{unit: 1}) {
+		t.Fatal("calculateTax() = false")
+	}
+
+	faktur := &transactiondomain.EFaktur{TaxInvoiceNumber: "0000000000001", Dpp: transaction.Dpp, Ppn: transaction.Ppn}
+	for _, detail := range transaction.TransactionDetail {
+		faktur.Details = append(faktur.Details, &transactiondomain.EFakturDetail{SellPrice: detail.SellPrice, Quantity: detail.Quantity,
+			TaxRate: detail.TaxRate, Dpp: detail.Dpp, Ppn: detail.Ppn})
+	}
+	u := &Usecase{transactionRepo: &eFakturRepo{fakturs: []*transactiondomain.EFaktur{faktur}}, configRepo: mapConfig{}}
+
+	rows, err := u.ExportEFaktur(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("ExportEFaktur() error = %v", err)
+	}
+
+	fk := rows[3]
+	dpp, ppn := 0.0, 0.0
+	for _, of := range rows[5:] {
+		dpp += of[7].(float64)
+		ppn += of[8].(float64)
+	}
+	if fk[10] != int64(dpp) || fk[11] != int64(ppn) {
+		t.Errorf("FK dpp %v, ppn %v, the OF rows sum to %v, %v", fk[10], fk[11], dpp, ppn)
+	}
+}
+
+// allocateRepo numbers the pending transactions in memory, stored holds a number given by another allocation
+type allocateRepo struct {
+	transactionrepo.TransactionRepo
+	pending []string
+	stored  map[string]string
+	numbers map[string]string
+}
+
+func (r *allocateRepo) FindTaxInvoicePending(ctx context.Context, startDate, endDate time.Time, tx *gorm.DB) ([]string, error) {
+	return r.pending, nil
+}
+
+func (r *allocateRepo) UpdateTaxInvoiceNumber(ctx context.Context, id string, number string, tx *gorm.DB) (string, error) {
+	if stored, ok := r.stored[id]; ok {
+		return stored, nil
+	}
+	r.numbers[id] = number
+	return number, nil
+}
+
+// sequence counts from 1, or fails with err
+type sequence struct {
+	sequencerepo.SequenceRepo
+	value int64
+	err   error
+}
+
+func (s *sequence) NextValTx(ctx context.Context, id string, tx *gorm.DB) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.value++
+	return s.value, nil
+}
+
+func TestAllocateTaxInvoiceNumbers(t *testing.T) {
+	request := transactiondomain.TaxInvoiceAllocateRequest{StartDate: "2024-03-01", EndDate: "2024-03-31"}
+	serial := mapConfig{configdomain.EFAKTUR_SERIAL_START: "100", configdomain.EFAKTUR_SERIAL_END: "102"}
+
+	tests := []struct {
+		name      string
+		request   transactiondomain.TaxInvoiceAllocateRequest
+		config    mapConfig
+		pending   []string
+		stored    map[string]string
+		seqErr    error
+		code      string
+		numbers   map[string]string
+		rollbacks int
+	}{
+		{"allocated", request, serial, []string{"T1", "T2"}, nil, nil, "",
+			map[string]string{"T1": "0000000000100", "T2": "0000000000101"}, 0},
+		{"bad date", transactiondomain.TaxInvoiceAllocateRequest{StartDate: "01-03-2024", EndDate: "2024-03-31"}, serial, nil, nil, nil,
+			restutil.ERR_BAD_REQUEST, map[string]string{}, 0},
+		{"range not set", request, mapConfig{}, nil, nil, nil, restutil.ERR_BAD_REQUEST, map[string]string{}, 0},
+		// a failed sequence never gives a number, it would repeat one already given
+		{"sequence fails", request, serial, []string{"T1"}, nil, errors.New("db down"), restutil.ERR_INTERNAL, map[string]string{}, 1},
+		{"range exhausted", request, serial, []string{"T1", "T2", "T3", "T4"}, nil, nil, restutil.ERR_BAD_REQUEST,
+			map[string]string{"T1": "0000000000100", "T2": "0000000000101", "T3": "0000000000102"}, 1},
+		{"numbered meanwhile", request, serial, []string{"T1"}, map[string]string{"T1": "0000000000050"}, nil, restutil.ERR_CONFLICT,
+			map[string]string{}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, rollbacks := useTxDB(t)
+			repo := &allocateRepo{pending: tt.pending, stored: tt.stored, numbers: map[string]string{}}
+			u := &Usecase{transactionRepo: repo, sequenceRepo: &sequence{err: tt.seqErr}, configRepo: tt.config}
+
+			response, err := u.AllocateTaxInvoiceNumbers(context.Background(), tt.request)
+			if tt.code != "" {
+				var restErr *restutil.Error
+				if !errors.As(err, &restErr) || restErr.Code != tt.code {
+					t.Fatalf("AllocateTaxInvoiceNumbers() error = %v, want %s", err, tt.code)
+				}
+			} else if err != nil {
+				t.Fatalf("AllocateTaxInvoiceNumbers() error = %v", err)
+			} else if response.Allocated != len(tt.pending) || *commits != 1 {
+				t.Errorf("allocated %d, commits %d, want %d, 1", response.Allocated, *commits, len(tt.pending))
+			}
+
+			if !reflect.DeepEqual(repo.numbers, tt.numbers) {
+				t.Errorf("numbers = %v, want %v", repo.numbers, tt.numbers)
+			}
+			if *rollbacks != tt.rollbacks {
+				t.Errorf("rollbacks = %d, want %d", *rollbacks, tt.rollbacks)
+			}
+		})
+	}
+}
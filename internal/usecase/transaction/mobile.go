@@ -1,249 +1,339 @@
 package transactionusecase
 
 import (
+	"context"
+	attachmentdomain "dromatech/pos-backend/internal/domain/attachment"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
-	"fmt"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	stringutil "dromatech/pos-backend/internal/util/string"
 	"time"
 )
 
-func (u *Usecase) FindDana(userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error) {
+func (u *Usecase) FindDana(ctx context.Context, userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error) {
 	// find to database
-	dana, err := u.transactionRepo.FindDana(userID, date)
+	dana, err := u.transactionRepo.FindDana(ctx, userID, date)
 	if err != nil {
-		return nil, err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if dana == nil {
+		return nil, nil
+	}
+
+	var ids []string
+	for _, transfer := range dana.DanaMasuk {
+		ids = append(ids, transfer.ID)
+	}
+	for _, transfer := range dana.DanaKeluar {
+		ids = append(ids, transfer.ID)
+	}
+	attachments, err := u.attachmentRepo.FindByOwner(ctx, attachmentdomain.OWNER_TYPE_DANA_TRANSFER, ids)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	for i := range dana.DanaMasuk {
+		dana.DanaMasuk[i].Attachments = attachments[dana.DanaMasuk[i].ID]
+	}
+	for i := range dana.DanaKeluar {
+		dana.DanaKeluar[i].Attachments = attachments[dana.DanaKeluar[i].ID]
 	}
 
 	return dana, nil
 }
 
-func (u *Usecase) CreateDana(userID string, request transactiondomain.DanaRequest) error {
-	// create dana
-	err := u.transactionRepo.CreateDana(userID, request)
+func (u *Usecase) CreateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error {
+	// the id of new dana is given by the server, only the offline sync keeps the client id
+	request.ID = ""
+	date, err := u.checkOpenDate(ctx, userID, request.Date)
 	if err != nil {
 		return err
 	}
+	if err := u.carrySaldoAwal(ctx, userID, date, &request); err != nil {
+		return err
+	}
+
+	// create dana
+	err = u.transactionRepo.CreateDana(ctx, userID, request)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_CREATE), err)
+	}
 
 	return nil
 }
 
-func (u *Usecase) UpdateDana(userID string, request transactiondomain.DanaRequest) error {
+func (u *Usecase) UpdateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error {
 	// find dana
-	dana, err := u.transactionRepo.FindDanaByID(request.ID)
+	dana, err := u.transactionRepo.FindDanaByID(ctx, request.ID)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UPDATE_DATA), err)
 	}
 
 	// check the userID is the same as the dana.UserID
 	if dana.WebUserID != userID {
-		return fmt.Errorf("not allowed")
+		return restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_DANA_UPDATE_NOT_ALLOWED))
+	}
+
+	if err := u.CheckOpen(ctx, userID, dana.Date); err != nil {
+		return err
+	}
+	if err := u.carrySaldoAwal(ctx, userID, dana.Date, &request); err != nil {
+		return err
 	}
 
 	// update dana
-	err = u.transactionRepo.UpdateDana(userID, request)
+	err = u.transactionRepo.UpdateDana(ctx, userID, request)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UPDATE_DATA), err)
 	}
 
 	return nil
 }
 
-func (u *Usecase) SendDana(userID string, request transactiondomain.DanaTransactionRequest) error {
+func (u *Usecase) SendDana(ctx context.Context, userID string, request transactiondomain.DanaTransactionRequest) error {
 	// check reciever is have permission for mobile
-	hasPermission, err := u.transactionRepo.CheckUserMobilePermission(userID)
+	hasPermission, err := u.transactionRepo.CheckUserMobilePermission(ctx, userID)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_SEND), err)
 	}
 	if !hasPermission {
-		return fmt.Errorf("user not have permission for mobile")
+		return restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_MOBILE_NOT_ALLOWED))
 	}
 
-	// send dana
-	err = u.transactionRepo.SendDana(userID, request)
+	// the transfer shows on the day of both users
+	date, err := u.checkOpenDate(ctx, userID, request.Date)
 	if err != nil {
 		return err
 	}
+	if err := u.CheckOpen(ctx, request.Receiver, date); err != nil {
+		return err
+	}
+
+	// send dana
+	request.ID = stringutil.GenerateUUID()
+	err = u.transactionRepo.SendDana(ctx, userID, request)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_SEND), err)
+	}
+	u.notifyTransfer(ctx, request.ID)
 
 	return nil
 }
 
-func (u *Usecase) ApproveDana(userID string, id string) error {
+func (u *Usecase) ApproveDana(ctx context.Context, userID string, id string) error {
+	return u.decideDana(ctx, userID, id, transactiondomain.DanaStatusApproved)
+}
+
+func (u *Usecase) RejectDana(ctx context.Context, userID string, id string) error {
+	return u.decideDana(ctx, userID, id, transactiondomain.DanaStatusRejected)
+}
+
+func (u *Usecase) CancelSendDana(ctx context.Context, userID string, id string) error {
 	// find dana transaction
-	danaTransaction, err := u.transactionRepo.FindDanaTransaction(id)
+	danaTransaction, err := u.transactionRepo.FindDanaTransaction(ctx, id)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_CANCEL), err)
 	}
 
-	// check the receiver is the userID
-	if danaTransaction.Receiver != userID {
-		return fmt.Errorf("not allowed")
+	// check the sender is the userID
+	if danaTransaction.Sender != userID {
+		return restutil.ErrForbidden(i18nutil.T(ctx, i18nutil.ERR_DANA_CANCEL_NOT_ALLOWED))
 	}
 
-	// check the dana transaction is still pending
-	if danaTransaction.Status != transactiondomain.DanaStatusPending {
-		return fmt.Errorf("not pending")
+	if err := u.checkTransferOpen(ctx, danaTransaction); err != nil {
+		return err
 	}
 
-	// approve dana
-	err = u.transactionRepo.ApproveDana(id)
+	// cancel send dana
+	err = u.transactionRepo.CancelSendDana(ctx, id)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_CANCEL), err)
 	}
+	u.notifyTransfer(ctx, id)
 
 	return nil
 }
 
-func (u *Usecase) RejectDana(userID string, id string) error {
-	// find dana transaction
-	danaTransaction, err := u.transactionRepo.FindDanaTransaction(id)
-	if err != nil {
-		return err
+// notifyTransfer tells the other side of the transfer about its new status
+func (u *Usecase) notifyTransfer(ctx context.Context, id string) {
+	transfer, err := u.transactionRepo.FindDanaTransfer(ctx, id)
+	if err != nil || transfer == nil {
+		return
 	}
+	u.notifier.NotifyTransfer(ctx, transfer)
+}
 
-	// check the receiver is the userID
-	if danaTransaction.Receiver != userID {
-		return fmt.Errorf("not allowed")
+func (u *Usecase) FindUserMobile(ctx context.Context, userID string) ([]transactiondomain.WebUserMobile, error) {
+	userMobile, err := u.transactionRepo.FindUserMobile(ctx, userID)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
 	}
 
-	// check the dana transaction is still pending
-	if danaTransaction.Status != transactiondomain.DanaStatusPending {
-		return fmt.Errorf("not pending")
+	return userMobile, nil
+}
+
+func (u *Usecase) CreatePenjualan(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error {
+	if _, err := u.checkOpenDate(ctx, userID, request.Date); err != nil {
+		return err
 	}
 
-	// reject dana
-	err = u.transactionRepo.RejectDana(id)
+	// create penjualan
+	err := u.transactionRepo.CreatePenjualan(ctx, userID, request)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PENJUALAN_CREATE), err)
 	}
 
 	return nil
 }
 
-func (u *Usecase) CancelSendDana(userID string, id string) error {
-	// find dana transaction
-	danaTransaction, err := u.transactionRepo.FindDanaTransaction(id)
-	if err != nil {
+func (u *Usecase) DeletePenjualan(ctx context.Context, userID string, id string) error {
+	if err := u.checkEntryOpen(ctx, transactiondomain.MobileEntryPenjualan, id); err != nil {
 		return err
 	}
 
-	// check the sender is the userID
-	if danaTransaction.Sender != userID {
-		return fmt.Errorf("not allowed")
-	}
-
-	// cancel send dana
-	err = u.transactionRepo.CancelSendDana(id)
+	err := u.deleteEntry(ctx, u.transactionRepo.DeletePenjualan, id)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_PENJUALAN_DELETE), err)
 	}
 
 	return nil
 }
 
-func (u *Usecase) FindUserMobile(userID string) ([]transactiondomain.WebUserMobile, error) {
-	userMobile, err := u.transactionRepo.FindUserMobile(userID)
+func (u *Usecase) FindPenjualan(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
+	penjualan, err := u.transactionRepo.FindPenjualan(ctx, userID, date)
 	if err != nil {
-		return nil, err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
 	}
 
-	return userMobile, nil
+	return penjualan, nil
 }
 
-func (u *Usecase) CreatePenjualan(userID string, request transactiondomain.TrxCreateRequest) error {
-	// create penjualan
-	err := u.transactionRepo.CreatePenjualan(userID, request)
-	if err != nil {
+func (u *Usecase) CreateBelanja(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error {
+	if _, err := u.checkOpenDate(ctx, userID, request.Date); err != nil {
 		return err
 	}
 
+	err := u.transactionRepo.CreateBelanja(ctx, userID, request)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BELANJA_CREATE), err)
+	}
+
 	return nil
 }
 
-func (u *Usecase) DeletePenjualan(userID string, id string) error {
-	err := u.transactionRepo.DeletePenjualan(id)
-	if err != nil {
+func (u *Usecase) DeleteBelanja(ctx context.Context, userID string, id string) error {
+	if err := u.checkEntryOpen(ctx, transactiondomain.MobileEntryBelanja, id); err != nil {
 		return err
 	}
 
+	err := u.deleteEntry(ctx, u.transactionRepo.DeleteBelanja, id)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BELANJA_DELETE), err)
+	}
+
 	return nil
 }
 
-func (u *Usecase) FindPenjualan(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
-	penjualan, err := u.transactionRepo.FindPenjualan(userID, date)
+func (u *Usecase) FindBelanja(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
+	belanja, err := u.transactionRepo.FindBelanja(ctx, userID, date)
 	if err != nil {
-		return nil, err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
 	}
 
-	return penjualan, nil
-}
-
-func (u *Usecase) CreateBelanja(userID string, request transactiondomain.TrxCreateRequest) error {
-	err := u.transactionRepo.CreateBelanja(userID, request)
+	var ids []string
+	for _, trx := range belanja {
+		ids = append(ids, trx.ID)
+	}
+	attachments, err := u.attachmentRepo.FindByOwner(ctx, attachmentdomain.OWNER_TYPE_BELANJA, ids)
 	if err != nil {
-		return err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	for i := range belanja {
+		belanja[i].Attachments = attachments[belanja[i].ID]
 	}
 
-	return nil
+	return belanja, nil
 }
 
-func (u *Usecase) DeleteBelanja(userID string, id string) error {
-	err := u.transactionRepo.DeleteBelanja(id)
+func (u *Usecase) CreateOperasional(ctx context.Context, userID string, request transactiondomain.TrxCreateOperasionalRequest) (*transactiondomain.OperasionalCreateResponse, error) {
+	date, err := u.checkOpenDate(ctx, userID, request.Date)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
-
-func (u *Usecase) FindBelanja(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error) {
-	belanja, err := u.transactionRepo.FindBelanja(userID, date)
+	request.ID = stringutil.GenerateUUID()
+	warnings, err := u.createOperasional(ctx, userID, request, date)
 	if err != nil {
 		return nil, err
 	}
 
-	return belanja, nil
+	return &transactiondomain.OperasionalCreateResponse{ID: request.ID, Warnings: warnings}, nil
 }
 
-func (u *Usecase) CreateOperasional(userID string, request transactiondomain.TrxCreateOperasionalRequest) error {
-	err := u.transactionRepo.CreateOperasional(userID, request)
-	if err != nil {
+func (u *Usecase) DeleteOperasional(ctx context.Context, userID string, id string) error {
+	if err := u.checkEntryOpen(ctx, transactiondomain.MobileEntryOperasional, id); err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (u *Usecase) DeleteOperasional(userID string, id string) error {
-	err := u.transactionRepo.DeleteOperasional(id)
+	err := u.deleteEntry(ctx, u.transactionRepo.DeleteOperasional, id)
 	if err != nil {
-		return err
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_OPERASIONAL_DELETE), err)
 	}
 
 	return nil
 }
 
-func (u *Usecase) FindOperasional(userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error) {
-	operasional, err := u.transactionRepo.FindOperasional(userID, date)
+func (u *Usecase) FindOperasional(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error) {
+	operasional, err := u.transactionRepo.FindOperasional(ctx, userID, date)
 	if err != nil {
-		return nil, err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	var ids []string
+	for _, trx := range operasional {
+		ids = append(ids, trx.ID)
+	}
+	attachments, err := u.attachmentRepo.FindByOwner(ctx, attachmentdomain.OWNER_TYPE_OPERASIONAL, ids)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	for i := range operasional {
+		operasional[i].Attachments = attachments[operasional[i].ID]
 	}
 
 	return operasional, nil
 }
 
-func (u *Usecase) FindSaldo(userID string, date time.Time) (*transactiondomain.SaldoResponse, error) {
-	saldo, err := u.transactionRepo.FindSaldo(userID, date)
+func (u *Usecase) FindSaldo(ctx context.Context, userID string, date time.Time) (*transactiondomain.SaldoResponse, error) {
+	saldo, err := u.transactionRepo.FindSaldo(ctx, userID, date)
 	if err != nil {
-		return nil, err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
 	}
 
 	return saldo, nil
 }
 
-func (u *Usecase) FindRekapitulasi(date time.Time) (*transactiondomain.RekapitulasiResponse, error) {
-	rekapitulasi, err := u.transactionRepo.FindRekapitulasi(date)
+func (u *Usecase) FindRekapitulasi(ctx context.Context, date time.Time) (*transactiondomain.RekapitulasiResponse, error) {
+	rekapitulasi, err := u.transactionRepo.FindRekapitulasi(ctx, date)
 	if err != nil {
-		return nil, err
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
 	}
 
 	return rekapitulasi, nil
 }
+
+// deleteEntry removes a penjualan, belanja or operasional with its attachments, the files are removed once the delete is committed
+func (u *Usecase) deleteEntry(ctx context.Context, remove func(ctx context.Context, id string) ([]string, error), id string) error {
+	keys, err := remove(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// a file left behind only takes space, the record is gone either way
+	for _, key := range keys {
+		if err := u.storage.Delete(ctx, key); err != nil {
+			logutil.WithContext(ctx).Warn(err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package transactionusecase
+
+import (
+	"context"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	dateutil "dromatech/pos-backend/internal/util/date"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"math"
+	"time"
+)
+
+// FindReconciliation compares the ordered quantity of the sell transactions with the belanja and transaction_buy per date and product
+func (u *Usecase) FindReconciliation(ctx context.Context, startDate, endDate time.Time) (*transactiondomain.ReconciliationResponse, error) {
+	if endDate.Before(startDate) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_RANGE_INVALID))
+	}
+	if endDate.Sub(startDate) >= MAX_REKAP_DAY*24*time.Hour {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_RANGE_TOO_LONG, MAX_REKAP_DAY))
+	}
+
+	items, err := u.transactionRepo.FindReconciliation(ctx, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	return &transactiondomain.ReconciliationResponse{StartDate: startDate, EndDate: endDate, Items: items}, nil
+}
+
+// ApplyReconciliation sets the average buy price of the reconciliation of a date, rounded to the rupiah, as buy price of
+// the sell transaction details of that date, details already at that price are left as they are
+func (u *Usecase) ApplyReconciliation(ctx context.Context, userID string, request transactiondomain.ReconciliationApplyRequest) (*transactiondomain.ReconciliationApplyResponse, error) {
+	date, err := time.Parse(dateutil.DateFormat(), request.Date)
+	if err != nil {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DATE_INVALID))
+	}
+
+	items, err := u.transactionRepo.FindReconciliation(ctx, date, date)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	selected := make(map[string]bool)
+	for _, productID := range request.ProductIDs {
+		selected[productID] = true
+	}
+	prices := make(map[string]float64)
+	var productIDs []string
+	for _, item := range items {
+		if item.AverageBuyPrice <= 0 || (len(selected) > 0 && !selected[item.ProductID]) {
+			continue
+		}
+		prices[item.ProductID] = math.Round(item.AverageBuyPrice)
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	response := &transactiondomain.ReconciliationApplyResponse{}
+	if len(productIDs) == 0 {
+		return response, nil
+	}
+
+	details, err := u.transactionRepo.FindDetails(ctx, []queryutil.Param{
+		{Logic: "AND", Field: "td.latest", Operator: "=", Value: true},
+		{Logic: "AND", Field: "t.date::date", Operator: "=", Value: date},
+		{Logic: "AND", Field: "t.transaction_type", Operator: "=", Value: transactiondomain.TRANSACTION_TYPE_SELL},
+		{Logic: "AND", Field: "t.status", Operator: "<>", Value: transactiondomain.TRANSACTION_BATAL},
+		{Logic: "AND", Field: "td.product_id", Operator: "IN", Value: productIDs},
+	})
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	tx := tenantutil.DB(ctx).Begin()
+	for _, detail := range details {
+		price := prices[detail.ProductID]
+		if detail.BuyPrice == price {
+			continue
+		}
+		if err := u.transactionRepo.UpdateHargaBeliTx(ctx, detail.ID, price, userID, tx); err != nil {
+			tx.Rollback()
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_RECONCILIATION_APPLY), err)
+		}
+		response.Updated++
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_RECONCILIATION_APPLY), err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,150 @@
+package transactionusecase
+
+import (
+	"context"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	exportutil "dromatech/pos-backend/internal/util/export"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"sort"
+	"time"
+)
+
+// MAX_REKAP_DAY caps the range of the rekapitulasi to a year
+const MAX_REKAP_DAY = 366
+
+var rekapBuckets = map[string]bool{
+	transactiondomain.RekapBucketDay:   true,
+	transactiondomain.RekapBucketWeek:  true,
+	transactiondomain.RekapBucketMonth: true,
+}
+
+// FindRekapRange sums the movements of the mobile users per bucket, per user, per belanja product and per operasional category over the range
+func (u *Usecase) FindRekapRange(ctx context.Context, startDate, endDate time.Time, bucket string) (*transactiondomain.RekapRangeResponse, error) {
+	if bucket == "" {
+		bucket = transactiondomain.RekapBucketDay
+	}
+	if !rekapBuckets[bucket] {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_BUCKET_INVALID))
+	}
+	if endDate.Before(startDate) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_RANGE_INVALID))
+	}
+	if endDate.Sub(startDate) >= MAX_REKAP_DAY*24*time.Hour {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_RANGE_TOO_LONG, MAX_REKAP_DAY))
+	}
+
+	periods, err := u.transactionRepo.FindRekapPeriods(ctx, startDate, endDate, bucket)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	products, err := u.transactionRepo.FindRekapProducts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	categories, err := u.transactionRepo.FindRekapCategories(ctx, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	rekap := &transactiondomain.RekapRangeResponse{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Bucket:     bucket,
+		Periods:    periods,
+		Users:      []transactiondomain.RekapUser{},
+		Products:   products,
+		Categories: categories,
+	}
+
+	users := make(map[string]*transactiondomain.RekapUser)
+	for _, period := range periods {
+		user, exists := users[period.WebUserID]
+		if !exists {
+			user = &transactiondomain.RekapUser{WebUserID: period.WebUserID, Name: period.Name}
+			users[period.WebUserID] = user
+		}
+		addRekapAmount(&user.RekapAmount, period.RekapAmount)
+		addRekapAmount(&rekap.Total, period.RekapAmount)
+	}
+	for _, user := range users {
+		rekap.Users = append(rekap.Users, *user)
+	}
+	sort.Slice(rekap.Users, func(i, j int) bool {
+		return rekap.Users[i].Name < rekap.Users[j].Name
+	})
+
+	return rekap, nil
+}
+
+// FindRekapBranch sums the movements per branch over the range, every branch is read whatever the branch of the session
+func (u *Usecase) FindRekapBranch(ctx context.Context, startDate, endDate time.Time) (*transactiondomain.RekapBranchResponse, error) {
+	if endDate.Before(startDate) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_RANGE_INVALID))
+	}
+	if endDate.Sub(startDate) >= MAX_REKAP_DAY*24*time.Hour {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_REKAP_RANGE_TOO_LONG, MAX_REKAP_DAY))
+	}
+
+	branches, err := u.transactionRepo.FindRekapBranches(ctx, startDate, endDate)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	rekap := &transactiondomain.RekapBranchResponse{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Branches:  branches,
+	}
+	for _, branch := range branches {
+		addRekapAmount(&rekap.Total, branch.RekapAmount)
+	}
+	return rekap, nil
+}
+
+// ExportRekapRange returns the range rekapitulasi as the sheets of an export
+func (u *Usecase) ExportRekapRange(ctx context.Context, startDate, endDate time.Time, bucket string) ([]exportutil.Table, error) {
+	rekap, err := u.FindRekapRange(ctx, startDate, endDate, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	amountHeaders := []string{"Penjualan", "Belanja", "Operasional", "Dana Tambahan", "Dana Masuk", "Dana Keluar"}
+
+	periods := exportutil.Table{Name: "Periode", Headers: append([]string{"Periode", "User"}, amountHeaders...)}
+	for _, period := range rekap.Periods {
+		periods.Rows = append(periods.Rows, append([]interface{}{period.Period.Format("2006-01-02"), period.Name}, rekapAmountCells(period.RekapAmount)...))
+	}
+
+	users := exportutil.Table{Name: "User", Headers: append([]string{"User"}, amountHeaders...)}
+	for _, user := range rekap.Users {
+		users.Rows = append(users.Rows, append([]interface{}{user.Name}, rekapAmountCells(user.RekapAmount)...))
+	}
+	users.Rows = append(users.Rows, append([]interface{}{"Total"}, rekapAmountCells(rekap.Total)...))
+
+	products := exportutil.Table{Name: "Produk Belanja", Headers: []string{"User", "Kode", "Produk", "Jumlah", "Total", "Harga Rata-rata"}}
+	for _, product := range rekap.Products {
+		products.Rows = append(products.Rows, []interface{}{product.Name, product.ProductCode, product.ProductName, product.Quantity,
+			product.Total, product.AveragePrice})
+	}
+
+	categories := exportutil.Table{Name: "Kategori Operasional", Headers: []string{"User", "Kode", "Kategori", "Jumlah Transaksi", "Total"}}
+	for _, category := range rekap.Categories {
+		categories.Rows = append(categories.Rows, []interface{}{category.Name, category.CategoryCode, category.CategoryName, category.Count, category.Total})
+	}
+
+	return []exportutil.Table{periods, users, products, categories}, nil
+}
+
+func addRekapAmount(total *transactiondomain.RekapAmount, amount transactiondomain.RekapAmount) {
+	total.Penjualan += amount.Penjualan
+	total.Belanja += amount.Belanja
+	total.Operasional += amount.Operasional
+	total.DanaTambahan += amount.DanaTambahan
+	total.DanaMasuk += amount.DanaMasuk
+	total.DanaKeluar += amount.DanaKeluar
+}
+
+func rekapAmountCells(amount transactiondomain.RekapAmount) []interface{} {
+	return []interface{}{amount.Penjualan, amount.Belanja, amount.Operasional, amount.DanaTambahan, amount.DanaMasuk, amount.DanaKeluar}
+}
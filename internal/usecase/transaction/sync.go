@@ -0,0 +1,450 @@
+package transactionusecase
+
+import (
+	"context"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	dateutil "dromatech/pos-backend/internal/util/date"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SYNC_MAX_RECORDS bounds a push, the client sends the rest in the next push
+const SYNC_MAX_RECORDS = 500
+
+const (
+	SYNC_DEFAULT_LIMIT = 200
+	SYNC_MAX_LIMIT     = 1000
+)
+
+// SyncPush applies the records recorded offline, a record already applied by an earlier push is reported as duplicate so a push can be retried.
+// An internal error stops the push, the results up to the failed record are returned and the client pushes the rest again
+func (u *Usecase) SyncPush(ctx context.Context, userID string, request transactiondomain.SyncPushRequest) (*transactiondomain.SyncPushResponse, error) {
+	total := len(request.Dana) + len(request.DanaTransfer) + len(request.Penjualan) + len(request.Belanja) + len(request.Operasional)
+	if total > SYNC_MAX_RECORDS {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_SYNC_TOO_MANY, SYNC_MAX_RECORDS))
+	}
+
+	response := &transactiondomain.SyncPushResponse{Results: []transactiondomain.SyncResult{}}
+	add := func(result transactiondomain.SyncResult, err error) bool {
+		if err != nil {
+			syncFailed(ctx, &result, err)
+		}
+		response.Results = append(response.Results, result)
+		return err == nil
+	}
+
+	for _, dana := range request.Dana {
+		if !add(u.syncDana(ctx, userID, dana)) {
+			return response, nil
+		}
+	}
+
+	for _, transfer := range request.DanaTransfer {
+		if !add(u.syncDanaTransfer(ctx, userID, transfer)) {
+			return response, nil
+		}
+	}
+
+	for _, penjualan := range request.Penjualan {
+		trx := transactiondomain.TrxCreateRequest{ID: penjualan.ID, Date: penjualan.Date, ProductID: penjualan.ProductID,
+			Quantity: penjualan.Quantity, Price: penjualan.Price, CreatedTime: penjualan.ClientTime}
+		result, err := u.syncEntry(ctx, userID, transactiondomain.SyncEntityPenjualan, transactiondomain.MobileEntryPenjualan,
+			penjualan.ID, penjualan.Date, penjualan.Deleted,
+			func(record interface{}) bool {
+				saved, ok := record.(*transactiondomain.PenjualanTunai)
+				return ok && sameTrx(trx, saved.Date, saved.ProductID, saved.Quantity, saved.Price)
+			},
+			func() error { return u.transactionRepo.CreatePenjualan(ctx, userID, trx) },
+			func() error { return u.deleteEntry(ctx, u.transactionRepo.DeletePenjualan, penjualan.ID) })
+		if !add(result, err) {
+			return response, nil
+		}
+	}
+
+	for _, belanja := range request.Belanja {
+		trx := transactiondomain.TrxCreateRequest{ID: belanja.ID, Date: belanja.Date, ProductID: belanja.ProductID,
+			Quantity: belanja.Quantity, Price: belanja.Price, CreatedTime: belanja.ClientTime}
+		result, err := u.syncEntry(ctx, userID, transactiondomain.SyncEntityBelanja, transactiondomain.MobileEntryBelanja,
+			belanja.ID, belanja.Date, belanja.Deleted,
+			func(record interface{}) bool {
+				saved, ok := record.(*transactiondomain.Belanja)
+				return ok && sameTrx(trx, saved.Date, saved.ProductID, saved.Quantity, saved.Price)
+			},
+			func() error { return u.transactionRepo.CreateBelanja(ctx, userID, trx) },
+			func() error { return u.deleteEntry(ctx, u.transactionRepo.DeleteBelanja, belanja.ID) })
+		if !add(result, err) {
+			return response, nil
+		}
+	}
+
+	for _, operasional := range request.Operasional {
+		trx := transactiondomain.TrxCreateOperasionalRequest{ID: operasional.ID, Date: operasional.Date, CategoryID: operasional.CategoryID,
+			Description: operasional.Description, Quantity: operasional.Quantity, Price: operasional.Price, CreatedTime: operasional.ClientTime}
+		result, err := u.syncEntry(ctx, userID, transactiondomain.SyncEntityOperasional, transactiondomain.MobileEntryOperasional,
+			operasional.ID, operasional.Date, operasional.Deleted,
+			func(record interface{}) bool {
+				saved, ok := record.(*transactiondomain.Operasional)
+				return ok && sameOperasional(trx, saved)
+			},
+			func() error {
+				// the date is validated by syncEntry before create
+				date, _ := time.Parse(dateutil.DateFormat(), trx.Date)
+				_, err := u.createOperasional(ctx, userID, trx, date)
+				return err
+			},
+			func() error { return u.deleteEntry(ctx, u.transactionRepo.DeleteOperasional, operasional.ID) })
+		if !add(result, err) {
+			return response, nil
+		}
+	}
+
+	return response, nil
+}
+
+// SyncChanges returns the changes of the records of the user after the cursor, only the latest change of a record in the page is returned
+func (u *Usecase) SyncChanges(ctx context.Context, userID string, cursor string, limit int) (*transactiondomain.SyncChangesResponse, error) {
+	after, ok := parseSyncCursor(cursor)
+	if !ok {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_SYNC_CURSOR_INVALID))
+	}
+
+	if limit <= 0 {
+		limit = SYNC_DEFAULT_LIMIT
+	}
+	if limit > SYNC_MAX_LIMIT {
+		limit = SYNC_MAX_LIMIT
+	}
+
+	changes, err := u.transactionRepo.FindChanges(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	response := &transactiondomain.SyncChangesResponse{Changes: []transactiondomain.SyncChange{}, Cursor: formatSyncCursor(after)}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		response.HasMore = true
+	}
+	if len(changes) == 0 {
+		return response, nil
+	}
+	last := changes[len(changes)-1]
+	response.Cursor = formatSyncCursor(transactiondomain.SyncCursor{TxID: last.TxID, Seq: last.Seq})
+
+	// keep the latest change of each record
+	latest := make(map[string]int)
+	for i, change := range changes {
+		latest[change.Entity+":"+change.ID] = i
+	}
+	ids := make(map[string][]string)
+	for i, change := range changes {
+		if latest[change.Entity+":"+change.ID] != i {
+			continue
+		}
+		if change.Action == transactiondomain.SyncActionUpsert {
+			ids[change.Entity] = append(ids[change.Entity], change.ID)
+		}
+	}
+
+	records := make(map[string]map[string]interface{})
+	for entity, entityIDs := range ids {
+		records[entity], err = u.transactionRepo.FindSyncRecords(ctx, entity, entityIDs)
+		if err != nil {
+			return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+	}
+
+	for i, change := range changes {
+		if latest[change.Entity+":"+change.ID] != i {
+			continue
+		}
+		if change.Action == transactiondomain.SyncActionUpsert {
+			record, ok := records[change.Entity][change.ID]
+			if ok {
+				change.Data = record
+			} else {
+				// removed by a later change past this page
+				change.Action = transactiondomain.SyncActionDelete
+			}
+		}
+		response.Changes = append(response.Changes, change)
+	}
+
+	return response, nil
+}
+
+func (u *Usecase) syncDana(ctx context.Context, userID string, item transactiondomain.SyncDana) (transactiondomain.SyncResult, error) {
+	result := transactiondomain.SyncResult{Entity: transactiondomain.SyncEntityDana, ID: item.ID}
+	date, ok := syncValidate(ctx, &result, item.ID, item.Date)
+	if !ok {
+		return result, nil
+	}
+	if closed, err := u.syncClosed(ctx, &result, userID, date); err != nil || closed {
+		return result, err
+	}
+
+	request := transactiondomain.DanaRequest{ID: item.ID, Date: item.Date, SaldoAwal: item.SaldoAwal,
+		DanaTambahan: item.DanaTambahan, CreatedTime: item.ClientTime}
+	if err := u.carrySaldoAwal(ctx, userID, date, &request); err != nil {
+		return result, err
+	}
+
+	existing, err := u.transactionRepo.FindDanaByDate(ctx, userID, date)
+	if err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	// a date has one dana, the client takes the id the server keeps
+	if existing != nil {
+		if existing.ID != item.ID {
+			result.ServerID = existing.ID
+		}
+		if existing.SaldoAwal == request.SaldoAwal && existing.DanaTambahan == request.DanaTambahan {
+			result.Status = transactiondomain.SyncStatusDuplicate
+			return result, nil
+		}
+
+		request.ID = existing.ID
+		if err := u.transactionRepo.UpdateDana(ctx, userID, request); err != nil {
+			return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UPDATE_DATA), err)
+		}
+		result.Status = transactiondomain.SyncStatusApplied
+		return result, nil
+	}
+
+	taken, err := u.transactionRepo.FindDanaByID(ctx, item.ID)
+	if err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if taken.ID != "" {
+		syncConflict(ctx, &result, transactiondomain.SyncReasonNotOwner, i18nutil.ERR_SYNC_ID_TAKEN)
+		return result, nil
+	}
+
+	if err := u.transactionRepo.CreateDana(ctx, userID, request); err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_CREATE), err)
+	}
+	result.Status = transactiondomain.SyncStatusApplied
+	return result, nil
+}
+
+func (u *Usecase) syncDanaTransfer(ctx context.Context, userID string, item transactiondomain.SyncDanaTransfer) (transactiondomain.SyncResult, error) {
+	result := transactiondomain.SyncResult{Entity: transactiondomain.SyncEntityDanaTransfer, ID: item.ID}
+	date, ok := syncValidate(ctx, &result, item.ID, item.Date)
+	if !ok {
+		return result, nil
+	}
+
+	existing, err := u.transactionRepo.FindDanaTransfer(ctx, item.ID)
+	if err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if existing != nil {
+		switch {
+		case existing.Sender != userID:
+			syncConflict(ctx, &result, transactiondomain.SyncReasonNotOwner, i18nutil.ERR_SYNC_ID_TAKEN)
+		case existing.Status == transactiondomain.DanaStatusRejected:
+			syncConflict(ctx, &result, transactiondomain.SyncReasonRejected, i18nutil.ERR_SYNC_TRANSFER_REJECTED)
+		case existing.Status == transactiondomain.DanaStatusCanceled:
+			syncConflict(ctx, &result, transactiondomain.SyncReasonCanceled, i18nutil.ERR_SYNC_TRANSFER_CANCELED)
+		case existing.Status == transactiondomain.DanaStatusExpired:
+			syncConflict(ctx, &result, transactiondomain.SyncReasonExpired, i18nutil.ERR_SYNC_TRANSFER_EXPIRED)
+		default:
+			result.Status = transactiondomain.SyncStatusDuplicate
+		}
+		return result, nil
+	}
+
+	hasPermission, err := u.transactionRepo.CheckUserMobilePermission(ctx, item.Receiver)
+	if err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_SEND), err)
+	}
+	if !hasPermission {
+		syncConflict(ctx, &result, transactiondomain.SyncReasonNotAllowed, i18nutil.ERR_MOBILE_NOT_ALLOWED)
+		return result, nil
+	}
+
+	if closed, err := u.syncClosed(ctx, &result, userID, date); err != nil || closed {
+		return result, err
+	}
+	if closed, err := u.syncClosed(ctx, &result, item.Receiver, date); err != nil || closed {
+		return result, err
+	}
+
+	err = u.transactionRepo.SendDana(ctx, userID, transactiondomain.DanaTransactionRequest{Date: item.Date, Receiver: item.Receiver,
+		Amount: item.Amount, ID: item.ID, CreatedTime: item.ClientTime})
+	if err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_DANA_SEND), err)
+	}
+	u.notifyTransfer(ctx, item.ID)
+	result.Status = transactiondomain.SyncStatusApplied
+	return result, nil
+}
+
+// syncEntry applies a penjualan, belanja or operasional, a delete of a record the server does not have is a duplicate.
+// The records cannot be edited, a record pushed again is a duplicate only when same finds it matches the saved one
+func (u *Usecase) syncEntry(ctx context.Context, userID, entity, table, id, date string, deleted bool, same func(record interface{}) bool, create, remove func() error) (transactiondomain.SyncResult, error) {
+	result := transactiondomain.SyncResult{Entity: entity, ID: id}
+	if !syncValidID(ctx, &result, id) {
+		return result, nil
+	}
+
+	entry, err := u.transactionRepo.FindMobileEntry(ctx, table, id)
+	if err != nil {
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+
+	if entry != nil && entry.WebUserID != userID {
+		syncConflict(ctx, &result, transactiondomain.SyncReasonNotOwner, i18nutil.ERR_SYNC_ID_TAKEN)
+		return result, nil
+	}
+
+	if deleted {
+		if entry == nil {
+			result.Status = transactiondomain.SyncStatusDuplicate
+			return result, nil
+		}
+		if closed, err := u.syncClosed(ctx, &result, userID, entry.Date); err != nil || closed {
+			return result, err
+		}
+		if err := remove(); err != nil {
+			return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SYNC_SAVE), err)
+		}
+		result.Status = transactiondomain.SyncStatusApplied
+		return result, nil
+	}
+
+	if entry != nil {
+		records, err := u.transactionRepo.FindSyncRecords(ctx, entity, []string{id})
+		if err != nil {
+			return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+		}
+		if !same(records[id]) {
+			syncConflict(ctx, &result, transactiondomain.SyncReasonModified, i18nutil.ERR_SYNC_RECORD_MODIFIED)
+			return result, nil
+		}
+		result.Status = transactiondomain.SyncStatusDuplicate
+		return result, nil
+	}
+	parsed, ok := syncValidDate(ctx, &result, date)
+	if !ok {
+		return result, nil
+	}
+	if closed, err := u.syncClosed(ctx, &result, userID, parsed); err != nil || closed {
+		return result, err
+	}
+	if err := create(); err != nil {
+		// refused by a rule of the server such as a budget, the client keeps the record
+		var restErr *restutil.Error
+		if errors.As(err, &restErr) && restErr.HTTPStatus < http.StatusInternalServerError {
+			result.Status = transactiondomain.SyncStatusConflict
+			result.Reason = transactiondomain.SyncReasonRefused
+			result.Message = restErr.Message
+			return result, nil
+		}
+		return result, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_SYNC_SAVE), err)
+	}
+	result.Status = transactiondomain.SyncStatusApplied
+	return result, nil
+}
+
+// syncClosed marks the result as a conflict when the day of the user is closed
+func (u *Usecase) syncClosed(ctx context.Context, result *transactiondomain.SyncResult, userID string, date time.Time) (bool, error) {
+	closing, err := u.transactionRepo.FindClosing(ctx, userID, date)
+	if err != nil {
+		return false, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	if closing == nil {
+		return false, nil
+	}
+	result.Status = transactiondomain.SyncStatusConflict
+	result.Reason = transactiondomain.SyncReasonClosed
+	result.Message = i18nutil.T(ctx, i18nutil.ERR_CASH_CLOSED, date.Format(dateutil.DateFormatResponse()))
+	return true, nil
+}
+
+// syncValidate checks the client id and date of a pushed record, the result is marked invalid when false
+func syncValidate(ctx context.Context, result *transactiondomain.SyncResult, id, date string) (time.Time, bool) {
+	if !syncValidID(ctx, result, id) {
+		return time.Time{}, false
+	}
+	return syncValidDate(ctx, result, date)
+}
+
+// syncValidID checks the client id fits the id columns
+func syncValidID(ctx context.Context, result *transactiondomain.SyncResult, id string) bool {
+	if id == "" || len(id) > 32 {
+		result.Status = transactiondomain.SyncStatusInvalid
+		result.Message = i18nutil.T(ctx, i18nutil.ERR_SYNC_ID_INVALID)
+		return false
+	}
+	return true
+}
+
+func syncValidDate(ctx context.Context, result *transactiondomain.SyncResult, date string) (time.Time, bool) {
+	parsed, err := time.Parse(dateutil.DateFormat(), date)
+	if err != nil {
+		result.Status = transactiondomain.SyncStatusInvalid
+		result.Message = i18nutil.T(ctx, i18nutil.ERR_DATE_INVALID)
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+func syncConflict(ctx context.Context, result *transactiondomain.SyncResult, reason string, message string) {
+	result.Status = transactiondomain.SyncStatusConflict
+	result.Reason = reason
+	result.Message = i18nutil.T(ctx, message)
+}
+
+// syncFailed marks the result of the record an internal error stopped the push at
+func syncFailed(ctx context.Context, result *transactiondomain.SyncResult, err error) {
+	result.Status = transactiondomain.SyncStatusFailed
+	result.Reason = ""
+	result.Message = i18nutil.T(ctx, i18nutil.ERR_SYNC_SAVE)
+	var restErr *restutil.Error
+	if errors.As(err, &restErr) {
+		result.Message = restErr.Message
+	}
+}
+
+// sameTrx tells whether a penjualan or belanja pushed again matches the saved one
+func sameTrx(trx transactiondomain.TrxCreateRequest, date time.Time, productID string, quantity int16, price float64) bool {
+	return trx.Date == date.Format(dateutil.DateFormat()) && trx.ProductID == productID && trx.Quantity == quantity && trx.Price == price
+}
+
+// sameOperasional tells whether an operasional pushed again matches the saved one
+func sameOperasional(trx transactiondomain.TrxCreateOperasionalRequest, saved *transactiondomain.Operasional) bool {
+	return trx.Date == saved.Date.Format(dateutil.DateFormat()) && trx.CategoryID == saved.CategoryID &&
+		trx.Description == saved.Description && trx.Quantity == saved.Quantity && trx.Price == saved.Price
+}
+
+// parseSyncCursor reads the cursor of the change feed, empty reads from the start
+func parseSyncCursor(value string) (transactiondomain.SyncCursor, bool) {
+	cursor := transactiondomain.SyncCursor{}
+	if value == "" {
+		return cursor, true
+	}
+
+	txID, seq, found := strings.Cut(value, "-")
+	if !found {
+		return cursor, false
+	}
+	var err error
+	if cursor.TxID, err = strconv.ParseInt(txID, 10, 64); err != nil || cursor.TxID < 0 {
+		return transactiondomain.SyncCursor{}, false
+	}
+	if cursor.Seq, err = strconv.ParseInt(seq, 10, 64); err != nil || cursor.Seq < 0 {
+		return transactiondomain.SyncCursor{}, false
+	}
+	return cursor, true
+}
+
+func formatSyncCursor(cursor transactiondomain.SyncCursor) string {
+	return strconv.FormatInt(cursor.TxID, 10) + "-" + strconv.FormatInt(cursor.Seq, 10)
+}
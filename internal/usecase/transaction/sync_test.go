@@ -0,0 +1,184 @@
+package transactionusecase
+
+import (
+	"context"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	transactionrepo "dromatech/pos-backend/internal/repo/transaction"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"errors"
+	"testing"
+	"time"
+)
+
+// syncRepo keeps the penjualan of one user in memory, the methods the sync does not use are left to the embedded nil repo
+type syncRepo struct {
+	transactionrepo.TransactionRepo
+	penjualan map[string]*transactiondomain.PenjualanTunai
+	closed    bool
+	createErr error
+}
+
+func (r *syncRepo) FindMobileEntry(ctx context.Context, table string, id string) (*transactiondomain.MobileEntry, error) {
+	saved, ok := r.penjualan[id]
+	if !ok {
+		return nil, nil
+	}
+	return &transactiondomain.MobileEntry{ID: saved.ID, Date: saved.Date, WebUserID: saved.WebUserID}, nil
+}
+
+func (r *syncRepo) FindSyncRecords(ctx context.Context, entity string, ids []string) (map[string]interface{}, error) {
+	records := make(map[string]interface{})
+	for _, id := range ids {
+		if saved, ok := r.penjualan[id]; ok {
+			records[id] = saved
+		}
+	}
+	return records, nil
+}
+
+func (r *syncRepo) FindClosing(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error) {
+	if r.closed {
+		return &transactiondomain.CashClosing{}, nil
+	}
+	return nil, nil
+}
+
+func (r *syncRepo) CreatePenjualan(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	date, _ := time.Parse("2006-01-02", request.Date)
+	r.penjualan[request.ID] = &transactiondomain.PenjualanTunai{ID: request.ID, Date: date, WebUserID: userID,
+		ProductID: request.ProductID, Quantity: request.Quantity, Price: request.Price}
+	return nil
+}
+
+func (r *syncRepo) DeletePenjualan(ctx context.Context, id string) ([]string, error) {
+	delete(r.penjualan, id)
+	return nil, nil
+}
+
+func newSyncRepo() *syncRepo {
+	date, _ := time.Parse("2006-01-02", "2024-03-01")
+	return &syncRepo{penjualan: map[string]*transactiondomain.PenjualanTunai{
+		"P1": {ID: "P1", Date: date, WebUserID: "U1", ProductID: "PR1", Quantity: 2, Price: 5000},
+		"P2": {ID: "P2", Date: date, WebUserID: "U2", ProductID: "PR1", Quantity: 1, Price: 5000},
+	}}
+}
+
+func TestSyncPushPenjualan(t *testing.T) {
+	tests := []struct {
+		name      string
+		item      transactiondomain.SyncTrx
+		closed    bool
+		createErr error
+		status    string
+		reason    string
+	}{
+		{"new record", transactiondomain.SyncTrx{ID: "P3", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000}, false, nil,
+			transactiondomain.SyncStatusApplied, ""},
+		{"pushed again", transactiondomain.SyncTrx{ID: "P1", Date: "2024-03-01", ProductID: "PR1", Quantity: 2, Price: 5000}, false, nil,
+			transactiondomain.SyncStatusDuplicate, ""},
+		{"edited offline", transactiondomain.SyncTrx{ID: "P1", Date: "2024-03-01", ProductID: "PR1", Quantity: 3, Price: 5000}, false, nil,
+			transactiondomain.SyncStatusConflict, transactiondomain.SyncReasonModified},
+		{"moved to another day", transactiondomain.SyncTrx{ID: "P1", Date: "2024-03-02", ProductID: "PR1", Quantity: 2, Price: 5000}, false, nil,
+			transactiondomain.SyncStatusConflict, transactiondomain.SyncReasonModified},
+		{"id of another user", transactiondomain.SyncTrx{ID: "P2", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000}, false, nil,
+			transactiondomain.SyncStatusConflict, transactiondomain.SyncReasonNotOwner},
+		{"deleted", transactiondomain.SyncTrx{ID: "P1", Deleted: true}, false, nil,
+			transactiondomain.SyncStatusApplied, ""},
+		{"deleted again", transactiondomain.SyncTrx{ID: "P9", Deleted: true}, false, nil,
+			transactiondomain.SyncStatusDuplicate, ""},
+		{"deleted on a closed day", transactiondomain.SyncTrx{ID: "P1", Deleted: true}, true, nil,
+			transactiondomain.SyncStatusConflict, transactiondomain.SyncReasonClosed},
+		{"closed day", transactiondomain.SyncTrx{ID: "P3", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000}, true, nil,
+			transactiondomain.SyncStatusConflict, transactiondomain.SyncReasonClosed},
+		{"refused by the server", transactiondomain.SyncTrx{ID: "P3", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000}, false,
+			restutil.ErrBadRequest("over budget"), transactiondomain.SyncStatusConflict, transactiondomain.SyncReasonRefused},
+		{"no id", transactiondomain.SyncTrx{Date: "2024-03-01"}, false, nil,
+			transactiondomain.SyncStatusInvalid, ""},
+		{"id too long", transactiondomain.SyncTrx{ID: "0123456789012345678901234567890123", Date: "2024-03-01"}, false, nil,
+			transactiondomain.SyncStatusInvalid, ""},
+		{"bad date", transactiondomain.SyncTrx{ID: "P3", Date: "01-03-2024"}, false, nil,
+			transactiondomain.SyncStatusInvalid, ""},
+		{"internal error", transactiondomain.SyncTrx{ID: "P3", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000}, false,
+			errors.New("connection reset"), transactiondomain.SyncStatusFailed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newSyncRepo()
+			repo.closed = tt.closed
+			repo.createErr = tt.createErr
+			u := &Usecase{transactionRepo: repo}
+
+			response, err := u.SyncPush(context.Background(), "U1", transactiondomain.SyncPushRequest{Penjualan: []transactiondomain.SyncTrx{tt.item}})
+			if err != nil {
+				t.Fatalf("SyncPush() error = %v", err)
+			}
+			if len(response.Results) != 1 {
+				t.Fatalf("SyncPush() results = %d, want 1", len(response.Results))
+			}
+			result := response.Results[0]
+			if result.Status != tt.status || result.Reason != tt.reason {
+				t.Errorf("SyncPush() = %s/%s, want %s/%s", result.Status, result.Reason, tt.status, tt.reason)
+			}
+			if result.Status != transactiondomain.SyncStatusApplied && result.Status != transactiondomain.SyncStatusDuplicate && result.Message == "" {
+				t.Errorf("SyncPush() %s without a message", result.Status)
+			}
+		})
+	}
+}
+
+func TestSyncPushStopsAtFailure(t *testing.T) {
+	repo := newSyncRepo()
+	repo.createErr = errors.New("connection reset")
+	u := &Usecase{transactionRepo: repo}
+
+	response, err := u.SyncPush(context.Background(), "U1", transactiondomain.SyncPushRequest{Penjualan: []transactiondomain.SyncTrx{
+		{ID: "P1", Date: "2024-03-01", ProductID: "PR1", Quantity: 2, Price: 5000},
+		{ID: "P3", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000},
+		{ID: "P4", Date: "2024-03-01", ProductID: "PR1", Quantity: 1, Price: 5000},
+	}})
+	if err != nil {
+		t.Fatalf("SyncPush() error = %v", err)
+	}
+
+	want := []string{transactiondomain.SyncStatusDuplicate, transactiondomain.SyncStatusFailed}
+	if len(response.Results) != len(want) {
+		t.Fatalf("SyncPush() results = %d, want %d", len(response.Results), len(want))
+	}
+	for i, status := range want {
+		if response.Results[i].Status != status {
+			t.Errorf("SyncPush() result %d = %s, want %s", i, response.Results[i].Status, status)
+		}
+	}
+}
+
+func TestParseSyncCursor(t *testing.T) {
+	tests := []struct {
+		value  string
+		cursor transactiondomain.SyncCursor
+		ok     bool
+	}{
+		{"", transactiondomain.SyncCursor{}, true},
+		{"0-0", transactiondomain.SyncCursor{}, true},
+		{"1042-77", transactiondomain.SyncCursor{TxID: 1042, Seq: 77}, true},
+		{"77", transactiondomain.SyncCursor{}, false},
+		{"-1-5", transactiondomain.SyncCursor{}, false},
+		{"1042-", transactiondomain.SyncCursor{}, false},
+		{"a-b", transactiondomain.SyncCursor{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cursor, ok := parseSyncCursor(tt.value)
+			if cursor != tt.cursor || ok != tt.ok {
+				t.Errorf("parseSyncCursor(%q) = %v, %v, want %v, %v", tt.value, cursor, ok, tt.cursor, tt.ok)
+			}
+			if ok && tt.value != "" && formatSyncCursor(cursor) != tt.value {
+				t.Errorf("formatSyncCursor(%v) = %q, want %q", cursor, formatSyncCursor(cursor), tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package transactionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	taxutil "dromatech/pos-backend/internal/util/tax"
+	"strconv"
+)
+
+// DEFAULT_PPN_RATE is the PPN percentage when neither the product, the customer nor PPN_RATE sets one
+const DEFAULT_PPN_RATE = 11.0
+
+// rounding is how the discounts, the DPP and the PPN are rounded, from TAX_ROUNDING and TAX_ROUNDING_UNIT
+type rounding struct {
+	mode string
+	unit float64
+}
+
+func (r rounding) round(value float64) float64 {
+	return taxutil.Round(value, r.mode, r.unit)
+}
+
+// applyTax works out the discounts, DPP, PPN and grand total of a transaction and keeps them on the transaction and its details
+func (uc *Usecase) applyTax(ctx context.Context, transaction *transactiondomain.Transaction, failed string) error {
+	var productIDs []string
+	for _, detail := range transaction.TransactionDetail {
+		productIDs = append(productIDs, detail.ProductID)
+	}
+
+	setting, err := uc.transactionRepo.FindTaxSetting(ctx, transaction.StakeholderID, productIDs)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, failed), err)
+	}
+
+	if !calculateTax(transaction, setting, uc.ppnRate(ctx), uc.taxRounding(ctx)) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DISCOUNT_INVALID))
+	}
+	return nil
+}
+
+// calculateTax takes the line discounts off the lines, spreads the invoice discount over the lines by their share of the
+// subtotal and works out the DPP and PPN of every line at its own rate, false when a discount is not valid.
+// The DPP and PPN of the transaction are the sums of the rounded lines so the tax invoice adds up.
+func calculateTax(transaction *transactiondomain.Transaction, setting *transactiondomain.TaxSetting, defaultRate float64, rounding rounding) bool {
+	nets := make([]float64, len(transaction.TransactionDetail))
+	subtotal := 0.0
+	largest := -1
+	for i, detail := range transaction.TransactionDetail {
+		gross := detail.SellPrice * detail.Quantity
+		discount, ok := discountOf(gross, detail.DiscountType, detail.DiscountValue)
+		if !ok {
+			return false
+		}
+		detail.DiscountAmount = rounding.round(discount)
+		nets[i] = gross - detail.DiscountAmount
+		subtotal += nets[i]
+		if largest < 0 || nets[i] > nets[largest] {
+			largest = i
+		}
+	}
+
+	discount, ok := discountOf(subtotal, transaction.DiscountType, transaction.DiscountValue)
+	if !ok {
+		return false
+	}
+	discount = rounding.round(discount)
+
+	// the share of every line is rounded to the unit, what the rounding leaves over goes to the largest line
+	shares := make([]float64, len(nets))
+	if subtotal != 0 {
+		spread := 0.0
+		for i := range nets {
+			shares[i] = taxutil.Round(discount*nets[i]/subtotal, taxutil.ROUNDING_NEAREST, rounding.unit)
+			spread += shares[i]
+		}
+		shares[largest] += discount - spread
+	}
+
+	dpp, ppn := 0.0, 0.0
+	for i, detail := range transaction.TransactionDetail {
+		base := nets[i] - shares[i]
+		rate := taxRate(setting, detail.ProductID, defaultRate)
+		if setting.TaxInclusive {
+			_, linePpn := taxutil.Exclusive(base, rate)
+			detail.Ppn = rounding.round(linePpn)
+			detail.Dpp = base - detail.Ppn
+		} else {
+			detail.Dpp = base
+			detail.Ppn = rounding.round(base * rate / 100)
+		}
+
+		detail.TaxRate = rate
+		dpp += detail.Dpp
+		ppn += detail.Ppn
+	}
+
+	transaction.TaxInclusive = setting.TaxInclusive
+	transaction.Subtotal = subtotal
+	transaction.Discount = discount
+	transaction.Dpp = dpp
+	transaction.Ppn = ppn
+	transaction.GrandTotal = dpp + ppn
+	transaction.Total = transaction.GrandTotal
+	return true
+}
+
+// discountOf is the discount of base, a percentage of it or an amount no more than it, false when the discount is not valid
+func discountOf(base float64, discountType string, value float64) (float64, bool) {
+	switch discountType {
+	case "":
+		return 0, value == 0
+	case transactiondomain.DISCOUNT_PERCENT:
+		if value < 0 || value > 100 {
+			return 0, false
+		}
+		return base * value / 100, true
+	case transactiondomain.DISCOUNT_AMOUNT:
+		if value < 0 {
+			return 0, false
+		}
+		if value > base {
+			return base, true
+		}
+		return value, true
+	}
+	return 0, false
+}
+
+// taxRate is the PPN rate of a product, none for a customer that is not taxable, else the rate of the product, of the customer or the default
+func taxRate(setting *transactiondomain.TaxSetting, productID string, defaultRate float64) float64 {
+	if !setting.Taxable {
+		return 0
+	}
+	if rate := setting.ProductRates[productID]; rate != nil {
+		return *rate
+	}
+	if setting.CustomerRate != nil {
+		return *setting.CustomerRate
+	}
+	return defaultRate
+}
+
+func (uc *Usecase) ppnRate(ctx context.Context) float64 {
+	rate, err := strconv.ParseFloat(uc.configRepo.GetValue(ctx, configdomain.PPN_RATE), 64)
+	if err != nil || !taxutil.ValidRate(&rate) {
+		return DEFAULT_PPN_RATE
+	}
+	return rate
+}
+
+func (uc *Usecase) taxRounding(ctx context.Context) rounding {
+	unit, err := strconv.ParseFloat(uc.configRepo.GetValue(ctx, configdomain.TAX_ROUNDING_UNIT), 64)
+	if err != nil || unit <= 0 {
+		unit = 1
+	}
+	return rounding{mode: uc.configRepo.GetValue(ctx, configdomain.TAX_ROUNDING), unit: unit}
+}
@@ -0,0 +1,156 @@
+package transactionusecase
+
+import (
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	taxutil "dromatech/pos-backend/internal/util/tax"
+	"math"
+	"testing"
+)
+
+// line is a detail of a test transaction and the DPP and PPN it should get
+type line struct {
+	product       string
+	price         float64
+	quantity      float64
+	discountType  string
+	discountValue float64
+	dpp           float64
+	ppn           float64
+}
+
+func rate(value float64) *float64 {
+	return &value
+}
+
+func TestCalculateTax(t *testing.T) {
+	taxable := &transactiondomain.TaxSetting{Taxable: true}
+	nearest := rounding{mode: taxutil.ROUNDING_NEAREST, unit: 1}
+
+	tests := []struct {
+		name          string
+		setting       *transactiondomain.TaxSetting
+		rounding      rounding
+		lines         []line
+		discountType  string
+		discountValue float64
+		ok            bool
+		discount      float64
+		dpp           float64
+		ppn           float64
+		grandTotal    float64
+	}{
+		{"exclusive", taxable, nearest,
+			[]line{{"A", 10000, 3, "", 0, 30000, 3300}},
+			"", 0, true, 0, 30000, 3300, 33300},
+		{"inclusive", &transactiondomain.TaxSetting{Taxable: true, TaxInclusive: true}, nearest,
+			[]line{{"A", 11100, 1, "", 0, 10000, 1100}, {"B", 5000, 1, "", 0, 4505, 495}},
+			"", 0, true, 0, 14505, 1595, 16100},
+		{"line discounts", taxable, nearest,
+			[]line{{"A", 10000, 2, transactiondomain.DISCOUNT_PERCENT, 10, 18000, 1980}, {"B", 5000, 1, transactiondomain.DISCOUNT_AMOUNT, 500, 4500, 495}},
+			"", 0, true, 0, 22500, 2475, 24975},
+		{"invoice discount spread by share", taxable, nearest,
+			[]line{{"A", 10000, 1, "", 0, 9667, 1063}, {"B", 20000, 1, "", 0, 19333, 2127}},
+			transactiondomain.DISCOUNT_AMOUNT, 1000, true, 1000, 29000, 3190, 32190},
+		{"invoice discount remainder on the largest line", taxable, nearest,
+			[]line{{"A", 10000, 1, "", 0, 9666, 1063}, {"B", 10000, 1, "", 0, 9667, 1063}, {"C", 10000, 1, "", 0, 9667, 1063}},
+			transactiondomain.DISCOUNT_AMOUNT, 1000, true, 1000, 29000, 3189, 32189},
+		{"invoice discount inclusive", &transactiondomain.TaxSetting{Taxable: true, TaxInclusive: true}, nearest,
+			[]line{{"A", 11100, 1, "", 0, 9000, 990}, {"B", 11100, 1, "", 0, 9000, 990}},
+			transactiondomain.DISCOUNT_PERCENT, 10, true, 2220, 18000, 1980, 19980},
+		{"rounded down to the hundred", taxable, rounding{mode: taxutil.ROUNDING_DOWN, unit: 100},
+			[]line{{"A", 10050, 1, "", 0, 10050, 1100}},
+			"", 0, true, 0, 10050, 1100, 11150},
+		{"rounded up", taxable, rounding{mode: taxutil.ROUNDING_UP, unit: 1},
+			[]line{{"A", 1001, 1, "", 0, 1001, 111}},
+			"", 0, true, 0, 1001, 111, 1112},
+		{"not taxable", &transactiondomain.TaxSetting{}, nearest,
+			[]line{{"A", 10000, 1, "", 0, 10000, 0}},
+			"", 0, true, 0, 10000, 0, 10000},
+		{"product and customer rates", &transactiondomain.TaxSetting{Taxable: true, CustomerRate: rate(12), ProductRates: map[string]*float64{"A": rate(0)}}, nearest,
+			[]line{{"A", 10000, 1, "", 0, 10000, 0}, {"B", 10000, 1, "", 0, 10000, 1200}},
+			"", 0, true, 0, 20000, 1200, 21200},
+		{"invoice discount over 100 percent", taxable, nearest,
+			[]line{{"A", 10000, 1, "", 0, 0, 0}},
+			transactiondomain.DISCOUNT_PERCENT, 120, false, 0, 0, 0, 0},
+		{"line discount without a type", taxable, nearest,
+			[]line{{"A", 10000, 1, "", 5, 0, 0}},
+			"", 0, false, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &transactiondomain.Transaction{DiscountType: tt.discountType, DiscountValue: tt.discountValue}
+			for _, l := range tt.lines {
+				transaction.TransactionDetail = append(transaction.TransactionDetail, &transactiondomain.TransactionDetail{
+					ProductID: l.product, SellPrice: l.price, Quantity: l.quantity, DiscountType: l.discountType, DiscountValue: l.discountValue,
+				})
+			}
+
+			if ok := calculateTax(transaction, tt.setting, 11, tt.rounding); ok != tt.ok {
+				t.Fatalf("calculateTax() = %v, want %v", ok, tt.ok)
+			}
+			if !tt.ok {
+				return
+			}
+
+			if transaction.Discount != tt.discount || transaction.Dpp != tt.dpp || transaction.Ppn != tt.ppn || transaction.GrandTotal != tt.grandTotal {
+				t.Errorf("discount %v, dpp %v, ppn %v, grand total %v, want %v, %v, %v, %v", transaction.Discount, transaction.Dpp, transaction.Ppn,
+					transaction.GrandTotal, tt.discount, tt.dpp, tt.ppn, tt.grandTotal)
+			}
+
+			dpp, ppn := 0.0, 0.0
+			for i, detail := range transaction.TransactionDetail {
+				if detail.Dpp != tt.lines[i].dpp || detail.Ppn != tt.lines[i].ppn {
+					t.Errorf("line %d dpp %v, ppn %v, want %v, %v", i+1, detail.Dpp, detail.Ppn, tt.lines[i].dpp, tt.lines[i].ppn)
+				}
+				dpp += detail.Dpp
+				ppn += detail.Ppn
+			}
+			if dpp != transaction.Dpp || ppn != transaction.Ppn {
+				t.Errorf("the lines sum to dpp %v, ppn %v, the transaction has %v, %v", dpp, ppn, transaction.Dpp, transaction.Ppn)
+			}
+			if math.Abs(transaction.Subtotal-transaction.Discount-transaction.Dpp-ppnIncluded(transaction)) > 1e-9 {
+				t.Errorf("subtotal %v less discount %v is not the dpp %v", transaction.Subtotal, transaction.Discount, transaction.Dpp)
+			}
+		})
+	}
+}
+
+// ppnIncluded is the PPN taken off the subtotal of an inclusive transaction, none for an exclusive one
+func ppnIncluded(transaction *transactiondomain.Transaction) float64 {
+	if transaction.TaxInclusive {
+		return transaction.Ppn
+	}
+	return 0
+}
+
+func TestDiscountOf(t *testing.T) {
+	tests := []struct {
+		name         string
+		base         float64
+		discountType string
+		value        float64
+		discount     float64
+		ok           bool
+	}{
+		{"none", 10000, "", 0, 0, true},
+		{"value without a type", 10000, "", 5, 0, false},
+		{"percent", 10000, transactiondomain.DISCOUNT_PERCENT, 12.5, 1250, true},
+		{"all of it", 10000, transactiondomain.DISCOUNT_PERCENT, 100, 10000, true},
+		{"over 100 percent", 10000, transactiondomain.DISCOUNT_PERCENT, 100.5, 0, false},
+		{"negative percent", 10000, transactiondomain.DISCOUNT_PERCENT, -1, 0, false},
+		{"amount", 10000, transactiondomain.DISCOUNT_AMOUNT, 2500, 2500, true},
+		{"amount over the base", 10000, transactiondomain.DISCOUNT_AMOUNT, 15000, 10000, true},
+		{"negative amount", 10000, transactiondomain.DISCOUNT_AMOUNT, -1, 0, false},
+		{"unknown type", 10000, "FREE", 10, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount, ok := discountOf(tt.base, tt.discountType, tt.value)
+			if discount != tt.discount || ok != tt.ok {
+				t.Errorf("discountOf() = %v, %v, want %v, %v", discount, ok, tt.discount, tt.ok)
+			}
+		})
+	}
+}
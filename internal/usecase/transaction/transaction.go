@@ -1,19 +1,25 @@
 package transactionusecase
 
 import (
-	"fmt"
+	"context"
+	exportutil "dromatech/pos-backend/internal/util/export"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	storageutil "dromatech/pos-backend/internal/util/storage"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 
-	"dromatech/pos-backend/global"
 	customerdomain "dromatech/pos-backend/internal/domain/customer"
 	supplierdomain "dromatech/pos-backend/internal/domain/supplier"
 	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	attachmentrepo "dromatech/pos-backend/internal/repo/attachment"
 	customerrepo "dromatech/pos-backend/internal/repo/customer"
+	expenserepo "dromatech/pos-backend/internal/repo/expense"
 	kontrabonrepo "dromatech/pos-backend/internal/repo/kontrabon"
 	sequencerepo "dromatech/pos-backend/internal/repo/sequence"
 	supplierrepo "dromatech/pos-backend/internal/repo/supplier"
@@ -24,47 +30,69 @@ import (
 )
 
 type TransactoionUsecase interface {
-	CreateTransaction(transaction *transactiondomain.Transaction) (string, error)
-	ViewTransaction(startDate, endDate, code, stakeholderID, txType, status, productID string) ([]*transactiondomain.Transaction, error)
-	UpdateStatus(transactionID string) error
-	UpdateBuyPrice(transactionID, productID string, buyPrice, sellPrice float64, quantity, buyQuantity int64) error
-	ViewSellTransaction(startDate, endDate, code, stakeholderID, txType, status, productID, txId string) ([]*transactiondomain.TransactionStatus, error)
-	CancelTrx(transactionID string) error
-	UpdateTransaction(transaction *transactiondomain.Transaction) error
-	FindReport(startDate, endDate, code, stakeholderID, txType, status, productID, txId string) ([]*transactiondomain.ReportDate, error)
-	UpdateHargaBeli(request transactiondomain.UpdateHargaBeliRequest) error
-	InsertTransactionBuy(request transactiondomain.InsertTransactionBuyRequestBulk) error
-	FindCustomerCredit(month time.Time, sell bool) (*transactiondomain.TransactionCredit, error)
-	FindCustomerReport(stakeholderId string, month time.Time) (*transactiondomain.LaporanCustomerSumary, error)
+	CreateTransaction(ctx context.Context, transaction *transactiondomain.Transaction) (string, error)
+	ViewTransaction(ctx context.Context, startDate, endDate, code, stakeholderID, txType, status, productID string) ([]*transactiondomain.Transaction, error)
+	UpdateStatus(ctx context.Context, transactionID string) error
+	UpdateBuyPrice(ctx context.Context, transactionID, productID string, buyPrice, sellPrice float64, quantity, buyQuantity int64) error
+	ViewSellTransaction(ctx context.Context, startDate, endDate, code, stakeholderID, txType, status, productID, txId string, list queryutil.ListParam) ([]*transactiondomain.TransactionStatus, int64, error)
+	CancelTrx(ctx context.Context, transactionID string) error
+	UpdateTransaction(ctx context.Context, transaction *transactiondomain.Transaction) error
+	FindReport(ctx context.Context, startDate, endDate, code, stakeholderID, txType, status, productID, txId string) ([]*transactiondomain.ReportDate, error)
+	ExportEFaktur(ctx context.Context, startDate, endDate time.Time) ([][]interface{}, error)
+	AllocateTaxInvoiceNumbers(ctx context.Context, request transactiondomain.TaxInvoiceAllocateRequest) (*transactiondomain.TaxInvoiceAllocateResponse, error)
+	UpdateHargaBeli(ctx context.Context, request transactiondomain.UpdateHargaBeliRequest) error
+	InsertTransactionBuy(ctx context.Context, request transactiondomain.InsertTransactionBuyRequestBulk) error
+	FindCustomerCredit(ctx context.Context, month time.Time, sell bool) (*transactiondomain.TransactionCredit, error)
+	FindCustomerReport(ctx context.Context, stakeholderId string, month time.Time) (*transactiondomain.LaporanCustomerSumary, error)
+	FindReconciliation(ctx context.Context, startDate, endDate time.Time) (*transactiondomain.ReconciliationResponse, error)
+	ApplyReconciliation(ctx context.Context, userID string, request transactiondomain.ReconciliationApplyRequest) (*transactiondomain.ReconciliationApplyResponse, error)
 
 	// mobile
-	FindDana(userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error)
-	CreateDana(userID string, request transactiondomain.DanaRequest) error
-	UpdateDana(userID string, request transactiondomain.DanaRequest) error
-	SendDana(userID string, request transactiondomain.DanaTransactionRequest) error
-	ApproveDana(userID string, id string) error
-	RejectDana(userID string, id string) error
-	CancelSendDana(userID string, id string) error
-	FindUserMobile(userID string) ([]transactiondomain.WebUserMobile, error)
+	FindDana(ctx context.Context, userID string, date time.Time) (*transactiondomain.DanaInquiryResponse, error)
+	CreateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error
+	UpdateDana(ctx context.Context, userID string, request transactiondomain.DanaRequest) error
+	SendDana(ctx context.Context, userID string, request transactiondomain.DanaTransactionRequest) error
+	ApproveDana(ctx context.Context, userID string, id string) error
+	RejectDana(ctx context.Context, userID string, id string) error
+	ApproveDanaBulk(ctx context.Context, userID string, ids []string) (*transactiondomain.DanaBulkResponse, error)
+	RejectDanaBulk(ctx context.Context, userID string, ids []string) (*transactiondomain.DanaBulkResponse, error)
+	FindSupervisedTransfers(ctx context.Context, userID string, status string) ([]transactiondomain.SupervisedDanaTransfer, error)
+	CancelSendDana(ctx context.Context, userID string, id string) error
+	FindUserMobile(ctx context.Context, userID string) ([]transactiondomain.WebUserMobile, error)
 
 	// penjualan tunai
-	FindPenjualan(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
-	CreatePenjualan(userID string, request transactiondomain.TrxCreateRequest) error
-	DeletePenjualan(userID string, id string) error
+	FindPenjualan(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
+	CreatePenjualan(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error
+	DeletePenjualan(ctx context.Context, userID string, id string) error
 
 	// belanja
-	FindBelanja(userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
-	CreateBelanja(userID string, request transactiondomain.TrxCreateRequest) error
-	DeleteBelanja(userID string, id string) error
+	FindBelanja(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryResponse, error)
+	CreateBelanja(ctx context.Context, userID string, request transactiondomain.TrxCreateRequest) error
+	DeleteBelanja(ctx context.Context, userID string, id string) error
 
 	// operasional
-	FindOperasional(userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error)
-	CreateOperasional(userID string, request transactiondomain.TrxCreateOperasionalRequest) error
-	DeleteOperasional(userID string, id string) error
+	FindOperasional(ctx context.Context, userID string, date time.Time) ([]transactiondomain.TrxInquiryOperasionalResponse, error)
+	CreateOperasional(ctx context.Context, userID string, request transactiondomain.TrxCreateOperasionalRequest) (*transactiondomain.OperasionalCreateResponse, error)
+	DeleteOperasional(ctx context.Context, userID string, id string) error
 
 	// saldo
-	FindSaldo(userID string, date time.Time) (*transactiondomain.SaldoResponse, error)
-	FindRekapitulasi(date time.Time) (*transactiondomain.RekapitulasiResponse, error)
+	FindSaldo(ctx context.Context, userID string, date time.Time) (*transactiondomain.SaldoResponse, error)
+	FindRekapitulasi(ctx context.Context, date time.Time) (*transactiondomain.RekapitulasiResponse, error)
+	FindRekapRange(ctx context.Context, startDate, endDate time.Time, bucket string) (*transactiondomain.RekapRangeResponse, error)
+	FindRekapBranch(ctx context.Context, startDate, endDate time.Time) (*transactiondomain.RekapBranchResponse, error)
+	ExportRekapRange(ctx context.Context, startDate, endDate time.Time, bucket string) ([]exportutil.Table, error)
+
+	// closing
+	CloseCash(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error)
+	FindClosing(ctx context.Context, userID string, date time.Time) (*transactiondomain.CashClosing, error)
+	FindClosingList(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosing, error)
+	ReopenCash(ctx context.Context, supervisorID string, request transactiondomain.CashClosingReopenRequest) error
+	FindReopenHistory(ctx context.Context, userID string, startDate, endDate time.Time) ([]transactiondomain.CashClosingReopen, error)
+	CheckOpen(ctx context.Context, userID string, date time.Time) error
+
+	// sync
+	SyncPush(ctx context.Context, userID string, request transactiondomain.SyncPushRequest) (*transactiondomain.SyncPushResponse, error)
+	SyncChanges(ctx context.Context, userID string, cursor string, limit int) (*transactiondomain.SyncChangesResponse, error)
 }
 
 type Usecase struct {
@@ -73,22 +101,48 @@ type Usecase struct {
 	supplierRepo    supplierrepo.SupplierRepo
 	customerRepo    customerrepo.CustomerRepo
 	kontrabonRepo   kontrabonrepo.KontrabonRepo
+	attachmentRepo  attachmentrepo.AttachmentRepo
+	expenseRepo     expenserepo.ExpenseRepo
+	configRepo      configRepo
+	branchRepo      branchRepo
+	notifier        notifier
+	storage         storageutil.Storage
 }
 
-func New(transactionRepo transactionrepo.TransactionRepo, sequenceRepo sequencerepo.SequenceRepo, supplierRepo supplierrepo.SupplierRepo, customerRepo customerrepo.CustomerRepo, kontrabonRepo kontrabonrepo.KontrabonRepo) *Usecase {
+type configRepo interface {
+	GetValue(ctx context.Context, key string) string
+}
+
+// branchRepo gives the code prefix of the branch in ctx, codes and their sequences are kept apart per branch
+type branchRepo interface {
+	CodePrefix(ctx context.Context) (string, error)
+}
+
+// notifier tells users about the transfers sent to them and the answers to the ones they sent
+type notifier interface {
+	NotifyTransfer(ctx context.Context, transfer *transactiondomain.DanaTransaction)
+}
+
+func New(transactionRepo transactionrepo.TransactionRepo, sequenceRepo sequencerepo.SequenceRepo, supplierRepo supplierrepo.SupplierRepo, customerRepo customerrepo.CustomerRepo, kontrabonRepo kontrabonrepo.KontrabonRepo, attachmentRepo attachmentrepo.AttachmentRepo, expenseRepo expenserepo.ExpenseRepo, configRepo configRepo, branchRepo branchRepo, notifier notifier, storage storageutil.Storage) *Usecase {
 	uc := &Usecase{
 		transactionRepo: transactionRepo,
 		sequenceRepo:    sequenceRepo,
 		supplierRepo:    supplierRepo,
 		customerRepo:    customerRepo,
 		kontrabonRepo:   kontrabonRepo,
+		attachmentRepo:  attachmentRepo,
+		expenseRepo:     expenseRepo,
+		configRepo:      configRepo,
+		branchRepo:      branchRepo,
+		notifier:        notifier,
+		storage:         storage,
 	}
 
 	return uc
 }
 
-func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction) (string, error) {
-	tx := global.DBCON.Begin()
+func (uc *Usecase) CreateTransaction(ctx context.Context, transaction *transactiondomain.Transaction) (string, error) {
+	tx := tenantutil.DB(ctx).Begin()
 
 	timeNow := time.Now().UTC()
 	if transaction.Date == "" {
@@ -96,43 +150,59 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 	}
 	stakeHolderCode := ""
 	if transaction.TransactionType == transactiondomain.TRANSACTION_TYPE_BUY {
-		supplier, err := uc.supplierRepo.Find(map[string]interface{}{"id": transaction.StakeholderID})
+		supplier, err := uc.supplierRepo.Find(ctx, map[string]interface{}{"id": transaction.StakeholderID})
 		if err != nil {
-			logrus.Error(err.Error())
-			return "", fmt.Errorf("Supplier dengan kode '%s' tidak ditemukan", transaction.StakeholderID)
+			logutil.WithContext(ctx).Error(err.Error())
+			return "", restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_SUPPLIER_NOT_FOUND, transaction.StakeholderID))
 		}
 		stakeHolderCode = supplier[0].Code
 	} else {
-		customer, err := uc.customerRepo.Find(map[string]interface{}{"id": transaction.StakeholderID})
+		customer, err := uc.customerRepo.Find(ctx, map[string]interface{}{"id": transaction.StakeholderID})
 		if err != nil {
-			logrus.Error(err.Error())
-			return "", fmt.Errorf("Customer dengan kode '%s' tidak ditemukan", transaction.StakeholderID)
+			logutil.WithContext(ctx).Error(err.Error())
+			return "", restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_NOT_FOUND, transaction.StakeholderID))
 		}
 		stakeHolderCode = customer[0].Code
 	}
+
+	if err := uc.applyTax(ctx, transaction, i18nutil.ERR_TRANSACTION_CREATE); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
 	transactionID := strings.ReplaceAll(uuid.NewString(), "-", "")
 	dateCode, err := time.Parse(dateutil.DateFormat(), transaction.Date)
 	if err != nil {
-		logrus.Error(err.Error())
-		return "", fmt.Errorf("Terjadi kesalahan saat melakukan transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_PROCESS), err)
 	}
 
-	seqcode := stakeHolderCode + "/" + dateCode.Format("2006")
-	seq := uc.sequenceRepo.NextValTx(seqcode, tx)
+	prefix, err := uc.branchRepo.CodePrefix(ctx)
+	if err != nil {
+		tx.Rollback()
+		return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_PROCESS), err)
+	}
+
+	seqcode := prefix + stakeHolderCode + "/" + dateCode.Format("2006")
+	seq, err := uc.sequenceRepo.NextValTx(ctx, seqcode, tx)
+	if err != nil {
+		tx.Rollback()
+		return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_CREATE), err)
+	}
 	transactionCode := stakeHolderCode + "/" + stringutil.ToRoman(int(dateCode.Month())) + "/" + dateCode.Format("2006")
-	transactionCode = strconv.Itoa(int(seq)) + "/" + transactionCode
+	transactionCode = prefix + strconv.Itoa(int(seq)) + "/" + transactionCode
 
 	transaction.ID = transactionID
 	transaction.Code = transactionCode
 	transaction.Status = transactiondomain.TRANSACTION_PEMBUATAN
 	transaction.CreatedTime = timeNow
 
-	uc.transactionRepo.Create(transaction, tx)
+	uc.transactionRepo.Create(ctx, transaction, tx)
 
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
-		return "", fmt.Errorf("Terjadi kesalahan saat menambahkan transaksi")
+		logutil.WithContext(ctx).Error(tx.Error.Error())
+		return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_CREATE), tx.Error)
 	}
 
 	for _, detail := range transaction.TransactionDetail {
@@ -140,7 +210,7 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 		detail.BuyPrice = 0
 
 		// cek dan update harga beli
-		buyPriceOlds, err := uc.supplierRepo.FindBuyPrice([]queryutil.Param{
+		buyPriceOlds, err := uc.supplierRepo.FindBuyPrice(ctx, []queryutil.Param{
 			{
 				Logic:    "AND",
 				Field:    "s.product_id",
@@ -157,8 +227,8 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 
 		if err != nil {
 			tx.Rollback()
-			logrus.Error(err.Error())
-			return "", fmt.Errorf("Terjadi kesalahan saat menambahkan transaksi")
+			logutil.WithContext(ctx).Error(err.Error())
+			return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_CREATE), err)
 		}
 
 		if buyPriceOlds != nil && len(buyPriceOlds) > 0 {
@@ -169,7 +239,7 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 				} else {
 					tId = nil
 				}
-				uc.supplierRepo.AddBuyPriceTx(supplierdomain.AddPriceRequest{
+				uc.supplierRepo.AddBuyPriceTx(ctx, supplierdomain.AddPriceRequest{
 					ID:            strings.ReplaceAll(uuid.NewString(), "-", ""),
 					Date:          timeNow,
 					UnitId:        detail.UnitID,
@@ -187,7 +257,7 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 			} else {
 				tId = nil
 			}
-			uc.supplierRepo.AddBuyPriceTx(supplierdomain.AddPriceRequest{
+			uc.supplierRepo.AddBuyPriceTx(ctx, supplierdomain.AddPriceRequest{
 				ID:            strings.ReplaceAll(uuid.NewString(), "-", ""),
 				Date:          timeNow,
 				UnitId:        detail.UnitID,
@@ -200,7 +270,7 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 		}
 
 		// cek dan update harga jual
-		sellPriceOlds, err := uc.customerRepo.FindSellPrice([]queryutil.Param{
+		sellPriceOlds, err := uc.customerRepo.FindSellPrice(ctx, []queryutil.Param{
 			{
 				Logic:    "AND",
 				Field:    "s.customer_id",
@@ -223,8 +293,8 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 
 		if err != nil {
 			tx.Rollback()
-			logrus.Error(err.Error())
-			return "", fmt.Errorf("Terjadi kesalahan saat menambahkan transaksi")
+			logutil.WithContext(ctx).Error(err.Error())
+			return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_CREATE), err)
 		}
 
 		if sellPriceOlds != nil && len(sellPriceOlds) > 0 {
@@ -235,7 +305,7 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 				} else {
 					tId = nil
 				}
-				uc.customerRepo.AddSellPriceTx(customerdomain.AddPriceRequest{
+				uc.customerRepo.AddSellPriceTx(ctx, customerdomain.AddPriceRequest{
 					ID:            strings.ReplaceAll(uuid.NewString(), "-", ""),
 					Date:          timeNow,
 					UnitId:        detail.UnitID,
@@ -254,7 +324,7 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 			} else {
 				tId = nil
 			}
-			uc.customerRepo.AddSellPriceTx(customerdomain.AddPriceRequest{
+			uc.customerRepo.AddSellPriceTx(ctx, customerdomain.AddPriceRequest{
 				ID:            strings.ReplaceAll(uuid.NewString(), "-", ""),
 				Date:          timeNow,
 				UnitId:        detail.UnitID,
@@ -271,13 +341,13 @@ func (uc *Usecase) CreateTransaction(transaction *transactiondomain.Transaction)
 	tx.Commit()
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
-		return "", fmt.Errorf("Terjadi kesalahan saat menambahkan transaksi")
+		logutil.WithContext(ctx).Error(tx.Error.Error())
+		return "", restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_CREATE), tx.Error)
 	}
 	return transaction.ID, nil
 }
 
-func (uc *Usecase) ViewTransaction(startDate, endDate, code, stakeholderID, txType, status, productID string) ([]*transactiondomain.Transaction, error) {
+func (uc *Usecase) ViewTransaction(ctx context.Context, startDate, endDate, code, stakeholderID, txType, status, productID string) ([]*transactiondomain.Transaction, error) {
 	var param []queryutil.Param
 	if startDate != "" {
 		param = append(param, queryutil.Param{
@@ -336,10 +406,10 @@ func (uc *Usecase) ViewTransaction(startDate, endDate, code, stakeholderID, txTy
 		})
 	}
 
-	return uc.transactionRepo.Find(param)
+	return uc.transactionRepo.Find(ctx, param)
 }
 
-func (uc *Usecase) ViewSellTransaction(startDate, endDate, code, stakeholderID, txType, status, productID, txId string) ([]*transactiondomain.TransactionStatus, error) {
+func (uc *Usecase) ViewSellTransaction(ctx context.Context, startDate, endDate, code, stakeholderID, txType, status, productID, txId string, list queryutil.ListParam) ([]*transactiondomain.TransactionStatus, int64, error) {
 	var param []queryutil.Param
 	if startDate != "" {
 		param = append(param, queryutil.Param{
@@ -361,8 +431,8 @@ func (uc *Usecase) ViewSellTransaction(startDate, endDate, code, stakeholderID,
 		param = append(param, queryutil.Param{
 			Logic:    "AND",
 			Field:    "t.code",
-			Operator: "=",
-			Value:    code,
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(code),
 		})
 	}
 	if stakeholderID != "" {
@@ -421,10 +491,10 @@ func (uc *Usecase) ViewSellTransaction(startDate, endDate, code, stakeholderID,
 		Value:    "TRUE",
 	})
 
-	return uc.transactionRepo.FindSells(param)
+	return uc.transactionRepo.FindSellsList(ctx, param, list)
 }
 
-func (uc *Usecase) UpdateStatus(transactionID string) error {
+func (uc *Usecase) UpdateStatus(ctx context.Context, transactionID string) error {
 	var param []queryutil.Param
 	param = append(param, queryutil.Param{
 		Logic:    "AND",
@@ -433,15 +503,15 @@ func (uc *Usecase) UpdateStatus(transactionID string) error {
 		Value:    transactionID,
 	})
 
-	transactions, err := uc.transactionRepo.FindSells(param)
+	transactions, err := uc.transactionRepo.FindSells(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), err)
 	}
 
 	if transactions == nil || len(transactions) == 0 {
-		logrus.Error("Transactions is nil or zero")
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error("Transactions is nil or zero")
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), nil)
 	}
 
 	status := transactions[0].Status
@@ -450,15 +520,15 @@ func (uc *Usecase) UpdateStatus(transactionID string) error {
 	} else if transactions[0].Status == transactiondomain.TRANSACTION_KONTRABON {
 		status = transactiondomain.TRANSACTION_DIBAYAR
 	}
-	err = uc.transactionRepo.UpdateStatus(transactionID, status)
+	err = uc.transactionRepo.UpdateStatus(ctx, transactionID, status)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), err)
 	}
 	return nil
 }
 
-func (uc *Usecase) CancelTrx(transactionID string) error {
+func (uc *Usecase) CancelTrx(ctx context.Context, transactionID string) error {
 	var param []queryutil.Param
 	param = append(param, queryutil.Param{
 		Logic:    "AND",
@@ -467,57 +537,114 @@ func (uc *Usecase) CancelTrx(transactionID string) error {
 		Value:    transactionID,
 	})
 
-	transactions, err := uc.transactionRepo.FindSells(param)
+	transactions, err := uc.transactionRepo.FindSells(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), err)
 	}
 
 	if transactions == nil || len(transactions) == 0 {
-		logrus.Error("Transactions is nil or zero")
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error("Transactions is nil or zero")
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), nil)
 	}
 
-	err = uc.transactionRepo.UpdateStatus(transactionID, transactiondomain.TRANSACTION_BATAL)
+	err = uc.transactionRepo.UpdateStatus(ctx, transactionID, transactiondomain.TRANSACTION_BATAL)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), err)
 	}
 	return nil
 }
 
-func (uc *Usecase) UpdateBuyPrice(transactionID, productID string, buyPrice, sellPrice float64, quantity, buyQuantity int64) error {
-	err := uc.transactionRepo.UpdatePrice(transactionID, productID, buyPrice, sellPrice, quantity, buyQuantity)
+// UpdateBuyPrice changes the prices and quantities of a product of the transaction as a new version of its details,
+// the discounts and the tax are worked out again like any other update
+func (uc *Usecase) UpdateBuyPrice(ctx context.Context, transactionID, productID string, buyPrice, sellPrice float64, quantity, buyQuantity int64) error {
+	transactions, err := uc.transactionRepo.FindSells(ctx, []queryutil.Param{
+		{Logic: "AND", Field: "t.id", Operator: "=", Value: transactionID},
+		{Logic: "AND", Field: "td.latest", Operator: "=", Value: true},
+	})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan update status transaksi")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_STATUS_UPDATE), err)
 	}
-	return nil
+	if len(transactions) == 0 {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_NOT_FOUND))
+	}
+
+	current := transactions[0]
+	transaction := &transactiondomain.Transaction{
+		ID:            current.ID,
+		StakeholderID: current.StakeholderID,
+		UserId:        current.UserId,
+		DiscountType:  current.DiscountType,
+		DiscountValue: current.DiscountValue,
+	}
+	found := false
+	for _, detail := range current.TransactionDetail {
+		entry := &transactiondomain.TransactionDetail{
+			UnitID:        detail.UnitID,
+			ProductID:     detail.ProductID,
+			BuyPrice:      detail.BuyPrice,
+			SellPrice:     detail.SellPrice,
+			Quantity:      detail.Quantity,
+			BuyQuantity:   detail.BuyQuantity,
+			DiscountType:  detail.DiscountType,
+			DiscountValue: detail.DiscountValue,
+		}
+		if detail.ProductID == productID {
+			entry.BuyPrice = buyPrice
+			entry.SellPrice = sellPrice
+			entry.Quantity = float64(quantity)
+			entry.BuyQuantity = float64(buyQuantity)
+			found = true
+		}
+		transaction.TransactionDetail = append(transaction.TransactionDetail, entry)
+	}
+	if !found {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_NOT_FOUND))
+	}
+
+	return uc.UpdateTransaction(ctx, transaction)
 }
 
-func (uc *Usecase) UpdateTransaction(transaction *transactiondomain.Transaction) error {
-	tx := global.DBCON.Begin()
+func (uc *Usecase) UpdateTransaction(ctx context.Context, transaction *transactiondomain.Transaction) error {
+	transactions, err := uc.transactionRepo.FindSells(ctx, []queryutil.Param{{Logic: "AND", Field: "t.id", Operator: "=", Value: transaction.ID}})
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_UPDATE), err)
+	}
+	if len(transactions) == 0 {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_NOT_FOUND))
+	}
+
+	// the customer is not changed by an update, the tax is worked out with the one the transaction was made for
+	transaction.StakeholderID = transactions[0].StakeholderID
+	if err := uc.applyTax(ctx, transaction, i18nutil.ERR_TRANSACTION_UPDATE); err != nil {
+		return err
+	}
+
+	tx := tenantutil.DB(ctx).Begin()
 
 	timeNow := time.Now().UTC()
 	transaction.CreatedTime = timeNow
 
-	uc.transactionRepo.UpdateTransaction(transaction, tx)
+	uc.transactionRepo.UpdateTransaction(ctx, transaction, tx)
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
-		return fmt.Errorf("Terjadi kesalahan saat memperbarui transaksi")
+		logutil.WithContext(ctx).Error(tx.Error.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_UPDATE), tx.Error)
 	}
 
 	tx.Commit()
 	if tx.Error != nil {
 		tx.Rollback()
-		logrus.Error(tx.Error.Error())
-		return fmt.Errorf("Terjadi kesalahan saat memperbarui transaksi")
+		logutil.WithContext(ctx).Error(tx.Error.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TRANSACTION_UPDATE), tx.Error)
 	}
 	return nil
 }
 
-func (uc *Usecase) FindReport(startDate, endDate, code, stakeholderID, txType, status, productID, txId string) ([]*transactiondomain.ReportDate, error) {
+func (uc *Usecase) FindReport(ctx context.Context, startDate, endDate, code, stakeholderID, txType, status, productID, txId string) ([]*transactiondomain.ReportDate, error) {
 	var param []queryutil.Param
 	if startDate != "" {
 		param = append(param, queryutil.Param{
@@ -614,19 +741,19 @@ func (uc *Usecase) FindReport(startDate, endDate, code, stakeholderID, txType, s
 		Value:    "TRUE",
 	})
 
-	return uc.transactionRepo.FindReport(param)
+	return uc.transactionRepo.FindReport(ctx, param)
 }
 
-func (uc *Usecase) UpdateHargaBeli(request transactiondomain.UpdateHargaBeliRequest) error {
-	err := uc.transactionRepo.UpdateHargaBeli(request.TransactionDetailID, request.BuyPrice, request.WebUserID)
+func (uc *Usecase) UpdateHargaBeli(ctx context.Context, request transactiondomain.UpdateHargaBeliRequest) error {
+	err := uc.transactionRepo.UpdateHargaBeli(ctx, request.TransactionDetailID, request.BuyPrice, request.WebUserID)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat memperbarui harga beli")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BUY_PRICE_UPDATE), err)
 	}
 	return nil
 }
 
-func (uc *Usecase) InsertTransactionBuy(request transactiondomain.InsertTransactionBuyRequestBulk) error {
+func (uc *Usecase) InsertTransactionBuy(ctx context.Context, request transactiondomain.InsertTransactionBuyRequestBulk) error {
 	now := time.Now()
 	var entities []transactiondomain.TransactionBuy
 	for _, detail := range request.Details {
@@ -644,19 +771,19 @@ func (uc *Usecase) InsertTransactionBuy(request transactiondomain.InsertTransact
 		entities = append(entities, entity)
 	}
 
-	err := uc.transactionRepo.InsertTransactionBuy(request.TransactionID, entities)
+	err := uc.transactionRepo.InsertTransactionBuy(ctx, request.TransactionID, entities)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat menambahkan harga beli")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_BUY_PRICE_CREATE), err)
 	}
 	return nil
 }
 
-func (uc *Usecase) FindTransactionBuyStatus() ([]transactiondomain.TransactionBuyStatus, error) {
-	return uc.transactionRepo.FindTransactionBuyStatus()
+func (uc *Usecase) FindTransactionBuyStatus(ctx context.Context) ([]transactiondomain.TransactionBuyStatus, error) {
+	return uc.transactionRepo.FindTransactionBuyStatus(ctx)
 }
 
-func (uc *Usecase) FindCustomerCredit(month time.Time, sell bool) (*transactiondomain.TransactionCredit, error) {
+func (uc *Usecase) FindCustomerCredit(ctx context.Context, month time.Time, sell bool) (*transactiondomain.TransactionCredit, error) {
 	transactionCredit := &transactiondomain.TransactionCredit{}
 
 	status := []string{"BATAL"}
@@ -693,10 +820,10 @@ func (uc *Usecase) FindCustomerCredit(month time.Time, sell bool) (*transactiond
 		Value:    true,
 	})
 
-	lastCreditMap, err := uc.transactionRepo.FindLastCredit(param)
+	lastCreditMap, err := uc.transactionRepo.FindLastCredit(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat mengambil data piutang")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CREDIT_FETCH), err)
 	}
 
 	var param2 []queryutil.Param
@@ -728,9 +855,9 @@ func (uc *Usecase) FindCustomerCredit(month time.Time, sell bool) (*transactiond
 		Value:    true,
 	})
 
-	lastCreditPerMonthMap, err := uc.transactionRepo.FindLastCreditPerMonth(param2)
+	lastCreditPerMonthMap, err := uc.transactionRepo.FindLastCreditPerMonth(ctx, param2)
 
-	customers, err := uc.customerRepo.Find(map[string]interface{}{"active": true})
+	customers, err := uc.customerRepo.Find(ctx, map[string]interface{}{"active": true})
 
 	transactions := make([]transactiondomain.TransactionCreditDate, 0)
 	for _, v := range customers {
@@ -744,21 +871,21 @@ func (uc *Usecase) FindCustomerCredit(month time.Time, sell bool) (*transactiond
 		transactions = append(transactions, t)
 	}
 
-	transactionCredit.PreviousMonth = dateutil.MonthToString(int(month.AddDate(0, -1, 0).Month()))
+	transactionCredit.PreviousMonth = i18nutil.MonthName(ctx, int(month.AddDate(0, -1, 0).Month()))
 	transactionCredit.Days = dateutil.DaysIn(month.Month(), month.Year())
 	transactionCredit.Transactions = transactions
 
 	return transactionCredit, nil
 }
 
-func (uc *Usecase) FindCustomerReport(stakeholderId string, month time.Time) (*transactiondomain.LaporanCustomerSumary, error) {
+func (uc *Usecase) FindCustomerReport(ctx context.Context, stakeholderId string, month time.Time) (*transactiondomain.LaporanCustomerSumary, error) {
 	startDate := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
 	endDate := time.Date(month.Year(), month.Month(), dateutil.DaysIn(month.Month(), month.Year()), 0, 0, 0, 0, time.UTC).Format("2006-01-02")
 
-	laporanCustomer, totalOrder, err := uc.transactionRepo.FindCustomerReport(stakeholderId, month)
+	laporanCustomer, totalOrder, err := uc.transactionRepo.FindCustomerReport(ctx, stakeholderId, month)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat mengambil data laporan per customer")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_CUSTOMER_REPORT_FETCH), err)
 	}
 
 	var param []queryutil.Param
@@ -781,10 +908,10 @@ func (uc *Usecase) FindCustomerReport(stakeholderId string, month time.Time) (*t
 		Value:    stakeholderId,
 	})
 
-	trx, err := uc.kontrabonRepo.Find(param)
+	trx, err := uc.kontrabonRepo.Find(ctx, param)
 	if err != nil {
-		logrus.Error(err.Error())
-		return nil, fmt.Errorf("Terjadi kesalahan saat melakukan pencarian kontrabon")
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_KONTRABON_FIND), err)
 	}
 
 	result := &transactiondomain.LaporanCustomerSumary{
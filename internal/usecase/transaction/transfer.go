@@ -0,0 +1,184 @@
+package transactionusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	transactiondomain "dromatech/pos-backend/internal/domain/transaction"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	tenantutil "dromatech/pos-backend/internal/util/tenant"
+	"strconv"
+	"time"
+)
+
+// DEFAULT_TRANSFER_EXPIRE_HOUR is how long a transfer stays pending when DANA_TRANSFER_EXPIRE_HOUR is not set
+const DEFAULT_TRANSFER_EXPIRE_HOUR = 48
+
+// DEFAULT_TRANSFER_SWEEP_MINUTE is how often the sweeper runs when DANA_TRANSFER_SWEEP_MINUTE is not set
+const DEFAULT_TRANSFER_SWEEP_MINUTE = 10
+
+// MAX_DANA_BULK is the most transfers a bulk approve or reject decides
+const MAX_DANA_BULK = 100
+
+// decideDana approves or rejects a pending transfer for its receiver, or for a supervisor who manages both the sender and the receiver
+func (u *Usecase) decideDana(ctx context.Context, userID string, id string, status transactiondomain.DanaStatus) error {
+	failed, notAllowed, notPending := i18nutil.ERR_DANA_UPDATE, i18nutil.ERR_DANA_APPROVE_NOT_ALLOWED, i18nutil.ERR_DANA_APPROVE_NOT_PENDING
+	if status == transactiondomain.DanaStatusRejected {
+		failed, notAllowed, notPending = i18nutil.ERR_DANA_REJECT, i18nutil.ERR_DANA_REJECT_NOT_ALLOWED, i18nutil.ERR_DANA_REJECT_NOT_PENDING
+	}
+
+	transfer, err := u.transactionRepo.FindDanaTransfer(ctx, id)
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, failed), err)
+	}
+	if transfer == nil {
+		return restutil.ErrNotFound(i18nutil.T(ctx, i18nutil.ERR_DANA_TRANSFER_NOT_FOUND))
+	}
+
+	if transfer.Receiver != userID {
+		supervisor, err := u.transactionRepo.IsSupervisorOf(ctx, userID, transfer.Sender, transfer.Receiver)
+		if err != nil {
+			return restutil.ErrInternal(i18nutil.T(ctx, failed), err)
+		}
+		if !supervisor {
+			return restutil.ErrForbidden(i18nutil.T(ctx, notAllowed))
+		}
+	}
+
+	// the sweeper may not have run yet on a transfer past the window
+	if transfer.Status == transactiondomain.DanaStatusExpired ||
+		(transfer.Status == transactiondomain.DanaStatusPending && !transfer.InsertedTime.After(u.transferExpireBefore(ctx))) {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_DANA_TRANSFER_EXPIRED))
+	}
+	if transfer.Status != transactiondomain.DanaStatusPending {
+		return restutil.ErrConflict(i18nutil.T(ctx, notPending))
+	}
+
+	if err := u.checkTransferOpen(ctx, transfer); err != nil {
+		return err
+	}
+
+	var decided bool
+	if status == transactiondomain.DanaStatusApproved {
+		decided, err = u.transactionRepo.ApproveDana(ctx, id, userID)
+	} else {
+		decided, err = u.transactionRepo.RejectDana(ctx, id, userID)
+	}
+	if err != nil {
+		return restutil.ErrInternal(i18nutil.T(ctx, failed), err)
+	}
+	if !decided {
+		return restutil.ErrConflict(i18nutil.T(ctx, notPending))
+	}
+	u.notifyTransfer(ctx, id)
+
+	return nil
+}
+
+func (u *Usecase) ApproveDanaBulk(ctx context.Context, userID string, ids []string) (*transactiondomain.DanaBulkResponse, error) {
+	return u.decideDanaBulk(ctx, userID, ids, transactiondomain.DanaStatusApproved)
+}
+
+func (u *Usecase) RejectDanaBulk(ctx context.Context, userID string, ids []string) (*transactiondomain.DanaBulkResponse, error) {
+	return u.decideDanaBulk(ctx, userID, ids, transactiondomain.DanaStatusRejected)
+}
+
+// decideDanaBulk decides each transfer on its own, a failed one is reported in its result and does not stop the others
+func (u *Usecase) decideDanaBulk(ctx context.Context, userID string, ids []string, status transactiondomain.DanaStatus) (*transactiondomain.DanaBulkResponse, error) {
+	if len(ids) == 0 {
+		return nil, restutil.ErrRequired("ids", i18nutil.T(ctx, i18nutil.ERR_DANA_BULK_EMPTY))
+	}
+	if len(ids) > MAX_DANA_BULK {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DANA_BULK_TOO_MANY, MAX_DANA_BULK))
+	}
+
+	response := &transactiondomain.DanaBulkResponse{Results: []transactiondomain.DanaBulkResult{}}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := transactiondomain.DanaBulkResult{ID: id, Success: true}
+		if err := u.decideDana(ctx, userID, id, status); err != nil {
+			result.Success = false
+			result.Message = err.Error()
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// FindSupervisedTransfers lists the transfers between the users the supervisor manages
+func (u *Usecase) FindSupervisedTransfers(ctx context.Context, userID string, status string) ([]transactiondomain.SupervisedDanaTransfer, error) {
+	switch transactiondomain.DanaStatus(status) {
+	case "", transactiondomain.DanaStatusPending, transactiondomain.DanaStatusApproved, transactiondomain.DanaStatusRejected,
+		transactiondomain.DanaStatusCanceled, transactiondomain.DanaStatusExpired:
+	default:
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_DANA_STATUS_INVALID))
+	}
+
+	transfers, err := u.transactionRepo.FindSupervisedTransfers(ctx, userID, transactiondomain.DanaStatus(status))
+	if err != nil {
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return transfers, nil
+}
+
+// ExpireDanaTransfers expires the transfers pending longer than DANA_TRANSFER_EXPIRE_HOUR and tells their senders
+func (u *Usecase) ExpireDanaTransfers(ctx context.Context) (int, error) {
+	ids, err := u.transactionRepo.ExpireDanaTransfers(ctx, u.transferExpireBefore(ctx))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		u.notifyTransfer(ctx, id)
+	}
+	return len(ids), nil
+}
+
+// tenantLister gives a context per tenant, the sweeper expires the transfers of every tenant
+type tenantLister interface {
+	Contexts(ctx context.Context) []context.Context
+}
+
+// SweepExpiredTransfers runs ExpireDanaTransfers for every tenant every DANA_TRANSFER_SWEEP_MINUTE until the context is done
+func (u *Usecase) SweepExpiredTransfers(ctx context.Context, tenants tenantLister) {
+	for {
+		for _, tenantCtx := range tenants.Contexts(ctx) {
+			expired, err := u.ExpireDanaTransfers(tenantCtx)
+			if err != nil {
+				logutil.WithContext(tenantCtx).Error(err.Error())
+			} else if expired > 0 {
+				logutil.WithContext(tenantCtx).Infof("expired %d dana transfers of tenant %s", expired, tenantutil.Code(tenantCtx))
+			}
+		}
+
+		timer := time.NewTimer(time.Duration(u.configInt(ctx, configdomain.DANA_TRANSFER_SWEEP_MINUTE, DEFAULT_TRANSFER_SWEEP_MINUTE)) * time.Minute)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// transferExpireBefore is the inserted time a pending transfer expires at or before
+func (u *Usecase) transferExpireBefore(ctx context.Context) time.Time {
+	hours := u.configInt(ctx, configdomain.DANA_TRANSFER_EXPIRE_HOUR, DEFAULT_TRANSFER_EXPIRE_HOUR)
+	return time.Now().Add(-time.Duration(hours) * time.Hour)
+}
+
+func (u *Usecase) configInt(ctx context.Context, key string, defaultValue int) int {
+	value, err := strconv.Atoi(u.configRepo.GetValue(ctx, key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
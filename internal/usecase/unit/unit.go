@@ -1,17 +1,20 @@
 package unitusecase
 
 import (
+	"context"
 	unitdomain "dromatech/pos-backend/internal/domain/unit"
-	"fmt"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 	"strings"
 )
 
 type UnitUsecase interface {
-	Find(id, code string, active *bool) ([]*unitdomain.Unit, error)
-	Create(code, description string) error
-	Edit(id, code, description string, active *bool) error
+	Find(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*unitdomain.Unit, int64, error)
+	Create(ctx context.Context, code, description string) error
+	Edit(ctx context.Context, id, code, description string, active *bool) error
 }
 
 type Usecase struct {
@@ -19,9 +22,10 @@ type Usecase struct {
 }
 
 type unitRepo interface {
-	Find(params map[string]interface{}) ([]*unitdomain.Unit, error)
-	Create(product *unitdomain.Unit) error
-	Edit(product *unitdomain.Unit) error
+	Find(ctx context.Context, params map[string]interface{}) ([]*unitdomain.Unit, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*unitdomain.Unit, int64, error)
+	Create(ctx context.Context, product *unitdomain.Unit) error
+	Edit(ctx context.Context, product *unitdomain.Unit) error
 }
 
 func New(unitRepo unitRepo) *Usecase {
@@ -32,29 +36,44 @@ func New(unitRepo unitRepo) *Usecase {
 	return uc
 }
 
-func (uc *Usecase) Find(id, code string, active *bool) ([]*unitdomain.Unit, error) {
-	param := make(map[string]interface{})
+func (uc *Usecase) Find(ctx context.Context, id, code string, active *bool, list queryutil.ListParam) ([]*unitdomain.Unit, int64, error) {
+	var param []queryutil.Param
 	if id != "" {
-		param["id"] = id
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "id",
+			Operator: "=",
+			Value:    id,
+		})
 	}
 	if code != "" {
-		param["code"] = code
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "code",
+			Operator: "ILIKE",
+			Value:    queryutil.Contains(code),
+		})
 	}
 	if active != nil {
-		param["active"] = active
+		param = append(param, queryutil.Param{
+			Logic:    "AND",
+			Field:    "active",
+			Operator: "=",
+			Value:    *active,
+		})
 	}
-	return uc.unitRepo.Find(param)
+	return uc.unitRepo.FindList(ctx, param, list)
 }
 
-func (uc *Usecase) Create(code, description string) error {
-	entities, err := uc.unitRepo.Find(map[string]interface{}{"code": code})
+func (uc *Usecase) Create(ctx context.Context, code, description string) error {
+	entities, err := uc.unitRepo.Find(ctx, map[string]interface{}{"code": code})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan penambahan data satuan")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UNIT_CREATE), err)
 	}
 
 	if entities != nil || len(entities) > 0 {
-		return fmt.Errorf("Satuan dengan kode %s sudah terdaftar", code)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_UNIT_CODE_EXISTS, code))
 	}
 
 	id := strings.ReplaceAll(uuid.NewString(), "-", "")
@@ -66,34 +85,34 @@ func (uc *Usecase) Create(code, description string) error {
 		Active:      true,
 	}
 
-	err = uc.unitRepo.Create(product)
+	err = uc.unitRepo.Create(ctx, product)
 
 	return nil
 }
 
-func (uc *Usecase) Edit(id, code, description string, active *bool) error {
-	entities, err := uc.unitRepo.Find(map[string]interface{}{"id": id})
+func (uc *Usecase) Edit(ctx context.Context, id, code, description string, active *bool) error {
+	entities, err := uc.unitRepo.Find(ctx, map[string]interface{}{"id": id})
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Ada kesalahan saat melakukan pembaruan data satuan")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UNIT_UPDATE), err)
 	}
 
 	if len(entities) != 1 {
-		logrus.Errorf("Product with id %s more than 1", id)
-		return fmt.Errorf("Ada kesalahan saat melakukan pembaruan data satuan")
+		logutil.WithContext(ctx).Errorf("Product with id %s more than 1", id)
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UNIT_UPDATE), nil)
 	}
 
 	entity := entities[0]
 
 	if code != entity.Code {
-		products, err := uc.unitRepo.Find(map[string]interface{}{"code": code})
+		products, err := uc.unitRepo.Find(ctx, map[string]interface{}{"code": code})
 		if err != nil {
-			logrus.Error(err.Error())
-			return fmt.Errorf("Ada kesalahan saat melakukan pembaruan data satuan")
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UNIT_UPDATE), err)
 		}
 
 		if products != nil || len(products) > 0 {
-			return fmt.Errorf("Produk dengan kode %s sudah terdaftar", code)
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_PRODUCT_CODE_EXISTS, code))
 		}
 	}
 
@@ -107,10 +126,10 @@ func (uc *Usecase) Edit(id, code, description string, active *bool) error {
 		entity.Active = *active
 	}
 
-	err = uc.unitRepo.Edit(entity)
+	err = uc.unitRepo.Edit(ctx, entity)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan pembaruan data satuan")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_UNIT_UPDATE), err)
 	}
 
 	return nil
@@ -0,0 +1,152 @@
+package webuserusecase
+
+import (
+	"context"
+	configdomain "dromatech/pos-backend/internal/domain/config"
+	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	totputil "dromatech/pos-backend/internal/util/totp"
+)
+
+const DEFAULT_TOTP_ISSUER = "POS"
+
+// SetupTotp creates a new secret for the user to scan, two-factor authentication stays off until EnableTotp confirms a code
+func (uc *Usecase) SetupTotp(ctx context.Context, userId string) (*webuserdomain.TotpSetup, error) {
+	webuser, err := uc.findTotpUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if webuser.TotpEnabled {
+		return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TOTP_ALREADY_ENABLED))
+	}
+
+	issuer := uc.configRepo.GetValue(ctx, configdomain.TOTP_ISSUER)
+	if issuer == "" {
+		issuer = DEFAULT_TOTP_ISSUER
+	}
+	secret, uri, qrCode, err := totputil.Generate(issuer, webuser.Username)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP), err)
+	}
+
+	err = uc.webuserrepo.SaveTotpSecret(ctx, userId, secret)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP), err)
+	}
+
+	return &webuserdomain.TotpSetup{Secret: secret, URI: uri, QRCode: qrCode}, nil
+}
+
+// EnableTotp turns on two-factor authentication once the user proves the authenticator app works
+func (uc *Usecase) EnableTotp(ctx context.Context, userId, code string) (*webuserdomain.RecoveryCodes, error) {
+	webuser, err := uc.findTotpUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if webuser.TotpEnabled {
+		return nil, restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_TOTP_ALREADY_ENABLED))
+	}
+	if webuser.TotpSecret == "" {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP_REQUIRED))
+	}
+	if !totputil.Validate(code, webuser.TotpSecret) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TOTP_INVALID))
+	}
+
+	codes, hashes, err := totputil.NewRecoveryCodes()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP), err)
+	}
+
+	err = uc.webuserrepo.EnableTotp(ctx, userId, hashes)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP), err)
+	}
+
+	uc.sessionRefresher.RefreshUser(ctx, userId)
+	return &webuserdomain.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTotp turns off two-factor authentication, sessions of a role requiring it are held until the user enrols again
+func (uc *Usecase) DisableTotp(ctx context.Context, userId, code string) error {
+	webuser, err := uc.findEnabledTotpUser(ctx, userId, code)
+	if err != nil {
+		return err
+	}
+
+	err = uc.webuserrepo.DisableTotp(ctx, webuser.ID)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+
+	uc.sessionRefresher.RefreshUser(ctx, userId)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user, the old ones stop working
+func (uc *Usecase) RegenerateRecoveryCodes(ctx context.Context, userId, code string) (*webuserdomain.RecoveryCodes, error) {
+	webuser, err := uc.findEnabledTotpUser(ctx, userId, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := totputil.NewRecoveryCodes()
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP), err)
+	}
+
+	err = uc.webuserrepo.ReplaceRecoveryCodes(ctx, webuser.ID, hashes)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_TOTP_SETUP), err)
+	}
+
+	return &webuserdomain.RecoveryCodes{Codes: codes}, nil
+}
+
+// ResetTotp lets an admin turn off two-factor authentication of a user who lost the authenticator and recovery codes
+func (uc *Usecase) ResetTotp(ctx context.Context, userId string) error {
+	if _, err := uc.findTotpUser(ctx, userId); err != nil {
+		return err
+	}
+
+	err := uc.webuserrepo.DisableTotp(ctx, userId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+
+	uc.sessionRefresher.RefreshUser(ctx, userId)
+	return nil
+}
+
+func (uc *Usecase) findTotpUser(ctx context.Context, userId string) (*webuserdomain.WebUser, error) {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+	return webuser, nil
+}
+
+// findEnabledTotpUser returns the user when two-factor authentication is on and the code is current
+func (uc *Usecase) findEnabledTotpUser(ctx context.Context, userId, code string) (*webuserdomain.WebUser, error) {
+	webuser, err := uc.findTotpUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if !webuser.TotpEnabled {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TOTP_NOT_ENABLED))
+	}
+	if !totputil.Validate(code, webuser.TotpSecret) {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_TOTP_INVALID))
+	}
+	return webuser, nil
+}
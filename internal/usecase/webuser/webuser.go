@@ -1,58 +1,106 @@
 package webuserusecase
 
 import (
-	"crypto/sha256"
+	"context"
+	branchdomain "dromatech/pos-backend/internal/domain/branch"
+	configdomain "dromatech/pos-backend/internal/domain/config"
 	webuserdomain "dromatech/pos-backend/internal/domain/webuser"
-	"encoding/base64"
-	"fmt"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	passwordutil "dromatech/pos-backend/internal/util/password"
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 )
 
 type SessionUsecase interface {
-	EditUser(userId, name, username, role, status string) error
-	ChangePassword(userId, password1, password2 string) error
-	RegisterUser(creatorId, name, username, password, roleId string) error
-	FindAllUser() ([]*webuserdomain.WebUser, error)
-	ForceChangePassword(userId, password string) error
-	ChangeStatus(userId string, status bool)
+	EditUser(ctx context.Context, userId, name, username, role, status string) error
+	ChangePassword(ctx context.Context, userId, password1, password2 string) error
+	RegisterUser(ctx context.Context, creatorId, name, username, password, roleId string) error
+	RegisterServiceAccount(ctx context.Context, creatorId, name, username, roleId string) error
+	FindAllUser(ctx context.Context, list queryutil.ListParam) ([]*webuserdomain.WebUser, int64, error)
+	ForceChangePassword(ctx context.Context, userId, password string) error
+	ChangeStatus(ctx context.Context, userId string, status bool)
+	ChangeLanguage(ctx context.Context, userId string, language string) error
+	Unlock(ctx context.Context, userId string) error
+	SetupTotp(ctx context.Context, userId string) (*webuserdomain.TotpSetup, error)
+	EnableTotp(ctx context.Context, userId, code string) (*webuserdomain.RecoveryCodes, error)
+	DisableTotp(ctx context.Context, userId, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId, code string) (*webuserdomain.RecoveryCodes, error)
+	ResetTotp(ctx context.Context, userId string) error
+	FindStakeholders(ctx context.Context, userId string) (*webuserdomain.Stakeholders, error)
+	AssignStakeholders(ctx context.Context, userId string, customerIds, supplierIds []string) error
+	FindManagedUsers(ctx context.Context, supervisorId string) ([]*webuserdomain.ManagedUser, error)
+	AssignManagedUsers(ctx context.Context, supervisorId string, userIds []string) error
+	FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error)
+	AssignBranches(ctx context.Context, userId string, branchIds []string) error
 }
 
 type Usecase struct {
-	webuserrepo webUserRepo
+	webuserrepo      webUserRepo
+	configRepo       configRepo
+	sessionRefresher sessionRefresher
+}
+
+const DEFAULT_PASSWORD_MIN_LENGTH = 8
+
+type configRepo interface {
+	GetValue(ctx context.Context, key string) string
+}
+
+// sessionRefresher applies user changes to the sessions the user is logged in with and to the login throttle
+type sessionRefresher interface {
+	RefreshUser(ctx context.Context, userID string)
+	ClearLoginAttempts(ctx context.Context, username string)
 }
 
 type webUserRepo interface {
-	FindAll() ([]*webuserdomain.WebUser, error)
-	Find(id string) *webuserdomain.WebUser
-	FindByUsername(username string) *webuserdomain.WebUser
-	EditUser(webUser *webuserdomain.WebUser) error
-	ChangePassword(userId string, newPassword string)
-	RegisterUser(webUser *webuserdomain.WebUser)
-	ChangeStatus(userId string, active bool)
+	FindAll(ctx context.Context) ([]*webuserdomain.WebUser, error)
+	FindList(ctx context.Context, params []queryutil.Param, list queryutil.ListParam) ([]*webuserdomain.WebUser, int64, error)
+	Find(ctx context.Context, id string) *webuserdomain.WebUser
+	FindByUsername(ctx context.Context, username string) *webuserdomain.WebUser
+	EditUser(ctx context.Context, webUser *webuserdomain.WebUser) error
+	ChangePassword(ctx context.Context, userId string, passwordHash string, mustChange bool, keepHistory int) error
+	FindPasswordHistory(ctx context.Context, userId string, limit int) ([]*webuserdomain.PasswordHistory, error)
+	RegisterUser(ctx context.Context, webUser *webuserdomain.WebUser)
+	ChangeStatus(ctx context.Context, userId string, active bool)
+	ChangeLanguage(ctx context.Context, userId string, language string) error
+	ResetLoginFailure(ctx context.Context, userId string) error
+	SaveTotpSecret(ctx context.Context, userId string, secret string) error
+	EnableTotp(ctx context.Context, userId string, codeHashes []string) error
+	DisableTotp(ctx context.Context, userId string) error
+	ReplaceRecoveryCodes(ctx context.Context, userId string, codeHashes []string) error
+	FindStakeholders(ctx context.Context, userId string) (*webuserdomain.Stakeholders, error)
+	ReplaceStakeholders(ctx context.Context, userId string, customerIds, supplierIds []string) error
+	FindManagedUsers(ctx context.Context, supervisorId string) ([]*webuserdomain.ManagedUser, error)
+	ReplaceManagedUsers(ctx context.Context, supervisorId string, userIds []string) error
+	FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error)
+	ReplaceBranches(ctx context.Context, userId string, branchIds []string) error
 }
 
-func New(webuserrepo webUserRepo) *Usecase {
+func New(webuserrepo webUserRepo, configRepo configRepo, sessionRefresher sessionRefresher) *Usecase {
 	uc := &Usecase{
-		webuserrepo: webuserrepo,
+		webuserrepo:      webuserrepo,
+		configRepo:       configRepo,
+		sessionRefresher: sessionRefresher,
 	}
 
 	return uc
 }
 
-func (uc *Usecase) EditUser(userId, name, username, role, status string) error {
-	webuser := uc.webuserrepo.Find(userId)
+func (uc *Usecase) EditUser(ctx context.Context, userId, name, username, role, status string) error {
+	webuser := uc.webuserrepo.Find(ctx, userId)
 	if name != "" {
 		webuser.Name = name
 	}
 	if username != "" {
-		u := uc.webuserrepo.FindByUsername(username)
+		u := uc.webuserrepo.FindByUsername(ctx, username)
 		if u != nil && u.ID != userId {
-			return fmt.Errorf("User dengan username '%s' sudah ada", username)
+			return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_USERNAME_EXISTS, username))
 		}
 		webuser.Username = username
 	}
@@ -62,79 +110,304 @@ func (uc *Usecase) EditUser(userId, name, username, role, status string) error {
 	if status != "" {
 		ac, err := strconv.ParseBool(status)
 		if err != nil {
-			return fmt.Errorf("Status tidak valid")
+			return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_STATUS_INVALID))
 		}
 		webuser.Active = ac
 	}
 
-	err := uc.webuserrepo.EditUser(webuser)
+	err := uc.webuserrepo.EditUser(ctx, webuser)
 	if err != nil {
-		logrus.Error(err.Error())
-		return fmt.Errorf("Terjadi kesalahan saat melakukan perubahan data user")
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
 	}
+
+	uc.sessionRefresher.RefreshUser(ctx, userId)
 	return nil
 }
 
-func (uc *Usecase) ChangePassword(userId, password1, password2 string) error {
-	webuser := uc.webuserrepo.Find(userId)
-	hasher := sha256.New()
-	hasher.Write([]byte(password1 + webuser.PasswordSalt))
-	passwordHash := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+func (uc *Usecase) ChangePassword(ctx context.Context, userId, password1, password2 string) error {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
 
-	if passwordHash != webuser.PasswordHash {
-		return fmt.Errorf("Kata sandi lama tidak sesuai")
+	if passwordutil.Hash(password1, webuser.PasswordSalt) != webuser.PasswordHash {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_OLD_PASSWORD_MISMATCH))
 	}
 
-	hasher = sha256.New()
-	hasher.Write([]byte(password2 + webuser.PasswordSalt))
-	passwordHash = base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+	return uc.changePassword(ctx, webuser, password2, false)
+}
 
-	uc.webuserrepo.ChangePassword(webuser.ID, passwordHash)
-	return nil
+// ForceChangePassword lets an admin set the password, the user has to change it on the next login
+func (uc *Usecase) ForceChangePassword(ctx context.Context, userId, password string) error {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	return uc.changePassword(ctx, webuser, password, true)
 }
 
-func (uc *Usecase) ForceChangePassword(userId, password string) error {
-	webuser := uc.webuserrepo.Find(userId)
-	hasher := sha256.New()
-	hasher.Write([]byte(password + webuser.PasswordSalt))
-	passwordHash := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+func (uc *Usecase) changePassword(ctx context.Context, webuser *webuserdomain.WebUser, password string, mustChange bool) error {
+	policy := uc.passwordPolicy(ctx)
+	if err := uc.checkPassword(ctx, policy, password); err != nil {
+		return err
+	}
 
-	uc.webuserrepo.ChangePassword(webuser.ID, passwordHash)
+	if policy.HistoryCount > 0 {
+		histories, err := uc.webuserrepo.FindPasswordHistory(ctx, webuser.ID, policy.HistoryCount)
+		if err != nil {
+			logutil.WithContext(ctx).Error(err.Error())
+			return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+		}
+		// the current password may predate the history
+		histories = append(histories, &webuserdomain.PasswordHistory{PasswordHash: webuser.PasswordHash, PasswordSalt: webuser.PasswordSalt})
+		for _, history := range histories {
+			if passwordutil.Hash(password, history.PasswordSalt) == history.PasswordHash {
+				return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_PASSWORD_REUSED, policy.HistoryCount))
+			}
+		}
+	}
+
+	err := uc.webuserrepo.ChangePassword(ctx, webuser.ID, passwordutil.Hash(password, webuser.PasswordSalt), mustChange, policy.HistoryCount)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+
+	uc.sessionRefresher.RefreshUser(ctx, webuser.ID)
 	return nil
 }
 
-func (uc *Usecase) RegisterUser(creatorId, name, username, password, roleId string) error {
-	webuser := uc.webuserrepo.FindByUsername(username)
+// checkPassword returns the rule of the policy the password violates as a bad request
+func (uc *Usecase) checkPassword(ctx context.Context, policy passwordutil.Policy, password string) error {
+	switch policy.Check(password) {
+	case passwordutil.VIOLATION_LENGTH:
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_PASSWORD_TOO_SHORT, policy.MinLength))
+	case passwordutil.VIOLATION_MIXED_CASE:
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_PASSWORD_MIXED_CASE))
+	case passwordutil.VIOLATION_DIGIT:
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_PASSWORD_DIGIT))
+	case passwordutil.VIOLATION_SYMBOL:
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_PASSWORD_SYMBOL))
+	}
+	return nil
+}
+
+func (uc *Usecase) passwordPolicy(ctx context.Context) passwordutil.Policy {
+	return passwordutil.Policy{
+		MinLength:        uc.configInt(ctx, configdomain.PASSWORD_MIN_LENGTH, DEFAULT_PASSWORD_MIN_LENGTH),
+		RequireMixedCase: uc.configBool(ctx, configdomain.PASSWORD_REQUIRE_MIXED_CASE),
+		RequireDigit:     uc.configBool(ctx, configdomain.PASSWORD_REQUIRE_DIGIT),
+		RequireSymbol:    uc.configBool(ctx, configdomain.PASSWORD_REQUIRE_SYMBOL),
+		HistoryCount:     uc.configInt(ctx, configdomain.PASSWORD_HISTORY_COUNT, 0),
+		ExpiryDay:        uc.configInt(ctx, configdomain.PASSWORD_EXPIRY_DAY, 0),
+	}
+}
+
+func (uc *Usecase) configInt(ctx context.Context, key string, defaultValue int) int {
+	value, err := strconv.Atoi(uc.configRepo.GetValue(ctx, key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func (uc *Usecase) configBool(ctx context.Context, key string) bool {
+	value, _ := strconv.ParseBool(uc.configRepo.GetValue(ctx, key))
+	return value
+}
+
+func (uc *Usecase) RegisterUser(ctx context.Context, creatorId, name, username, password, roleId string) error {
+	webuser := uc.webuserrepo.FindByUsername(ctx, username)
 	if webuser != nil {
-		return fmt.Errorf("Pengguna dengan username '%s' sudah ada", username)
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_USERNAME_EXISTS, username))
 	}
 
-	passwordSalt := strings.ReplaceAll(uuid.NewString(), "-", "")
-	hasher := sha256.New()
-	hasher.Write([]byte(password + passwordSalt))
-	passwordHash := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+	if err := uc.checkPassword(ctx, uc.passwordPolicy(ctx), password); err != nil {
+		return err
+	}
 
+	now := time.Now().UTC()
+	passwordSalt := strings.ReplaceAll(uuid.NewString(), "-", "")
 	webuser = &webuserdomain.WebUser{
 		ID:                    strings.ReplaceAll(uuid.NewString(), "-", ""),
 		Name:                  name,
 		Username:              username,
-		PasswordHash:          passwordHash,
+		PasswordHash:          passwordutil.Hash(password, passwordSalt),
 		PasswordSalt:          passwordSalt,
 		Email:                 "-",
 		RoleId:                roleId,
 		Active:                true,
+		RegistrationTimestamp: now,
+		CreatedBy:             creatorId,
+		// the password was chosen by the admin
+		MustChangePassword:  true,
+		PasswordChangedTime: &now,
+	}
+
+	uc.webuserrepo.RegisterUser(ctx, webuser)
+	return nil
+}
+
+// RegisterServiceAccount adds a user for integrations, it has no usable password and calls the api with api keys
+func (uc *Usecase) RegisterServiceAccount(ctx context.Context, creatorId, name, username, roleId string) error {
+	webuser := uc.webuserrepo.FindByUsername(ctx, username)
+	if webuser != nil {
+		return restutil.ErrConflict(i18nutil.T(ctx, i18nutil.ERR_USERNAME_EXISTS, username))
+	}
+
+	webuser = &webuserdomain.WebUser{
+		ID:       strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Name:     name,
+		Username: username,
+		// no password hashes to this value, login is refused for service accounts anyway
+		PasswordHash:          "-",
+		PasswordSalt:          strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Email:                 "-",
+		RoleId:                roleId,
+		Active:                true,
 		RegistrationTimestamp: time.Now().UTC(),
 		CreatedBy:             creatorId,
+		ServiceAccount:        true,
 	}
 
-	uc.webuserrepo.RegisterUser(webuser)
+	uc.webuserrepo.RegisterUser(ctx, webuser)
 	return nil
 }
 
-func (uc *Usecase) FindAllUser() ([]*webuserdomain.WebUser, error) {
-	return uc.webuserrepo.FindAll()
+func (uc *Usecase) FindAllUser(ctx context.Context, list queryutil.ListParam) ([]*webuserdomain.WebUser, int64, error) {
+	users, total, err := uc.webuserrepo.FindList(ctx, nil, list)
+	if err != nil {
+		return nil, 0, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return users, total, nil
+}
+
+func (uc *Usecase) ChangeStatus(ctx context.Context, userId string, status bool) {
+	uc.webuserrepo.ChangeStatus(ctx, userId, status)
+	uc.sessionRefresher.RefreshUser(ctx, userId)
 }
 
-func (uc *Usecase) ChangeStatus(userId string, status bool) {
-	uc.webuserrepo.ChangeStatus(userId, status)
+func (uc *Usecase) ChangeLanguage(ctx context.Context, userId string, language string) error {
+	if !i18nutil.IsSupported(language) {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_LANGUAGE_INVALID))
+	}
+
+	err := uc.webuserrepo.ChangeLanguage(ctx, userId, language)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+
+	uc.sessionRefresher.RefreshUser(ctx, userId)
+	return nil
+}
+
+// Unlock clears the failed logins of the user so they can log in again before the lock expires
+func (uc *Usecase) Unlock(ctx context.Context, userId string) error {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	err := uc.webuserrepo.ResetLoginFailure(ctx, userId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+
+	uc.sessionRefresher.ClearLoginAttempts(ctx, webuser.Username)
+	return nil
+}
+
+// FindStakeholders returns the customers and suppliers assigned to the user
+func (uc *Usecase) FindStakeholders(ctx context.Context, userId string) (*webuserdomain.Stakeholders, error) {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	stakeholders, err := uc.webuserrepo.FindStakeholders(ctx, userId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return stakeholders, nil
+}
+
+// AssignStakeholders replaces the customers and suppliers the user may read, it takes effect on the next request
+func (uc *Usecase) AssignStakeholders(ctx context.Context, userId string, customerIds, supplierIds []string) error {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	err := uc.webuserrepo.ReplaceStakeholders(ctx, userId, customerIds, supplierIds)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+	return nil
+}
+
+// FindManagedUsers returns the users the supervisor manages
+func (uc *Usecase) FindManagedUsers(ctx context.Context, supervisorId string) ([]*webuserdomain.ManagedUser, error) {
+	webuser := uc.webuserrepo.Find(ctx, supervisorId)
+	if webuser == nil || webuser.ID == "" {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	users, err := uc.webuserrepo.FindManagedUsers(ctx, supervisorId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return users, nil
+}
+
+// AssignManagedUsers replaces the users the supervisor manages
+func (uc *Usecase) AssignManagedUsers(ctx context.Context, supervisorId string, userIds []string) error {
+	webuser := uc.webuserrepo.Find(ctx, supervisorId)
+	if webuser == nil || webuser.ID == "" {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	err := uc.webuserrepo.ReplaceManagedUsers(ctx, supervisorId, userIds)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+	return nil
+}
+
+// FindBranches returns the branches assigned to the user
+func (uc *Usecase) FindBranches(ctx context.Context, userId string) ([]*branchdomain.Branch, error) {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return nil, restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	branches, err := uc.webuserrepo.FindBranches(ctx, userId)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return nil, restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_FETCH_DATA), err)
+	}
+	return branches, nil
+}
+
+// AssignBranches replaces the branches of the user, the sessions of the user switch to the first branch when the active one is taken away
+func (uc *Usecase) AssignBranches(ctx context.Context, userId string, branchIds []string) error {
+	webuser := uc.webuserrepo.Find(ctx, userId)
+	if webuser == nil || webuser.ID == "" {
+		return restutil.ErrBadRequest(i18nutil.T(ctx, i18nutil.ERR_USER_SELECT))
+	}
+
+	err := uc.webuserrepo.ReplaceBranches(ctx, userId, branchIds)
+	if err != nil {
+		logutil.WithContext(ctx).Error(err.Error())
+		return restutil.ErrInternal(i18nutil.T(ctx, i18nutil.ERR_USER_UPDATE), err)
+	}
+	uc.sessionRefresher.RefreshUser(ctx, userId)
+	return nil
 }
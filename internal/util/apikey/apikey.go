@@ -0,0 +1,47 @@
+package apikeyutil
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+// PREFIX marks a key as an api key of this service, keys look like pos_<id>.<secret>
+const PREFIX = "pos_"
+
+const secretSize = 32
+
+// Generate creates a new key for the key id, only the hash of its secret is stored
+func Generate(id string) (key, hash string, err error) {
+	random := make([]byte, secretSize)
+	if _, err = rand.Read(random); err != nil {
+		return "", "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(random)
+	return PREFIX + id + "." + secret, Hash(secret), nil
+}
+
+// Parse splits a key into the key id and the secret, false when it is not an api key
+func Parse(key string) (id, secret string, ok bool) {
+	key = strings.TrimSpace(key)
+	if !strings.HasPrefix(key, PREFIX) {
+		return "", "", false
+	}
+	id, secret, ok = strings.Cut(strings.TrimPrefix(key, PREFIX), ".")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func Hash(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return base64.URLEncoding.EncodeToString(hash[:])
+}
+
+// Verify compares the secret with the stored hash in constant time
+func Verify(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(secret)), []byte(hash)) == 1
+}
@@ -0,0 +1,87 @@
+package apikeyutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateParseVerify(t *testing.T) {
+	key, hash, err := Generate("abc123")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !strings.HasPrefix(key, PREFIX+"abc123.") {
+		t.Fatalf("key %q does not start with its prefix and id", key)
+	}
+
+	id, secret, ok := Parse(key)
+	if !ok || id != "abc123" {
+		t.Fatalf("Parse(%q) = %q, %v", key, id, ok)
+	}
+	if !Verify(secret, hash) {
+		t.Error("the secret of a generated key does not verify against its hash")
+	}
+	if Verify(secret+"x", hash) {
+		t.Error("another secret verifies against the hash")
+	}
+	if strings.Contains(hash, secret) {
+		t.Error("the hash contains the secret")
+	}
+
+	other, _, _ := Generate("abc123")
+	if other == key {
+		t.Error("two generated keys are the same")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		wantID     string
+		wantSecret string
+		wantOK     bool
+	}{
+		{"valid", "pos_id1.secret", "id1", "secret", true},
+		{"surrounding space", "  pos_id1.secret\n", "id1", "secret", true},
+		{"dot in the secret", "pos_id1.se.cret", "id1", "se.cret", true},
+		{"no prefix", "id1.secret", "", "", false},
+		{"other prefix", "key_id1.secret", "", "", false},
+		{"no dot", "pos_id1secret", "", "", false},
+		{"no id", "pos_.secret", "", "", false},
+		{"no secret", "pos_id1.", "", "", false},
+		{"empty", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, secret, ok := Parse(tt.key)
+			if id != tt.wantID || secret != tt.wantSecret || ok != tt.wantOK {
+				t.Errorf("Parse(%q) = %q, %q, %v, want %q, %q, %v", tt.key, id, secret, ok, tt.wantID, tt.wantSecret, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	hash := Hash("secret")
+	tests := []struct {
+		name   string
+		secret string
+		hash   string
+		want   bool
+	}{
+		{"matching", "secret", hash, true},
+		{"other secret", "Secret", hash, false},
+		{"empty secret", "", hash, false},
+		{"empty hash", "secret", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.hash); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package exportutil
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FORMAT_CSV  = "csv"
+	FORMAT_XLSX = "xlsx"
+)
+
+const CONTENT_TYPE_CSV = "text/csv; charset=utf-8"
+const CONTENT_TYPE_XLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Table is a sheet of an export, a cell is a string or a number
+type Table struct {
+	Name    string
+	Headers []string
+	Rows    [][]interface{}
+}
+
+// ContentType returns the content type of the format, empty when the format is not supported
+func ContentType(format string) string {
+	switch format {
+	case FORMAT_CSV:
+		return CONTENT_TYPE_CSV
+	case FORMAT_XLSX:
+		return CONTENT_TYPE_XLSX
+	}
+	return ""
+}
+
+// Write writes the tables in the format, a csv has no sheets so its tables follow each other separated by an empty line
+func Write(w io.Writer, format string, tables ...Table) error {
+	switch format {
+	case FORMAT_CSV:
+		return WriteCSV(w, tables...)
+	case FORMAT_XLSX:
+		return WriteXLSX(w, tables...)
+	}
+	return fmt.Errorf("unsupported export format %q", format)
+}
+
+func WriteCSV(w io.Writer, tables ...Table) error {
+	writer := csv.NewWriter(w)
+	for i, table := range tables {
+		if i > 0 {
+			writer.Write([]string{})
+		}
+		if err := writer.Write(table.Headers); err != nil {
+			return err
+		}
+		for _, row := range table.Rows {
+			if err := writer.Write(record(row)); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteRows writes a csv of rows that need not have the same columns, for layouts such as the e-Faktur import that mix
+// several kinds of rows under several header rows
+func WriteRows(w io.Writer, rows ...[]interface{}) error {
+	writer := csv.NewWriter(w)
+	for _, row := range rows {
+		if err := writer.Write(record(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func record(row []interface{}) []string {
+	record := make([]string, len(row))
+	for i, cell := range row {
+		record[i] = text(cell)
+		if _, ok := cell.(string); ok {
+			record[i] = defuse(record[i])
+		}
+	}
+	return record
+}
+
+// WriteXLSX writes a workbook with a sheet per table, strings are written inline so no shared string table is needed
+func WriteXLSX(w io.Writer, tables ...Table) error {
+	archive := zip.NewWriter(w)
+
+	var sheets, relations, overrides strings.Builder
+	for i, table := range tables {
+		n := i + 1
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheetName(table.Name, n)), n, n)
+		fmt.Fprintf(&relations, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			relations.String() + `</Relationships>`},
+	}
+	for i, table := range tables {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(table)})
+	}
+
+	for _, file := range files {
+		part, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(part, file.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func worksheet(table Table) string {
+	var sheet strings.Builder
+	sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	headers := make([]interface{}, len(table.Headers))
+	for i, header := range table.Headers {
+		headers[i] = header
+	}
+	writeRow(&sheet, 1, headers)
+	for i, row := range table.Rows {
+		writeRow(&sheet, i+2, row)
+	}
+
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.String()
+}
+
+func writeRow(sheet *strings.Builder, number int, cells []interface{}) {
+	fmt.Fprintf(sheet, `<row r="%d">`, number)
+	for i, cell := range cells {
+		ref := column(i) + strconv.Itoa(number)
+		switch value := cell.(type) {
+		case int, int16, int32, int64, float32, float64:
+			fmt.Fprintf(sheet, `<c r="%s"><v>%s</v></c>`, ref, text(value))
+		default:
+			fmt.Fprintf(sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escape(text(value)))
+		}
+	}
+	sheet.WriteString(`</row>`)
+}
+
+// column returns the letters of a zero based column index, e.g. 0 is A and 26 is AA
+func column(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName keeps the name within the 31 characters a sheet name may have and drops the characters it may not contain
+func sheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "Sheet" + strconv.Itoa(n)
+	}
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	return name
+}
+
+func text(cell interface{}) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32)
+	}
+	return fmt.Sprint(cell)
+}
+
+// defuse keeps a spreadsheet from reading a text cell of a csv as a formula
+func defuse(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func escape(value string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
@@ -0,0 +1,169 @@
+package exportutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+type sheetXML struct {
+	Rows []struct {
+		Ref   string `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// readPart parses a part of the xlsx archive
+func readPart(t *testing.T, archive *zip.Reader, name string, v interface{}) {
+	t.Helper()
+	part, err := archive.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer part.Close()
+	content, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	if err := xml.Unmarshal(content, v); err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteXLSX(&buffer,
+		Table{Name: "Rekap: Maret/2024", Headers: []string{"Tanggal", "Jumlah"}, Rows: [][]interface{}{
+			{"01-03-2024", int64(150000)},
+			{"=HYPERLINK(\"x\")", 12.5},
+			{"<Toko & Co>", nil},
+		}},
+		Table{Headers: []string{"Kode"}},
+	)
+	if err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("the output is not a zip: %v", err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels"} {
+		var part struct{}
+		readPart(t, archive, name, &part)
+	}
+
+	var workbook workbookXML
+	readPart(t, archive, "xl/workbook.xml", &workbook)
+	wantSheets := []string{"Rekap Maret2024", "Sheet2"}
+	if len(workbook.Sheets) != len(wantSheets) {
+		t.Fatalf("sheets = %d, want %d", len(workbook.Sheets), len(wantSheets))
+	}
+	for i, name := range wantSheets {
+		if workbook.Sheets[i].Name != name {
+			t.Errorf("sheet %d = %q, want %q", i+1, workbook.Sheets[i].Name, name)
+		}
+	}
+
+	var sheet sheetXML
+	readPart(t, archive, "xl/worksheets/sheet1.xml", &sheet)
+	tests := []struct {
+		row, cell int
+		ref       string
+		kind      string
+		value     string
+	}{
+		{0, 0, "A1", "inlineStr", "Tanggal"},
+		{0, 1, "B1", "inlineStr", "Jumlah"},
+		{1, 0, "A2", "inlineStr", "01-03-2024"},
+		{1, 1, "B2", "", "150000"},
+		// an inline string is never read as a formula, it is kept as it is
+		{2, 0, "A3", "inlineStr", "=HYPERLINK(\"x\")"},
+		{2, 1, "B3", "", "12.5"},
+		{3, 0, "A4", "inlineStr", "<Toko & Co>"},
+		{3, 1, "B4", "inlineStr", ""},
+	}
+	if len(sheet.Rows) != 4 {
+		t.Fatalf("rows = %d, want 4", len(sheet.Rows))
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			cell := sheet.Rows[tt.row].Cells[tt.cell]
+			value := cell.Value
+			if cell.Type == "inlineStr" {
+				value = cell.Inline
+			}
+			if cell.Ref != tt.ref || cell.Type != tt.kind || value != tt.value {
+				t.Errorf("cell = %s %q %q, want %s %q %q", cell.Ref, cell.Type, value, tt.ref, tt.kind, tt.value)
+			}
+		})
+	}
+
+	var empty sheetXML
+	readPart(t, archive, "xl/worksheets/sheet2.xml", &empty)
+	if len(empty.Rows) != 1 || empty.Rows[0].Cells[0].Inline != "Kode" {
+		t.Errorf("sheet 2 = %+v, want the header row only", empty.Rows)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []Table
+		want   string
+	}{
+		{"text and numbers", []Table{{Headers: []string{"Kode", "Jumlah"}, Rows: [][]interface{}{{"A-1", int64(-5)}, {"B", 1.5}}}},
+			"Kode,Jumlah\nA-1,-5\nB,1.5\n"},
+		{"formulas", []Table{{Headers: []string{"Nama"}, Rows: [][]interface{}{{"=SUM(A1:A2)"}, {"+62"}, {"-1"}, {"@cmd"}, {"\tx"}, {"\rx"}}}},
+			"Nama\n'=SUM(A1:A2)\n'+62\n'-1\n'@cmd\n'\tx\n\"'\rx\"\n"},
+		{"tables", []Table{{Headers: []string{"A"}, Rows: [][]interface{}{{"1"}}}, {Headers: []string{"B"}}},
+			"A\n1\n\nB\n"},
+		{"nil cell", []Table{{Headers: []string{"A", "B"}, Rows: [][]interface{}{{nil, "x"}}}},
+			"A,B\n,x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := WriteCSV(&buffer, tt.tables...); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buffer.String() != tt.want {
+				t.Errorf("WriteCSV() = %q, want %q", buffer.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestColumn(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := column(tt.index); got != tt.want {
+			t.Errorf("column(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
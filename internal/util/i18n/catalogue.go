@@ -0,0 +1,713 @@
+package i18nutil
+
+const MONTH_PREFIX = "month."
+const MENU_PREFIX = "menu."
+
+// message keys
+const (
+	ERR_FETCH_DATA                     = "err.fetch.data"
+	ERR_UPDATE_DATA                    = "err.update.data"
+	ERR_DATE_INVALID                   = "err.date.invalid"
+	ERR_DATE_REQUIRED                  = "err.date.required"
+	ERR_DATE_SELECT                    = "err.date.select"
+	ERR_MONTH_REQUIRED                 = "err.month.required"
+	ERR_MONTH_INVALID                  = "err.month.invalid"
+	ERR_STATUS_REQUIRED                = "err.status.required"
+	ERR_STATUS_INVALID                 = "err.status.invalid"
+	ERR_NAME_REQUIRED                  = "err.name.required"
+	ERR_USERNAME_REQUIRED              = "err.username.required"
+	ERR_PASSWORD_REQUIRED              = "err.password.required"
+	ERR_OLD_PASSWORD_REQUIRED          = "err.old.password.required"
+	ERR_NEW_PASSWORD_REQUIRED          = "err.new.password.required"
+	ERR_REPEAT_PASSWORD_REQUIRED       = "err.repeat.password.required"
+	ERR_REPEAT_PASSWORD_MISMATCH       = "err.repeat.password.mismatch"
+	ERR_OLD_PASSWORD_MISMATCH          = "err.old.password.mismatch"
+	ERR_LANGUAGE_REQUIRED              = "err.language.required"
+	ERR_LANGUAGE_INVALID               = "err.language.invalid"
+	ERR_LIST_PARAM_INVALID             = "err.list.param.invalid"
+	ERR_SESSION_NOT_FOUND              = "err.session.not.found"
+	ERR_SESSION_SELECT                 = "err.session.select"
+	ERR_LOGIN_THROTTLED                = "err.login.throttled"
+	ERR_USER_LOCKED                    = "err.user.locked"
+	ERR_TOTP_CODE_REQUIRED             = "err.totp.code.required"
+	ERR_TOTP_INVALID                   = "err.totp.invalid"
+	ERR_TOTP_CHALLENGE_INVALID         = "err.totp.challenge.invalid"
+	ERR_TOTP_ALREADY_ENABLED           = "err.totp.already.enabled"
+	ERR_TOTP_NOT_ENABLED               = "err.totp.not.enabled"
+	ERR_TOTP_SETUP_REQUIRED            = "err.totp.setup.required"
+	ERR_TOTP_SETUP                     = "err.totp.setup"
+	ERR_SERVICE_ACCOUNT_SELECT         = "err.service.account.select"
+	ERR_API_KEY_NAME_REQUIRED          = "err.api.key.name.required"
+	ERR_API_KEY_PERMISSION_INVALID     = "err.api.key.permission.invalid"
+	ERR_API_KEY_EXPIRY_INVALID         = "err.api.key.expiry.invalid"
+	ERR_API_KEY_NOT_FOUND              = "err.api.key.not.found"
+	ERR_API_KEY_REVOKED                = "err.api.key.revoked"
+	ERR_API_KEY_SAVE                   = "err.api.key.save"
+	ERR_API_KEY_SELECT                 = "err.api.key.select"
+	ERR_PASSWORD_TOO_SHORT             = "err.password.too.short"
+	ERR_PASSWORD_MIXED_CASE            = "err.password.mixed.case"
+	ERR_PASSWORD_DIGIT                 = "err.password.digit"
+	ERR_PASSWORD_SYMBOL                = "err.password.symbol"
+	ERR_PASSWORD_REUSED                = "err.password.reused"
+	ERR_CASH_CLOSED                    = "err.cash.closed"
+	ERR_CASH_CLOSING_NOT_FOUND         = "err.cash.closing.not.found"
+	ERR_CASH_CLOSING_NEXT_CLOSED       = "err.cash.closing.next.closed"
+	ERR_CASH_CLOSING_REASON_REQUIRED   = "err.cash.closing.reason.required"
+	ERR_CASH_CLOSING_USER_REQUIRED     = "err.cash.closing.user.required"
+	ERR_CASH_CLOSING_SAVE              = "err.cash.closing.save"
+	ERR_SYNC_TOO_MANY                  = "err.sync.too.many"
+	ERR_SYNC_ID_INVALID                = "err.sync.id.invalid"
+	ERR_SYNC_ID_TAKEN                  = "err.sync.id.taken"
+	ERR_SYNC_TRANSFER_REJECTED         = "err.sync.transfer.rejected"
+	ERR_SYNC_TRANSFER_CANCELED         = "err.sync.transfer.canceled"
+	ERR_SYNC_SAVE                      = "err.sync.save"
+	ERR_SYNC_CURSOR_INVALID            = "err.sync.cursor.invalid"
+	ERR_ATTACHMENT_TOO_LARGE           = "err.attachment.too.large"
+	ERR_ATTACHMENT_TYPE_INVALID        = "err.attachment.type.invalid"
+	ERR_ATTACHMENT_OWNER_TYPE_INVALID  = "err.attachment.owner.type.invalid"
+	ERR_ATTACHMENT_OWNER_NOT_FOUND     = "err.attachment.owner.not.found"
+	ERR_ATTACHMENT_NOT_FOUND           = "err.attachment.not.found"
+	ERR_ATTACHMENT_FILE_REQUIRED       = "err.attachment.file.required"
+	ERR_ATTACHMENT_SAVE                = "err.attachment.save"
+	ERR_ATTACHMENT_NOT_ALLOWED         = "err.attachment.not.allowed"
+	ERR_NOTIFICATION_READ              = "err.notification.read"
+	ERR_REKAP_BUCKET_INVALID           = "err.rekap.bucket.invalid"
+	ERR_REKAP_RANGE_INVALID            = "err.rekap.range.invalid"
+	ERR_REKAP_RANGE_TOO_LONG           = "err.rekap.range.too.long"
+	ERR_EXPORT_FORMAT_INVALID          = "err.export.format.invalid"
+	ERR_EXPENSE_CATEGORY_CODE_REQUIRED = "err.expense.category.code.required"
+	ERR_EXPENSE_CATEGORY_NAME_REQUIRED = "err.expense.category.name.required"
+	ERR_EXPENSE_CATEGORY_SELECT        = "err.expense.category.select"
+	ERR_EXPENSE_CATEGORY_CODE_EXISTS   = "err.expense.category.code.exists"
+	ERR_EXPENSE_CATEGORY_INVALID       = "err.expense.category.invalid"
+	ERR_EXPENSE_CATEGORY_CREATE        = "err.expense.category.create"
+	ERR_EXPENSE_CATEGORY_UPDATE        = "err.expense.category.update"
+	ERR_EXPENSE_BUDGET_SELECT          = "err.expense.budget.select"
+	ERR_EXPENSE_BUDGET_PERIOD_INVALID  = "err.expense.budget.period.invalid"
+	ERR_EXPENSE_BUDGET_MODE_INVALID    = "err.expense.budget.mode.invalid"
+	ERR_EXPENSE_BUDGET_AMOUNT_INVALID  = "err.expense.budget.amount.invalid"
+	ERR_EXPENSE_BUDGET_USER_INVALID    = "err.expense.budget.user.invalid"
+	ERR_EXPENSE_BUDGET_SAVE            = "err.expense.budget.save"
+	ERR_EXPENSE_BUDGET_DELETE          = "err.expense.budget.delete"
+	ERR_EXPENSE_BUDGET_EXCEEDED        = "err.expense.budget.exceeded"
+	ERR_RECONCILIATION_APPLY           = "err.reconciliation.apply"
+	ERR_SYNC_TRANSFER_EXPIRED          = "err.sync.transfer.expired"
+	ERR_DANA_TRANSFER_EXPIRED          = "err.dana.transfer.expired"
+	ERR_DANA_TRANSFER_NOT_FOUND        = "err.dana.transfer.not.found"
+	ERR_DANA_BULK_EMPTY                = "err.dana.bulk.empty"
+	ERR_DANA_BULK_TOO_MANY             = "err.dana.bulk.too.many"
+	ERR_DANA_STATUS_INVALID            = "err.dana.status.invalid"
+	ERR_BRANCH_CODE_REQUIRED           = "err.branch.code.required"
+	ERR_BRANCH_NAME_REQUIRED           = "err.branch.name.required"
+	ERR_BRANCH_CODE_EXISTS             = "err.branch.code.exists"
+	ERR_BRANCH_PREFIX_INVALID          = "err.branch.prefix.invalid"
+	ERR_BRANCH_SELECT                  = "err.branch.select"
+	ERR_BRANCH_SAVE                    = "err.branch.save"
+	ERR_BRANCH_NOT_ASSIGNED            = "err.branch.not.assigned"
+	ERR_TENANT_NOT_FOUND               = "err.tenant.not.found"
+	ERR_TENANT_CONNECT                 = "err.tenant.connect"
+	ERR_TENANT_CODE_INVALID            = "err.tenant.code.invalid"
+	ERR_TENANT_SCHEMA_INVALID          = "err.tenant.schema.invalid"
+	ERR_TENANT_EXISTS                  = "err.tenant.exists"
+	ERR_TENANT_SCHEMA_EXISTS           = "err.tenant.schema.exists"
+	ERR_TENANT_PROVISION               = "err.tenant.provision"
+	ERR_TAX_RATE_INVALID               = "err.tax.rate.invalid"
+	ERR_DISCOUNT_INVALID               = "err.discount.invalid"
+	ERR_TRANSACTION_NOT_FOUND          = "err.transaction.not.found"
+	ERR_NPWP_INVALID                   = "err.npwp.invalid"
+	ERR_TAX_SERIAL_NOT_SET             = "err.tax.serial.not.set"
+	ERR_TAX_SERIAL_EXHAUSTED           = "err.tax.serial.exhausted"
+	ERR_EFAKTUR_EXPORT                 = "err.efaktur.export"
+	ERR_INTERNAL_SERVER                = "err.internal.server"
+	ERR_SYNC_RECORD_MODIFIED           = "err.sync.record.modified"
+	ERR_TAX_SERIAL_ALLOCATE            = "err.tax.serial.allocate"
+	ERR_TAX_SERIAL_CONFLICT            = "err.tax.serial.conflict"
+	ERR_LOGIN_REQUIRED                 = "err.login.required"
+	ERR_LOGIN_INVALID                  = "err.login.invalid"
+	ERR_USER_INACTIVE                  = "err.user.inactive"
+	ERR_USERNAME_EXISTS                = "err.username.exists"
+	ERR_USER_UPDATE                    = "err.user.update"
+	ERR_USER_SELECT                    = "err.user.select"
+	ERR_ROLE_REQUIRED                  = "err.role.required"
+	ERR_ROLE_NAME_REQUIRED             = "err.role.name.required"
+	ERR_ROLE_NAME_EXISTS               = "err.role.name.exists"
+	ERR_ROLE_FETCH                     = "err.role.fetch"
+	ERR_ABILITY_REQUIRED               = "err.ability.required"
+	ERR_PRODUCT_REQUIRED               = "err.product.required"
+	ERR_PRODUCT_SELECT                 = "err.product.select"
+	ERR_PRODUCT_CODE_REQUIRED          = "err.product.code.required"
+	ERR_PRODUCT_NAME_REQUIRED          = "err.product.name.required"
+	ERR_PRODUCT_PRICE_REQUIRED         = "err.product.price.required"
+	ERR_PRODUCT_CODE_EXISTS            = "err.product.code.exists"
+	ERR_PRODUCT_CREATE                 = "err.product.create"
+	ERR_PRODUCT_UPDATE                 = "err.product.update"
+	ERR_UNIT_REQUIRED                  = "err.unit.required"
+	ERR_UNIT_SELECT                    = "err.unit.select"
+	ERR_UNIT_CODE_REQUIRED             = "err.unit.code.required"
+	ERR_UNIT_CODE_EXISTS               = "err.unit.code.exists"
+	ERR_UNIT_CREATE                    = "err.unit.create"
+	ERR_UNIT_UPDATE                    = "err.unit.update"
+	ERR_SUPPLIER_REQUIRED              = "err.supplier.required"
+	ERR_SUPPLIER_SELECT                = "err.supplier.select"
+	ERR_SUPPLIER_CODE_REQUIRED         = "err.supplier.code.required"
+	ERR_SUPPLIER_NAME_REQUIRED         = "err.supplier.name.required"
+	ERR_SUPPLIER_CODE_EXISTS           = "err.supplier.code.exists"
+	ERR_SUPPLIER_NOT_FOUND             = "err.supplier.not.found"
+	ERR_SUPPLIER_CREATE                = "err.supplier.create"
+	ERR_SUPPLIER_UPDATE                = "err.supplier.update"
+	ERR_CUSTOMER_REQUIRED              = "err.customer.required"
+	ERR_CUSTOMER_SELECT                = "err.customer.select"
+	ERR_CUSTOMER_CODE_REQUIRED         = "err.customer.code.required"
+	ERR_CUSTOMER_NAME_REQUIRED         = "err.customer.name.required"
+	ERR_CUSTOMER_CODE_EXISTS           = "err.customer.code.exists"
+	ERR_CUSTOMER_NOT_FOUND             = "err.customer.not.found"
+	ERR_CUSTOMER_ID_NOT_FOUND          = "err.customer.id.not.found"
+	ERR_CUSTOMER_CREATE                = "err.customer.create"
+	ERR_CUSTOMER_UPDATE                = "err.customer.update"
+	ERR_STAKEHOLDER_REQUIRED           = "err.stakeholder.required"
+	ERR_STAKEHOLDER_CODE_REQUIRED      = "err.stakeholder.code.required"
+	ERR_TRANSACTION_TYPE_REQUIRED      = "err.transaction.type.required"
+	ERR_DATA_SELECT                    = "err.data.select"
+	ERR_BUY_PRICE_REQUIRED             = "err.buy.price.required"
+	ERR_SELL_PRICE_REQUIRED            = "err.sell.price.required"
+	ERR_BUY_QUANTITY_REQUIRED          = "err.buy.quantity.required"
+	ERR_SELL_QUANTITY_REQUIRED         = "err.sell.quantity.required"
+	ERR_PRICE_CREATE                   = "err.price.create"
+	ERR_PRICE_UPDATE                   = "err.price.update"
+	ERR_PRICE_CHANGE                   = "err.price.change"
+	ERR_PRICE_FIND                     = "err.price.find"
+	ERR_BUY_PRICE_CREATE               = "err.buy.price.create"
+	ERR_BUY_PRICE_UPDATE               = "err.buy.price.update"
+	ERR_TEMPLATE_REQUIRED              = "err.template.required"
+	ERR_TEMPLATE_NAME_REQUIRED         = "err.template.name.required"
+	ERR_TEMPLATE_NAME_EXISTS           = "err.template.name.exists"
+	ERR_TEMPLATE_CREATE                = "err.template.create"
+	ERR_TEMPLATE_COPY                  = "err.template.copy"
+	ERR_FIND                           = "err.find"
+	ERR_TRANSACTION_REQUIRED           = "err.transaction.required"
+	ERR_TRANSACTION_SELECT             = "err.transaction.select"
+	ERR_TRANSACTION_ADD_SELECT         = "err.transaction.add.select"
+	ERR_KONTRABON_TRANSACTION_SELECT   = "err.kontrabon.transaction.select"
+	ERR_TRANSACTION_CREATE             = "err.transaction.create"
+	ERR_TRANSACTION_PROCESS            = "err.transaction.process"
+	ERR_TRANSACTION_UPDATE             = "err.transaction.update"
+	ERR_TRANSACTION_STATUS_UPDATE      = "err.transaction.status.update"
+	ERR_TRANSACTION_FIND               = "err.transaction.find"
+	ERR_CREDIT_FETCH                   = "err.credit.fetch"
+	ERR_CUSTOMER_REPORT_FETCH          = "err.customer.report.fetch"
+	ERR_KONTRABON_SELECT               = "err.kontrabon.select"
+	ERR_KONTRABON_CREATE               = "err.kontrabon.create"
+	ERR_KONTRABON_UPDATE               = "err.kontrabon.update"
+	ERR_KONTRABON_FIND                 = "err.kontrabon.find"
+	ERR_STATUS_UPDATE                  = "err.status.update"
+	ERR_PAYMENT_TOTAL_REQUIRED         = "err.payment.total.required"
+	ERR_PAYMENT_DATE_REQUIRED          = "err.payment.date.required"
+	ERR_DANA_CREATE                    = "err.dana.create"
+	ERR_DANA_SEND                      = "err.dana.send"
+	ERR_DANA_UPDATE                    = "err.dana.update"
+	ERR_DANA_REJECT                    = "err.dana.reject"
+	ERR_DANA_CANCEL                    = "err.dana.cancel"
+	ERR_DANA_APPROVE_NOT_PENDING       = "err.dana.approve.not.pending"
+	ERR_DANA_REJECT_NOT_PENDING        = "err.dana.reject.not.pending"
+	ERR_DANA_UPDATE_NOT_ALLOWED        = "err.dana.update.not.allowed"
+	ERR_DANA_APPROVE_NOT_ALLOWED       = "err.dana.approve.not.allowed"
+	ERR_DANA_REJECT_NOT_ALLOWED        = "err.dana.reject.not.allowed"
+	ERR_DANA_CANCEL_NOT_ALLOWED        = "err.dana.cancel.not.allowed"
+	ERR_MOBILE_NOT_ALLOWED             = "err.mobile.not.allowed"
+	ERR_PENJUALAN_CREATE               = "err.penjualan.create"
+	ERR_PENJUALAN_DELETE               = "err.penjualan.delete"
+	ERR_BELANJA_CREATE                 = "err.belanja.create"
+	ERR_BELANJA_DELETE                 = "err.belanja.delete"
+	ERR_OPERASIONAL_CREATE             = "err.operasional.create"
+	ERR_OPERASIONAL_DELETE             = "err.operasional.delete"
+)
+
+var catalogue = map[string]map[string]string{
+	LANG_ID: {
+		ERR_FETCH_DATA:                     "Gagal mengambil data",
+		ERR_UPDATE_DATA:                    "Gagal mengubah data",
+		ERR_DATE_INVALID:                   "Tanggal tidak valid",
+		ERR_DATE_REQUIRED:                  "Harap isi tanggal",
+		ERR_DATE_SELECT:                    "Harap pilih tanggal",
+		ERR_MONTH_REQUIRED:                 "Harap pilih bulan",
+		ERR_MONTH_INVALID:                  "Bulan tidak valid",
+		ERR_STATUS_REQUIRED:                "Harap pilih status",
+		ERR_STATUS_INVALID:                 "Status tidak valid",
+		ERR_NAME_REQUIRED:                  "Harap isi nama",
+		ERR_USERNAME_REQUIRED:              "Harap isi username",
+		ERR_PASSWORD_REQUIRED:              "Harap isi kata sandi",
+		ERR_OLD_PASSWORD_REQUIRED:          "Harap isi kata sandi lama",
+		ERR_NEW_PASSWORD_REQUIRED:          "Harap isi kata sandi baru",
+		ERR_REPEAT_PASSWORD_REQUIRED:       "Harap isi ulangi kata sandi baru",
+		ERR_REPEAT_PASSWORD_MISMATCH:       "Ulangi kata sandi baru harus sesuai",
+		ERR_OLD_PASSWORD_MISMATCH:          "Kata sandi lama tidak sesuai",
+		ERR_LANGUAGE_REQUIRED:              "Harap pilih bahasa",
+		ERR_LANGUAGE_INVALID:               "Bahasa tidak didukung",
+		ERR_LIST_PARAM_INVALID:             "Parameter %s tidak valid",
+		ERR_SESSION_NOT_FOUND:              "Sesi tidak ditemukan",
+		ERR_SESSION_SELECT:                 "Harap pilih sesi",
+		ERR_LOGIN_THROTTLED:                "Terlalu banyak percobaan login, coba lagi dalam %d detik",
+		ERR_USER_LOCKED:                    "User terkunci karena terlalu banyak percobaan login, coba lagi dalam %d menit",
+		ERR_TOTP_CODE_REQUIRED:             "Kode verifikasi harus diisi",
+		ERR_TOTP_INVALID:                   "Kode verifikasi salah",
+		ERR_TOTP_CHALLENGE_INVALID:         "Verifikasi login tidak valid atau sudah kedaluwarsa, silakan login ulang",
+		ERR_TOTP_ALREADY_ENABLED:           "Autentikasi dua faktor sudah aktif",
+		ERR_TOTP_NOT_ENABLED:               "Autentikasi dua faktor belum aktif",
+		ERR_TOTP_SETUP_REQUIRED:            "Harap mulai pendaftaran autentikasi dua faktor terlebih dahulu",
+		ERR_TOTP_SETUP:                     "Gagal menyiapkan autentikasi dua faktor",
+		ERR_SERVICE_ACCOUNT_SELECT:         "Harap pilih service account",
+		ERR_API_KEY_NAME_REQUIRED:          "Nama API key harus diisi",
+		ERR_API_KEY_PERMISSION_INVALID:     "Hak akses %s tidak dimiliki role service account",
+		ERR_API_KEY_EXPIRY_INVALID:         "Masa berlaku API key harus setelah waktu sekarang",
+		ERR_API_KEY_NOT_FOUND:              "API key tidak ditemukan",
+		ERR_API_KEY_REVOKED:                "API key sudah dicabut",
+		ERR_API_KEY_SAVE:                   "Gagal menyimpan API key",
+		ERR_API_KEY_SELECT:                 "Harap pilih API key",
+		ERR_PASSWORD_TOO_SHORT:             "Kata sandi minimal %d karakter",
+		ERR_PASSWORD_MIXED_CASE:            "Kata sandi harus mengandung huruf besar dan huruf kecil",
+		ERR_PASSWORD_DIGIT:                 "Kata sandi harus mengandung angka",
+		ERR_PASSWORD_SYMBOL:                "Kata sandi harus mengandung simbol",
+		ERR_PASSWORD_REUSED:                "Kata sandi tidak boleh sama dengan %d kata sandi terakhir",
+		ERR_CASH_CLOSED:                    "Kas tanggal %s sudah ditutup",
+		ERR_CASH_CLOSING_NOT_FOUND:         "Kas tanggal %s belum ditutup",
+		ERR_CASH_CLOSING_NEXT_CLOSED:       "Kas tanggal %s sudah ditutup, buka kembali kas tersebut terlebih dahulu",
+		ERR_CASH_CLOSING_REASON_REQUIRED:   "Harap isi alasan membuka kembali kas",
+		ERR_CASH_CLOSING_USER_REQUIRED:     "Harap pilih user mobile",
+		ERR_CASH_CLOSING_SAVE:              "Gagal menyimpan tutup kas",
+		ERR_SYNC_TOO_MANY:                  "Maksimal %d data per sinkronisasi",
+		ERR_SYNC_ID_INVALID:                "ID data tidak valid",
+		ERR_SYNC_ID_TAKEN:                  "ID data sudah dipakai user lain",
+		ERR_SYNC_TRANSFER_REJECTED:         "Pengiriman dana sudah ditolak",
+		ERR_SYNC_TRANSFER_CANCELED:         "Pengiriman dana sudah dibatalkan",
+		ERR_SYNC_SAVE:                      "Gagal menyimpan data sinkronisasi",
+		ERR_SYNC_CURSOR_INVALID:            "Cursor tidak valid",
+		ERR_ATTACHMENT_TOO_LARGE:           "File melebihi batas ukuran %d KB",
+		ERR_ATTACHMENT_TYPE_INVALID:        "Jenis file tidak didukung, gunakan JPEG, PNG, GIF atau PDF",
+		ERR_ATTACHMENT_OWNER_TYPE_INVALID:  "Jenis pemilik lampiran tidak valid",
+		ERR_ATTACHMENT_OWNER_NOT_FOUND:     "Data pemilik lampiran tidak ditemukan",
+		ERR_ATTACHMENT_NOT_FOUND:           "Lampiran tidak ditemukan",
+		ERR_ATTACHMENT_FILE_REQUIRED:       "File harus diisi",
+		ERR_ATTACHMENT_SAVE:                "Gagal menyimpan lampiran",
+		ERR_ATTACHMENT_NOT_ALLOWED:         "Tidak memiliki akses ke lampiran ini",
+		ERR_NOTIFICATION_READ:              "Gagal menandai notifikasi sudah dibaca",
+		ERR_REKAP_BUCKET_INVALID:           "Jenis periode rekapitulasi tidak valid, gunakan day, week atau month",
+		ERR_REKAP_RANGE_INVALID:            "Tanggal akhir tidak boleh sebelum tanggal awal",
+		ERR_REKAP_RANGE_TOO_LONG:           "Rentang tanggal maksimal %d hari",
+		ERR_EXPORT_FORMAT_INVALID:          "Format ekspor tidak didukung, gunakan csv atau xlsx",
+		ERR_EXPENSE_CATEGORY_CODE_REQUIRED: "Harap isi kode kategori",
+		ERR_EXPENSE_CATEGORY_NAME_REQUIRED: "Harap isi nama kategori",
+		ERR_EXPENSE_CATEGORY_SELECT:        "Harap pilih kategori yang akan diperbarui",
+		ERR_EXPENSE_CATEGORY_CODE_EXISTS:   "Kategori dengan kode %s sudah terdaftar",
+		ERR_EXPENSE_CATEGORY_INVALID:       "Kategori biaya tidak ditemukan atau tidak aktif",
+		ERR_EXPENSE_CATEGORY_CREATE:        "Gagal menambahkan kategori biaya",
+		ERR_EXPENSE_CATEGORY_UPDATE:        "Gagal memperbarui kategori biaya",
+		ERR_EXPENSE_BUDGET_SELECT:          "Harap pilih anggaran",
+		ERR_EXPENSE_BUDGET_PERIOD_INVALID:  "Periode anggaran harus daily atau monthly",
+		ERR_EXPENSE_BUDGET_MODE_INVALID:    "Mode anggaran harus warn atau reject",
+		ERR_EXPENSE_BUDGET_AMOUNT_INVALID:  "Jumlah anggaran harus lebih dari 0",
+		ERR_EXPENSE_BUDGET_USER_INVALID:    "User anggaran tidak ditemukan",
+		ERR_EXPENSE_BUDGET_SAVE:            "Gagal menyimpan anggaran",
+		ERR_EXPENSE_BUDGET_DELETE:          "Gagal menghapus anggaran",
+		ERR_EXPENSE_BUDGET_EXCEEDED:        "Anggaran %s terlampaui: terpakai %.0f dari %.0f",
+		ERR_RECONCILIATION_APPLY:           "Gagal menerapkan harga beli rata-rata",
+		ERR_SYNC_TRANSFER_EXPIRED:          "Pengiriman dana sudah kedaluwarsa",
+		ERR_DANA_TRANSFER_EXPIRED:          "Pengiriman dana sudah kedaluwarsa",
+		ERR_DANA_TRANSFER_NOT_FOUND:        "Pengiriman dana tidak ditemukan",
+		ERR_DANA_BULK_EMPTY:                "Pilih pengiriman dana",
+		ERR_DANA_BULK_TOO_MANY:             "Maksimal %d pengiriman dana sekaligus",
+		ERR_DANA_STATUS_INVALID:            "Status pengiriman dana tidak valid",
+		ERR_BRANCH_CODE_REQUIRED:           "Kode cabang harus diisi",
+		ERR_BRANCH_NAME_REQUIRED:           "Nama cabang harus diisi",
+		ERR_BRANCH_CODE_EXISTS:             "Kode cabang %s sudah digunakan",
+		ERR_BRANCH_PREFIX_INVALID:          "Prefix kode cabang hanya boleh huruf, angka atau tanda hubung, maksimal 16 karakter",
+		ERR_BRANCH_SELECT:                  "Pilih cabang",
+		ERR_BRANCH_SAVE:                    "Gagal menyimpan cabang",
+		ERR_BRANCH_NOT_ASSIGNED:            "Anda tidak terdaftar di cabang ini",
+		ERR_TENANT_NOT_FOUND:               "Tenant %s tidak ditemukan",
+		ERR_TENANT_CONNECT:                 "Gagal terhubung ke database tenant %s",
+		ERR_TENANT_CODE_INVALID:            "Kode tenant hanya boleh huruf kecil, angka atau tanda hubung, maksimal 31 karakter",
+		ERR_TENANT_SCHEMA_INVALID:          "Nama schema tenant tidak valid",
+		ERR_TENANT_EXISTS:                  "Tenant %s sudah terdaftar",
+		ERR_TENANT_SCHEMA_EXISTS:           "Schema %s sudah ada",
+		ERR_TENANT_PROVISION:               "Gagal menyiapkan tenant %s",
+		ERR_TAX_RATE_INVALID:               "Tarif pajak harus di antara 0 dan 100 persen",
+		ERR_DISCOUNT_INVALID:               "Diskon harus PERCENT di antara 0 dan 100 atau AMOUNT yang tidak negatif",
+		ERR_TRANSACTION_NOT_FOUND:          "Transaksi tidak ditemukan",
+		ERR_NPWP_INVALID:                   "NPWP harus 15 atau 16 digit",
+		ERR_TAX_SERIAL_NOT_SET:             "Nomor seri faktur pajak belum diatur",
+		ERR_TAX_SERIAL_EXHAUSTED:           "Nomor seri faktur pajak sudah habis, harap atur rentang nomor yang baru",
+		ERR_EFAKTUR_EXPORT:                 "Terjadi kesalahan saat mengekspor e-Faktur",
+		ERR_INTERNAL_SERVER:                "Terjadi kesalahan pada server",
+		ERR_SYNC_RECORD_MODIFIED:           "Data dengan ID ini sudah tersimpan dengan isi berbeda",
+		ERR_TAX_SERIAL_ALLOCATE:            "Terjadi kesalahan saat memberi nomor seri faktur pajak",
+		ERR_TAX_SERIAL_CONFLICT:            "Transaksi sudah diberi nomor seri faktur pajak oleh proses lain, harap ulangi",
+		ERR_LOGIN_REQUIRED:                 "Harap melakukan login terlebih dahulu",
+		ERR_LOGIN_INVALID:                  "User atau Password yang dimasukan salah",
+		ERR_USER_INACTIVE:                  "User sudah tidak aktif",
+		ERR_USERNAME_EXISTS:                "User dengan username '%s' sudah ada",
+		ERR_USER_UPDATE:                    "Terjadi kesalahan saat melakukan perubahan data user",
+		ERR_USER_SELECT:                    "Harap pilih user yang akan diedit",
+		ERR_ROLE_REQUIRED:                  "Harap pilih role",
+		ERR_ROLE_NAME_REQUIRED:             "Harap isi nama role",
+		ERR_ROLE_NAME_EXISTS:               "Role dengan nama %s sudah terdaftar",
+		ERR_ROLE_FETCH:                     "Terjadi kesalahan saat pengambilan data Role",
+		ERR_ABILITY_REQUIRED:               "Harap pilih ability",
+		ERR_PRODUCT_REQUIRED:               "Harap pilih produk",
+		ERR_PRODUCT_SELECT:                 "Harap pilih produk yang akan diperbarui",
+		ERR_PRODUCT_CODE_REQUIRED:          "Harap isi kode produk",
+		ERR_PRODUCT_NAME_REQUIRED:          "Harap isi nama produk",
+		ERR_PRODUCT_PRICE_REQUIRED:         "Harap isi harga produk",
+		ERR_PRODUCT_CODE_EXISTS:            "Produk dengan kode %s sudah terdaftar",
+		ERR_PRODUCT_CREATE:                 "Terjadi kesalahan saat melakukan penambahan data produk",
+		ERR_PRODUCT_UPDATE:                 "Terjadi kesalahan saat melakukan pembaruan data produk",
+		ERR_UNIT_REQUIRED:                  "Harap pilih satuan",
+		ERR_UNIT_SELECT:                    "Harap pilih unit yang akan diperbarui",
+		ERR_UNIT_CODE_REQUIRED:             "Harap isi kode satuan",
+		ERR_UNIT_CODE_EXISTS:               "Satuan dengan kode %s sudah terdaftar",
+		ERR_UNIT_CREATE:                    "Terjadi kesalahan saat melakukan penambahan data satuan",
+		ERR_UNIT_UPDATE:                    "Terjadi kesalahan saat melakukan pembaruan data satuan",
+		ERR_SUPPLIER_REQUIRED:              "Harap pilih supplier",
+		ERR_SUPPLIER_SELECT:                "Harap pilih supplier yang akan diperbarui",
+		ERR_SUPPLIER_CODE_REQUIRED:         "Harap isi kode supplier",
+		ERR_SUPPLIER_NAME_REQUIRED:         "Harap isi nama supplier",
+		ERR_SUPPLIER_CODE_EXISTS:           "Supplier dengan kode %s sudah terdaftar",
+		ERR_SUPPLIER_NOT_FOUND:             "Supplier dengan kode '%s' tidak ditemukan",
+		ERR_SUPPLIER_CREATE:                "Terjadi kesalahan saat melakukan penambahan data supplier",
+		ERR_SUPPLIER_UPDATE:                "Terjadi kesalahan saat melakukan pembaruan data supplier",
+		ERR_CUSTOMER_REQUIRED:              "Harap pilih customer",
+		ERR_CUSTOMER_SELECT:                "Harap pilih customer yang akan diperbarui",
+		ERR_CUSTOMER_CODE_REQUIRED:         "Harap isi kode customer",
+		ERR_CUSTOMER_NAME_REQUIRED:         "Harap isi nama customer",
+		ERR_CUSTOMER_CODE_EXISTS:           "Customer dengan kode %s sudah terdaftar",
+		ERR_CUSTOMER_NOT_FOUND:             "Customer dengan kode '%s' tidak ditemukan",
+		ERR_CUSTOMER_ID_NOT_FOUND:          "Customer dengan ID %s tidak ditemukan",
+		ERR_CUSTOMER_CREATE:                "Terjadi kesalahan saat melakukan penambahan data customer",
+		ERR_CUSTOMER_UPDATE:                "Terjadi kesalahan saat melakukan pembaruan data customer",
+		ERR_STAKEHOLDER_REQUIRED:           "Harap pilih stakeholder",
+		ERR_STAKEHOLDER_CODE_REQUIRED:      "Harap isi kode stakeholder",
+		ERR_TRANSACTION_TYPE_REQUIRED:      "Harap isi kode tipe transaksi",
+		ERR_DATA_SELECT:                    "Harap pilih data yang akan diperbarui",
+		ERR_BUY_PRICE_REQUIRED:             "Harap isi harga beli",
+		ERR_SELL_PRICE_REQUIRED:            "Harap isi harga jual",
+		ERR_BUY_QUANTITY_REQUIRED:          "Harap isi jumlah beli",
+		ERR_SELL_QUANTITY_REQUIRED:         "Harap isi jumlah jual",
+		ERR_PRICE_CREATE:                   "Ada kesalahan saat menambahkan data harga",
+		ERR_PRICE_UPDATE:                   "Ada kesalahan saat memperbarui harga",
+		ERR_PRICE_CHANGE:                   "Terjadi kesalahan saat melakukan perubahan data harga",
+		ERR_PRICE_FIND:                     "Terjadi kesalahan saat melakukan pencarian data harga",
+		ERR_BUY_PRICE_CREATE:               "Terjadi kesalahan saat menambahkan harga beli",
+		ERR_BUY_PRICE_UPDATE:               "Terjadi kesalahan saat memperbarui harga beli",
+		ERR_TEMPLATE_REQUIRED:              "Harap pilih template",
+		ERR_TEMPLATE_NAME_REQUIRED:         "Harap isi nama template",
+		ERR_TEMPLATE_NAME_EXISTS:           "Template dengan nama %s sudah terdaftar",
+		ERR_TEMPLATE_CREATE:                "Terjadi kesalahan saat melakukan penambahan data template harga",
+		ERR_TEMPLATE_COPY:                  "Terjadi kesalahan saat melakukan duplikasi data template harga",
+		ERR_FIND:                           "Terjadi kesalahan saat melakukan pencarian",
+		ERR_TRANSACTION_REQUIRED:           "Harap pilih transaksi",
+		ERR_TRANSACTION_SELECT:             "Harap pilih transaksi yang akan diperbarui",
+		ERR_TRANSACTION_ADD_SELECT:         "Harap pilih transaksi yang akan ditambahkan",
+		ERR_KONTRABON_TRANSACTION_SELECT:   "Harap pilih transaksi yang akan ditambahkan ke kontrabon",
+		ERR_TRANSACTION_CREATE:             "Terjadi kesalahan saat menambahkan transaksi",
+		ERR_TRANSACTION_PROCESS:            "Terjadi kesalahan saat melakukan transaksi",
+		ERR_TRANSACTION_UPDATE:             "Terjadi kesalahan saat memperbarui transaksi",
+		ERR_TRANSACTION_STATUS_UPDATE:      "Terjadi kesalahan saat melakukan update status transaksi",
+		ERR_TRANSACTION_FIND:               "Terjadi kesalahan saat melakukan pencarian transaksi",
+		ERR_CREDIT_FETCH:                   "Terjadi kesalahan saat mengambil data piutang",
+		ERR_CUSTOMER_REPORT_FETCH:          "Terjadi kesalahan saat mengambil data laporan per customer",
+		ERR_KONTRABON_SELECT:               "Harap pilih kontrabon yang akan diperbarui",
+		ERR_KONTRABON_CREATE:               "Terjadi kesalahan saat pembuatan kontrabon",
+		ERR_KONTRABON_UPDATE:               "Terjadi kesalahan saat melakukan perubahan data kontrabon",
+		ERR_KONTRABON_FIND:                 "Terjadi kesalahan saat melakukan pencarian kontrabon",
+		ERR_STATUS_UPDATE:                  "Terjadi kesalahan saat melakukan perubahan status",
+		ERR_PAYMENT_TOTAL_REQUIRED:         "Harap isi total pembayaran",
+		ERR_PAYMENT_DATE_REQUIRED:          "Harap isi tanggal pembayaran",
+		ERR_DANA_CREATE:                    "Gagal membuat dana",
+		ERR_DANA_SEND:                      "Gagal mengirim dana",
+		ERR_DANA_UPDATE:                    "Gagal mengubah dana",
+		ERR_DANA_REJECT:                    "Gagal menolak pengiriman dana",
+		ERR_DANA_CANCEL:                    "Gagal membatalkan pengiriman dana",
+		ERR_DANA_APPROVE_NOT_PENDING:       "Pengiriman dana sudah tidak dapat diapprove",
+		ERR_DANA_REJECT_NOT_PENDING:        "Pengiriman dana sudah tidak dapat ditolak",
+		ERR_DANA_UPDATE_NOT_ALLOWED:        "Anda tidak diperbolehkan mengubah data ini",
+		ERR_DANA_APPROVE_NOT_ALLOWED:       "Anda tidak diperbolehkan mengubah dana ini",
+		ERR_DANA_REJECT_NOT_ALLOWED:        "Anda tidak diperbolehkan menolak pengiriman dana ini",
+		ERR_DANA_CANCEL_NOT_ALLOWED:        "Anda tidak diperbolehkan membatalkan pengiriman dana ini",
+		ERR_MOBILE_NOT_ALLOWED:             "Anda tidak memiliki akses mobile",
+		ERR_PENJUALAN_CREATE:               "Gagal membuat penjualan",
+		ERR_PENJUALAN_DELETE:               "Gagal menghapus penjualan",
+		ERR_BELANJA_CREATE:                 "Gagal membuat belanja",
+		ERR_BELANJA_DELETE:                 "Gagal menghapus belanja",
+		ERR_OPERASIONAL_CREATE:             "Gagal membuat operasional",
+		ERR_OPERASIONAL_DELETE:             "Gagal menghapus operasional",
+		MONTH_PREFIX + "1":                 "Januari",
+		MONTH_PREFIX + "2":                 "Februari",
+		MONTH_PREFIX + "3":                 "Maret",
+		MONTH_PREFIX + "4":                 "April",
+		MONTH_PREFIX + "5":                 "Mei",
+		MONTH_PREFIX + "6":                 "Juni",
+		MONTH_PREFIX + "7":                 "Juli",
+		MONTH_PREFIX + "8":                 "Agustus",
+		MONTH_PREFIX + "9":                 "September",
+		MONTH_PREFIX + "10":                "Oktober",
+		MONTH_PREFIX + "11":                "November",
+		MONTH_PREFIX + "12":                "Desember",
+	},
+	LANG_EN: {
+		ERR_FETCH_DATA:                                 "Failed to fetch data",
+		ERR_UPDATE_DATA:                                "Failed to update data",
+		ERR_DATE_INVALID:                               "Invalid date",
+		ERR_DATE_REQUIRED:                              "Please fill in the date",
+		ERR_DATE_SELECT:                                "Please select a date",
+		ERR_MONTH_REQUIRED:                             "Please select a month",
+		ERR_MONTH_INVALID:                              "Invalid month",
+		ERR_STATUS_REQUIRED:                            "Please select a status",
+		ERR_STATUS_INVALID:                             "Invalid status",
+		ERR_NAME_REQUIRED:                              "Please fill in the name",
+		ERR_USERNAME_REQUIRED:                          "Please fill in the username",
+		ERR_PASSWORD_REQUIRED:                          "Please fill in the password",
+		ERR_OLD_PASSWORD_REQUIRED:                      "Please fill in the old password",
+		ERR_NEW_PASSWORD_REQUIRED:                      "Please fill in the new password",
+		ERR_REPEAT_PASSWORD_REQUIRED:                   "Please repeat the new password",
+		ERR_REPEAT_PASSWORD_MISMATCH:                   "The repeated new password does not match",
+		ERR_OLD_PASSWORD_MISMATCH:                      "The old password is incorrect",
+		ERR_LANGUAGE_REQUIRED:                          "Please select a language",
+		ERR_LANGUAGE_INVALID:                           "Language is not supported",
+		ERR_LIST_PARAM_INVALID:                         "Invalid %s parameter",
+		ERR_SESSION_NOT_FOUND:                          "Session not found",
+		ERR_SESSION_SELECT:                             "Please select a session",
+		ERR_LOGIN_THROTTLED:                            "Too many login attempts, try again in %d seconds",
+		ERR_USER_LOCKED:                                "User is locked after too many failed logins, try again in %d minutes",
+		ERR_TOTP_CODE_REQUIRED:                         "Verification code is required",
+		ERR_TOTP_INVALID:                               "Invalid verification code",
+		ERR_TOTP_CHALLENGE_INVALID:                     "The login verification is invalid or expired, please log in again",
+		ERR_TOTP_ALREADY_ENABLED:                       "Two-factor authentication is already enabled",
+		ERR_TOTP_NOT_ENABLED:                           "Two-factor authentication is not enabled",
+		ERR_TOTP_SETUP_REQUIRED:                        "Please start the two-factor enrolment first",
+		ERR_TOTP_SETUP:                                 "Failed to set up two-factor authentication",
+		ERR_SERVICE_ACCOUNT_SELECT:                     "Please select a service account",
+		ERR_API_KEY_NAME_REQUIRED:                      "API key name is required",
+		ERR_API_KEY_PERMISSION_INVALID:                 "Permission %s is not granted to the role of the service account",
+		ERR_API_KEY_EXPIRY_INVALID:                     "API key expiry must be in the future",
+		ERR_API_KEY_NOT_FOUND:                          "API key not found",
+		ERR_API_KEY_REVOKED:                            "API key has been revoked",
+		ERR_API_KEY_SAVE:                               "Failed to save API key",
+		ERR_API_KEY_SELECT:                             "Please select an API key",
+		ERR_PASSWORD_TOO_SHORT:                         "Password must be at least %d characters",
+		ERR_PASSWORD_MIXED_CASE:                        "Password must contain upper and lower case letters",
+		ERR_PASSWORD_DIGIT:                             "Password must contain a digit",
+		ERR_PASSWORD_SYMBOL:                            "Password must contain a symbol",
+		ERR_PASSWORD_REUSED:                            "Password cannot be one of the last %d passwords",
+		ERR_CASH_CLOSED:                                "The cash box of %s is already closed",
+		ERR_CASH_CLOSING_NOT_FOUND:                     "The cash box of %s is not closed",
+		ERR_CASH_CLOSING_NEXT_CLOSED:                   "The cash box of %s is already closed, reopen it first",
+		ERR_CASH_CLOSING_REASON_REQUIRED:               "Please fill in the reason to reopen the cash box",
+		ERR_CASH_CLOSING_USER_REQUIRED:                 "Please select the mobile user",
+		ERR_CASH_CLOSING_SAVE:                          "Failed to save the cash closing",
+		ERR_SYNC_TOO_MANY:                              "At most %d records per sync",
+		ERR_SYNC_ID_INVALID:                            "Invalid record id",
+		ERR_SYNC_ID_TAKEN:                              "The record id is already used by another user",
+		ERR_SYNC_TRANSFER_REJECTED:                     "The dana transfer was rejected",
+		ERR_SYNC_TRANSFER_CANCELED:                     "The dana transfer was canceled",
+		ERR_SYNC_SAVE:                                  "Failed to save the synced record",
+		ERR_SYNC_CURSOR_INVALID:                        "Invalid cursor",
+		ERR_ATTACHMENT_TOO_LARGE:                       "File exceeds the size limit of %d KB",
+		ERR_ATTACHMENT_TYPE_INVALID:                    "Unsupported file type, use JPEG, PNG, GIF or PDF",
+		ERR_ATTACHMENT_OWNER_TYPE_INVALID:              "Invalid attachment owner type",
+		ERR_ATTACHMENT_OWNER_NOT_FOUND:                 "Attachment owner not found",
+		ERR_ATTACHMENT_NOT_FOUND:                       "Attachment not found",
+		ERR_ATTACHMENT_FILE_REQUIRED:                   "File is required",
+		ERR_ATTACHMENT_SAVE:                            "Failed to save attachment",
+		ERR_ATTACHMENT_NOT_ALLOWED:                     "Not allowed to access this attachment",
+		ERR_NOTIFICATION_READ:                          "Failed to mark notifications as read",
+		ERR_REKAP_BUCKET_INVALID:                       "Invalid rekapitulasi bucket, use day, week or month",
+		ERR_REKAP_RANGE_INVALID:                        "End date must not be before start date",
+		ERR_REKAP_RANGE_TOO_LONG:                       "Date range is limited to %d days",
+		ERR_EXPORT_FORMAT_INVALID:                      "Unsupported export format, use csv or xlsx",
+		ERR_EXPENSE_CATEGORY_CODE_REQUIRED:             "Please fill in the category code",
+		ERR_EXPENSE_CATEGORY_NAME_REQUIRED:             "Please fill in the category name",
+		ERR_EXPENSE_CATEGORY_SELECT:                    "Please select the category to update",
+		ERR_EXPENSE_CATEGORY_CODE_EXISTS:               "Category with code %s is already registered",
+		ERR_EXPENSE_CATEGORY_INVALID:                   "Expense category not found or inactive",
+		ERR_EXPENSE_CATEGORY_CREATE:                    "Failed to create the expense category",
+		ERR_EXPENSE_CATEGORY_UPDATE:                    "Failed to update the expense category",
+		ERR_EXPENSE_BUDGET_SELECT:                      "Please select the budget",
+		ERR_EXPENSE_BUDGET_PERIOD_INVALID:              "The budget period must be daily or monthly",
+		ERR_EXPENSE_BUDGET_MODE_INVALID:                "The budget mode must be warn or reject",
+		ERR_EXPENSE_BUDGET_AMOUNT_INVALID:              "The budget amount must be greater than 0",
+		ERR_EXPENSE_BUDGET_USER_INVALID:                "The budget user was not found",
+		ERR_EXPENSE_BUDGET_SAVE:                        "Failed to save the budget",
+		ERR_EXPENSE_BUDGET_DELETE:                      "Failed to delete the budget",
+		ERR_EXPENSE_BUDGET_EXCEEDED:                    "The %s budget is exceeded: %.0f of %.0f spent",
+		ERR_RECONCILIATION_APPLY:                       "Failed to apply the average buy price",
+		ERR_SYNC_TRANSFER_EXPIRED:                      "The dana transfer has expired",
+		ERR_DANA_TRANSFER_EXPIRED:                      "The fund transfer has expired",
+		ERR_DANA_TRANSFER_NOT_FOUND:                    "Fund transfer not found",
+		ERR_DANA_BULK_EMPTY:                            "Select the fund transfers",
+		ERR_DANA_BULK_TOO_MANY:                         "At most %d fund transfers at once",
+		ERR_DANA_STATUS_INVALID:                        "Invalid fund transfer status",
+		ERR_BRANCH_CODE_REQUIRED:                       "Branch code is required",
+		ERR_BRANCH_NAME_REQUIRED:                       "Branch name is required",
+		ERR_BRANCH_CODE_EXISTS:                         "Branch code %s is already used",
+		ERR_BRANCH_PREFIX_INVALID:                      "The branch code prefix may only hold letters, digits or dashes, at most 16 characters",
+		ERR_BRANCH_SELECT:                              "Select a branch",
+		ERR_BRANCH_SAVE:                                "Failed to save the branch",
+		ERR_BRANCH_NOT_ASSIGNED:                        "You are not assigned to this branch",
+		ERR_TENANT_NOT_FOUND:                           "Tenant %s was not found",
+		ERR_TENANT_CONNECT:                             "Failed to connect to the database of tenant %s",
+		ERR_TENANT_CODE_INVALID:                        "The tenant code may only hold lowercase letters, digits or dashes, at most 31 characters",
+		ERR_TENANT_SCHEMA_INVALID:                      "The tenant schema name is not valid",
+		ERR_TENANT_EXISTS:                              "Tenant %s is already registered",
+		ERR_TENANT_SCHEMA_EXISTS:                       "Schema %s already exists",
+		ERR_TENANT_PROVISION:                           "Failed to provision tenant %s",
+		ERR_TAX_RATE_INVALID:                           "The tax rate must be between 0 and 100 percent",
+		ERR_DISCOUNT_INVALID:                           "A discount must be a PERCENT between 0 and 100 or an AMOUNT that is not negative",
+		ERR_TRANSACTION_NOT_FOUND:                      "Transaction not found",
+		ERR_NPWP_INVALID:                               "The NPWP must be 15 or 16 digits",
+		ERR_TAX_SERIAL_NOT_SET:                         "The tax invoice serial number range is not set",
+		ERR_TAX_SERIAL_EXHAUSTED:                       "The tax invoice serial numbers have run out, please set a new range",
+		ERR_EFAKTUR_EXPORT:                             "An error occurred while exporting the e-Faktur",
+		ERR_INTERNAL_SERVER:                            "An internal server error occurred",
+		ERR_SYNC_RECORD_MODIFIED:                       "A record with this id is already saved with different content",
+		ERR_TAX_SERIAL_ALLOCATE:                        "An error occurred while giving the tax invoice serial numbers",
+		ERR_TAX_SERIAL_CONFLICT:                        "A transaction was given a tax invoice serial number by another process, please try again",
+		ERR_LOGIN_REQUIRED:                             "Please log in first",
+		ERR_LOGIN_INVALID:                              "Incorrect username or password",
+		ERR_USER_INACTIVE:                              "User is no longer active",
+		ERR_USERNAME_EXISTS:                            "User with username '%s' already exists",
+		ERR_USER_UPDATE:                                "An error occurred while updating user data",
+		ERR_USER_SELECT:                                "Please select the user to edit",
+		ERR_ROLE_REQUIRED:                              "Please select a role",
+		ERR_ROLE_NAME_REQUIRED:                         "Please fill in the role name",
+		ERR_ROLE_NAME_EXISTS:                           "Role with name %s is already registered",
+		ERR_ROLE_FETCH:                                 "An error occurred while fetching role data",
+		ERR_ABILITY_REQUIRED:                           "Please select an ability",
+		ERR_PRODUCT_REQUIRED:                           "Please select a product",
+		ERR_PRODUCT_SELECT:                             "Please select the product to update",
+		ERR_PRODUCT_CODE_REQUIRED:                      "Please fill in the product code",
+		ERR_PRODUCT_NAME_REQUIRED:                      "Please fill in the product name",
+		ERR_PRODUCT_PRICE_REQUIRED:                     "Please fill in the product price",
+		ERR_PRODUCT_CODE_EXISTS:                        "Product with code %s is already registered",
+		ERR_PRODUCT_CREATE:                             "An error occurred while adding product data",
+		ERR_PRODUCT_UPDATE:                             "An error occurred while updating product data",
+		ERR_UNIT_REQUIRED:                              "Please select a unit",
+		ERR_UNIT_SELECT:                                "Please select the unit to update",
+		ERR_UNIT_CODE_REQUIRED:                         "Please fill in the unit code",
+		ERR_UNIT_CODE_EXISTS:                           "Unit with code %s is already registered",
+		ERR_UNIT_CREATE:                                "An error occurred while adding unit data",
+		ERR_UNIT_UPDATE:                                "An error occurred while updating unit data",
+		ERR_SUPPLIER_REQUIRED:                          "Please select a supplier",
+		ERR_SUPPLIER_SELECT:                            "Please select the supplier to update",
+		ERR_SUPPLIER_CODE_REQUIRED:                     "Please fill in the supplier code",
+		ERR_SUPPLIER_NAME_REQUIRED:                     "Please fill in the supplier name",
+		ERR_SUPPLIER_CODE_EXISTS:                       "Supplier with code %s is already registered",
+		ERR_SUPPLIER_NOT_FOUND:                         "Supplier with code '%s' was not found",
+		ERR_SUPPLIER_CREATE:                            "An error occurred while adding supplier data",
+		ERR_SUPPLIER_UPDATE:                            "An error occurred while updating supplier data",
+		ERR_CUSTOMER_REQUIRED:                          "Please select a customer",
+		ERR_CUSTOMER_SELECT:                            "Please select the customer to update",
+		ERR_CUSTOMER_CODE_REQUIRED:                     "Please fill in the customer code",
+		ERR_CUSTOMER_NAME_REQUIRED:                     "Please fill in the customer name",
+		ERR_CUSTOMER_CODE_EXISTS:                       "Customer with code %s is already registered",
+		ERR_CUSTOMER_NOT_FOUND:                         "Customer with code '%s' was not found",
+		ERR_CUSTOMER_ID_NOT_FOUND:                      "Customer with ID %s was not found",
+		ERR_CUSTOMER_CREATE:                            "An error occurred while adding customer data",
+		ERR_CUSTOMER_UPDATE:                            "An error occurred while updating customer data",
+		ERR_STAKEHOLDER_REQUIRED:                       "Please select a stakeholder",
+		ERR_STAKEHOLDER_CODE_REQUIRED:                  "Please fill in the stakeholder code",
+		ERR_TRANSACTION_TYPE_REQUIRED:                  "Please fill in the transaction type code",
+		ERR_DATA_SELECT:                                "Please select the data to update",
+		ERR_BUY_PRICE_REQUIRED:                         "Please fill in the buy price",
+		ERR_SELL_PRICE_REQUIRED:                        "Please fill in the sell price",
+		ERR_BUY_QUANTITY_REQUIRED:                      "Please fill in the buy quantity",
+		ERR_SELL_QUANTITY_REQUIRED:                     "Please fill in the sell quantity",
+		ERR_PRICE_CREATE:                               "An error occurred while adding price data",
+		ERR_PRICE_UPDATE:                               "An error occurred while updating price data",
+		ERR_PRICE_CHANGE:                               "An error occurred while changing price data",
+		ERR_PRICE_FIND:                                 "An error occurred while searching price data",
+		ERR_BUY_PRICE_CREATE:                           "An error occurred while adding the buy price",
+		ERR_BUY_PRICE_UPDATE:                           "An error occurred while updating the buy price",
+		ERR_TEMPLATE_REQUIRED:                          "Please select a template",
+		ERR_TEMPLATE_NAME_REQUIRED:                     "Please fill in the template name",
+		ERR_TEMPLATE_NAME_EXISTS:                       "Template with name %s is already registered",
+		ERR_TEMPLATE_CREATE:                            "An error occurred while adding the price template",
+		ERR_TEMPLATE_COPY:                              "An error occurred while copying the price template",
+		ERR_FIND:                                       "An error occurred while searching",
+		ERR_TRANSACTION_REQUIRED:                       "Please select a transaction",
+		ERR_TRANSACTION_SELECT:                         "Please select the transaction to update",
+		ERR_TRANSACTION_ADD_SELECT:                     "Please select the transaction to add",
+		ERR_KONTRABON_TRANSACTION_SELECT:               "Please select the transaction to add to the kontrabon",
+		ERR_TRANSACTION_CREATE:                         "An error occurred while adding the transaction",
+		ERR_TRANSACTION_PROCESS:                        "An error occurred while processing the transaction",
+		ERR_TRANSACTION_UPDATE:                         "An error occurred while updating the transaction",
+		ERR_TRANSACTION_STATUS_UPDATE:                  "An error occurred while updating the transaction status",
+		ERR_TRANSACTION_FIND:                           "An error occurred while searching transactions",
+		ERR_CREDIT_FETCH:                               "An error occurred while fetching receivables",
+		ERR_CUSTOMER_REPORT_FETCH:                      "An error occurred while fetching the customer report",
+		ERR_KONTRABON_SELECT:                           "Please select the kontrabon to update",
+		ERR_KONTRABON_CREATE:                           "An error occurred while creating the kontrabon",
+		ERR_KONTRABON_UPDATE:                           "An error occurred while updating the kontrabon",
+		ERR_KONTRABON_FIND:                             "An error occurred while searching kontrabon",
+		ERR_STATUS_UPDATE:                              "An error occurred while changing the status",
+		ERR_PAYMENT_TOTAL_REQUIRED:                     "Please fill in the payment total",
+		ERR_PAYMENT_DATE_REQUIRED:                      "Please fill in the payment date",
+		ERR_DANA_CREATE:                                "Failed to create fund",
+		ERR_DANA_SEND:                                  "Failed to send fund",
+		ERR_DANA_UPDATE:                                "Failed to update fund",
+		ERR_DANA_REJECT:                                "Failed to reject the fund transfer",
+		ERR_DANA_CANCEL:                                "Failed to cancel the fund transfer",
+		ERR_DANA_APPROVE_NOT_PENDING:                   "The fund transfer can no longer be approved",
+		ERR_DANA_REJECT_NOT_PENDING:                    "The fund transfer can no longer be rejected",
+		ERR_DANA_UPDATE_NOT_ALLOWED:                    "You are not allowed to change this data",
+		ERR_DANA_APPROVE_NOT_ALLOWED:                   "You are not allowed to change this fund",
+		ERR_DANA_REJECT_NOT_ALLOWED:                    "You are not allowed to reject this fund transfer",
+		ERR_DANA_CANCEL_NOT_ALLOWED:                    "You are not allowed to cancel this fund transfer",
+		ERR_MOBILE_NOT_ALLOWED:                         "You do not have mobile access",
+		ERR_PENJUALAN_CREATE:                           "Failed to create sale",
+		ERR_PENJUALAN_DELETE:                           "Failed to delete sale",
+		ERR_BELANJA_CREATE:                             "Failed to create purchase",
+		ERR_BELANJA_DELETE:                             "Failed to delete purchase",
+		ERR_OPERASIONAL_CREATE:                         "Failed to create operational expense",
+		ERR_OPERASIONAL_DELETE:                         "Failed to delete operational expense",
+		MONTH_PREFIX + "1":                             "January",
+		MONTH_PREFIX + "2":                             "February",
+		MONTH_PREFIX + "3":                             "March",
+		MONTH_PREFIX + "4":                             "April",
+		MONTH_PREFIX + "5":                             "May",
+		MONTH_PREFIX + "6":                             "June",
+		MONTH_PREFIX + "7":                             "July",
+		MONTH_PREFIX + "8":                             "August",
+		MONTH_PREFIX + "9":                             "September",
+		MONTH_PREFIX + "10":                            "October",
+		MONTH_PREFIX + "11":                            "November",
+		MONTH_PREFIX + "12":                            "December",
+		MENU_PREFIX + "web:user":                       "User",
+		MENU_PREFIX + "web:role":                       "Role",
+		MENU_PREFIX + "web:masterdata":                 "Master Data",
+		MENU_PREFIX + "web:price":                      "Price",
+		MENU_PREFIX + "web:transaction":                "Transaction",
+		MENU_PREFIX + "web:report":                     "Report",
+		MENU_PREFIX + "mobile":                         "Mobile",
+		MENU_PREFIX + "web:user:createUser":            "Add Data",
+		MENU_PREFIX + "web:user:editUser":              "Edit Data",
+		MENU_PREFIX + "web:user:session":               "Active Sessions",
+		MENU_PREFIX + "web:user:serviceAccount":        "Service Accounts",
+		MENU_PREFIX + "web:role:createRole":            "Add Data",
+		MENU_PREFIX + "web:role:editRole":              "Edit Data",
+		MENU_PREFIX + "web:masterdata:product":         "Product",
+		MENU_PREFIX + "web:masterdata:supplier":        "Supplier",
+		MENU_PREFIX + "web:masterdata:customer":        "Customer",
+		MENU_PREFIX + "web:price:buy":                  "Buy Price",
+		MENU_PREFIX + "web:price:sell":                 "Sell Price",
+		MENU_PREFIX + "web:price:template":             "Price Template",
+		MENU_PREFIX + "web:price:templatebuy":          "Buy Price Template",
+		MENU_PREFIX + "web:transaction:sell":           "Sales",
+		MENU_PREFIX + "web:transaction:status":         "Status",
+		MENU_PREFIX + "web:transaction:kontrabon":      "Kontrabon",
+		MENU_PREFIX + "web:transaction:buy":            "Purchase",
+		MENU_PREFIX + "web:transaction:report":         "Transaction Report",
+		MENU_PREFIX + "web:transaction:customerCredit": "Receivables Report",
+		MENU_PREFIX + "web:transaction:customerSell":   "Sales Report",
+		MENU_PREFIX + "web:transaction:customerReport": "Customer Report",
+		MENU_PREFIX + "web:transaction:closing":        "Cash Closing",
+	},
+}
@@ -0,0 +1,115 @@
+package i18nutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const LANG_ID = "id"
+const LANG_EN = "en"
+
+const DEFAULT_LANG = LANG_ID
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lang)
+}
+
+// Language returns the language of the request context, or the default language
+func Language(ctx context.Context) string {
+	if ctx != nil {
+		if lang, ok := ctx.Value(ctxKey{}).(string); ok && lang != "" {
+			return lang
+		}
+	}
+	return DEFAULT_LANG
+}
+
+func IsSupported(lang string) bool {
+	_, ok := catalogue[lang]
+	return ok
+}
+
+// T translates a message key into the language of the context
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(Language(ctx), key, args...)
+}
+
+// Translate falls back to the default language, then to the key itself
+func Translate(lang string, key string, args ...interface{}) string {
+	msg, ok := catalogue[lang][key]
+	if !ok {
+		msg, ok = catalogue[DEFAULT_LANG][key]
+	}
+	if !ok {
+		msg = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// MonthName returns the month name, month is 1-12
+func MonthName(ctx context.Context, month int) string {
+	if month < 1 || month > 12 {
+		return ""
+	}
+	return T(ctx, MONTH_PREFIX+strconv.Itoa(month))
+}
+
+// MenuName translates a menu or sub menu by its id and keeps the stored name when there is no translation
+func MenuName(ctx context.Context, id string, name string) string {
+	key := MENU_PREFIX + id
+	if msg, ok := catalogue[Language(ctx)][key]; ok {
+		return msg
+	}
+	return name
+}
+
+// ParseAcceptLanguage picks the supported language with the highest weight from an Accept-Language header
+func ParseAcceptLanguage(header string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "q=") {
+				if v, err := strconv.ParseFloat(field[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		// only the primary subtag matters, en-US and en-GB are both en
+		base := strings.SplitN(tag, "-", 2)[0]
+		langs = append(langs, weighted{lang: base, q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	for _, l := range langs {
+		if l.q > 0 && IsSupported(l.lang) {
+			return l.lang
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package i18nutil
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"single supported", "en", LANG_EN},
+		{"region is ignored", "en-US", LANG_EN},
+		{"case is ignored", "EN-gb", LANG_EN},
+		{"unsupported only", "fr, de;q=0.8", ""},
+		{"first supported by weight", "fr;q=1, en;q=0.5, id;q=0.9", LANG_ID},
+		{"missing weight is 1", "id;q=0.4, en", LANG_EN},
+		{"equal weights keep the header order", "id, en", LANG_ID},
+		{"zero weight is refused", "en;q=0, id;q=0.1", LANG_ID},
+		{"only zero weights", "en;q=0", ""},
+		{"bad weight counts as 1", "id;q=0.5, en;q=abc", LANG_EN},
+		{"blank parts are skipped", " , ,en-US;q=0.7", LANG_EN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); got != tt.want {
+				t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []interface{}
+		want string
+	}{
+		{"english", LANG_EN, ERR_TRANSACTION_NOT_FOUND, nil, "Transaction not found"},
+		{"indonesian", LANG_ID, ERR_TRANSACTION_NOT_FOUND, nil, "Transaksi tidak ditemukan"},
+		{"unknown language falls back to the default", "fr", ERR_TRANSACTION_NOT_FOUND, nil, "Transaksi tidak ditemukan"},
+		{"unknown key is returned as is", LANG_EN, "err.no.such.key", nil, "err.no.such.key"},
+		{"args are formatted", LANG_EN, "err.no.such.key %s", []interface{}{"x"}, "err.no.such.key x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Translate(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("Translate(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	if got := Language(context.Background()); got != DEFAULT_LANG {
+		t.Errorf("Language of an empty context = %q, want %q", got, DEFAULT_LANG)
+	}
+	if got := Language(NewContext(context.Background(), LANG_EN)); got != LANG_EN {
+		t.Errorf("Language = %q, want %q", got, LANG_EN)
+	}
+	if got := Language(NewContext(context.Background(), "")); got != DEFAULT_LANG {
+		t.Errorf("Language of an empty language = %q, want %q", got, DEFAULT_LANG)
+	}
+}
@@ -0,0 +1,96 @@
+package logutil
+
+import (
+	"context"
+	"dromatech/pos-backend/config"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const OUTPUT_FILE = "file"
+const OUTPUT_STDOUT = "stdout"
+const OUTPUT_BOTH = "both"
+
+// RequestInfo is carried in the request context so every layer can log with the same request ID
+type RequestInfo struct {
+	RequestID string
+	UserID    string
+	UserName  string
+}
+
+type ctxKey struct{}
+
+// Setup configures logrus output and rotation from log config
+func Setup(cfg config.LogConfig) {
+	var fileWriter io.Writer
+	if cfg.Output != OUTPUT_STDOUT {
+		fileWriter = &lumberjack.Logger{
+			Filename:   cfg.LogFilename,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+	}
+
+	switch cfg.Output {
+	case OUTPUT_STDOUT:
+		logrus.SetOutput(os.Stdout)
+	case OUTPUT_BOTH:
+		logrus.SetOutput(io.MultiWriter(os.Stdout, fileWriter))
+	default:
+		logrus.SetOutput(fileWriter)
+	}
+
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+}
+
+func NewContext(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, ctxKey{}, info)
+}
+
+func FromContext(ctx context.Context) *RequestInfo {
+	if ctx == nil {
+		return nil
+	}
+	if info, ok := ctx.Value(ctxKey{}).(*RequestInfo); ok {
+		return info
+	}
+	return nil
+}
+
+// WithContext returns a log entry tagged with the request ID and user of the context, if any
+func WithContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	info := FromContext(ctx)
+	if info == nil {
+		return entry
+	}
+
+	fields := logrus.Fields{"request_id": info.RequestID}
+	if info.UserID != "" {
+		fields["user_id"] = info.UserID
+		fields["username"] = info.UserName
+	}
+	return entry.WithFields(fields)
+}
+
+// SanitizeRequestID drops incoming request IDs that are too long or contain unexpected characters
+func SanitizeRequestID(requestID string) string {
+	if len(requestID) == 0 || len(requestID) > 64 {
+		return ""
+	}
+	valid := strings.IndexFunc(requestID, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+	}) < 0
+	if !valid {
+		return ""
+	}
+	return requestID
+}
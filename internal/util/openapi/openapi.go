@@ -0,0 +1,368 @@
+package openapiutil
+
+import (
+	queryutil "dromatech/pos-backend/internal/util/query"
+	restutil "dromatech/pos-backend/internal/util/rest"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const VERSION = "3.0.3"
+
+const SECURITY_TOKEN = "token"
+const SECURITY_API_KEY = "apiKey"
+
+// Route describes one route of the router for the specification
+type Route struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Query lists the query parameters read by the handler
+	Query []string
+	// Body is a value of the request body type, or Fields for bodies read field by field
+	Body interface{}
+	// Form describes a multipart/form-data body, file fields are given as Binary
+	Form Fields
+	// Response is a value of the type sent as data of the response envelope
+	Response interface{}
+	// SortFields marks a list endpoint and lists the fields it can be sorted by
+	SortFields []string
+	// Public routes do not need a session token
+	Public bool
+	// Raw routes do not answer with the response envelope
+	Raw bool
+}
+
+// Fields describes a request body that is read field by field, keyed by json field name
+type Fields map[string]interface{}
+
+// Binary marks a file field of a multipart form
+type Binary struct{}
+
+var binaryType = reflect.TypeOf(Binary{})
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// Builder collects routes into a document, registering every struct it meets as a component schema
+type Builder struct {
+	doc *Document
+}
+
+func New(title, version, description string) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI: VERSION,
+			Info: Info{
+				Title:       title,
+				Version:     version,
+				Description: description,
+			},
+			Paths: map[string]*PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+				SecuritySchemes: map[string]*SecurityScheme{
+					SECURITY_TOKEN:   {Type: "apiKey", In: "header", Name: "token"},
+					SECURITY_API_KEY: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				},
+			},
+		},
+	}
+}
+
+func (b *Builder) Add(route Route) {
+	op := &Operation{
+		Summary:     route.Summary,
+		OperationID: operationID(route.Method, route.Path),
+		Responses:   map[string]*Response{},
+		Security:    []map[string][]string{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if !route.Public {
+		// either scheme authenticates, a session token of a user or an api key of a service account
+		op.Security = append(op.Security, map[string][]string{SECURITY_TOKEN: {}}, map[string][]string{SECURITY_API_KEY: {}})
+	}
+
+	for _, name := range pathParams(route.Path) {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, name := range route.Query {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+	}
+	if route.SortFields != nil {
+		op.Parameters = append(op.Parameters, listParams(route.SortFields)...)
+	}
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: b.schemaOf(route.Body)}},
+		}
+	}
+	if route.Form != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"multipart/form-data": {Schema: b.schemaOf(route.Form)}},
+		}
+	}
+
+	if route.Raw {
+		op.Responses["200"] = &Response{Description: "OK"}
+	} else {
+		op.Responses["200"] = &Response{
+			Description: "Response envelope, status 0 on success and 1 on failure",
+			Content:     map[string]*MediaType{"application/json": {Schema: b.envelope(route)}},
+		}
+	}
+	if !route.Public {
+		op.Responses["401"] = &Response{Description: "Missing or expired session token or api key"}
+		op.Responses["403"] = &Response{Description: "The role of the session has no access to the route"}
+	}
+
+	path := Path(route.Path)
+	item, exists := b.doc.Paths[path]
+	if !exists {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	(*item)[strings.ToLower(route.Method)] = op
+}
+
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+// Path converts a gin route path to an OpenAPI path, e.g. /api/docs/*any to /api/docs/{any}
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(ginPath string) []string {
+	var names []string
+	for _, segment := range strings.Split(ginPath, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+		}
+	}
+	return names
+}
+
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/"), "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+func listParams(sortFields []string) []*Parameter {
+	return []*Parameter{
+		{Name: "q", In: "query", Schema: &Schema{Type: "string"}},
+		{Name: "page", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "limit", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "cursor", In: "query", Schema: &Schema{Type: "string"}},
+		{Name: "sort", In: "query", Schema: &Schema{Type: "string", Enum: sortFields}},
+		{Name: "order", In: "query", Schema: &Schema{Type: "string", Enum: []string{"asc", "desc"}}},
+	}
+}
+
+func (b *Builder) envelope(route Route) *Schema {
+	data := &Schema{Nullable: true}
+	if route.Response != nil {
+		data = b.schemaOf(route.Response)
+	}
+
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "integer"},
+			"message": {Type: "string"},
+			"code":    {Type: "string"},
+			"errors":  {Type: "array", Items: b.schemaOf(restutil.FieldError{})},
+			"data":    data,
+		},
+	}
+	if route.SortFields != nil {
+		schema.Properties["meta"] = b.schemaOf(queryutil.PageMeta{})
+	}
+	return schema
+}
+
+func (b *Builder) schemaOf(value interface{}) *Schema {
+	if fields, ok := value.(Fields); ok {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for name, field := range fields {
+			schema.Properties[name] = b.schemaOf(field)
+		}
+		return schema
+	}
+	return b.schemaOfType(reflect.TypeOf(value))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (b *Builder) schemaOfType(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t == binaryType {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.schemaOfType(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		name := t.String()
+		if _, exists := b.doc.Components.Schemas[name]; !exists {
+			schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			// registered before walking the fields so self references terminate
+			b.doc.Components.Schemas[name] = schema
+			b.addProperties(schema, t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (b *Builder) addProperties(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.addProperties(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = b.schemaOfType(field.Type)
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+// Has reports whether the document describes the gin route
+func (d *Document) Has(method, ginPath string) bool {
+	item, exists := d.Paths[Path(ginPath)]
+	if !exists {
+		return false
+	}
+	_, exists = (*item)[strings.ToLower(method)]
+	return exists
+}
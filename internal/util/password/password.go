@@ -0,0 +1,64 @@
+package passwordutil
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"unicode"
+	"unicode/utf8"
+)
+
+const VIOLATION_LENGTH = "LENGTH"
+const VIOLATION_MIXED_CASE = "MIXED_CASE"
+const VIOLATION_DIGIT = "DIGIT"
+const VIOLATION_SYMBOL = "SYMBOL"
+
+// Policy is the password policy read from config, zero values disable a rule
+type Policy struct {
+	MinLength        int
+	RequireMixedCase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// HistoryCount is how many previous passwords cannot be used again
+	HistoryCount int
+	// ExpiryDay is how many days a password is valid before it has to be changed
+	ExpiryDay int
+}
+
+// Hash hashes the password with the salt of the user
+func Hash(password, salt string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(password + salt))
+	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+// Check returns the first rule the password violates, empty when it complies
+func (p Policy) Check(password string) string {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return VIOLATION_LENGTH
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	if p.RequireMixedCase && !(upper && lower) {
+		return VIOLATION_MIXED_CASE
+	}
+	if p.RequireDigit && !digit {
+		return VIOLATION_DIGIT
+	}
+	if p.RequireSymbol && !symbol {
+		return VIOLATION_SYMBOL
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package passwordutil
+
+import "testing"
+
+func TestPolicyCheck(t *testing.T) {
+	strict := Policy{MinLength: 8, RequireMixedCase: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		want     string
+	}{
+		{"empty policy takes anything", Policy{}, "", ""},
+		{"too short", Policy{MinLength: 8}, "Ab1!", VIOLATION_LENGTH},
+		{"length counts runes not bytes", Policy{MinLength: 4}, "ééé", VIOLATION_LENGTH},
+		{"long enough in runes", Policy{MinLength: 3}, "ééé", ""},
+		{"complies with everything", strict, "Abcdef1!", ""},
+		{"length is checked first", strict, "abc", VIOLATION_LENGTH},
+		{"lower case only", strict, "abcdefg1!", VIOLATION_MIXED_CASE},
+		{"upper case only", strict, "ABCDEFG1!", VIOLATION_MIXED_CASE},
+		{"no digit", strict, "Abcdefgh!", VIOLATION_DIGIT},
+		{"no symbol", strict, "Abcdefg12", VIOLATION_SYMBOL},
+		{"space is a symbol", strict, "Abcd efg1", ""},
+		{"digit only rule", Policy{RequireDigit: true}, "password", VIOLATION_DIGIT},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Check(tt.password); got != tt.want {
+				t.Errorf("Check(%q) = %q, want %q", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := Hash("secret", "salt")
+	if hash != Hash("secret", "salt") {
+		t.Error("the same password and salt hash differently")
+	}
+	if hash == Hash("secret", "pepper") {
+		t.Error("another salt gives the same hash")
+	}
+	if hash == Hash("Secret", "salt") {
+		t.Error("another password gives the same hash")
+	}
+}
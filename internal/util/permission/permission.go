@@ -0,0 +1,95 @@
+package permissionutil
+
+import (
+	"strings"
+)
+
+const ANY_METHOD = "*"
+const WILDCARD = "/*"
+
+// Rule grants a method on a path pattern, the path is a gin route path or a prefix ending in /*
+type Rule struct {
+	Method string
+	Path   string
+}
+
+// Matches reports whether the rule grants the method on the gin route path
+func (r Rule) Matches(method, path string) bool {
+	if r.Method != ANY_METHOD && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if strings.HasSuffix(r.Path, WILDCARD) {
+		return strings.HasPrefix(path, strings.TrimSuffix(r.Path, "*"))
+	}
+	return r.Path == path
+}
+
+// Matcher answers whether a set of rules grants a request, exact paths are looked up by key
+type Matcher struct {
+	exact    map[string]bool
+	prefixes []Rule
+	// within limits the matcher to what another matcher allows as well
+	within *Matcher
+}
+
+func NewMatcher(rules []Rule) *Matcher {
+	matcher := &Matcher{
+		exact: make(map[string]bool),
+	}
+	for _, rule := range rules {
+		rule.Method = strings.ToUpper(strings.TrimSpace(rule.Method))
+		rule.Path = strings.TrimSpace(rule.Path)
+		if rule.Path == "" {
+			continue
+		}
+		if rule.Method == "" {
+			rule.Method = ANY_METHOD
+		}
+
+		if strings.HasSuffix(rule.Path, WILDCARD) {
+			matcher.prefixes = append(matcher.prefixes, rule)
+		} else {
+			matcher.exact[key(rule.Method, rule.Path)] = true
+		}
+	}
+	return matcher
+}
+
+// Allowed reports whether any rule grants the method on the gin route path
+func (m *Matcher) Allowed(method, path string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.within != nil && !m.within.Allowed(method, path) {
+		return false
+	}
+
+	method = strings.ToUpper(method)
+	if m.exact[key(method, path)] || m.exact[key(ANY_METHOD, path)] {
+		return true
+	}
+	for _, rule := range m.prefixes {
+		if rule.Matches(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Within returns a matcher allowing only what both m and other allow, other is not copied
+func (m *Matcher) Within(other *Matcher) *Matcher {
+	if m == nil {
+		return nil
+	}
+	restricted := *m
+	restricted.within = other
+	if other == nil {
+		restricted.within = &Matcher{}
+	}
+	return &restricted
+}
+
+func key(method, path string) string {
+	return method + " " + path
+}
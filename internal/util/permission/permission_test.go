@@ -0,0 +1,82 @@
+package permissionutil
+
+import "testing"
+
+func TestMatcherAllowed(t *testing.T) {
+	matcher := NewMatcher([]Rule{
+		{Method: "get", Path: "/api/product/find"},
+		{Method: "", Path: "/api/auth/check"},
+		{Method: "POST", Path: " /api/report/* "},
+		{Method: "*", Path: "/api/docs/*"},
+		{Method: "GET", Path: ""},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{"exact path and method", "GET", "/api/product/find", true},
+		{"method is not case sensitive", "get", "/api/product/find", true},
+		{"other method on an exact path", "POST", "/api/product/find", false},
+		{"empty method grants any method", "DELETE", "/api/auth/check", true},
+		{"prefix with its method", "POST", "/api/report/daily", true},
+		{"prefix with its method deeper", "POST", "/api/report/daily/export", true},
+		{"prefix with another method", "GET", "/api/report/daily", false},
+		{"prefix does not grant its parent", "POST", "/api/report", false},
+		{"prefix does not grant a longer sibling", "POST", "/api/reports/x", false},
+		{"any method prefix", "PUT", "/api/docs/openapi.json", true},
+		{"not granted", "GET", "/api/user/find", false},
+		{"blank rule grants nothing", "GET", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.Allowed(tt.method, tt.path); got != tt.want {
+				t.Errorf("Allowed(%s, %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherWithin(t *testing.T) {
+	role := NewMatcher([]Rule{
+		{Method: "GET", Path: "/api/product/find"},
+		{Method: "POST", Path: "/api/product/create"},
+		{Method: "GET", Path: "/api/transaction/*"},
+	})
+	scopes := NewMatcher([]Rule{
+		{Method: "GET", Path: "/api/product/find"},
+		{Method: "GET", Path: "/api/transaction/find"},
+		{Method: "GET", Path: "/api/user/find"},
+	})
+
+	tests := []struct {
+		name    string
+		matcher *Matcher
+		method  string
+		path    string
+		want    bool
+	}{
+		{"allowed by both", role.Within(scopes), "GET", "/api/product/find", true},
+		{"allowed by the role only", role.Within(scopes), "POST", "/api/product/create", false},
+		{"allowed by the scopes only", role.Within(scopes), "GET", "/api/user/find", false},
+		{"prefix of the role and exact scope", role.Within(scopes), "GET", "/api/transaction/find", true},
+		{"within nil allows nothing", role.Within(nil), "GET", "/api/product/find", false},
+		{"nil matcher allows nothing", (*Matcher)(nil), "GET", "/api/product/find", false},
+		{"nil within stays nil", (*Matcher)(nil).Within(scopes), "GET", "/api/product/find", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.Allowed(tt.method, tt.path); got != tt.want {
+				t.Errorf("Allowed(%s, %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if !role.Allowed("POST", "/api/product/create") {
+		t.Error("Within changed the matcher it was called on")
+	}
+}
@@ -0,0 +1,222 @@
+package queryutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const DEFAULT_LIMIT = 20
+const MAX_LIMIT = 500
+
+const ORDER_ASC = "asc"
+const ORDER_DESC = "desc"
+
+// ListParam carries paging, sorting and search of a list endpoint. A zero value means everything, unsorted by the caller.
+type ListParam struct {
+	Search string
+	Page   int
+	Limit  int
+	Offset int
+	Cursor bool
+	Sort   string
+	Desc   bool
+}
+
+type PageMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ParamError tells which list parameter is invalid
+type ParamError struct {
+	Field string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid list parameter %s", e.Field)
+}
+
+// ParseListParam reads q, page, limit, cursor, sort and order. The sort field must be one of sortFields.
+func ParseListParam(get func(key string) string, sortFields ...string) (ListParam, error) {
+	list := ListParam{
+		Search: strings.TrimSpace(get("q")),
+	}
+
+	if limit := get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return list, &ParamError{Field: "limit"}
+		}
+		list.Limit = l
+	}
+	if list.Limit > MAX_LIMIT {
+		list.Limit = MAX_LIMIT
+	}
+
+	if cursor := get("cursor"); cursor != "" {
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return list, &ParamError{Field: "cursor"}
+		}
+		list.Cursor = true
+		list.Offset = offset
+	} else if page := get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil || p < 1 {
+			return list, &ParamError{Field: "page"}
+		}
+		list.Page = p
+	}
+
+	if (list.Page > 0 || list.Cursor) && list.Limit == 0 {
+		list.Limit = DEFAULT_LIMIT
+	}
+	if list.Page > 0 {
+		list.Offset = (list.Page - 1) * list.Limit
+	}
+
+	if sort := get("sort"); sort != "" {
+		valid := false
+		for _, field := range sortFields {
+			if field == sort {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return list, &ParamError{Field: "sort"}
+		}
+		list.Sort = sort
+	}
+
+	switch strings.ToLower(get("order")) {
+	case "", ORDER_ASC:
+	case ORDER_DESC:
+		list.Desc = true
+	default:
+		return list, &ParamError{Field: "order"}
+	}
+
+	return list, nil
+}
+
+// Paged tells whether the caller asked for a window instead of every row
+func (l ListParam) Paged() bool {
+	return l.Limit > 0
+}
+
+// OrderBy maps the requested sort field to its column, defaultOrder is used when nothing was requested
+func (l ListParam) OrderBy(columns map[string]string, defaultOrder string) string {
+	column, ok := columns[l.Sort]
+	if !ok {
+		return "ORDER BY " + defaultOrder
+	}
+
+	direction := "ASC"
+	if l.Desc {
+		direction = "DESC"
+	}
+	// keep the default order as tie breaker so pages are stable
+	return fmt.Sprintf("ORDER BY %s %s, %s", column, direction, defaultOrder)
+}
+
+func (l ListParam) LimitOffset() string {
+	if !l.Paged() {
+		return ""
+	}
+	return fmt.Sprintf("LIMIT %d OFFSET %d", l.Limit, l.Offset)
+}
+
+func (l ListParam) Meta(total int64, count int) *PageMeta {
+	meta := &PageMeta{
+		Page:  l.Page,
+		Limit: l.Limit,
+		Total: total,
+	}
+	if l.Cursor && int64(l.Offset+count) < total {
+		meta.NextCursor = encodeCursor(l.Offset + count)
+	}
+	return meta
+}
+
+// Where builds the WHERE clause of params, Logic defaults to AND
+func Where(params []Param) (string, []interface{}) {
+	where := ""
+	var values []interface{}
+	for _, param := range params {
+		if where != "" {
+			logic := "AND "
+			if param.Logic != "" {
+				logic = param.Logic + " "
+			}
+			where += logic
+		}
+		where += param.Field + " " + param.Operator + " ? "
+		values = append(values, param.Value)
+	}
+
+	if where != "" {
+		where = "WHERE " + where
+	}
+	return where, values
+}
+
+// Search adds a case-insensitive partial match of search against any of fields to a WHERE clause.
+// The existing clause is parenthesized so an OR in it cannot bypass the search.
+func Search(where string, values []interface{}, search string, fields ...string) (string, []interface{}) {
+	if search == "" || len(fields) == 0 {
+		return where, values
+	}
+
+	var conditions []string
+	for _, field := range fields {
+		conditions = append(conditions, field+" ILIKE ?")
+		values = append(values, Contains(search))
+	}
+
+	clause := "(" + strings.Join(conditions, " OR ") + ") "
+	if where == "" {
+		return "WHERE " + clause, values
+	}
+	return "WHERE (" + strings.TrimPrefix(where, "WHERE ") + ") AND " + clause, values
+}
+
+// Contains wraps value for an ILIKE partial match, escaping the LIKE wildcards
+func Contains(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(value) + "%"
+}
+
+// FromMap turns equality filters into params
+func FromMap(params map[string]interface{}) []Param {
+	var result []Param
+	for key, value := range params {
+		result = append(result, Param{
+			Logic:    "AND",
+			Field:    key,
+			Operator: "=",
+			Value:    value,
+		})
+	}
+	return result
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, nil
+}
@@ -0,0 +1,164 @@
+package queryutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func query(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestParseListParam(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    map[string]string
+		want     ListParam
+		badField string
+	}{
+		{"nothing asked is everything", map[string]string{}, ListParam{}, ""},
+		{"search is trimmed", map[string]string{"q": "  abc "}, ListParam{Search: "abc"}, ""},
+		{"page takes the default limit", map[string]string{"page": "3"}, ListParam{Page: 3, Limit: DEFAULT_LIMIT, Offset: 2 * DEFAULT_LIMIT}, ""},
+		{"page and limit", map[string]string{"page": "2", "limit": "10"}, ListParam{Page: 2, Limit: 10, Offset: 10}, ""},
+		{"limit alone", map[string]string{"limit": "5"}, ListParam{Limit: 5}, ""},
+		{"limit is capped", map[string]string{"limit": "100000"}, ListParam{Limit: MAX_LIMIT}, ""},
+		{"cursor wins over page", map[string]string{"cursor": encodeCursor(40), "page": "9", "limit": "20"}, ListParam{Cursor: true, Limit: 20, Offset: 40}, ""},
+		{"sort and order", map[string]string{"sort": "name", "order": "DESC"}, ListParam{Sort: "name", Desc: true}, ""},
+		{"ascending order", map[string]string{"sort": "code", "order": "asc"}, ListParam{Sort: "code"}, ""},
+		{"zero limit", map[string]string{"limit": "0"}, ListParam{}, "limit"},
+		{"text limit", map[string]string{"limit": "ten"}, ListParam{}, "limit"},
+		{"zero page", map[string]string{"page": "0"}, ListParam{}, "page"},
+		{"bad cursor", map[string]string{"cursor": "!!"}, ListParam{}, "cursor"},
+		{"negative cursor", map[string]string{"cursor": encodeCursor(-1)}, ListParam{}, "cursor"},
+		{"unknown sort", map[string]string{"sort": "password"}, ListParam{}, "sort"},
+		{"unknown order", map[string]string{"order": "sideways"}, ListParam{}, "order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseListParam(query(tt.query), "code", "name")
+			if tt.badField != "" {
+				var paramErr *ParamError
+				if !errors.As(err, &paramErr) || paramErr.Field != tt.badField {
+					t.Fatalf("error = %v, want a ParamError on %s", err, tt.badField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseListParam = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListParamOrderBy(t *testing.T) {
+	columns := map[string]string{"name": "p.name"}
+	tests := []struct {
+		name string
+		list ListParam
+		want string
+	}{
+		{"default", ListParam{}, "ORDER BY p.code"},
+		{"unknown field keeps the default", ListParam{Sort: "other"}, "ORDER BY p.code"},
+		{"ascending with tie breaker", ListParam{Sort: "name"}, "ORDER BY p.name ASC, p.code"},
+		{"descending with tie breaker", ListParam{Sort: "name", Desc: true}, "ORDER BY p.name DESC, p.code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.list.OrderBy(columns, "p.code"); got != tt.want {
+				t.Errorf("OrderBy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListParamLimitOffsetAndMeta(t *testing.T) {
+	if got := (ListParam{}).LimitOffset(); got != "" {
+		t.Errorf("LimitOffset of an unpaged list = %q, want empty", got)
+	}
+	if got := (ListParam{Limit: 10, Offset: 30}).LimitOffset(); got != "LIMIT 10 OFFSET 30" {
+		t.Errorf("LimitOffset = %q", got)
+	}
+
+	tests := []struct {
+		name       string
+		list       ListParam
+		total      int64
+		count      int
+		nextCursor string
+	}{
+		{"page has no cursor", ListParam{Page: 1, Limit: 10}, 50, 10, ""},
+		{"cursor with more rows", ListParam{Cursor: true, Limit: 10, Offset: 10}, 50, 10, encodeCursor(20)},
+		{"cursor on the last rows", ListParam{Cursor: true, Limit: 10, Offset: 40}, 50, 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := tt.list.Meta(tt.total, tt.count)
+			if meta.NextCursor != tt.nextCursor || meta.Total != tt.total || meta.Limit != tt.list.Limit {
+				t.Errorf("Meta = %+v, want next cursor %q", meta, tt.nextCursor)
+			}
+		})
+	}
+}
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     []Param
+		wantWhere  string
+		wantValues []interface{}
+	}{
+		{"no params", nil, "", nil},
+		{"one param", []Param{{Field: "code", Operator: "=", Value: "A"}}, "WHERE code = ? ", []interface{}{"A"}},
+		{"logic defaults to AND", []Param{{Field: "a", Operator: "=", Value: 1}, {Field: "b", Operator: ">", Value: 2}},
+			"WHERE a = ? AND b > ? ", []interface{}{1, 2}},
+		{"explicit logic", []Param{{Field: "a", Operator: "=", Value: 1}, {Logic: "OR", Field: "b", Operator: "=", Value: 2}},
+			"WHERE a = ? OR b = ? ", []interface{}{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, values := Where(tt.params)
+			if where != tt.wantWhere || !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Where = %q %v, want %q %v", where, values, tt.wantWhere, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		where      string
+		values     []interface{}
+		search     string
+		fields     []string
+		wantWhere  string
+		wantValues []interface{}
+	}{
+		{"empty search", "WHERE a = ? ", []interface{}{1}, "", []string{"code"}, "WHERE a = ? ", []interface{}{1}},
+		{"no fields", "", nil, "x", nil, "", nil},
+		{"without a where", "", nil, "ab", []string{"code", "name"},
+			"WHERE (code ILIKE ? OR name ILIKE ?) ", []interface{}{"%ab%", "%ab%"}},
+		{"added to a where", "WHERE a = ? ", []interface{}{1}, "ab", []string{"code"},
+			"WHERE (a = ? ) AND (code ILIKE ?) ", []interface{}{1, "%ab%"}},
+		{"added to a where with an OR", "WHERE a = ? OR b = ? ", []interface{}{1, 2}, "ab", []string{"code"},
+			"WHERE (a = ? OR b = ? ) AND (code ILIKE ?) ", []interface{}{1, 2, "%ab%"}},
+		{"wildcards are escaped", "", nil, `50%_\`, []string{"code"},
+			"WHERE (code ILIKE ?) ", []interface{}{`%50\%\_\\%`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, values := Search(tt.where, tt.values, tt.search, tt.fields...)
+			if where != tt.wantWhere || !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Search = %q %v, want %q %v", where, values, tt.wantWhere, tt.wantValues)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package restutil
+
+import (
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorModeHeader lets a client opt in to real 4xx/5xx statuses instead of the legacy HTTP 200 envelope
+const ErrorModeHeader = "X-Error-Mode"
+const ERROR_MODE_HTTP = "http"
+
+const ERR_BAD_REQUEST = "BAD_REQUEST"
+const ERR_VALIDATION = "VALIDATION_ERROR"
+const ERR_UNAUTHORIZED = "UNAUTHORIZED"
+const ERR_FORBIDDEN = "FORBIDDEN"
+const ERR_NOT_FOUND = "NOT_FOUND"
+const ERR_CONFLICT = "CONFLICT"
+const ERR_TOO_MANY_REQUESTS = "TOO_MANY_REQUESTS"
+const ERR_INTERNAL = "INTERNAL_ERROR"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is returned by usecases so handlers can answer with a proper code and status
+type Error struct {
+	Code       string
+	HTTPStatus int
+	Message    string
+	Details    []FieldError
+	Cause      error
+	// RetryAfter is sent as Retry-After header when set
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+func NewError(code string, httpStatus int, message string) *Error {
+	return &Error{
+		Code:       code,
+		HTTPStatus: httpStatus,
+		Message:    message,
+	}
+}
+
+func ErrBadRequest(message string) *Error {
+	return NewError(ERR_BAD_REQUEST, http.StatusBadRequest, message)
+}
+
+func ErrValidation(message string, details ...FieldError) *Error {
+	err := NewError(ERR_VALIDATION, http.StatusUnprocessableEntity, message)
+	err.Details = details
+	return err
+}
+
+// ErrRequired is a validation error for a single missing field
+func ErrRequired(field string, message string) *Error {
+	return ErrValidation(message, FieldError{Field: field, Message: message})
+}
+
+func ErrUnauthorized(message string) *Error {
+	return NewError(ERR_UNAUTHORIZED, http.StatusUnauthorized, message)
+}
+
+func ErrForbidden(message string) *Error {
+	return NewError(ERR_FORBIDDEN, http.StatusForbidden, message)
+}
+
+func ErrNotFound(message string) *Error {
+	return NewError(ERR_NOT_FOUND, http.StatusNotFound, message)
+}
+
+func ErrConflict(message string) *Error {
+	return NewError(ERR_CONFLICT, http.StatusConflict, message)
+}
+
+func ErrTooManyRequests(message string, retryAfter time.Duration) *Error {
+	err := NewError(ERR_TOO_MANY_REQUESTS, http.StatusTooManyRequests, message)
+	err.RetryAfter = retryAfter
+	return err
+}
+
+func ErrInternal(message string, cause error) *Error {
+	err := NewError(ERR_INTERNAL, http.StatusInternalServerError, message)
+	err.Cause = cause
+	return err
+}
+
+// SendError maps any error to the response envelope. Untyped errors are treated as internal errors, their message
+// may come from the database or a driver so it is logged and the client gets the generic one.
+func SendError(c *gin.Context, err error) {
+	var restErr *Error
+	if !errors.As(err, &restErr) {
+		logutil.WithContext(c.Request.Context()).Error(err.Error())
+		restErr = ErrInternal(i18nutil.T(c.Request.Context(), i18nutil.ERR_INTERNAL_SERVER), err)
+	}
+
+	if restErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(restErr.RetryAfter.Seconds()))))
+	}
+
+	httpStatus := http.StatusOK
+	if strings.EqualFold(c.GetHeader(ErrorModeHeader), ERROR_MODE_HTTP) {
+		httpStatus = restErr.HTTPStatus
+	}
+
+	response := CreateResponse(1, restErr.Message, nil)
+	response.Code = restErr.Code
+	response.Errors = restErr.Details
+	c.JSON(httpStatus, response)
+}
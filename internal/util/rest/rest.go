@@ -3,15 +3,21 @@ package restutil
 import (
 	"dromatech/pos-backend/global"
 	sessiondomain "dromatech/pos-backend/internal/domain/session"
+	i18nutil "dromatech/pos-backend/internal/util/i18n"
+	logutil "dromatech/pos-backend/internal/util/log"
+	queryutil "dromatech/pos-backend/internal/util/query"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
 type Response struct {
-	Status  int         `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    interface{}         `json:"data"`
+	Code    string              `json:"code,omitempty"`
+	Errors  []FieldError        `json:"errors,omitempty"`
+	Meta    *queryutil.PageMeta `json:"meta,omitempty"`
 }
 
 func CreateResponse(status int, message string, data interface{}) Response {
@@ -37,8 +43,27 @@ func SendResponseOk(c *gin.Context, msg string, data interface{}) {
 	c.JSON(http.StatusOK, CreateResponseOk(msg, data))
 }
 
+// SendResponseList sends a list with its paging meta, data stays the plain list for current frontends
+func SendResponseList(c *gin.Context, msg string, data interface{}, meta *queryutil.PageMeta) {
+	response := CreateResponseOk(msg, data)
+	response.Meta = meta
+	c.JSON(http.StatusOK, response)
+}
+
+// GetListParam parses the list query of the request, on invalid input the error response is already sent
+func GetListParam(c *gin.Context, sortFields ...string) (queryutil.ListParam, bool) {
+	list, err := queryutil.ParseListParam(c.Query, sortFields...)
+	if err != nil {
+		field := err.(*queryutil.ParamError).Field
+		msg := i18nutil.T(c.Request.Context(), i18nutil.ERR_LIST_PARAM_INVALID, field)
+		SendError(c, ErrValidation(msg, FieldError{Field: field, Message: msg}))
+		return list, false
+	}
+	return list, true
+}
+
 func SendResponseFail(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, CreateResponse(1, msg, nil))
+	SendError(c, ErrBadRequest(msg))
 }
 
 func RedirectToLogin(c *gin.Context) {
@@ -61,6 +86,12 @@ func CloseContext(c *gin.Context) {
 
 func SetSession(c *gin.Context, session *sessiondomain.Session) {
 	c.Set("session", session)
+
+	// attribute the request log to the session user
+	if info := logutil.FromContext(c.Request.Context()); info != nil && session != nil {
+		info.UserID = session.UserID
+		info.UserName = session.UserName
+	}
 }
 
 func GetSession(c *gin.Context) *sessiondomain.Session {
@@ -0,0 +1,95 @@
+package scopeutil
+
+import (
+	"context"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// NewContext restricts the data read with ctx to the customers and suppliers assigned to the user
+func NewContext(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, userID)
+}
+
+// UserID returns the user the data is restricted to, false when ctx may read all data
+func UserID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	userID, ok := ctx.Value(ctxKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// Condition returns the restriction of column, holding a customer or supplier id, to the assignments of the user in ctx.
+// It is empty when ctx may read all data.
+func Condition(ctx context.Context, column string) (string, []interface{}) {
+	userID, ok := UserID(ctx)
+	if !ok {
+		return "", nil
+	}
+	return column + " IN (SELECT stakeholder_id FROM web_user_stakeholder WHERE web_user_id = ?) ", []interface{}{userID}
+}
+
+// Where adds the restriction of Condition to a WHERE clause built by queryutil.Where.
+// The existing clause is parenthesized so an OR in it cannot bypass the restriction.
+func Where(ctx context.Context, where string, values []interface{}, column string) (string, []interface{}) {
+	condition, conditionValues := Condition(ctx, column)
+	if condition == "" {
+		return where, values
+	}
+	if where == "" {
+		where = "WHERE " + condition
+	} else {
+		where = "WHERE (" + strings.TrimPrefix(where, "WHERE ") + ") AND " + condition
+	}
+	return where, append(values, conditionValues...)
+}
+
+type branchKey struct{}
+
+// NewBranchContext restricts the branch scoped data read and written with ctx to the branch
+func NewBranchContext(ctx context.Context, branchID string) context.Context {
+	return context.WithValue(ctx, branchKey{}, branchID)
+}
+
+// BranchID returns the branch the data is restricted to, false when ctx reads every branch
+func BranchID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	branchID, ok := ctx.Value(branchKey{}).(string)
+	return branchID, ok && branchID != ""
+}
+
+// BranchCondition returns the restriction of column, holding a branch id, to the branch in ctx.
+// It is empty when ctx reads every branch.
+func BranchCondition(ctx context.Context, column string) (string, []interface{}) {
+	branchID, ok := BranchID(ctx)
+	if !ok {
+		return "", nil
+	}
+	return column + " = ? ", []interface{}{branchID}
+}
+
+// BranchWhere adds the restriction of BranchCondition to a WHERE clause the same way Where does
+func BranchWhere(ctx context.Context, where string, values []interface{}, column string) (string, []interface{}) {
+	condition, conditionValues := BranchCondition(ctx, column)
+	if condition == "" {
+		return where, values
+	}
+	if where == "" {
+		where = "WHERE " + condition
+	} else {
+		where = "WHERE (" + strings.TrimPrefix(where, "WHERE ") + ") AND " + condition
+	}
+	return where, append(values, conditionValues...)
+}
+
+// Branch is the branch in ctx to store on a new record, nil when ctx has no branch
+func Branch(ctx context.Context) interface{} {
+	if branchID, ok := BranchID(ctx); ok {
+		return branchID
+	}
+	return nil
+}
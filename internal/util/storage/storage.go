@@ -0,0 +1,105 @@
+package storageutil
+
+import (
+	"context"
+	"dromatech/pos-backend/config"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	TYPE_LOCAL = "local"
+
+	DEFAULT_LOCAL_PATH = "attachments"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files by key, keys are made by the server and may contain "/"
+type Storage interface {
+	Save(ctx context.Context, key string, reader io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the storage of the config, the local filesystem when the type is empty
+func New(cfg config.Storage) (Storage, error) {
+	switch cfg.Type {
+	case "", TYPE_LOCAL:
+		root := cfg.LocalPath
+		if root == "" {
+			root = DEFAULT_LOCAL_PATH
+		}
+		return NewLocal(root), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %s", cfg.Type)
+	}
+}
+
+// Local keeps the files under a directory of the filesystem
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) *Local {
+	return &Local{root: root}
+}
+
+func (l *Local) Save(ctx context.Context, key string, reader io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write aside and rename so a reader never sees a partial file
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path resolves a key under the root, a key escaping the root is rejected
+func (l *Local) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.root, clean), nil
+}
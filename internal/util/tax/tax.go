@@ -0,0 +1,67 @@
+package taxutil
+
+import (
+	"math"
+	"strings"
+)
+
+// the rounding modes of TAX_ROUNDING, amounts are rounded to a multiple of TAX_ROUNDING_UNIT
+const (
+	ROUNDING_NEAREST = "NEAREST"
+	ROUNDING_DOWN    = "DOWN"
+	ROUNDING_UP      = "UP"
+)
+
+// epsilon keeps the float noise of a product, 2.9999999 units, from being rounded down or up a whole unit
+const epsilon = 1e-9
+
+// ValidRate tells whether rate is a percentage, a nil rate is valid and follows the default
+func ValidRate(rate *float64) bool {
+	return rate == nil || (*rate >= 0 && *rate <= 100)
+}
+
+// Round rounds value to a multiple of unit with mode, an unknown mode rounds to the nearest
+func Round(value float64, mode string, unit float64) float64 {
+	if unit <= 0 {
+		unit = 1
+	}
+
+	units := value / unit
+	switch mode {
+	case ROUNDING_DOWN:
+		units = math.Floor(units + epsilon)
+	case ROUNDING_UP:
+		units = math.Ceil(units - epsilon)
+	default:
+		units = math.Round(units)
+	}
+	return units * unit
+}
+
+// Exclusive splits an amount that includes PPN at rate into its DPP and PPN
+func Exclusive(amount, rate float64) (float64, float64) {
+	dpp := amount * 100 / (100 + rate)
+	return dpp, amount - dpp
+}
+
+// Npwp keeps the digits of an NPWP written with or without its dots and dash, an empty NPWP is valid and false is for one that is not 15 or 16 digits
+func Npwp(npwp string) (string, bool) {
+	if strings.TrimSpace(npwp) == "" {
+		return "", true
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, npwp)
+	if len(digits) != 15 && len(digits) != 16 {
+		return "", false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return digits, true
+}
@@ -0,0 +1,113 @@
+package taxutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		mode  string
+		unit  float64
+		want  float64
+	}{
+		{"nearest down", 1063.37, ROUNDING_NEAREST, 1, 1063},
+		{"nearest up", 2126.63, ROUNDING_NEAREST, 1, 2127},
+		{"nearest half", 2.5, ROUNDING_NEAREST, 1, 3},
+		{"nearest negative half", -2.5, ROUNDING_NEAREST, 1, -3},
+		{"nearest hundred", 1150, ROUNDING_NEAREST, 100, 1200},
+		{"unknown mode is nearest", 1063.5, "HALF_EVEN", 1, 1064},
+		{"empty mode is nearest", 1063.4, "", 1, 1063},
+		{"down", 1063.99, ROUNDING_DOWN, 1, 1063},
+		{"down hundred", 1105.5, ROUNDING_DOWN, 100, 1100},
+		{"down float noise", 2.9999999999, ROUNDING_DOWN, 1, 3},
+		{"up", 1063.01, ROUNDING_UP, 1, 1064},
+		{"up thousand", 1001, ROUNDING_UP, 1000, 2000},
+		{"up float noise", (0.1 + 0.2) * 10, ROUNDING_UP, 1, 3},
+		{"whole unit", 1100, ROUNDING_UP, 100, 1100},
+		{"no unit", 10.6, ROUNDING_DOWN, 0, 10},
+		{"negative unit", 10.4, ROUNDING_UP, -5, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Round(tt.value, tt.mode, tt.unit); got != tt.want {
+				t.Errorf("Round(%v, %q, %v) = %v, want %v", tt.value, tt.mode, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExclusive(t *testing.T) {
+	tests := []struct {
+		amount float64
+		rate   float64
+		dpp    float64
+		ppn    float64
+	}{
+		{11100, 11, 10000, 1100},
+		{11200, 12, 10000, 1200},
+		{10000, 0, 10000, 0},
+	}
+
+	for _, tt := range tests {
+		dpp, ppn := Exclusive(tt.amount, tt.rate)
+		if math.Abs(dpp-tt.dpp) > 1e-9 || math.Abs(ppn-tt.ppn) > 1e-9 {
+			t.Errorf("Exclusive(%v, %v) = %v, %v, want %v, %v", tt.amount, tt.rate, dpp, ppn, tt.dpp, tt.ppn)
+		}
+	}
+}
+
+func TestValidRate(t *testing.T) {
+	tests := []struct {
+		rate *float64
+		want bool
+	}{
+		{nil, true},
+		{ptr(0), true},
+		{ptr(11), true},
+		{ptr(100), true},
+		{ptr(-0.5), false},
+		{ptr(100.5), false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidRate(tt.rate); got != tt.want {
+			t.Errorf("ValidRate(%v) = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func ptr(value float64) *float64 {
+	return &value
+}
+
+func TestNpwp(t *testing.T) {
+	tests := []struct {
+		npwp   string
+		digits string
+		ok     bool
+	}{
+		{"", "", true},
+		{"01.234.567.8-901.000", "012345678901000", true},
+		{"012345678901000", "012345678901000", true},
+		{"0123 4567 8901 2345", "0123456789012345", true},
+		{"0123456789012345", "0123456789012345", true},
+		{"01.234.567.8-901.00", "", false},
+		{"01234567890123456", "", false},
+		{"01.234.567.8-901.00A", "", false},
+		{"01/234/567/8/901/000", "", false},
+		{"---", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.npwp, func(t *testing.T) {
+			digits, ok := Npwp(tt.npwp)
+			if digits != tt.digits || ok != tt.ok {
+				t.Errorf("Npwp(%q) = %q, %v, want %q, %v", tt.npwp, digits, ok, tt.digits, tt.ok)
+			}
+		})
+	}
+}
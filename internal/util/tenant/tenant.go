@@ -0,0 +1,100 @@
+package tenantutil
+
+import (
+	"context"
+	"dromatech/pos-backend/global"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type ctxKey struct{}
+
+// schemaPattern is the postgres schema names a tenant may use, they are put unquoted into the search path
+var schemaPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// pools are the connections of the tenants by code, each opened on the schema of its tenant
+var pools = struct {
+	sync.RWMutex
+	dbs map[string]*gorm.DB
+}{dbs: make(map[string]*gorm.DB)}
+
+// NewContext routes the data read and written with ctx to the tenant with code
+func NewContext(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, code)
+}
+
+// Code returns the tenant of ctx, empty for the default tenant kept in the public schema
+func Code(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	code, _ := ctx.Value(ctxKey{}).(string)
+	return code
+}
+
+// Key prefixes a cache key with the tenant of ctx, the tenants share the caches but never their entries
+func Key(ctx context.Context, key string) string {
+	code := Code(ctx)
+	if code == "" {
+		return key
+	}
+	return code + "/" + key
+}
+
+// DB returns the connection of the tenant of ctx, global.DBCON for the default tenant.
+// The tenant must be connected first, the tenant resolver does it before putting the tenant into ctx.
+func DB(ctx context.Context) *gorm.DB {
+	code := Code(ctx)
+	if code == "" {
+		return global.DBCON
+	}
+
+	pools.RLock()
+	db, ok := pools.dbs[code]
+	pools.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("tenant %s is not connected", code))
+	}
+	return db
+}
+
+// Connected tells whether the tenant with code has its connection
+func Connected(code string) bool {
+	pools.RLock()
+	defer pools.RUnlock()
+	_, ok := pools.dbs[code]
+	return ok
+}
+
+// Connect opens the connection of the tenant with code on schema, it is kept until the process ends
+func Connect(code, schema string) (*gorm.DB, error) {
+	pools.Lock()
+	defer pools.Unlock()
+	if db, ok := pools.dbs[code]; ok {
+		return db, nil
+	}
+
+	db, err := Open(schema)
+	if err != nil {
+		return nil, err
+	}
+	pools.dbs[code] = db
+	return db, nil
+}
+
+// Open opens a connection of the configured database whose search path is schema only
+func Open(schema string) (*gorm.DB, error) {
+	if !ValidSchema(schema) {
+		return nil, fmt.Errorf("invalid tenant schema %q", schema)
+	}
+	return gorm.Open(postgres.Open(global.CONFIG.Database.DSN()+" search_path="+schema), &gorm.Config{Logger: global.DBCON.Config.Logger})
+}
+
+// ValidSchema tells whether schema may be used as the schema of a tenant
+func ValidSchema(schema string) bool {
+	return schemaPattern.MatchString(schema) && schema != "public"
+}
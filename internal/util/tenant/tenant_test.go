@@ -0,0 +1,70 @@
+package tenantutil
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidSchema(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   bool
+	}{
+		{"tenant_acme", true},
+		{"t", true},
+		{"tenant_01", true},
+		{"a23456789012345678901234567890123456789012345678901234567890123", true},
+		{"a234567890123456789012345678901234567890123456789012345678901234", false},
+		{"", false},
+		{"public", false},
+		{"Tenant_acme", false},
+		{"1tenant", false},
+		{"_tenant", false},
+		{"tenant-acme", false},
+		{"tenant acme", false},
+		{"tenant_acme,public", false},
+		{"tenant_acme;DROP SCHEMA public", false},
+		{"\"tenant_acme\"", false},
+		{"tenant_acme\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			if got := ValidSchema(tt.schema); got != tt.want {
+				t.Errorf("ValidSchema(%q) = %v, want %v", tt.schema, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		key  string
+		want string
+	}{
+		{"default tenant", context.Background(), "TOKEN", "TOKEN"},
+		{"nil context", nil, "TOKEN", "TOKEN"},
+		{"tenant", NewContext(context.Background(), "acme"), "TOKEN", "acme/TOKEN"},
+		{"empty code", NewContext(context.Background(), ""), "TOKEN", "TOKEN"},
+		{"other tenant", NewContext(context.Background(), "beta"), "TOKEN", "beta/TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.ctx, tt.key); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenInvalidSchema(t *testing.T) {
+	// the schema is checked before the configuration is read, an invalid one never reaches the search path
+	for _, schema := range []string{"public", "tenant_acme,public", "tenant_acme options=-csearch_path=public"} {
+		if _, err := Open(schema); err == nil {
+			t.Errorf("Open(%q) error = nil, want an error", schema)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package totputil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const RECOVERY_CODE_COUNT = 10
+
+const qrCodeSize = 200
+
+// Generate creates a new RFC 6238 secret with the provisioning uri and its QR code as a png data uri
+func Generate(issuer, accountName string) (secret, uri, qrCode string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+
+	image, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return "", "", "", err
+	}
+	var buffer bytes.Buffer
+	if err = png.Encode(&buffer, image); err != nil {
+		return "", "", "", err
+	}
+
+	return key.Secret(), key.URL(), "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+// PERIOD is the seconds of a time step
+const PERIOD = 30
+
+// Validate checks the code against the secret allowing one period of clock skew
+func Validate(code, secret string) bool {
+	_, ok := Step(code, secret, time.Now())
+	return ok
+}
+
+// Step returns the time step the code belongs to, one period of clock skew is allowed. A code can be used once,
+// the caller keeps the last accepted step of the user and refuses a step that is not after it
+func Step(code, secret string, now time.Time) (int64, bool) {
+	if secret == "" {
+		return 0, false
+	}
+	code = strings.TrimSpace(code)
+
+	current := now.Unix() / PERIOD
+	for _, step := range []int64{current - 1, current, current + 1} {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*PERIOD, 0).UTC(), totp.ValidateOpts{
+			Period:    PERIOD,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCodes returns codes to show to the user once and the hashes to store
+func NewRecoveryCodes() (codes []string, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < RECOVERY_CODE_COUNT; i++ {
+		random := make([]byte, 5)
+		if _, err = rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(random))
+		code = code[:4] + "-" + code[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode hashes a recovery code ignoring case, spaces and dashes
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return base64.URLEncoding.EncodeToString(hash[:])
+}
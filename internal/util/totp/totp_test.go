@@ -0,0 +1,50 @@
+package totputil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+func TestStep(t *testing.T) {
+	secret := "JBSWY3DPEHPK3PXP"
+	now := time.Unix(1700000000, 0)
+	current := now.Unix() / PERIOD
+	code := func(at time.Time) string {
+		value, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{Period: PERIOD, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1})
+		if err != nil {
+			t.Fatalf("GenerateCodeCustom() error = %v", err)
+		}
+		return value
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		secret string
+		step   int64
+		ok     bool
+	}{
+		{"current step", code(now), secret, current, true},
+		{"padded with spaces", " " + code(now) + " ", secret, current, true},
+		{"previous step", code(now.Add(-PERIOD * time.Second)), secret, current - 1, true},
+		{"next step", code(now.Add(PERIOD * time.Second)), secret, current + 1, true},
+		{"two steps old", code(now.Add(-2 * PERIOD * time.Second)), secret, 0, false},
+		{"wrong code", "000000", secret, 0, false},
+		{"no secret", code(now), "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Step(tt.code, tt.secret, now)
+			if ok != tt.ok {
+				t.Fatalf("Step() ok = %v, want %v", ok, tt.ok)
+			}
+			if tt.ok && step != tt.step {
+				t.Errorf("Step() = %d, want %d", step, tt.step)
+			}
+		})
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"dromatech/pos-backend/app"
 	"dromatech/pos-backend/config"
 	"dromatech/pos-backend/global"
+	logutil "dromatech/pos-backend/internal/util/log"
 	"fmt"
-	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -24,15 +24,10 @@ func main() {
 	global.CONFIG = cfg
 
 	// set up log
-	f, err := os.OpenFile(cfg.LogConfig.LogFilename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0755)
-	if err != nil {
-		logrus.Fatal(err)
-	}
-	logrus.SetOutput(f)
-	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logutil.Setup(cfg.LogConfig)
 
 	// set up database
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.DBName, cfg.Database.Port)
+	dsn := cfg.Database.DSN()
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		logrus.Fatal(err)
@@ -17,6 +17,8 @@ VALUES ('web:user', 'User', 0, 'user', 'fas fa-users'),
 INSERT INTO sub_menu(id, menu_id, name, seq_order, outcome, icon)
 VALUES ('web:user:createUser', 'web:user', 'Tambah Data', 0, '/user/register-user.html', 'fas fa-plus'),
        ('web:user:editUser', 'web:user', 'Ubah Data', 1, '/user/edit-user.html', 'fas fa-pen'),
+       ('web:user:session', 'web:user', 'Sesi Aktif', 2, '/user/session.html', 'fas fa-user-clock'),
+       ('web:user:serviceAccount', 'web:user', 'Service Account', 3, '/user/service-account.html', 'fas fa-key'),
        ('web:role:createRole', 'web:role', 'Tambah Data', 0, '/role/create-role.html', 'fas fa-plus'),
        ('web:role:editRole', 'web:role', 'Ubah Data', 1, '/role/edit-role.html', 'fas fa-pen'),
        ('web:masterdata:product', 'web:masterdata', 'Produk', 0, '/master/product.html', 'fas fa-seedling'),
@@ -26,6 +28,8 @@ VALUES ('web:user:createUser', 'web:user', 'Tambah Data', 0, '/user/register-use
        ('web:price:sell', 'web:masterdata', 'Harga Jual', 4, '/price/sell.html', 'fas fa-cash-register'),
        ('web:price:template', 'web:masterdata', 'Template Harga', 4, '/price/template.html', 'fas fa-cash-register'),
        ('web:price:templatebuy', 'web:masterdata', 'Template Harga Beli', 5, '/price/template-buy.html', 'fas fa-cash-register'),
+       ('web:masterdata:expense', 'web:masterdata', 'Kategori Biaya', 6, '/master/expense.html', 'fas fa-gas-pump'),
+       ('web:masterdata:branch', 'web:masterdata', 'Cabang', 7, '/master/branch.html', 'fas fa-store'),
        ('web:transaction:sell', 'web:transaction', 'Penjualan', 2, '/transaction/sell.html', 'fas fa-money-check'),
        ('web:transaction:status', 'web:transaction', 'Status', 3, '/transaction/status.html', 'fas fa-clipboard-list'),
        ('web:transaction:kontrabon', 'web:transaction', 'Kontrabon', 3, '/transaction/kontrabon.html', 'fas fa-clipboard-list'),
@@ -34,42 +38,229 @@ VALUES ('web:user:createUser', 'web:user', 'Tambah Data', 0, '/user/register-use
        ('web:transaction:customerCredit', 'web:transaction', 'Laporan Piutang', 5, '/transaction/customer-credit.html', 'fas fa-clipboard-list'),
        ('web:transaction:customerSell', 'web:transaction', 'Laporan Penjualan', 6, '/transaction/customer-sell.html', 'fas fa-clipboard-list'),
        ('web:transaction:customerReport', 'web:transaction', 'Laporan Customer', 7, '/transaction/customer-report.html', 'fas fa-clipboard-list'),
+       ('web:transaction:closing', 'web:transaction', 'Tutup Kas', 8, '/transaction/closing.html', 'fas fa-cash-register'),
+       ('web:transaction:reconciliation', 'web:transaction', 'Rekonsiliasi Belanja', 9, '/transaction/reconciliation.html', 'fas fa-scale-balanced'),
        ('mobile', 'mobile', 'Mobile', -1, '', 'fas fa-clipboard-list')
 ;
 
 ------------ PERMISSION -------------
-INSERT INTO permission(id, sub_menu_id, name, seq_order, apis)
-VALUES ('web:user:createUser', 'web:user:createUser', 'Registrasi', 0, '/api/role/active-list;/api/user/register-user'),
-       ('web:user:editUser', 'web:user:editUser', 'Ubah Data', 1,
-        '/api/role/active-list;/api/user/find-all;/api/user/force-change-password;/api/user/change-status;/api/user/edit-user'),
-       ('web:role:createRole', 'web:role:createRole', 'Tambah Data', 0, '/api/role/permissions;/api/role/create'),
-       ('web:role:editRole', 'web:role:editRole', 'Ubah Data', 1, '/api/role/find-all;/api/role/edit'),
-       ('web:masterdata:product:add', 'web:masterdata:product', 'Tambah Data Produk', 0, '/api/product/find;/api/product/create;/api/auth/check;/api/unit/findActive'),
-       ('web:masterdata:product:view', 'web:masterdata:product', 'Lihat Data Produk', 1, '/api/product/find;/api/auth/check'),
-       ('web:masterdata:product:edit', 'web:masterdata:product', 'Perbarui Data Produk', 2, '/api/product/find;/api/product/edit;/api/auth/check;/api/unit/findActive'),
-       ('web:masterdata:supplier:add', 'web:masterdata:supplier', 'Tambah Data Supplier', 0, '/api/supplier/find;/api/supplier/create;/api/auth/check'),
-       ('web:masterdata:supplier:view', 'web:masterdata:supplier', 'Lihat Data Supplier', 1, '/api/supplier/find;/api/auth/check'),
-       ('web:masterdata:supplier:edit', 'web:masterdata:supplier', 'Perbarui Data Supplier', 2, '/api/supplier/find;/api/supplier/edit;/api/auth/check'),
-       ('web:masterdata:customer:add', 'web:masterdata:customer', 'Tambah Data Customer', 0, '/api/customer/find;/api/customer/create;/api/auth/check'),
-       ('web:masterdata:customer:view', 'web:masterdata:customer', 'Lihat Data Customer', 1, '/api/customer/find;/api/auth/check'),
-       ('web:masterdata:customer:edit', 'web:masterdata:customer', 'Perbarui Data Customer', 2, '/api/customer/find;/api/customer/edit;/api/auth/check'),
-       ('web:price:buy:manage', 'web:price:buy', 'Kelola Harga Beli', 0, '/api/unit/find;/api/unit/edit;/api/unit/create;/api/supplier/find;/api/product/findActive;/api/supplier/add-price;/api/supplier/find-latest-price;/api/supplier/find-price'),
-       ('web:price:sell:manage', 'web:price:sell', 'Kelola Harga Jual', 1, '/api/unit/find;/api/unit/edit;/api/unit/create;/api/customer/find;/api/product/findActive;/api/customer/add-price;/api/customer/find-latest-price;/api/customer/find-price'),
-       ('web:price:template:manage', 'web:price:template', 'Template Harga', 3, '/api/customer/findActive;/api/product/findActive;/api/price/template/find;/api/price/template/create;/api/price/template/edit-price;/api/price/template/apply;/api/price/template/findDetail;/api/price/template/delete;/api/price/template/copy;/api/price/template/download'),
-       ('web:price:templatebuy:managebuy', 'web:price:templatebuy', 'Template Harga Beli', 3, '/api/product/findActive;/api/price/buytemplate/find;/api/price/buytemplate/create;/api/price/buytemplate/edit-price;/api/price/buytemplate/apply;/api/price/buytemplate/findDetail;/api/price/buytemplate/delete;/api/price/buytemplate/copy;/api/price/buytemplate/download'),
-       ('web:transaction:sell:add', 'web:transaction:sell', 'Penjualan', 2, '/api/transaction/create;/api/unit/find;/api/customer/find;/api/product/findActive;/api/customer/sell-price'),
-       ('web:transaction:kontrabon:manage', 'web:transaction:kontrabon', 'Kelola Kontrabon', 3, '/api/kontrabon/find;/api/kontrabon/findTransaction;/api/kontrabon/create;/api/kontrabon/add;/api/kontrabon/remove;/api/kontrabon/update-lunas'),
-       ('web:transaction:kontrabon:view', 'web:transaction:kontrabon', 'Lihat Kontrabon', 4, '/api/kontrabon/find'),
-       ('web:transaction:status:viewstatus', 'web:transaction:status', 'Lihat Status Penjualan', 0, '/api/transaction/find'),
-       ('web:transaction:status:managestatus', 'web:transaction:status', 'Perbarui Status Penjualan', 1, '/api/transaction/find;/api/transaction/updateStatus;/api/transaction/updateBuyPrice;/api/transaction/cancelTrx;/api/transaction/update'),
-       ('web:transaction:report:view', 'web:transaction:report', 'Laporan', 0, '/api/transaction/find;/api/transaction/report'),
-       ('web:transaction:report:updatebuyprice', 'web:transaction:report', 'Ubah Harga Beli', 0, '/api/transaction/find;/api/transaction/report;/api/transaction/updateHargaBeli'),
-       ('web:transaction:buy:add', 'web:transaction:buy', 'Pembelian', 0, '/api/transaction/insertTransactionBuy;/api/unit/find;/api/supplier/find;/api/product/find;'),
-       ('web:transaction:customerCredit:view', 'web:transaction:customerCredit', 'Lihat Laporan Piutang', 0, '/api/transaction/findCustomerCredit'),
-       ('web:transaction:customerSell:view', 'web:transaction:customerSell', 'Lihat Laporan Penjualan', 0, '/api/transaction/findCustomerCredit'),
-       ('web:transaction:customerReport:view', 'web:transaction:customerReport', 'Lihat Laporan Customer', 0, '/api/transaction/findCustomerReport;/api/customer/find'),
-       ('web:transaction:mobile:dana:send', 'web:transaction:mobile', 'Kirim Dana', 0, '/api/transaction/mobile/dana/send'),
-       ('mobile', 'mobile', 'Purchasing', -1, '/api/mobile/dana/find;/api/mobile/dana/create;/api/mobile/dana/update;/api/mobile/dana/send;/api/mobile/dana/approve;/api/mobile/dana/reject;/api/mobile/dana/cancel;/api/mobile/dana/find-user;/api/product/findActive;/api/mobile/penjualan/create;/api/mobile/penjualan/delete;/api/mobile/penjualan/find;/api/mobile/belanja/create;/api/mobile/belanja/delete;/api/mobile/belanja/find;/api/mobile/operasional/create;/api/mobile/operasional/delete;/api/mobile/operasional/find;/api/mobile/operasional/find-description;/api/mobile/rekapitulasi;/api/mobile/saldo')
+INSERT INTO permission(id, sub_menu_id, name, seq_order)
+VALUES ('web:user:createUser', 'web:user:createUser', 'Registrasi', 0),
+       ('web:user:editUser', 'web:user:editUser', 'Ubah Data', 1),
+       ('web:user:session', 'web:user:session', 'Kelola Sesi', 2),
+       ('web:user:serviceAccount', 'web:user:serviceAccount', 'Kelola Service Account', 3),
+       ('web:role:createRole', 'web:role:createRole', 'Tambah Data', 0),
+       ('web:role:editRole', 'web:role:editRole', 'Ubah Data', 1),
+       ('web:masterdata:product:add', 'web:masterdata:product', 'Tambah Data Produk', 0),
+       ('web:masterdata:product:view', 'web:masterdata:product', 'Lihat Data Produk', 1),
+       ('web:masterdata:product:edit', 'web:masterdata:product', 'Perbarui Data Produk', 2),
+       ('web:masterdata:supplier:add', 'web:masterdata:supplier', 'Tambah Data Supplier', 0),
+       ('web:masterdata:supplier:view', 'web:masterdata:supplier', 'Lihat Data Supplier', 1),
+       ('web:masterdata:supplier:edit', 'web:masterdata:supplier', 'Perbarui Data Supplier', 2),
+       ('web:masterdata:customer:add', 'web:masterdata:customer', 'Tambah Data Customer', 0),
+       ('web:masterdata:customer:view', 'web:masterdata:customer', 'Lihat Data Customer', 1),
+       ('web:masterdata:customer:edit', 'web:masterdata:customer', 'Perbarui Data Customer', 2),
+       ('web:price:buy:manage', 'web:price:buy', 'Kelola Harga Beli', 0),
+       ('web:price:sell:manage', 'web:price:sell', 'Kelola Harga Jual', 1),
+       ('web:price:template:manage', 'web:price:template', 'Template Harga', 3),
+       ('web:price:templatebuy:managebuy', 'web:price:templatebuy', 'Template Harga Beli', 3),
+       ('web:transaction:sell:add', 'web:transaction:sell', 'Penjualan', 2),
+       ('web:transaction:kontrabon:manage', 'web:transaction:kontrabon', 'Kelola Kontrabon', 3),
+       ('web:transaction:kontrabon:view', 'web:transaction:kontrabon', 'Lihat Kontrabon', 4),
+       ('web:transaction:status:viewstatus', 'web:transaction:status', 'Lihat Status Penjualan', 0),
+       ('web:transaction:status:managestatus', 'web:transaction:status', 'Perbarui Status Penjualan', 1),
+       ('web:transaction:report:view', 'web:transaction:report', 'Laporan', 0),
+       ('web:transaction:report:updatebuyprice', 'web:transaction:report', 'Ubah Harga Beli', 0),
+       ('web:transaction:buy:add', 'web:transaction:buy', 'Pembelian', 0),
+       ('web:transaction:customerCredit:view', 'web:transaction:customerCredit', 'Lihat Laporan Piutang', 0),
+       ('web:transaction:customerSell:view', 'web:transaction:customerSell', 'Lihat Laporan Penjualan', 0),
+       ('web:transaction:customerReport:view', 'web:transaction:customerReport', 'Lihat Laporan Customer', 0),
+       ('web:transaction:mobile:dana:send', 'web:transaction:mobile', 'Kirim Dana', 0),
+       ('web:transaction:closing:view', 'web:transaction:closing', 'Lihat Tutup Kas', 0),
+       ('web:transaction:closing:reopen', 'web:transaction:closing', 'Buka Kembali Kas', 1),
+       ('web:transaction:closing:attachment', 'web:transaction:closing', 'Lihat Bukti', 2),
+       ('web:masterdata:expense:view', 'web:masterdata:expense', 'Lihat Kategori dan Anggaran', 0),
+       ('web:masterdata:expense:manage', 'web:masterdata:expense', 'Kelola Kategori dan Anggaran', 1),
+       ('web:transaction:reconciliation:view', 'web:transaction:reconciliation', 'Lihat Rekonsiliasi', 0),
+       ('web:transaction:reconciliation:apply', 'web:transaction:reconciliation', 'Terapkan Harga Beli Rata-rata', 1),
+       ('web:masterdata:branch:view', 'web:masterdata:branch', 'Lihat Cabang', 0),
+       ('web:masterdata:branch:manage', 'web:masterdata:branch', 'Kelola Cabang', 1),
+       ('web:masterdata:branch:report', 'web:masterdata:branch', 'Laporan Konsolidasi Cabang', 2),
+       ('web:transaction:report:efaktur', 'web:transaction:report', 'Ekspor e-Faktur', 1),
+       ('web:transaction:report:efaktur:allocate', 'web:transaction:report', 'Beri Nomor Seri Faktur Pajak', 2),
+       ('mobile', 'mobile', 'Purchasing', -1)
+;
+
+------------ PERMISSION_API -------------
+INSERT INTO permission_api(permission_id, method, path)
+VALUES ('web:user:createUser', 'GET', '/api/role/active-list'),
+       ('web:user:createUser', 'POST', '/api/user/register-user'),
+       ('web:user:editUser', 'GET', '/api/role/active-list'),
+       ('web:user:editUser', 'GET', '/api/user/find-all'),
+       ('web:user:editUser', 'POST', '/api/user/force-change-password'),
+       ('web:user:editUser', 'POST', '/api/user/change-status'),
+       ('web:user:editUser', 'POST', '/api/user/edit-user'),
+       ('web:user:editUser', 'POST', '/api/user/unlock'),
+       ('web:user:editUser', 'POST', '/api/user/totp/reset'),
+       ('web:user:editUser', 'GET', '/api/user/stakeholders'),
+       ('web:user:editUser', 'POST', '/api/user/stakeholders/assign'),
+       ('web:user:editUser', 'GET', '/api/user/managed-users'),
+       ('web:user:editUser', 'POST', '/api/user/managed-users/assign'),
+       ('web:user:editUser', 'GET', '/api/user/branches'),
+       ('web:user:editUser', 'POST', '/api/user/branches/assign'),
+       ('web:user:editUser', 'GET', '/api/branch/find'),
+       ('web:user:editUser', 'GET', '/api/customer/find'),
+       ('web:user:editUser', 'GET', '/api/supplier/find'),
+       ('web:user:session', 'GET', '/api/user/find-all'),
+       ('web:user:session', 'GET', '/api/session/find'),
+       ('web:user:session', 'POST', '/api/session/revoke'),
+       ('web:user:session', 'POST', '/api/session/revoke-user'),
+       ('web:user:session', 'GET', '/api/session/login-history'),
+       ('web:user:serviceAccount', 'GET', '/api/role/active-list'),
+       ('web:user:serviceAccount', 'GET', '/api/role/permissions'),
+       ('web:user:serviceAccount', 'GET', '/api/user/find-all'),
+       ('web:user:serviceAccount', 'POST', '/api/user/register-service-account'),
+       ('web:user:serviceAccount', 'POST', '/api/user/change-status'),
+       ('web:user:serviceAccount', 'GET', '/api/apikey/find'),
+       ('web:user:serviceAccount', 'POST', '/api/apikey/create'),
+       ('web:user:serviceAccount', 'POST', '/api/apikey/rotate'),
+       ('web:user:serviceAccount', 'POST', '/api/apikey/revoke'),
+       ('web:role:createRole', 'GET', '/api/role/permissions'),
+       ('web:role:createRole', 'POST', '/api/role/create'),
+       ('web:role:editRole', 'GET', '/api/role/find-all'),
+       ('web:role:editRole', 'POST', '/api/role/edit'),
+       ('web:masterdata:product:add', 'GET', '/api/product/find'),
+       ('web:masterdata:product:add', 'POST', '/api/product/create'),
+       ('web:masterdata:product:add', 'GET', '/api/auth/check'),
+       ('web:masterdata:product:add', 'GET', '/api/unit/findActive'),
+       ('web:masterdata:product:view', 'GET', '/api/product/find'),
+       ('web:masterdata:product:view', 'GET', '/api/auth/check'),
+       ('web:masterdata:product:edit', 'GET', '/api/product/find'),
+       ('web:masterdata:product:edit', 'POST', '/api/product/edit'),
+       ('web:masterdata:product:edit', 'GET', '/api/auth/check'),
+       ('web:masterdata:product:edit', 'GET', '/api/unit/findActive'),
+       ('web:masterdata:supplier:add', 'GET', '/api/supplier/find'),
+       ('web:masterdata:supplier:add', 'POST', '/api/supplier/create'),
+       ('web:masterdata:supplier:add', 'GET', '/api/auth/check'),
+       ('web:masterdata:supplier:view', 'GET', '/api/supplier/find'),
+       ('web:masterdata:supplier:view', 'GET', '/api/auth/check'),
+       ('web:masterdata:supplier:edit', 'GET', '/api/supplier/find'),
+       ('web:masterdata:supplier:edit', 'POST', '/api/supplier/edit'),
+       ('web:masterdata:supplier:edit', 'GET', '/api/auth/check'),
+       ('web:masterdata:customer:add', 'GET', '/api/customer/find'),
+       ('web:masterdata:customer:add', 'POST', '/api/customer/create'),
+       ('web:masterdata:customer:add', 'GET', '/api/auth/check'),
+       ('web:masterdata:customer:view', 'GET', '/api/customer/find'),
+       ('web:masterdata:customer:view', 'GET', '/api/auth/check'),
+       ('web:masterdata:customer:edit', 'GET', '/api/customer/find'),
+       ('web:masterdata:customer:edit', 'POST', '/api/customer/edit'),
+       ('web:masterdata:customer:edit', 'GET', '/api/auth/check'),
+       ('web:price:buy:manage', 'GET', '/api/unit/find'),
+       ('web:price:buy:manage', 'POST', '/api/unit/edit'),
+       ('web:price:buy:manage', 'POST', '/api/unit/create'),
+       ('web:price:buy:manage', 'GET', '/api/supplier/find'),
+       ('web:price:buy:manage', 'GET', '/api/product/findActive'),
+       ('web:price:buy:manage', 'POST', '/api/supplier/add-price'),
+       ('web:price:buy:manage', 'GET', '/api/supplier/find-latest-price'),
+       ('web:price:buy:manage', 'GET', '/api/supplier/find-price'),
+       ('web:price:sell:manage', 'GET', '/api/unit/find'),
+       ('web:price:sell:manage', 'POST', '/api/unit/edit'),
+       ('web:price:sell:manage', 'POST', '/api/unit/create'),
+       ('web:price:sell:manage', 'GET', '/api/customer/find'),
+       ('web:price:sell:manage', 'GET', '/api/product/findActive'),
+       ('web:price:sell:manage', 'POST', '/api/customer/add-price'),
+       ('web:price:sell:manage', 'GET', '/api/customer/find-latest-price'),
+       ('web:price:sell:manage', 'GET', '/api/customer/find-price'),
+       ('web:price:template:manage', 'GET', '/api/customer/findActive'),
+       ('web:price:template:manage', 'GET', '/api/product/findActive'),
+       ('web:price:template:manage', '*', '/api/price/template/*'),
+       ('web:price:templatebuy:managebuy', 'GET', '/api/product/findActive'),
+       ('web:price:templatebuy:managebuy', '*', '/api/price/buytemplate/*'),
+       ('web:transaction:sell:add', 'POST', '/api/transaction/create'),
+       ('web:transaction:sell:add', 'GET', '/api/unit/find'),
+       ('web:transaction:sell:add', 'GET', '/api/customer/find'),
+       ('web:transaction:sell:add', 'GET', '/api/product/findActive'),
+       ('web:transaction:sell:add', 'GET', '/api/customer/sell-price'),
+       ('web:transaction:kontrabon:manage', 'GET', '/api/kontrabon/find'),
+       ('web:transaction:kontrabon:manage', 'GET', '/api/kontrabon/findTransaction'),
+       ('web:transaction:kontrabon:manage', 'POST', '/api/kontrabon/create'),
+       ('web:transaction:kontrabon:manage', 'POST', '/api/kontrabon/add'),
+       ('web:transaction:kontrabon:manage', 'POST', '/api/kontrabon/remove'),
+       ('web:transaction:kontrabon:manage', 'POST', '/api/kontrabon/update-lunas'),
+       ('web:transaction:kontrabon:view', 'GET', '/api/kontrabon/find'),
+       ('web:transaction:status:viewstatus', 'GET', '/api/transaction/find'),
+       ('web:transaction:status:managestatus', 'GET', '/api/transaction/find'),
+       ('web:transaction:status:managestatus', 'POST', '/api/transaction/updateStatus'),
+       ('web:transaction:status:managestatus', 'POST', '/api/transaction/updateBuyPrice'),
+       ('web:transaction:status:managestatus', 'POST', '/api/transaction/cancelTrx'),
+       ('web:transaction:status:managestatus', 'POST', '/api/transaction/update'),
+       ('web:transaction:report:view', 'GET', '/api/transaction/find'),
+       ('web:transaction:report:view', 'GET', '/api/transaction/report'),
+       ('web:transaction:report:updatebuyprice', 'GET', '/api/transaction/find'),
+       ('web:transaction:report:updatebuyprice', 'GET', '/api/transaction/report'),
+       ('web:transaction:report:updatebuyprice', 'POST', '/api/transaction/updateHargaBeli'),
+       ('web:transaction:buy:add', 'POST', '/api/transaction/insertTransactionBuy'),
+       ('web:transaction:buy:add', 'GET', '/api/unit/find'),
+       ('web:transaction:buy:add', 'GET', '/api/supplier/find'),
+       ('web:transaction:buy:add', 'GET', '/api/product/find'),
+       ('web:transaction:customerCredit:view', 'GET', '/api/transaction/findCustomerCredit'),
+       ('web:transaction:customerSell:view', 'GET', '/api/transaction/findCustomerCredit'),
+       ('web:transaction:customerReport:view', 'GET', '/api/transaction/findCustomerReport'),
+       ('web:transaction:customerReport:view', 'GET', '/api/customer/find'),
+       ('web:transaction:mobile:dana:send', 'POST', '/api/mobile/dana/send'),
+       ('mobile', '*', '/api/mobile/dana/*'),
+       ('mobile', 'GET', '/api/product/findActive'),
+       ('mobile', '*', '/api/mobile/penjualan/*'),
+       ('mobile', '*', '/api/mobile/belanja/*'),
+       ('mobile', '*', '/api/mobile/operasional/*'),
+       ('mobile', 'GET', '/api/mobile/rekapitulasi'),
+       ('mobile', 'GET', '/api/mobile/rekapitulasi/range'),
+       ('mobile', 'GET', '/api/mobile/rekapitulasi/export'),
+       ('mobile', 'GET', '/api/mobile/saldo'),
+       ('mobile', '*', '/api/mobile/closing/*'),
+       ('mobile', '*', '/api/mobile/sync/*'),
+       ('mobile', '*', '/api/mobile/attachment/*'),
+       ('mobile', '*', '/api/mobile/notification/*'),
+       ('web:transaction:mobile:dana:send', '*', '/api/mobile/notification/*'),
+       ('web:transaction:closing:view', 'GET', '/api/transaction/closing/find'),
+       ('web:transaction:closing:view', 'GET', '/api/transaction/closing/reopen-history'),
+       ('web:transaction:closing:view', 'GET', '/api/user/find-all'),
+       ('web:transaction:closing:reopen', 'GET', '/api/transaction/closing/find'),
+       ('web:transaction:closing:reopen', 'GET', '/api/transaction/closing/reopen-history'),
+       ('web:transaction:closing:reopen', 'POST', '/api/transaction/closing/reopen'),
+       ('web:transaction:closing:reopen', 'GET', '/api/user/find-all'),
+       ('web:transaction:closing:attachment', 'GET', '/api/transaction/attachment/find'),
+       ('web:transaction:closing:attachment', 'GET', '/api/transaction/attachment/download'),
+       ('web:masterdata:expense:view', 'GET', '/api/expense/category/find'),
+       ('web:masterdata:expense:view', 'GET', '/api/expense/budget/find'),
+       ('web:masterdata:expense:view', 'GET', '/api/auth/check'),
+       ('web:masterdata:expense:manage', 'GET', '/api/expense/category/find'),
+       ('web:masterdata:expense:manage', 'POST', '/api/expense/category/create'),
+       ('web:masterdata:expense:manage', 'POST', '/api/expense/category/edit'),
+       ('web:masterdata:expense:manage', 'GET', '/api/expense/budget/find'),
+       ('web:masterdata:expense:manage', 'POST', '/api/expense/budget/create'),
+       ('web:masterdata:expense:manage', 'POST', '/api/expense/budget/edit'),
+       ('web:masterdata:expense:manage', 'POST', '/api/expense/budget/delete'),
+       ('web:masterdata:expense:manage', 'GET', '/api/user/find-all'),
+       ('web:masterdata:expense:manage', 'GET', '/api/auth/check'),
+       ('web:transaction:reconciliation:view', 'GET', '/api/transaction/reconciliation'),
+       ('web:transaction:reconciliation:view', 'GET', '/api/auth/check'),
+       ('web:transaction:reconciliation:apply', 'GET', '/api/transaction/reconciliation'),
+       ('web:transaction:reconciliation:apply', 'POST', '/api/transaction/reconciliation/apply'),
+       ('web:transaction:reconciliation:apply', 'GET', '/api/auth/check'),
+       ('web:masterdata:branch:view', 'GET', '/api/branch/find'),
+       ('web:masterdata:branch:view', 'GET', '/api/auth/check'),
+       ('web:masterdata:branch:manage', 'GET', '/api/branch/find'),
+       ('web:masterdata:branch:manage', 'POST', '/api/branch/create'),
+       ('web:masterdata:branch:manage', 'POST', '/api/branch/edit'),
+       ('web:masterdata:branch:manage', 'GET', '/api/auth/check'),
+       ('web:masterdata:branch:report', 'GET', '/api/branch/find'),
+       ('web:masterdata:branch:report', 'GET', '/api/transaction/rekap/branch'),
+       ('web:masterdata:branch:report', 'GET', '/api/auth/check'),
+       ('web:transaction:report:efaktur', 'GET', '/api/transaction/efaktur/export'),
+       ('web:transaction:report:efaktur', 'GET', '/api/auth/check'),
+       ('web:transaction:report:efaktur:allocate', 'POST', '/api/transaction/efaktur/allocate'),
+       ('web:transaction:report:efaktur:allocate', 'GET', '/api/auth/check')
 ;
 
 ----------------- ROLE ---------------
@@ -83,6 +274,8 @@ VALUES ('735c7b8b96a8463c8493037d4c8ff085', true, 'Super Admin')
 INSERT INTO role_permission(role_id, permission_id)
 VALUES ('735c7b8b96a8463c8493037d4c8ff085', 'web:user:createUser'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:user:editUser'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:user:session'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:user:serviceAccount'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:role:createRole'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:role:editRole'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:masterdata:product:add'),
@@ -95,7 +288,19 @@ VALUES ('735c7b8b96a8463c8493037d4c8ff085', 'web:user:createUser'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:price:sell:manage'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:sell:add'),
        ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:status:viewstatus'),
-       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:status:managestatus')
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:status:managestatus'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:closing:view'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:closing:reopen'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:closing:attachment'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:masterdata:expense:view'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:masterdata:expense:manage'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:reconciliation:view'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:reconciliation:apply'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:masterdata:branch:view'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:masterdata:branch:manage'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:masterdata:branch:report'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:report:efaktur'),
+       ('735c7b8b96a8463c8493037d4c8ff085', 'web:transaction:report:efaktur:allocate')
 ;
 
 ----------------- USER ---------------
@@ -105,111 +310,145 @@ VALUES ('b11dd364dd714d5a8279123426bb92e5', 'super', 'Super Admin',
         'ZuuV7rk4-Nr9PJ_TXqlUpxMoLfKA4WIcm5enyhdJlSE=', '0238daa119ab995155f346bf52cdb727',
         'email@gmail.com', '735c7b8b96a8463c8493037d4c8ff085', true, now(), null);
 
-INSERT INTO public.config(id, value)
+INSERT INTO config(id, value)
 VALUES ('LOGIN_URL', 'http://localhost/login.html'),
        ('FORBIDDEN_URL', 'http://localhost/forbidden.html'),
        ('UNAUTHORIZED_URL', 'http://localhost/unauthorized.html'),
-       ('SESSION_TIMEOUT_MINUTE', '30')
+       ('SESSION_TIMEOUT_MINUTE', '30'),
+       ('LOGIN_MAX_ATTEMPT', '5'),
+       ('LOGIN_LOCK_MINUTE', '15'),
+       ('LOGIN_IP_MAX_ATTEMPT', '20'),
+       ('LOGIN_BACKOFF_SECOND', '1'),
+       ('LOGIN_BACKOFF_MAX_SECOND', '60'),
+       ('TOTP_ISSUER', 'POS Dromatech'),
+       ('TOTP_CHALLENGE_MINUTE', '5'),
+       ('PASSWORD_MIN_LENGTH', '8'),
+       ('PASSWORD_REQUIRE_MIXED_CASE', 'false'),
+       ('PASSWORD_REQUIRE_DIGIT', 'true'),
+       ('PASSWORD_REQUIRE_SYMBOL', 'false'),
+       ('PASSWORD_HISTORY_COUNT', '3'),
+       ('PASSWORD_EXPIRY_DAY', '0'),
+       ('ATTACHMENT_MAX_SIZE_KB', '5120'),
+       ('DANA_TRANSFER_EXPIRE_HOUR', '48'),
+       ('DANA_TRANSFER_SWEEP_MINUTE', '10'),
+       ('PPN_RATE', '11'),
+       ('TAX_ROUNDING', 'NEAREST'),
+       ('TAX_ROUNDING_UNIT', '1'),
+       ('EFAKTUR_TRANSACTION_CODE', '01'),
+       ('EFAKTUR_SERIAL_START', '0'),
+       ('EFAKTUR_SERIAL_END', '0')
+;
+
+INSERT INTO expense_category(id, code, name, active)
+VALUES ('BBM', 'BBM', 'Bahan Bakar', true),
+       ('PARKIR', 'PARKIR', 'Parkir', true),
+       ('TOL', 'TOL', 'Tol', true),
+       ('KULI', 'KULI', 'Kuli Angkut', true),
+       ('LAIN', 'LAIN', 'Lain-lain', true)
 ;
 
-INSERT INTO public.unit(id, code, description, active) VALUES ('KG', 'Kg', 'Kilogram', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('PACK', 'Pack', 'Pack', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('PCS', 'Pcs', 'Pcs', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('BOX', 'Box', 'Box', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('PAPAN', 'Papan', 'Papan', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('LTR', 'Ltr', 'Ltr', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('SACHET', 'Sachet', 'Sachet', true);
-INSERT INTO public.unit(id, code, description, active) VALUES ('CAN', 'Can', 'Can', true);
-
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('1','KG','Anggur Import Hijau','Anggur Import Hijau','Anggur Import Hijau',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('2','KG','Anggur Import Merah','Anggur Import Merah','Anggur Import Merah',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('3','KG','Apel Green Smith','Apel Green Smith','Apel Green Smith',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('4','KG','Apel Malang','Apel Malang','Apel Malang',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('5','KG','Apel Merah USA','Apel Merah USA','Apel Merah USA',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('6','KG','Apel Fuji Import','Apel Fuji Import','Apel Fuji Import',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('7','KG','Avocado','Avocado','Avocado',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('8','KG','Belimbing','Belimbing','Belimbing',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('9','KG','Bengkuang','Bengkuang','Bengkuang',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('10','PACK','Blueberry','Blueberry','Blueberry',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('11','PACK','Cecenet','Cecenet','Cecenet',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('12','PACK','Cendol Elizabeth','Cendol Elizabeth','Cendol Elizabeth',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('13','PACK','Cincau hitam','Cincau hitam','Cincau hitam',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('14','PACK','Cincau hijau','Cincau hijau','Cincau hijau',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('15','KG','Duku','Duku','Duku',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('16','KG','Durian montong utuh','Durian montong utuh','Durian montong utuh',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('17','PACK','Durian lokal utuh beku','Durian lokal utuh beku','Durian lokal utuh beku',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('18','KG','Durian frozen montong @ 400gr','Durian frozen montong @ 400gr','Durian frozen montong @ 400gr',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('19','KG','Durian musang king @ 400 gr','Durian musang king @ 400 gr','Durian musang king @ 400 gr',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('20','PACK','Fruit Blackberry','Fruit Blackberry','Fruit Blackberry',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('21','KG','Jambu Air Cincalo','Jambu Air Cincalo','Jambu Air Cincalo',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('22','KG','Jambu Air Biasa','Jambu Air Biasa','Jambu Air Biasa',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('23','KG','Jambu Merah','Jambu Merah','Jambu Merah',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('24','KG','Jeruk bali / pomelo ','Jeruk bali / pomelo ','Jeruk bali / pomelo ',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('25','KG','Jeruk Mandarin','Jeruk Mandarin','Jeruk Mandarin',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('26','KG','Jeruk Medan','Jeruk Medan','Jeruk Medan',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('27','KG','Jeruk Medan Super ','Jeruk Medan Super ','Jeruk Medan Super ',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('28','KG','Jeruk Pontianak / Peres','Jeruk Pontianak / Peres','Jeruk Pontianak / Peres',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('29','KG','Jeruk Sunkist','Jeruk Sunkist','Jeruk Sunkist',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('30','KG','Kedondong','Kedondong','Kedondong',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('31','KG','Kelapa Muda Kerok','Kelapa Muda Kerok','Kelapa Muda Kerok',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('32','KG','Kelapa Muda Utuh','Kelapa Muda Utuh','Kelapa Muda Utuh',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('33','KG','Kiwi','Kiwi','Kiwi',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('34','KG','Kiwi zespri','Kiwi zespri','Kiwi zespri',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('35','KG','Kolang Kaling','Kolang Kaling','Kolang Kaling',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('36','KG','Konyal','Konyal','Konyal',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('37','KG','Kurma Palm bertangkai','Kurma Palm bertangkai','Kurma Palm bertangkai',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('38','KG','Lemon Import','Lemon Import','Lemon Import',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('39','KG','Lemon Lokal','Lemon Lokal','Lemon Lokal',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('40','KG','Lemon lembang','Lemon lembang','Lemon lembang',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('41','KG','Lengkeng','Lengkeng','Lengkeng',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('42','KG','Mangga Gedong','Mangga Gedong','Mangga Gedong',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('43','KG','Mangga Gedong Gincu','Mangga Gedong Gincu','Mangga Gedong Gincu',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('44','KG','Mangga Harum Manis','Mangga Harum Manis','Mangga Harum Manis',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('45','KG','Mangga Kweni','Mangga Kweni','Mangga Kweni',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('46','KG','Mangga Muda cengkir','Mangga Muda cengkir','Mangga Muda cengkir',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('47','KG','Mangga muda biasa','Mangga muda biasa','Mangga muda biasa',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('48','KG','Manggis','Manggis','Manggis',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('49','KG','Melon Honey Dew','Melon Honey Dew','Melon Honey Dew',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('50','KG','Melon Merah / Melon Rock','Melon Merah / Melon Rock','Melon Merah / Melon Rock',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('51','KG','Naga Merah','Naga Merah','Naga Merah',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('52','PCS','Nanas Jawa','Nanas Jawa','Nanas Jawa',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('53','PCS','Nanas Madu','Nanas Madu','Nanas Madu',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('54','KG','Nangka Matang Kupas','Nangka Matang Kupas','Nangka Matang Kupas',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('55','KG','Pear century','Pear century','Pear century',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('56','KG','Pear Hijau Aust/ Pear Jambu','Pear Hijau Aust/ Pear Jambu','Pear Hijau Aust/ Pear Jambu',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('57','KG','Pear Xiang Lie','Pear Xiang Lie','Pear Xiang Lie',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('58','KG','Pepaya','Pepaya','Pepaya',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('59','KG','Pepaya muda','Pepaya muda','Pepaya muda',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('60','KG','Pisang Ambon ','Pisang Ambon ','Pisang Ambon ',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('61','KG','Pisang raja cere','Pisang raja cere','Pisang raja cere',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('62','KG','Pisang raja bulu','Pisang raja bulu','Pisang raja bulu',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('63','KG','Pisang Muli','Pisang Muli','Pisang Muli',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('64','KG','Pisang Tanduk','Pisang Tanduk','Pisang Tanduk',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('65','KG','Pisang Nangka','Pisang Nangka','Pisang Nangka',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('66','KG','Rambutan','Rambutan','Rambutan',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('67','PACK','Raspberry','Raspberry','Raspberry',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('68','KG','Salak Pondoh','Salak Pondoh','Salak Pondoh',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('69','KG','Sawo','Sawo','Sawo',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('70','KG','Semangka Kuning','Semangka Kuning','Semangka Kuning',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('71','KG','Semangka Merah','Semangka Merah','Semangka Merah',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('72','KG','Sirsak','Sirsak','Sirsak',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('73','PACK','Strawberry','Strawberry','Strawberry',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('74','KG','Strawberry','Strawberry','Strawberry',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('75','KG','Sukun mentah','Sukun mentah','Sukun mentah',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('76','KG','Timun Suri','Timun Suri','Timun Suri',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('77','KG','Ubi Cilembu','Ubi Cilembu','Ubi Cilembu',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('78','KG','Ubi cilembu Mentah','Ubi cilembu Mentah','Ubi cilembu Mentah',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('79','KG','Ubi Manis Ungu  ','Ubi Manis Ungu  ','Ubi Manis Ungu  ',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('80','KG','Ubi Manis Biasa ','Ubi Manis Biasa ','Ubi Manis Biasa ',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('81','KG','Tape singkong','Tape singkong','Tape singkong',TRUE);
-INSERT INTO public.product(id, unit_id, code, name, description, active)VALUES ('82','KG','Tape ketan hitam','Tape ketan hitam','Tape ketan hitam',TRUE);
-
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('1', 'HOTEL HARRIS', 'HOTEL HARRIS', 'HOTEL HARRIS', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('2', 'HOTEL HILTON', 'HOTEL HILTON', 'HOTEL HILTON', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('3', 'HOTEL SAVOY HOMMAN', 'HOTEL SAVOY HOMMAN', 'HOTEL SAVOY HOMMAN', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('4', 'HOTEL ART DECO', 'HOTEL ART DECO', 'HOTEL ART DECO', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('5', 'HOTEL CALIFORNIA', 'HOTEL CALIFORNIA', 'HOTEL CALIFORNIA', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('6', 'HOTEL ARYA DUTA', 'HOTEL ARYA DUTA', 'HOTEL ARYA DUTA', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('7', 'RUMAH SAKIT BORROMEUS', 'RUMAH SAKIT BORROMEUS', 'RUMAH SAKIT BORROMEUS', TRUE);
-INSERT INTO public.customer(id, code, name, description, active)VALUES ('8', 'RUMAH SAKIT IMMANUEL', 'RUMAH SAKIT IMMANUEL', 'RUMAH SAKIT IMMANUEL', TRUE);
+INSERT INTO branch(id, code, name, code_prefix, address, active)
+VALUES ('PUSAT', 'PUSAT', 'Kantor Pusat', '', '', true)
+;
+
+INSERT INTO unit(id, code, description, active) VALUES ('KG', 'Kg', 'Kilogram', true);
+INSERT INTO unit(id, code, description, active) VALUES ('PACK', 'Pack', 'Pack', true);
+INSERT INTO unit(id, code, description, active) VALUES ('PCS', 'Pcs', 'Pcs', true);
+INSERT INTO unit(id, code, description, active) VALUES ('BOX', 'Box', 'Box', true);
+INSERT INTO unit(id, code, description, active) VALUES ('PAPAN', 'Papan', 'Papan', true);
+INSERT INTO unit(id, code, description, active) VALUES ('LTR', 'Ltr', 'Ltr', true);
+INSERT INTO unit(id, code, description, active) VALUES ('SACHET', 'Sachet', 'Sachet', true);
+INSERT INTO unit(id, code, description, active) VALUES ('CAN', 'Can', 'Can', true);
+
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('1','KG','Anggur Import Hijau','Anggur Import Hijau','Anggur Import Hijau',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('2','KG','Anggur Import Merah','Anggur Import Merah','Anggur Import Merah',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('3','KG','Apel Green Smith','Apel Green Smith','Apel Green Smith',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('4','KG','Apel Malang','Apel Malang','Apel Malang',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('5','KG','Apel Merah USA','Apel Merah USA','Apel Merah USA',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('6','KG','Apel Fuji Import','Apel Fuji Import','Apel Fuji Import',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('7','KG','Avocado','Avocado','Avocado',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('8','KG','Belimbing','Belimbing','Belimbing',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('9','KG','Bengkuang','Bengkuang','Bengkuang',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('10','PACK','Blueberry','Blueberry','Blueberry',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('11','PACK','Cecenet','Cecenet','Cecenet',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('12','PACK','Cendol Elizabeth','Cendol Elizabeth','Cendol Elizabeth',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('13','PACK','Cincau hitam','Cincau hitam','Cincau hitam',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('14','PACK','Cincau hijau','Cincau hijau','Cincau hijau',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('15','KG','Duku','Duku','Duku',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('16','KG','Durian montong utuh','Durian montong utuh','Durian montong utuh',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('17','PACK','Durian lokal utuh beku','Durian lokal utuh beku','Durian lokal utuh beku',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('18','KG','Durian frozen montong @ 400gr','Durian frozen montong @ 400gr','Durian frozen montong @ 400gr',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('19','KG','Durian musang king @ 400 gr','Durian musang king @ 400 gr','Durian musang king @ 400 gr',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('20','PACK','Fruit Blackberry','Fruit Blackberry','Fruit Blackberry',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('21','KG','Jambu Air Cincalo','Jambu Air Cincalo','Jambu Air Cincalo',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('22','KG','Jambu Air Biasa','Jambu Air Biasa','Jambu Air Biasa',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('23','KG','Jambu Merah','Jambu Merah','Jambu Merah',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('24','KG','Jeruk bali / pomelo ','Jeruk bali / pomelo ','Jeruk bali / pomelo ',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('25','KG','Jeruk Mandarin','Jeruk Mandarin','Jeruk Mandarin',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('26','KG','Jeruk Medan','Jeruk Medan','Jeruk Medan',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('27','KG','Jeruk Medan Super ','Jeruk Medan Super ','Jeruk Medan Super ',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('28','KG','Jeruk Pontianak / Peres','Jeruk Pontianak / Peres','Jeruk Pontianak / Peres',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('29','KG','Jeruk Sunkist','Jeruk Sunkist','Jeruk Sunkist',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('30','KG','Kedondong','Kedondong','Kedondong',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('31','KG','Kelapa Muda Kerok','Kelapa Muda Kerok','Kelapa Muda Kerok',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('32','KG','Kelapa Muda Utuh','Kelapa Muda Utuh','Kelapa Muda Utuh',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('33','KG','Kiwi','Kiwi','Kiwi',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('34','KG','Kiwi zespri','Kiwi zespri','Kiwi zespri',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('35','KG','Kolang Kaling','Kolang Kaling','Kolang Kaling',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('36','KG','Konyal','Konyal','Konyal',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('37','KG','Kurma Palm bertangkai','Kurma Palm bertangkai','Kurma Palm bertangkai',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('38','KG','Lemon Import','Lemon Import','Lemon Import',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('39','KG','Lemon Lokal','Lemon Lokal','Lemon Lokal',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('40','KG','Lemon lembang','Lemon lembang','Lemon lembang',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('41','KG','Lengkeng','Lengkeng','Lengkeng',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('42','KG','Mangga Gedong','Mangga Gedong','Mangga Gedong',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('43','KG','Mangga Gedong Gincu','Mangga Gedong Gincu','Mangga Gedong Gincu',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('44','KG','Mangga Harum Manis','Mangga Harum Manis','Mangga Harum Manis',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('45','KG','Mangga Kweni','Mangga Kweni','Mangga Kweni',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('46','KG','Mangga Muda cengkir','Mangga Muda cengkir','Mangga Muda cengkir',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('47','KG','Mangga muda biasa','Mangga muda biasa','Mangga muda biasa',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('48','KG','Manggis','Manggis','Manggis',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('49','KG','Melon Honey Dew','Melon Honey Dew','Melon Honey Dew',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('50','KG','Melon Merah / Melon Rock','Melon Merah / Melon Rock','Melon Merah / Melon Rock',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('51','KG','Naga Merah','Naga Merah','Naga Merah',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('52','PCS','Nanas Jawa','Nanas Jawa','Nanas Jawa',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('53','PCS','Nanas Madu','Nanas Madu','Nanas Madu',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('54','KG','Nangka Matang Kupas','Nangka Matang Kupas','Nangka Matang Kupas',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('55','KG','Pear century','Pear century','Pear century',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('56','KG','Pear Hijau Aust/ Pear Jambu','Pear Hijau Aust/ Pear Jambu','Pear Hijau Aust/ Pear Jambu',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('57','KG','Pear Xiang Lie','Pear Xiang Lie','Pear Xiang Lie',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('58','KG','Pepaya','Pepaya','Pepaya',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('59','KG','Pepaya muda','Pepaya muda','Pepaya muda',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('60','KG','Pisang Ambon ','Pisang Ambon ','Pisang Ambon ',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('61','KG','Pisang raja cere','Pisang raja cere','Pisang raja cere',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('62','KG','Pisang raja bulu','Pisang raja bulu','Pisang raja bulu',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('63','KG','Pisang Muli','Pisang Muli','Pisang Muli',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('64','KG','Pisang Tanduk','Pisang Tanduk','Pisang Tanduk',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('65','KG','Pisang Nangka','Pisang Nangka','Pisang Nangka',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('66','KG','Rambutan','Rambutan','Rambutan',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('67','PACK','Raspberry','Raspberry','Raspberry',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('68','KG','Salak Pondoh','Salak Pondoh','Salak Pondoh',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('69','KG','Sawo','Sawo','Sawo',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('70','KG','Semangka Kuning','Semangka Kuning','Semangka Kuning',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('71','KG','Semangka Merah','Semangka Merah','Semangka Merah',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('72','KG','Sirsak','Sirsak','Sirsak',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('73','PACK','Strawberry','Strawberry','Strawberry',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('74','KG','Strawberry','Strawberry','Strawberry',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('75','KG','Sukun mentah','Sukun mentah','Sukun mentah',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('76','KG','Timun Suri','Timun Suri','Timun Suri',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('77','KG','Ubi Cilembu','Ubi Cilembu','Ubi Cilembu',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('78','KG','Ubi cilembu Mentah','Ubi cilembu Mentah','Ubi cilembu Mentah',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('79','KG','Ubi Manis Ungu  ','Ubi Manis Ungu  ','Ubi Manis Ungu  ',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('80','KG','Ubi Manis Biasa ','Ubi Manis Biasa ','Ubi Manis Biasa ',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('81','KG','Tape singkong','Tape singkong','Tape singkong',TRUE);
+INSERT INTO product(id, unit_id, code, name, description, active)VALUES ('82','KG','Tape ketan hitam','Tape ketan hitam','Tape ketan hitam',TRUE);
+
+INSERT INTO customer(id, code, name, description, active)VALUES ('1', 'HOTEL HARRIS', 'HOTEL HARRIS', 'HOTEL HARRIS', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('2', 'HOTEL HILTON', 'HOTEL HILTON', 'HOTEL HILTON', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('3', 'HOTEL SAVOY HOMMAN', 'HOTEL SAVOY HOMMAN', 'HOTEL SAVOY HOMMAN', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('4', 'HOTEL ART DECO', 'HOTEL ART DECO', 'HOTEL ART DECO', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('5', 'HOTEL CALIFORNIA', 'HOTEL CALIFORNIA', 'HOTEL CALIFORNIA', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('6', 'HOTEL ARYA DUTA', 'HOTEL ARYA DUTA', 'HOTEL ARYA DUTA', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('7', 'RUMAH SAKIT BORROMEUS', 'RUMAH SAKIT BORROMEUS', 'RUMAH SAKIT BORROMEUS', TRUE);
+INSERT INTO customer(id, code, name, description, active)VALUES ('8', 'RUMAH SAKIT IMMANUEL', 'RUMAH SAKIT IMMANUEL', 'RUMAH SAKIT IMMANUEL', TRUE);
 
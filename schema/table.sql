@@ -1,10 +1,10 @@
-CREATE TABLE public.role
+CREATE TABLE role
 (
     id     VARCHAR(32) PRIMARY KEY,
     active boolean     NOT NULL DEFAULT true,
     name   VARCHAR(32) NOT NULL
 );
-CREATE TABLE public.web_user
+CREATE TABLE web_user
 (
     id                     VARCHAR(32) PRIMARY KEY,
     name                   VARCHAR(128)        NOT NULL,
@@ -16,10 +16,10 @@ CREATE TABLE public.web_user
     active                 boolean             NOT NULL DEFAULT true,
     registration_timestamp TIMESTAMP           NOT NULL,
     created_by             VARCHAR(32),
-    FOREIGN KEY (role_id) REFERENCES public.role (id),
-    FOREIGN KEY (created_by) REFERENCES public.web_user (id)
+    FOREIGN KEY (role_id) REFERENCES role (id),
+    FOREIGN KEY (created_by) REFERENCES web_user (id)
 );
-CREATE TABLE public.menu
+CREATE TABLE menu
 (
     id        VARCHAR(32) PRIMARY KEY,
     name      VARCHAR(32)  NOT NULL,
@@ -27,7 +27,7 @@ CREATE TABLE public.menu
     path      VARCHAR(128) NOT NULL,
     icon      VARCHAR(32)
 );
-CREATE TABLE public.sub_menu
+CREATE TABLE sub_menu
 (
     id        VARCHAR(32) PRIMARY KEY,
     menu_id   VARCHAR(32)  NOT NULL,
@@ -35,31 +35,31 @@ CREATE TABLE public.sub_menu
     seq_order SMALLINT     NOT NULL,
     outcome   VARCHAR(128) NOT NULL,
     icon      VARCHAR(32),
-    FOREIGN KEY (menu_id) REFERENCES public.menu (id)
+    FOREIGN KEY (menu_id) REFERENCES menu (id)
 );
-CREATE TABLE public.permission
+CREATE TABLE permission
 (
     id          VARCHAR(128) PRIMARY KEY,
     sub_menu_id VARCHAR(32)  NOT NULL,
     name        VARCHAR(32)  NOT NULL,
     seq_order   SMALLINT     NOT NULL,
     apis        VARCHAR(256) NOT NULL,
-    FOREIGN KEY (sub_menu_id) REFERENCES public.sub_menu (id)
+    FOREIGN KEY (sub_menu_id) REFERENCES sub_menu (id)
 );
-CREATE TABLE public.role_permission
+CREATE TABLE role_permission
 (
     role_id       VARCHAR(32),
     permission_id VARCHAR(128),
-    FOREIGN KEY (role_id) REFERENCES public.role (id),
-    FOREIGN KEY (permission_id) REFERENCES public.permission (id),
+    FOREIGN KEY (role_id) REFERENCES role (id),
+    FOREIGN KEY (permission_id) REFERENCES permission (id),
     PRIMARY KEY (role_id, permission_id)
 );
-CREATE TABLE public.config
+CREATE TABLE config
 (
     id    VARCHAR(32) PRIMARY KEY,
     value VARCHAR(512) NOT NULL
 );
-CREATE TABLE public.unit
+CREATE TABLE unit
 (
     id          VARCHAR(32),
     code        VARCHAR(32) NOT NULL,
@@ -67,7 +67,7 @@ CREATE TABLE public.unit
     active      boolean     NOT NULL DEFAULT true,
     PRIMARY KEY (id)
 );
-CREATE TABLE public.product
+CREATE TABLE product
 (
     id          VARCHAR(32) PRIMARY KEY,
     unit_id     VARCHAR(32)  NOT NULL,
@@ -75,9 +75,9 @@ CREATE TABLE public.product
     name        VARCHAR(128) NOT NULL,
     description VARCHAR(256),
     active      boolean      NOT NULL DEFAULT true,
-    FOREIGN KEY (unit_id) REFERENCES public.unit (id)
+    FOREIGN KEY (unit_id) REFERENCES unit (id)
 );
-CREATE TABLE public.supplier
+CREATE TABLE supplier
 (
     id          VARCHAR(32) PRIMARY KEY,
     code        VARCHAR(32)  NOT NULL,
@@ -85,7 +85,7 @@ CREATE TABLE public.supplier
     description VARCHAR(256),
     active      boolean      NOT NULL DEFAULT true
 );
-CREATE TABLE public.customer
+CREATE TABLE customer
 (
     id          VARCHAR(32) PRIMARY KEY,
     code        VARCHAR(32)  NOT NULL,
@@ -93,7 +93,7 @@ CREATE TABLE public.customer
     description VARCHAR(256),
     active      boolean      NOT NULL DEFAULT true
 );
-CREATE TABLE public.transaction
+CREATE TABLE transaction
 (
     id               VARCHAR(32)  NOT NULL PRIMARY KEY,
     code             VARCHAR(128) NOT NULL UNIQUE,
@@ -104,9 +104,9 @@ CREATE TABLE public.transaction
     reference_code   VARCHAR(128),
     web_user_id      VARCHAR(32),
     created_time     TIMESTAMP WITHOUT TIME ZONE NOT NULL,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
 );
-CREATE TABLE public.transaction_detail
+CREATE TABLE transaction_detail
 (
     id             VARCHAR(32) PRIMARY KEY,
     transaction_id VARCHAR(32),
@@ -118,11 +118,11 @@ CREATE TABLE public.transaction_detail
     created_time   TIMESTAMP WITHOUT TIME ZONE,
     web_user_id    VARCHAR(32),
     latest         BOOLEAN DEFAULT TRUE,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id),
-    FOREIGN KEY (transaction_id) REFERENCES public.transaction (id),
-    FOREIGN KEY (product_id) REFERENCES public.product (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction (id),
+    FOREIGN KEY (product_id) REFERENCES product (id)
 );
-CREATE TABLE public.buy_price
+CREATE TABLE buy_price
 (
     id             VARCHAR(32) PRIMARY KEY,
     date           TIMESTAMP WITHOUT TIME ZONE,
@@ -132,12 +132,12 @@ CREATE TABLE public.buy_price
     web_user_id    VARCHAR(32) NOT NULL,
     latest         BOOLEAN DEFAULT FALSE,
     transaction_id VARCHAR(32),
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id),
-    FOREIGN KEY (supplier_id) REFERENCES public.supplier (id),
-    FOREIGN KEY (product_id) REFERENCES public.product (id),
-    FOREIGN KEY (transaction_id) REFERENCES public.transaction (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (supplier_id) REFERENCES supplier (id),
+    FOREIGN KEY (product_id) REFERENCES product (id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction (id)
 );
-CREATE TABLE public.sell_price
+CREATE TABLE sell_price
 (
     id             VARCHAR(32) PRIMARY KEY,
     date           TIMESTAMP WITHOUT TIME ZONE,
@@ -147,46 +147,46 @@ CREATE TABLE public.sell_price
     web_user_id    VARCHAR(32) NOT NULL,
     latest         BOOLEAN DEFAULT FALSE,
     transaction_id VARCHAR(32),
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id),
-    FOREIGN KEY (customer_id) REFERENCES public.customer (id),
-    FOREIGN KEY (product_id) REFERENCES public.product (id),
-    FOREIGN KEY (transaction_id) REFERENCES public.transaction (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (customer_id) REFERENCES customer (id),
+    FOREIGN KEY (product_id) REFERENCES product (id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction (id)
 );
-CREATE TABLE public.SEQUENCE
+CREATE TABLE SEQUENCE
 (
     id         VARCHAR(128) PRIMARY KEY,
     next_value INTEGER NOT NULL DEFAULT 0
 );
-CREATE TABLE public.kontrabon
+CREATE TABLE kontrabon
 (
     id           VARCHAR(32) PRIMARY KEY,
     code         VARCHAR(128) NOT NULL,
     customer_id  VARCHAR(32)  NOT NULL,
     created_time TIMESTAMP WITHOUT TIME ZONE,
     status       VARCHAR(128) NOT NULL,
-    FOREIGN KEY (customer_id) REFERENCES public.customer (id)
+    FOREIGN KEY (customer_id) REFERENCES customer (id)
 );
-CREATE TABLE public.kontrabon_transaction
+CREATE TABLE kontrabon_transaction
 (
     kontrabon_id   VARCHAR(32),
     transaction_id VARCHAR(32),
-    FOREIGN KEY (kontrabon_id) REFERENCES public.kontrabon (id),
-    FOREIGN KEY (transaction_id) REFERENCES public.transaction (id),
+    FOREIGN KEY (kontrabon_id) REFERENCES kontrabon (id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction (id),
     PRIMARY KEY (kontrabon_id, transaction_id)
 );
-CREATE TABLE public.price_template
+CREATE TABLE price_template
 (
     id   VARCHAR(32) PRIMARY KEY,
     name VARCHAR(256) NOT NULL
 );
-CREATE TABLE public.price_template_detail
+CREATE TABLE price_template_detail
 (
     id                VARCHAR(32) PRIMARY KEY,
     price_template_id VARCHAR(32) NOT NULL,
     product_id        VARCHAR(32) NOT NULL,
     price             NUMERIC     NOT NULL,
-    FOREIGN KEY (product_id) REFERENCES public.product (id),
-    FOREIGN KEY (price_template_id) REFERENCES public.price_template (id)
+    FOREIGN KEY (product_id) REFERENCES product (id),
+    FOREIGN KEY (price_template_id) REFERENCES price_template (id)
 );
 
 ALTER TABLE price_template
@@ -210,7 +210,7 @@ ALTER TABLE kontrabon
 ALTER TABLE kontrabon
     ADD COLUMN description VARCHAR(512);
 
-CREATE TABLE public.transaction_buy
+CREATE TABLE transaction_buy
 (
     id             VARCHAR(32) PRIMARY KEY,
     transaction_id VARCHAR(32)              NOT NULL,
@@ -221,18 +221,18 @@ CREATE TABLE public.transaction_buy
     created_time   TIMESTAMP WITH TIME ZONE NOT NULL,
     web_user_id    VARCHAR(32)              NOT NULL,
     latest         BOOLEAN DEFAULT TRUE,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id),
-    FOREIGN KEY (transaction_id) REFERENCES public.transaction (id),
-    FOREIGN KEY (product_id) REFERENCES public.product (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction (id),
+    FOREIGN KEY (product_id) REFERENCES product (id)
 );
 
-CREATE TABLE public.buy_price_template
+CREATE TABLE buy_price_template
 (
     id   VARCHAR(32) PRIMARY KEY,
     name VARCHAR(256) NOT NULL
 );
 
-CREATE TABLE public.buy_price_template_transaction
+CREATE TABLE buy_price_template_transaction
 (
     id                    VARCHAR(32) PRIMARY KEY,
     buy_price_template_id VARCHAR(32) NOT NULL,
@@ -241,18 +241,18 @@ CREATE TABLE public.buy_price_template_transaction
     web_user_id           VARCHAR(32) NOT NULL
 );
 
-CREATE TABLE public.buy_price_template_detail
+CREATE TABLE buy_price_template_detail
 (
     id                    VARCHAR(32) PRIMARY KEY,
     buy_price_template_id VARCHAR(32) NOT NULL,
     product_id            VARCHAR(32) NOT NULL,
     price                 NUMERIC     NOT NULL,
     checked               boolean,
-    FOREIGN KEY (product_id) REFERENCES public.product (id),
-    FOREIGN KEY (buy_price_template_id) REFERENCES public.buy_price_template (id)
+    FOREIGN KEY (product_id) REFERENCES product (id),
+    FOREIGN KEY (buy_price_template_id) REFERENCES buy_price_template (id)
 );
 
-CREATE TABLE public.audit_log
+CREATE TABLE audit_log
 (
     id          VARCHAR(32) PRIMARY KEY,
     module      VARCHAR(32)              NOT NULL,
@@ -262,16 +262,19 @@ CREATE TABLE public.audit_log
     action      VARCHAR(32)              NOT NULL,
     action_id   VARCHAR(32)              NOT NULL,
     created_at  TIMESTAMP WITH TIME ZONE NOT NULL,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
 );
 
-ALTER TABLE public.customer
+ALTER TABLE customer
 ADD COLUMN initial_credit NUMERIC DEFAULT 0;
 
-ALTER TABLE public.permission
+ALTER TABLE permission
 ALTER COLUMN apis TYPE VARCHAR(1000);
 
-CREATE TABLE public.dana
+ALTER TABLE web_user
+ADD COLUMN language VARCHAR(5);
+
+CREATE TABLE dana
 (
     id              VARCHAR(32) PRIMARY KEY,
     date            TIMESTAMP WITH TIME ZONE NOT NULL,
@@ -279,10 +282,10 @@ CREATE TABLE public.dana
     saldo_awal      NUMERIC     NOT NULL,
     dana_tambahan   NUMERIC NOT NULL,
     created_time    TIMESTAMP WITH TIME ZONE NOT NULL,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
 );
 
-CREATE TABLE public.dana_transaction
+CREATE TABLE dana_transaction
 (
     id                  VARCHAR(32) PRIMARY KEY,
     date                TIMESTAMP WITH TIME ZONE NOT NULL,
@@ -291,11 +294,11 @@ CREATE TABLE public.dana_transaction
     amount              NUMERIC NOT NULL,
     status              VARCHAR(32) NOT NULL,
     created_time        TIMESTAMP WITH TIME ZONE NOT NULL,
-    FOREIGN KEY (sender) REFERENCES public.web_user (id),
-    FOREIGN KEY (receiver) REFERENCES public.web_user (id)
+    FOREIGN KEY (sender) REFERENCES web_user (id),
+    FOREIGN KEY (receiver) REFERENCES web_user (id)
 );
 
-CREATE TABLE public.penjualan_tunai
+CREATE TABLE penjualan_tunai
 (   
     id              VARCHAR(32) PRIMARY KEY,
     web_user_id     VARCHAR(32) NOT NULL,
@@ -304,11 +307,11 @@ CREATE TABLE public.penjualan_tunai
     quantity        SMALLINT NOT NULL,
     price           NUMERIC NOT NULL,
     created_time    TIMESTAMP WITH TIME ZONE NOT NULL,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id),
-    FOREIGN KEY (product_id) REFERENCES public.product (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (product_id) REFERENCES product (id)
 );
 
-CREATE TABLE public.belanja
+CREATE TABLE belanja
 (
     id              VARCHAR(32) PRIMARY KEY,
     web_user_id     VARCHAR(32) NOT NULL,
@@ -317,11 +320,11 @@ CREATE TABLE public.belanja
     quantity        SMALLINT NOT NULL,
     price           NUMERIC NOT NULL,
     created_time    TIMESTAMP WITH TIME ZONE NOT NULL,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id),
-    FOREIGN KEY (product_id) REFERENCES public.product (id)
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (product_id) REFERENCES product (id)
 );
 
-CREATE TABLE public.operasional
+CREATE TABLE operasional
 (
     id              VARCHAR(32) PRIMARY KEY,
     web_user_id     VARCHAR(32) NOT NULL,
@@ -330,5 +333,360 @@ CREATE TABLE public.operasional
     quantity        SMALLINT NOT NULL,  
     price           NUMERIC NOT NULL,
     created_time    TIMESTAMP WITH TIME ZONE NOT NULL,
-    FOREIGN KEY (web_user_id) REFERENCES public.web_user (id)
-);
\ No newline at end of file
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+CREATE TABLE login_history
+(
+    id            VARCHAR(32) PRIMARY KEY,
+    web_user_id   VARCHAR(32),
+    username      VARCHAR(64)              NOT NULL,
+    success       boolean                  NOT NULL,
+    client_ip     VARCHAR(64),
+    user_agent    VARCHAR(512),
+    login_time    TIMESTAMP WITH TIME ZONE NOT NULL,
+    logout_time   TIMESTAMP WITH TIME ZONE,
+    logout_reason VARCHAR(16),
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+CREATE INDEX login_history_web_user_id_idx ON login_history (web_user_id, login_time);
+
+ALTER TABLE web_user
+ADD COLUMN failed_login_count SMALLINT NOT NULL DEFAULT 0,
+ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE login_history
+ADD COLUMN failure_reason VARCHAR(16);
+
+ALTER TABLE web_user
+ADD COLUMN totp_secret VARCHAR(64),
+ADD COLUMN totp_enabled boolean NOT NULL DEFAULT false;
+
+ALTER TABLE role
+ADD COLUMN require_two_factor boolean NOT NULL DEFAULT false;
+
+CREATE TABLE web_user_recovery_code
+(
+    id           VARCHAR(32) PRIMARY KEY,
+    web_user_id  VARCHAR(32)              NOT NULL,
+    code_hash    VARCHAR(128)             NOT NULL,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_time    TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+CREATE INDEX web_user_recovery_code_web_user_id_idx ON web_user_recovery_code (web_user_id);
+
+-- method is an HTTP method or * for any, a path ending in /* matches every path below it
+CREATE TABLE permission_api
+(
+    permission_id VARCHAR(128) NOT NULL,
+    method        VARCHAR(8)   NOT NULL,
+    path          VARCHAR(256) NOT NULL,
+    PRIMARY KEY (permission_id, method, path),
+    FOREIGN KEY (permission_id) REFERENCES permission (id)
+);
+
+INSERT INTO permission_api(permission_id, method, path)
+SELECT DISTINCT p.id, '*', api
+FROM permission p, unnest(string_to_array(p.apis, ';')) api
+WHERE api <> '';
+
+ALTER TABLE permission
+DROP COLUMN apis;
+
+-- roles without all_data only read the customers and suppliers assigned to their users
+ALTER TABLE role
+    ADD COLUMN all_data boolean NOT NULL DEFAULT true;
+
+CREATE TABLE web_user_stakeholder
+(
+    web_user_id      VARCHAR(32) NOT NULL,
+    stakeholder_id   VARCHAR(32) NOT NULL,
+    stakeholder_type VARCHAR(16) NOT NULL,
+    PRIMARY KEY (web_user_id, stakeholder_id),
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+-- service accounts cannot log in, they call the api with the keys in api_key
+ALTER TABLE web_user
+    ADD COLUMN service_account boolean NOT NULL DEFAULT false;
+
+CREATE TABLE api_key
+(
+    id             VARCHAR(32) PRIMARY KEY,
+    web_user_id    VARCHAR(32)              NOT NULL,
+    name           VARCHAR(128)             NOT NULL,
+    key_hash       VARCHAR(128)             NOT NULL,
+    expired_time   TIMESTAMP WITH TIME ZONE,
+    last_used_time TIMESTAMP WITH TIME ZONE,
+    last_used_ip   VARCHAR(64),
+    created_by     VARCHAR(32)              NOT NULL,
+    created_time   TIMESTAMP WITH TIME ZONE NOT NULL,
+    rotated_time   TIMESTAMP WITH TIME ZONE,
+    revoked_time   TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+CREATE INDEX api_key_web_user_id_idx ON api_key (web_user_id);
+
+-- a key without rows here is granted everything the role of its service account grants
+CREATE TABLE api_key_permission
+(
+    api_key_id    VARCHAR(32)  NOT NULL,
+    permission_id VARCHAR(128) NOT NULL,
+    PRIMARY KEY (api_key_id, permission_id),
+    FOREIGN KEY (api_key_id) REFERENCES api_key (id),
+    FOREIGN KEY (permission_id) REFERENCES permission (id)
+);
+
+-- must_change_password restricts the session to changing the password, set when an admin sets the password
+ALTER TABLE web_user
+    ADD COLUMN must_change_password boolean NOT NULL DEFAULT false,
+    ADD COLUMN password_changed_time TIMESTAMP WITH TIME ZONE;
+
+UPDATE web_user
+SET password_changed_time = now();
+
+CREATE TABLE web_user_password_history
+(
+    id            VARCHAR(32) PRIMARY KEY,
+    web_user_id   VARCHAR(32)              NOT NULL,
+    password_hash VARCHAR(128)             NOT NULL,
+    password_salt VARCHAR(32)              NOT NULL,
+    created_time  TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+CREATE INDEX web_user_password_history_web_user_id_idx ON web_user_password_history (web_user_id, created_time);
+
+-- a closed day of a mobile user cannot be edited, saldo_akhir is carried forward as the saldo_awal of the next day
+CREATE TABLE cash_closing
+(
+    id            VARCHAR(32) PRIMARY KEY,
+    web_user_id   VARCHAR(32)              NOT NULL,
+    date          TIMESTAMP WITH TIME ZONE NOT NULL,
+    saldo_awal    NUMERIC                  NOT NULL,
+    dana_tambahan NUMERIC                  NOT NULL,
+    dana_masuk    NUMERIC                  NOT NULL,
+    belanja       NUMERIC                  NOT NULL,
+    operasional   NUMERIC                  NOT NULL,
+    dana_keluar   NUMERIC                  NOT NULL,
+    saldo_akhir   NUMERIC                  NOT NULL,
+    closed_by     VARCHAR(32)              NOT NULL,
+    closed_time   TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (web_user_id, date),
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+-- audit of the closings reopened by a supervisor
+CREATE TABLE cash_closing_reopen
+(
+    id            VARCHAR(32) PRIMARY KEY,
+    web_user_id   VARCHAR(32)              NOT NULL,
+    date          TIMESTAMP WITH TIME ZONE NOT NULL,
+    saldo_akhir   NUMERIC                  NOT NULL,
+    closed_by     VARCHAR(32)              NOT NULL,
+    closed_time   TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason        VARCHAR(512)             NOT NULL,
+    reopened_by   VARCHAR(32)              NOT NULL,
+    reopened_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+CREATE INDEX cash_closing_reopen_date_idx ON cash_closing_reopen (date, web_user_id);
+
+-- change feed of the mobile records, the offline sync reads the changes of a user after the last seq it has seen
+CREATE TABLE mobile_change
+(
+    seq          BIGSERIAL PRIMARY KEY,
+    web_user_id  VARCHAR(32)              NOT NULL,
+    entity       VARCHAR(32)              NOT NULL,
+    entity_id    VARCHAR(32)              NOT NULL,
+    action       VARCHAR(16)              NOT NULL,
+    changed_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX mobile_change_web_user_id_idx ON mobile_change (web_user_id, seq);
+
+INSERT INTO mobile_change(web_user_id, entity, entity_id, action, changed_time)
+SELECT web_user_id, entity, id, 'upsert', created_time
+FROM (SELECT web_user_id, 'dana' AS entity, id, created_time FROM dana
+      UNION ALL
+      SELECT sender, 'danaTransfer', id, created_time FROM dana_transaction
+      UNION ALL
+      SELECT receiver, 'danaTransfer', id, created_time FROM dana_transaction
+      UNION ALL
+      SELECT web_user_id, 'penjualan', id, created_time FROM penjualan_tunai
+      UNION ALL
+      SELECT web_user_id, 'belanja', id, created_time FROM belanja
+      UNION ALL
+      SELECT web_user_id, 'operasional', id, created_time FROM operasional
+      UNION ALL
+      SELECT web_user_id, 'closing', id, closed_time FROM cash_closing) existing
+ORDER BY created_time;
+
+-- receipt photos and documents of the mobile records, the files are kept in the storage backend
+CREATE TABLE attachment
+(
+    id            VARCHAR(32)              NOT NULL PRIMARY KEY,
+    owner_type    VARCHAR(32)              NOT NULL,
+    owner_id      VARCHAR(32)              NOT NULL,
+    file_name     VARCHAR(255)             NOT NULL,
+    content_type  VARCHAR(64)              NOT NULL,
+    size          BIGINT                   NOT NULL,
+    storage_key   VARCHAR(255)             NOT NULL,
+    thumbnail_key VARCHAR(255),
+    uploaded_by   VARCHAR(32)              NOT NULL,
+    uploaded_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX attachment_owner_idx ON attachment (owner_type, owner_id);
+
+-- notifications of the users, the id is also the event id of the notification stream
+CREATE TABLE notification
+(
+    id           BIGSERIAL PRIMARY KEY,
+    web_user_id  VARCHAR(32)              NOT NULL,
+    type         VARCHAR(32)              NOT NULL,
+    entity_id    VARCHAR(32)              NOT NULL,
+    data         JSONB,
+    read_time    TIMESTAMP WITH TIME ZONE,
+    created_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX notification_web_user_id_idx ON notification (web_user_id, id);
+
+-- categories of the operasional, e.g. fuel, parking or porter
+CREATE TABLE expense_category
+(
+    id     VARCHAR(32)  NOT NULL PRIMARY KEY,
+    code   VARCHAR(32)  NOT NULL UNIQUE,
+    name   VARCHAR(128) NOT NULL,
+    active BOOLEAN      NOT NULL DEFAULT TRUE
+);
+
+ALTER TABLE operasional ADD COLUMN category_id VARCHAR(32);
+
+-- daily or monthly budgets of the operasional, an empty category or user covers all of them
+CREATE TABLE expense_budget
+(
+    id          VARCHAR(32) NOT NULL PRIMARY KEY,
+    category_id VARCHAR(32),
+    web_user_id VARCHAR(32),
+    period      VARCHAR(16) NOT NULL,
+    amount      NUMERIC     NOT NULL,
+    mode        VARCHAR(16) NOT NULL,
+    active      BOOLEAN     NOT NULL DEFAULT TRUE
+);
+
+-- the user who approved or rejected a transfer, the receiver or a supervisor overriding it
+ALTER TABLE dana_transaction ADD COLUMN decided_by VARCHAR(32);
+
+CREATE INDEX dana_transaction_pending_idx ON dana_transaction (created_time) WHERE status = 'pending';
+
+-- the users a supervisor manages, the supervisor may approve or reject the transfers between them
+CREATE TABLE web_user_supervisor
+(
+    supervisor_id VARCHAR(32) NOT NULL,
+    web_user_id   VARCHAR(32) NOT NULL,
+    PRIMARY KEY (supervisor_id, web_user_id),
+    FOREIGN KEY (supervisor_id) REFERENCES web_user (id),
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id)
+);
+
+-- branches keep their books apart, code_prefix is put in front of the transaction and kontrabon codes and their sequences
+CREATE TABLE branch
+(
+    id          VARCHAR(32)  NOT NULL PRIMARY KEY,
+    code        VARCHAR(32)  NOT NULL UNIQUE,
+    name        VARCHAR(128) NOT NULL,
+    code_prefix VARCHAR(16)  NOT NULL DEFAULT '',
+    address     TEXT         NOT NULL DEFAULT '',
+    active      BOOLEAN      NOT NULL DEFAULT TRUE
+);
+
+-- the branches of a user, the first active one is selected at login, a user without one reads every branch
+CREATE TABLE web_user_branch
+(
+    web_user_id VARCHAR(32) NOT NULL,
+    branch_id   VARCHAR(32) NOT NULL,
+    PRIMARY KEY (web_user_id, branch_id),
+    FOREIGN KEY (web_user_id) REFERENCES web_user (id),
+    FOREIGN KEY (branch_id) REFERENCES branch (id)
+);
+
+-- the branch a record was made in, NULL for the records made without a branch
+ALTER TABLE transaction ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE kontrabon ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE price_template ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE buy_price_template ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE dana ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE dana_transaction ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE penjualan_tunai ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE belanja ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE operasional ADD COLUMN branch_id VARCHAR(32);
+
+CREATE INDEX transaction_branch_id_idx ON transaction (branch_id);
+CREATE INDEX kontrabon_branch_id_idx ON kontrabon (branch_id);
+
+-- the tax settings of a customer and a product, a NULL tax_rate follows the product, the customer or PPN_RATE in that order
+ALTER TABLE customer ADD COLUMN taxable BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE customer ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE customer ADD COLUMN tax_rate NUMERIC;
+ALTER TABLE product ADD COLUMN tax_rate NUMERIC;
+
+-- the discounts and the PPN of a transaction, discount_type is PERCENT or AMOUNT and the amounts are kept as worked out when it was saved
+ALTER TABLE transaction ADD COLUMN discount_type VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE transaction ADD COLUMN discount_value NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transaction ADD COLUMN subtotal NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN discount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN dpp NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN ppn NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN grand_total NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction_detail ADD COLUMN discount_type VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE transaction_detail ADD COLUMN discount_value NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction_detail ADD COLUMN discount_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction_detail ADD COLUMN tax_rate NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction_detail ADD COLUMN dpp NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction_detail ADD COLUMN ppn NUMERIC NOT NULL DEFAULT 0;
+
+-- the transactions made before had neither discount nor PPN, their whole amount is the DPP
+UPDATE transaction_detail SET dpp = sell_price * quantity;
+UPDATE transaction t
+SET subtotal = s.amount, dpp = s.amount, grand_total = s.amount
+FROM (SELECT transaction_id, SUM(sell_price * quantity) AS amount FROM transaction_detail WHERE latest GROUP BY transaction_id) s
+WHERE s.transaction_id = t.id;
+
+-- the NPWP and address of a customer for its tax invoices, the NPWP is kept as digits only
+ALTER TABLE customer ADD COLUMN npwp VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE customer ADD COLUMN address TEXT NOT NULL DEFAULT '';
+
+-- the tax invoice serial number (NSFP) a transaction got when it was first exported to e-Faktur, from EFAKTUR_SERIAL_START to EFAKTUR_SERIAL_END
+ALTER TABLE transaction ADD COLUMN tax_invoice_number VARCHAR(13);
+
+CREATE UNIQUE INDEX transaction_tax_invoice_number_idx ON transaction (tax_invoice_number);
+
+-- the transaction that logged a change, the feed is read in the order the changes committed since a seq taken by a
+-- transaction still running can be lower than the seq of a change already read
+ALTER TABLE mobile_change ADD COLUMN tx_id BIGINT NOT NULL DEFAULT pg_current_xact_id()::TEXT::BIGINT;
+
+CREATE INDEX mobile_change_tx_id_idx ON mobile_change (web_user_id, tx_id, seq);
+
+-- the time step of the last TOTP code a user logged in with, a code is refused when its step is not after it
+ALTER TABLE web_user ADD COLUMN totp_last_step BIGINT;
+
+-- the time the server saved a transfer, it expires DANA_TRANSFER_EXPIRE_HOUR after it since created_time is the client time of a synced transfer
+ALTER TABLE dana_transaction ADD COLUMN inserted_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+UPDATE dana_transaction SET inserted_time = LEAST(created_time, inserted_time);
+
+DROP INDEX dana_transaction_pending_idx;
+CREATE INDEX dana_transaction_pending_idx ON dana_transaction (inserted_time) WHERE status = 'pending';
+
+-- a day is closed per branch, a user working in two branches closes the saldo of each
+ALTER TABLE cash_closing ADD COLUMN branch_id VARCHAR(32);
+ALTER TABLE cash_closing_reopen ADD COLUMN branch_id VARCHAR(32);
+
+ALTER TABLE cash_closing DROP CONSTRAINT cash_closing_web_user_id_date_key;
+CREATE UNIQUE INDEX cash_closing_web_user_id_branch_id_date_idx ON cash_closing (web_user_id, COALESCE(branch_id, ''), date);
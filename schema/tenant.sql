@@ -0,0 +1,11 @@
+-- the registry of the businesses hosted on the deployment, it stays in the public schema next to the default tenant
+-- while every tenant gets its own schema with table.sql and seed.sql
+CREATE TABLE IF NOT EXISTS public.tenant
+(
+    id           VARCHAR(32)  NOT NULL PRIMARY KEY,
+    code         VARCHAR(32)  NOT NULL UNIQUE,
+    name         VARCHAR(128) NOT NULL,
+    schema_name  VARCHAR(63)  NOT NULL UNIQUE,
+    active       BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_time TIMESTAMP    NOT NULL
+);